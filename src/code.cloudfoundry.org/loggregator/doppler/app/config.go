@@ -2,10 +2,13 @@ package app
 
 import (
 	"errors"
+	"fmt"
 	"io/ioutil"
+	"runtime"
 	"time"
 
 	"code.cloudfoundry.org/loggregator/doppler/internal/iprange"
+	"code.cloudfoundry.org/loggregator/plumbing/limits"
 
 	"encoding/json"
 	"os"
@@ -32,6 +35,15 @@ type GRPC struct {
 	CipherSuites []string
 }
 
+type AggregateDrain struct {
+	Name                string
+	URL                 string
+	Hostname            string
+	BufferSize          uint
+	SourceTypes         []string
+	IncludePlatformLogs bool
+}
+
 type Config struct {
 	DisableSyslogDrains             bool
 	DisableAnnounce                 bool
@@ -46,6 +58,8 @@ type Config struct {
 	JobName                         string
 	IP                              string
 	MaxRetainedLogMessages          uint32
+	MaxRetainedLogBytes             int64
+	MaxRetainedLogBytesPerApp       int64
 	MessageDrainBufferSize          uint
 	MetricBatchIntervalMilliseconds uint
 	MetronConfig                    MetronConfig
@@ -63,6 +77,11 @@ type Config struct {
 	Zone                            string
 	PPROFPort                       uint32
 	HealthAddr                      string
+	AggregateDrains                 []AggregateDrain
+	IngressLimits                   limits.Config
+	UsageIntervalSeconds            uint
+	UsageMaxSourceIDs               int
+	RouterWorkerCount               int
 }
 
 func (c *Config) validate() (err error) {
@@ -99,6 +118,18 @@ func (c *Config) validate() (err error) {
 		return errors.New("invalid doppler config, no GRPC.KeyFile provided")
 	}
 
+	names := make(map[string]bool)
+	for _, d := range c.AggregateDrains {
+		if d.Name == "" || d.URL == "" {
+			return errors.New("invalid doppler config, aggregate drains require a Name and URL")
+		}
+
+		if names[d.Name] {
+			return fmt.Errorf("invalid doppler config, duplicate aggregate drain %s", d.Name)
+		}
+		names[d.Name] = true
+	}
+
 	return nil
 }
 
@@ -163,9 +194,27 @@ func Parse(confData []byte) (*Config, error) {
 		config.GRPC.Port = 8082
 	}
 
+	if config.UsageIntervalSeconds == 0 {
+		config.UsageIntervalSeconds = 60
+	}
+
+	if config.UsageMaxSourceIDs == 0 {
+		config.UsageMaxSourceIDs = 10000
+	}
+
+	if config.RouterWorkerCount == 0 {
+		config.RouterWorkerCount = runtime.NumCPU()
+	}
+
 	if config.HealthAddr == "" {
 		config.HealthAddr = "localhost:14825"
 	}
 
+	for i := range config.AggregateDrains {
+		if config.AggregateDrains[i].Hostname == "" {
+			config.AggregateDrains[i].Hostname = "loggregator"
+		}
+	}
+
 	return config, nil
 }
@@ -1,8 +1,7 @@
 package firehose_group
 
 import (
-	"math/rand"
-	"sync"
+	"sync/atomic"
 
 	"code.cloudfoundry.org/loggregator/doppler/internal/groupedsinks/sink_wrapper"
 	"code.cloudfoundry.org/loggregator/doppler/internal/sinks"
@@ -24,9 +23,11 @@ type FirehoseGroup interface {
 	BroadcastMessage(msg *events.Envelope)
 }
 
+// firehoseGroup distributes messages across the sinks of a firehose
+// subscription. Broadcasting never locks.
 type firehoseGroup struct {
-	mu       sync.RWMutex
-	wrappers map[string]*sink_wrapper.SinkWrapper
+	next     uint64
+	wrappers *sink_wrapper.Set
 
 	batcher       MetricBatcher
 	droppedMetric *metricemitter.Counter
@@ -37,110 +38,53 @@ func NewFirehoseGroup(
 	droppedMetric *metricemitter.Counter,
 ) *firehoseGroup {
 	return &firehoseGroup{
-		wrappers:      make(map[string]*sink_wrapper.SinkWrapper),
+		wrappers:      sink_wrapper.NewSet(),
 		batcher:       batcher,
 		droppedMetric: droppedMetric,
 	}
 }
 
 func (group *firehoseGroup) Exists(sink sinks.Sink) bool {
-	group.mu.RLock()
-	defer group.mu.RUnlock()
-	return group.exists(sink)
+	return group.wrappers.Exists(sink)
 }
 
 func (group *firehoseGroup) AddSink(sink sinks.Sink, in chan<- *events.Envelope) bool {
-	group.mu.Lock()
-	defer group.mu.Unlock()
-
-	if group.exists(sink) {
-		return false
-	}
-
-	group.wrappers[sink.Identifier()] = &sink_wrapper.SinkWrapper{
-		InputChan: in,
-		Sink:      sink,
-	}
-
-	return true
-}
-
-// exists needs to be called with read or write lock held.
-func (group *firehoseGroup) exists(sink sinks.Sink) bool {
-	w, ok := group.wrappers[sink.Identifier()]
-	return ok && w != nil
+	return group.wrappers.Add(sink, in)
 }
 
 func (group *firehoseGroup) RemoveSink(fsink sinks.Sink) bool {
-	group.mu.Lock()
-	defer group.mu.Unlock()
-	return group.removeSink(fsink)
+	return group.wrappers.Remove(fsink)
 }
 
 func (group *firehoseGroup) RemoveAllSinks() {
-	group.mu.Lock()
-	defer group.mu.Unlock()
-
-	for _, wrapper := range group.wrappers {
-		if wrapper != nil {
-			group.removeSink(wrapper.Sink)
-		}
-	}
-}
-
-// removeSink needs to be called with write lock held.
-func (group *firehoseGroup) removeSink(sink sinks.Sink) bool {
-	wrapper, ok := group.wrappers[sink.Identifier()]
-	delete(group.wrappers, sink.Identifier())
-
-	if !ok || wrapper == nil {
-		return false
-	}
-
-	close(wrapper.InputChan)
-	return true
+	group.wrappers.RemoveAll()
 }
 
 func (group *firehoseGroup) IsEmpty() bool {
-	group.mu.RLock()
-	defer group.mu.RUnlock()
-	return len(group.wrappers) == 0
+	return group.wrappers.Len() == 0
 }
 
 func (group *firehoseGroup) BroadcastMessage(msg *events.Envelope) {
-	group.mu.RLock()
-	defer group.mu.RUnlock()
-
-	// only write to a single wrapper
-	wrapper := group.randomWrapper()
-	if wrapper == nil {
-		return
-	}
-	select {
-	case wrapper.InputChan <- msg:
-	default:
-		// metric-documentation-v1: (sinks.dropped) Number of envelopes dropped
-		// while inserting envelope into sink.
-		group.batcher.BatchIncrementCounter("sinks.dropped")
-
-		// metric-documentation-v2: (loggregator.doppler.sinks.dropped)
-		// Number of envelopes dropped while inserting envelope into sink.
-		group.droppedMetric.Increment(1)
-	}
-}
+	group.wrappers.Read(func(wrappers []*sink_wrapper.SinkWrapper) {
+		if len(wrappers) == 0 {
+			return
+		}
 
-// randomWrapper needs to be called with read or write lock held.
-func (group *firehoseGroup) randomWrapper() *sink_wrapper.SinkWrapper {
-	var i int
-	if len(group.wrappers) == 0 {
-		return nil
-	}
-	r := rand.Intn(len(group.wrappers))
-	for _, w := range group.wrappers {
-		if i == r {
-			return w
+		// only write to a single wrapper. The wrappers take turns as
+		// picking one at random would lock the shared random source.
+		i := atomic.AddUint64(&group.next, 1)
+		wrapper := wrappers[i%uint64(len(wrappers))]
+
+		select {
+		case wrapper.InputChan <- msg:
+		default:
+			// metric-documentation-v1: (sinks.dropped) Number of envelopes dropped
+			// while inserting envelope into sink.
+			group.batcher.BatchIncrementCounter("sinks.dropped")
+
+			// metric-documentation-v2: (loggregator.doppler.sinks.dropped)
+			// Number of envelopes dropped while inserting envelope into sink.
+			group.droppedMetric.Increment(1)
 		}
-		i++
-	}
-	return nil
+	})
 }
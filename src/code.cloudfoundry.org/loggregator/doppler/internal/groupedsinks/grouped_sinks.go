@@ -2,11 +2,13 @@ package groupedsinks
 
 import (
 	"sync"
+	"sync/atomic"
 
 	"code.cloudfoundry.org/loggregator/metricemitter"
 
 	"code.cloudfoundry.org/loggregator/doppler/internal/groupedsinks/firehose_group"
 	"code.cloudfoundry.org/loggregator/doppler/internal/groupedsinks/sink_wrapper"
+	"code.cloudfoundry.org/loggregator/doppler/internal/keyhash"
 	"code.cloudfoundry.org/loggregator/doppler/internal/sinks"
 	"code.cloudfoundry.org/loggregator/doppler/internal/sinks/containermetric"
 	"code.cloudfoundry.org/loggregator/doppler/internal/sinks/dump"
@@ -33,78 +35,107 @@ func NewGroupedSinks(b MetricBatcher, mc MetricClient) *GroupedSinks {
 		metricemitter.WithVersion(2, 0),
 	)
 
-	return &GroupedSinks{
-		apps:          make(map[string]*AppGroup),
-		firehoses:     make(map[string]firehose_group.FirehoseGroup),
+	group := &GroupedSinks{
 		batcher:       b,
 		droppedMetric: droppedMetric,
 		errorMetric:   errorMetric,
 	}
+	for i := range group.apps {
+		group.apps[i].Store(make(map[string]*AppGroup))
+	}
+	group.firehoses.Store(make(map[string]firehose_group.FirehoseGroup))
+
+	return group
 }
 
+// appShardCount is the number of shards of the app registry. Registering
+// or removing the sinks of an app copies one shard.
+const appShardCount = 64
+
+// GroupedSinks is the registry of the app and firehose sinks. The apps are
+// sharded by app ID. Each shard and the firehoses are immutable maps that
+// are replaced on every change so that broadcasting never locks.
+// Registering and removing sinks is serialized.
 type GroupedSinks struct {
-	sync.RWMutex
+	mu        sync.Mutex
+	apps      [appShardCount]atomic.Value
+	firehoses atomic.Value
 
-	apps          map[string]*AppGroup
-	firehoses     map[string]firehose_group.FirehoseGroup
 	batcher       MetricBatcher
 	droppedMetric *metricemitter.Counter
 	errorMetric   *metricemitter.Counter
 }
 
 func (group *GroupedSinks) RegisterAppSink(in chan<- *events.Envelope, sink sinks.Sink) bool {
-	group.Lock()
-	defer group.Unlock()
+	group.mu.Lock()
+	defer group.mu.Unlock()
 
 	appId := sink.AppID()
 	if appId == "" || sink.Identifier() == "" {
 		return false
 	}
 
-	sinksForApp, ok := group.apps[appId]
-	if !ok || sinksForApp == nil {
+	shard := group.appShard(appId)
+	apps := shard.Load().(map[string]*AppGroup)
+	sinksForApp, ok := apps[appId]
+	if !ok {
 		sinksForApp = NewAppGroup(
 			group.batcher,
 			group.droppedMetric,
 			group.errorMetric,
 		)
-		group.apps[appId] = sinksForApp
+		if !sinksForApp.AddSink(sink, in) {
+			return false
+		}
+
+		apps = copyApps(apps)
+		apps[appId] = sinksForApp
+		shard.Store(apps)
+
+		return true
 	}
+
 	return sinksForApp.AddSink(sink, in)
 }
 
 func (group *GroupedSinks) RegisterFirehoseSink(in chan<- *events.Envelope, sink sinks.Sink) bool {
-	group.Lock()
-	defer group.Unlock()
+	group.mu.Lock()
+	defer group.mu.Unlock()
 
 	subscriptionId := sink.AppID()
 	if subscriptionId == "" {
 		return false
 	}
 
-	fgroup, ok := group.firehoses[subscriptionId]
-	if !ok || fgroup == nil {
+	firehoses := group.loadFirehoses()
+	fgroup, ok := firehoses[subscriptionId]
+	if !ok {
 		fgroup = firehose_group.NewFirehoseGroup(
 			group.batcher,
 			group.droppedMetric,
 		)
-		group.firehoses[subscriptionId] = fgroup
+		if !fgroup.AddSink(sink, in) {
+			return false
+		}
+
+		firehoses = copyFirehoses(firehoses)
+		firehoses[subscriptionId] = fgroup
+		group.firehoses.Store(firehoses)
+
+		return true
 	}
 
 	return fgroup.AddSink(sink, in)
 }
 
 func (group *GroupedSinks) IsFirehoseRegistered(sink sinks.Sink) bool {
-	group.RLock()
-	defer group.RUnlock()
-
 	subscriptionId := sink.AppID()
 	if subscriptionId == "" {
 		return false
 	}
 
-	fgroup, ok := group.firehoses[subscriptionId]
-	if !ok || fgroup == nil {
+	fgroup, ok := group.loadFirehoses()[subscriptionId]
+	if !ok {
 		return false
 	}
 
@@ -112,162 +143,177 @@ func (group *GroupedSinks) IsFirehoseRegistered(sink sinks.Sink) bool {
 }
 
 func (group *GroupedSinks) Broadcast(appId string, msg *events.Envelope) {
-	group.RLock()
-	defer group.RUnlock()
-
-	sinksForApp, ok := group.apps[appId]
-	if ok && sinksForApp != nil {
+	sinksForApp, ok := group.loadApps(appId)[appId]
+	if ok {
 		sinksForApp.BroadcastMessage(msg)
 	}
 	group.broadcastMessageToFirehoses(msg)
 }
 
 func (group *GroupedSinks) BroadcastError(appId string, msg *events.Envelope) {
-	group.RLock()
-	defer group.RUnlock()
-
-	sinksForApp, ok := group.apps[appId]
-	if ok && sinksForApp != nil {
+	sinksForApp, ok := group.loadApps(appId)[appId]
+	if ok {
 		sinksForApp.BroadcastError(msg)
 	}
 	group.broadcastMessageToFirehoses(msg)
 }
 
 func (group *GroupedSinks) broadcastMessageToFirehoses(msg *events.Envelope) {
-	for _, fgroup := range group.firehoses {
-		if fgroup == nil {
-			continue
-		}
+	for _, fgroup := range group.loadFirehoses() {
 		fgroup.BroadcastMessage(msg)
 	}
 }
 
 func (group *GroupedSinks) CountFor(appId string) int {
-	group.RLock()
-	defer group.RUnlock()
-
-	sinksForApp, ok := group.apps[appId]
-	if !ok || sinksForApp == nil {
+	sinksForApp, ok := group.loadApps(appId)[appId]
+	if !ok {
 		return 0
 	}
 	return sinksForApp.length()
 }
 
 func (group *GroupedSinks) DrainFor(appId, drainMetaData string) sinks.Sink {
-	group.RLock()
-	defer group.RUnlock()
-
-	sinksForApp, ok := group.apps[appId]
-	if !ok || sinksForApp == nil {
+	sinksForApp, ok := group.loadApps(appId)[appId]
+	if !ok {
 		return nil
 	}
 	return sinksForApp.Sink(drainMetaData)
 }
 
 func (group *GroupedSinks) DrainsFor(appId string) []sinks.Sink {
-	group.RLock()
-	defer group.RUnlock()
-
-	sinksForApp, ok := group.apps[appId]
-	if !ok || sinksForApp == nil {
+	sinksForApp, ok := group.loadApps(appId)[appId]
+	if !ok {
 		return nil
 	}
 	return sinksForApp.SyslogSinks()
 }
 
 func (group *GroupedSinks) DumpFor(appId string) *dump.DumpSink {
-	group.RLock()
-	defer group.RUnlock()
-
-	sinksForApp, ok := group.apps[appId]
-	if !ok || sinksForApp == nil {
+	sinksForApp, ok := group.loadApps(appId)[appId]
+	if !ok {
 		return nil
 	}
 	return sinksForApp.RecentLogsSink(appId)
 }
 
 func (group *GroupedSinks) ContainerMetricsFor(appId string) *containermetric.ContainerMetricSink {
-	group.RLock()
-	defer group.RUnlock()
-
-	sinksForApp, ok := group.apps[appId]
-	if !ok || sinksForApp == nil {
+	sinksForApp, ok := group.loadApps(appId)[appId]
+	if !ok {
 		return nil
 	}
 	return sinksForApp.ContainerMetricsSink("container-metrics-" + appId)
 }
 
 func (group *GroupedSinks) WebsocketSinksFor(appId string) []websocket.WebsocketSink {
-	group.RLock()
-	defer group.RUnlock()
-
-	sinksForApp, ok := group.apps[appId]
-	if !ok || sinksForApp == nil {
+	sinksForApp, ok := group.loadApps(appId)[appId]
+	if !ok {
 		return nil
 	}
 	return sinksForApp.WebsocketSinks()
 }
 
 func (group *GroupedSinks) CloseAndDelete(sink sinks.Sink) bool {
-	group.Lock()
-	defer group.Unlock()
+	group.mu.Lock()
+	defer group.mu.Unlock()
 
 	appId := sink.AppID()
 
-	sinksForApp, ok := group.apps[appId]
-	if !ok || sinksForApp == nil {
+	shard := group.appShard(appId)
+	apps := shard.Load().(map[string]*AppGroup)
+	sinksForApp, ok := apps[appId]
+	if !ok {
 		return false
 	}
 
 	removed := sinksForApp.RemoveSink(sink)
 	if sinksForApp.IsEmpty() {
-		delete(group.apps, appId)
+		apps = copyApps(apps)
+		delete(apps, appId)
+		shard.Store(apps)
 	}
 
 	return removed
 }
 
 func (group *GroupedSinks) CloseAndDeleteFirehose(sink sinks.Sink) bool {
-	group.Lock()
-	defer group.Unlock()
+	group.mu.Lock()
+	defer group.mu.Unlock()
 
 	firehoseSubscriptionId := sink.AppID()
 
-	fgroup, ok := group.firehoses[firehoseSubscriptionId]
-	if !ok || fgroup == nil {
+	firehoses := group.loadFirehoses()
+	fgroup, ok := firehoses[firehoseSubscriptionId]
+	if !ok {
 		return false
 	}
 
 	removed := fgroup.RemoveSink(sink)
 
 	if fgroup.IsEmpty() {
-		delete(group.firehoses, firehoseSubscriptionId)
+		firehoses = copyFirehoses(firehoses)
+		delete(firehoses, firehoseSubscriptionId)
+		group.firehoses.Store(firehoses)
 	}
 
 	return removed
 }
 
 func (group *GroupedSinks) DeleteAll() {
-	group.Lock()
-	defer group.Unlock()
+	group.mu.Lock()
+	defer group.mu.Unlock()
+
+	for i := range group.apps {
+		apps := group.apps[i].Load().(map[string]*AppGroup)
+		group.apps[i].Store(make(map[string]*AppGroup))
 
-	for appId, sinksForApp := range group.apps {
-		if sinksForApp != nil {
+		for _, sinksForApp := range apps {
 			sinksForApp.RemoveAllSinks()
 		}
-		delete(group.apps, appId)
 	}
-	for subscriptionId, fgroup := range group.firehoses {
-		if fgroup != nil {
-			fgroup.RemoveAllSinks()
-		}
-		delete(group.firehoses, subscriptionId)
+
+	firehoses := group.loadFirehoses()
+	group.firehoses.Store(make(map[string]firehose_group.FirehoseGroup))
+
+	for _, fgroup := range firehoses {
+		fgroup.RemoveAllSinks()
+	}
+}
+
+// appShard returns the shard of the app registry that holds the app.
+func (group *GroupedSinks) appShard(appId string) *atomic.Value {
+	return &group.apps[keyhash.Sum32(appId)%appShardCount]
+}
+
+// loadApps returns the apps of the shard that holds the app. The map must
+// not be modified.
+func (group *GroupedSinks) loadApps(appId string) map[string]*AppGroup {
+	return group.appShard(appId).Load().(map[string]*AppGroup)
+}
+
+// loadFirehoses returns the firehoses. The map must not be modified.
+func (group *GroupedSinks) loadFirehoses() map[string]firehose_group.FirehoseGroup {
+	return group.firehoses.Load().(map[string]firehose_group.FirehoseGroup)
+}
+
+func copyApps(apps map[string]*AppGroup) map[string]*AppGroup {
+	c := make(map[string]*AppGroup, len(apps)+1)
+	for k, v := range apps {
+		c[k] = v
+	}
+	return c
+}
+
+func copyFirehoses(firehoses map[string]firehose_group.FirehoseGroup) map[string]firehose_group.FirehoseGroup {
+	c := make(map[string]firehose_group.FirehoseGroup, len(firehoses)+1)
+	for k, v := range firehoses {
+		c[k] = v
 	}
+	return c
 }
 
+// AppGroup holds the sinks of an app. Broadcasting never locks.
 type AppGroup struct {
-	mu       sync.RWMutex
-	wrappers map[string]*sink_wrapper.SinkWrapper
+	wrappers *sink_wrapper.Set
 
 	batcher       MetricBatcher
 	droppedMetric *metricemitter.Counter
@@ -280,7 +326,7 @@ func NewAppGroup(
 	errorMetric *metricemitter.Counter,
 ) *AppGroup {
 	return &AppGroup{
-		wrappers:      make(map[string]*sink_wrapper.SinkWrapper),
+		wrappers:      sink_wrapper.NewSet(),
 		batcher:       batcher,
 		droppedMetric: droppedMetric,
 		errorMetric:   errorMetric,
@@ -288,44 +334,23 @@ func NewAppGroup(
 }
 
 func (g *AppGroup) AddSink(sink sinks.Sink, in chan<- *events.Envelope) bool {
-	g.mu.Lock()
-	defer g.mu.Unlock()
-
-	if g.exists(sink) {
-		return false
-	}
-
-	g.wrappers[sink.Identifier()] = &sink_wrapper.SinkWrapper{
-		InputChan: in,
-		Sink:      sink,
-	}
-
-	return true
+	return g.wrappers.Add(sink, in)
 }
 
 func (g *AppGroup) Exists(sink sinks.Sink) bool {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
-	return g.exists(sink)
-}
-
-// exists needs to be called with read or write lock held.
-func (g *AppGroup) exists(sink sinks.Sink) bool {
-	w, ok := g.wrappers[sink.Identifier()]
-	return ok && w != nil
+	return g.wrappers.Exists(sink)
 }
 
 func (g *AppGroup) Sink(id string) sinks.Sink {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
-	return g.sink(id)
+	wrapper := g.wrappers.Get(id)
+	if wrapper == nil {
+		return nil
+	}
+	return wrapper.Sink
 }
 
 func (g *AppGroup) RecentLogsSink(id string) *dump.DumpSink {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
-
-	dump, ok := g.sink(id).(*dump.DumpSink)
+	dump, ok := g.Sink(id).(*dump.DumpSink)
 	if !ok {
 		return nil
 	}
@@ -333,34 +358,16 @@ func (g *AppGroup) RecentLogsSink(id string) *dump.DumpSink {
 }
 
 func (g *AppGroup) ContainerMetricsSink(id string) *containermetric.ContainerMetricSink {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
-
-	containerMetrics, ok := g.sink(id).(*containermetric.ContainerMetricSink)
+	containerMetrics, ok := g.Sink(id).(*containermetric.ContainerMetricSink)
 	if !ok {
 		return nil
 	}
 	return containerMetrics
 }
 
-// sink needs to be called with read or write lock held.
-func (g *AppGroup) sink(id string) sinks.Sink {
-	wrapper, ok := g.wrappers[id]
-	if !ok || wrapper == nil {
-		return nil
-	}
-	return wrapper.Sink
-}
-
 func (g *AppGroup) SyslogSinks() []sinks.Sink {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
-
 	results := []sinks.Sink{}
-	for _, wrapper := range g.wrappers {
-		if wrapper == nil {
-			continue
-		}
+	for _, wrapper := range g.wrappers.Wrappers() {
 		_, ok := wrapper.Sink.(*syslog.SyslogSink)
 		if !ok {
 			continue
@@ -372,14 +379,8 @@ func (g *AppGroup) SyslogSinks() []sinks.Sink {
 }
 
 func (g *AppGroup) WebsocketSinks() []websocket.WebsocketSink {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
-
 	results := []websocket.WebsocketSink{}
-	for _, wrapper := range g.wrappers {
-		if wrapper == nil {
-			continue
-		}
+	for _, wrapper := range g.wrappers.Wrappers() {
 		sink, ok := wrapper.Sink.(*websocket.WebsocketSink)
 		if ok {
 			results = append(results, *sink)
@@ -390,73 +391,41 @@ func (g *AppGroup) WebsocketSinks() []websocket.WebsocketSink {
 }
 
 func (g *AppGroup) RemoveSink(sink sinks.Sink) bool {
-	g.mu.Lock()
-	defer g.mu.Unlock()
-	return g.removeSink(sink)
+	return g.wrappers.Remove(sink)
 }
 
 func (g *AppGroup) RemoveAllSinks() {
-	g.mu.Lock()
-	defer g.mu.Unlock()
-
-	for _, wrapper := range g.wrappers {
-		if wrapper == nil {
-			continue
-		}
-		g.removeSink(wrapper.Sink)
-	}
-}
-
-// removeSink needs to be called with write lock held.
-func (g *AppGroup) removeSink(sink sinks.Sink) bool {
-	wrapper, ok := g.wrappers[sink.Identifier()]
-	delete(g.wrappers, sink.Identifier())
-
-	if !ok || wrapper == nil {
-		return false
-	}
-
-	close(wrapper.InputChan)
-	return true
+	g.wrappers.RemoveAll()
 }
 
 func (g *AppGroup) IsEmpty() bool {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
-	return len(g.wrappers) == 0
+	return g.wrappers.Len() == 0
 }
 
 func (g *AppGroup) BroadcastMessage(msg *events.Envelope) {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
+	g.wrappers.Read(func(wrappers []*sink_wrapper.SinkWrapper) {
+		for _, wrapper := range wrappers {
+			select {
+			case wrapper.InputChan <- msg:
+			default:
+				// metric-documentation-v1: (sinks.dropped) Number of envelopes dropped
+				// while inserting envelope into sink.
+				g.batcher.BatchIncrementCounter("sinks.dropped")
 
-	for _, wrapper := range g.wrappers {
-		if wrapper == nil {
-			continue
-		}
-		select {
-		case wrapper.InputChan <- msg:
-		default:
-			// metric-documentation-v1: (sinks.dropped) Number of envelopes dropped
-			// while inserting envelope into sink.
-			g.batcher.BatchIncrementCounter("sinks.dropped")
-
-			// metric-documentation-v2: (loggregator.doppler.sinks.dropped)
-			// Number of envelopes dropped while inserting envelope into sink.
-			g.droppedMetric.Increment(1)
+				// metric-documentation-v2: (loggregator.doppler.sinks.dropped)
+				// Number of envelopes dropped while inserting envelope into sink.
+				g.droppedMetric.Increment(1)
+			}
 		}
-	}
+	})
 }
 
 func (g *AppGroup) BroadcastError(msg *events.Envelope) {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
-
-	for _, wrapper := range g.wrappers {
-		if wrapper == nil {
-			continue
-		}
-		if wrapper.Sink.ShouldReceiveErrors() {
+	g.wrappers.Read(func(wrappers []*sink_wrapper.SinkWrapper) {
+		for _, wrapper := range wrappers {
+			if !wrapper.Sink.ShouldReceiveErrors() {
+				continue
+			}
 			select {
 			case wrapper.InputChan <- msg:
 			default:
@@ -469,11 +438,9 @@ func (g *AppGroup) BroadcastError(msg *events.Envelope) {
 				g.errorMetric.Increment(1)
 			}
 		}
-	}
+	})
 }
 
 func (g *AppGroup) length() int {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
-	return len(g.wrappers)
+	return g.wrappers.Len()
 }
@@ -1,9 +1,12 @@
 package groupedsinks_test
 
 import (
+	"fmt"
+	"math/rand"
 	"net"
 	"net/url"
 	"sync"
+	"testing"
 	"time"
 
 	"code.cloudfoundry.org/loggregator/metricemitter/testhelper"
@@ -161,6 +164,36 @@ var _ = Describe("GroupedSink", func() {
 
 			Eventually(c).Should(BeClosed())
 		})
+
+		It("does not panic when sinks are removed while broadcasting", func() {
+			msg, _ := emitter.Wrap(factories.NewLogMessage(events.LogMessage_OUT, "test message", "app-id", "App"), "origin")
+			done := make(chan struct{})
+			stopped := make(chan struct{})
+			go func() {
+				defer close(stopped)
+				for {
+					select {
+					case <-done:
+						return
+					default:
+						groupedSinks.Broadcast("app-id", msg)
+					}
+				}
+			}()
+
+			for i := 0; i < 1000; i++ {
+				appSink := &fakeSink{sinkId: "sink1", appId: "app-id"}
+				firehoseSink := &fakeSink{sinkId: "sink1", appId: "firehose-a"}
+				groupedSinks.RegisterAppSink(make(chan *events.Envelope, 1), appSink)
+				groupedSinks.RegisterFirehoseSink(make(chan *events.Envelope, 1), firehoseSink)
+
+				groupedSinks.CloseAndDelete(appSink)
+				groupedSinks.CloseAndDeleteFirehose(firehoseSink)
+			}
+
+			close(done)
+			Eventually(stopped).Should(BeClosed())
+		})
 	})
 
 	Describe("BroadcastError", func() {
@@ -554,6 +587,77 @@ var _ = Describe("GroupedSink", func() {
 	})
 })
 
+func BenchmarkBroadcast(b *testing.B) {
+	b.Run("WithoutChurn", func(b *testing.B) {
+		benchmarkBroadcast(b, false)
+	})
+
+	b.Run("WithChurn", func(b *testing.B) {
+		benchmarkBroadcast(b, true)
+	})
+}
+
+// benchmarkBroadcast broadcasts to thousands of apps from every CPU. With
+// churn sinks are registered and removed continuously, like websocket
+// clients that reconnect.
+func benchmarkBroadcast(b *testing.B, churn bool) {
+	const apps = 5000
+
+	groupedSinks := groupedsinks.NewGroupedSinks(
+		&spyMetricBatcher{},
+		testhelper.NewMetricClient(),
+	)
+
+	appIDs := make([]string, apps)
+	msgs := make([]*events.Envelope, apps)
+	for i := range appIDs {
+		appIDs[i] = fmt.Sprintf("app-%d", i)
+		msgs[i], _ = emitter.Wrap(factories.NewLogMessage(events.LogMessage_OUT, "test message", appIDs[i], "App"), "origin")
+
+		sink := &fakeSink{sinkId: "sink", appId: appIDs[i]}
+		groupedSinks.RegisterAppSink(make(chan *events.Envelope, 1), sink)
+	}
+	groupedSinks.RegisterFirehoseSink(
+		make(chan *events.Envelope, 1),
+		&fakeSink{sinkId: "sink", appId: "firehose-a"},
+	)
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	if churn {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				sink := &fakeSink{sinkId: "churn", appId: appIDs[i%apps]}
+				groupedSinks.RegisterAppSink(make(chan *events.Envelope, 1), sink)
+				groupedSinks.CloseAndDelete(sink)
+			}
+		}()
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		i := rand.Intn(apps)
+		for pb.Next() {
+			i = (i + 1) % apps
+			groupedSinks.Broadcast(appIDs[i], msgs[i])
+		}
+	})
+
+	b.StopTimer()
+	close(done)
+	wg.Wait()
+}
+
 func dummyErrorHandler(_, _ string) {}
 
 type DummySyslogWriter struct{}
@@ -0,0 +1,206 @@
+package sink_wrapper
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"code.cloudfoundry.org/loggregator/doppler/internal/sinks"
+
+	"github.com/cloudfoundry/sonde-go/events"
+)
+
+// Set is a copy-on-write set of SinkWrappers keyed by sink identifier.
+// Readers never lock: they load an immutable snapshot of the wrappers.
+// Writers are serialized and replace the snapshot. The input channel of a
+// removed wrapper is only closed once every reader of the previous snapshot
+// has finished, so readers can send to the channels of a snapshot without
+// checking whether they are closed.
+type Set struct {
+	mu       sync.Mutex
+	snapshot atomic.Value
+}
+
+// snapshot is an immutable version of the wrappers. readers counts the
+// readers of the snapshot. Once a writer has replaced the snapshot it
+// retires it and waits for drained, which the last reader of a retired
+// snapshot closes. readers is first to keep it 64-bit aligned.
+type snapshot struct {
+	readers  int64
+	retired  int32
+	signaled int32
+	drained  chan struct{}
+
+	byID     map[string]*SinkWrapper
+	wrappers []*SinkWrapper
+}
+
+func newSnapshot(size int) *snapshot {
+	return &snapshot{
+		drained:  make(chan struct{}),
+		byID:     make(map[string]*SinkWrapper, size),
+		wrappers: make([]*SinkWrapper, 0, size),
+	}
+}
+
+func NewSet() *Set {
+	s := &Set{}
+	s.snapshot.Store(newSnapshot(0))
+
+	return s
+}
+
+// Add adds the sink with the given input channel. It returns false if a
+// sink with the same identifier already exists.
+func (s *Set) Add(sink sinks.Sink, in chan<- *events.Envelope) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old := s.load()
+	if _, ok := old.byID[sink.Identifier()]; ok {
+		return false
+	}
+
+	s.store(old, &SinkWrapper{
+		InputChan: in,
+		Sink:      sink,
+	}, nil)
+
+	return true
+}
+
+// Remove removes the sink and closes its input channel. It returns false
+// if the sink does not exist.
+func (s *Set) Remove(sink sinks.Sink) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old := s.load()
+	wrapper, ok := old.byID[sink.Identifier()]
+	if !ok {
+		return false
+	}
+
+	s.store(old, nil, wrapper)
+	close(wrapper.InputChan)
+
+	return true
+}
+
+// RemoveAll removes every sink and closes their input channels.
+func (s *Set) RemoveAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old := s.load()
+	s.replace(old, newSnapshot(0))
+
+	for _, w := range old.wrappers {
+		close(w.InputChan)
+	}
+}
+
+// Get returns the wrapper of the sink with the given identifier or nil.
+func (s *Set) Get(id string) *SinkWrapper {
+	return s.load().byID[id]
+}
+
+// Exists returns whether a sink with the identifier of the given sink
+// exists.
+func (s *Set) Exists(sink sinks.Sink) bool {
+	_, ok := s.load().byID[sink.Identifier()]
+	return ok
+}
+
+// Len returns the number of sinks.
+func (s *Set) Len() int {
+	return len(s.load().wrappers)
+}
+
+// Wrappers returns the current wrappers. The input channels of the
+// wrappers may be closed at any time and must not be sent to. Use Read to
+// send to them.
+func (s *Set) Wrappers() []*SinkWrapper {
+	return s.load().wrappers
+}
+
+// Read calls fn with the current wrappers. Their input channels are not
+// closed before fn returns. The slice must not be modified and fn must not
+// block or modify the Set.
+func (s *Set) Read(fn func(wrappers []*SinkWrapper)) {
+	snap := s.enter()
+	fn(snap.wrappers)
+	snap.leave()
+}
+
+func (s *Set) load() *snapshot {
+	return s.snapshot.Load().(*snapshot)
+}
+
+// store replaces the snapshot with a copy of old with the added wrapper
+// added and the removed wrapper removed. Either may be nil. It needs to be
+// called with the lock held.
+func (s *Set) store(old *snapshot, added, removed *SinkWrapper) {
+	next := newSnapshot(len(old.wrappers) + 1)
+
+	for _, w := range old.wrappers {
+		if w == removed {
+			continue
+		}
+		next.byID[w.Sink.Identifier()] = w
+		next.wrappers = append(next.wrappers, w)
+	}
+
+	if added != nil {
+		next.byID[added.Sink.Identifier()] = added
+		next.wrappers = append(next.wrappers, added)
+	}
+
+	s.replace(old, next)
+}
+
+// replace replaces the old snapshot with next and waits for the readers of
+// the old one. Every snapshot is retired, so that no reader holds a
+// snapshot older than the current one once replace returns. It needs to be
+// called with the lock held.
+func (s *Set) replace(old, next *snapshot) {
+	s.snapshot.Store(next)
+	old.retire()
+}
+
+// enter registers a reader with the current snapshot and returns it.
+func (s *Set) enter() *snapshot {
+	for {
+		snap := s.load()
+		atomic.AddInt64(&snap.readers, 1)
+
+		// A writer might have retired the snapshot and stopped waiting for
+		// its readers before the reader was registered.
+		if atomic.LoadInt32(&snap.retired) == 0 {
+			return snap
+		}
+		snap.leave()
+	}
+}
+
+// leave unregisters a reader. The last reader of a retired snapshot
+// signals the writer waiting for it.
+func (snap *snapshot) leave() {
+	if atomic.AddInt64(&snap.readers, -1) != 0 || atomic.LoadInt32(&snap.retired) == 0 {
+		return
+	}
+
+	if atomic.CompareAndSwapInt32(&snap.signaled, 0, 1) {
+		close(snap.drained)
+	}
+}
+
+// retire waits until every reader of the snapshot has finished. It needs
+// to be called after the snapshot has been replaced.
+func (snap *snapshot) retire() {
+	atomic.StoreInt32(&snap.retired, 1)
+	if atomic.LoadInt64(&snap.readers) == 0 {
+		return
+	}
+
+	<-snap.drained
+}
@@ -0,0 +1,161 @@
+package sink_wrapper_test
+
+import (
+	"fmt"
+	"sync"
+
+	"code.cloudfoundry.org/loggregator/doppler/internal/groupedsinks/sink_wrapper"
+	"code.cloudfoundry.org/loggregator/doppler/internal/sinks"
+
+	"github.com/cloudfoundry/sonde-go/events"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Set", func() {
+	var set *sink_wrapper.Set
+
+	BeforeEach(func() {
+		set = sink_wrapper.NewSet()
+	})
+
+	It("adds sinks once", func() {
+		sink := &fakeSink{sinkId: "sink-a"}
+		in := make(chan *events.Envelope)
+
+		Expect(set.Add(sink, in)).To(BeTrue())
+		Expect(set.Add(sink, in)).To(BeFalse())
+
+		Expect(set.Len()).To(Equal(1))
+		Expect(set.Exists(sink)).To(BeTrue())
+		Expect(set.Get("sink-a").Sink).To(Equal(sink))
+	})
+
+	It("removes sinks and closes their input channels", func() {
+		sinkA := &fakeSink{sinkId: "sink-a"}
+		sinkB := &fakeSink{sinkId: "sink-b"}
+		inA := make(chan *events.Envelope)
+		inB := make(chan *events.Envelope)
+		set.Add(sinkA, inA)
+		set.Add(sinkB, inB)
+
+		Expect(set.Remove(sinkA)).To(BeTrue())
+		Expect(set.Remove(sinkA)).To(BeFalse())
+
+		Expect(inA).To(BeClosed())
+		Expect(inB).ToNot(BeClosed())
+		Expect(set.Exists(sinkA)).To(BeFalse())
+		Expect(set.Get("sink-a")).To(BeNil())
+		Expect(set.Wrappers()).To(HaveLen(1))
+	})
+
+	It("removes all sinks and closes their input channels", func() {
+		inA := make(chan *events.Envelope)
+		inB := make(chan *events.Envelope)
+		set.Add(&fakeSink{sinkId: "sink-a"}, inA)
+		set.Add(&fakeSink{sinkId: "sink-b"}, inB)
+
+		set.RemoveAll()
+
+		Expect(set.Len()).To(Equal(0))
+		Expect(inA).To(BeClosed())
+		Expect(inB).To(BeClosed())
+	})
+
+	It("does not change the wrappers a reader has", func() {
+		set.Add(&fakeSink{sinkId: "sink-a"}, make(chan *events.Envelope, 1))
+		wrappers := set.Wrappers()
+
+		set.Add(&fakeSink{sinkId: "sink-b"}, make(chan *events.Envelope, 1))
+
+		Expect(wrappers).To(HaveLen(1))
+		Expect(set.Wrappers()).To(HaveLen(2))
+	})
+
+	It("does not close input channels while they are read", func() {
+		sink := &fakeSink{sinkId: "sink-a"}
+		in := make(chan *events.Envelope, 1)
+		set.Add(sink, in)
+
+		reading := make(chan struct{})
+		release := make(chan struct{})
+		go set.Read(func(wrappers []*sink_wrapper.SinkWrapper) {
+			close(reading)
+			<-release
+			wrappers[0].InputChan <- &events.Envelope{}
+		})
+		<-reading
+
+		removed := make(chan bool)
+		go func() {
+			removed <- set.Remove(sink)
+		}()
+
+		Consistently(removed).ShouldNot(Receive())
+		close(release)
+
+		Eventually(removed).Should(Receive(BeTrue()))
+		Expect(in).To(Receive())
+		Expect(in).To(BeClosed())
+	})
+
+	It("can be read while sinks are added and removed", func() {
+		done := make(chan struct{})
+		var wg sync.WaitGroup
+		defer wg.Wait()
+		defer close(done)
+
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					select {
+					case <-done:
+						return
+					default:
+					}
+
+					set.Read(func(wrappers []*sink_wrapper.SinkWrapper) {
+						for _, w := range wrappers {
+							select {
+							case w.InputChan <- &events.Envelope{}:
+							default:
+							}
+						}
+					})
+				}
+			}()
+		}
+
+		for i := 0; i < 1000; i++ {
+			sink := &fakeSink{sinkId: fmt.Sprintf("sink-%d", i%10)}
+			set.Add(sink, make(chan *events.Envelope, 1))
+			set.Remove(sink)
+		}
+	})
+})
+
+type fakeSink struct {
+	sinkId string
+}
+
+func (f *fakeSink) AppID() string {
+	return "app-id"
+}
+
+func (f *fakeSink) Run(<-chan *events.Envelope) {
+}
+
+func (f *fakeSink) Identifier() string {
+	return f.sinkId
+}
+
+func (f *fakeSink) ShouldReceiveErrors() bool {
+	return false
+}
+
+func (f *fakeSink) GetInstrumentationMetric() sinks.Metric {
+	return sinks.Metric{}
+}
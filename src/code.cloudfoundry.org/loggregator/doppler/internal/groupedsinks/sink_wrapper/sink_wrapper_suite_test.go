@@ -0,0 +1,13 @@
+package sink_wrapper_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestSinkWrapper(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "SinkWrapper Suite")
+}
@@ -11,9 +11,16 @@ import (
 
 type shardID string
 
+// Marshaler marshals the envelopes sent to subscriptions. The returned
+// bytes may be shared between subscriptions and must not be modified.
+type Marshaler interface {
+	Marshal(*events.Envelope) ([]byte, error)
+}
+
 type Router struct {
 	lock          sync.RWMutex
 	subscriptions map[filter]map[shardID][]DataSetter
+	marshaler     Marshaler
 }
 
 type filterType uint8
@@ -29,9 +36,13 @@ type filter struct {
 	envelopeType filterType
 }
 
-func NewRouter() *Router {
+// NewRouter returns a Router that marshals envelopes with the given
+// marshaler. Envelopes are only marshaled when a subscription matches and
+// the bytes are shared by every matching subscription.
+func NewRouter(m Marshaler) *Router {
 	return &Router{
 		subscriptions: make(map[filter]map[shardID][]DataSetter),
+		marshaler:     m,
 	}
 }
 
@@ -48,15 +59,26 @@ func (r *Router) SendTo(appID string, envelope *events.Envelope) {
 	r.lock.RLock()
 	defer r.lock.RUnlock()
 
-	data := r.marshal(envelope)
-
-	if data == nil {
+	if len(r.subscriptions) == 0 {
 		return
 	}
 
+	var data []byte
 	typedFilters := r.createTypedFilters(appID, envelope)
 	for _, typedFilter := range typedFilters {
-		for id, setters := range r.subscriptions[typedFilter] {
+		shards := r.subscriptions[typedFilter]
+		if len(shards) == 0 {
+			continue
+		}
+
+		if data == nil {
+			data = r.marshal(envelope)
+			if data == nil {
+				return
+			}
+		}
+
+		for id, setters := range shards {
 			r.writeToShard(id, setters, data)
 		}
 	}
@@ -73,25 +95,28 @@ func (r *Router) writeToShard(id shardID, setters []DataSetter, data []byte) {
 	setters[rand.Intn(len(setters))].Set(data)
 }
 
-func (r *Router) createTypedFilters(appID string, envelope *events.Envelope) []filter {
-	return []filter{
+// createTypedFilters returns an array rather than a slice so that it does
+// not escape to the heap.
+func (r *Router) createTypedFilters(appID string, envelope *events.Envelope) [4]filter {
+	envelopeType := r.filterTypeFromEnvelope(envelope)
+	return [4]filter{
 		{appID: appID, envelopeType: noType},
-		{appID: appID, envelopeType: r.filterTypeFromEnvelope(envelope)},
-		{appID: "", envelopeType: r.filterTypeFromEnvelope(envelope)},
+		{appID: appID, envelopeType: envelopeType},
+		{appID: "", envelopeType: envelopeType},
 		{},
 	}
 }
 
 func (r *Router) registerSetter(req *plumbing.SubscriptionRequest, dataSetter DataSetter) {
-	f := r.convertFilter(req)
+	for _, f := range r.convertFilters(req) {
+		m, ok := r.subscriptions[f]
+		if !ok {
+			m = make(map[shardID][]DataSetter)
+			r.subscriptions[f] = m
+		}
 
-	m, ok := r.subscriptions[f]
-	if !ok {
-		m = make(map[shardID][]DataSetter)
-		r.subscriptions[f] = m
+		m[shardID(req.ShardID)] = append(m[shardID(req.ShardID)], dataSetter)
 	}
-
-	m[shardID(req.ShardID)] = append(m[shardID(req.ShardID)], dataSetter)
 }
 
 func (r *Router) buildCleanup(req *plumbing.SubscriptionRequest, dataSetter DataSetter) func() {
@@ -99,29 +124,34 @@ func (r *Router) buildCleanup(req *plumbing.SubscriptionRequest, dataSetter Data
 		r.lock.Lock()
 		defer r.lock.Unlock()
 
-		f := r.convertFilter(req)
-		var setters []DataSetter
-		for _, s := range r.subscriptions[f][shardID(req.ShardID)] {
-			if s != dataSetter {
-				setters = append(setters, s)
-			}
+		for _, f := range r.convertFilters(req) {
+			r.removeSetter(f, shardID(req.ShardID), dataSetter)
 		}
+	}
+}
 
-		if len(setters) > 0 {
-			r.subscriptions[f][shardID(req.ShardID)] = setters
-			return
+func (r *Router) removeSetter(f filter, id shardID, dataSetter DataSetter) {
+	var setters []DataSetter
+	for _, s := range r.subscriptions[f][id] {
+		if s != dataSetter {
+			setters = append(setters, s)
 		}
+	}
 
-		delete(r.subscriptions[f], shardID(req.ShardID))
+	if len(setters) > 0 {
+		r.subscriptions[f][id] = setters
+		return
+	}
 
-		if len(r.subscriptions[f]) == 0 {
-			delete(r.subscriptions, f)
-		}
+	delete(r.subscriptions[f], id)
+
+	if len(r.subscriptions[f]) == 0 {
+		delete(r.subscriptions, f)
 	}
 }
 
 func (r *Router) marshal(envelope *events.Envelope) []byte {
-	data, err := envelope.Marshal()
+	data, err := r.marshaler.Marshal(envelope)
 	if err != nil {
 		return nil
 	}
@@ -129,20 +159,37 @@ func (r *Router) marshal(envelope *events.Envelope) []byte {
 	return data
 }
 
-func (r *Router) convertFilter(req *plumbing.SubscriptionRequest) filter {
+// convertFilters returns a filter for each app the request subscribes to.
+// A request with AppIDs is registered once per app.
+func (r *Router) convertFilters(req *plumbing.SubscriptionRequest) []filter {
 	if req.GetFilter() == nil {
-		return filter{}
-	}
-	f := filter{
-		appID: req.Filter.AppID,
+		return []filter{{}}
 	}
+
+	var envelopeType filterType
 	if req.GetFilter().GetLog() != nil {
-		f.envelopeType = logType
+		envelopeType = logType
 	}
 	if req.GetFilter().GetMetric() != nil {
-		f.envelopeType = metricType
+		envelopeType = metricType
 	}
-	return f
+
+	appIDs := req.GetFilter().GetAppIDs()
+	if len(appIDs) == 0 {
+		return []filter{{appID: req.GetFilter().GetAppID(), envelopeType: envelopeType}}
+	}
+
+	seen := make(map[string]bool, len(appIDs))
+	filters := make([]filter, 0, len(appIDs))
+	for _, appID := range appIDs {
+		if appID == "" || seen[appID] {
+			continue
+		}
+		seen[appID] = true
+		filters = append(filters, filter{appID: appID, envelopeType: envelopeType})
+	}
+
+	return filters
 }
 
 func (r *Router) filterTypeFromEnvelope(envelope *events.Envelope) filterType {
@@ -1,9 +1,14 @@
 package v1_test
 
 import (
+	"fmt"
+	"sync"
+	"testing"
+
 	"code.cloudfoundry.org/loggregator/plumbing"
 
 	"code.cloudfoundry.org/loggregator/doppler/internal/grpcmanager/v1"
+	"code.cloudfoundry.org/loggregator/doppler/internal/marshaler"
 
 	. "github.com/apoydence/eachers"
 	"github.com/cloudfoundry/sonde-go/events"
@@ -19,7 +24,8 @@ var _ = Describe("Router", func() {
 		counterEnvelopeBytes []byte
 		logEnvelopeBytes     []byte
 
-		router *v1.Router
+		marshalSpy *spyMarshaler
+		router     *v1.Router
 	)
 
 	BeforeEach(func() {
@@ -39,7 +45,8 @@ var _ = Describe("Router", func() {
 		logEnvelopeBytes, err = logEnvelope.Marshal()
 		Expect(err).ToNot(HaveOccurred())
 
-		router = v1.NewRouter()
+		marshalSpy = &spyMarshaler{}
+		router = v1.NewRouter(marshalSpy)
 	})
 
 	Context("with firehose subscriptions", func() {
@@ -198,6 +205,48 @@ var _ = Describe("Router", func() {
 		})
 	})
 
+	Context("with multiple app ID subscriptions", func() {
+		var (
+			stream  *mockDataSetter
+			cleanup func()
+		)
+
+		BeforeEach(func() {
+			stream = newMockDataSetter()
+			cleanup = router.Register(&plumbing.SubscriptionRequest{
+				ShardID: "some-sub-id",
+				Filter: &plumbing.Filter{
+					AppIDs: []string{"app-a", "app-b", "app-b"},
+					Message: &plumbing.Filter_Log{
+						Log: &plumbing.LogFilter{},
+					},
+				},
+			}, stream)
+		})
+
+		It("receives messages for each app once", func() {
+			router.SendTo("app-a", logEnvelope)
+			router.SendTo("app-b", logEnvelope)
+			router.SendTo("app-c", logEnvelope)
+
+			Expect(stream.SetCalled).To(HaveLen(2))
+		})
+
+		It("only receives messages of the filtered type", func() {
+			router.SendTo("app-a", counterEnvelope)
+
+			Expect(stream.SetCalled).To(BeEmpty())
+		})
+
+		It("does not receive messages after cleanup", func() {
+			cleanup()
+			router.SendTo("app-a", logEnvelope)
+			router.SendTo("app-b", logEnvelope)
+
+			Expect(stream.SetCalled).To(BeEmpty())
+		})
+	})
+
 	Context("with log filter subscriptions", func() {
 		var (
 			streamWithLogFilter *mockDataSetter
@@ -268,4 +317,116 @@ var _ = Describe("Router", func() {
 			)
 		})
 	})
+
+	Context("with subscriptions that do not match", func() {
+		It("does not marshal envelopes", func() {
+			router.SendTo("some-app-id", logEnvelope)
+
+			router.Register(&plumbing.SubscriptionRequest{
+				Filter: &plumbing.Filter{
+					AppID: "other-app-id",
+				},
+			}, newMockDataSetter())
+			router.SendTo("some-app-id", logEnvelope)
+
+			Expect(marshalSpy.count()).To(BeZero())
+		})
+	})
+
+	Context("with several matching subscriptions", func() {
+		It("marshals each envelope once", func() {
+			router.Register(&plumbing.SubscriptionRequest{}, newMockDataSetter())
+			router.Register(&plumbing.SubscriptionRequest{ShardID: "some-shard"}, newMockDataSetter())
+			router.Register(&plumbing.SubscriptionRequest{
+				Filter: &plumbing.Filter{
+					AppID: "some-app-id",
+				},
+			}, newMockDataSetter())
+
+			router.SendTo("some-app-id", logEnvelope)
+
+			Expect(marshalSpy.count()).To(Equal(1))
+		})
+	})
 })
+
+type spyMarshaler struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (m *spyMarshaler) Marshal(e *events.Envelope) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls++
+
+	return e.Marshal()
+}
+
+func (m *spyMarshaler) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls
+}
+
+func BenchmarkRouterSendToWithoutSubscriptions(b *testing.B) {
+	router := v1.NewRouter(marshaler.New(10000))
+	e := benchmarkEnvelope()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		router.SendTo("some-app-id", e)
+	}
+}
+
+func BenchmarkRouterSendToUnmatchedSubscriptions(b *testing.B) {
+	router := v1.NewRouter(marshaler.New(10000))
+	for i := 0; i < 100; i++ {
+		router.Register(&plumbing.SubscriptionRequest{
+			Filter: &plumbing.Filter{
+				AppID: fmt.Sprintf("other-app-%d", i),
+			},
+		}, nopSetter{})
+	}
+	e := benchmarkEnvelope()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		router.SendTo("some-app-id", e)
+	}
+}
+
+func BenchmarkRouterSendToFirehoses(b *testing.B) {
+	router := v1.NewRouter(marshaler.New(10000))
+	for i := 0; i < 10; i++ {
+		router.Register(&plumbing.SubscriptionRequest{
+			ShardID: fmt.Sprintf("shard-%d", i),
+		}, nopSetter{})
+	}
+	e := benchmarkEnvelope()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		router.SendTo("some-app-id", e)
+	}
+}
+
+type nopSetter struct{}
+
+func (nopSetter) Set([]byte) {}
+
+func benchmarkEnvelope() *events.Envelope {
+	return &events.Envelope{
+		Origin:    proto.String("some-origin"),
+		EventType: events.Envelope_LogMessage.Enum(),
+		LogMessage: &events.LogMessage{
+			Message:     []byte("some-message"),
+			MessageType: events.LogMessage_OUT.Enum(),
+			Timestamp:   proto.Int64(1),
+			AppId:       proto.String("some-app-id"),
+		},
+	}
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/cloudfoundry/dropsonde/metricbatcher"
 	"github.com/cloudfoundry/sonde-go/events"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 )
 
 type HealthRegistrar interface {
@@ -26,10 +28,19 @@ type DataSetter interface {
 	Set(data *events.Envelope)
 }
 
+// Limiter rejects oversized batches from Metron and truncates or splits
+// their envelopes.
+type Limiter interface {
+	BatchAllowed(size int) bool
+	Envelope(e *plumbing.Envelope) []*plumbing.Envelope
+}
+
 type IngressServer struct {
 	envelopeBuffer DataSetter
 	batcher        Batcher
+	limiter        Limiter
 	ingressMetric  *metricemitter.Counter
+	rejectedMetric *metricemitter.Counter
 	health         HealthRegistrar
 }
 
@@ -41,17 +52,23 @@ type MetricClient interface {
 func NewIngressServer(
 	envelopeBuffer DataSetter,
 	batcher Batcher,
+	limiter Limiter,
 	metricClient MetricClient,
 	health HealthRegistrar,
 ) *IngressServer {
 	ingressMetric := metricClient.NewCounter("ingress",
 		metricemitter.WithVersion(2, 0),
 	)
+	rejectedMetric := metricClient.NewCounter("rejected_requests",
+		metricemitter.WithVersion(2, 0),
+	)
 
 	return &IngressServer{
 		envelopeBuffer: envelopeBuffer,
 		batcher:        batcher,
+		limiter:        limiter,
 		ingressMetric:  ingressMetric,
+		rejectedMetric: rejectedMetric,
 		health:         health,
 	}
 }
@@ -66,24 +83,15 @@ func (i IngressServer) BatchSender(s plumbing.DopplerIngress_BatchSenderServer)
 			return err
 		}
 
+		if !i.limiter.BatchAllowed(len(v2eBatch.Batch)) {
+			// metric-documentation-v2: (loggregator.doppler.rejected_requests)
+			// Number of batches from Metron rejected as too large.
+			i.rejectedMetric.Increment(1)
+			return grpc.Errorf(codes.ResourceExhausted, "batch of %d envelopes exceeds the maximum batch size", len(v2eBatch.Batch))
+		}
+
 		for _, v2e := range v2eBatch.Batch {
-			envelopes := conversion.ToV1(v2e)
-			for _, v1e := range envelopes {
-				if v1e == nil || v1e.EventType == nil {
-					continue
-				}
-
-				i.envelopeBuffer.Set(v1e)
-
-				// metric-documentation-v1: (listeners.totalReceivedMessageCount)
-				// Total number of messages received by doppler.
-				i.batcher.BatchCounter("listeners.totalReceivedMessageCount").
-					Increment()
-
-				// metric-documentation-v2: (loggregator.doppler.ingress) Number of received
-				// envelopes from Metron on Doppler's v2 gRPC server
-				i.ingressMetric.Increment(1)
-			}
+			i.set(v2e)
 		}
 	}
 }
@@ -98,7 +106,13 @@ func (i IngressServer) Sender(s plumbing.DopplerIngress_SenderServer) error {
 			return err
 		}
 
-		envelopes := conversion.ToV1(v2e)
+		i.set(v2e)
+	}
+}
+
+func (i IngressServer) set(v2e *plumbing.Envelope) {
+	for _, limited := range i.limiter.Envelope(v2e) {
+		envelopes := conversion.ToV1(limited)
 		for _, v1e := range envelopes {
 			if v1e == nil || v1e.EventType == nil {
 				continue
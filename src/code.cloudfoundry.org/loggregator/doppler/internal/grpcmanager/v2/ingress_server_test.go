@@ -6,11 +6,14 @@ import (
 
 	"code.cloudfoundry.org/loggregator/doppler/internal/grpcmanager/v2"
 	"code.cloudfoundry.org/loggregator/metricemitter/testhelper"
+	"code.cloudfoundry.org/loggregator/plumbing/limits"
 	plumbing "code.cloudfoundry.org/loggregator/plumbing/v2"
 
 	"github.com/cloudfoundry/dropsonde/metricbatcher"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 )
 
 var _ = Describe("Ingress", func() {
@@ -19,6 +22,8 @@ var _ = Describe("Ingress", func() {
 		mockSender      *mockDopplerIngress_SenderServer
 		mockBatchSender *mockBatcherSenderServer
 		healthRegistrar *SpyHealthRegistrar
+		limitsConfig    limits.Config
+		metricClient    *testhelper.SpyMetricClient
 
 		ingestor *v2.IngressServer
 	)
@@ -28,11 +33,17 @@ var _ = Describe("Ingress", func() {
 		mockSender = newMockDopplerIngress_SenderServer()
 		mockBatchSender = newMockBatcherSenderServer()
 		healthRegistrar = newSpyHealthRegistrar()
+		limitsConfig = limits.Config{}
+	})
+
+	JustBeforeEach(func() {
+		metricClient = testhelper.NewMetricClient()
 
 		ingestor = v2.NewIngressServer(
 			mockDataSetter,
 			SpyBatcher{},
-			testhelper.NewMetricClient(),
+			limits.New(limitsConfig, metricClient),
+			metricClient,
 			healthRegistrar,
 		)
 	})
@@ -91,6 +102,51 @@ var _ = Describe("Ingress", func() {
 		Expect(mockDataSetter.SetCalled).To(HaveLen(0))
 	})
 
+	Context("with ingress limits", func() {
+		BeforeEach(func() {
+			limitsConfig = limits.Config{
+				MaxBatchSize:       1,
+				MaxLogPayloadBytes: 5,
+				SplitOversizedLogs: true,
+			}
+		})
+
+		It("rejects batches that are too large", func() {
+			e := &plumbing.Envelope{
+				Message: &plumbing.Envelope_Log{
+					Log: &plumbing.Log{
+						Payload: []byte("hello"),
+					},
+				},
+			}
+			mockBatchSender.RecvOutput.Ret0 <- &plumbing.EnvelopeBatch{
+				Batch: []*plumbing.Envelope{e, e},
+			}
+			mockBatchSender.RecvOutput.Ret1 <- nil
+
+			err := ingestor.BatchSender(mockBatchSender)
+			Expect(grpc.Code(err)).To(Equal(codes.ResourceExhausted))
+			Expect(mockDataSetter.SetCalled).To(HaveLen(0))
+			Expect(metricClient.GetDelta("rejected_requests")).To(Equal(uint64(1)))
+		})
+
+		It("splits oversized logs", func() {
+			mockSender.RecvOutput.Ret0 <- &plumbing.Envelope{
+				Message: &plumbing.Envelope_Log{
+					Log: &plumbing.Log{
+						Payload: []byte("hello world"),
+					},
+				},
+			}
+			mockSender.RecvOutput.Ret1 <- nil
+			mockSender.RecvOutput.Ret0 <- nil
+			mockSender.RecvOutput.Ret1 <- io.EOF
+
+			ingestor.Sender(mockSender)
+			Expect(mockDataSetter.SetCalled).To(HaveLen(3))
+		})
+	})
+
 	Describe("health monitoring", func() {
 		Describe("Sender()", func() {
 			It("increments and decrements the number of ingress streams", func() {
@@ -15,13 +15,96 @@ func (err ResolutionFailure) Error() string {
 	return fmt.Sprintf("Resolving host failed: %s", string(err))
 }
 
+// IPRange is either an inclusive range from Start to End or a CIDR block
+// such as 10.0.0.0/8.
 type IPRange struct {
 	Start string
 	End   string
+	CIDR  string
+}
+
+// Ranges is a set of validated IPRanges. Their addresses and CIDR blocks
+// are parsed once.
+type Ranges struct {
+	nets   []*net.IPNet
+	bounds [][2]net.IP
+}
+
+// NewRanges validates and parses the ranges.
+func NewRanges(ranges []IPRange) (*Ranges, error) {
+	if err := ValidateIpAddresses(ranges); err != nil {
+		return nil, err
+	}
+
+	r := &Ranges{}
+	for _, ipRange := range ranges {
+		if ipRange.CIDR != "" {
+			_, ipNet, _ := net.ParseCIDR(ipRange.CIDR)
+			r.nets = append(r.nets, ipNet)
+			continue
+		}
+
+		r.bounds = append(r.bounds, [2]net.IP{
+			net.ParseIP(ipRange.Start),
+			net.ParseIP(ipRange.End),
+		})
+	}
+
+	return r, nil
+}
+
+// Contains reports whether the IP is within any of the ranges.
+func (r *Ranges) Contains(ip net.IP) bool {
+	for _, ipNet := range r.nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+
+	ip = ip.To16()
+	for _, b := range r.bounds {
+		if bytes.Compare(ip, b[0]) >= 0 && bytes.Compare(ip, b[1]) <= 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// URLOutside reports whether none of the IPs the host of the URL resolves
+// to are within the ranges.
+func (r *Ranges) URLOutside(testURL url.URL) (bool, error) {
+	if len(testURL.Host) == 0 {
+		return false, errors.New(fmt.Sprintf("Incomplete URL %s. "+
+			"This could be caused by an URL without slashes or protocol.", testURL))
+	}
+
+	host := strings.Split(testURL.Host, ":")[0]
+	ipAddresses, err := LookupIP(host)
+	if err != nil {
+		return false, err
+	}
+
+	for _, ip := range ipAddresses {
+		if r.Contains(ip) {
+			return false, nil
+		}
+	}
+	return true, nil
 }
 
 func ValidateIpAddresses(ranges []IPRange) error {
 	for _, ipRange := range ranges {
+		if ipRange.CIDR != "" {
+			if ipRange.Start != "" || ipRange.End != "" {
+				return fmt.Errorf("Invalid Blacklist IP Range: CIDR %s cannot be combined with Start and End", ipRange.CIDR)
+			}
+			if _, _, err := net.ParseCIDR(ipRange.CIDR); err != nil {
+				return fmt.Errorf("Invalid CIDR for Blacklist IP Range: %s", ipRange.CIDR)
+			}
+			continue
+		}
+
 		startIP := net.ParseIP(ipRange.Start)
 		endIP := net.ParseIP(ipRange.End)
 		if startIP == nil {
@@ -38,25 +121,23 @@ func ValidateIpAddresses(ranges []IPRange) error {
 }
 
 func IpOutsideOfRanges(testURL url.URL, ranges []IPRange) (bool, error) {
-	if len(testURL.Host) == 0 {
-		return false, errors.New(fmt.Sprintf("Incomplete URL %s. "+
-			"This could be caused by an URL without slashes or protocol.", testURL))
+	r, err := NewRanges(ranges)
+	if err != nil {
+		return false, err
 	}
+	return r.URLOutside(testURL)
+}
 
-	host := strings.Split(testURL.Host, ":")[0]
-	ipAddress := net.ParseIP(host)
-	if ipAddress == nil {
-		ipAddr, err := net.ResolveIPAddr("ip", host)
-		if err != nil {
-			return false, ResolutionFailure(err.Error())
-		}
-		ipAddress = net.ParseIP(ipAddr.String())
+// LookupIP returns all IPs of the host. A host that is already an IP is
+// returned as is.
+func LookupIP(host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
 	}
 
-	for _, ipRange := range ranges {
-		if bytes.Compare(ipAddress, net.ParseIP(ipRange.Start)) >= 0 && bytes.Compare(ipAddress, net.ParseIP(ipRange.End)) <= 0 {
-			return false, nil
-		}
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return nil, ResolutionFailure(err.Error())
 	}
-	return true, nil
+	return ips, nil
 }
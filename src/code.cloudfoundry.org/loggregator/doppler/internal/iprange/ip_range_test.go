@@ -2,6 +2,7 @@ package iprange_test
 
 import (
 	"fmt"
+	"net"
 	"net/url"
 
 	"code.cloudfoundry.org/loggregator/doppler/internal/iprange"
@@ -51,6 +52,60 @@ var _ = Describe("IPRange", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
+		It("accepts CIDR blocks", func() {
+			ranges := []iprange.IPRange{{CIDR: "10.0.0.0/8"}, {CIDR: "fd00::/8"}}
+			err := iprange.ValidateIpAddresses(ranges)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("validates CIDR blocks", func() {
+			ranges := []iprange.IPRange{{CIDR: "10.0.0.0/33"}}
+			err := iprange.ValidateIpAddresses(ranges)
+			Expect(err).To(MatchError("Invalid CIDR for Blacklist IP Range: 10.0.0.0/33"))
+		})
+
+		It("does not allow a CIDR block with a start and end", func() {
+			ranges := []iprange.IPRange{{CIDR: "10.0.0.0/8", Start: "10.0.0.1", End: "10.0.0.2"}}
+			err := iprange.ValidateIpAddresses(ranges)
+			Expect(err).To(HaveOccurred())
+		})
+
+	})
+
+	Describe("Ranges", func() {
+		newRanges := func(ranges ...iprange.IPRange) *iprange.Ranges {
+			r, err := iprange.NewRanges(ranges)
+			Expect(err).ToNot(HaveOccurred())
+			return r
+		}
+
+		It("matches IPs within a start and end range", func() {
+			r := newRanges(iprange.IPRange{Start: "10.10.10.10", End: "10.10.10.20"})
+
+			Expect(r.Contains(net.ParseIP("10.10.10.15"))).To(BeTrue())
+			Expect(r.Contains(net.ParseIP("10.10.10.21"))).To(BeFalse())
+		})
+
+		It("matches IPs within a CIDR block", func() {
+			r := newRanges(iprange.IPRange{CIDR: "169.254.0.0/16"})
+
+			Expect(r.Contains(net.ParseIP("169.254.169.254"))).To(BeTrue())
+			Expect(r.Contains(net.ParseIP("169.255.0.1"))).To(BeFalse())
+		})
+
+		It("matches IPv4 addresses in their 4 byte form", func() {
+			r := newRanges(iprange.IPRange{Start: "10.10.10.10", End: "10.10.10.20"})
+
+			Expect(r.Contains(net.IPv4(10, 10, 10, 15).To4())).To(BeTrue())
+		})
+
+		It("rejects invalid ranges", func() {
+			_, err := iprange.NewRanges([]iprange.IPRange{{CIDR: "10.0.0.0/33"}})
+			Expect(err).To(HaveOccurred())
+
+			_, err = iprange.NewRanges([]iprange.IPRange{{Start: "10.0.0.2", End: "10.0.0.1"}})
+			Expect(err).To(HaveOccurred())
+		})
 	})
 
 	Describe("IpOutsideOfRanges", func() {
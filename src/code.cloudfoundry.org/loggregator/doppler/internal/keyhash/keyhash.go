@@ -0,0 +1,19 @@
+// Package keyhash hashes the keys envelopes are sharded by.
+package keyhash
+
+const (
+	offset32 = 2166136261
+	prime32  = 16777619
+)
+
+// Sum32 returns the 32 bit FNV-1a hash of the key. It is computed inline as
+// hash/fnv would allocate a hash for every key.
+func Sum32(key string) uint32 {
+	h := uint32(offset32)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= prime32
+	}
+
+	return h
+}
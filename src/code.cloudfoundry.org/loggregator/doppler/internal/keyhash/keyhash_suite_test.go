@@ -0,0 +1,13 @@
+package keyhash_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestKeyhash(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Keyhash Suite")
+}
@@ -0,0 +1,21 @@
+package keyhash_test
+
+import (
+	"hash/fnv"
+
+	"code.cloudfoundry.org/loggregator/doppler/internal/keyhash"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sum32", func() {
+	It("matches hash/fnv", func() {
+		for _, key := range []string{"", "a", "some-app-id", "9a3f6c1e-1bd4-4b5e-8c0e-5f1c0a4d2b7e"} {
+			h := fnv.New32a()
+			h.Write([]byte(key))
+
+			Expect(keyhash.Sum32(key)).To(Equal(h.Sum32()))
+		}
+	})
+})
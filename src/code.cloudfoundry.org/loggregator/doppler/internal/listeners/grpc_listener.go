@@ -5,7 +5,6 @@ import (
 	"log"
 	"net"
 
-	"code.cloudfoundry.org/loggregator/diodes"
 	"code.cloudfoundry.org/loggregator/doppler/app"
 	"code.cloudfoundry.org/loggregator/doppler/internal/grpcmanager/v1"
 	"code.cloudfoundry.org/loggregator/doppler/internal/grpcmanager/v2"
@@ -13,10 +12,10 @@ import (
 	"code.cloudfoundry.org/loggregator/healthendpoint"
 	"code.cloudfoundry.org/loggregator/metricemitter"
 	plumbingv1 "code.cloudfoundry.org/loggregator/plumbing"
+	"code.cloudfoundry.org/loggregator/plumbing/limits"
 	plumbingv2 "code.cloudfoundry.org/loggregator/plumbing/v2"
 
-	"github.com/cloudfoundry/dropsonde/metricbatcher"
-
+	"github.com/cloudfoundry/sonde-go/events"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 )
@@ -26,6 +25,11 @@ type MetricClient interface {
 	NewCounter(name string, opts ...metricemitter.MetricOption) *metricemitter.Counter
 }
 
+// EnvelopeBuffer receives the envelopes of the ingress servers.
+type EnvelopeBuffer interface {
+	Set(*events.Envelope)
+}
+
 type GRPCListener struct {
 	listener net.Listener
 	server   *grpc.Server
@@ -35,8 +39,9 @@ func NewGRPCListener(
 	reg v1.Registrar,
 	sinkmanager *sinkmanager.SinkManager,
 	conf app.GRPC,
-	envelopeBuffer *diodes.ManyToOneEnvelope,
-	batcher *metricbatcher.MetricBatcher,
+	envelopeBuffer EnvelopeBuffer,
+	batcher Batcher,
+	limiter *limits.Limiter,
 	metricClient MetricClient,
 	health *healthendpoint.Registrar,
 ) (*GRPCListener, error) {
@@ -79,7 +84,7 @@ func NewGRPCListener(
 	// v2 ingress
 	plumbingv2.RegisterDopplerIngressServer(
 		grpcServer,
-		v2.NewIngressServer(envelopeBuffer, batcher, metricClient, health),
+		v2.NewIngressServer(envelopeBuffer, batcher, limiter, metricClient, health),
 	)
 
 	return &GRPCListener{
@@ -0,0 +1,93 @@
+package marshaler
+
+import (
+	"sync"
+	"unsafe"
+
+	"github.com/cloudfoundry/sonde-go/events"
+)
+
+const numShards = 32
+
+// Cache marshals each envelope at most once while it is cached and shares
+// the resulting bytes between every caller. Envelopes are cached by pointer
+// and evicted in the order they were added. Neither the envelope nor the
+// bytes may be modified once the envelope has been marshaled.
+type Cache struct {
+	shards [numShards]shard
+}
+
+type shard struct {
+	mu      sync.Mutex
+	entries map[*events.Envelope]*entry
+	ring    []*events.Envelope
+	next    int
+}
+
+type entry struct {
+	once sync.Once
+	data []byte
+	err  error
+}
+
+// New returns a Cache that holds about size envelopes.
+func New(size int) *Cache {
+	perShard := size / numShards
+	if perShard < 1 {
+		perShard = 1
+	}
+
+	c := &Cache{}
+	for i := range c.shards {
+		c.shards[i].entries = make(map[*events.Envelope]*entry, perShard)
+		c.shards[i].ring = make([]*events.Envelope, perShard)
+	}
+
+	return c
+}
+
+// Marshal returns the marshaled envelope. The envelope is only marshaled
+// if it is not cached. Concurrent calls for the same envelope marshal it
+// once.
+func (c *Cache) Marshal(e *events.Envelope) ([]byte, error) {
+	en := c.shard(e).entry(e)
+	en.once.Do(func() {
+		en.data, en.err = e.Marshal()
+	})
+
+	return en.data, en.err
+}
+
+func (c *Cache) shard(e *events.Envelope) *shard {
+	// Envelopes are at least 8 byte aligned so the lower bits of their
+	// address do not help to spread them across shards.
+	addr := uintptr(unsafe.Pointer(e)) >> 3
+	return &c.shards[addr%numShards]
+}
+
+func (s *shard) entry(e *events.Envelope) *entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if en, ok := s.entries[e]; ok {
+		return en
+	}
+
+	if evicted := s.ring[s.next]; evicted != nil {
+		delete(s.entries, evicted)
+	}
+	s.ring[s.next] = e
+	s.next = (s.next + 1) % len(s.ring)
+
+	en := &entry{}
+	s.entries[e] = en
+
+	return en
+}
+
+// Uncached marshals every envelope without caching it.
+type Uncached struct{}
+
+func (Uncached) Marshal(e *events.Envelope) ([]byte, error) {
+	return e.Marshal()
+}
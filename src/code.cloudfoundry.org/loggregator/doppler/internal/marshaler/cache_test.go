@@ -0,0 +1,120 @@
+package marshaler_test
+
+import (
+	"sync"
+	"testing"
+
+	"code.cloudfoundry.org/loggregator/doppler/internal/marshaler"
+
+	"github.com/cloudfoundry/sonde-go/events"
+	"github.com/gogo/protobuf/proto"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cache", func() {
+	It("marshals envelopes", func() {
+		c := marshaler.New(10)
+		e := buildEnvelope("some-app")
+
+		data, err := c.Marshal(e)
+		Expect(err).ToNot(HaveOccurred())
+
+		var actual events.Envelope
+		Expect(proto.Unmarshal(data, &actual)).To(Succeed())
+		Expect(actual.GetLogMessage().GetAppId()).To(Equal("some-app"))
+	})
+
+	It("shares the bytes of an envelope between callers", func() {
+		c := marshaler.New(10)
+		e := buildEnvelope("some-app")
+
+		var wg sync.WaitGroup
+		results := make(chan []byte, 10)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				data, _ := c.Marshal(e)
+				results <- data
+			}()
+		}
+		wg.Wait()
+		close(results)
+
+		first := <-results
+		for data := range results {
+			Expect(&data[0]).To(BeIdenticalTo(&first[0]))
+		}
+	})
+
+	It("does not share bytes between envelopes", func() {
+		c := marshaler.New(10)
+
+		a, err := c.Marshal(buildEnvelope("app-a"))
+		Expect(err).ToNot(HaveOccurred())
+		b, err := c.Marshal(buildEnvelope("app-b"))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(a).ToNot(Equal(b))
+	})
+
+	It("marshals envelopes again once they are evicted", func() {
+		c := marshaler.New(1)
+		e := buildEnvelope("some-app")
+
+		first, err := c.Marshal(e)
+		Expect(err).ToNot(HaveOccurred())
+
+		for i := 0; i < 1000; i++ {
+			c.Marshal(buildEnvelope("other-app"))
+		}
+
+		second, err := c.Marshal(e)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(second).To(Equal(first))
+		Expect(&second[0]).ToNot(BeIdenticalTo(&first[0]))
+	})
+
+	It("returns errors of invalid envelopes", func() {
+		c := marshaler.New(10)
+
+		_, err := c.Marshal(&events.Envelope{})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+func BenchmarkCacheMarshalShared(b *testing.B) {
+	c := marshaler.New(10000)
+	e := buildEnvelope("some-app")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Marshal(e)
+	}
+}
+
+func BenchmarkUncachedMarshal(b *testing.B) {
+	e := buildEnvelope("some-app")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		marshaler.Uncached{}.Marshal(e)
+	}
+}
+
+func buildEnvelope(appID string) *events.Envelope {
+	return &events.Envelope{
+		Origin:    proto.String("some-origin"),
+		EventType: events.Envelope_LogMessage.Enum(),
+		LogMessage: &events.LogMessage{
+			Message:     []byte("some-message"),
+			MessageType: events.LogMessage_OUT.Enum(),
+			Timestamp:   proto.Int64(1),
+			AppId:       proto.String(appID),
+		},
+	}
+}
@@ -0,0 +1,13 @@
+package marshaler_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestMarshaler(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Marshaler Suite")
+}
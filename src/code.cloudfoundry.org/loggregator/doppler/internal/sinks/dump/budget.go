@@ -0,0 +1,248 @@
+package dump
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// lowWaterMark is the fraction of the maximum bytes the recent logs are
+// evicted down to once the budget is exceeded. Evicting below the maximum
+// keeps evictions from happening for every message.
+const lowWaterMark = 0.95
+
+// Budget bounds the memory used by the recent logs of every app. The size
+// of a message is its marshaled size. Every app is limited to the bytes per
+// app. Once the bytes of all apps exceed the maximum, the oldest messages
+// of the apps with the highest eviction score are evicted. The score is the
+// bytes of an app multiplied by the seconds since it was last written to,
+// so large apps and apps that have stopped logging are evicted first.
+//
+// The bytes of all apps are updated together with the bytes of a sink
+// under the lock of the sink, so they are never less than the bytes of the
+// registered sinks and never negative.
+//
+// Budget is a prometheus.Collector that reports the bytes, the number of
+// evicted messages and the retention span of the apps.
+type Budget struct {
+	bytes     int64
+	evictions uint64
+	evicting  int32
+
+	maxBytes    int64
+	maxAppBytes int64
+
+	mu    sync.Mutex
+	sinks map[*DumpSink]struct{}
+
+	bytesDesc     *prometheus.Desc
+	evictionsDesc *prometheus.Desc
+	retentionDesc *prometheus.Desc
+}
+
+// NewBudget returns a Budget of maxBytes for all apps and maxAppBytes per
+// app. A limit of zero disables it.
+func NewBudget(maxBytes, maxAppBytes int64) *Budget {
+	return &Budget{
+		maxBytes:    maxBytes,
+		maxAppBytes: maxAppBytes,
+		sinks:       make(map[*DumpSink]struct{}),
+
+		// metric-documentation-health: (recentLogCacheBytes)
+		// Marshaled size of the recent logs of all apps
+		bytesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("loggregator", "doppler", "recentLogCacheBytes"),
+			"Marshaled size of the recent logs of all apps",
+			nil, nil,
+		),
+		// metric-documentation-health: (recentLogCacheEvictions)
+		// Number of recent log messages evicted to stay within the byte budget
+		evictionsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("loggregator", "doppler", "recentLogCacheEvictions"),
+			"Number of recent log messages evicted to stay within the byte budget",
+			nil, nil,
+		),
+		// metric-documentation-health: (recentLogRetentionSeconds)
+		// Time between the oldest and newest recent log message of the apps
+		retentionDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("loggregator", "doppler", "recentLogRetentionSeconds"),
+			"Time between the oldest and newest recent log message of the apps",
+			nil, nil,
+		),
+	}
+}
+
+// AppRetention is the retention of the recent logs of an app.
+type AppRetention struct {
+	AppID         string  `json:"app_id"`
+	Bytes         int64   `json:"bytes"`
+	Messages      int     `json:"messages"`
+	RetentionSpan float64 `json:"retention_span_seconds"`
+}
+
+// Bytes returns the bytes of the recent logs of all apps.
+func (b *Budget) Bytes() int64 {
+	return atomic.LoadInt64(&b.bytes)
+}
+
+// Retention returns the retention of the n apps with the shortest retention
+// spans.
+func (b *Budget) Retention(n int) []AppRetention {
+	retention := b.retention()
+	if n < len(retention) {
+		retention = retention[:n]
+	}
+
+	return retention
+}
+
+// retention returns the retention of every app sorted by retention span.
+func (b *Budget) retention() []AppRetention {
+	sinks := b.registered()
+	retention := make([]AppRetention, 0, len(sinks))
+	for _, s := range sinks {
+		messages, span := s.retention()
+		retention = append(retention, AppRetention{
+			AppID:         s.appId,
+			Bytes:         atomic.LoadInt64(&s.bytes),
+			Messages:      messages,
+			RetentionSpan: span.Seconds(),
+		})
+	}
+
+	sort.Slice(retention, func(i, j int) bool {
+		if retention[i].RetentionSpan == retention[j].RetentionSpan {
+			return retention[i].AppID < retention[j].AppID
+		}
+		return retention[i].RetentionSpan < retention[j].RetentionSpan
+	})
+
+	return retention
+}
+
+// registered returns the registered sinks.
+func (b *Budget) registered() []*DumpSink {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sinks := make([]*DumpSink, 0, len(b.sinks))
+	for s := range b.sinks {
+		sinks = append(sinks, s)
+	}
+
+	return sinks
+}
+
+// Describe implements prometheus.Collector.
+func (b *Budget) Describe(ch chan<- *prometheus.Desc) {
+	ch <- b.bytesDesc
+	ch <- b.evictionsDesc
+	ch <- b.retentionDesc
+}
+
+// Collect implements prometheus.Collector. The retention spans of the apps
+// are reported as a summary over all apps.
+func (b *Budget) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(
+		b.bytesDesc,
+		prometheus.GaugeValue,
+		float64(b.Bytes()),
+	)
+	ch <- prometheus.MustNewConstMetric(
+		b.evictionsDesc,
+		prometheus.CounterValue,
+		float64(atomic.LoadUint64(&b.evictions)),
+	)
+
+	retention := b.retention()
+	var sum float64
+	for _, r := range retention {
+		sum += r.RetentionSpan
+	}
+
+	quantiles := make(map[float64]float64)
+	if len(retention) > 0 {
+		for _, q := range []float64{0.05, 0.5, 0.95} {
+			quantiles[q] = retention[int(q*float64(len(retention)-1))].RetentionSpan
+		}
+	}
+
+	ch <- prometheus.MustNewConstSummary(
+		b.retentionDesc,
+		uint64(len(retention)),
+		sum,
+		quantiles,
+	)
+}
+
+func (b *Budget) register(s *DumpSink) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sinks[s] = struct{}{}
+}
+
+func (b *Budget) unregister(s *DumpSink) {
+	b.mu.Lock()
+	delete(b.sinks, s)
+	b.mu.Unlock()
+
+	s.release()
+}
+
+// enforce evicts messages if the budget is exceeded. Only one caller evicts
+// at a time. The others return right away as the eviction frees enough
+// bytes for them as well.
+func (b *Budget) enforce() {
+	if b.maxBytes == 0 || atomic.LoadInt64(&b.bytes) <= b.maxBytes {
+		return
+	}
+
+	if !atomic.CompareAndSwapInt32(&b.evicting, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&b.evicting, 0)
+
+	b.evict(atomic.LoadInt64(&b.bytes) - int64(float64(b.maxBytes)*lowWaterMark))
+}
+
+// evict evicts at least the given bytes from the sinks with the highest
+// score.
+func (b *Budget) evict(bytes int64) {
+	type candidate struct {
+		sink  *DumpSink
+		score float64
+	}
+
+	sinks := b.registered()
+	now := time.Now().UnixNano()
+	candidates := make([]candidate, 0, len(sinks))
+	for _, s := range sinks {
+		idle := time.Duration(now - atomic.LoadInt64(&s.lastWrite))
+		candidates = append(candidates, candidate{
+			sink:  s,
+			score: float64(atomic.LoadInt64(&s.bytes)) * (idle.Seconds() + 1),
+		})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	for _, c := range candidates {
+		if bytes <= 0 {
+			return
+		}
+
+		freed, messages := c.sink.evict(bytes)
+		b.evicted(messages)
+		bytes -= freed
+	}
+}
+
+func (b *Budget) evicted(messages int) {
+	atomic.AddUint64(&b.evictions, uint64(messages))
+}
@@ -0,0 +1,271 @@
+package dump_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/doppler/internal/sinks/dump"
+
+	"github.com/cloudfoundry/dropsonde/emitter"
+	"github.com/cloudfoundry/dropsonde/factories"
+	"github.com/cloudfoundry/sonde-go/events"
+	"github.com/gogo/protobuf/proto"
+	"github.com/prometheus/client_golang/prometheus"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Budget", func() {
+	var (
+		health *SpyHealthRegistrar
+		size   int64
+	)
+
+	BeforeEach(func() {
+		health = newSpyHealthRegistrar()
+		size = int64(proto.Size(logMessage("0")))
+	})
+
+	It("limits the bytes of an app", func() {
+		budget := dump.NewBudget(0, 3*size)
+		sink := dump.NewDumpSink("app-a", 100, time.Second, health, dump.WithBudget(budget))
+		input, done := runSink(sink)
+
+		for i := 0; i < 5; i++ {
+			input <- logMessage(strconv.Itoa(i))
+		}
+		close(input)
+		<-done
+
+		Expect(messages(sink.Dump())).To(Equal([]string{"2", "3", "4"}))
+	})
+
+	It("evicts the oldest messages of the largest app first", func() {
+		budget := dump.NewBudget(10*size, 0)
+		small := dump.NewDumpSink("small", 100, time.Second, health, dump.WithBudget(budget))
+		large := dump.NewDumpSink("large", 100, time.Second, health, dump.WithBudget(budget))
+		smallInput, _ := runSink(small)
+		largeInput, _ := runSink(large)
+		defer close(smallInput)
+		defer close(largeInput)
+
+		smallInput <- logMessage("0")
+		Eventually(small.Dump).Should(HaveLen(1))
+		for i := 0; i < 10; i++ {
+			largeInput <- logMessage(strconv.Itoa(i))
+		}
+
+		Eventually(budget.Bytes).Should(BeNumerically("<=", 10*size))
+		Eventually(func() []string {
+			return messages(large.Dump())
+		}).Should(HaveLen(8))
+		Expect(messages(large.Dump())[0]).To(Equal("2"))
+		Expect(messages(small.Dump())).To(Equal([]string{"0"}))
+	})
+
+	It("releases the bytes of sinks that stop", func() {
+		budget := dump.NewBudget(0, 0)
+		sink := dump.NewDumpSink("app-a", 100, time.Second, health, dump.WithBudget(budget))
+		input, done := runSink(sink)
+
+		input <- logMessage("0")
+		Eventually(budget.Bytes).Should(Equal(size))
+
+		close(input)
+		<-done
+		Expect(budget.Bytes()).To(BeZero())
+	})
+
+	It("never goes negative while sinks evict and stop concurrently", func() {
+		budget := dump.NewBudget(5*size, 3*size)
+
+		stop := make(chan struct{})
+		negative := make(chan int64, 1)
+		go func() {
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				if b := budget.Bytes(); b < 0 {
+					select {
+					case negative <- b:
+					default:
+					}
+				}
+			}
+		}()
+
+		var dones []<-chan struct{}
+		for i := 0; i < 10; i++ {
+			sink := dump.NewDumpSink(strconv.Itoa(i), 100, time.Second, health, dump.WithBudget(budget))
+			input, done := runSink(sink)
+			dones = append(dones, done)
+
+			go func() {
+				for j := 0; j < 100; j++ {
+					input <- logMessage(strconv.Itoa(j))
+				}
+				close(input)
+			}()
+		}
+
+		for _, done := range dones {
+			<-done
+		}
+		close(stop)
+
+		Expect(negative).ToNot(Receive())
+		Expect(budget.Bytes()).To(BeZero())
+	})
+
+	It("returns the apps with the shortest retention span", func() {
+		budget := dump.NewBudget(0, 0)
+		short := dump.NewDumpSink("short", 100, time.Second, health, dump.WithBudget(budget))
+		long := dump.NewDumpSink("long", 100, time.Second, health, dump.WithBudget(budget))
+		shortInput, _ := runSink(short)
+		longInput, _ := runSink(long)
+		defer close(shortInput)
+		defer close(longInput)
+
+		longInput <- logMessage("0")
+		time.Sleep(10 * time.Millisecond)
+		longInput <- logMessage("1")
+		shortInput <- logMessage("0")
+
+		Eventually(func() int {
+			return len(budget.Retention(10))
+		}).Should(Equal(2))
+		Eventually(func() []string {
+			var ids []string
+			for _, r := range budget.Retention(10) {
+				ids = append(ids, r.AppID)
+			}
+			return ids
+		}).Should(Equal([]string{"short", "long"}))
+
+		retention := budget.Retention(1)
+		Expect(retention).To(HaveLen(1))
+		Expect(retention[0].AppID).To(Equal("short"))
+		Expect(retention[0].Messages).To(Equal(1))
+		Expect(retention[0].Bytes).To(Equal(size))
+		Expect(retention[0].RetentionSpan).To(BeZero())
+	})
+
+	It("reports metrics to prometheus", func() {
+		budget := dump.NewBudget(0, size)
+		sink := dump.NewDumpSink("app-a", 100, time.Second, health, dump.WithBudget(budget))
+		input, _ := runSink(sink)
+		defer close(input)
+
+		input <- logMessage("0")
+		input <- logMessage("1")
+		Eventually(func() []string {
+			return messages(sink.Dump())
+		}).Should(Equal([]string{"1"}))
+
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(budget)
+
+		gather := func() map[string]float64 {
+			families, err := registry.Gather()
+			Expect(err).ToNot(HaveOccurred())
+
+			values := make(map[string]float64)
+			for _, f := range families {
+				m := f.GetMetric()[0]
+				switch {
+				case m.GetGauge() != nil:
+					values[f.GetName()] = m.GetGauge().GetValue()
+				case m.GetCounter() != nil:
+					values[f.GetName()] = m.GetCounter().GetValue()
+				case m.GetSummary() != nil:
+					values[f.GetName()] = float64(m.GetSummary().GetSampleCount())
+				}
+			}
+			return values
+		}
+
+		Eventually(gather).Should(Equal(map[string]float64{
+			"loggregator_doppler_recentLogCacheBytes":       float64(size),
+			"loggregator_doppler_recentLogCacheEvictions":   1,
+			"loggregator_doppler_recentLogRetentionSeconds": 1,
+		}))
+	})
+
+	Describe("RetentionHandler", func() {
+		var (
+			budget   *dump.Budget
+			recorder *httptest.ResponseRecorder
+			handler  http.Handler
+		)
+
+		BeforeEach(func() {
+			budget = dump.NewBudget(0, 0)
+			recorder = httptest.NewRecorder()
+			handler = dump.NewRetentionHandler(budget)
+		})
+
+		It("serves the retention of the apps as JSON", func() {
+			for _, id := range []string{"app-a", "app-b"} {
+				sink := dump.NewDumpSink(id, 100, time.Second, health, dump.WithBudget(budget))
+				input, _ := runSink(sink)
+				defer close(input)
+				input <- logMessage("0")
+			}
+			Eventually(budget.Bytes).Should(Equal(2 * size))
+
+			req, _ := http.NewRequest("GET", "/recentlogs/retention?n=1", nil)
+			handler.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+
+			var resp struct {
+				Bytes int64               `json:"bytes"`
+				Apps  []dump.AppRetention `json:"apps"`
+			}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &resp)).To(Succeed())
+			Expect(resp.Bytes).To(Equal(2 * size))
+			Expect(resp.Apps).To(HaveLen(1))
+			Expect(resp.Apps[0].AppID).To(Equal("app-a"))
+		})
+
+		It("rejects an invalid n", func() {
+			req, _ := http.NewRequest("GET", "/recentlogs/retention?n=-1", nil)
+			handler.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+})
+
+func runSink(s *dump.DumpSink) (chan<- *events.Envelope, <-chan struct{}) {
+	input := make(chan *events.Envelope)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Run(input)
+	}()
+
+	return input, done
+}
+
+func logMessage(msg string) *events.Envelope {
+	e, _ := emitter.Wrap(factories.NewLogMessage(events.LogMessage_OUT, msg, "app-id", "App"), "origin")
+	return e
+}
+
+func messages(envs []*events.Envelope) []string {
+	var msgs []string
+	for _, e := range envs {
+		msgs = append(msgs, string(e.GetLogMessage().GetMessage()))
+	}
+	return msgs
+}
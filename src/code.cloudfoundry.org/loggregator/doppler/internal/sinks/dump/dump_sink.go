@@ -1,11 +1,12 @@
 package dump
 
 import (
-	"container/ring"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cloudfoundry/sonde-go/events"
+	"github.com/gogo/protobuf/proto"
 )
 
 type HealthRegistrar interface {
@@ -14,12 +15,41 @@ type HealthRegistrar interface {
 }
 
 type DumpSink struct {
+	// bytes and lastWrite are read by the Budget without the lock.
+	bytes     int64
+	lastWrite int64
+
 	appId              string
-	messageRing        *ring.Ring
-	inputChan          chan *events.Envelope
 	inactivityDuration time.Duration
-	lock               sync.RWMutex
 	health             HealthRegistrar
+	budget             *Budget
+
+	lock sync.RWMutex
+	// messages is a ring buffer of the newest messages. start is the index
+	// of the oldest message.
+	messages []message
+	start    int
+	count    int
+	// released is set once the bytes of the sink were taken off the
+	// budget. No messages are evicted from it afterwards.
+	released bool
+}
+
+type message struct {
+	envelope *events.Envelope
+	size     int64
+	received int64
+}
+
+// DumpSinkOption configures a DumpSink.
+type DumpSinkOption func(*DumpSink)
+
+// WithBudget limits the bytes of the recent logs of the sink with the
+// given Budget. The Budget is shared by the sinks of all apps.
+func WithBudget(b *Budget) DumpSinkOption {
+	return func(d *DumpSink) {
+		d.budget = b
+	}
 }
 
 func NewDumpSink(
@@ -27,13 +57,19 @@ func NewDumpSink(
 	bufferSize uint32,
 	inactivityDuration time.Duration,
 	h HealthRegistrar,
+	opts ...DumpSinkOption,
 ) *DumpSink {
 	dumpSink := &DumpSink{
 		appId:              appId,
-		messageRing:        ring.New(int(bufferSize)),
+		messages:           make([]message, bufferSize),
 		inactivityDuration: inactivityDuration,
 		health:             h,
 	}
+
+	for _, o := range opts {
+		o(dumpSink)
+	}
+
 	return dumpSink
 }
 
@@ -41,6 +77,11 @@ func (d *DumpSink) Run(inputChan <-chan *events.Envelope) {
 	d.health.Inc("recentLogCacheCount")
 	defer d.health.Dec("recentLogCacheCount")
 
+	if d.budget != nil {
+		d.budget.register(d)
+		defer d.budget.unregister(d)
+	}
+
 	timer := time.NewTimer(d.inactivityDuration)
 	defer timer.Stop()
 	for {
@@ -66,26 +107,116 @@ func (d *DumpSink) Run(inputChan <-chan *events.Envelope) {
 }
 
 func (d *DumpSink) addMsg(msg *events.Envelope) {
+	now := time.Now().UnixNano()
+	m := message{
+		envelope: msg,
+		received: now,
+	}
+	// The size is only needed to enforce the budget.
+	if d.budget != nil {
+		m.size = int64(proto.Size(msg))
+	}
+
+	d.lock.Lock()
+	delta := m.size
+	if d.count == len(d.messages) {
+		delta -= d.removeOldest()
+	}
+	d.messages[(d.start+d.count)%len(d.messages)] = m
+	d.count++
+
+	var evicted int
+	if d.budget != nil && d.budget.maxAppBytes > 0 {
+		for d.count > 0 && atomic.LoadInt64(&d.bytes)+delta > d.budget.maxAppBytes {
+			delta -= d.removeOldest()
+			evicted++
+		}
+	}
+	d.addBytes(delta)
+	d.lock.Unlock()
+
+	atomic.StoreInt64(&d.lastWrite, now)
+	if d.budget != nil {
+		d.budget.evicted(evicted)
+		d.budget.enforce()
+	}
+}
+
+// evict removes the oldest messages until at least the given bytes are
+// freed or the sink is empty. It returns the freed bytes and the number of
+// removed messages.
+func (d *DumpSink) evict(bytes int64) (int64, int) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.released {
+		return 0, 0
+	}
+
+	var freed int64
+	var messages int
+	for d.count > 0 && freed < bytes {
+		freed += d.removeOldest()
+		messages++
+	}
+	d.addBytes(-freed)
+
+	return freed, messages
+}
+
+// release takes the bytes of the sink off the budget.
+func (d *DumpSink) release() {
 	d.lock.Lock()
 	defer d.lock.Unlock()
 
-	d.messageRing = d.messageRing.Next()
-	d.messageRing.Value = msg
+	d.released = true
+	atomic.AddInt64(&d.budget.bytes, -atomic.LoadInt64(&d.bytes))
 }
 
-func (d *DumpSink) Dump() []*events.Envelope {
+// addBytes adds the delta to the bytes of the sink and its budget. It needs
+// to be called with the write lock held.
+func (d *DumpSink) addBytes(delta int64) {
+	atomic.AddInt64(&d.bytes, delta)
+	if d.budget != nil && !d.released {
+		atomic.AddInt64(&d.budget.bytes, delta)
+	}
+}
+
+// removeOldest removes the oldest message and returns its size. It needs to
+// be called with the write lock held. It does not update the bytes.
+func (d *DumpSink) removeOldest() int64 {
+	m := d.messages[d.start]
+	d.messages[d.start] = message{}
+	d.start = (d.start + 1) % len(d.messages)
+	d.count--
+
+	return m.size
+}
+
+// retention returns the number of messages and the time between the oldest
+// and newest message.
+func (d *DumpSink) retention() (int, time.Duration) {
 	d.lock.RLock()
 	defer d.lock.RUnlock()
 
-	data := make([]*events.Envelope, 0, d.messageRing.Len())
-	d.messageRing.Next().Do(func(value interface{}) {
-		if value == nil {
-			return
-		}
+	if d.count == 0 {
+		return 0, 0
+	}
+
+	oldest := d.messages[d.start]
+	newest := d.messages[(d.start+d.count-1)%len(d.messages)]
+
+	return d.count, time.Duration(newest.received - oldest.received)
+}
+
+func (d *DumpSink) Dump() []*events.Envelope {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
 
-		msg := value.(*events.Envelope)
-		data = append(data, msg)
-	})
+	data := make([]*events.Envelope, 0, d.count)
+	for i := 0; i < d.count; i++ {
+		data = append(data, d.messages[(d.start+i)%len(d.messages)].envelope)
+	}
 
 	return data
 }
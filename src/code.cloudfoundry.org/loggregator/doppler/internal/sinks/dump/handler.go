@@ -0,0 +1,48 @@
+package dump
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+const defaultRetentionCount = 10
+
+type retentionResponse struct {
+	Bytes int64          `json:"bytes"`
+	Apps  []AppRetention `json:"apps"`
+}
+
+// RetentionHandler serves the apps with the shortest recent log retention
+// spans as JSON. The number of apps is given by the n query parameter.
+type RetentionHandler struct {
+	budget *Budget
+}
+
+func NewRetentionHandler(b *Budget) *RetentionHandler {
+	return &RetentionHandler{
+		budget: b,
+	}
+}
+
+func (h *RetentionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := defaultRetentionCount
+	if s := r.URL.Query().Get("n"); s != "" {
+		var err error
+		n, err = strconv.Atoi(s)
+		if err != nil || n < 0 {
+			http.Error(w, "n must be a positive number", http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(retentionResponse{
+		Bytes: h.budget.Bytes(),
+		Apps:  h.budget.Retention(n),
+	})
+	if err != nil {
+		log.Printf("failed to write recent log retention: %s", err)
+	}
+}
@@ -0,0 +1,42 @@
+package syslog
+
+import (
+	"log"
+	"net/url"
+
+	"code.cloudfoundry.org/loggregator/doppler/internal/sinks/syslogwriter"
+)
+
+// AggregateDrainSink is a SyslogSink for a drain that the operator
+// configured to receive the logs of every app. Each message is written with
+// the app ID it belongs to. Errors are logged with the name of the drain
+// rather than sent to the logs of an app.
+type AggregateDrainSink struct {
+	*SyslogSink
+	name string
+}
+
+func NewAggregateDrainSink(name string, drainURL *url.URL, messageDrainBufferSize uint, syslogWriter syslogwriter.Writer, dropsondeOrigin string, opts ...SinkOption) *AggregateDrainSink {
+	handleSendError := func(errorMsg, _ string) {
+		log.Printf("Aggregate drain %s: %s", name, errorMsg)
+	}
+
+	opts = append(opts, WithMessageAppIDs())
+	return &AggregateDrainSink{
+		SyslogSink: NewSyslogSink(
+			"aggregate-drain-"+name,
+			drainURL,
+			messageDrainBufferSize,
+			syslogWriter,
+			handleSendError,
+			dropsondeOrigin,
+			opts...,
+		),
+		name: name,
+	}
+}
+
+// Name returns the name of the drain.
+func (s *AggregateDrainSink) Name() string {
+	return s.name
+}
@@ -0,0 +1,225 @@
+package syslog
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cloudfoundry/sonde-go/events"
+)
+
+const (
+	includeSourceTypesParam = "include-source-types"
+	excludeSourceTypesParam = "exclude-source-types"
+	logTypesParam           = "log-types"
+	instanceIndexesParam    = "instance-indexes"
+)
+
+// ParseFilter builds a Filter from the query parameters of a drain URL:
+//
+//	include-source-types=APP,RTR  only write logs with these source types
+//	exclude-source-types=STG,API  do not write logs with these source types
+//	log-types=out                 only write OUT or ERR logs
+//	instance-indexes=0,1          only write logs from these instances
+//
+// It returns a copy of the URL without the filter parameters so they are not
+// sent to the drain. The Filter is nil if the URL has no filter parameters.
+func ParseFilter(drainURL *url.URL) (Filter, *url.URL, error) {
+	query := drainURL.Query()
+
+	var (
+		include   []string
+		exclude   []string
+		logTypes  map[events.LogMessage_MessageType]bool
+		instances map[string]bool
+		found     bool
+	)
+
+	for param, values := range query {
+		var err error
+		switch param {
+		case includeSourceTypesParam:
+			include, err = parseList(param, values)
+		case excludeSourceTypesParam:
+			exclude, err = parseList(param, values)
+		case logTypesParam:
+			logTypes, err = parseLogTypes(values)
+		case instanceIndexesParam:
+			instances, err = parseInstanceIndexes(values)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		found = true
+		query.Del(param)
+	}
+
+	if !found {
+		return nil, drainURL, nil
+	}
+
+	strippedURL := *drainURL
+	strippedURL.RawQuery = query.Encode()
+
+	filter := func(m *events.LogMessage) bool {
+		sourceType := m.GetSourceType()
+		if len(include) > 0 && !MatchesSourceType(sourceType, include) {
+			return false
+		}
+
+		if MatchesSourceType(sourceType, exclude) {
+			return false
+		}
+
+		if logTypes != nil && !logTypes[m.GetMessageType()] {
+			return false
+		}
+
+		if instances != nil && !instances[m.GetSourceInstance()] {
+			return false
+		}
+
+		return true
+	}
+
+	return filter, &strippedURL, nil
+}
+
+// filterParams are the filter parameters in the order they appear in a
+// drain identifier.
+var filterParams = []string{
+	excludeSourceTypesParam,
+	includeSourceTypesParam,
+	instanceIndexesParam,
+	logTypesParam,
+}
+
+// DrainIdentifier returns the identifier of the drain with the given URL.
+// It is made of the scheme, host and path of the URL and its normalized
+// filter parameters, so that drains that only differ by their filter are
+// different drains while the same filter written differently identifies
+// the same drain. Other query parameters are ignored.
+func DrainIdentifier(drainURL *url.URL) string {
+	id := fmt.Sprintf("%s://%s%s", drainURL.Scheme, drainURL.Host, drainURL.Path)
+
+	query := drainURL.Query()
+	var params []string
+	for _, param := range filterParams {
+		values, ok := query[param]
+		if !ok {
+			continue
+		}
+
+		items, err := parseList(param, values)
+		if err != nil {
+			items = values
+		}
+		params = append(params, param+"="+normalizeFilterItems(param, items))
+	}
+
+	if len(params) == 0 {
+		return id
+	}
+	return id + "?" + strings.Join(params, "&")
+}
+
+// normalizeFilterItems returns the sorted unique values of a filter
+// parameter in the case they are matched in.
+func normalizeFilterItems(param string, items []string) string {
+	unique := make(map[string]bool, len(items))
+	for _, item := range items {
+		switch param {
+		case includeSourceTypesParam, excludeSourceTypesParam:
+			item = strings.ToUpper(item)
+		case logTypesParam:
+			item = strings.ToLower(item)
+		case instanceIndexesParam:
+			if n, err := strconv.ParseUint(item, 10, 32); err == nil {
+				item = strconv.FormatUint(n, 10)
+			}
+		}
+		unique[url.QueryEscape(item)] = true
+	}
+
+	sorted := make([]string, 0, len(unique))
+	for item := range unique {
+		sorted = append(sorted, item)
+	}
+	sort.Strings(sorted)
+
+	return strings.Join(sorted, ",")
+}
+
+// MatchesSourceType reports whether the source type is one of the given
+// types. Matching is case insensitive and a type of APP also matches
+// APP/PROC/WEB.
+func MatchesSourceType(sourceType string, types []string) bool {
+	sourceType = strings.ToUpper(sourceType)
+	for _, t := range types {
+		t = strings.ToUpper(t)
+		if sourceType == t || strings.HasPrefix(sourceType, t+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseList(param string, values []string) ([]string, error) {
+	var result []string
+	for _, v := range values {
+		for _, item := range strings.Split(v, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				return nil, fmt.Errorf("invalid drain filter %s=%s: empty value", param, v)
+			}
+			result = append(result, item)
+		}
+	}
+
+	return result, nil
+}
+
+func parseLogTypes(values []string) (map[events.LogMessage_MessageType]bool, error) {
+	types, err := parseList(logTypesParam, values)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[events.LogMessage_MessageType]bool)
+	for _, t := range types {
+		switch strings.ToLower(t) {
+		case "out":
+			result[events.LogMessage_OUT] = true
+		case "err":
+			result[events.LogMessage_ERR] = true
+		default:
+			return nil, fmt.Errorf("invalid drain filter %s=%s: must be out or err", logTypesParam, t)
+		}
+	}
+
+	return result, nil
+}
+
+func parseInstanceIndexes(values []string) (map[string]bool, error) {
+	indexes, err := parseList(instanceIndexesParam, values)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]bool)
+	for _, i := range indexes {
+		n, err := strconv.ParseUint(i, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid drain filter %s=%s: must be a non-negative integer", instanceIndexesParam, i)
+		}
+		result[strconv.FormatUint(n, 10)] = true
+	}
+
+	return result, nil
+}
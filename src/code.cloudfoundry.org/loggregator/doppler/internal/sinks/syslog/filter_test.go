@@ -0,0 +1,124 @@
+package syslog_test
+
+import (
+	"net/url"
+
+	"code.cloudfoundry.org/loggregator/doppler/internal/sinks/syslog"
+
+	"github.com/cloudfoundry/dropsonde/factories"
+	"github.com/cloudfoundry/sonde-go/events"
+	"github.com/gogo/protobuf/proto"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseFilter", func() {
+	parse := func(rawURL string) (syslog.Filter, *url.URL, error) {
+		u, err := url.Parse(rawURL)
+		Expect(err).ToNot(HaveOccurred())
+		return syslog.ParseFilter(u)
+	}
+
+	logMessage := func(messageType events.LogMessage_MessageType, sourceType, instance string) *events.LogMessage {
+		m := factories.NewLogMessage(messageType, "message", "app-id", sourceType)
+		m.SourceInstance = proto.String(instance)
+		return m
+	}
+
+	It("returns a nil filter and the same URL without filter parameters", func() {
+		filter, u, err := parse("syslog://example.com:514?other=value")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(filter).To(BeNil())
+		Expect(u.String()).To(Equal("syslog://example.com:514?other=value"))
+	})
+
+	It("removes filter parameters from the URL", func() {
+		_, u, err := parse("https://example.com/drain?include-source-types=APP&token=abc")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(u.String()).To(Equal("https://example.com/drain?token=abc"))
+	})
+
+	It("filters by included source types", func() {
+		filter, _, err := parse("syslog://example.com?include-source-types=APP,STG")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(filter(logMessage(events.LogMessage_OUT, "APP/PROC/WEB", "0"))).To(BeTrue())
+		Expect(filter(logMessage(events.LogMessage_OUT, "STG", "0"))).To(BeTrue())
+		Expect(filter(logMessage(events.LogMessage_OUT, "RTR", "0"))).To(BeFalse())
+	})
+
+	It("filters by excluded source types", func() {
+		filter, _, err := parse("syslog://example.com?exclude-source-types=rtr&exclude-source-types=API")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(filter(logMessage(events.LogMessage_OUT, "APP/PROC/WEB", "0"))).To(BeTrue())
+		Expect(filter(logMessage(events.LogMessage_OUT, "RTR", "0"))).To(BeFalse())
+		Expect(filter(logMessage(events.LogMessage_OUT, "API", "0"))).To(BeFalse())
+	})
+
+	It("filters by log type", func() {
+		filter, _, err := parse("syslog://example.com?log-types=err")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(filter(logMessage(events.LogMessage_ERR, "APP", "0"))).To(BeTrue())
+		Expect(filter(logMessage(events.LogMessage_OUT, "APP", "0"))).To(BeFalse())
+	})
+
+	It("filters by instance index", func() {
+		filter, _, err := parse("syslog://example.com?instance-indexes=0,2")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(filter(logMessage(events.LogMessage_OUT, "APP", "0"))).To(BeTrue())
+		Expect(filter(logMessage(events.LogMessage_OUT, "APP", "2"))).To(BeTrue())
+		Expect(filter(logMessage(events.LogMessage_OUT, "APP", "1"))).To(BeFalse())
+	})
+
+	It("combines filters", func() {
+		filter, _, err := parse("syslog://example.com?include-source-types=APP&log-types=out&instance-indexes=1")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(filter(logMessage(events.LogMessage_OUT, "APP/PROC/WEB", "1"))).To(BeTrue())
+		Expect(filter(logMessage(events.LogMessage_ERR, "APP/PROC/WEB", "1"))).To(BeFalse())
+		Expect(filter(logMessage(events.LogMessage_OUT, "APP/PROC/WEB", "0"))).To(BeFalse())
+	})
+
+	DescribeTable("returns an error for malformed filters", func(rawURL string) {
+		_, _, err := parse(rawURL)
+		Expect(err).To(HaveOccurred())
+	},
+		Entry("empty source type", "syslog://example.com?include-source-types=APP,"),
+		Entry("unknown log type", "syslog://example.com?log-types=debug"),
+		Entry("negative instance index", "syslog://example.com?instance-indexes=-1"),
+		Entry("non-numeric instance index", "syslog://example.com?instance-indexes=web"),
+	)
+})
+
+var _ = Describe("DrainIdentifier", func() {
+	identifier := func(rawURL string) string {
+		u, err := url.Parse(rawURL)
+		Expect(err).ToNot(HaveOccurred())
+		return syslog.DrainIdentifier(u)
+	}
+
+	It("ignores query parameters that are not filters", func() {
+		Expect(identifier("https://example.com/drain?token=abc")).To(Equal("https://example.com/drain"))
+	})
+
+	It("includes the filter", func() {
+		Expect(identifier("https://example.com/drain?log-types=out&token=abc")).To(Equal("https://example.com/drain?log-types=out"))
+	})
+
+	It("distinguishes drains that only differ by their filter", func() {
+		Expect(identifier("syslog://example.com?log-types=out")).ToNot(Equal(identifier("syslog://example.com?log-types=err")))
+		Expect(identifier("syslog://example.com?log-types=out")).ToNot(Equal(identifier("syslog://example.com")))
+	})
+
+	It("normalizes the filter", func() {
+		Expect(identifier("syslog://example.com?log-types=OUT,err&include-source-types=rtr,app&instance-indexes=02,1,2")).To(Equal(
+			identifier("syslog://example.com?instance-indexes=1,2&include-source-types=APP&include-source-types=RTR&log-types=err,out"),
+		))
+		Expect(identifier("syslog://example.com?include-source-types=rtr,app")).To(Equal("syslog://example.com?include-source-types=APP,RTR"))
+	})
+})
@@ -11,6 +11,7 @@ import (
 	"code.cloudfoundry.org/loggregator/doppler/internal/sinks/retrystrategy"
 	"code.cloudfoundry.org/loggregator/doppler/internal/sinks/syslogwriter"
 	"code.cloudfoundry.org/loggregator/doppler/internal/truncatingbuffer"
+	"code.cloudfoundry.org/loggregator/metricemitter"
 
 	"github.com/cloudfoundry/sonde-go/events"
 )
@@ -27,9 +28,45 @@ type SyslogSink struct {
 	disconnectChannel      chan struct{}
 	dropsondeOrigin        string
 	disconnectOnce         sync.Once
+
+	filter         Filter
+	droppedMetric  *metricemitter.Counter
+	useMessageApps bool
 }
 
-func NewSyslogSink(appId string, drainURL *url.URL, messageDrainBufferSize uint, syslogWriter syslogwriter.Writer, errorHandler func(string, string), dropsondeOrigin string) *SyslogSink {
+// Filter decides if a log message should be written to the drain.
+type Filter func(*events.LogMessage) bool
+
+// SinkOption configures optional behavior of a SyslogSink.
+type SinkOption func(*SyslogSink)
+
+// WithFilter only writes log messages that the filter allows. Messages are
+// filtered before they are buffered.
+func WithFilter(f Filter) SinkOption {
+	return func(s *SyslogSink) {
+		s.filter = f
+	}
+}
+
+// WithDroppedMetric increments the given counter whenever the sink's buffer
+// overflows.
+func WithDroppedMetric(c *metricemitter.Counter) SinkOption {
+	return func(s *SyslogSink) {
+		s.droppedMetric = c
+	}
+}
+
+// WithMessageAppIDs writes each message with the app ID of the message
+// rather than the app ID of the sink. It is used by drains that receive
+// logs for many apps. It requires a writer that implements
+// syslogwriter.AppWriter.
+func WithMessageAppIDs() SinkOption {
+	return func(s *SyslogSink) {
+		s.useMessageApps = true
+	}
+}
+
+func NewSyslogSink(appId string, drainURL *url.URL, messageDrainBufferSize uint, syslogWriter syslogwriter.Writer, errorHandler func(string, string), dropsondeOrigin string, opts ...SinkOption) *SyslogSink {
 
 	syslogSink := &SyslogSink{
 		appId:                  appId,
@@ -41,6 +78,10 @@ func NewSyslogSink(appId string, drainURL *url.URL, messageDrainBufferSize uint,
 		dropsondeOrigin:        dropsondeOrigin,
 	}
 
+	for _, o := range opts {
+		o(syslogSink)
+	}
+
 	log.Printf("Syslog Sink %s: Created for appId [%s]", syslogSink.Identifier(), appId)
 	return syslogSink
 }
@@ -52,7 +93,11 @@ func (s *SyslogSink) Run(inputChan <-chan *events.Envelope) {
 
 	backoffStrategy := retrystrategy.Exponential()
 
-	context := truncatingbuffer.NewLogAllowedContext(s.dropsondeOrigin, syslogIdentifier)
+	context := &drainContext{
+		LogAllowedContext: truncatingbuffer.NewLogAllowedContext(s.dropsondeOrigin, syslogIdentifier),
+		filter:            s.filter,
+		droppedMetric:     s.droppedMetric,
+	}
 	buffer := sinks.RunTruncatingBuffer(inputChan, s.messageDrainBufferSize, context, s.disconnectChannel)
 	timer := time.NewTimer(backoffStrategy(0))
 	connected := false
@@ -116,7 +161,7 @@ func (s *SyslogSink) Identifier() string {
 	if s.drainURL.Host == "" {
 		return ""
 	}
-	return fmt.Sprintf("%s://%s%s", s.drainURL.Scheme, s.drainURL.Host, s.drainURL.Path)
+	return DrainIdentifier(s.drainURL)
 }
 
 func (s *SyslogSink) AppID() string {
@@ -128,6 +173,11 @@ func (s *SyslogSink) ShouldReceiveErrors() bool {
 }
 
 func (s *SyslogSink) sendLogMessage(logMessage *events.LogMessage) error {
+	if appWriter, ok := s.syslogWriter.(syslogwriter.AppWriter); ok && s.useMessageApps && logMessage.GetAppId() != "" {
+		_, err := appWriter.WriteForApp(logMessage.GetAppId(), messagePriorityValue(logMessage), logMessage.GetMessage(), logMessage.GetSourceType(), logMessage.GetSourceInstance(), *logMessage.Timestamp)
+		return err
+	}
+
 	_, err := s.syslogWriter.Write(messagePriorityValue(logMessage), logMessage.GetMessage(), logMessage.GetSourceType(), logMessage.GetSourceInstance(), *logMessage.Timestamp)
 	return err
 }
@@ -142,3 +192,25 @@ func messagePriorityValue(msg *events.LogMessage) int {
 		return -1
 	}
 }
+
+// drainContext only buffers log messages the sink's filter allows and
+// reports buffer overflows to the sink's dropped metric.
+type drainContext struct {
+	*truncatingbuffer.LogAllowedContext
+	filter        Filter
+	droppedMetric *metricemitter.Counter
+}
+
+func (c *drainContext) EnvelopeAllowed(e *events.Envelope) bool {
+	if c.filter == nil {
+		return true
+	}
+	return c.filter(e.GetLogMessage())
+}
+
+func (c *drainContext) MessagesDropped(delta uint64) {
+	if c.droppedMetric == nil {
+		return
+	}
+	c.droppedMetric.Increment(delta)
+}
@@ -32,6 +32,7 @@ var _ = Describe("SyslogSink", func() {
 		inputChan             chan *events.Envelope
 		dialer                *net.Dialer
 		drainURL              string
+		sinkOpts              []syslog.SinkOption
 	)
 
 	BeforeEach(func() {
@@ -41,6 +42,7 @@ var _ = Describe("SyslogSink", func() {
 		inputChan = make(chan *events.Envelope)
 		dialer = &net.Dialer{}
 		drainURL = "syslog://using-fake"
+		sinkOpts = nil
 
 		errorHandler = func(errorMsg, appId string) {
 			logMessage := factories.NewLogMessage(events.LogMessage_ERR, errorMsg, appId, "LGR")
@@ -56,7 +58,7 @@ var _ = Describe("SyslogSink", func() {
 	JustBeforeEach(func() {
 		drainURL, err := url.Parse(drainURL)
 		Expect(err).ToNot(HaveOccurred())
-		syslogSink = syslog.NewSyslogSink("appId", drainURL, bufferSize, sysLogger, errorHandler, "dropsonde-origin")
+		syslogSink = syslog.NewSyslogSink("appId", drainURL, bufferSize, sysLogger, errorHandler, "dropsonde-origin", sinkOpts...)
 	})
 
 	Describe("Identifier", func() {
@@ -181,6 +183,27 @@ var _ = Describe("SyslogSink", func() {
 			close(done)
 		})
 
+		Context("with a filter", func() {
+			BeforeEach(func() {
+				sinkOpts = []syslog.SinkOption{
+					syslog.WithFilter(func(m *events.LogMessage) bool {
+						return m.GetSourceType() == "App"
+					}),
+				}
+			})
+
+			It("only sends log messages the filter allows", func() {
+				rtrMessage, _ := emitter.Wrap(factories.NewLogMessage(events.LogMessage_OUT, "router message", "appId", "RTR"), "origin")
+				appMessage, _ := emitter.Wrap(factories.NewLogMessage(events.LogMessage_OUT, "app message", "appId", "App"), "origin")
+
+				inputChan <- rtrMessage
+				inputChan <- appMessage
+
+				Eventually(sysLogger.receivedChannel).Should(Receive(ContainSubstring("app message")))
+				Consistently(sysLogger.receivedChannel).ShouldNot(Receive())
+			})
+		})
+
 		Context("when remote syslog server goes down", func() {
 			BeforeEach(func() {
 				sysLogger.SetDown(true)
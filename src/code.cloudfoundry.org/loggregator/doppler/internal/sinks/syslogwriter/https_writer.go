@@ -33,7 +33,7 @@ type httpsWriter struct {
 	lastError error
 }
 
-func NewHttpsWriter(outputUrl *url.URL, appId, hostname string, skipCertVerify bool, dialer *net.Dialer, timeout time.Duration) (w *httpsWriter, err error) {
+func NewHttpsWriter(outputUrl *url.URL, appId, hostname string, skipCertVerify bool, dialer Dialer, timeout time.Duration) (w *httpsWriter, err error) {
 	if dialer == nil {
 		return nil, errors.New("cannot construct a writer with a nil dialer")
 	}
@@ -47,7 +47,7 @@ func NewHttpsWriter(outputUrl *url.URL, appId, hostname string, skipCertVerify b
 	tr := &http.Transport{
 		MaxIdleConnsPerHost: 1,
 		TLSClientConfig:     tlsConfig,
-		TLSHandshakeTimeout: dialer.Timeout * 2,
+		TLSHandshakeTimeout: dialTimeout(dialer) * 2,
 		Dial: func(network, addr string) (net.Conn, error) {
 			return dialer.Dial(network, addr)
 		},
@@ -74,7 +74,11 @@ func (w *httpsWriter) Connect() error {
 }
 
 func (w *httpsWriter) Write(p int, b []byte, source string, sourceId string, timestamp int64) (int, error) {
-	syslogMsg := createMessage(p, w.appId, w.hostname, source, sourceId, b, timestamp)
+	return w.WriteForApp(w.appId, p, b, source, sourceId, timestamp)
+}
+
+func (w *httpsWriter) WriteForApp(appId string, p int, b []byte, source string, sourceId string, timestamp int64) (int, error) {
+	syslogMsg := createMessage(p, appId, w.hostname, source, sourceId, b, timestamp)
 	bytesWritten, err := w.writeHttp(syslogMsg)
 	w.mu.Lock()
 	w.lastError = err
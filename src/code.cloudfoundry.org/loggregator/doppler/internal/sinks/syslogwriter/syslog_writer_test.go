@@ -66,6 +66,15 @@ var _ = Describe("SyslogWriter", func() {
 			Eventually(syslogServerSession, 5).Should(gbytes.Say(`\d <\d+>1 \d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{1,6}([-+]\d{2}:\d{2}) org-name.space-name.app-name.1 appId \[APP/PROC/BLAH/2\] - - just a test\n`))
 		}, 10)
 
+		It("sends messages on behalf of other apps", func() {
+			appWriter, ok := sysLogWriter.(syslogwriter.AppWriter)
+			Expect(ok).To(BeTrue())
+
+			appWriter.WriteForApp("otherAppId", standardOutPriority, []byte("just a test"), "App", "2", time.Now().UnixNano())
+
+			Eventually(syslogServerSession, 5).Should(gbytes.Say(`org-name.space-name.app-name.1 otherAppId \[APP/2\] - - just a test\n`))
+		}, 10)
+
 		It("strips null termination char from message", func() {
 			sysLogWriter.Write(standardOutPriority, []byte(string(0)+" hi"), "appId", "", time.Now().UnixNano())
 
@@ -24,13 +24,13 @@ type tlsWriter struct {
 
 	mu        sync.Mutex // guards conn
 	conn      net.Conn
-	dialer    *net.Dialer
+	dialer    Dialer
 	ioTimeout time.Duration
 
 	TlsConfig *tls.Config
 }
 
-func NewTlsWriter(outputUrl *url.URL, appId, hostname string, skipCertVerify bool, dialer *net.Dialer, ioTimeout time.Duration) (w *tlsWriter, err error) {
+func NewTlsWriter(outputUrl *url.URL, appId, hostname string, skipCertVerify bool, dialer Dialer, ioTimeout time.Duration) (w *tlsWriter, err error) {
 	if dialer == nil {
 		return nil, errors.New("cannot construct a writer with a nil dialer")
 	}
@@ -60,11 +60,32 @@ func (w *tlsWriter) Connect() error {
 		w.conn.Close()
 		w.conn = nil
 	}
-	c, err := tls.DialWithDialer(w.dialer, "tcp", w.host, w.TlsConfig)
-	if err == nil {
-		w.conn = c
+	c, err := w.dialer.Dial("tcp", w.host)
+	if err != nil {
+		return err
+	}
+
+	tlsConfig := w.TlsConfig.Clone()
+	if tlsConfig.ServerName == "" {
+		host, _, err := net.SplitHostPort(w.host)
+		if err != nil {
+			host = w.host
+		}
+		tlsConfig.ServerName = host
 	}
-	return err
+
+	tlsConn := tls.Client(c, tlsConfig)
+	if w.ioTimeout > 0 {
+		tlsConn.SetDeadline(time.Now().Add(w.ioTimeout))
+	}
+	if err := tlsConn.Handshake(); err != nil {
+		c.Close()
+		return err
+	}
+	tlsConn.SetDeadline(time.Time{})
+
+	w.conn = tlsConn
+	return nil
 }
 
 func (w *tlsWriter) Close() error {
@@ -80,7 +101,11 @@ func (w *tlsWriter) Close() error {
 }
 
 func (w *tlsWriter) Write(p int, b []byte, source string, sourceId string, timestamp int64) (byteCount int, err error) {
-	syslogMsg := createMessage(p, w.appId, w.hostname, source, sourceId, b, timestamp)
+	return w.WriteForApp(w.appId, p, b, source, sourceId, timestamp)
+}
+
+func (w *tlsWriter) WriteForApp(appId string, p int, b []byte, source string, sourceId string, timestamp int64) (byteCount int, err error) {
+	syslogMsg := createMessage(p, appId, w.hostname, source, sourceId, b, timestamp)
 	// Frame msg with Octet Counting: https://tools.ietf.org/html/rfc6587#section-3.4.1
 	finalMsg := []byte(fmt.Sprintf("%d %s", len(syslogMsg), syslogMsg))
 
@@ -24,15 +24,40 @@ type Writer interface {
 	Close() error
 }
 
+// AppWriter is implemented by writers that can write messages on behalf of
+// apps other than the one they were created for. Aggregate drains use it to
+// set the syslog APP-NAME of each message.
+type AppWriter interface {
+	WriteForApp(appId string, p int, b []byte, source, sourceId string, timestamp int64) (int, error)
+}
+
+// Dialer dials connections to syslog drains.
+type Dialer interface {
+	Dial(network, address string) (net.Conn, error)
+}
+
+// dialTimeout returns the timeout of a single dial of the dialer or zero
+// if it is unknown.
+func dialTimeout(d Dialer) time.Duration {
+	switch d := d.(type) {
+	case *net.Dialer:
+		return d.Timeout
+	case interface {
+		Timeout() time.Duration
+	}:
+		return d.Timeout()
+	}
+	return 0
+}
+
 func NewWriter(
 	outputUrl *url.URL,
 	appId string,
 	hostname string,
 	skipCertVerify bool,
-	dialTimeout time.Duration,
+	dialer Dialer,
 	ioTimeout time.Duration,
 ) (Writer, error) {
-	dialer := &net.Dialer{Timeout: dialTimeout}
 	switch outputUrl.Scheme {
 	case "https":
 		return NewHttpsWriter(outputUrl, appId, hostname, skipCertVerify, dialer, ioTimeout)
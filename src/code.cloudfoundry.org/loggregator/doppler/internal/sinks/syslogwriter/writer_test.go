@@ -1,6 +1,7 @@
 package syslogwriter_test
 
 import (
+	"net"
 	"time"
 
 	"code.cloudfoundry.org/loggregator/doppler/internal/sinks/syslogwriter"
@@ -16,7 +17,7 @@ var _ = Describe("Writer", func() {
 
 	It("returns an syslogWriter for syslog scheme", func() {
 		outputUrl, _ := url.Parse("syslog://localhost:9999")
-		w, err := syslogwriter.NewWriter(outputUrl, "appId", "hostname", false, 1*time.Second, 0)
+		w, err := syslogwriter.NewWriter(outputUrl, "appId", "hostname", false, &net.Dialer{Timeout: time.Second}, 0)
 		Expect(err).ToNot(HaveOccurred())
 		writerType := reflect.TypeOf(w).String()
 		Expect(writerType).To(Equal("*syslogwriter.syslogWriter"))
@@ -24,7 +25,7 @@ var _ = Describe("Writer", func() {
 
 	It("returns an tlsWriter for syslog-tls scheme", func() {
 		outputUrl, _ := url.Parse("syslog-tls://localhost:9999")
-		w, err := syslogwriter.NewWriter(outputUrl, "appId", "hostname", false, 1*time.Second, 0)
+		w, err := syslogwriter.NewWriter(outputUrl, "appId", "hostname", false, &net.Dialer{Timeout: time.Second}, 0)
 		Expect(err).ToNot(HaveOccurred())
 		writerType := reflect.TypeOf(w).String()
 		Expect(writerType).To(Equal("*syslogwriter.tlsWriter"))
@@ -32,7 +33,7 @@ var _ = Describe("Writer", func() {
 
 	It("returns an httpsWriter for https scheme", func() {
 		outputUrl, _ := url.Parse("https://localhost:9999")
-		w, err := syslogwriter.NewWriter(outputUrl, "appId", "hostname", false, 1*time.Second, 0)
+		w, err := syslogwriter.NewWriter(outputUrl, "appId", "hostname", false, &net.Dialer{Timeout: time.Second}, 0)
 		Expect(err).ToNot(HaveOccurred())
 		writerType := reflect.TypeOf(w).String()
 		Expect(writerType).To(Equal("*syslogwriter.httpsWriter"))
@@ -40,7 +41,7 @@ var _ = Describe("Writer", func() {
 
 	It("returns an error for invalid scheme", func() {
 		outputUrl, _ := url.Parse("notValid://localhost:9999")
-		w, err := syslogwriter.NewWriter(outputUrl, "appId", "hostname", false, 1*time.Second, 0)
+		w, err := syslogwriter.NewWriter(outputUrl, "appId", "hostname", false, &net.Dialer{Timeout: time.Second}, 0)
 		Expect(err).To(HaveOccurred())
 		Expect(w).To(BeNil())
 	})
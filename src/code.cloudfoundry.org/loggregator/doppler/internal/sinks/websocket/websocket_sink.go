@@ -5,12 +5,12 @@ import (
 	"net"
 	"time"
 
+	"code.cloudfoundry.org/loggregator/doppler/internal/marshaler"
 	"code.cloudfoundry.org/loggregator/doppler/internal/sinks"
 
 	"code.cloudfoundry.org/loggregator/doppler/internal/truncatingbuffer"
 
 	"github.com/cloudfoundry/sonde-go/events"
-	"github.com/gogo/protobuf/proto"
 	gorilla "github.com/gorilla/websocket"
 )
 
@@ -28,6 +28,12 @@ type noopCounter struct{}
 
 func (noopCounter) Increment(events.Envelope_EventType) {}
 
+// Marshaler marshals the envelopes written to the websocket. The returned
+// bytes may be shared with other sinks.
+type Marshaler interface {
+	Marshal(*events.Envelope) ([]byte, error)
+}
+
 type WebsocketSink struct {
 	appID                  string
 	ws                     remoteMessageWriter
@@ -36,6 +42,7 @@ type WebsocketSink struct {
 	writeTimeout           time.Duration
 	dropsondeOrigin        string
 	counter                Counter
+	marshaler              Marshaler
 }
 
 func NewWebsocketSink(appID string, ws remoteMessageWriter, messageDrainBufferSize uint, writeTimeout time.Duration, dropsondeOrigin string) *WebsocketSink {
@@ -47,6 +54,7 @@ func NewWebsocketSink(appID string, ws remoteMessageWriter, messageDrainBufferSi
 		writeTimeout:           writeTimeout,
 		dropsondeOrigin:        dropsondeOrigin,
 		counter:                noopCounter{},
+		marshaler:              marshaler.Uncached{},
 	}
 }
 
@@ -54,6 +62,13 @@ func (sink *WebsocketSink) SetCounter(counter Counter) {
 	sink.counter = counter
 }
 
+// SetMarshaler sets the marshaler used to marshal envelopes. A marshaler
+// that is shared between sinks avoids marshaling an envelope once per
+// sink.
+func (sink *WebsocketSink) SetMarshaler(m Marshaler) {
+	sink.marshaler = m
+}
+
 func (sink *WebsocketSink) Identifier() string {
 	return sink.ws.RemoteAddr().String()
 }
@@ -80,7 +95,7 @@ func (sink *WebsocketSink) Run(inputChan <-chan *events.Envelope) {
 			return
 		}
 
-		messageBytes, err := proto.Marshal(messageEnvelope)
+		messageBytes, err := sink.marshaler.Marshal(messageEnvelope)
 		if err != nil {
 			log.Printf("Websocket Sink %s: Error marshalling %s envelope from origin %s: %s", sink.clientAddress, messageEnvelope.GetEventType(), messageEnvelope.GetOrigin(), err.Error())
 			continue
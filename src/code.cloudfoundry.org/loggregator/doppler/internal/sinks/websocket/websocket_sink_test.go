@@ -1,3 +1,4 @@
+//go:build deprecated
 // +build deprecated
 
 package websocket_test
@@ -81,6 +82,16 @@ var _ = Describe("WebsocketSink", func() {
 			Eventually(fakeWebsocket.WriteDeadline).Should(BeTemporally("~", time.Now().Add(writeTimeout), time.Millisecond*50))
 		})
 
+		It("writes the bytes of the marshaler", func() {
+			websocketSink.SetMarshaler(staticMarshaler("some-bytes"))
+			go websocketSink.Run(inputChan)
+
+			message, _ := emitter.Wrap(factories.NewLogMessage(events.LogMessage_OUT, "hello world", "appId", "App"), "origin")
+			inputChan <- message
+			Eventually(fakeWebsocket.ReadMessages).Should(HaveLen(1))
+			Expect(fakeWebsocket.ReadMessages()[0]).To(Equal([]byte("some-bytes")))
+		})
+
 		Describe("Counter", func() {
 			var counter *fakeCounter
 
@@ -119,3 +130,9 @@ var _ = Describe("WebsocketSink", func() {
 		})
 	})
 })
+
+type staticMarshaler string
+
+func (m staticMarshaler) Marshal(*events.Envelope) ([]byte, error) {
+	return []byte(m), nil
+}
@@ -0,0 +1,79 @@
+package blacklist
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/doppler/internal/iprange"
+)
+
+// BlacklistedError is returned when a drain host resolves to a blacklisted
+// IP.
+type BlacklistedError struct {
+	Host string
+	IP   net.IP
+}
+
+func (e BlacklistedError) Error() string {
+	return fmt.Sprintf("syslog drain host %s resolves to blacklisted IP %s", e.Host, e.IP)
+}
+
+// Dialer resolves the drain host on every dial and refuses to connect if
+// any of its IPs are blacklisted. It then dials the resolved IPs directly so
+// the host cannot resolve to a different IP between the check and the
+// connect.
+type Dialer struct {
+	dialer    *net.Dialer
+	ranges    *iprange.Ranges
+	onBlocked func(address string, ip net.IP)
+}
+
+// Dialer returns a Dialer for the blacklist. onBlocked is called whenever a
+// dial is refused and may be nil.
+func (blacklistManager *URLBlacklistManager) Dialer(timeout time.Duration, onBlocked func(address string, ip net.IP)) *Dialer {
+	return &Dialer{
+		dialer:    &net.Dialer{Timeout: timeout},
+		ranges:    blacklistManager.blacklistIPs,
+		onBlocked: onBlocked,
+	}
+}
+
+// Timeout returns the timeout of a single dial.
+func (d *Dialer) Timeout() time.Duration {
+	return d.dialer.Timeout
+}
+
+func (d *Dialer) Dial(network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	ips, err := iprange.LookupIP(host)
+	if err != nil {
+		return nil, err
+	}
+	if len(ips) == 0 {
+		return nil, iprange.ResolutionFailure(host)
+	}
+
+	for _, ip := range ips {
+		if d.ranges.Contains(ip) {
+			if d.onBlocked != nil {
+				d.onBlocked(address, ip)
+			}
+			return nil, BlacklistedError{Host: host, IP: ip}
+		}
+	}
+
+	for _, ip := range ips {
+		var conn net.Conn
+		conn, err = d.dialer.Dial(network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+	}
+
+	return nil, err
+}
@@ -0,0 +1,82 @@
+package blacklist_test
+
+import (
+	"net"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/doppler/internal/iprange"
+	"code.cloudfoundry.org/loggregator/doppler/internal/sinkserver/blacklist"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Dialer", func() {
+	var (
+		listener net.Listener
+		port     string
+		blocked  chan net.IP
+	)
+
+	BeforeEach(func() {
+		var err error
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		_, port, err = net.SplitHostPort(listener.Addr().String())
+		Expect(err).ToNot(HaveOccurred())
+
+		blocked = make(chan net.IP, 10)
+	})
+
+	AfterEach(func() {
+		listener.Close()
+	})
+
+	onBlocked := func(address string, ip net.IP) {
+		blocked <- ip
+	}
+
+	It("connects to hosts that are not blacklisted", func() {
+		manager, err := blacklist.New([]iprange.IPRange{{Start: "10.10.10.10", End: "10.10.10.20"}})
+		Expect(err).ToNot(HaveOccurred())
+		dialer := manager.Dialer(time.Second, onBlocked)
+
+		conn, err := dialer.Dial("tcp", net.JoinHostPort("127.0.0.1", port))
+		Expect(err).ToNot(HaveOccurred())
+		conn.Close()
+		Expect(blocked).ToNot(Receive())
+	})
+
+	It("refuses to connect to blacklisted ranges", func() {
+		manager, err := blacklist.New([]iprange.IPRange{{Start: "127.0.0.0", End: "127.0.0.10"}})
+		Expect(err).ToNot(HaveOccurred())
+		dialer := manager.Dialer(time.Second, onBlocked)
+
+		_, err = dialer.Dial("tcp", net.JoinHostPort("127.0.0.1", port))
+		Expect(err).To(BeAssignableToTypeOf(blacklist.BlacklistedError{}))
+		Expect(blocked).To(Receive(Equal(net.ParseIP("127.0.0.1"))))
+	})
+
+	It("refuses to connect to blacklisted CIDR blocks", func() {
+		manager, err := blacklist.New([]iprange.IPRange{{CIDR: "127.0.0.0/8"}})
+		Expect(err).ToNot(HaveOccurred())
+		dialer := manager.Dialer(time.Second, onBlocked)
+
+		_, err = dialer.Dial("tcp", net.JoinHostPort("127.0.0.1", port))
+		Expect(err).To(HaveOccurred())
+		Expect(blocked).To(Receive())
+	})
+
+	It("checks the resolved IPs of hostnames on every dial", func() {
+		manager, err := blacklist.New([]iprange.IPRange{{CIDR: "127.0.0.0/8"}})
+		Expect(err).ToNot(HaveOccurred())
+		dialer := manager.Dialer(time.Second, onBlocked)
+
+		_, err = dialer.Dial("tcp", net.JoinHostPort("localhost", port))
+		Expect(err).To(HaveOccurred())
+		_, err = dialer.Dial("tcp", net.JoinHostPort("localhost", port))
+		Expect(err).To(HaveOccurred())
+
+		Expect(blocked).To(HaveLen(2))
+	})
+})
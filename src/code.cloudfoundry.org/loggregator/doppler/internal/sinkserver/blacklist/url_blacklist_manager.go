@@ -9,12 +9,19 @@ import (
 )
 
 type URLBlacklistManager struct {
-	blacklistIPs    []iprange.IPRange
+	blacklistIPs    *iprange.Ranges
 	blacklistedURLs []string
 }
 
-func New(blacklistIPs []iprange.IPRange) *URLBlacklistManager {
-	return &URLBlacklistManager{blacklistIPs: blacklistIPs}
+// New returns a URLBlacklistManager for the given ranges. It returns an
+// error if any of the ranges is invalid.
+func New(blacklistIPs []iprange.IPRange) (*URLBlacklistManager, error) {
+	ranges, err := iprange.NewRanges(blacklistIPs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &URLBlacklistManager{blacklistIPs: ranges}, nil
 }
 
 func (blacklistManager *URLBlacklistManager) CheckUrl(rawUrl string) (outputURL *url.URL, err error) {
@@ -23,7 +30,7 @@ func (blacklistManager *URLBlacklistManager) CheckUrl(rawUrl string) (outputURL
 		return nil, err
 	}
 
-	ipNotBlacklisted, err := iprange.IpOutsideOfRanges(*outputURL, blacklistManager.blacklistIPs)
+	ipNotBlacklisted, err := blacklistManager.blacklistIPs.URLOutside(*outputURL)
 	if err != nil {
 		_, ok := err.(iprange.ResolutionFailure)
 		if !ok {
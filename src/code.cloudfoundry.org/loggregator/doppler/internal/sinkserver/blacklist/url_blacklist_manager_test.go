@@ -14,7 +14,16 @@ var _ = Describe("UrlBlacklistManager", func() {
 	var urlBlacklistManager *blacklist.URLBlacklistManager
 
 	BeforeEach(func() {
-		urlBlacklistManager = blacklist.New([]iprange.IPRange{{Start: "14.15.16.17", End: "14.15.16.20"}})
+		var err error
+		urlBlacklistManager, err = blacklist.New([]iprange.IPRange{{Start: "14.15.16.17", End: "14.15.16.20"}})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Describe("New", func() {
+		It("rejects invalid ranges", func() {
+			_, err := blacklist.New([]iprange.IPRange{{CIDR: "10.0.0.0"}})
+			Expect(err).To(HaveOccurred())
+		})
 	})
 
 	Describe("CheckUrl", func() {
@@ -2,40 +2,166 @@ package sinkserver
 
 import (
 	"log"
+	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"code.cloudfoundry.org/loggregator/diodes"
+	"code.cloudfoundry.org/loggregator/doppler/internal/keyhash"
+	"code.cloudfoundry.org/loggregator/metricemitter"
 
+	gendiodes "github.com/cloudfoundry/diodes"
 	"github.com/cloudfoundry/dropsonde/envelope_extensions"
 	"github.com/cloudfoundry/sonde-go/events"
 )
 
+// MetricClient creates the queue depth gauge and the dropped envelope
+// counter of the router.
+type MetricClient interface {
+	NewCounter(name string, opts ...metricemitter.MetricOption) *metricemitter.Counter
+	NewGauge(name, unit string, opts ...metricemitter.MetricOption) *metricemitter.Gauge
+}
+
+// depthInterval is how often the queue depth of the workers is reported.
+const depthInterval = 250 * time.Millisecond
+
+// MessageRouter partitions envelopes by app ID across workers. Each worker
+// has a diode of its own and sends the envelopes of its partition to every
+// sender. The envelopes of an app are sent in the order they were set.
+// Envelopes without an app ID are spread across the workers in turn.
 type MessageRouter struct {
-	senders  []EnvelopeSender
-	done     chan struct{}
-	stopOnce sync.Once
+	senders    []EnvelopeSender
+	partitions []*partition
+	next       uint64
 }
 
 type EnvelopeSender interface {
 	SendTo(string, *events.Envelope)
 }
 
-func NewMessageRouter(e ...EnvelopeSender) *MessageRouter {
-	return &MessageRouter{
+type partition struct {
+	buffer *diodes.ManyToOneEnvelope
+	depth  int64
+
+	depthMetric   *metricemitter.Gauge
+	droppedMetric *metricemitter.Counter
+}
+
+// NewMessageRouter returns a MessageRouter with the given number of workers.
+// The workers buffer up to bufferSize envelopes together, each an equal
+// share of it. The alerter is called with the number of envelopes dropped
+// by any worker.
+func NewMessageRouter(
+	workers int,
+	bufferSize int,
+	alerter gendiodes.Alerter,
+	m MetricClient,
+	e ...EnvelopeSender,
+) *MessageRouter {
+	if workers < 1 {
+		workers = 1
+	}
+
+	workerBufferSize := bufferSize / workers
+	if workerBufferSize < 1 {
+		workerBufferSize = 1
+	}
+
+	r := &MessageRouter{
 		senders: e,
-		done:    make(chan struct{}),
 	}
+
+	for i := 0; i < workers; i++ {
+		tags := metricemitter.WithTags(map[string]string{
+			"partition": strconv.Itoa(i),
+		})
+
+		p := &partition{
+			// metric-documentation-v2: (loggregator.doppler.router.queue_depth)
+			// Number of envelopes waiting to be routed by a worker.
+			depthMetric: m.NewGauge("router.queue_depth", "envelopes",
+				metricemitter.WithVersion(2, 0),
+				tags,
+			),
+			// metric-documentation-v2: (loggregator.doppler.router.dropped)
+			// Number of envelopes dropped because a worker fell behind.
+			droppedMetric: m.NewCounter("router.dropped",
+				metricemitter.WithVersion(2, 0),
+				tags,
+			),
+		}
+		p.buffer = diodes.NewManyToOneEnvelope(workerBufferSize, gendiodes.AlertFunc(func(missed int) {
+			atomic.AddInt64(&p.depth, -int64(missed))
+			p.droppedMetric.Increment(uint64(missed))
+
+			if alerter != nil {
+				alerter.Alert(missed)
+			}
+		}))
+
+		r.partitions = append(r.partitions, p)
+	}
+
+	return r
 }
 
-func (r *MessageRouter) Start(incomingLog *diodes.ManyToOneEnvelope) {
-	log.Print("MessageRouter:Starting")
+// Set queues the envelope with the worker of its app. It is safe to call
+// from many goroutines.
+func (r *MessageRouter) Set(e *events.Envelope) {
+	p := r.partitions[r.partition(envelope_extensions.GetAppId(e))]
+	atomic.AddInt64(&p.depth, 1)
+	p.buffer.Set(e)
+}
+
+// Start runs the workers and reports their queue depth. It does not
+// return.
+func (r *MessageRouter) Start() {
+	log.Printf("MessageRouter:Starting %d workers", len(r.partitions))
+
+	go r.reportDepth()
+
+	var wg sync.WaitGroup
+	for _, p := range r.partitions {
+		wg.Add(1)
+		go func(p *partition) {
+			defer wg.Done()
+			r.work(p)
+		}(p)
+	}
+	wg.Wait()
+}
 
+func (r *MessageRouter) work(p *partition) {
 	for {
-		envelope := incomingLog.Next()
-		appId := envelope_extensions.GetAppId(envelope)
+		envelope := p.buffer.Next()
+		atomic.AddInt64(&p.depth, -1)
+
+		appID := envelope_extensions.GetAppId(envelope)
+		for _, s := range r.senders {
+			s.SendTo(appID, envelope)
+		}
+	}
+}
 
-		for _, sm := range r.senders {
-			sm.SendTo(appId, envelope)
+func (r *MessageRouter) reportDepth() {
+	for range time.Tick(depthInterval) {
+		for _, p := range r.partitions {
+			p.depthMetric.Set(float64(atomic.LoadInt64(&p.depth)))
 		}
 	}
 }
+
+func (r *MessageRouter) partition(appID string) int {
+	if len(r.partitions) == 1 {
+		return 0
+	}
+
+	if appID == "" || appID == envelope_extensions.SystemAppId {
+		return int(atomic.AddUint64(&r.next, 1) % uint64(len(r.partitions)))
+	}
+
+	// The envelopes of an app always go to the same partition so that they
+	// stay in order.
+	return int(keyhash.Sum32(appID) % uint32(len(r.partitions)))
+}
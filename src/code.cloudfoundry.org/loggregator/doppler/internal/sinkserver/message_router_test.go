@@ -1,15 +1,19 @@
 package sinkserver_test
 
 import (
+	"fmt"
+	"strconv"
 	"sync"
-
-	"code.cloudfoundry.org/loggregator/diodes"
+	"sync/atomic"
 
 	"code.cloudfoundry.org/loggregator/doppler/internal/sinkserver"
+	"code.cloudfoundry.org/loggregator/metricemitter/testhelper"
 
+	gendiodes "github.com/cloudfoundry/diodes"
 	"github.com/cloudfoundry/dropsonde/emitter"
 	"github.com/cloudfoundry/dropsonde/factories"
 	"github.com/cloudfoundry/sonde-go/events"
+	"github.com/gogo/protobuf/proto"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -50,6 +54,7 @@ var _ = Describe("Message Router", func() {
 	var (
 		fakeManagerA  *fakeSinkManager
 		fakeManagerB  *fakeSinkManager
+		metricClient  *testhelper.SpyMetricClient
 		messageRouter *sinkserver.MessageRouter
 	)
 
@@ -64,25 +69,145 @@ var _ = Describe("Message Router", func() {
 			receivedDrains:   make([][]string, 0),
 		}
 
-		messageRouter = sinkserver.NewMessageRouter(fakeManagerA, fakeManagerB)
+		metricClient = testhelper.NewMetricClient()
 	})
 
 	Describe("Start", func() {
 		Context("with an incoming message", func() {
-			var incoming *diodes.ManyToOneEnvelope
 			BeforeEach(func() {
-				incoming = diodes.NewManyToOneEnvelope(5, nil)
-				go messageRouter.Start(incoming)
+				messageRouter = sinkserver.NewMessageRouter(4, 5, nil, metricClient, fakeManagerA, fakeManagerB)
+				go messageRouter.Start()
 			})
 
 			It("sends the message to each sender if it is an app message", func() {
 				message, _ := emitter.Wrap(factories.NewLogMessage(events.LogMessage_OUT, "testMessage", "app", "App"), "origin")
-				incoming.Set(message)
+				messageRouter.Set(message)
 				Eventually(fakeManagerA.received).Should(HaveLen(1))
 				Eventually(fakeManagerB.received).Should(HaveLen(1))
 				Expect(fakeManagerA.received()[0].GetLogMessage()).To(Equal(message.GetLogMessage()))
 				Expect(fakeManagerB.received()[0].GetLogMessage()).To(Equal(message.GetLogMessage()))
 			})
 		})
+
+		Context("with many apps", func() {
+			BeforeEach(func() {
+				messageRouter = sinkserver.NewMessageRouter(4, 1000, nil, metricClient, fakeManagerA)
+				go messageRouter.Start()
+			})
+
+			It("sends the messages of each app in order", func() {
+				for i := 0; i < 50; i++ {
+					for j := 0; j < 10; j++ {
+						appID := fmt.Sprintf("app-%d", j)
+						message, _ := emitter.Wrap(factories.NewLogMessage(events.LogMessage_OUT, strconv.Itoa(i), appID, "App"), "origin")
+						messageRouter.Set(message)
+					}
+				}
+
+				Eventually(fakeManagerA.received).Should(HaveLen(500))
+
+				next := make(map[string]int)
+				for _, e := range fakeManagerA.received() {
+					appID := e.GetLogMessage().GetAppId()
+					Expect(string(e.GetLogMessage().GetMessage())).To(Equal(strconv.Itoa(next[appID])))
+					next[appID]++
+				}
+				Expect(next).To(HaveLen(10))
+			})
+		})
+
+		Context("with envelopes without an app ID", func() {
+			It("spreads them across the workers", func() {
+				sender := newBlockingSender()
+				defer close(sender.release)
+				messageRouter = sinkserver.NewMessageRouter(4, 100, nil, metricClient, sender)
+				go messageRouter.Start()
+
+				for i := 0; i < 4; i++ {
+					messageRouter.Set(counterEvent())
+				}
+
+				Eventually(sender.inFlight).Should(Equal(int64(4)))
+			})
+		})
+
+		It("reports the queue depth of the workers", func() {
+			sender := newBlockingSender()
+			defer close(sender.release)
+			messageRouter = sinkserver.NewMessageRouter(1, 100, nil, metricClient, sender)
+			go messageRouter.Start()
+
+			for i := 0; i < 3; i++ {
+				message, _ := emitter.Wrap(factories.NewLogMessage(events.LogMessage_OUT, "testMessage", "app", "App"), "origin")
+				messageRouter.Set(message)
+			}
+
+			Eventually(func() float64 {
+				return metricClient.GetValue("router.queue_depth")
+			}).Should(Equal(2.0))
+		})
+
+		Context("when a worker falls behind", func() {
+			It("drops envelopes and reports them", func() {
+				var alerted int
+				var mu sync.Mutex
+				alerter := gendiodes.AlertFunc(func(missed int) {
+					mu.Lock()
+					defer mu.Unlock()
+					alerted += missed
+				})
+				messageRouter = sinkserver.NewMessageRouter(1, 5, alerter, metricClient, fakeManagerA)
+
+				for i := 0; i < 10; i++ {
+					message, _ := emitter.Wrap(factories.NewLogMessage(events.LogMessage_OUT, "testMessage", "app", "App"), "origin")
+					messageRouter.Set(message)
+				}
+
+				go messageRouter.Start()
+
+				Eventually(func() uint64 {
+					return metricClient.GetDelta("router.dropped")
+				}).Should(BeNumerically(">", 0))
+				Eventually(func() int {
+					mu.Lock()
+					defer mu.Unlock()
+					return alerted
+				}).Should(BeNumerically(">", 0))
+				Eventually(func() float64 {
+					return metricClient.GetValue("router.queue_depth")
+				}).Should(Equal(0.0))
+			})
+		})
 	})
 })
+
+type blockingSender struct {
+	count   int64
+	release chan struct{}
+}
+
+func newBlockingSender() *blockingSender {
+	return &blockingSender{
+		release: make(chan struct{}),
+	}
+}
+
+func (s *blockingSender) SendTo(appID string, e *events.Envelope) {
+	atomic.AddInt64(&s.count, 1)
+	<-s.release
+}
+
+func (s *blockingSender) inFlight() int64 {
+	return atomic.LoadInt64(&s.count)
+}
+
+func counterEvent() *events.Envelope {
+	return &events.Envelope{
+		Origin:    proto.String("origin"),
+		EventType: events.Envelope_CounterEvent.Enum(),
+		CounterEvent: &events.CounterEvent{
+			Name:  proto.String("some-counter"),
+			Delta: proto.Uint64(1),
+		},
+	}
+}
@@ -19,6 +19,7 @@ type SinkManagerMetrics struct {
 	dumpSinks        int32
 	websocketSinks   int32
 	syslogSinks      int32
+	aggregateDrains  int32
 	firehoseSinks    int32
 	containerMetrics int32
 	done             chan struct{}
@@ -49,6 +50,9 @@ func (s *SinkManagerMetrics) run(ticker *time.Ticker) {
 		// metric-documentation-v1: (messageRouter.numberOfSyslogSinks) Number of
 		// syslog sinks
 		metrics.SendValue("messageRouter.numberOfSyslogSinks", float64(atomic.LoadInt32(&s.syslogSinks)), "sinks")
+		// metric-documentation-v1: (messageRouter.numberOfAggregateDrainSinks)
+		// Number of aggregate drain sinks
+		metrics.SendValue("messageRouter.numberOfAggregateDrainSinks", float64(atomic.LoadInt32(&s.aggregateDrains)), "sinks")
 		// metric-documentation-v1: (messageRouter.numberOfFirehoseSinks) Number of
 		// firehose sinks
 		metrics.SendValue("messageRouter.numberOfFirehoseSinks", float64(atomic.LoadInt32(&s.firehoseSinks)), "sinks")
@@ -64,6 +68,8 @@ func (s *SinkManagerMetrics) Inc(sink sinks.Sink) {
 		atomic.AddInt32(&s.dumpSinks, 1)
 	case *syslog.SyslogSink:
 		atomic.AddInt32(&s.syslogSinks, 1)
+	case *syslog.AggregateDrainSink:
+		atomic.AddInt32(&s.aggregateDrains, 1)
 	case *websocket.WebsocketSink:
 		atomic.AddInt32(&s.websocketSinks, 1)
 	case *containermetric.ContainerMetricSink:
@@ -77,6 +83,8 @@ func (s *SinkManagerMetrics) Dec(sink sinks.Sink) {
 		atomic.AddInt32(&s.dumpSinks, -1)
 	case *syslog.SyslogSink:
 		atomic.AddInt32(&s.syslogSinks, -1)
+	case *syslog.AggregateDrainSink:
+		atomic.AddInt32(&s.aggregateDrains, -1)
 	case *websocket.WebsocketSink:
 		atomic.AddInt32(&s.websocketSinks, -1)
 	case *containermetric.ContainerMetricSink:
@@ -101,6 +101,34 @@ var _ = Describe("SinkManagerMetrics", func() {
 		Eventually(fakeEventEmitter.GetMessages, 2).Should(ContainElement(expected))
 	})
 
+	It("emits metrics for aggregate drain sinks", func() {
+		Eventually(fakeEventEmitter.GetMessages).Should(BeEmpty())
+
+		sink = &syslog.AggregateDrainSink{}
+		sinkManagerMetrics.Inc(sink)
+
+		expected := fake.Message{
+			Origin: "doppler",
+			Event: &events.ValueMetric{
+				Name:  proto.String("messageRouter.numberOfAggregateDrainSinks"),
+				Value: proto.Float64(1),
+				Unit:  proto.String("sinks"),
+			},
+		}
+		Eventually(fakeEventEmitter.GetMessages, 2).Should(ContainElement(expected))
+
+		fakeEventEmitter.Reset()
+
+		sinkManagerMetrics.Dec(sink)
+
+		expected.Event = &events.ValueMetric{
+			Name:  proto.String("messageRouter.numberOfAggregateDrainSinks"),
+			Value: proto.Float64(0),
+			Unit:  proto.String("sinks"),
+		}
+		Eventually(fakeEventEmitter.GetMessages, 2).Should(ContainElement(expected))
+	})
+
 	XIt("emits metrics for websocket sinks", func() {
 		Eventually(fakeEventEmitter.GetMessages).Should(BeEmpty())
 
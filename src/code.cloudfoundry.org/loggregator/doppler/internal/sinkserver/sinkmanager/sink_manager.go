@@ -3,6 +3,8 @@ package sinkmanager
 import (
 	"fmt"
 	"log"
+	"net"
+	"net/url"
 	"sync"
 	"time"
 
@@ -42,8 +44,9 @@ type SinkManager struct {
 	messageDrainBufferSize uint
 	dropsondeOrigin        string
 
-	metrics        *metrics.SinkManagerMetrics
-	recentLogCount uint32
+	metrics          *metrics.SinkManagerMetrics
+	recentLogCount   uint32
+	recentLogsBudget *dump.Budget
 
 	doneChannel         chan struct{}
 	errorChannel        chan *events.Envelope
@@ -55,12 +58,38 @@ type SinkManager struct {
 	metricTTL           time.Duration
 	dialTimeout         time.Duration
 	health              HealthRegistrar
+	metricClient        MetricClient
+	blacklistedMetric   *metricemitter.Counter
 
 	stopOnce sync.Once
 }
 
+// AggregateDrain is an operator configured syslog drain that receives the
+// logs of every app. Drains can be limited to source types but not to
+// organizations, as Doppler does not know the organization of an app.
+type AggregateDrain struct {
+	Name     string
+	URL      string
+	Hostname string
+
+	// BufferSize overrides the message drain buffer size of the SinkManager
+	// when it is not zero.
+	BufferSize uint
+
+	// SourceTypes limits the drain to logs with the given source types
+	// (e.g. APP, RTR). A source type of APP also matches APP/PROC/WEB. All
+	// source types are written when it is empty.
+	SourceTypes []string
+
+	// IncludePlatformLogs also writes logs that do not belong to an app.
+	IncludePlatformLogs bool
+}
+
+// New returns a SinkManager. The recent logs of all apps are limited by the
+// given Budget. It may be nil to only limit the number of messages per app.
 func New(
 	maxRetainedLogMessages uint32,
+	recentLogsBudget *dump.Budget,
 	skipCertVerify bool,
 	blackListManager *blacklist.URLBlacklistManager,
 	messageDrainBufferSize uint,
@@ -80,6 +109,7 @@ func New(
 		sinks:                  groupedsinks.NewGroupedSinks(metricBatcher, metricClient),
 		skipCertVerify:         skipCertVerify,
 		recentLogCount:         maxRetainedLogMessages,
+		recentLogsBudget:       recentLogsBudget,
 		metrics:                metrics.NewSinkManagerMetrics(),
 		messageDrainBufferSize: messageDrainBufferSize,
 		dropsondeOrigin:        dropsondeOrigin,
@@ -88,6 +118,10 @@ func New(
 		metricTTL:              metricTTL,
 		dialTimeout:            dialTimeout,
 		health:                 health,
+		metricClient:           metricClient,
+		blacklistedMetric: metricClient.NewCounter("sinks.blacklisted",
+			metricemitter.WithVersion(2, 0),
+		),
 	}
 }
 
@@ -173,6 +207,89 @@ func (sm *SinkManager) UnregisterFirehoseSink(sink sinks.Sink) {
 	sm.metrics.DecFirehose()
 }
 
+// RegisterAggregateDrain registers a syslog sink for the drain that receives
+// the logs of all apps. Aggregate drains are configured by the operator and
+// are therefore not checked against the blacklist.
+func (sm *SinkManager) RegisterAggregateDrain(d AggregateDrain) error {
+	drainURL, err := url.Parse(d.URL)
+	if err != nil {
+		return err
+	}
+
+	writer, err := syslogwriter.NewWriter(
+		drainURL,
+		d.Name,
+		d.Hostname,
+		sm.skipCertVerify,
+		&net.Dialer{Timeout: sm.dialTimeout},
+		sm.sinkIOTimeout,
+	)
+	if err != nil {
+		return err
+	}
+
+	bufferSize := sm.messageDrainBufferSize
+	if d.BufferSize != 0 {
+		bufferSize = d.BufferSize
+	}
+
+	// metric-documentation-v2: (loggregator.doppler.aggregate_drain.dropped)
+	// Number of log messages dropped by an aggregate drain because its
+	// buffer was full.
+	droppedMetric := sm.metricClient.NewCounter("aggregate_drain.dropped",
+		metricemitter.WithVersion(2, 0),
+		metricemitter.WithTags(map[string]string{"drain": d.Name}),
+	)
+
+	sink := syslog.NewAggregateDrainSink(
+		d.Name,
+		drainURL,
+		bufferSize,
+		writer,
+		sm.dropsondeOrigin,
+		syslog.WithFilter(aggregateDrainFilter(d)),
+		syslog.WithDroppedMetric(droppedMetric),
+	)
+
+	inputChan := make(chan *events.Envelope, 128)
+	if !sm.sinks.RegisterFirehoseSink(inputChan, sink) {
+		return fmt.Errorf("aggregate drain %s is already registered", d.Name)
+	}
+
+	// metric-documentation-v1: see sink_manager_metrics.go for details
+	sm.metrics.Inc(sink)
+
+	go func() {
+		sink.Run(inputChan)
+		sm.unregisterAggregateDrain(sink)
+	}()
+
+	return nil
+}
+
+func (sm *SinkManager) unregisterAggregateDrain(sink *syslog.AggregateDrainSink) {
+	ok := sm.sinks.CloseAndDeleteFirehose(sink)
+	if !ok {
+		return
+	}
+	sm.metrics.Dec(sink)
+	sink.Disconnect()
+}
+
+func aggregateDrainFilter(d AggregateDrain) syslog.Filter {
+	return func(m *events.LogMessage) bool {
+		if m.GetAppId() == "" && !d.IncludePlatformLogs {
+			return false
+		}
+
+		if len(d.SourceTypes) == 0 {
+			return true
+		}
+
+		return syslog.MatchesSourceType(m.GetSourceType(), d.SourceTypes)
+	}
+}
+
 func (sm *SinkManager) RecentLogsFor(appId string) []*events.Envelope {
 	if sink := sm.sinks.DumpFor(appId); sink != nil {
 		return sink.Dump()
@@ -219,7 +336,7 @@ func (sm *SinkManager) listenForDeletedAppServices(deletedAppServiceChan <-chan
 		case <-sm.doneChannel:
 			return
 		case appService := <-deletedAppServiceChan:
-			syslogSink := sm.sinks.DrainFor(appService.AppId(), appService.Url())
+			syslogSink := sm.sinks.DrainFor(appService.AppId(), drainIdentifier(appService.Url()))
 			if syslogSink != nil {
 				sm.UnregisterSink(syslogSink)
 			}
@@ -249,20 +366,37 @@ func (sm *SinkManager) registerNewSyslogSink(appId, syslogSinkURL, hostname stri
 		return
 	}
 
+	filter, writerURL, err := syslog.ParseFilter(parsedSyslogDrainURL)
+	if err != nil {
+		sm.SendSyslogErrorToLoggregator(invalidSyslogFilterErrorMsg(appId, drainIdentifier(syslogSinkURL), err), appId)
+		return
+	}
+
+	dialer := sm.urlBlacklistManager.Dialer(sm.dialTimeout, func(address string, ip net.IP) {
+		log.Printf("SinkManager: refused to connect to %s for application %s: %s is blacklisted", address, appId, ip)
+
+		// metric-documentation-v2: (loggregator.doppler.sinks.blacklisted)
+		// Number of syslog drain connections refused because the drain host
+		// resolved to a blacklisted IP.
+		sm.blacklistedMetric.Increment(1)
+	})
+
 	syslogWriter, err := syslogwriter.NewWriter(
-		parsedSyslogDrainURL,
+		writerURL,
 		appId,
 		hostname,
 		sm.skipCertVerify,
-		sm.dialTimeout,
+		dialer,
 		sm.sinkIOTimeout,
 	)
 	if err != nil {
-		logURL := fmt.Sprintf("%s://%s%s", parsedSyslogDrainURL.Scheme, parsedSyslogDrainURL.Host, parsedSyslogDrainURL.Path)
+		logURL := fmt.Sprintf("%s://%s%s", writerURL.Scheme, writerURL.Host, writerURL.Path)
 		sm.SendSyslogErrorToLoggregator(invalidSyslogURLErrorMsg(appId, logURL, err), appId)
 		return
 	}
 
+	// The sink is identified by the drain URL including its filter, so
+	// drains that only differ by their filter are kept apart.
 	syslogSink := syslog.NewSyslogSink(
 		appId,
 		parsedSyslogDrainURL,
@@ -270,6 +404,7 @@ func (sm *SinkManager) registerNewSyslogSink(appId, syslogSinkURL, hostname stri
 		syslogWriter,
 		sm.SendSyslogErrorToLoggregator,
 		sm.dropsondeOrigin,
+		syslog.WithFilter(filter),
 	)
 
 	sm.RegisterSink(syslogSink)
@@ -279,16 +414,36 @@ func invalidSyslogURLErrorMsg(appId string, syslogSinkURL string, err error) str
 	return fmt.Sprintf("SinkManager: Invalid syslog drain URL (%s) for application %s. Err: %v", syslogSinkURL, appId, err)
 }
 
+func invalidSyslogFilterErrorMsg(appId string, syslogSinkURL string, err error) string {
+	return fmt.Sprintf("SinkManager: Invalid syslog drain filter for %s for application %s. Err: %v", syslogSinkURL, appId, err)
+}
+
+// drainIdentifier returns the identifier a syslog sink for the URL is
+// registered with.
+func drainIdentifier(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return syslog.DrainIdentifier(u)
+}
+
 func (sm *SinkManager) ensureRecentLogsSinkFor(appId string) {
 	if sm.sinks.DumpFor(appId) != nil {
 		return
 	}
 
+	var opts []dump.DumpSinkOption
+	if sm.recentLogsBudget != nil {
+		opts = append(opts, dump.WithBudget(sm.recentLogsBudget))
+	}
+
 	sink := dump.NewDumpSink(
 		appId,
 		sm.recentLogCount,
 		sm.sinkTimeout,
 		sm.health,
+		opts...,
 	)
 
 	sm.RegisterSink(sink)
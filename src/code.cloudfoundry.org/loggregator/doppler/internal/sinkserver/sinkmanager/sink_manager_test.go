@@ -1,6 +1,9 @@
 package sinkmanager_test
 
 import (
+	"bufio"
+	"io"
+	"log"
 	"net"
 	"net/url"
 	"sync"
@@ -23,10 +26,10 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 )
 
 var _ = Describe("SinkManager", func() {
-	var blackListManager = blacklist.New([]iprange.IPRange{{Start: "10.10.10.10", End: "10.10.10.20"}})
 	var sinkManager *sinkmanager.SinkManager
 	var sinkManagerDone chan struct{}
 	var newAppServiceChan, deletedAppServiceChan chan store.AppService
@@ -34,8 +37,11 @@ var _ = Describe("SinkManager", func() {
 	BeforeEach(func() {
 		fakeMetricSender.Reset()
 
+		blackListManager, err := blacklist.New([]iprange.IPRange{{Start: "10.10.10.10", End: "10.10.10.20"}})
+		Expect(err).ToNot(HaveOccurred())
+
 		health := newSpyHealthRegistrar()
-		sinkManager = sinkmanager.New(1, true, blackListManager, 100,
+		sinkManager = sinkmanager.New(1, nil, true, blackListManager, 100,
 			"dropsonde-origin", 1*time.Second, 0, 1*time.Second,
 			1*time.Second, nil, testhelper.NewMetricClient(), health)
 
@@ -214,6 +220,14 @@ var _ = Describe("SinkManager", func() {
 						Expect(string(errorMsg.GetLogMessage().GetMessage())).To(MatchRegexp("Invalid syslog drain URL"))
 					})
 
+					It("sends an error message if the drain filter is malformed", func() {
+						newAppServiceChan <- store.NewServiceInfo("aptastic", "syslog://127.0.1.1:884?log-types=debug", "org.space.app.1")
+						Eventually(errorSink.Received).Should(HaveLen(1))
+						errorMsg := errorSink.Received()[0]
+						Expect(string(errorMsg.GetLogMessage().GetMessage())).To(MatchRegexp("Invalid syslog drain filter"))
+						Expect(errorMsg.GetLogMessage().GetSourceType()).To(Equal("LGR"))
+					})
+
 					It("sends an error message if the drain URL is invalid", func() {
 						newAppServiceChan <- store.NewServiceInfo("aptastic", "syslog//invalid", "org.space.app.1")
 						Eventually(errorSink.Received).Should(HaveLen(1))
@@ -239,6 +253,37 @@ var _ = Describe("SinkManager", func() {
 					}, 2).Should(Equal(initialNumSinks))
 				})
 
+				It("deletes a syslog sink with filter parameters", func() {
+					initialNumSinks := fakeMetricSender.GetValue("messageRouter.numberOfSyslogSinks").Value
+					newAppServiceChan <- store.NewServiceInfo("aptastic", "syslog://127.0.1.1:886?log-types=out", "org.space.app.1")
+
+					Eventually(func() float64 {
+						return fakeMetricSender.GetValue("messageRouter.numberOfSyslogSinks").Value
+					}, 2).Should(Equal(initialNumSinks + 1))
+
+					deletedAppServiceChan <- store.NewServiceInfo("aptastic", "syslog://127.0.1.1:886?log-types=out", "org.space.app.1")
+
+					Eventually(func() float64 {
+						return fakeMetricSender.GetValue("messageRouter.numberOfSyslogSinks").Value
+					}, 2).Should(Equal(initialNumSinks))
+				})
+
+				It("keeps drains that only differ by their filter apart", func() {
+					initialNumSinks := fakeMetricSender.GetValue("messageRouter.numberOfSyslogSinks").Value
+					newAppServiceChan <- store.NewServiceInfo("aptastic", "syslog://127.0.1.1:886?log-types=out", "org.space.app.1")
+					newAppServiceChan <- store.NewServiceInfo("aptastic", "syslog://127.0.1.1:886?log-types=err", "org.space.app.1")
+
+					Eventually(func() float64 {
+						return fakeMetricSender.GetValue("messageRouter.numberOfSyslogSinks").Value
+					}, 2).Should(Equal(initialNumSinks + 2))
+
+					deletedAppServiceChan <- store.NewServiceInfo("aptastic", "syslog://127.0.1.1:886?log-types=out", "org.space.app.1")
+
+					Eventually(func() float64 {
+						return fakeMetricSender.GetValue("messageRouter.numberOfSyslogSinks").Value
+					}, 2).Should(Equal(initialNumSinks + 1))
+				})
+
 				It("handles a delete for a nonexistent sink", func() {
 					initialNumSinks := fakeMetricSender.GetValue("messageRouter.numberOfSyslogSinks").Value
 					deletedAppServiceChan <- store.NewServiceInfo("aptastic", "syslog://127.0.1.1:886", "org.space.app.1")
@@ -386,6 +431,138 @@ var _ = Describe("SinkManager", func() {
 		})
 	})
 
+	Describe("RegisterAggregateDrain", func() {
+		var (
+			listener net.Listener
+			lines    chan string
+		)
+
+		BeforeEach(func() {
+			var err error
+			listener, err = net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).ToNot(HaveOccurred())
+
+			lines = make(chan string, 100)
+			go func() {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+
+				r := bufio.NewReader(conn)
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					lines <- line
+				}
+			}()
+		})
+
+		AfterEach(func() {
+			listener.Close()
+		})
+
+		sendLog := func(appID, sourceType, msg string) {
+			e, _ := emitter.Wrap(factories.NewLogMessage(events.LogMessage_OUT, msg, appID, sourceType), "origin")
+			sinkManager.SendTo(appID, e)
+		}
+
+		It("writes logs for every app with the app ID of each log", func() {
+			err := sinkManager.RegisterAggregateDrain(sinkmanager.AggregateDrain{
+				Name:     "siem",
+				URL:      "syslog://" + listener.Addr().String(),
+				Hostname: "loggregator",
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			sendLog("app-1", "APP/PROC/WEB", "from app 1")
+			sendLog("app-2", "RTR", "from app 2")
+
+			Eventually(lines).Should(Receive(MatchRegexp(`loggregator app-1 \[APP/PROC/WEB/\] - - from app 1`)))
+			Eventually(lines).Should(Receive(MatchRegexp(`loggregator app-2 \[RTR\] - - from app 2`)))
+		})
+
+		It("only writes logs with the configured source types", func() {
+			err := sinkManager.RegisterAggregateDrain(sinkmanager.AggregateDrain{
+				Name:        "siem",
+				URL:         "syslog://" + listener.Addr().String(),
+				SourceTypes: []string{"APP"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			sendLog("app-1", "RTR", "router log")
+			sendLog("app-1", "APP/PROC/WEB", "app log")
+
+			Eventually(lines).Should(Receive(ContainSubstring("app log")))
+			Consistently(lines).ShouldNot(Receive())
+		})
+
+		It("only writes platform logs when configured to", func() {
+			err := sinkManager.RegisterAggregateDrain(sinkmanager.AggregateDrain{
+				Name: "siem",
+				URL:  "syslog://" + listener.Addr().String(),
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			sendLog("", "CELL", "platform log")
+			sendLog("app-1", "APP/PROC/WEB", "app log")
+
+			Eventually(lines).Should(Receive(ContainSubstring("app log")))
+			Consistently(lines).ShouldNot(Receive())
+		})
+
+		It("counts aggregate drains apart from firehose sinks", func() {
+			err := sinkManager.RegisterAggregateDrain(sinkmanager.AggregateDrain{
+				Name: "siem",
+				URL:  "syslog://" + listener.Addr().String(),
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(func() float64 {
+				return fakeMetricSender.GetValue("messageRouter.numberOfAggregateDrainSinks").Value
+			}, 2).Should(BeEquivalentTo(1))
+			Expect(fakeMetricSender.GetValue("messageRouter.numberOfFirehoseSinks").Value).To(BeZero())
+		})
+
+		It("logs errors with the name of the drain", func() {
+			logs := gbytes.NewBuffer()
+			log.SetOutput(io.MultiWriter(logs, GinkgoWriter))
+			defer log.SetOutput(GinkgoWriter)
+
+			addr := listener.Addr().String()
+			listener.Close()
+			err := sinkManager.RegisterAggregateDrain(sinkmanager.AggregateDrain{
+				Name: "siem",
+				URL:  "syslog://" + addr,
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			sendLog("app-1", "APP/PROC/WEB", "app log")
+
+			Eventually(logs).Should(gbytes.Say("Aggregate drain siem: .*Error when dialing out"))
+		})
+
+		It("returns an error for an invalid drain URL", func() {
+			err := sinkManager.RegisterAggregateDrain(sinkmanager.AggregateDrain{
+				Name: "siem",
+				URL:  "ftp://example.com",
+			})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("returns an error for a duplicate drain", func() {
+			d := sinkmanager.AggregateDrain{
+				Name: "siem",
+				URL:  "syslog://" + listener.Addr().String(),
+			}
+			Expect(sinkManager.RegisterAggregateDrain(d)).To(Succeed())
+			Expect(sinkManager.RegisterAggregateDrain(d)).ToNot(Succeed())
+		})
+	})
+
 	Describe("UnregisterFirehoseSink", func() {
 
 		It("stops the sink and updates metrics", func() {
@@ -19,8 +19,7 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/onsi/ginkgo/config"
 
-	"code.cloudfoundry.org/loggregator/diodes"
-
+	"code.cloudfoundry.org/loggregator/doppler/internal/marshaler"
 	"code.cloudfoundry.org/loggregator/doppler/internal/sinkserver"
 	"code.cloudfoundry.org/loggregator/doppler/internal/sinkserver/blacklist"
 	"code.cloudfoundry.org/loggregator/doppler/internal/sinkserver/sinkmanager"
@@ -33,7 +32,6 @@ var _ = Describe("Dumping", func() {
 		sinkManager         *sinkmanager.SinkManager
 		TestMessageRouter   *sinkserver.MessageRouter
 		TestWebsocketServer *websocketserver.WebsocketServer
-		dataRead            *diodes.ManyToOneEnvelope
 		services            sync.WaitGroup
 		serverPort          string
 		mockBatcher         *mockBatcher
@@ -48,14 +46,14 @@ var _ = Describe("Dumping", func() {
 
 		port := 9081 + config.GinkgoConfig.ParallelNode
 		serverPort = strconv.Itoa(port)
-		dataRead = diodes.NewManyToOneEnvelope(5, nil)
 
 		newAppServiceChan := make(chan store.AppService)
 		deletedAppServiceChan := make(chan store.AppService)
 
-		emptyBlacklist := blacklist.New(nil)
+		emptyBlacklist, err := blacklist.New(nil)
+		Expect(err).ToNot(HaveOccurred())
 		health := newSpyHealthRegistrar()
-		sinkManager = sinkmanager.New(1024, false, emptyBlacklist, 100, "dropsonde-origin",
+		sinkManager = sinkmanager.New(1024, nil, false, emptyBlacklist, 100, "dropsonde-origin",
 			2*time.Second, 0, 1*time.Second, 500*time.Millisecond, nil, testhelper.NewMetricClient(), health)
 
 		services.Add(1)
@@ -64,15 +62,10 @@ var _ = Describe("Dumping", func() {
 			sinkManager.Start(newAppServiceChan, deletedAppServiceChan)
 		}(sinkManager)
 
-		TestMessageRouter = sinkserver.NewMessageRouter(sinkManager)
-		tempMessageRouter := TestMessageRouter
-
-		go func(dataRead *diodes.ManyToOneEnvelope) {
-			tempMessageRouter.Start(dataRead)
-		}(dataRead)
+		TestMessageRouter = sinkserver.NewMessageRouter(1, 5, nil, testhelper.NewMetricClient(), sinkManager)
+		go TestMessageRouter.Start()
 
 		apiEndpoint := "localhost:" + serverPort
-		var err error
 		TestWebsocketServer, err = websocketserver.New(
 			apiEndpoint,
 			sinkManager,
@@ -81,6 +74,7 @@ var _ = Describe("Dumping", func() {
 			100,
 			"dropsonde-origin",
 			mockBatcher,
+			marshaler.New(100),
 		)
 		Expect(err).NotTo(HaveOccurred())
 
@@ -107,8 +101,8 @@ var _ = Describe("Dumping", func() {
 		lm = factories.NewLogMessage(events.LogMessage_OUT, expectedSecondMessageString, "myOtherApp", "APP")
 		env2, _ := emitter.Wrap(lm, "ORIGIN")
 
-		dataRead.Set(env1)
-		dataRead.Set(env2)
+		TestMessageRouter.Set(env1)
+		TestMessageRouter.Set(env2)
 
 		var receivedChan chan []byte
 		Eventually(func() int {
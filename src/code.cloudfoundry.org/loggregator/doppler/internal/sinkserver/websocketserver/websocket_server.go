@@ -15,7 +15,6 @@ import (
 
 	"github.com/cloudfoundry/dropsonde/metricbatcher"
 	"github.com/cloudfoundry/sonde-go/events"
-	"github.com/gogo/protobuf/proto"
 	gorilla "github.com/gorilla/websocket"
 )
 
@@ -77,6 +76,7 @@ type WebsocketServer struct {
 	batcher           Batcher
 	listener          net.Listener
 	dropsondeOrigin   string
+	marshaler         websocket.Marshaler
 
 	done chan struct{}
 }
@@ -89,6 +89,7 @@ func New(
 	messageDrainBufferSize uint,
 	dropsondeOrigin string,
 	batcher Batcher,
+	marshaler websocket.Marshaler,
 ) (*WebsocketServer, error) {
 	listener, err := net.Listen("tcp", apiEndpoint)
 	if err != nil {
@@ -104,6 +105,7 @@ func New(
 		bufferSize:        messageDrainBufferSize,
 		batcher:           batcher,
 		dropsondeOrigin:   dropsondeOrigin,
+		marshaler:         marshaler,
 		done:              make(chan struct{}),
 	}, nil
 }
@@ -227,6 +229,7 @@ func (w *WebsocketServer) streamLogs(appId string, websocketConnection *gorilla.
 	)
 
 	websocketSink.SetCounter(newStreamCounter(w.batcher))
+	websocketSink.SetMarshaler(w.marshaler)
 
 	w.streamWebsocket(websocketSink, websocketConnection, w.sinkManager.RegisterSink, w.sinkManager.UnregisterSink)
 }
@@ -242,6 +245,7 @@ func (w *WebsocketServer) streamFirehose(subscriptionId string, websocketConnect
 
 	firehoseCounter := newFirehoseCounter(subscriptionId, w.batcher)
 	websocketSink.SetCounter(firehoseCounter)
+	websocketSink.SetMarshaler(w.marshaler)
 
 	w.streamWebsocket(websocketSink, websocketConnection, w.sinkManager.RegisterFirehoseSink, w.sinkManager.UnregisterFirehoseSink)
 }
@@ -264,17 +268,17 @@ func (w *WebsocketServer) streamWebsocket(websocketSink *websocket.WebsocketSink
 
 func (w *WebsocketServer) recentLogs(appId string, websocketConnection *gorilla.Conn) {
 	logMessages := w.sinkManager.RecentLogsFor(appId)
-	sendMessagesToWebsocket("recentlogs", logMessages, websocketConnection, w.batcher)
+	sendMessagesToWebsocket("recentlogs", logMessages, websocketConnection, w.batcher, w.marshaler)
 }
 
 func (w *WebsocketServer) latestContainerMetrics(appId string, websocketConnection *gorilla.Conn) {
 	metrics := w.sinkManager.LatestContainerMetrics(appId)
-	sendMessagesToWebsocket("containermetrics", metrics, websocketConnection, w.batcher)
+	sendMessagesToWebsocket("containermetrics", metrics, websocketConnection, w.batcher, w.marshaler)
 }
 
-func sendMessagesToWebsocket(endpoint string, envelopes []*events.Envelope, websocketConnection *gorilla.Conn, batcher Batcher) {
+func sendMessagesToWebsocket(endpoint string, envelopes []*events.Envelope, websocketConnection *gorilla.Conn, batcher Batcher, marshaler websocket.Marshaler) {
 	for _, messageEnvelope := range envelopes {
-		envelopeBytes, err := proto.Marshal(messageEnvelope)
+		envelopeBytes, err := marshaler.Marshal(messageEnvelope)
 		if err != nil {
 			log.Printf("Websocket Server %s: Error marshalling %s envelope from origin %s: %s", websocketConnection.RemoteAddr(), messageEnvelope.GetEventType().String(), messageEnvelope.GetOrigin(), err.Error())
 			continue
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"time"
 
+	"code.cloudfoundry.org/loggregator/doppler/internal/marshaler"
 	"code.cloudfoundry.org/loggregator/doppler/internal/sinkserver/blacklist"
 	"code.cloudfoundry.org/loggregator/doppler/internal/sinkserver/sinkmanager"
 	"code.cloudfoundry.org/loggregator/doppler/internal/sinkserver/websocketserver"
@@ -28,10 +29,8 @@ import (
 // WebsocketSinks are a deprecated code path
 var _ = XDescribe("WebsocketServer", func() {
 	var (
-		server      *websocketserver.WebsocketServer
-		sinkManager = sinkmanager.New(1024, false, blacklist.New(nil),
-			100, "dropsonde-origin", 1*time.Second, 0, 1*time.Second,
-			500*time.Millisecond, nil, testhelper.NewMetricClient(), nil)
+		server         *websocketserver.WebsocketServer
+		sinkManager    *sinkmanager.SinkManager
 		appId          = "my-app"
 		wsReceivedChan chan []byte
 		apiEndpoint    string
@@ -47,7 +46,12 @@ var _ = XDescribe("WebsocketServer", func() {
 
 		wsReceivedChan = make(chan []byte, 100)
 
-		var err error
+		emptyBlacklist, err := blacklist.New(nil)
+		Expect(err).NotTo(HaveOccurred())
+		sinkManager = sinkmanager.New(1024, nil, false, emptyBlacklist,
+			100, "dropsonde-origin", 1*time.Second, 0, 1*time.Second,
+			500*time.Millisecond, nil, testhelper.NewMetricClient(), nil)
+
 		server, err = websocketserver.New(
 			"127.0.0.1:0",
 			sinkManager,
@@ -56,6 +60,7 @@ var _ = XDescribe("WebsocketServer", func() {
 			100,
 			"dropsonde-origin",
 			mockBatcher,
+			marshaler.New(100),
 		)
 		Expect(err).NotTo(HaveOccurred())
 		apiEndpoint = server.Addr()
@@ -12,6 +12,18 @@ type BufferContext interface {
 	AppID(*events.Envelope) string
 }
 
+// EnvelopeFilter can be implemented by a BufferContext that needs more than
+// the event type to decide if an envelope should be buffered.
+type EnvelopeFilter interface {
+	EnvelopeAllowed(*events.Envelope) bool
+}
+
+// DropNotifier can be implemented by a BufferContext that wants to be told
+// when envelopes are dropped.
+type DropNotifier interface {
+	MessagesDropped(delta uint64)
+}
+
 type DefaultContext struct {
 	destination string
 	origin      string
@@ -58,6 +58,18 @@ func (r *TruncatingBuffer) eventAllowed(eventType events.Envelope_EventType) boo
 	return r.context.EventAllowed(eventType)
 }
 
+func (r *TruncatingBuffer) envelopeAllowed(msg *events.Envelope) bool {
+	if !r.eventAllowed(msg.GetEventType()) {
+		return false
+	}
+
+	f, ok := r.context.(EnvelopeFilter)
+	if !ok {
+		return true
+	}
+	return f.EnvelopeAllowed(msg)
+}
+
 func (r *TruncatingBuffer) Run() {
 	defer r.closeOutputChannel()
 	for {
@@ -68,7 +80,7 @@ func (r *TruncatingBuffer) Run() {
 			if !ok {
 				return
 			}
-			if r.eventAllowed(msg.GetEventType()) {
+			if r.envelopeAllowed(msg) {
 				r.forwardMessage(msg)
 			}
 		}
@@ -129,6 +141,9 @@ func (r *TruncatingBuffer) dropMessages() uint64 {
 
 func (r *TruncatingBuffer) notifyMessagesDropped(deltaDropped, totalDropped uint64, appId string) {
 	metrics.BatchAddCounter("TruncatingBuffer.totalDroppedMessages", deltaDropped)
+	if n, ok := r.context.(DropNotifier); ok {
+		n.MessagesDropped(deltaDropped)
+	}
 	if r.eventAllowed(events.Envelope_LogMessage) {
 		r.emitMessage(generateLogMessage(deltaDropped, totalDropped, appId, r.context.Origin(), r.context.Destination()))
 	}
@@ -72,6 +72,20 @@ func (f *LogFilteredContext) EventAllowed(eventType events.Envelope_EventType) b
 	return true
 }
 
+type EnvelopeFilterContext struct {
+	allowedMessage string
+	dropped        chan uint64
+	FakeContext
+}
+
+func (f *EnvelopeFilterContext) EnvelopeAllowed(e *events.Envelope) bool {
+	return string(e.GetLogMessage().GetMessage()) == f.allowedMessage
+}
+
+func (f *EnvelopeFilterContext) MessagesDropped(delta uint64) {
+	f.dropped <- delta
+}
+
 var _ = Describe("Truncating Buffer", func() {
 	var inMessageChan chan *events.Envelope
 	var stopChannel chan struct{}
@@ -325,6 +339,36 @@ var _ = Describe("Truncating Buffer", func() {
 				})
 			})
 		})
+
+		Context("when the context filters envelopes", func() {
+			var filterContext *EnvelopeFilterContext
+
+			BeforeEach(func() {
+				filterContext = &EnvelopeFilterContext{
+					allowedMessage: "keep",
+					dropped:        make(chan uint64, 10),
+				}
+				context = filterContext
+			})
+
+			It("only buffers allowed envelopes", func() {
+				sendLogMessages("skip", inMessageChan)
+				sendLogMessages("keep", inMessageChan)
+
+				var readMessage *events.Envelope
+				Eventually(buffer.GetOutputChannel).Should(Receive(&readMessage))
+				Expect(readMessage.GetLogMessage().GetMessage()).To(Equal([]byte("keep")))
+				Consistently(buffer.GetOutputChannel).ShouldNot(Receive())
+			})
+
+			It("notifies the context of dropped envelopes", func() {
+				for i := 0; i < 4; i++ {
+					sendLogMessages("keep", inMessageChan)
+				}
+
+				Eventually(filterContext.dropped).Should(Receive(Equal(uint64(3))))
+			})
+		})
 	})
 })
 
@@ -0,0 +1,289 @@
+package usage
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/doppler/internal/keyhash"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+
+	"github.com/cloudfoundry/dropsonde/envelope_extensions"
+	"github.com/cloudfoundry/sonde-go/events"
+)
+
+// OverflowSourceID accounts for the envelopes of source IDs seen after the
+// maximum number of source IDs has been reached within an interval.
+const OverflowSourceID = "overflow"
+
+// Usage is the number of envelopes and bytes of a source ID.
+type Usage struct {
+	Envelopes uint64 `json:"envelopes"`
+	Bytes     uint64 `json:"bytes"`
+}
+
+// SourceUsage is the usage of a source ID within an interval, in total and
+// by envelope type.
+type SourceUsage struct {
+	SourceID string           `json:"source_id"`
+	Total    Usage            `json:"total"`
+	ByType   map[string]Usage `json:"by_type"`
+}
+
+// Accountant counts the envelopes and bytes Doppler receives per source ID
+// and envelope type. The counts are reset every interval when the usage is
+// emitted. Source IDs are sharded so that workers accounting for different
+// source IDs rarely contend.
+type Accountant struct {
+	maxSources int64
+	sources    int64
+	shards     [shardCount]shard
+
+	startMu sync.Mutex
+	start   time.Time
+
+	lastMu       sync.RWMutex
+	last         []SourceUsage
+	lastInterval time.Duration
+}
+
+const shardCount = 16
+
+type shard struct {
+	mu      sync.Mutex
+	current map[string]map[events.Envelope_EventType]*Usage
+}
+
+// usageEntry is the usage of a source ID and envelope type.
+type usageEntry struct {
+	sourceID     string
+	envelopeType events.Envelope_EventType
+	usage        Usage
+}
+
+// NewAccountant returns an Accountant that tracks at most maxSources source
+// IDs per interval.
+func NewAccountant(maxSources int) *Accountant {
+	a := &Accountant{
+		maxSources: int64(maxSources),
+		start:      time.Now(),
+	}
+	for i := range a.shards {
+		a.shards[i].current = make(map[string]map[events.Envelope_EventType]*Usage)
+	}
+
+	return a
+}
+
+// SendTo accounts for the envelope. It implements
+// sinkserver.EnvelopeSender.
+func (a *Accountant) SendTo(appID string, e *events.Envelope) {
+	a.add(sourceID(appID, e), e.GetEventType(), Usage{Envelopes: 1, Bytes: uint64(e.Size())})
+}
+
+func (a *Accountant) add(sourceID string, t events.Envelope_EventType, u Usage) {
+	s := a.shard(sourceID)
+	s.mu.Lock()
+	byType, ok := s.current[sourceID]
+	if !ok && sourceID != OverflowSourceID {
+		if atomic.AddInt64(&a.sources, 1) > a.maxSources {
+			atomic.AddInt64(&a.sources, -1)
+			s.mu.Unlock()
+
+			a.add(OverflowSourceID, t, u)
+			return
+		}
+	}
+	if byType == nil {
+		byType = make(map[events.Envelope_EventType]*Usage)
+		s.current[sourceID] = byType
+	}
+
+	total, ok := byType[t]
+	if !ok {
+		total = &Usage{}
+		byType[t] = total
+	}
+	total.Envelopes += u.Envelopes
+	total.Bytes += u.Bytes
+	s.mu.Unlock()
+}
+
+func (a *Accountant) shard(sourceID string) *shard {
+	return &a.shards[keyhash.Sum32(sourceID)%shardCount]
+}
+
+// WithEnvelopes calls fn with the usage of every source ID since the last
+// call as v2 counters and starts a new interval. The usage fn fails to
+// send is kept for the next call. It implements metricemitter.EnvelopeSet.
+func (a *Accountant) WithEnvelopes(fn func(*v2.Envelope) error) error {
+	entries, interval := a.swap()
+	a.setLast(entries, interval)
+
+	now := time.Now().UnixNano()
+	for i, en := range entries {
+		t := en.envelopeType.String()
+		if en.usage.Envelopes > 0 {
+			err := fn(counter("usage.envelopes", en.sourceID, t, en.usage.Envelopes, now))
+			if err != nil {
+				a.restore(entries[i:])
+				return err
+			}
+		}
+
+		if en.usage.Bytes > 0 {
+			err := fn(counter("usage.bytes", en.sourceID, t, en.usage.Bytes, now))
+			if err != nil {
+				a.add(en.sourceID, en.envelopeType, Usage{Bytes: en.usage.Bytes})
+				a.restore(entries[i+1:])
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// swap replaces the usage of every shard and returns the previous usage
+// sorted by source ID and type, and the duration of the interval.
+func (a *Accountant) swap() ([]usageEntry, time.Duration) {
+	a.startMu.Lock()
+	interval := time.Since(a.start)
+	a.start = time.Now()
+	a.startMu.Unlock()
+
+	var entries []usageEntry
+	for i := range a.shards {
+		s := &a.shards[i]
+		s.mu.Lock()
+		current := s.current
+		s.current = make(map[string]map[events.Envelope_EventType]*Usage)
+		s.mu.Unlock()
+
+		for id, byType := range current {
+			if id != OverflowSourceID {
+				atomic.AddInt64(&a.sources, -1)
+			}
+
+			for t, u := range byType {
+				entries = append(entries, usageEntry{
+					sourceID:     id,
+					envelopeType: t,
+					usage:        *u,
+				})
+			}
+		}
+	}
+	sort.Sort(byKey(entries))
+
+	return entries, interval
+}
+
+func (a *Accountant) restore(entries []usageEntry) {
+	for _, en := range entries {
+		a.add(en.sourceID, en.envelopeType, en.usage)
+	}
+}
+
+func (a *Accountant) setLast(entries []usageEntry, interval time.Duration) {
+	usage := make([]SourceUsage, 0, len(entries))
+	for i, en := range entries {
+		if i == 0 || entries[i-1].sourceID != en.sourceID {
+			usage = append(usage, SourceUsage{
+				SourceID: en.sourceID,
+				ByType:   make(map[string]Usage),
+			})
+		}
+
+		su := &usage[len(usage)-1]
+		su.ByType[en.envelopeType.String()] = en.usage
+		su.Total.Envelopes += en.usage.Envelopes
+		su.Total.Bytes += en.usage.Bytes
+	}
+	sort.Sort(byBytes(usage))
+
+	a.lastMu.Lock()
+	a.last = usage
+	a.lastInterval = interval
+	a.lastMu.Unlock()
+}
+
+// Top returns the n source IDs with the most bytes in the last interval and
+// the duration of the interval.
+func (a *Accountant) Top(n int) ([]SourceUsage, time.Duration) {
+	a.lastMu.RLock()
+	defer a.lastMu.RUnlock()
+
+	if n > len(a.last) {
+		n = len(a.last)
+	}
+
+	return a.last[:n], a.lastInterval
+}
+
+// sourceID returns the source ID the envelope has once converted to v2.
+func sourceID(appID string, e *events.Envelope) string {
+	if appID != "" && appID != envelope_extensions.SystemAppId {
+		return appID
+	}
+
+	if id, ok := e.GetTags()["source_id"]; ok {
+		return id
+	}
+
+	return e.GetDeployment() + "/" + e.GetJob()
+}
+
+func counter(name, sourceID, envelopeType string, delta uint64, timestamp int64) *v2.Envelope {
+	// metric-documentation-v2: (loggregator.doppler.usage.envelopes) Number
+	// of envelopes received per source ID and envelope type.
+	// metric-documentation-v2: (loggregator.doppler.usage.bytes) Number of
+	// bytes received per source ID and envelope type.
+	return &v2.Envelope{
+		Timestamp: timestamp,
+		DeprecatedTags: map[string]*v2.Value{
+			"usage_source_id": text(sourceID),
+			"envelope_type":   text(envelopeType),
+			"metric_version":  text("2.0"),
+		},
+		Message: &v2.Envelope_Counter{
+			Counter: &v2.Counter{
+				Name: name,
+				Value: &v2.Counter_Delta{
+					Delta: delta,
+				},
+			},
+		},
+	}
+}
+
+func text(s string) *v2.Value {
+	return &v2.Value{
+		Data: &v2.Value_Text{
+			Text: s,
+		},
+	}
+}
+
+type byKey []usageEntry
+
+func (s byKey) Len() int      { return len(s) }
+func (s byKey) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byKey) Less(i, j int) bool {
+	if s[i].sourceID != s[j].sourceID {
+		return s[i].sourceID < s[j].sourceID
+	}
+	return s[i].envelopeType.String() < s[j].envelopeType.String()
+}
+
+type byBytes []SourceUsage
+
+func (s byBytes) Len() int      { return len(s) }
+func (s byBytes) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byBytes) Less(i, j int) bool {
+	if s[i].Total.Bytes != s[j].Total.Bytes {
+		return s[i].Total.Bytes > s[j].Total.Bytes
+	}
+	return s[i].SourceID < s[j].SourceID
+}
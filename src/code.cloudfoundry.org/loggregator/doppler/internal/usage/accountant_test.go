@@ -0,0 +1,190 @@
+package usage_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"code.cloudfoundry.org/loggregator/doppler/internal/usage"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+
+	"github.com/cloudfoundry/sonde-go/events"
+	"github.com/gogo/protobuf/proto"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Accountant", func() {
+	var accountant *usage.Accountant
+
+	BeforeEach(func() {
+		accountant = usage.NewAccountant(100)
+	})
+
+	It("counts envelopes and bytes per source ID and type", func() {
+		log := logMessage("app-1", "hello")
+		accountant.SendTo("app-1", log)
+		accountant.SendTo("app-1", log)
+		accountant.SendTo("app-1", counterEvent(nil))
+
+		envs := envelopes(accountant)
+
+		Expect(counters(envs, "usage.envelopes")).To(Equal(map[string]uint64{
+			"app-1/LogMessage":   2,
+			"app-1/CounterEvent": 1,
+		}))
+		Expect(counters(envs, "usage.bytes")).To(HaveKeyWithValue(
+			"app-1/LogMessage", uint64(2*log.Size()),
+		))
+	})
+
+	It("uses the v2 source ID of envelopes without an app ID", func() {
+		accountant.SendTo("system", counterEvent(nil))
+		accountant.SendTo("system", counterEvent(map[string]string{"source_id": "some-source"}))
+
+		Expect(counters(envelopes(accountant), "usage.envelopes")).To(Equal(map[string]uint64{
+			"some-deployment/some-job/CounterEvent": 1,
+			"some-source/CounterEvent":              1,
+		}))
+	})
+
+	It("starts a new interval after the envelopes are returned", func() {
+		accountant.SendTo("app-1", logMessage("app-1", "hello"))
+		Expect(envelopes(accountant)).ToNot(BeEmpty())
+
+		Expect(envelopes(accountant)).To(BeEmpty())
+	})
+
+	It("keeps the usage that fails to send for the next interval", func() {
+		accountant.SendTo("app-1", logMessage("app-1", "hello"))
+		accountant.SendTo("app-2", logMessage("app-2", "hello"))
+
+		var sent []*v2.Envelope
+		err := accountant.WithEnvelopes(func(e *v2.Envelope) error {
+			if len(sent) == 3 {
+				return errors.New("some-error")
+			}
+			sent = append(sent, e)
+			return nil
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(counters(sent, "usage.envelopes")).To(Equal(map[string]uint64{
+			"app-1/LogMessage": 1,
+			"app-2/LogMessage": 1,
+		}))
+
+		envs := envelopes(accountant)
+		Expect(counters(envs, "usage.envelopes")).To(BeEmpty())
+		Expect(counters(envs, "usage.bytes")).To(HaveKey("app-2/LogMessage"))
+	})
+
+	It("counts concurrently from many goroutines", func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				appID := fmt.Sprintf("app-%d", i%3)
+				for j := 0; j < 100; j++ {
+					accountant.SendTo(appID, logMessage(appID, "hello"))
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		Expect(counters(envelopes(accountant), "usage.envelopes")).To(Equal(map[string]uint64{
+			"app-0/LogMessage": 400,
+			"app-1/LogMessage": 300,
+			"app-2/LogMessage": 300,
+		}))
+	})
+
+	It("accounts for source IDs beyond the maximum as overflow", func() {
+		accountant = usage.NewAccountant(2)
+		accountant.SendTo("app-1", logMessage("app-1", "hello"))
+		accountant.SendTo("app-2", logMessage("app-2", "hello"))
+		accountant.SendTo("app-3", logMessage("app-3", "hello"))
+		accountant.SendTo("app-4", logMessage("app-4", "hello"))
+		accountant.SendTo("app-1", logMessage("app-1", "hello"))
+
+		Expect(counters(envelopes(accountant), "usage.envelopes")).To(Equal(map[string]uint64{
+			"app-1/LogMessage":    2,
+			"app-2/LogMessage":    1,
+			"overflow/LogMessage": 2,
+		}))
+	})
+
+	It("returns the top source IDs by bytes of the last interval", func() {
+		accountant.SendTo("app-1", logMessage("app-1", "a"))
+		accountant.SendTo("app-2", logMessage("app-2", "a much longer message"))
+		accountant.SendTo("app-3", logMessage("app-3", "medium message"))
+		accountant.SendTo("app-3", counterEvent(nil))
+
+		top, _ := accountant.Top(2)
+		Expect(top).To(BeEmpty())
+
+		envelopes(accountant)
+
+		top, _ = accountant.Top(2)
+		Expect(top).To(HaveLen(2))
+		Expect(top[0].SourceID).To(Equal("app-3"))
+		Expect(top[0].Total.Envelopes).To(Equal(uint64(2)))
+		Expect(top[0].ByType).To(HaveKey("CounterEvent"))
+		Expect(top[1].SourceID).To(Equal("app-2"))
+
+		top, _ = accountant.Top(10)
+		Expect(top).To(HaveLen(3))
+	})
+})
+
+func logMessage(appID, msg string) *events.Envelope {
+	return &events.Envelope{
+		Origin:    proto.String("some-origin"),
+		EventType: events.Envelope_LogMessage.Enum(),
+		LogMessage: &events.LogMessage{
+			Message:     []byte(msg),
+			MessageType: events.LogMessage_OUT.Enum(),
+			Timestamp:   proto.Int64(1),
+			AppId:       proto.String(appID),
+		},
+	}
+}
+
+func counterEvent(tags map[string]string) *events.Envelope {
+	return &events.Envelope{
+		Origin:     proto.String("some-origin"),
+		Deployment: proto.String("some-deployment"),
+		Job:        proto.String("some-job"),
+		EventType:  events.Envelope_CounterEvent.Enum(),
+		Tags:       tags,
+		CounterEvent: &events.CounterEvent{
+			Name:  proto.String("some-counter"),
+			Delta: proto.Uint64(1),
+		},
+	}
+}
+
+func envelopes(a *usage.Accountant) []*v2.Envelope {
+	var envs []*v2.Envelope
+	a.WithEnvelopes(func(e *v2.Envelope) error {
+		envs = append(envs, e)
+		return nil
+	})
+
+	return envs
+}
+
+func counters(envs []*v2.Envelope, name string) map[string]uint64 {
+	result := make(map[string]uint64)
+	for _, e := range envs {
+		if e.GetCounter().GetName() != name {
+			continue
+		}
+
+		key := e.DeprecatedTags["usage_source_id"].GetText() + "/" + e.DeprecatedTags["envelope_type"].GetText()
+		result[key] = e.GetCounter().GetDelta()
+	}
+
+	return result
+}
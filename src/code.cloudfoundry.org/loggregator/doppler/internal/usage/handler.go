@@ -0,0 +1,53 @@
+package usage
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+const defaultTopCount = 10
+
+type topResponse struct {
+	IntervalSeconds float64       `json:"interval_seconds"`
+	Sources         []SourceUsage `json:"sources"`
+}
+
+// TopHandler serves the source IDs with the most bytes in the last interval
+// as JSON. The number of source IDs is given by the n query parameter.
+type TopHandler struct {
+	accountant *Accountant
+}
+
+func NewTopHandler(a *Accountant) *TopHandler {
+	return &TopHandler{
+		accountant: a,
+	}
+}
+
+func (h *TopHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := defaultTopCount
+	if s := r.URL.Query().Get("n"); s != "" {
+		var err error
+		n, err = strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			http.Error(w, "n must be a positive number", http.StatusBadRequest)
+			return
+		}
+	}
+
+	sources, interval := h.accountant.Top(n)
+	if sources == nil {
+		sources = []SourceUsage{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(topResponse{
+		IntervalSeconds: interval.Seconds(),
+		Sources:         sources,
+	})
+	if err != nil {
+		log.Printf("failed to write top talkers: %s", err)
+	}
+}
@@ -0,0 +1,75 @@
+package usage_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"code.cloudfoundry.org/loggregator/doppler/internal/usage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TopHandler", func() {
+	var (
+		accountant *usage.Accountant
+		handler    *usage.TopHandler
+		recorder   *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		accountant = usage.NewAccountant(100)
+		handler = usage.NewTopHandler(accountant)
+		recorder = httptest.NewRecorder()
+
+		accountant.SendTo("app-1", logMessage("app-1", "a"))
+		accountant.SendTo("app-2", logMessage("app-2", "a much longer message"))
+		envelopes(accountant)
+	})
+
+	It("serves the top source IDs as JSON", func() {
+		req, _ := http.NewRequest("GET", "/usage/top?n=1", nil)
+		handler.ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.HeaderMap.Get("Content-Type")).To(Equal("application/json"))
+
+		var resp struct {
+			Sources []usage.SourceUsage `json:"sources"`
+		}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &resp)).To(Succeed())
+		Expect(resp.Sources).To(HaveLen(1))
+		Expect(resp.Sources[0].SourceID).To(Equal("app-2"))
+		Expect(resp.Sources[0].ByType["LogMessage"].Envelopes).To(Equal(uint64(1)))
+	})
+
+	It("defaults to ten source IDs", func() {
+		req, _ := http.NewRequest("GET", "/usage/top", nil)
+		handler.ServeHTTP(recorder, req)
+
+		var resp struct {
+			Sources []usage.SourceUsage `json:"sources"`
+		}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &resp)).To(Succeed())
+		Expect(resp.Sources).To(HaveLen(2))
+	})
+
+	It("rejects an invalid count", func() {
+		req, _ := http.NewRequest("GET", "/usage/top?n=many", nil)
+		handler.ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("rejects counts that are not positive", func() {
+		for _, n := range []string{"0", "-1"} {
+			recorder = httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/usage/top?n="+n, nil)
+			handler.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("n must be a positive number"))
+		}
+	})
+})
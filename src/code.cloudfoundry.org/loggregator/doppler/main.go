@@ -14,21 +14,24 @@ import (
 	"code.cloudfoundry.org/loggregator/healthendpoint"
 	"code.cloudfoundry.org/loggregator/metricemitter"
 	"code.cloudfoundry.org/loggregator/plumbing"
+	"code.cloudfoundry.org/loggregator/plumbing/limits"
 
 	"code.cloudfoundry.org/loggregator/dopplerservice"
 
-	"code.cloudfoundry.org/loggregator/diodes"
 	"code.cloudfoundry.org/loggregator/monitor"
 	"code.cloudfoundry.org/loggregator/profiler"
 
 	"code.cloudfoundry.org/loggregator/doppler/app"
 	grpcv1 "code.cloudfoundry.org/loggregator/doppler/internal/grpcmanager/v1"
 	"code.cloudfoundry.org/loggregator/doppler/internal/listeners"
+	"code.cloudfoundry.org/loggregator/doppler/internal/marshaler"
+	"code.cloudfoundry.org/loggregator/doppler/internal/sinks/dump"
 	"code.cloudfoundry.org/loggregator/doppler/internal/sinkserver"
 	"code.cloudfoundry.org/loggregator/doppler/internal/sinkserver/blacklist"
 	"code.cloudfoundry.org/loggregator/doppler/internal/sinkserver/sinkmanager"
 	"code.cloudfoundry.org/loggregator/doppler/internal/sinkserver/websocketserver"
 	"code.cloudfoundry.org/loggregator/doppler/internal/store"
+	"code.cloudfoundry.org/loggregator/doppler/internal/usage"
 
 	"code.cloudfoundry.org/workpool"
 	gendiodes "github.com/cloudfoundry/diodes"
@@ -76,14 +79,26 @@ func main() {
 		log.Fatal(err)
 	}
 
-	metricClient := setupMetricsEmitter(conf)
+	mirror := healthendpoint.NewMirror("loggregator", "doppler")
+	metricClient := setupMetricsEmitter(conf, mirror)
 	monitorInterval := time.Duration(conf.MonitorIntervalSeconds) * time.Second
 	openFileMonitor := monitor.NewLinuxFD(monitorInterval)
 	uptimeMonitor := monitor.NewUptime(monitorInterval)
-	batcher := initializeMetrics(conf.MetricBatchIntervalMilliseconds)
+	batcher := initializeMetrics(conf.MetricBatchIntervalMilliseconds, mirror)
+
+	accountant := usage.NewAccountant(conf.UsageMaxSourceIDs)
+	metricClient.PulseEnvelopeSet(accountant, time.Duration(conf.UsageIntervalSeconds)*time.Second)
+
+	recentLogsBudget := dump.NewBudget(conf.MaxRetainedLogBytes, conf.MaxRetainedLogBytesPerApp)
 
 	promRegistry := prometheus.NewRegistry()
-	healthendpoint.StartServer(conf.HealthAddr, promRegistry)
+	promRegistry.MustRegister(recentLogsBudget)
+	healthendpoint.StartServer(
+		conf.HealthAddr,
+		prometheus.Gatherers{promRegistry, mirror},
+		healthendpoint.WithHandler("/usage/top", usage.NewTopHandler(accountant)),
+		healthendpoint.WithHandler("/recentlogs/retention", dump.NewRetentionHandler(recentLogsBudget)),
+	)
 	healthRegistrar := healthendpoint.New(promRegistry, map[string]prometheus.Gauge{
 		// metric-documentation-health: (ingressStreamCount)
 		// Number of open firehose streams
@@ -130,10 +145,16 @@ func main() {
 	//------------------------------
 	// Caching
 	//------------------------------
+	blacklistManager, err := blacklist.New(conf.BlackListIps)
+	if err != nil {
+		log.Fatalf("Invalid blacklist IP ranges: %s", err)
+	}
+
 	sinkManager := sinkmanager.New(
 		conf.MaxRetainedLogMessages,
+		recentLogsBudget,
 		conf.SinkSkipCertVerify,
-		blacklist.New(conf.BlackListIps),
+		blacklistManager,
 		conf.MessageDrainBufferSize,
 		dopplerOrigin,
 		time.Duration(conf.SinkInactivityTimeoutSeconds)*time.Second,
@@ -145,6 +166,20 @@ func main() {
 		healthRegistrar,
 	)
 
+	for _, d := range conf.AggregateDrains {
+		err := sinkManager.RegisterAggregateDrain(sinkmanager.AggregateDrain{
+			Name:                d.Name,
+			URL:                 d.URL,
+			Hostname:            d.Hostname,
+			BufferSize:          d.BufferSize,
+			SourceTypes:         d.SourceTypes,
+			IncludePlatformLogs: d.IncludePlatformLogs,
+		})
+		if err != nil {
+			log.Panicf("Failed to register aggregate drain %s: %s", d.Name, err)
+		}
+	}
+
 	//------------------------------
 	// Ingress
 	//------------------------------
@@ -161,16 +196,28 @@ func main() {
 		metricemitter.WithTags(map[string]string{"direction": "ingress"}),
 	)
 
-	envelopeBuffer := diodes.NewManyToOneEnvelope(10000, gendiodes.AlertFunc(func(missed int) {
-		log.Printf("Shed %d envelopes", missed)
-		// metric-documentation-v1: (doppler.shedEnvelopes) Number of envelopes dropped by the
-		// diode inbound from metron
-		batcher.BatchCounter("doppler.shedEnvelopes").Add(uint64(missed))
-
-		// metric-documentation-v2: (loggregator.doppler.dropped) Number of envelopes dropped by the
-		// diode inbound from metron
-		droppedMetric.Increment(uint64(missed))
-	}))
+	// Envelopes are marshaled once and the bytes are shared by every
+	// subscription they are routed to.
+	envelopeMarshaler := marshaler.New(10000)
+	grpcRouter := grpcv1.NewRouter(envelopeMarshaler)
+	messageRouter := sinkserver.NewMessageRouter(
+		conf.RouterWorkerCount,
+		10000,
+		gendiodes.AlertFunc(func(missed int) {
+			log.Printf("Shed %d envelopes", missed)
+			// metric-documentation-v1: (doppler.shedEnvelopes) Number of envelopes dropped by the
+			// diode inbound from metron
+			batcher.BatchCounter("doppler.shedEnvelopes").Add(uint64(missed))
+
+			// metric-documentation-v2: (loggregator.doppler.dropped) Number of envelopes dropped by the
+			// diode inbound from metron
+			droppedMetric.Increment(uint64(missed))
+		}),
+		metricClient,
+		sinkManager,
+		grpcRouter,
+		accountant,
+	)
 
 	udpListener, dropsondeBytesChan := listeners.NewUDPListener(
 		fmt.Sprintf("%s:%d", conf.IP, conf.IncomingUDPPort),
@@ -178,15 +225,14 @@ func main() {
 		"udpListener",
 	)
 
-	grpcRouter := grpcv1.NewRouter()
-	messageRouter := sinkserver.NewMessageRouter(sinkManager, grpcRouter)
 	signatureVerifier := signature.NewVerifier(conf.SharedSecret)
 	grpcListener, err := listeners.NewGRPCListener(
 		grpcRouter,
 		sinkManager,
 		conf.GRPC,
-		envelopeBuffer,
+		messageRouter,
 		batcher,
+		limits.New(conf.IngressLimits, metricClient),
 		metricClient,
 		healthRegistrar,
 	)
@@ -210,6 +256,7 @@ func main() {
 		conf.MessageDrainBufferSize,
 		dopplerOrigin,
 		batcher,
+		envelopeMarshaler,
 	)
 	if err != nil {
 		log.Panicf("Failed to create the websocket server: %s", err)
@@ -223,7 +270,6 @@ func main() {
 		dropsondeUnmarshallerCollection,
 		openFileMonitor,
 		uptimeMonitor,
-		envelopeBuffer,
 		appStoreWatcher,
 		newAppServiceChan,
 		deletedAppServiceChan,
@@ -263,13 +309,12 @@ func start(
 	dropsondeUnmarshallerCollection *dropsonde_unmarshaller.DropsondeUnmarshallerCollection,
 	openFileMonitor *monitor.LinuxFileDescriptor,
 	uptimeMonitor *monitor.Uptime,
-	envelopeBuffer *diodes.ManyToOneEnvelope,
 	appStoreWatcher *store.AppServiceStoreWatcher,
 	newAppServiceChan <-chan store.AppService,
 	deletedAppServiceChan <-chan store.AppService,
 	dropsondeBytesChan <-chan []byte,
 	udpListener *listeners.UDPListener,
-	batcher *metricbatcher.MetricBatcher,
+	batcher *healthendpoint.MirroredBatcher,
 	sinkManager *sinkmanager.SinkManager,
 	websocketServer *websocketserver.WebsocketServer,
 	messageRouter *sinkserver.MessageRouter,
@@ -300,7 +345,7 @@ func start(
 				SetTag("protocol", "udp").
 				SetTag("event_type", env.GetEventType().String()).
 				Increment()
-			envelopeBuffer.Set(env)
+			messageRouter.Set(env)
 		}
 	}()
 
@@ -312,7 +357,7 @@ func start(
 	}()
 
 	go sinkManager.Start(newAppServiceChan, deletedAppServiceChan)
-	go messageRouter.Start(envelopeBuffer)
+	go messageRouter.Start()
 	go websocketServer.Start()
 	go uptimeMonitor.Start()
 	go openFileMonitor.Start()
@@ -323,7 +368,7 @@ func start(
 	}
 }
 
-func initializeMetrics(batchIntervalMilliseconds uint) *metricbatcher.MetricBatcher {
+func initializeMetrics(batchIntervalMilliseconds uint, mirror *healthendpoint.Mirror) *healthendpoint.MirroredBatcher {
 	eventEmitter := dropsonde.AutowiredEmitter()
 	metricSender := metric_sender.NewMetricSender(eventEmitter)
 	metricBatcher := metricbatcher.New(
@@ -335,8 +380,9 @@ func initializeMetrics(batchIntervalMilliseconds uint) *metricbatcher.MetricBatc
 		"TruncatingBuffer.totalDroppedMessages",
 		"listeners.totalReceivedMessageCount",
 	)
-	metrics.Initialize(metricSender, metricBatcher)
-	return metricBatcher
+	mirroredBatcher := mirror.Batcher(metricBatcher)
+	metrics.Initialize(metricSender, mirroredBatcher)
+	return mirroredBatcher
 }
 
 func connectToEtcd(conf *app.Config) storeadapter.StoreAdapter {
@@ -363,7 +409,7 @@ func connectToEtcd(conf *app.Config) storeadapter.StoreAdapter {
 	return etcdStoreAdapter
 }
 
-func setupMetricsEmitter(conf *app.Config) *metricemitter.Client {
+func setupMetricsEmitter(conf *app.Config, mirror *healthendpoint.Mirror) *metricemitter.Client {
 	credentials, err := plumbing.NewClientCredentials(
 		conf.GRPC.CertFile,
 		conf.GRPC.KeyFile,
@@ -382,6 +428,7 @@ func setupMetricsEmitter(conf *app.Config) *metricemitter.Client {
 		metricemitter.WithGRPCDialOptions(grpc.WithTransportCredentials(credentials)),
 		metricemitter.WithOrigin("loggregator.doppler"),
 		metricemitter.WithPulseInterval(batchInterval),
+		metricemitter.WithMirror(mirror),
 	)
 	if err != nil {
 		log.Fatalf("Could not configure metric emitter: %s", err)
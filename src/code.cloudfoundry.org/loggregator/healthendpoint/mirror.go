@@ -0,0 +1,435 @@
+package healthendpoint
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"code.cloudfoundry.org/loggregator/metricemitter"
+
+	"github.com/cloudfoundry/dropsonde/metricbatcher"
+	"github.com/golang/protobuf/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Mirror exposes the metrics of a metricemitter.Client and the counters of
+// a dropsonde batcher as Prometheus metrics. This makes them available on
+// the health endpoint even when the firehose is not. Metric names are
+// prefixed with the namespace and subsystem and have characters that are
+// not valid in Prometheus replaced by underscores. Counters have a _total
+// suffix. Counters and summaries with the same name and tags are combined.
+//
+// Mirrored metrics are created after the health endpoint has started and
+// the metrics of a name may have different tags, so the Mirror is a
+// prometheus.Gatherer rather than a collector of a registry. Every metric
+// of a name has the labels of all metrics of that name, with an empty value
+// for the tags it does not have. It is served next to a registry with
+// prometheus.Gatherers.
+type Mirror struct {
+	namespace string
+	subsystem string
+
+	mu      sync.RWMutex
+	metrics map[string]*mirrored
+	keys    []string
+}
+
+type mirrored struct {
+	name   string
+	help   string
+	labels map[string]string
+	valid  bool
+
+	// valueType is untyped for summaries.
+	valueType prometheus.ValueType
+
+	// values are summed for counters. The last value is used for gauges.
+	values    []func() float64
+	summaries []metricemitter.Summarizer
+
+	// batched is the count of dropsonde batcher counters.
+	batched uint64
+}
+
+// family is the metrics of a name with the union of their label names.
+type family struct {
+	name       string
+	help       string
+	valueType  prometheus.ValueType
+	labelNames []string
+	metrics    []*mirrored
+}
+
+// NewMirror returns a Mirror for the given Prometheus namespace and
+// subsystem.
+func NewMirror(namespace, subsystem string) *Mirror {
+	return &Mirror{
+		namespace: namespace,
+		subsystem: subsystem,
+		metrics:   make(map[string]*mirrored),
+	}
+}
+
+// MirrorCounter implements metricemitter.Mirror.
+func (m *Mirror) MirrorCounter(name string, tags map[string]string, c *metricemitter.Counter) {
+	mm := m.metric(name+"_total", name, tags, prometheus.CounterValue)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mm.values = append(mm.values, func() float64 {
+		return float64(c.GetTotal())
+	})
+}
+
+// MirrorGauge implements metricemitter.Mirror.
+func (m *Mirror) MirrorGauge(name, unit string, tags map[string]string, g *metricemitter.Gauge) {
+	mm := m.metric(name, name+" ("+unit+")", tags, prometheus.GaugeValue)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mm.values = append(mm.values, g.GetValue)
+}
+
+// MirrorSummary implements metricemitter.Mirror.
+func (m *Mirror) MirrorSummary(name, unit string, tags map[string]string, s metricemitter.Summarizer) {
+	mm := m.metric(name, name+" ("+unit+")", tags, prometheus.UntypedValue)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mm.summaries = append(mm.summaries, s)
+}
+
+// Gather implements prometheus.Gatherer. It includes the number of
+// mirrored metrics. Metrics whose type differs from the first metric of
+// their name or whose tags are invalid label names or values are skipped.
+func (m *Mirror) Gather() ([]*dto.MetricFamily, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	mfs := []*dto.MetricFamily{{
+		Name: proto.String(prometheus.BuildFQName(m.namespace, m.subsystem, "mirrored_metrics")),
+		Help: proto.String("Number of metrics mirrored from the metric emitter and batcher"),
+		Type: dto.MetricType_GAUGE.Enum(),
+		Metric: []*dto.Metric{
+			{Gauge: &dto.Gauge{Value: proto.Float64(float64(len(m.keys)))}},
+		},
+	}}
+
+	for _, f := range m.families() {
+		mf := &dto.MetricFamily{
+			Name: proto.String(f.name),
+			Help: proto.String(f.help),
+		}
+		for _, mm := range f.metrics {
+			mf.Metric = append(mf.Metric, mm.dtoMetric(f))
+		}
+
+		switch f.valueType {
+		case prometheus.CounterValue:
+			mf.Type = dto.MetricType_COUNTER.Enum()
+		case prometheus.GaugeValue:
+			mf.Type = dto.MetricType_GAUGE.Enum()
+		default:
+			mf.Type = dto.MetricType_SUMMARY.Enum()
+		}
+		mfs = append(mfs, mf)
+	}
+
+	return mfs, nil
+}
+
+// families groups the mirrored metrics by name. It has to be called with
+// the lock held.
+func (m *Mirror) families() []*family {
+	var families []*family
+	byName := make(map[string]*family)
+	for _, k := range m.keys {
+		mm := m.metrics[k]
+		if !mm.valid {
+			continue
+		}
+
+		f, ok := byName[mm.name]
+		if !ok {
+			f = &family{
+				name:      mm.name,
+				help:      mm.help,
+				valueType: mm.valueType,
+			}
+			byName[mm.name] = f
+			families = append(families, f)
+		}
+		if mm.valueType != f.valueType {
+			continue
+		}
+
+		f.metrics = append(f.metrics, mm)
+		for n := range mm.labels {
+			if !contains(f.labelNames, n) {
+				f.labelNames = append(f.labelNames, n)
+			}
+		}
+	}
+
+	for _, f := range families {
+		sort.Strings(f.labelNames)
+	}
+
+	return families
+}
+
+// dtoMetric returns the metric with a value for every label of its family.
+// It has to be called with the lock of the Mirror held.
+func (mm *mirrored) dtoMetric(f *family) *dto.Metric {
+	metric := &dto.Metric{}
+	for _, n := range f.labelNames {
+		metric.Label = append(metric.Label, &dto.LabelPair{
+			Name:  proto.String(n),
+			Value: proto.String(mm.labels[n]),
+		})
+	}
+
+	if mm.valueType == prometheus.UntypedValue {
+		var count uint64
+		var sum float64
+		var quantiles map[float64]float64
+		for _, s := range mm.summaries {
+			c, su, q := s.Summary()
+			count += c
+			sum += su
+			if q != nil {
+				quantiles = q
+			}
+		}
+
+		metric.Summary = &dto.Summary{
+			SampleCount: proto.Uint64(count),
+			SampleSum:   proto.Float64(sum),
+		}
+		qs := make([]float64, 0, len(quantiles))
+		for q := range quantiles {
+			qs = append(qs, q)
+		}
+		sort.Float64s(qs)
+		for _, q := range qs {
+			metric.Summary.Quantile = append(metric.Summary.Quantile, &dto.Quantile{
+				Quantile: proto.Float64(q),
+				Value:    proto.Float64(quantiles[q]),
+			})
+		}
+
+		return metric
+	}
+
+	value := float64(atomic.LoadUint64(&mm.batched))
+	for _, v := range mm.values {
+		if mm.valueType == prometheus.GaugeValue {
+			value = v()
+			continue
+		}
+		value += v()
+	}
+
+	if mm.valueType == prometheus.CounterValue {
+		metric.Counter = &dto.Counter{Value: proto.Float64(value)}
+	} else {
+		metric.Gauge = &dto.Gauge{Value: proto.Float64(value)}
+	}
+
+	return metric
+}
+
+// Batcher returns a batcher that mirrors the counters of the given batcher.
+func (m *Mirror) Batcher(b Batcher) *MirroredBatcher {
+	return &MirroredBatcher{
+		Batcher:  b,
+		mirror:   m,
+		counters: make(map[string]*mirrored),
+	}
+}
+
+// metric returns the mirrored metric with the given name and tags and
+// creates it if it does not exist yet.
+func (m *Mirror) metric(name, help string, tags map[string]string, t prometheus.ValueType) *mirrored {
+	fqName := prometheus.BuildFQName(m.namespace, m.subsystem, sanitize(name))
+
+	names := make([]string, 0, len(tags))
+	for k := range tags {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	labels := make(map[string]string, len(tags))
+	key := fqName
+	for _, k := range names {
+		labels[sanitize(k)] = tags[k]
+		key += "\xff" + sanitize(k) + "=" + tags[k]
+	}
+
+	m.mu.RLock()
+	mm, ok := m.metrics[key]
+	m.mu.RUnlock()
+	if ok {
+		return mm
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if mm, ok := m.metrics[key]; ok {
+		return mm
+	}
+
+	mm = &mirrored{
+		name:      fqName,
+		help:      help,
+		labels:    labels,
+		valid:     len(labels) == len(tags) && validLabels(labels),
+		valueType: t,
+	}
+	m.metrics[key] = mm
+	m.keys = append(m.keys, key)
+
+	return mm
+}
+
+// sanitize replaces every character that is not valid in a Prometheus
+// metric or label name with an underscore.
+func sanitize(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' ||
+			r >= 'A' && r <= 'Z' ||
+			r >= '0' && r <= '9' ||
+			r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// validLabels reports whether the sanitized labels are valid in
+// Prometheus.
+func validLabels(labels map[string]string) bool {
+	for n, v := range labels {
+		if n == "" || n[0] >= '0' && n[0] <= '9' || strings.HasPrefix(n, "__") {
+			return false
+		}
+		if !utf8.ValidString(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Batcher is implemented by dropsonde's metricbatcher.MetricBatcher.
+type Batcher interface {
+	BatchCounter(name string) metricbatcher.BatchCounterChainer
+	BatchIncrementCounter(name string)
+	BatchAddCounter(name string, delta uint64)
+	Close()
+}
+
+// MirroredBatcher passes counters to a batcher and mirrors them. The
+// mirrored counters are cached by their raw name, and their tags for
+// chained counters, as batched counters are incremented on hot paths.
+type MirroredBatcher struct {
+	Batcher
+	mirror *Mirror
+
+	mu       sync.RWMutex
+	counters map[string]*mirrored
+}
+
+func (b *MirroredBatcher) BatchCounter(name string) metricbatcher.BatchCounterChainer {
+	return &mirroredChainer{
+		chainer: b.Batcher.BatchCounter(name),
+		batcher: b,
+		name:    name,
+		tags:    make(map[string]string),
+	}
+}
+
+func (b *MirroredBatcher) BatchIncrementCounter(name string) {
+	b.Batcher.BatchIncrementCounter(name)
+	atomic.AddUint64(&b.counter(name, name, nil).batched, 1)
+}
+
+func (b *MirroredBatcher) BatchAddCounter(name string, delta uint64) {
+	b.Batcher.BatchAddCounter(name, delta)
+	atomic.AddUint64(&b.counter(name, name, nil).batched, delta)
+}
+
+// counter returns the mirrored counter cached for the key and mirrors the
+// counter with the given name and tags if there is none.
+func (b *MirroredBatcher) counter(key, name string, tags map[string]string) *mirrored {
+	b.mu.RLock()
+	mm, ok := b.counters[key]
+	b.mu.RUnlock()
+	if ok {
+		return mm
+	}
+
+	mm = b.mirror.metric(name+"_total", name, tags, prometheus.CounterValue)
+
+	b.mu.Lock()
+	b.counters[key] = mm
+	b.mu.Unlock()
+
+	return mm
+}
+
+type mirroredChainer struct {
+	chainer metricbatcher.BatchCounterChainer
+	batcher *MirroredBatcher
+	name    string
+	tags    map[string]string
+}
+
+func (c *mirroredChainer) SetTag(key, value string) metricbatcher.BatchCounterChainer {
+	c.chainer = c.chainer.SetTag(key, value)
+	c.tags[key] = value
+	return c
+}
+
+func (c *mirroredChainer) Increment() {
+	c.chainer.Increment()
+	atomic.AddUint64(&c.counter().batched, 1)
+}
+
+func (c *mirroredChainer) Add(delta uint64) {
+	c.chainer.Add(delta)
+	atomic.AddUint64(&c.counter().batched, delta)
+}
+
+func (c *mirroredChainer) counter() *mirrored {
+	if len(c.tags) == 0 {
+		return c.batcher.counter(c.name, c.name, nil)
+	}
+
+	names := make([]string, 0, len(c.tags))
+	for k := range c.tags {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	key := c.name
+	for _, k := range names {
+		key += "\xff" + k + "\xff" + c.tags[k]
+	}
+
+	return c.batcher.counter(key, c.name, c.tags)
+}
@@ -0,0 +1,196 @@
+package healthendpoint_test
+
+import (
+	"code.cloudfoundry.org/loggregator/healthendpoint"
+	"code.cloudfoundry.org/loggregator/metricemitter"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+
+	"github.com/cloudfoundry/dropsonde/metricbatcher"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Mirror", func() {
+	var (
+		mirror   *healthendpoint.Mirror
+		gatherer prometheus.Gatherers
+	)
+
+	BeforeEach(func() {
+		mirror = healthendpoint.NewMirror("loggregator", "test")
+		gatherer = prometheus.Gatherers{prometheus.NewRegistry(), mirror}
+	})
+
+	It("mirrors counters with their total", func() {
+		tags := map[string]string{"direction": "egress"}
+		c1 := metricemitter.NewCounter("dropped", "")
+		c2 := metricemitter.NewCounter("dropped", "")
+		mirror.MirrorCounter("dropped", tags, c1)
+		mirror.MirrorCounter("dropped", tags, c2)
+
+		c1.Increment(2)
+		c1.WithEnvelope(func(*v2.Envelope) error { return nil })
+		c2.Increment(3)
+
+		m := gather(gatherer, "loggregator_test_dropped_total")
+		Expect(m).To(HaveLen(1))
+		Expect(m[0].GetCounter().GetValue()).To(Equal(5.0))
+		Expect(m[0].GetLabel()).To(HaveLen(1))
+		Expect(m[0].GetLabel()[0].GetName()).To(Equal("direction"))
+		Expect(m[0].GetLabel()[0].GetValue()).To(Equal("egress"))
+	})
+
+	It("mirrors gauges with sanitized names", func() {
+		g := metricemitter.NewGauge("doppler_proxy.firehoses", "connections", "")
+		mirror.MirrorGauge("doppler_proxy.firehoses", "connections", nil, g)
+		g.Set(7)
+
+		m := gather(gatherer, "loggregator_test_doppler_proxy_firehoses")
+		Expect(m).To(HaveLen(1))
+		Expect(m[0].GetGauge().GetValue()).To(Equal(7.0))
+	})
+
+	It("mirrors histograms and timers as summaries", func() {
+		h := metricemitter.NewHistogram("latency", "ms", "", []float64{0.5})
+		mirror.MirrorSummary("latency", "ms", nil, h)
+		h.Observe(10)
+		h.Observe(20)
+
+		m := gather(gatherer, "loggregator_test_latency")
+		Expect(m).To(HaveLen(1))
+		Expect(m[0].GetSummary().GetSampleCount()).To(Equal(uint64(2)))
+		Expect(m[0].GetSummary().GetSampleSum()).To(Equal(30.0))
+	})
+
+	It("mirrors the counters of a batcher", func() {
+		spy := &spyBatcher{}
+		batcher := mirror.Batcher(spy)
+
+		batcher.BatchIncrementCounter("listeners.receivedEnvelopes")
+		batcher.BatchAddCounter("listeners.receivedEnvelopes", 2)
+		batcher.BatchCounter("listeners.receivedEnvelopes").
+			SetTag("protocol", "grpc").
+			Add(4)
+
+		Expect(spy.count).To(Equal(uint64(7)))
+
+		m := gather(gatherer, "loggregator_test_listeners_receivedEnvelopes_total")
+		Expect(m).To(HaveLen(2))
+		Expect(m[0].GetCounter().GetValue()).To(Equal(3.0))
+		Expect(m[1].GetCounter().GetValue()).To(Equal(4.0))
+		Expect(m[1].GetLabel()[0].GetValue()).To(Equal("grpc"))
+	})
+
+	It("mirrors chained counters with the same tags as one metric", func() {
+		batcher := mirror.Batcher(&spyBatcher{})
+
+		batcher.BatchCounter("dropped").SetTag("a", "1").SetTag("b", "2").Increment()
+		batcher.BatchCounter("dropped").SetTag("b", "2").SetTag("a", "1").Add(2)
+		batcher.BatchCounter("dropped").SetTag("a", "1").Increment()
+
+		m := gather(gatherer, "loggregator_test_dropped_total")
+		Expect(m).To(HaveLen(2))
+		Expect(labels(m[0])).To(Equal(map[string]string{"a": "1", "b": "2"}))
+		Expect(m[0].GetCounter().GetValue()).To(Equal(3.0))
+		Expect(labels(m[1])).To(Equal(map[string]string{"a": "1", "b": ""}))
+		Expect(m[1].GetCounter().GetValue()).To(Equal(1.0))
+	})
+
+	It("gives every metric of a name the same labels", func() {
+		c1 := metricemitter.NewCounter("ingress", "")
+		c2 := metricemitter.NewCounter("ingress", "")
+		mirror.MirrorCounter("ingress", map[string]string{"metric_version": "2.0"}, c1)
+		mirror.MirrorCounter("ingress", map[string]string{"metric_version": "2.0", "protocol": "http"}, c2)
+		c1.Increment(1)
+		c2.Increment(2)
+
+		m := gather(gatherer, "loggregator_test_ingress_total")
+		Expect(m).To(HaveLen(2))
+		Expect(labels(m[0])).To(Equal(map[string]string{"metric_version": "2.0", "protocol": ""}))
+		Expect(m[0].GetCounter().GetValue()).To(Equal(1.0))
+		Expect(labels(m[1])).To(Equal(map[string]string{"metric_version": "2.0", "protocol": "http"}))
+		Expect(m[1].GetCounter().GetValue()).To(Equal(2.0))
+	})
+
+	It("skips metrics of a name with a different type", func() {
+		mirror.MirrorCounter("ingress", nil, metricemitter.NewCounter("ingress", ""))
+		mirror.MirrorGauge("ingress_total", "envelopes", map[string]string{"a": "b"}, metricemitter.NewGauge("ingress_total", "envelopes", ""))
+
+		m := gather(gatherer, "loggregator_test_ingress_total")
+		Expect(m).To(HaveLen(1))
+		Expect(m[0].GetCounter()).ToNot(BeNil())
+		Expect(m[0].GetLabel()).To(BeEmpty())
+	})
+
+	It("skips metrics with tags that are invalid labels", func() {
+		mirror.MirrorCounter("dropped", map[string]string{"a.b": "1", "a_b": "2"}, metricemitter.NewCounter("dropped", ""))
+		mirror.MirrorCounter("dropped", map[string]string{"__name": "1"}, metricemitter.NewCounter("dropped", ""))
+		mirror.MirrorCounter("dropped", map[string]string{"a": "\xff"}, metricemitter.NewCounter("dropped", ""))
+
+		Expect(gather(gatherer, "loggregator_test_dropped_total")).To(BeEmpty())
+	})
+
+	It("reports the number of mirrored metrics", func() {
+		mirror.MirrorCounter("a", nil, metricemitter.NewCounter("a", ""))
+		mirror.MirrorCounter("b", nil, metricemitter.NewCounter("b", ""))
+
+		m := gather(gatherer, "loggregator_test_mirrored_metrics")
+		Expect(m).To(HaveLen(1))
+		Expect(m[0].GetGauge().GetValue()).To(Equal(2.0))
+	})
+})
+
+func gather(g prometheus.Gatherer, name string) []*dto.Metric {
+	families, err := g.Gather()
+	Expect(err).ToNot(HaveOccurred())
+
+	for _, f := range families {
+		if f.GetName() == name {
+			return f.GetMetric()
+		}
+	}
+
+	return nil
+}
+
+func labels(m *dto.Metric) map[string]string {
+	l := make(map[string]string)
+	for _, p := range m.GetLabel() {
+		l[p.GetName()] = p.GetValue()
+	}
+
+	return l
+}
+
+type spyBatcher struct {
+	count uint64
+}
+
+func (s *spyBatcher) BatchCounter(string) metricbatcher.BatchCounterChainer {
+	return s
+}
+
+func (s *spyBatcher) BatchIncrementCounter(string) {
+	s.count++
+}
+
+func (s *spyBatcher) BatchAddCounter(_ string, delta uint64) {
+	s.count += delta
+}
+
+func (s *spyBatcher) Close() {}
+
+func (s *spyBatcher) SetTag(string, string) metricbatcher.BatchCounterChainer {
+	return s
+}
+
+func (s *spyBatcher) Increment() {
+	s.count++
+}
+
+func (s *spyBatcher) Add(delta uint64) {
+	s.count += delta
+}
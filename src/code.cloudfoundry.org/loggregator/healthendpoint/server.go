@@ -10,9 +10,25 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-func StartServer(addr string, gatherer prometheus.Gatherer) net.Listener {
+// ServerOption configures the health endpoint server.
+type ServerOption func(*http.ServeMux)
+
+// WithHandler serves the handler for the given pattern next to the health
+// endpoint.
+func WithHandler(pattern string, h http.Handler) ServerOption {
+	return func(router *http.ServeMux) {
+		router.Handle(pattern, h)
+	}
+}
+
+func StartServer(addr string, gatherer prometheus.Gatherer, opts ...ServerOption) net.Listener {
 	router := http.NewServeMux()
-	router.Handle("/health", promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+	handler := promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})
+	router.Handle("/health", handler)
+	router.Handle("/metrics", handler)
+	for _, o := range opts {
+		o(router)
+	}
 
 	server := http.Server{
 		Addr:         addr,
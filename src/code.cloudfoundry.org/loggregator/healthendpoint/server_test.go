@@ -0,0 +1,40 @@
+package healthendpoint_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"code.cloudfoundry.org/loggregator/healthendpoint"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Server", func() {
+	It("serves the health and metrics endpoints and additional handlers", func() {
+		lis := healthendpoint.StartServer(
+			"127.0.0.1:0",
+			prometheus.NewRegistry(),
+			healthendpoint.WithHandler("/other", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("other"))
+			})),
+		)
+
+		resp, err := http.Get(fmt.Sprintf("http://%s/health", lis.Addr()))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		resp, err = http.Get(fmt.Sprintf("http://%s/metrics", lis.Addr()))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		resp, err = http.Get(fmt.Sprintf("http://%s/other", lis.Addr()))
+		Expect(err).ToNot(HaveOccurred())
+		body, err := ioutil.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(body)).To(Equal("other"))
+	})
+})
@@ -14,6 +14,8 @@ type Client struct {
 	dialOpts      []grpc.DialOption
 	sourceID      string
 	tags          map[string]string
+	quantiles     []float64
+	mirror        Mirror
 }
 
 type sendable interface {
@@ -40,6 +42,14 @@ func WithSourceID(s string) ClientOption {
 	}
 }
 
+// WithQuantiles sets the quantiles emitted by histograms and timers. It
+// defaults to DefaultQuantiles.
+func WithQuantiles(q ...float64) ClientOption {
+	return func(c *Client) {
+		c.quantiles = q
+	}
+}
+
 func WithOrigin(name string) ClientOption {
 	return func(c *Client) {
 		c.tags["origin"] = name
@@ -58,6 +68,7 @@ func NewClient(addr string, opts ...ClientOption) (*Client, error) {
 	client := &Client{
 		tags:          make(map[string]string),
 		pulseInterval: 5 * time.Second,
+		quantiles:     DefaultQuantiles,
 	}
 
 	for _, opt := range opts {
@@ -77,7 +88,10 @@ func NewClient(addr string, opts ...ClientOption) (*Client, error) {
 func (c *Client) NewCounter(name string, opts ...MetricOption) *Counter {
 	opts = append(opts, WithTags(c.tags))
 	m := NewCounter(name, c.sourceID, opts...)
-	go c.pulse(m)
+	if c.mirror != nil {
+		c.mirror.MirrorCounter(name, c.mirrorTags(m.Tagged), m)
+	}
+	go c.pulse(m, c.pulseInterval)
 
 	return m
 }
@@ -85,14 +99,54 @@ func (c *Client) NewCounter(name string, opts ...MetricOption) *Counter {
 func (c *Client) NewGauge(name, unit string, opts ...MetricOption) *Gauge {
 	opts = append(opts, WithTags(c.tags))
 	m := NewGauge(name, unit, c.sourceID, opts...)
-	go c.pulse(m)
+	if c.mirror != nil {
+		c.mirror.MirrorGauge(name, unit, c.mirrorTags(m.Tagged), m)
+	}
+	go c.pulse(m, c.pulseInterval)
+
+	return m
+}
+
+func (c *Client) NewHistogram(name, unit string, opts ...MetricOption) *Histogram {
+	opts = append(opts, WithTags(c.tags))
+	m := NewHistogram(name, unit, c.sourceID, c.quantiles, opts...)
+	if c.mirror != nil {
+		c.mirror.MirrorSummary(name, unit, c.mirrorTags(m.Tagged), m)
+	}
+	go c.pulse(m, c.pulseInterval)
+
+	return m
+}
+
+// NewTimer returns a Timer that emits a summary of its durations in
+// milliseconds.
+func (c *Client) NewTimer(name string, opts ...MetricOption) *Timer {
+	opts = append(opts, WithTags(c.tags))
+	m := NewTimer(name, c.sourceID, c.quantiles, opts...)
+	if c.mirror != nil {
+		c.mirror.MirrorSummary(name, "ms", c.mirrorTags(m.Tagged), m)
+	}
+	go c.pulse(m, c.pulseInterval)
+
+	return m
+}
+
+// NewRawTimer returns a Timer that emits a v2 Timer envelope for each of its
+// durations.
+func (c *Client) NewRawTimer(name string, opts ...MetricOption) *Timer {
+	opts = append(opts, WithTags(c.tags))
+	m := NewRawTimer(name, c.sourceID, opts...)
+	if c.mirror != nil {
+		c.mirror.MirrorSummary(name, "ms", c.mirrorTags(m.Tagged), m)
+	}
+	go c.pulse(m, c.pulseInterval)
 
 	return m
 }
 
-func (c *Client) pulse(s sendable) {
+func (c *Client) pulse(s sendable, interval time.Duration) {
 	var senderClient v2.Ingress_SenderClient
-	for range time.Tick(c.pulseInterval) {
+	for range time.Tick(interval) {
 		if senderClient == nil {
 			var err error
 			senderClient, err = c.ingressClient.Sender(context.Background())
@@ -170,8 +170,138 @@ var _ = Describe("Emitter Client", func() {
 			})
 		})
 	})
+
+	Context("with a timer", func() {
+		It("emits the configured quantiles", func() {
+			grpcServer := newgRPCServer()
+			defer grpcServer.stop()
+
+			client, err := metricemitter.NewClient(
+				grpcServer.addr,
+				metricemitter.WithGRPCDialOptions(grpc.WithInsecure()),
+				metricemitter.WithPulseInterval(50*time.Millisecond),
+				metricemitter.WithQuantiles(0.9),
+			)
+			Expect(err).ToNot(HaveOccurred())
+
+			timer := client.NewTimer("some-timer")
+			timer.Observe(time.Unix(0, 0), time.Unix(0, int64(time.Second)))
+
+			var env *v2.Envelope
+			Eventually(grpcServer.envelopes).Should(Receive(&env))
+			metrics := env.GetGauge().GetMetrics()
+			Expect(metrics).To(HaveLen(3))
+			Expect(metrics["some-timer.p90"].GetValue()).To(Equal(1000.0))
+		})
+	})
+
+	Context("with a mirror", func() {
+		It("gives every metric to the mirror without the client's tags", func() {
+			grpcServer := newgRPCServer()
+			defer grpcServer.stop()
+
+			mirror := &spyMirror{}
+			client, err := metricemitter.NewClient(
+				grpcServer.addr,
+				metricemitter.WithGRPCDialOptions(grpc.WithInsecure()),
+				metricemitter.WithOrigin("a-origin"),
+				metricemitter.WithMirror(mirror),
+			)
+			Expect(err).ToNot(HaveOccurred())
+
+			counter := client.NewCounter("some-counter",
+				metricemitter.WithTags(map[string]string{"a": "1"}),
+			)
+			client.NewGauge("some-gauge", "some-unit")
+			client.NewHistogram("some-histogram", "some-unit")
+			client.NewTimer("some-timer")
+
+			Expect(mirror.counters).To(Equal([]*metricemitter.Counter{counter}))
+			Expect(mirror.names).To(Equal([]string{
+				"some-counter",
+				"some-gauge",
+				"some-histogram",
+				"some-timer",
+			}))
+			Expect(mirror.tags[0]).To(Equal(map[string]string{"a": "1"}))
+			Expect(mirror.tags[1]).To(BeEmpty())
+		})
+	})
+
+	Context("with an envelope set", func() {
+		It("emits the envelopes of the set with the client's tags", func() {
+			grpcServer := newgRPCServer()
+			defer grpcServer.stop()
+
+			client, err := metricemitter.NewClient(
+				grpcServer.addr,
+				metricemitter.WithGRPCDialOptions(grpc.WithInsecure()),
+				metricemitter.WithPulseInterval(50*time.Millisecond),
+				metricemitter.WithSourceID("a-source"),
+				metricemitter.WithOrigin("a-origin"),
+			)
+			Expect(err).ToNot(HaveOccurred())
+
+			client.PulseEnvelopeSet(spyEnvelopeSet{
+				{
+					Message: &v2.Envelope_Counter{
+						Counter: &v2.Counter{Name: "first"},
+					},
+				},
+				{
+					Message: &v2.Envelope_Counter{
+						Counter: &v2.Counter{Name: "second"},
+					},
+				},
+			}, 50*time.Millisecond)
+
+			var env *v2.Envelope
+			Eventually(grpcServer.envelopes).Should(Receive(&env))
+			Expect(env.GetCounter().GetName()).To(Equal("first"))
+			Expect(env.GetSourceId()).To(Equal("a-source"))
+			Expect(env.GetDeprecatedTags()["origin"].GetText()).To(Equal("a-origin"))
+
+			Eventually(grpcServer.envelopes).Should(Receive(&env))
+			Expect(env.GetCounter().GetName()).To(Equal("second"))
+		})
+	})
 })
 
+type spyMirror struct {
+	names    []string
+	tags     []map[string]string
+	counters []*metricemitter.Counter
+}
+
+func (s *spyMirror) MirrorCounter(name string, tags map[string]string, c *metricemitter.Counter) {
+	s.names = append(s.names, name)
+	s.tags = append(s.tags, tags)
+	s.counters = append(s.counters, c)
+}
+
+func (s *spyMirror) MirrorGauge(name, unit string, tags map[string]string, g *metricemitter.Gauge) {
+	s.names = append(s.names, name)
+	s.tags = append(s.tags, tags)
+}
+
+func (s *spyMirror) MirrorSummary(name, unit string, tags map[string]string, _ metricemitter.Summarizer) {
+	s.names = append(s.names, name)
+	s.tags = append(s.tags, tags)
+}
+
+type spyEnvelopeSet []*v2.Envelope
+
+func (s spyEnvelopeSet) WithEnvelopes(fn func(*v2.Envelope) error) error {
+	for _, e := range s {
+		c := *e
+		if err := fn(&c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func newgRPCServer() *SpyIngressServer {
 	return newgRPCServerWithAddr("127.0.0.1:0")
 }
@@ -13,6 +13,7 @@ type Counter struct {
 	name     string
 	sourceID string
 	delta    uint64
+	total    uint64
 }
 
 type Tagged struct {
@@ -37,12 +38,19 @@ func NewCounter(name, sourceID string, opts ...MetricOption) *Counter {
 
 func (m *Counter) Increment(c uint64) {
 	atomic.AddUint64(&m.delta, c)
+	atomic.AddUint64(&m.total, c)
 }
 
 func (m *Counter) GetDelta() uint64 {
 	return atomic.LoadUint64(&m.delta)
 }
 
+// GetTotal returns the sum of all increments. Unlike the delta it is not
+// reset when the counter is emitted.
+func (m *Counter) GetTotal() uint64 {
+	return atomic.LoadUint64(&m.total)
+}
+
 func (m *Counter) WithEnvelope(fn func(*v2.Envelope) error) error {
 	d := atomic.SwapUint64(&m.delta, 0)
 
@@ -70,6 +78,16 @@ func (m *Counter) toEnvelope(delta uint64) *v2.Envelope {
 	}
 }
 
+// textTags returns the tags of the metric as text.
+func (t Tagged) textTags() map[string]string {
+	tags := make(map[string]string, len(t.tags))
+	for k, v := range t.tags {
+		tags[k] = v.GetText()
+	}
+
+	return tags
+}
+
 func WithVersion(major, minor uint) MetricOption {
 	return WithTags(map[string]string{
 		"metric_version": fmt.Sprintf("%d.%d", major, minor),
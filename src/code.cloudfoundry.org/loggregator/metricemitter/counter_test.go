@@ -38,5 +38,18 @@ var _ = Describe("Counter", func() {
 
 			Expect(metric.GetDelta()).To(Equal(uint64(10)))
 		})
+
+		It("does not reset the total", func() {
+			metric := metricemitter.NewCounter("name", "source-id")
+
+			metric.Increment(10)
+			err := metric.WithEnvelope(func(_ *v2.Envelope) error {
+				return nil
+			})
+			Expect(err).ToNot(HaveOccurred())
+			metric.Increment(5)
+
+			Expect(metric.GetTotal()).To(Equal(uint64(15)))
+		})
 	})
 })
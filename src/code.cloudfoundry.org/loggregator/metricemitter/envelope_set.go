@@ -0,0 +1,53 @@
+package metricemitter
+
+import (
+	"time"
+
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+)
+
+// EnvelopeSet provides the envelopes of metrics that are not known ahead of
+// time, such as metrics tagged by app.
+type EnvelopeSet interface {
+	// WithEnvelopes calls fn with every envelope of the set and stops at
+	// the first error. The values of envelopes that fail to send are kept
+	// for the next call.
+	WithEnvelopes(fn func(*v2.Envelope) error) error
+}
+
+// PulseEnvelopeSet emits the envelopes of the set every interval. The
+// source ID and tags of the client are added to each envelope.
+func (c *Client) PulseEnvelopeSet(s EnvelopeSet, interval time.Duration) {
+	go c.pulse(&envelopeSet{
+		set:      s,
+		sourceID: c.sourceID,
+		tags:     c.tags,
+	}, interval)
+}
+
+type envelopeSet struct {
+	set      EnvelopeSet
+	sourceID string
+	tags     map[string]string
+}
+
+func (s *envelopeSet) WithEnvelope(fn func(*v2.Envelope) error) error {
+	return s.set.WithEnvelopes(func(e *v2.Envelope) error {
+		if e.SourceId == "" {
+			e.SourceId = s.sourceID
+		}
+
+		if e.DeprecatedTags == nil {
+			e.DeprecatedTags = make(map[string]*v2.Value)
+		}
+		for k, v := range s.tags {
+			e.DeprecatedTags[k] = &v2.Value{
+				Data: &v2.Value_Text{
+					Text: v,
+				},
+			}
+		}
+
+		return fn(e)
+	})
+}
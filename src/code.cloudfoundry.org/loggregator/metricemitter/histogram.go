@@ -0,0 +1,351 @@
+package metricemitter
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+)
+
+// maxSamples is the number of observations a Histogram keeps between pulses
+// to compute quantiles. Observations beyond it are sampled.
+const maxSamples = 1028
+
+// DefaultQuantiles are the quantiles emitted by histograms and timers when
+// none are configured.
+var DefaultQuantiles = []float64{0.5, 0.95, 0.99}
+
+// Histogram accumulates observations between pulses and emits them as a
+// single gauge with the count, sum and quantiles of the observations.
+type Histogram struct {
+	Tagged
+	name      string
+	unit      string
+	sourceID  string
+	quantiles []float64
+
+	mu      sync.Mutex
+	count   uint64
+	sum     float64
+	samples []float64
+	rand    *rand.Rand
+
+	totalCount    uint64
+	totalSum      float64
+	lastQuantiles map[float64]float64
+}
+
+func NewHistogram(name, unit, sourceID string, quantiles []float64, opts ...MetricOption) *Histogram {
+	m := &Histogram{
+		name:      name,
+		unit:      unit,
+		sourceID:  sourceID,
+		quantiles: quantiles,
+		samples:   make([]float64, 0, maxSamples),
+		rand:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	m.Tagged.tags = make(map[string]*v2.Value)
+
+	for _, opt := range opts {
+		opt(m.Tagged)
+	}
+
+	return m
+}
+
+// Observe records a single observation.
+func (m *Histogram) Observe(value float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.count++
+	m.sum += value
+	m.totalCount++
+	m.totalSum += value
+
+	m.sample(value)
+}
+
+// sample adds the value to the samples. It needs to be called with the lock
+// held after the count was increased.
+func (m *Histogram) sample(value float64) {
+	if len(m.samples) < maxSamples {
+		m.samples = append(m.samples, value)
+		return
+	}
+
+	// Reservoir sampling keeps every observation equally likely to be
+	// part of the samples.
+	if i := m.rand.Int63n(int64(m.count)); i < maxSamples {
+		m.samples[i] = value
+	}
+}
+
+// GetCount returns the number of observations since the last pulse.
+func (m *Histogram) GetCount() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.count
+}
+
+// GetSum returns the sum of the observations since the last pulse.
+func (m *Histogram) GetSum() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.sum
+}
+
+// Summary returns the count and sum of all observations and the quantiles
+// of the observations emitted last.
+func (m *Histogram) Summary() (uint64, float64, map[float64]float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.totalCount, m.totalSum, m.lastQuantiles
+}
+
+// WithEnvelope emits the observations since the last call and resets the
+// histogram. Quantiles are only emitted when there are observations. If fn
+// fails, the observations are kept and emitted with the next call.
+func (m *Histogram) WithEnvelope(fn func(*v2.Envelope) error) error {
+	m.mu.Lock()
+	count, sum, samples := m.count, m.sum, m.samples
+	m.count, m.sum = 0, 0
+	m.samples = make([]float64, 0, maxSamples)
+	m.mu.Unlock()
+
+	quantiles := m.computeQuantiles(samples)
+	if err := fn(m.toEnvelope(count, sum, quantiles)); err != nil {
+		m.mu.Lock()
+		m.count += count
+		m.sum += sum
+		for _, v := range samples {
+			m.sample(v)
+		}
+		m.mu.Unlock()
+
+		return err
+	}
+
+	if quantiles != nil {
+		m.mu.Lock()
+		m.lastQuantiles = quantiles
+		m.mu.Unlock()
+	}
+
+	return nil
+}
+
+func (m *Histogram) computeQuantiles(samples []float64) map[float64]float64 {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	sort.Float64s(samples)
+	quantiles := make(map[float64]float64, len(m.quantiles))
+	for _, q := range m.quantiles {
+		quantiles[q] = quantile(samples, q)
+	}
+
+	return quantiles
+}
+
+func (m *Histogram) toEnvelope(count uint64, sum float64, quantiles map[float64]float64) *v2.Envelope {
+	metrics := map[string]*v2.GaugeValue{
+		m.name + ".count": {
+			Unit:  "count",
+			Value: float64(count),
+		},
+		m.name + ".sum": {
+			Unit:  m.unit,
+			Value: sum,
+		},
+	}
+
+	for q, v := range quantiles {
+		metrics[m.name+"."+quantileName(q)] = &v2.GaugeValue{
+			Unit:  m.unit,
+			Value: v,
+		}
+	}
+
+	return &v2.Envelope{
+		SourceId:  m.sourceID,
+		Timestamp: time.Now().UnixNano(),
+		Message: &v2.Envelope_Gauge{
+			Gauge: &v2.Gauge{
+				Metrics: metrics,
+			},
+		},
+		DeprecatedTags: m.tags,
+	}
+}
+
+// Timer measures durations. By default durations are accumulated in a
+// Histogram in milliseconds. A raw Timer instead emits a v2 Timer envelope
+// for each observation.
+type Timer struct {
+	Tagged
+	name      string
+	sourceID  string
+	histogram *Histogram
+
+	mu         sync.Mutex
+	timers     []*v2.Timer
+	totalCount uint64
+	totalSum   float64
+}
+
+func NewTimer(name, sourceID string, quantiles []float64, opts ...MetricOption) *Timer {
+	h := NewHistogram(name, "ms", sourceID, quantiles, opts...)
+
+	return &Timer{
+		Tagged:    h.Tagged,
+		name:      name,
+		sourceID:  sourceID,
+		histogram: h,
+	}
+}
+
+// NewRawTimer returns a Timer that emits a v2 Timer envelope per
+// observation. At most maxSamples observations are kept between pulses;
+// further observations are dropped.
+func NewRawTimer(name, sourceID string, opts ...MetricOption) *Timer {
+	m := &Timer{
+		name:     name,
+		sourceID: sourceID,
+	}
+	m.Tagged.tags = make(map[string]*v2.Value)
+
+	for _, opt := range opts {
+		opt(m.Tagged)
+	}
+
+	return m
+}
+
+// Observe records the duration between start and stop.
+func (m *Timer) Observe(start, stop time.Time) {
+	if m.histogram != nil {
+		m.histogram.Observe(float64(stop.Sub(start)) / float64(time.Millisecond))
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.totalCount++
+	m.totalSum += float64(stop.Sub(start)) / float64(time.Millisecond)
+
+	if len(m.timers) >= maxSamples {
+		return
+	}
+
+	m.timers = append(m.timers, &v2.Timer{
+		Name:  m.name,
+		Start: start.UnixNano(),
+		Stop:  stop.UnixNano(),
+	})
+}
+
+// ObserveSince records the duration from start until now. It is intended to
+// be deferred with a start of time.Now().
+func (m *Timer) ObserveSince(start time.Time) {
+	m.Observe(start, time.Now())
+}
+
+// GetCount returns the number of observations since the last pulse.
+func (m *Timer) GetCount() uint64 {
+	if m.histogram != nil {
+		return m.histogram.GetCount()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return uint64(len(m.timers))
+}
+
+// Summary returns the count and sum in milliseconds of all observations and
+// the quantiles of the observations emitted last. Raw timers have no
+// quantiles.
+func (m *Timer) Summary() (uint64, float64, map[float64]float64) {
+	if m.histogram != nil {
+		return m.histogram.Summary()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.totalCount, m.totalSum, nil
+}
+
+// WithEnvelope emits the observations since the last call. If fn fails,
+// the observations that were not sent are kept and emitted with the next
+// call.
+func (m *Timer) WithEnvelope(fn func(*v2.Envelope) error) error {
+	if m.histogram != nil {
+		return m.histogram.WithEnvelope(fn)
+	}
+
+	m.mu.Lock()
+	timers := m.timers
+	m.timers = nil
+	m.mu.Unlock()
+
+	for i, t := range timers {
+		err := fn(&v2.Envelope{
+			SourceId:  m.sourceID,
+			Timestamp: t.Stop,
+			Message: &v2.Envelope_Timer{
+				Timer: t,
+			},
+			DeprecatedTags: m.tags,
+		})
+		if err != nil {
+			m.restore(timers[i:])
+			return err
+		}
+	}
+
+	return nil
+}
+
+// restore puts the timers that were not sent back in front of the timers
+// observed since. Timers beyond maxSamples are dropped.
+func (m *Timer) restore(unsent []*v2.Timer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	timers := append(unsent, m.timers...)
+	if len(timers) > maxSamples {
+		timers = timers[:maxSamples]
+	}
+	m.timers = timers
+}
+
+// quantile returns the nearest rank quantile of the sorted samples.
+func quantile(sorted []float64, q float64) float64 {
+	rank := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+
+	return sorted[rank]
+}
+
+// quantileName returns the metric suffix of a quantile, e.g. p99 for 0.99.
+func quantileName(q float64) string {
+	// Rounding avoids names such as p28.999999999999996 for 0.29.
+	percent := math.Floor(q*1e6+0.5) / 1e4
+	return "p" + strconv.FormatFloat(percent, 'f', -1, 64)
+}
@@ -0,0 +1,213 @@
+package metricemitter_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/metricemitter"
+
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Histogram", func() {
+	Context("WithEnvelope", func() {
+		It("creates a gauge envelope with the count, sum and quantiles", func() {
+			metric := metricemitter.NewHistogram("name", "unit", "source-id",
+				[]float64{0.5, 0.99, 0.999},
+				metricemitter.WithTags(map[string]string{
+					"a": "1",
+				}))
+			for i := 1; i <= 100; i++ {
+				metric.Observe(float64(i))
+			}
+
+			env := histogramEnvelope(metric)
+			Expect(env.GetSourceId()).To(Equal("source-id"))
+			Expect(env.GetTimestamp()).ToNot(BeZero())
+			Expect(env.GetDeprecatedTags()["a"].GetText()).To(Equal("1"))
+
+			metrics := env.GetGauge().GetMetrics()
+			Expect(metrics).To(HaveLen(5))
+			Expect(metrics["name.count"].GetValue()).To(Equal(100.0))
+			Expect(metrics["name.count"].GetUnit()).To(Equal("count"))
+			Expect(metrics["name.sum"].GetValue()).To(Equal(5050.0))
+			Expect(metrics["name.sum"].GetUnit()).To(Equal("unit"))
+			Expect(metrics["name.p50"].GetValue()).To(Equal(50.0))
+			Expect(metrics["name.p99"].GetValue()).To(Equal(99.0))
+			Expect(metrics["name.p99.9"].GetValue()).To(Equal(100.0))
+		})
+
+		It("resets the observations", func() {
+			metric := metricemitter.NewHistogram("name", "unit", "source-id",
+				[]float64{0.5})
+			metric.Observe(10)
+			histogramEnvelope(metric)
+
+			Expect(metric.GetCount()).To(BeZero())
+			metrics := histogramEnvelope(metric).GetGauge().GetMetrics()
+			Expect(metrics).To(HaveLen(2))
+			Expect(metrics["name.count"].GetValue()).To(BeZero())
+			Expect(metrics["name.sum"].GetValue()).To(BeZero())
+		})
+
+		It("keeps the observations when the first send fails", func() {
+			metric := metricemitter.NewHistogram("name", "unit", "source-id",
+				[]float64{0.5})
+			metric.Observe(10)
+
+			err := metric.WithEnvelope(func(_ *v2.Envelope) error {
+				return errors.New("some error")
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(metric.GetCount()).To(Equal(uint64(1)))
+
+			metric.Observe(20)
+			metrics := histogramEnvelope(metric).GetGauge().GetMetrics()
+			Expect(metrics["name.count"].GetValue()).To(Equal(2.0))
+			Expect(metrics["name.sum"].GetValue()).To(Equal(30.0))
+			Expect(metrics["name.p50"].GetValue()).To(Equal(10.0))
+		})
+
+		It("summarizes all observations with the last quantiles", func() {
+			metric := metricemitter.NewHistogram("name", "unit", "source-id",
+				[]float64{0.5})
+			metric.Observe(10)
+			histogramEnvelope(metric)
+			metric.Observe(20)
+
+			count, sum, quantiles := metric.Summary()
+			Expect(count).To(Equal(uint64(2)))
+			Expect(sum).To(Equal(30.0))
+			Expect(quantiles).To(Equal(map[float64]float64{0.5: 10}))
+		})
+
+		It("bounds the samples kept for quantiles", func() {
+			metric := metricemitter.NewHistogram("name", "unit", "source-id",
+				[]float64{1})
+			for i := 0; i < 100000; i++ {
+				metric.Observe(1)
+			}
+
+			Expect(metric.GetCount()).To(Equal(uint64(100000)))
+			Expect(metric.GetSum()).To(Equal(100000.0))
+			metrics := histogramEnvelope(metric).GetGauge().GetMetrics()
+			Expect(metrics["name.p100"].GetValue()).To(Equal(1.0))
+		})
+	})
+})
+
+var _ = Describe("Timer", func() {
+	var (
+		start = time.Unix(0, 0)
+		stop  = time.Unix(0, int64(250*time.Millisecond))
+	)
+
+	It("emits a summary of the durations in milliseconds", func() {
+		metric := metricemitter.NewTimer("name", "source-id", []float64{0.5})
+		metric.Observe(start, stop)
+
+		Expect(metric.GetCount()).To(Equal(uint64(1)))
+		env := histogramEnvelope(metric)
+		Expect(env.GetSourceId()).To(Equal("source-id"))
+		metrics := env.GetGauge().GetMetrics()
+		Expect(metrics["name.sum"].GetUnit()).To(Equal("ms"))
+		Expect(metrics["name.p50"].GetValue()).To(Equal(250.0))
+	})
+
+	It("records the time since a start", func() {
+		metric := metricemitter.NewTimer("name", "source-id", []float64{0.5})
+		metric.ObserveSince(time.Now().Add(-time.Second))
+
+		metrics := histogramEnvelope(metric).GetGauge().GetMetrics()
+		Expect(metrics["name.p50"].GetValue()).To(BeNumerically(">=", 1000))
+	})
+
+	It("keeps the observations when the first send fails", func() {
+		metric := metricemitter.NewTimer("name", "source-id", []float64{0.5})
+		metric.Observe(start, stop)
+
+		err := metric.WithEnvelope(func(e *v2.Envelope) error {
+			return errors.New("some-error")
+		})
+		Expect(err).To(HaveOccurred())
+
+		metrics := histogramEnvelope(metric).GetGauge().GetMetrics()
+		Expect(metrics["name.count"].GetValue()).To(Equal(1.0))
+		Expect(metrics["name.p50"].GetValue()).To(Equal(250.0))
+	})
+
+	Context("when raw", func() {
+		It("emits a timer envelope per duration", func() {
+			metric := metricemitter.NewRawTimer("name", "source-id",
+				metricemitter.WithTags(map[string]string{
+					"a": "1",
+				}))
+			metric.Observe(start, stop)
+			metric.Observe(start, stop)
+
+			var envs []*v2.Envelope
+			err := metric.WithEnvelope(func(e *v2.Envelope) error {
+				envs = append(envs, e)
+				return nil
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(envs).To(HaveLen(2))
+			Expect(envs[0].GetSourceId()).To(Equal("source-id"))
+			Expect(envs[0].GetDeprecatedTags()["a"].GetText()).To(Equal("1"))
+			Expect(envs[0].GetTimer()).To(Equal(&v2.Timer{
+				Name:  "name",
+				Start: start.UnixNano(),
+				Stop:  stop.UnixNano(),
+			}))
+			Expect(metric.GetCount()).To(BeZero())
+		})
+
+		It("keeps the durations that fail to send", func() {
+			metric := metricemitter.NewRawTimer("name", "source-id")
+			metric.Observe(start, stop)
+			metric.Observe(start, stop.Add(time.Second))
+
+			var sent int
+			err := metric.WithEnvelope(func(e *v2.Envelope) error {
+				if sent == 1 {
+					return errors.New("some-error")
+				}
+				sent++
+				return nil
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(metric.GetCount()).To(Equal(uint64(1)))
+
+			metric.Observe(start, stop)
+			var envs []*v2.Envelope
+			err = metric.WithEnvelope(func(e *v2.Envelope) error {
+				envs = append(envs, e)
+				return nil
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(envs).To(HaveLen(2))
+			Expect(envs[0].GetTimer().GetStop()).To(Equal(stop.Add(time.Second).UnixNano()))
+			Expect(envs[1].GetTimer().GetStop()).To(Equal(stop.UnixNano()))
+		})
+
+	})
+})
+
+type envelopeWither interface {
+	WithEnvelope(func(*v2.Envelope) error) error
+}
+
+func histogramEnvelope(m envelopeWither) *v2.Envelope {
+	var env *v2.Envelope
+	err := m.WithEnvelope(func(e *v2.Envelope) error {
+		env = e
+		return nil
+	})
+	Expect(err).ToNot(HaveOccurred())
+
+	return env
+}
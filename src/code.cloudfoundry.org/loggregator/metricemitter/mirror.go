@@ -0,0 +1,33 @@
+package metricemitter
+
+// Summarizer provides the count and sum of all observations and the most
+// recent quantiles of a Histogram or Timer.
+type Summarizer interface {
+	Summary() (count uint64, sum float64, quantiles map[float64]float64)
+}
+
+// Mirror is given every metric a Client creates so that the metric can be
+// read by other means than the firehose, e.g. a Prometheus endpoint. The
+// tags do not include the tags of the Client.
+type Mirror interface {
+	MirrorCounter(name string, tags map[string]string, c *Counter)
+	MirrorGauge(name, unit string, tags map[string]string, g *Gauge)
+	MirrorSummary(name, unit string, tags map[string]string, s Summarizer)
+}
+
+// WithMirror gives every metric the client creates to the mirror.
+func WithMirror(m Mirror) ClientOption {
+	return func(c *Client) {
+		c.mirror = m
+	}
+}
+
+// mirrorTags returns the tags of the metric without the client's tags.
+func (c *Client) mirrorTags(t Tagged) map[string]string {
+	tags := t.textTags()
+	for k := range c.tags {
+		delete(tags, k)
+	}
+
+	return tags
+}
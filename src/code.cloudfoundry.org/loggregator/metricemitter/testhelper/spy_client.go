@@ -15,9 +15,21 @@ type gaugeMetric struct {
 	metric     *metricemitter.Gauge
 }
 
+type histogramMetric struct {
+	metricName string
+	metric     *metricemitter.Histogram
+}
+
+type timerMetric struct {
+	metricName string
+	metric     *metricemitter.Timer
+}
+
 type SpyMetricClient struct {
-	counterMetrics []counterMetric
-	gaugeMetrics   []gaugeMetric
+	counterMetrics   []counterMetric
+	gaugeMetrics     []gaugeMetric
+	histogramMetrics []histogramMetric
+	timerMetrics     []timerMetric
 }
 
 func NewMetricClient() *SpyMetricClient {
@@ -46,6 +58,39 @@ func (s *SpyMetricClient) NewGauge(name, unit string, opts ...metricemitter.Metr
 	return m
 }
 
+func (s *SpyMetricClient) NewHistogram(name, unit string, opts ...metricemitter.MetricOption) *metricemitter.Histogram {
+	m := metricemitter.NewHistogram(name, unit, "", metricemitter.DefaultQuantiles, opts...)
+
+	s.histogramMetrics = append(s.histogramMetrics, histogramMetric{
+		metricName: name,
+		metric:     m,
+	})
+
+	return m
+}
+
+func (s *SpyMetricClient) NewTimer(name string, opts ...metricemitter.MetricOption) *metricemitter.Timer {
+	m := metricemitter.NewTimer(name, "", metricemitter.DefaultQuantiles, opts...)
+
+	s.timerMetrics = append(s.timerMetrics, timerMetric{
+		metricName: name,
+		metric:     m,
+	})
+
+	return m
+}
+
+func (s *SpyMetricClient) NewRawTimer(name string, opts ...metricemitter.MetricOption) *metricemitter.Timer {
+	m := metricemitter.NewRawTimer(name, "", opts...)
+
+	s.timerMetrics = append(s.timerMetrics, timerMetric{
+		metricName: name,
+		metric:     m,
+	})
+
+	return m
+}
+
 func (s *SpyMetricClient) GetDelta(name string) uint64 {
 	for _, m := range s.counterMetrics {
 		if m.metricName == name {
@@ -83,6 +128,24 @@ func (s *SpyMetricClient) GetEnvelopes(name string) []*v2.Envelope {
 		}
 	}
 
+	for _, m := range s.histogramMetrics {
+		if m.metricName == name {
+			m.metric.WithEnvelope(func(e *v2.Envelope) error {
+				envs = append(envs, e)
+				return nil
+			})
+		}
+	}
+
+	for _, m := range s.timerMetrics {
+		if m.metricName == name {
+			m.metric.WithEnvelope(func(e *v2.Envelope) error {
+				envs = append(envs, e)
+				return nil
+			})
+		}
+	}
+
 	return envs
 }
 
@@ -95,3 +158,21 @@ func (s *SpyMetricClient) GetValue(name string) float64 {
 
 	return 0
 }
+
+// GetCount returns the number of observations of the first histogram or
+// timer with the given name.
+func (s *SpyMetricClient) GetCount(name string) uint64 {
+	for _, m := range s.histogramMetrics {
+		if m.metricName == name {
+			return m.metric.GetCount()
+		}
+	}
+
+	for _, m := range s.timerMetrics {
+		if m.metricName == name {
+			return m.metric.GetCount()
+		}
+	}
+
+	return 0
+}
@@ -19,7 +19,9 @@ import (
 	clientpoolv1 "code.cloudfoundry.org/loggregator/metron/internal/clientpool/v1"
 	egress "code.cloudfoundry.org/loggregator/metron/internal/egress/v1"
 	ingress "code.cloudfoundry.org/loggregator/metron/internal/ingress/v1"
+	"code.cloudfoundry.org/loggregator/metron/internal/timeraggregator"
 	"code.cloudfoundry.org/loggregator/plumbing"
+	"code.cloudfoundry.org/loggregator/plumbing/limits"
 )
 
 type AppV1 struct {
@@ -27,10 +29,34 @@ type AppV1 struct {
 	creds           credentials.TransportCredentials
 	healthRegistrar *healthendpoint.Registrar
 	metricClient    MetricClient
+	mirror          *healthendpoint.Mirror
+	limiter         *limits.Limiter
+	timerAggregator *timeraggregator.TimerAggregator
 }
 
-func NewV1App(c *Config, r *healthendpoint.Registrar, creds credentials.TransportCredentials, m MetricClient) *AppV1 {
-	return &AppV1{config: c, healthRegistrar: r, creds: creds, metricClient: m}
+// NewV1App returns the v1 API of Metron. The counters of its metric batcher
+// are mirrored to the given mirror and received envelopes are checked by
+// the given limiter, which is shared with the v2 API. HttpStartStop events
+// selected by the timer aggregator are written to it instead of Doppler's
+// v1 API. The timer aggregator may be nil.
+func NewV1App(
+	c *Config,
+	r *healthendpoint.Registrar,
+	creds credentials.TransportCredentials,
+	m MetricClient,
+	mirror *healthendpoint.Mirror,
+	limiter *limits.Limiter,
+	t *timeraggregator.TimerAggregator,
+) *AppV1 {
+	return &AppV1{
+		config:          c,
+		healthRegistrar: r,
+		creds:           creds,
+		metricClient:    m,
+		mirror:          mirror,
+		limiter:         limiter,
+		timerAggregator: t,
+	}
 }
 
 func (a *AppV1) Start() {
@@ -42,21 +68,24 @@ func (a *AppV1) Start() {
 	batcher, eventWriter := a.initializeMetrics(statsStopChan)
 
 	log.Print("Startup: Setting up the Metron agent")
-	marshaller := a.initializeV1DopplerPool(batcher)
+	var writer egress.EnvelopeWriter = a.initializeV1DopplerPool(batcher)
+	if a.timerAggregator != nil {
+		writer = egress.NewTimerWriter(a.timerAggregator, writer)
+	}
 
 	messageTagger := egress.NewTagger(
 		a.config.Deployment,
 		a.config.Job,
 		a.config.Index,
 		a.config.IP,
-		marshaller,
+		writer,
 	)
 	aggregator := egress.NewAggregator(messageTagger)
 	eventWriter.SetWriter(aggregator)
 
 	dropsondeUnmarshaller := ingress.NewUnMarshaller(aggregator, batcher)
 	metronAddress := fmt.Sprintf("127.0.0.1:%d", a.config.IncomingUDPPort)
-	networkReader, err := ingress.New(metronAddress, "dropsondeAgentListener", dropsondeUnmarshaller)
+	networkReader, err := ingress.New(metronAddress, "dropsondeAgentListener", dropsondeUnmarshaller, a.limiter)
 	if err != nil {
 		log.Panic(fmt.Errorf("Failed to listen on %s: %s", metronAddress, err))
 	}
@@ -66,10 +95,12 @@ func (a *AppV1) Start() {
 	networkReader.StartWriting()
 }
 
-func (a *AppV1) initializeMetrics(stopChan chan struct{}) (*metricbatcher.MetricBatcher, *egress.EventWriter) {
+func (a *AppV1) initializeMetrics(stopChan chan struct{}) (*healthendpoint.MirroredBatcher, *egress.EventWriter) {
 	eventWriter := egress.New("MetronAgent")
 	metricSender := metric_sender.NewMetricSender(eventWriter)
-	metricBatcher := metricbatcher.New(metricSender, time.Duration(a.config.MetricBatchIntervalMilliseconds)*time.Millisecond)
+	metricBatcher := a.mirror.Batcher(
+		metricbatcher.New(metricSender, time.Duration(a.config.MetricBatchIntervalMilliseconds)*time.Millisecond),
+	)
 	metrics.Initialize(metricSender, metricBatcher)
 
 	stats := runtime_stats.NewRuntimeStats(eventWriter, time.Duration(a.config.RuntimeStatsIntervalMilliseconds)*time.Millisecond)
@@ -77,7 +108,7 @@ func (a *AppV1) initializeMetrics(stopChan chan struct{}) (*metricbatcher.Metric
 	return metricBatcher, eventWriter
 }
 
-func (a *AppV1) initializeV1DopplerPool(batcher *metricbatcher.MetricBatcher) *egress.EventMarshaller {
+func (a *AppV1) initializeV1DopplerPool(batcher egress.EventBatcher) *egress.EventMarshaller {
 	pool := a.setupGRPC()
 
 	marshaller := egress.NewMarshaller(batcher)
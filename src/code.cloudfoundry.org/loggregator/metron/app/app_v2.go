@@ -4,19 +4,28 @@ import (
 	"fmt"
 	"log"
 	"math/rand"
+	"net/http"
+	"path"
 	"time"
 
 	"code.cloudfoundry.org/loggregator/diodes"
 	"code.cloudfoundry.org/loggregator/healthendpoint"
 	"code.cloudfoundry.org/loggregator/metricemitter"
 	"code.cloudfoundry.org/loggregator/plumbing"
+	"code.cloudfoundry.org/loggregator/plumbing/limits"
 
 	gendiodes "github.com/cloudfoundry/diodes"
 
 	"code.cloudfoundry.org/loggregator/metron/internal/clientpool"
 	clientpoolv2 "code.cloudfoundry.org/loggregator/metron/internal/clientpool/v2"
 	egress "code.cloudfoundry.org/loggregator/metron/internal/egress/v2"
+	"code.cloudfoundry.org/loggregator/metron/internal/filetail"
+	"code.cloudfoundry.org/loggregator/metron/internal/hostmetrics"
+	"code.cloudfoundry.org/loggregator/metron/internal/ingress/otlp"
 	ingress "code.cloudfoundry.org/loggregator/metron/internal/ingress/v2"
+	"code.cloudfoundry.org/loggregator/metron/internal/redactor"
+	"code.cloudfoundry.org/loggregator/metron/internal/stitcher"
+	"code.cloudfoundry.org/loggregator/metron/internal/timeraggregator"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -26,6 +35,7 @@ import (
 type MetricClient interface {
 	NewCounter(name string, opts ...metricemitter.MetricOption) *metricemitter.Counter
 	NewGauge(name, unit string, opts ...metricemitter.MetricOption) *metricemitter.Gauge
+	NewTimer(name string, opts ...metricemitter.MetricOption) *metricemitter.Timer
 }
 
 type AppV2 struct {
@@ -34,27 +44,32 @@ type AppV2 struct {
 	clientCreds     credentials.TransportCredentials
 	serverCreds     credentials.TransportCredentials
 	metricClient    MetricClient
+	limiter         *limits.Limiter
+
+	envelopeBuffer  *diodes.ManyToOneEnvelopeV2
+	setter          ingress.DataSetter
+	timerAggregator *timeraggregator.TimerAggregator
 }
 
+// NewV2App returns the v2 API of Metron. The redaction, log stitching and
+// timer aggregation stages in front of its buffer are set up right away so
+// that the v1 API can hand timers to the timer aggregation. The limiter is
+// shared with the v1 API.
 func NewV2App(
 	c *Config,
 	r *healthendpoint.Registrar,
 	clientCreds credentials.TransportCredentials,
 	serverCreds credentials.TransportCredentials,
 	metricClient MetricClient,
+	limiter *limits.Limiter,
 ) *AppV2 {
-	return &AppV2{
+	a := &AppV2{
 		config:          c,
 		healthRegistrar: r,
 		clientCreds:     clientCreds,
 		serverCreds:     serverCreds,
 		metricClient:    metricClient,
-	}
-}
-
-func (a *AppV2) Start() {
-	if a.serverCreds == nil {
-		log.Panic("Failed to load TLS server config")
+		limiter:         limiter,
 	}
 
 	droppedMetric := a.metricClient.NewCounter("dropped",
@@ -62,7 +77,7 @@ func (a *AppV2) Start() {
 		metricemitter.WithTags(map[string]string{"direction": "ingress"}),
 	)
 
-	envelopeBuffer := diodes.NewManyToOneEnvelopeV2(10000, gendiodes.AlertFunc(func(missed int) {
+	a.envelopeBuffer = diodes.NewManyToOneEnvelopeV2(10000, gendiodes.AlertFunc(func(missed int) {
 		// metric-documentation-v2: (loggregator.metron.dropped) Number of v2 envelopes
 		// dropped from the metron ingress diode
 		droppedMetric.Increment(uint64(missed))
@@ -70,10 +85,31 @@ func (a *AppV2) Start() {
 		log.Printf("Dropped %d v2 envelopes", missed)
 	}))
 
+	a.setter = a.initializeRedactor(a.envelopeBuffer)
+	a.setter = a.initializeStitcher(a.setter)
+	a.timerAggregator = a.initializeTimerAggregator(a.setter)
+	if a.timerAggregator != nil {
+		a.setter = a.timerAggregator
+	}
+
+	return a
+}
+
+// TimerAggregator returns the timer aggregation stage or nil if timer
+// aggregation is disabled.
+func (a *AppV2) TimerAggregator() *timeraggregator.TimerAggregator {
+	return a.timerAggregator
+}
+
+func (a *AppV2) Start() {
+	if a.serverCreds == nil {
+		log.Panic("Failed to load TLS server config")
+	}
+
 	pool := a.initializePool()
 	counterAggr := egress.NewCounterAggregator(pool)
 	tx := egress.NewTransponder(
-		envelopeBuffer,
+		a.envelopeBuffer,
 		counterAggr,
 		a.config.Tags,
 		100, time.Second,
@@ -81,13 +117,180 @@ func (a *AppV2) Start() {
 	)
 	go tx.Start()
 
+	a.startHostMetrics(a.envelopeBuffer)
+
 	metronAddress := fmt.Sprintf("127.0.0.1:%d", a.config.GRPC.Port)
 	log.Printf("metron v2 API started on addr %s", metronAddress)
-	rx := ingress.NewReceiver(envelopeBuffer, a.metricClient)
+	a.startFileTailing(a.setter)
+	a.startHTTPIngress(a.setter, a.limiter)
+	a.startOTLPIngress(a.setter, a.limiter)
+	rx := ingress.NewReceiver(a.setter, a.limiter, a.metricClient)
 	ingressServer := ingress.NewServer(metronAddress, rx, grpc.Creds(a.serverCreds))
 	ingressServer.Start()
 }
 
+func (a *AppV2) startHostMetrics(setter ingress.DataSetter) {
+	c := a.config.HostMetrics
+	if !c.Enabled {
+		return
+	}
+
+	for _, patterns := range [][]string{c.IncludeInterfaces, c.ExcludeInterfaces} {
+		for _, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				log.Fatalf("Invalid interface pattern %q: %s", p, err)
+			}
+		}
+	}
+
+	collector := hostmetrics.New(
+		setter,
+		time.Duration(c.IntervalMilliseconds)*time.Millisecond,
+		hostmetrics.WithInterfaces(c.IncludeInterfaces, c.ExcludeInterfaces),
+		hostmetrics.WithStatfsTimeout(time.Duration(c.StatfsTimeoutMilliseconds)*time.Millisecond),
+	)
+	go collector.Start()
+}
+
+func (a *AppV2) startHTTPIngress(setter ingress.DataSetter, limiter ingress.Limiter) {
+	c := a.config.HTTPIngress
+	if !c.Enabled {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/v2/envelopes", ingress.NewHTTPHandler(setter, limiter, c.MaxBodyBytes, a.metricClient))
+
+	server := &http.Server{
+		Addr:         fmt.Sprintf("127.0.0.1:%d", c.Port),
+		Handler:      mux,
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+	log.Printf("metron v2 HTTP API started on addr %s", server.Addr)
+
+	go func() {
+		log.Fatalf("Failed to serve v2 HTTP API: %s", server.ListenAndServe())
+	}()
+}
+
+func (a *AppV2) startOTLPIngress(setter ingress.DataSetter, limiter ingress.Limiter) {
+	c := a.config.OTLP
+	if !c.Enabled {
+		return
+	}
+
+	rx := otlp.NewReceiver(setter, limiter, a.metricClient)
+
+	grpcAddr := fmt.Sprintf("127.0.0.1:%d", c.GRPCPort)
+	log.Printf("metron OTLP/gRPC API started on addr %s", grpcAddr)
+	grpcServer := otlp.NewServer(grpcAddr, rx, grpc.RPCDecompressor(grpc.NewGZIPDecompressor()))
+	go grpcServer.Start()
+
+	server := &http.Server{
+		Addr:         fmt.Sprintf("127.0.0.1:%d", c.HTTPPort),
+		Handler:      otlp.NewHTTPHandler(rx, c.MaxBodyBytes),
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+	log.Printf("metron OTLP/HTTP API started on addr %s", server.Addr)
+
+	go func() {
+		log.Fatalf("Failed to serve OTLP/HTTP API: %s", server.ListenAndServe())
+	}()
+}
+
+func (a *AppV2) startFileTailing(setter ingress.DataSetter) {
+	c := a.config.FileTailing
+	if !c.Enabled {
+		return
+	}
+
+	t, err := filetail.New(setter, filetail.Config{
+		Sources:      c.Sources,
+		OffsetsFile:  c.OffsetsFile,
+		PollInterval: time.Duration(c.PollIntervalMilliseconds) * time.Millisecond,
+		MaxLineBytes: c.MaxLineBytes,
+	}, a.metricClient)
+	if err != nil {
+		log.Panicf("Failed to configure file tailing: %s", err)
+	}
+	go t.Start()
+}
+
+func (a *AppV2) initializeStitcher(setter ingress.DataSetter) ingress.DataSetter {
+	c := a.config.LogStitching
+	if !c.Enabled {
+		return setter
+	}
+
+	s, err := stitcher.New(setter, stitcher.Config{
+		StartPatterns:        c.StartPatterns,
+		IndentedContinuation: c.IndentedContinuation,
+		MaxLines:             c.MaxLines,
+		MaxBytes:             c.MaxBytes,
+		FlushTimeout:         time.Duration(c.FlushTimeoutMilliseconds) * time.Millisecond,
+	}, a.metricClient)
+	if err != nil {
+		log.Panicf("Failed to configure log stitching: %s", err)
+	}
+	go s.Start()
+
+	return s
+}
+
+func (a *AppV2) initializeTimerAggregator(setter ingress.DataSetter) *timeraggregator.TimerAggregator {
+	c := a.config.TimerAggregation
+	if !c.Enabled {
+		return nil
+	}
+
+	buckets := c.Buckets
+	if c.BucketType == "exponential" {
+		var err error
+		buckets, err = timeraggregator.ExponentialBuckets(
+			c.ExponentialBucketStart,
+			c.ExponentialBucketFactor,
+			c.ExponentialBucketCount,
+		)
+		if err != nil {
+			log.Panicf("Failed to configure timer aggregation: %s", err)
+		}
+	}
+
+	t, err := timeraggregator.New(setter, timeraggregator.Config{
+		Names:     c.Names,
+		SourceIDs: c.SourceIDs,
+		GroupTags: c.GroupTags,
+		Buckets:   buckets,
+		Interval:  time.Duration(c.IntervalMilliseconds) * time.Millisecond,
+		MaxSeries: c.MaxSeries,
+	}, a.metricClient)
+	if err != nil {
+		log.Panicf("Failed to configure timer aggregation: %s", err)
+	}
+	go t.Start()
+
+	return t
+}
+
+func (a *AppV2) initializeRedactor(setter ingress.DataSetter) ingress.DataSetter {
+	c := a.config.Redaction
+	if !c.Enabled {
+		return setter
+	}
+
+	r, err := redactor.NewFromFile(setter, c.RulesFile, c.RedactTags, a.metricClient)
+	if err != nil {
+		log.Panicf("Failed to configure redaction: %s", err)
+	}
+	if c.ReloadIntervalMilliseconds > 0 {
+		go r.Watch(time.Duration(c.ReloadIntervalMilliseconds) * time.Millisecond)
+	}
+
+	return r
+}
+
 func (a *AppV2) initializePool() *clientpoolv2.ClientPool {
 	if a.clientCreds == nil {
 		log.Panic("Failed to load TLS client config")
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+
+	"code.cloudfoundry.org/loggregator/metron/internal/filetail"
+	"code.cloudfoundry.org/loggregator/plumbing/limits"
 )
 
 type GRPC struct {
@@ -15,6 +18,87 @@ type GRPC struct {
 	CipherSuites []string
 }
 
+// LogStitching configures joining multi-line log output (e.g. stack traces)
+// into a single envelope before it is sent to Doppler.
+type LogStitching struct {
+	Enabled                  bool
+	StartPatterns            []string
+	IndentedContinuation     bool
+	MaxLines                 int
+	MaxBytes                 int
+	FlushTimeoutMilliseconds uint
+}
+
+// Redaction configures masking of sensitive data in v2 log payloads and tag
+// values. Rules are read from RulesFile and reloaded when it changes unless
+// ReloadIntervalMilliseconds is 0.
+type Redaction struct {
+	Enabled                    bool
+	RulesFile                  string
+	RedactTags                 bool
+	ReloadIntervalMilliseconds uint
+}
+
+// TimerAggregation configures folding v2 timers into a histogram per
+// interval. Buckets are either the given upper bounds in milliseconds or,
+// when BucketType is "exponential", ExponentialBucketCount bounds starting
+// at ExponentialBucketStart and growing by ExponentialBucketFactor.
+type TimerAggregation struct {
+	Enabled                 bool
+	Names                   []string
+	SourceIDs               []string
+	GroupTags               []string
+	IntervalMilliseconds    uint
+	MaxSeries               int
+	BucketType              string
+	Buckets                 []float64
+	ExponentialBucketStart  float64
+	ExponentialBucketFactor float64
+	ExponentialBucketCount  int
+}
+
+// HostMetrics configures collecting CPU, load, memory, disk and network
+// statistics of the VM.
+type HostMetrics struct {
+	Enabled              bool
+	IntervalMilliseconds uint
+
+	// IncludeInterfaces and ExcludeInterfaces are patterns selecting the
+	// network interfaces that are collected. All interfaces but the
+	// excluded ones are collected when IncludeInterfaces is empty.
+	IncludeInterfaces []string
+	ExcludeInterfaces []string
+
+	StatfsTimeoutMilliseconds uint
+}
+
+// FileTailing configures tailing local files, e.g. the logs of BOSH jobs,
+// into v2 log envelopes.
+type FileTailing struct {
+	Enabled                  bool
+	Sources                  []filetail.Source
+	OffsetsFile              string
+	PollIntervalMilliseconds uint
+	MaxLineBytes             int
+}
+
+// HTTPIngress configures the localhost HTTP API accepting v2 envelopes as
+// JSON.
+type HTTPIngress struct {
+	Enabled      bool
+	Port         uint16
+	MaxBodyBytes int64
+}
+
+// OTLP configures the localhost OTLP/gRPC and OTLP/HTTP APIs accepting
+// OpenTelemetry logs and metrics exports.
+type OTLP struct {
+	Enabled      bool
+	GRPCPort     uint16
+	HTTPPort     uint16
+	MaxBodyBytes int64
+}
+
 type Config struct {
 	Deployment string
 	Zone       string
@@ -28,7 +112,16 @@ type Config struct {
 	IncomingUDPPort    int
 	HealthEndpointPort uint
 
-	GRPC GRPC
+	GRPC        GRPC
+	HTTPIngress HTTPIngress
+	OTLP        OTLP
+
+	LogStitching     LogStitching
+	Redaction        Redaction
+	TimerAggregation TimerAggregation
+	HostMetrics      HostMetrics
+	FileTailing      FileTailing
+	IngressLimits    limits.Config
 
 	DopplerAddr string
 
@@ -52,6 +145,38 @@ func Parse(reader io.Reader) (*Config, error) {
 	config := &Config{
 		MetricBatchIntervalMilliseconds:  5000,
 		RuntimeStatsIntervalMilliseconds: 15000,
+		LogStitching: LogStitching{
+			MaxLines:                 100,
+			MaxBytes:                 65536,
+			FlushTimeoutMilliseconds: 500,
+		},
+		Redaction: Redaction{
+			ReloadIntervalMilliseconds: 10000,
+		},
+		TimerAggregation: TimerAggregation{
+			IntervalMilliseconds: 10000,
+			MaxSeries:            10000,
+			BucketType:           "fixed",
+			Buckets:              []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000},
+		},
+		HostMetrics: HostMetrics{
+			IntervalMilliseconds:      30000,
+			ExcludeInterfaces:         []string{"veth*", "cali*"},
+			StatfsTimeoutMilliseconds: 5000,
+		},
+		HTTPIngress: HTTPIngress{
+			Port:         3459,
+			MaxBodyBytes: 1048576,
+		},
+		OTLP: OTLP{
+			GRPCPort:     4317,
+			HTTPPort:     4318,
+			MaxBodyBytes: 4194304,
+		},
+		FileTailing: FileTailing{
+			PollIntervalMilliseconds: 1000,
+			MaxLineBytes:             65536,
+		},
 	}
 	err := json.NewDecoder(reader).Decode(config)
 	if err != nil {
@@ -62,5 +187,15 @@ func Parse(reader io.Reader) (*Config, error) {
 		return nil, fmt.Errorf("DopplerAddr is required")
 	}
 
+	if config.Redaction.Enabled && config.Redaction.RulesFile == "" {
+		return nil, fmt.Errorf("Redaction.RulesFile is required when redaction is enabled")
+	}
+
+	switch config.TimerAggregation.BucketType {
+	case "fixed", "exponential":
+	default:
+		return nil, fmt.Errorf("TimerAggregation.BucketType must be fixed or exponential")
+	}
+
 	return config, nil
 }
@@ -143,6 +143,87 @@ var _ = Describe("Metron", func() {
 	})
 })
 
+var _ = Describe("Metron with timer aggregation", func() {
+	var (
+		metronCleanup  func()
+		metronConfig   app.Config
+		consumerServer *Server
+	)
+
+	BeforeEach(func() {
+		var err error
+		consumerServer, err = NewServer()
+		Expect(err).ToNot(HaveOccurred())
+
+		config := testservers.BuildMetronConfig("localhost", consumerServer.Port())
+		config.TimerAggregation = app.TimerAggregation{
+			Enabled:              true,
+			Names:                []string{"http"},
+			IntervalMilliseconds: 100,
+			MaxSeries:            100,
+			BucketType:           "fixed",
+			Buckets:              []float64{10, 100},
+		}
+
+		var metronReady func()
+		metronCleanup, metronConfig, metronReady = testservers.StartMetron(config)
+		metronReady()
+	})
+
+	AfterEach(func() {
+		consumerServer.Stop()
+		metronCleanup()
+	})
+
+	It("aggregates HttpStartStop events received over UDP", func() {
+		udpEmitter, err := emitter.NewUdpEmitter(fmt.Sprintf("127.0.0.1:%d", metronConfig.IncomingUDPPort))
+		Expect(err).ToNot(HaveOccurred())
+		eventEmitter := emitter.NewEventEmitter(udpEmitter, "gorouter")
+
+		emitEnvelope := &events.Envelope{
+			Origin:    proto.String("gorouter"),
+			EventType: events.Envelope_HttpStartStop.Enum(),
+			HttpStartStop: &events.HttpStartStop{
+				StartTimestamp: proto.Int64(0),
+				StopTimestamp:  proto.Int64(int64(5 * time.Millisecond)),
+				PeerType:       events.PeerType_Client.Enum(),
+				Method:         events.Method_GET.Enum(),
+				Uri:            proto.String("http://example.com"),
+				StatusCode:     proto.Int32(200),
+				ContentLength:  proto.Int64(10),
+			},
+		}
+
+		go func() {
+			for range time.Tick(10 * time.Millisecond) {
+				eventEmitter.Emit(emitEnvelope)
+			}
+		}()
+
+		var rx v2.DopplerIngress_BatchSenderServer
+		Eventually(consumerServer.V2.BatchSenderInput.Arg0, 5).Should(Receive(&rx))
+
+		f := func() *v2.Envelope {
+			batch, err := rx.Recv()
+			Expect(err).ToNot(HaveOccurred())
+
+			for _, envelope := range batch.Batch {
+				if _, ok := envelope.GetGauge().GetMetrics()["http.count"]; ok {
+					return envelope
+				}
+			}
+
+			return nil
+		}
+		var gauge *v2.Envelope
+		Eventually(func() *v2.Envelope {
+			gauge = f()
+			return gauge
+		}, 5).ShouldNot(BeNil())
+		Expect(gauge.GetGauge().GetMetrics()["http.le_10"].GetValue()).To(BeNumerically(">", 0))
+	})
+})
+
 func HomeAddrToPort(addr net.Addr) int {
 	port, err := strconv.Atoi(strings.Replace(addr.String(), "127.0.0.1:", "", 1))
 	if err != nil {
@@ -0,0 +1,74 @@
+package counters
+
+import "math"
+
+const (
+	offset64 = 14695981039346656037
+	prime64  = 1099511628211
+
+	// separator delimits strings. It never occurs in UTF-8.
+	separator = 0xff
+)
+
+// Hash is an FNV-1a hash. Strings are delimited so that tags whose keys and
+// values are split differently do not hash alike.
+type Hash uint64
+
+// NewHash returns an empty Hash.
+func NewHash() Hash {
+	return offset64
+}
+
+// String adds the string to the hash.
+func (h Hash) String(s string) Hash {
+	for i := 0; i < len(s); i++ {
+		h ^= Hash(s[i])
+		h *= prime64
+	}
+	h ^= separator
+	h *= prime64
+
+	return h
+}
+
+// Uint64 adds the number to the hash.
+func (h Hash) Uint64(v uint64) Hash {
+	for i := uint(0); i < 64; i += 8 {
+		h ^= Hash(byte(v >> i))
+		h *= prime64
+	}
+
+	return h
+}
+
+// Float64 adds the number to the hash.
+func (h Hash) Float64(v float64) Hash {
+	return h.Uint64(math.Float64bits(v))
+}
+
+// Tags adds the tags sorted by key to the hash.
+func (h Hash) Tags(tags map[string]string) Hash {
+	var buf [16]string
+	keys := buf[:0]
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	SortKeys(keys)
+
+	for _, k := range keys {
+		h = h.String(k).String(tags[k])
+	}
+
+	return h
+}
+
+// SortKeys sorts the keys of tags. Tags are few, so an insertion sort is
+// used as it is faster than sort.Strings for few keys and does not
+// allocate.
+func SortKeys(keys []string) {
+	for i := 1; i < len(keys); i++ {
+		for j := i; j > 0 && keys[j] < keys[j-1]; j-- {
+			keys[j], keys[j-1] = keys[j-1], keys[j]
+		}
+	}
+}
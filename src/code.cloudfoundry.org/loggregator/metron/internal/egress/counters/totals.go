@@ -0,0 +1,165 @@
+// Package counters keeps the running totals of counters that are emitted as
+// deltas.
+package counters
+
+import (
+	"sync"
+	"time"
+)
+
+const shardCount = 16
+
+// DefaultMaxCounters is the number of counters Totals keep at most unless
+// configured otherwise.
+const DefaultMaxCounters = 100000
+
+// ID identifies a counter. Hash is the hash of the name, origin and tags of
+// the counter. As the tags are only known by their hash, counters whose tags
+// collide share a total. With a 64 bit hash this is negligible.
+type ID struct {
+	Name   string
+	Origin string
+	Hash   uint64
+}
+
+// Totals keeps the totals of counters. Counters that have not been seen
+// within the TTL are evicted and start over at zero. When the maximum number
+// of counters is reached, the counter seen least recently is evicted to make
+// room for a new one. It is safe for concurrent use. Counters are sharded by
+// their hash so that writers of different counters rarely contend.
+type Totals struct {
+	ttl         int64
+	now         func() time.Time
+	maxCounters int
+	shards      [shardCount]shard
+}
+
+type shard struct {
+	mu        sync.Mutex
+	totals    map[ID]total
+	lastSweep int64
+}
+
+type total struct {
+	value    uint64
+	lastSeen int64
+}
+
+// TotalsOption configures Totals.
+type TotalsOption func(*Totals)
+
+// WithClock sets the function the current time is read from. It defaults to
+// time.Now.
+func WithClock(now func() time.Time) TotalsOption {
+	return func(t *Totals) {
+		t.now = now
+	}
+}
+
+// WithMaxCounters sets the number of counters that are kept at most. It
+// defaults to DefaultMaxCounters.
+func WithMaxCounters(n int) TotalsOption {
+	return func(t *Totals) {
+		t.maxCounters = n
+	}
+}
+
+// NewTotals returns Totals that evict counters not seen within the given
+// TTL.
+func NewTotals(ttl time.Duration, opts ...TotalsOption) *Totals {
+	t := &Totals{
+		ttl:         int64(ttl),
+		now:         time.Now,
+		maxCounters: DefaultMaxCounters,
+	}
+	for _, o := range opts {
+		o(t)
+	}
+
+	// The maximum is kept per shard so that a shard never needs to look at
+	// the others.
+	t.maxCounters = (t.maxCounters + shardCount - 1) / shardCount
+	if t.maxCounters < 1 {
+		t.maxCounters = 1
+	}
+
+	now := t.now().UnixNano()
+	for i := range t.shards {
+		t.shards[i].totals = make(map[ID]total)
+		t.shards[i].lastSweep = now
+	}
+
+	return t
+}
+
+// Add adds the delta to the total of the counter and returns the new total.
+func (t *Totals) Add(id ID, delta uint64) uint64 {
+	now := t.now().UnixNano()
+	s := &t.shards[id.Hash%shardCount]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now-s.lastSweep > t.ttl {
+		s.sweep(now, t.ttl)
+	}
+
+	c, ok := s.totals[id]
+	if ok && now-c.lastSeen > t.ttl {
+		c.value = 0
+	}
+	if !ok && len(s.totals) >= t.maxCounters {
+		s.sweep(now, t.ttl)
+		if len(s.totals) >= t.maxCounters {
+			s.evictOldest()
+		}
+	}
+	c.value += delta
+	c.lastSeen = now
+	s.totals[id] = c
+
+	return c.value
+}
+
+// Len returns the number of counters.
+func (t *Totals) Len() int {
+	var n int
+	for i := range t.shards {
+		s := &t.shards[i]
+		s.mu.Lock()
+		n += len(s.totals)
+		s.mu.Unlock()
+	}
+
+	return n
+}
+
+// sweep evicts the counters that have not been seen within the TTL. It
+// needs to be called with the lock held.
+func (s *shard) sweep(now, ttl int64) {
+	for id, c := range s.totals {
+		if now-c.lastSeen > ttl {
+			delete(s.totals, id)
+		}
+	}
+	s.lastSweep = now
+}
+
+// evictOldest evicts the counter seen least recently. It needs to be called
+// with the lock held.
+func (s *shard) evictOldest() {
+	var (
+		oldest   ID
+		lastSeen int64
+		found    bool
+	)
+	for id, c := range s.totals {
+		if !found || c.lastSeen < lastSeen {
+			oldest, lastSeen, found = id, c.lastSeen, true
+		}
+	}
+
+	if found {
+		delete(s.totals, oldest)
+	}
+}
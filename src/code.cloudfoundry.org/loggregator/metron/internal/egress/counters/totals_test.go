@@ -0,0 +1,187 @@
+package counters_test
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/metron/internal/egress/counters"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Totals", func() {
+	It("accumulates the deltas of a counter", func() {
+		totals := counters.NewTotals(time.Minute)
+		id := counters.ID{Name: "name", Hash: 1}
+
+		Expect(totals.Add(id, 10)).To(Equal(uint64(10)))
+		Expect(totals.Add(id, 15)).To(Equal(uint64(25)))
+	})
+
+	It("accumulates counters separately", func() {
+		totals := counters.NewTotals(time.Minute)
+
+		totals.Add(counters.ID{Name: "name-1", Hash: 1}, 10)
+		totals.Add(counters.ID{Name: "name-1", Origin: "origin", Hash: 1}, 10)
+
+		Expect(totals.Add(counters.ID{Name: "name-1", Hash: 2}, 5)).To(Equal(uint64(5)))
+		Expect(totals.Add(counters.ID{Name: "name-2", Hash: 1}, 5)).To(Equal(uint64(5)))
+		Expect(totals.Add(counters.ID{Name: "name-1", Hash: 1}, 5)).To(Equal(uint64(15)))
+		Expect(totals.Len()).To(Equal(4))
+	})
+
+	It("evicts counters that have not been seen within the TTL", func() {
+		clock := newFakeClock()
+		totals := counters.NewTotals(time.Minute, counters.WithClock(clock.Now))
+		stale := counters.ID{Name: "stale", Hash: 1}
+		active := counters.ID{Name: "active", Hash: 2}
+
+		totals.Add(stale, 10)
+		totals.Add(active, 10)
+
+		for i := 0; i < 4; i++ {
+			clock.Add(30 * time.Second)
+			totals.Add(active, 10)
+		}
+
+		Expect(totals.Add(active, 10)).To(Equal(uint64(60)))
+		Expect(totals.Add(stale, 10)).To(Equal(uint64(10)))
+	})
+
+	It("keeps counters seen within the TTL", func() {
+		clock := newFakeClock()
+		totals := counters.NewTotals(time.Minute, counters.WithClock(clock.Now))
+		id := counters.ID{Name: "name", Hash: 1}
+
+		totals.Add(id, 10)
+		clock.Add(time.Minute)
+
+		Expect(totals.Add(id, 10)).To(Equal(uint64(20)))
+	})
+
+	It("sweeps counters that have not been seen within the TTL", func() {
+		clock := newFakeClock()
+		totals := counters.NewTotals(time.Minute, counters.WithClock(clock.Now))
+		for i := 0; i < 100; i++ {
+			totals.Add(counters.ID{Name: fmt.Sprint("name-", i), Hash: uint64(i)}, 1)
+		}
+		Expect(totals.Len()).To(Equal(100))
+
+		clock.Add(2 * time.Minute)
+		for i := 0; i < 16; i++ {
+			totals.Add(counters.ID{Name: "new", Hash: uint64(i)}, 1)
+		}
+
+		Expect(totals.Len()).To(Equal(16))
+	})
+
+	It("evicts the counter seen least recently when full", func() {
+		clock := newFakeClock()
+		totals := counters.NewTotals(
+			time.Minute,
+			counters.WithClock(clock.Now),
+			counters.WithMaxCounters(32),
+		)
+		// The hashes all fall into the same shard which holds two counters.
+		recent := counters.ID{Name: "recent", Hash: 0}
+		old := counters.ID{Name: "old", Hash: 16}
+
+		totals.Add(recent, 10)
+		totals.Add(old, 10)
+		clock.Add(time.Second)
+		totals.Add(recent, 10)
+		totals.Add(counters.ID{Name: "new", Hash: 32}, 10)
+
+		Expect(totals.Len()).To(Equal(2))
+		Expect(totals.Add(recent, 0)).To(Equal(uint64(20)))
+	})
+
+	It("keeps at most the maximum number of counters", func() {
+		totals := counters.NewTotals(time.Minute, counters.WithMaxCounters(32))
+		for i := 0; i < 1000; i++ {
+			totals.Add(counters.ID{Name: fmt.Sprint("name-", i), Hash: uint64(i)}, 1)
+		}
+
+		Expect(totals.Len()).To(Equal(32))
+	})
+
+	It("is safe for concurrent use", func() {
+		totals := counters.NewTotals(time.Minute)
+		id := counters.ID{Name: "name", Hash: 1}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 1000; j++ {
+					totals.Add(id, 1)
+				}
+			}()
+		}
+		wg.Wait()
+
+		Expect(totals.Add(id, 0)).To(Equal(uint64(4000)))
+	})
+})
+
+var _ = Describe("Hash", func() {
+	It("hashes tags independent of their order", func() {
+		tags := map[string]string{}
+		for i := 0; i < 20; i++ {
+			tags[fmt.Sprint("key-", i)] = fmt.Sprint("value-", i)
+		}
+
+		h := counters.NewHash().Tags(tags)
+		for i := 0; i < 10; i++ {
+			Expect(counters.NewHash().Tags(tags)).To(Equal(h))
+		}
+	})
+
+	It("distinguishes tags that are split differently", func() {
+		a := counters.NewHash().Tags(map[string]string{"protocol": "tcp"})
+		b := counters.NewHash().Tags(map[string]string{"proto": "coltcp"})
+		c := counters.NewHash().Tags(map[string]string{"protocolt": "cp"})
+
+		Expect(a).ToNot(Equal(b))
+		Expect(a).ToNot(Equal(c))
+		Expect(b).ToNot(Equal(c))
+	})
+
+	It("distinguishes numbers", func() {
+		Expect(counters.NewHash().Uint64(1)).ToNot(Equal(counters.NewHash().Uint64(2)))
+		Expect(counters.NewHash().Float64(1)).ToNot(Equal(counters.NewHash().Float64(1.5)))
+	})
+
+	It("sorts keys", func() {
+		keys := []string{"c", "a", "b"}
+		counters.SortKeys(keys)
+
+		Expect(keys).To(Equal([]string{"a", "b", "c"}))
+	})
+})
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(0, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
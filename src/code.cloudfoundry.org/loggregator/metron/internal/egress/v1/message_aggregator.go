@@ -1,29 +1,61 @@
 package v1
 
 import (
-	"crypto/sha1"
-	"fmt"
-	"io"
-	"sort"
-	"sync"
 	"time"
 
+	"code.cloudfoundry.org/loggregator/metron/internal/egress/counters"
+
 	"github.com/cloudfoundry/dropsonde/metrics"
 	"github.com/cloudfoundry/sonde-go/events"
 )
 
-var MaxTTL = time.Minute
+// DefaultCounterTTL is the time after which counters that have not been
+// seen are evicted and start over at zero.
+const DefaultCounterTTL = 10 * time.Minute
 
+// MessageAggregator sets the total of counter events from their deltas.
+// Counters are identified by their name, origin and tags. It is safe for
+// concurrent use.
 type MessageAggregator struct {
-	mu            sync.Mutex
-	counterTotals map[counterID]uint64
-	outputWriter  EnvelopeWriter
+	totals       *counters.Totals
+	outputWriter EnvelopeWriter
+}
+
+// AggregatorOption configures a MessageAggregator.
+type AggregatorOption func(*aggregatorConfig)
+
+type aggregatorConfig struct {
+	ttl  time.Duration
+	opts []counters.TotalsOption
+}
+
+// WithCounterTTL sets the time after which counters that have not been seen
+// are evicted. It defaults to DefaultCounterTTL.
+func WithCounterTTL(ttl time.Duration) AggregatorOption {
+	return func(c *aggregatorConfig) {
+		c.ttl = ttl
+	}
+}
+
+// WithCounterClock sets the function the current time is read from when
+// evicting counters. It defaults to time.Now.
+func WithCounterClock(now func() time.Time) AggregatorOption {
+	return func(c *aggregatorConfig) {
+		c.opts = append(c.opts, counters.WithClock(now))
+	}
 }
 
-func NewAggregator(outputWriter EnvelopeWriter) *MessageAggregator {
+func NewAggregator(outputWriter EnvelopeWriter, opts ...AggregatorOption) *MessageAggregator {
+	conf := aggregatorConfig{
+		ttl: DefaultCounterTTL,
+	}
+	for _, o := range opts {
+		o(&conf)
+	}
+
 	return &MessageAggregator{
-		outputWriter:  outputWriter,
-		counterTotals: make(map[counterID]uint64),
+		outputWriter: outputWriter,
+		totals:       counters.NewTotals(conf.ttl, conf.opts...),
 	}
 }
 
@@ -39,53 +71,19 @@ func (m *MessageAggregator) handleCounter(envelope *events.Envelope) *events.Env
 	// counter events received by the message aggregator.
 	metrics.BatchIncrementCounter("MessageAggregator.counterEventReceived")
 
-	countID := counterID{
-		name:     envelope.GetCounterEvent().GetName(),
-		origin:   envelope.GetOrigin(),
-		tagsHash: hashTags(envelope.Tags),
+	name := envelope.GetCounterEvent().GetName()
+	origin := envelope.GetOrigin()
+	countID := counters.ID{
+		Name:   name,
+		Origin: origin,
+		Hash:   uint64(counters.NewHash().String(name).String(origin).Tags(envelope.Tags)),
 	}
 
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	newVal := m.counterTotals[countID] + envelope.GetCounterEvent().GetDelta()
-	m.counterTotals[countID] = newVal
-
+	newVal := m.totals.Add(countID, envelope.GetCounterEvent().GetDelta())
 	envelope.GetCounterEvent().Total = &newVal
 	return envelope
 }
 
-func hashTags(tags map[string]string) string {
-	hash := ""
-	elements := []mapElement{}
-	for k, v := range tags {
-		elements = append(elements, mapElement{k, v})
-	}
-	sort.Sort(byKey(elements))
-	for _, element := range elements {
-		kHash, vHash := sha1.New(), sha1.New()
-		io.WriteString(kHash, element.k)
-		io.WriteString(vHash, element.v)
-		hash += fmt.Sprintf("%x%x", kHash.Sum(nil), vHash.Sum(nil))
-	}
-	return hash
-}
-
-type byKey []mapElement
-
-func (a byKey) Len() int           { return len(a) }
-func (a byKey) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
-func (a byKey) Less(i, j int) bool { return a[i].k < a[j].k }
-
-type mapElement struct {
-	k, v string
-}
-
-type counterID struct {
-	origin   string
-	name     string
-	tagsHash string
-}
-
 type eventID struct {
 	requestID string
 	peerType  events.PeerType
@@ -19,7 +19,6 @@ var _ = Describe("MessageAggregator", func() {
 	var (
 		mockWriter        *MockEnvelopeWriter
 		messageAggregator *egress.MessageAggregator
-		originalTTL       time.Duration
 	)
 
 	BeforeEach(func() {
@@ -27,11 +26,6 @@ var _ = Describe("MessageAggregator", func() {
 		messageAggregator = egress.NewAggregator(
 			mockWriter,
 		)
-		originalTTL = egress.MaxTTL
-	})
-
-	AfterEach(func() {
-		egress.MaxTTL = originalTTL
 	})
 
 	It("passes value messages through", func() {
@@ -171,6 +165,44 @@ var _ = Describe("MessageAggregator", func() {
 			Expect(mockWriter.Events[2].GetOrigin()).To(Equal("fake-origin-4"))
 			expectCorrectCounterNameDeltaAndTotal(mockWriter.Events[2], "counter1", 4, 8)
 		})
+
+		It("evicts counters that have not been seen within the TTL", func() {
+			now := time.Unix(0, 0)
+			messageAggregator = egress.NewAggregator(
+				mockWriter,
+				egress.WithCounterTTL(time.Minute),
+				egress.WithCounterClock(func() time.Time { return now }),
+			)
+
+			messageAggregator.Write(createCounterMessage("stale", "fake-origin-4", nil))
+			for i := 0; i < 4; i++ {
+				now = now.Add(30 * time.Second)
+				messageAggregator.Write(createCounterMessage("active", "fake-origin-4", nil))
+			}
+			messageAggregator.Write(createCounterMessage("stale", "fake-origin-4", nil))
+
+			Expect(mockWriter.Events).To(HaveLen(6))
+			expectCorrectCounterNameDeltaAndTotal(mockWriter.Events[4], "active", 4, 16)
+			expectCorrectCounterNameDeltaAndTotal(mockWriter.Events[5], "stale", 4, 4)
+		})
+
+		It("accumulates concurrent writes", func() {
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for i := 0; i < 100; i++ {
+					messageAggregator.Write(createCounterMessage("total", "fake-origin-4", nil))
+				}
+			}()
+			for i := 0; i < 100; i++ {
+				messageAggregator.Write(createCounterMessage("total", "fake-origin-4", nil))
+			}
+			<-done
+
+			messageAggregator.Write(createCounterMessage("total", "fake-origin-4", nil))
+			Expect(mockWriter.Events).To(HaveLen(201))
+			expectCorrectCounterNameDeltaAndTotal(mockWriter.Events[200], "total", 4, 804)
+		})
 	})
 
 	Context("metrics", func() {
@@ -0,0 +1,42 @@
+package v1
+
+import (
+	"code.cloudfoundry.org/loggregator/plumbing/conversion"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+
+	"github.com/cloudfoundry/sonde-go/events"
+)
+
+// TimerAggregator folds v2 timers into histograms.
+type TimerAggregator interface {
+	Aggregates(e *v2.Envelope) bool
+	Set(e *v2.Envelope)
+}
+
+// TimerWriter hands HttpStartStop events to the v2 timer aggregation. The
+// events are converted to v2 timers named http and the ones the aggregator
+// selects are written to it instead of the output writer. All other
+// envelopes are written to the output writer untouched.
+type TimerWriter struct {
+	aggregator   TimerAggregator
+	outputWriter EnvelopeWriter
+}
+
+func NewTimerWriter(aggregator TimerAggregator, outputWriter EnvelopeWriter) *TimerWriter {
+	return &TimerWriter{
+		aggregator:   aggregator,
+		outputWriter: outputWriter,
+	}
+}
+
+func (w *TimerWriter) Write(envelope *events.Envelope) {
+	if envelope.GetEventType() == events.Envelope_HttpStartStop {
+		e := conversion.ToV2(envelope, true)
+		if w.aggregator.Aggregates(e) {
+			w.aggregator.Set(e)
+			return
+		}
+	}
+
+	w.outputWriter.Write(envelope)
+}
@@ -0,0 +1,98 @@
+package v1_test
+
+import (
+	egress "code.cloudfoundry.org/loggregator/metron/internal/egress/v1"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+
+	"github.com/cloudfoundry/sonde-go/events"
+	"github.com/gogo/protobuf/proto"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TimerWriter", func() {
+	var (
+		mockWriter *MockEnvelopeWriter
+		aggregator *spyTimerAggregator
+		w          *egress.TimerWriter
+	)
+
+	BeforeEach(func() {
+		mockWriter = &MockEnvelopeWriter{}
+		aggregator = &spyTimerAggregator{aggregates: true}
+		w = egress.NewTimerWriter(aggregator, mockWriter)
+	})
+
+	It("writes aggregated HttpStartStop events to the aggregator as v2 timers", func() {
+		w.Write(httpStartStop())
+
+		Expect(mockWriter.Events).To(BeEmpty())
+		Expect(aggregator.envelopes).To(HaveLen(1))
+
+		e := aggregator.envelopes[0]
+		Expect(e.SourceId).To(Equal("some-deployment/some-job"))
+		Expect(e.GetTimer().GetName()).To(Equal("http"))
+		Expect(e.GetTimer().GetStart()).To(Equal(int64(1)))
+		Expect(e.GetTimer().GetStop()).To(Equal(int64(5)))
+		Expect(e.GetTags()["status_code"]).To(Equal("200"))
+	})
+
+	It("writes HttpStartStop events the aggregator does not select", func() {
+		aggregator.aggregates = false
+		envelope := httpStartStop()
+
+		w.Write(envelope)
+
+		Expect(aggregator.envelopes).To(BeEmpty())
+		Expect(mockWriter.Events).To(Equal([]*events.Envelope{envelope}))
+	})
+
+	It("writes other envelopes", func() {
+		envelope := &events.Envelope{
+			Origin:    proto.String("some-origin"),
+			EventType: events.Envelope_ValueMetric.Enum(),
+			ValueMetric: &events.ValueMetric{
+				Name:  proto.String("some-metric"),
+				Value: proto.Float64(2),
+				Unit:  proto.String("ms"),
+			},
+		}
+
+		w.Write(envelope)
+
+		Expect(aggregator.envelopes).To(BeEmpty())
+		Expect(mockWriter.Events).To(Equal([]*events.Envelope{envelope}))
+	})
+})
+
+type spyTimerAggregator struct {
+	aggregates bool
+	envelopes  []*v2.Envelope
+}
+
+func (s *spyTimerAggregator) Aggregates(e *v2.Envelope) bool {
+	return s.aggregates && e.GetTimer() != nil
+}
+
+func (s *spyTimerAggregator) Set(e *v2.Envelope) {
+	s.envelopes = append(s.envelopes, e)
+}
+
+func httpStartStop() *events.Envelope {
+	return &events.Envelope{
+		Origin:     proto.String("gorouter"),
+		Deployment: proto.String("some-deployment"),
+		Job:        proto.String("some-job"),
+		EventType:  events.Envelope_HttpStartStop.Enum(),
+		HttpStartStop: &events.HttpStartStop{
+			StartTimestamp: proto.Int64(1),
+			StopTimestamp:  proto.Int64(5),
+			PeerType:       events.PeerType_Client.Enum(),
+			Method:         events.Method_GET.Enum(),
+			Uri:            proto.String("http://example.com"),
+			StatusCode:     proto.Int32(200),
+			ContentLength:  proto.Int64(10),
+		},
+	}
+}
@@ -1,80 +1,126 @@
 package v2
 
 import (
-	"crypto/sha1"
-	"fmt"
-	"io"
-	"sort"
+	"time"
 
+	"code.cloudfoundry.org/loggregator/metron/internal/egress/counters"
 	plumbing "code.cloudfoundry.org/loggregator/plumbing/v2"
 )
 
-type counterID struct {
-	name     string
-	tagsHash string
-}
+// DefaultCounterTTL is the time after which counters that have not been
+// seen are evicted and start over at zero.
+const DefaultCounterTTL = 10 * time.Minute
 
+// CounterAggregator sets the total of counter envelopes from their deltas.
+// Counters are identified by their source ID, instance ID, name and tags. It
+// is safe for concurrent use.
 type CounterAggregator struct {
-	writer        Writer
-	counterTotals map[counterID]uint64
+	writer Writer
+	totals *counters.Totals
 }
 
-func NewCounterAggregator(w Writer) *CounterAggregator {
-	return &CounterAggregator{
-		writer:        w,
-		counterTotals: make(map[counterID]uint64),
-	}
+// CounterAggregatorOption configures a CounterAggregator.
+type CounterAggregatorOption func(*counterAggregatorConfig)
+
+type counterAggregatorConfig struct {
+	ttl  time.Duration
+	opts []counters.TotalsOption
 }
 
-func (ca *CounterAggregator) Write(msgs []*plumbing.Envelope) error {
-	for i := range msgs {
-		if msgs[i].GetCounter() != nil {
-			if len(ca.counterTotals) > 10000 {
-				ca.resetTotals()
-			}
-
-			id := counterID{
-				name:     msgs[i].GetCounter().Name,
-				tagsHash: hashTags(msgs[i].GetDeprecatedTags()),
-			}
-
-			ca.counterTotals[id] = ca.counterTotals[id] + msgs[i].GetCounter().GetDelta()
-
-			msgs[i].GetCounter().Value = &plumbing.Counter_Total{
-				Total: ca.counterTotals[id],
-			}
-		}
+// WithCounterTTL sets the time after which counters that have not been seen
+// are evicted. It defaults to DefaultCounterTTL.
+func WithCounterTTL(ttl time.Duration) CounterAggregatorOption {
+	return func(c *counterAggregatorConfig) {
+		c.ttl = ttl
 	}
+}
 
-	return ca.writer.Write(msgs)
+// WithMaxCounters sets the number of counters whose totals are kept at
+// most. It defaults to counters.DefaultMaxCounters.
+func WithMaxCounters(n int) CounterAggregatorOption {
+	return func(c *counterAggregatorConfig) {
+		c.opts = append(c.opts, counters.WithMaxCounters(n))
+	}
 }
 
-func (ca *CounterAggregator) resetTotals() {
-	ca.counterTotals = make(map[counterID]uint64)
+// WithCounterClock sets the function the current time is read from when
+// evicting counters. It defaults to time.Now.
+func WithCounterClock(now func() time.Time) CounterAggregatorOption {
+	return func(c *counterAggregatorConfig) {
+		c.opts = append(c.opts, counters.WithClock(now))
+	}
 }
 
-func hashTags(tags map[string]*plumbing.Value) string {
-	hash := ""
-	elements := []mapElement{}
-	for k, v := range tags {
-		elements = append(elements, mapElement{k, v.String()})
+func NewCounterAggregator(w Writer, opts ...CounterAggregatorOption) *CounterAggregator {
+	conf := counterAggregatorConfig{
+		ttl: DefaultCounterTTL,
 	}
-	sort.Sort(byKey(elements))
-	for _, element := range elements {
-		kHash, vHash := sha1.New(), sha1.New()
-		io.WriteString(kHash, element.k)
-		io.WriteString(vHash, element.v)
-		hash += fmt.Sprintf("%x%x", kHash.Sum(nil), vHash.Sum(nil))
+	for _, o := range opts {
+		o(&conf)
+	}
+
+	return &CounterAggregator{
+		writer: w,
+		totals: counters.NewTotals(conf.ttl, conf.opts...),
 	}
-	return hash
 }
 
-type byKey []mapElement
+func (ca *CounterAggregator) Write(msgs []*plumbing.Envelope) error {
+	for _, e := range msgs {
+		c := e.GetCounter()
+		if c == nil {
+			continue
+		}
 
-func (a byKey) Len() int           { return len(a) }
-func (a byKey) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
-func (a byKey) Less(i, j int) bool { return a[i].k < a[j].k }
+		h := counters.NewHash().
+			String(e.GetSourceId()).
+			String(e.GetInstanceId()).
+			String(c.Name).
+			Tags(e.GetTags())
+		id := counters.ID{
+			Name: c.Name,
+			Hash: uint64(hashValueTags(h, e.GetDeprecatedTags())),
+		}
+
+		c.Value = &plumbing.Counter_Total{
+			Total: ca.totals.Add(id, c.GetDelta()),
+		}
+	}
+
+	return ca.writer.Write(msgs)
+}
 
-type mapElement struct {
-	k, v string
+// hashValueTags adds the tags sorted by key to the hash. The type of a
+// value is hashed along with it so that e.g. the text "1" and the integer 1
+// differ.
+func hashValueTags(h counters.Hash, tags map[string]*plumbing.Value) counters.Hash {
+	var buf [16]string
+	keys := buf[:0]
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	counters.SortKeys(keys)
+
+	for _, k := range keys {
+		h = h.String(k)
+		switch d := tags[k].GetData().(type) {
+		case *plumbing.Value_Text:
+			h = h.Uint64(valueText).String(d.Text)
+		case *plumbing.Value_Integer:
+			h = h.Uint64(valueInteger).Uint64(uint64(d.Integer))
+		case *plumbing.Value_Decimal:
+			h = h.Uint64(valueDecimal).Float64(d.Decimal)
+		default:
+			h = h.Uint64(valueNone)
+		}
+	}
+
+	return h
 }
+
+const (
+	valueNone = iota
+	valueText
+	valueInteger
+	valueDecimal
+)
@@ -2,6 +2,8 @@ package v2_test
 
 import (
 	"fmt"
+	"sync"
+	"time"
 
 	plumbing "code.cloudfoundry.org/loggregator/plumbing/v2"
 
@@ -95,6 +97,49 @@ var _ = Describe("Counteraggregator", func() {
 		Expect(receivedEnvelope[0].GetCounter().GetTotal()).To(Equal(uint64(20)))
 	})
 
+	It("calculates totals separately for counter envelopes with unique source and instance IDs", func() {
+		mockWriter := newMockWriter()
+		close(mockWriter.WriteOutput.Ret0)
+
+		aggregator := egress.NewCounterAggregator(mockWriter)
+		for _, id := range [][2]string{
+			{"source-1", "0"},
+			{"source-2", "0"},
+			{"source-1", "1"},
+			{"source-1", "0"},
+		} {
+			e := buildCounterEnvelope(10, "name-1", "origin-1")
+			e[0].SourceId = id[0]
+			e[0].InstanceId = id[1]
+			aggregator.Write(e)
+		}
+
+		var totals []uint64
+		for i := 0; i < 4; i++ {
+			var receivedEnvelope []*plumbing.Envelope
+			Expect(mockWriter.WriteInput.Msg).To(Receive(&receivedEnvelope))
+			totals = append(totals, receivedEnvelope[0].GetCounter().GetTotal())
+		}
+		Expect(totals).To(Equal([]uint64{10, 10, 10, 20}))
+	})
+
+	It("calculates totals separately for counter envelopes with unique tags", func() {
+		mockWriter := newMockWriter()
+		close(mockWriter.WriteOutput.Ret0)
+
+		aggregator := egress.NewCounterAggregator(mockWriter)
+		for _, service := range []string{"service-1", "service-2"} {
+			e := buildCounterEnvelope(10, "name-1", "origin-1")
+			e[0].Tags = map[string]string{"service": service}
+			aggregator.Write(e)
+		}
+
+		var receivedEnvelope []*plumbing.Envelope
+		Expect(mockWriter.WriteInput.Msg).To(Receive(&receivedEnvelope))
+		Expect(mockWriter.WriteInput.Msg).To(Receive(&receivedEnvelope))
+		Expect(receivedEnvelope[0].GetCounter().GetTotal()).To(Equal(uint64(10)))
+	})
+
 	It("calculations are unaffected for counter envelopes with total set", func() {
 		mockWriter := newMockWriter()
 		close(mockWriter.WriteOutput.Ret0)
@@ -113,20 +158,45 @@ var _ = Describe("Counteraggregator", func() {
 		Expect(receivedEnvelope[0].GetCounter().GetTotal()).To(Equal(uint64(10)))
 	})
 
-	It("prunes the cache of totals when there are too many unique counters", func() {
+	It("evicts counters that have not been seen within the TTL", func() {
 		mockWriter := newMockWriter()
 		close(mockWriter.WriteOutput.Ret0)
 
-		aggregator := egress.NewCounterAggregator(mockWriter)
-
-		aggregator.Write(buildCounterEnvelope(500, "unique-name", "origin-1"))
+		now := time.Unix(0, 0)
+		aggregator := egress.NewCounterAggregator(
+			mockWriter,
+			egress.WithCounterTTL(time.Minute),
+			egress.WithCounterClock(func() time.Time { return now }),
+		)
+
+		aggregator.Write(buildCounterEnvelope(500, "stale-name", "origin-1"))
+		for i := 0; i < 4; i++ {
+			now = now.Add(30 * time.Second)
+			aggregator.Write(buildCounterEnvelope(10, "active-name", "origin-1"))
+		}
+		aggregator.Write(buildCounterEnvelope(10, "stale-name", "origin-1"))
 
 		var receivedEnvelope []*plumbing.Envelope
+		for i := 0; i < 5; i++ {
+			Expect(mockWriter.WriteInput.Msg).To(Receive(&receivedEnvelope))
+		}
+		Expect(receivedEnvelope[0].GetCounter().GetTotal()).To(Equal(uint64(40)))
+
 		Expect(mockWriter.WriteInput.Msg).To(Receive(&receivedEnvelope))
-		Expect(receivedEnvelope).To(HaveLen(1))
-		Expect(receivedEnvelope[0].GetCounter().GetTotal()).To(Equal(uint64(500)))
+		Expect(receivedEnvelope[0].GetCounter().GetTotal()).To(Equal(uint64(10)))
+	})
 
-		for i := 0; i < 10000; i++ {
+	It("does not reset totals when there are many unique counters", func() {
+		mockWriter := newMockWriter()
+		close(mockWriter.WriteOutput.Ret0)
+
+		aggregator := egress.NewCounterAggregator(mockWriter)
+
+		aggregator.Write(buildCounterEnvelope(500, "unique-name", "origin-1"))
+		<-mockWriter.WriteInput.Msg
+		<-mockWriter.WriteCalled
+
+		for i := 0; i < 10001; i++ {
 			aggregator.Write(buildCounterEnvelope(10, fmt.Sprint("name-", i), "origin-1"))
 			<-mockWriter.WriteInput.Msg
 			<-mockWriter.WriteCalled
@@ -134,10 +204,59 @@ var _ = Describe("Counteraggregator", func() {
 
 		aggregator.Write(buildCounterEnvelope(10, "unique-name", "origin-1"))
 
+		var receivedEnvelope []*plumbing.Envelope
+		Expect(mockWriter.WriteInput.Msg).To(Receive(&receivedEnvelope))
+		Expect(receivedEnvelope[0].GetCounter().GetTotal()).To(Equal(uint64(510)))
+	})
+
+	It("distinguishes tags by their type", func() {
+		mockWriter := newMockWriter()
+		close(mockWriter.WriteOutput.Ret0)
+
+		aggregator := egress.NewCounterAggregator(mockWriter)
+
+		text := buildCounterEnvelope(10, "name-1", "origin-1")
+		text[0].DeprecatedTags["index"] = &plumbing.Value{Data: &plumbing.Value_Text{Text: "1"}}
+		integer := buildCounterEnvelope(10, "name-1", "origin-1")
+		integer[0].DeprecatedTags["index"] = &plumbing.Value{Data: &plumbing.Value_Integer{Integer: 1}}
+
+		aggregator.Write(text)
+		aggregator.Write(integer)
+
+		var receivedEnvelope []*plumbing.Envelope
+		Expect(mockWriter.WriteInput.Msg).To(Receive(&receivedEnvelope))
 		Expect(mockWriter.WriteInput.Msg).To(Receive(&receivedEnvelope))
-		Expect(receivedEnvelope).To(HaveLen(1))
 		Expect(receivedEnvelope[0].GetCounter().GetTotal()).To(Equal(uint64(10)))
 	})
+
+	It("is safe for concurrent use", func() {
+		mockWriter := newMockWriter()
+		close(mockWriter.WriteOutput.Ret0)
+
+		aggregator := egress.NewCounterAggregator(mockWriter)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 25; j++ {
+					aggregator.Write(buildCounterEnvelope(1, "name-1", "origin-1"))
+				}
+			}()
+		}
+		wg.Wait()
+
+		var max uint64
+		for i := 0; i < 100; i++ {
+			var receivedEnvelope []*plumbing.Envelope
+			Expect(mockWriter.WriteInput.Msg).To(Receive(&receivedEnvelope))
+			if t := receivedEnvelope[0].GetCounter().GetTotal(); t > max {
+				max = t
+			}
+		}
+		Expect(max).To(Equal(uint64(100)))
+	})
 })
 
 func buildCounterEnvelope(delta uint64, name, origin string) []*plumbing.Envelope {
@@ -18,6 +18,7 @@ type Writer interface {
 // MetricClient creates new CounterMetrics to be emitted periodically.
 type MetricClient interface {
 	NewCounter(name string, opts ...metricemitter.MetricOption) *metricemitter.Counter
+	NewTimer(name string, opts ...metricemitter.MetricOption) *metricemitter.Timer
 }
 
 type Transponder struct {
@@ -28,6 +29,7 @@ type Transponder struct {
 	batchInterval time.Duration
 	droppedMetric *metricemitter.Counter
 	egressMetric  *metricemitter.Counter
+	latencyMetric *metricemitter.Timer
 }
 
 func NewTransponder(
@@ -47,6 +49,10 @@ func NewTransponder(
 		metricemitter.WithVersion(2, 0),
 	)
 
+	latencyMetric := metricClient.NewTimer("egress_batch_latency",
+		metricemitter.WithVersion(2, 0),
+	)
+
 	return &Transponder{
 		nexter:        n,
 		writer:        w,
@@ -55,6 +61,7 @@ func NewTransponder(
 		batchInterval: batchInterval,
 		droppedMetric: droppedMetric,
 		egressMetric:  egressMetric,
+		latencyMetric: latencyMetric,
 	}
 }
 
@@ -85,6 +92,11 @@ func (t *Transponder) Start() {
 }
 
 func (t *Transponder) write(batch []*plumbing.Envelope) {
+	// metric-documentation-v2: (loggregator.metron.egress_batch_latency)
+	// Count, sum and quantiles of the time in ms to write a batch to
+	// Doppler's v2 API
+	defer t.latencyMetric.ObserveSince(time.Now())
+
 	if err := t.writer.Write(batch); err != nil {
 		// metric-documentation-v2: (loggregator.metron.dropped) Number of messages
 		// dropped when failing to write to Dopplers v2 API
@@ -0,0 +1,12 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package filetail
+
+import "os"
+
+// identify returns the zero fileID. Replaced files are still detected
+// while tailing but not across restarts.
+func identify(info os.FileInfo) fileID {
+	return fileID{}
+}
@@ -0,0 +1,21 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package filetail
+
+import (
+	"os"
+	"syscall"
+)
+
+func identify(info os.FileInfo) fileID {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileID{}
+	}
+
+	return fileID{
+		Device: uint64(stat.Dev),
+		Inode:  uint64(stat.Ino),
+	}
+}
@@ -0,0 +1,84 @@
+package filetail
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// fileID identifies a file independent of its path. It is used to detect
+// whether the file at a path was replaced while Metron was not running.
+type fileID struct {
+	Device uint64 `json:"device"`
+	Inode  uint64 `json:"inode"`
+}
+
+type offset struct {
+	ID     fileID `json:"id"`
+	Offset int64  `json:"offset"`
+}
+
+// loadOffsets reads the offsets persisted at path. A missing file has no
+// offsets.
+func loadOffsets(path string) (map[string]offset, error) {
+	offsets := make(map[string]offset)
+	if path == "" {
+		return offsets, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return offsets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &offsets); err != nil {
+		return nil, err
+	}
+
+	return offsets, nil
+}
+
+// storeOffsets writes the offsets to a temporary file and renames it to
+// path so a crash never leaves a partially written file behind.
+func storeOffsets(path string, offsets map[string]offset) error {
+	data, err := json.Marshal(offsets)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path))
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func equalOffsets(a, b map[string]offset) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for path, o := range a {
+		if other, ok := b[path]; !ok || other != o {
+			return false
+		}
+	}
+
+	return true
+}
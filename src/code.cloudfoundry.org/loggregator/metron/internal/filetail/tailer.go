@@ -0,0 +1,396 @@
+package filetail
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/metricemitter"
+
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+)
+
+type DataSetter interface {
+	Set(e *v2.Envelope)
+}
+
+// MetricClient creates the counter of lines read from tailed files.
+type MetricClient interface {
+	NewCounter(name string, opts ...metricemitter.MetricOption) *metricemitter.Counter
+}
+
+// Source is a set of files that are tailed with the same source ID and
+// tags.
+type Source struct {
+	Globs    []string
+	SourceID string
+	Tags     map[string]string
+}
+
+// Config determines which files are tailed.
+type Config struct {
+	Sources []Source
+
+	// OffsetsFile is where the read offsets are persisted so tailing
+	// resumes where it stopped after a restart. Offsets are not persisted
+	// when it is empty.
+	OffsetsFile string
+
+	PollInterval time.Duration
+
+	// MaxLineBytes limits the length of a line. Longer lines are split.
+	MaxLineBytes int
+}
+
+type tailedFile struct {
+	path    string
+	source  *Source
+	file    *os.File
+	id      fileID
+	offset  int64
+	partial []byte
+}
+
+// Tailer is a polling tailer of the files matching the globs of its
+// sources. Each line is written to a DataSetter as a log envelope with the
+// source ID and tags of the source and the path of the file as the file
+// tag.
+//
+// Rotation by renaming is detected by the identity of the file at a path
+// and the remainder of the rotated file is read before the new file.
+// Truncation, e.g. by logrotate's copytruncate, is detected by the size
+// of the file dropping below the read offset, in which case the file is
+// read from the start.
+//
+// Files that are found on the first poll and have no persisted offset are
+// read from their end to avoid replaying existing logs. Files that appear
+// later are read from their start.
+type Tailer struct {
+	setter       DataSetter
+	sources      []Source
+	offsetsFile  string
+	pollInterval time.Duration
+	maxLineBytes int
+
+	files      map[string]*tailedFile
+	offsets    map[string]offset
+	unsaved    bool
+	unreadable map[string]bool
+	polled     bool
+
+	linesMetric *metricemitter.Counter
+}
+
+func New(setter DataSetter, c Config, metricClient MetricClient) (*Tailer, error) {
+	if len(c.Sources) == 0 {
+		return nil, fmt.Errorf("at least one source must be configured")
+	}
+
+	for _, s := range c.Sources {
+		if len(s.Globs) == 0 || s.SourceID == "" {
+			return nil, fmt.Errorf("every source requires globs and a source ID")
+		}
+
+		for _, g := range s.Globs {
+			if _, err := filepath.Match(g, ""); err != nil {
+				return nil, fmt.Errorf("invalid glob %q: %s", g, err)
+			}
+		}
+	}
+
+	if c.PollInterval <= 0 || c.MaxLineBytes < 1 {
+		return nil, fmt.Errorf("poll interval and max line bytes must be positive")
+	}
+
+	offsets, err := loadOffsets(c.OffsetsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	linesMetric := metricClient.NewCounter("tailed_lines",
+		metricemitter.WithVersion(2, 0),
+	)
+
+	return &Tailer{
+		setter:       setter,
+		sources:      c.Sources,
+		offsetsFile:  c.OffsetsFile,
+		pollInterval: c.PollInterval,
+		maxLineBytes: c.MaxLineBytes,
+		files:        make(map[string]*tailedFile),
+		offsets:      offsets,
+		unreadable:   make(map[string]bool),
+		linesMetric:  linesMetric,
+	}, nil
+}
+
+// Start polls the files every poll interval. It blocks forever.
+func (t *Tailer) Start() {
+	ticker := time.NewTicker(t.pollInterval)
+	defer ticker.Stop()
+
+	t.Poll()
+	for range ticker.C {
+		t.Poll()
+	}
+}
+
+// Poll reads the lines written since the last poll, picks up new, rotated
+// and truncated files and persists the offsets.
+func (t *Tailer) Poll() {
+	matched := t.match()
+
+	for path := range t.unreadable {
+		if _, ok := matched[path]; !ok {
+			delete(t.unreadable, path)
+		}
+	}
+
+	for path, tf := range t.files {
+		if _, ok := matched[path]; ok {
+			continue
+		}
+
+		// The file was removed or renamed without being replaced.
+		t.read(tf)
+		t.flushPartial(tf)
+		tf.file.Close()
+		delete(t.files, path)
+	}
+
+	for path, source := range matched {
+		tf, ok := t.files[path]
+		if !ok {
+			tf = t.open(path, source)
+			if tf == nil {
+				continue
+			}
+			t.files[path] = tf
+		}
+
+		t.follow(tf)
+	}
+
+	t.polled = true
+	t.saveOffsets()
+}
+
+// match returns the paths matching the globs of the sources with the
+// first source they match.
+func (t *Tailer) match() map[string]*Source {
+	matched := make(map[string]*Source)
+	for i := range t.sources {
+		s := &t.sources[i]
+		for _, g := range s.Globs {
+			paths, err := filepath.Glob(g)
+			if err != nil {
+				continue
+			}
+
+			for _, p := range paths {
+				if _, ok := matched[p]; !ok {
+					matched[p] = s
+				}
+			}
+		}
+	}
+
+	return matched
+}
+
+// open opens the file at path and seeks to where reading starts. A file
+// that cannot be opened is logged once until it is opened or no longer
+// matches.
+func (t *Tailer) open(path string, source *Source) *tailedFile {
+	file, err := os.Open(path)
+	if err != nil {
+		if !t.unreadable[path] {
+			log.Printf("Failed to open tailed file %s: %s", path, err)
+			t.unreadable[path] = true
+		}
+		return nil
+	}
+	delete(t.unreadable, path)
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		file.Close()
+		return nil
+	}
+
+	tf := &tailedFile{
+		path:   path,
+		source: source,
+		file:   file,
+		id:     identify(info),
+	}
+
+	if o, ok := t.offsets[path]; ok && o.ID == tf.id && o.Offset <= info.Size() {
+		tf.offset = o.Offset
+	} else if !ok && !t.polled {
+		tf.offset = info.Size()
+	}
+
+	if _, err := file.Seek(tf.offset, io.SeekStart); err != nil {
+		log.Printf("Failed to seek in tailed file %s: %s", path, err)
+		file.Close()
+		return nil
+	}
+
+	return tf
+}
+
+// follow reads the new lines of the file. If the file at the path was
+// replaced, the remainder of the old file is read before the new one.
+func (t *Tailer) follow(tf *tailedFile) {
+	info, err := os.Stat(tf.path)
+	if err != nil {
+		return
+	}
+
+	current, err := tf.file.Stat()
+	if err == nil && !os.SameFile(info, current) {
+		t.read(tf)
+		t.flushPartial(tf)
+		tf.file.Close()
+
+		file, err := os.Open(tf.path)
+		if err != nil {
+			log.Printf("Failed to open rotated file %s: %s", tf.path, err)
+			delete(t.files, tf.path)
+			return
+		}
+
+		tf.file = file
+		tf.id = identify(info)
+		tf.offset = 0
+	}
+
+	if info.Size() < tf.offset {
+		tf.partial = nil
+		tf.offset = 0
+		if _, err := tf.file.Seek(0, io.SeekStart); err != nil {
+			log.Printf("Failed to seek in truncated file %s: %s", tf.path, err)
+			return
+		}
+	}
+
+	t.read(tf)
+}
+
+// read writes the complete lines up to the end of the file. An incomplete
+// last line is kept until it is completed.
+func (t *Tailer) read(tf *tailedFile) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := tf.file.Read(buf)
+		if n > 0 {
+			tf.offset += int64(n)
+			t.split(tf, buf[:n])
+		}
+
+		if err != nil {
+			if err != io.EOF {
+				log.Printf("Failed to read tailed file %s: %s", tf.path, err)
+			}
+			return
+		}
+	}
+}
+
+func (t *Tailer) split(tf *tailedFile, data []byte) {
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			tf.partial = append(tf.partial, data...)
+			for len(tf.partial) >= t.maxLineBytes {
+				t.write(tf, tf.partial[:t.maxLineBytes])
+				tf.partial = tf.partial[t.maxLineBytes:]
+			}
+			return
+		}
+
+		line := data[:i]
+		if len(tf.partial) > 0 {
+			line = append(tf.partial, line...)
+			tf.partial = nil
+		}
+		data = data[i+1:]
+
+		line = bytes.TrimSuffix(line, []byte("\r"))
+		for len(line) > t.maxLineBytes {
+			t.write(tf, line[:t.maxLineBytes])
+			line = line[t.maxLineBytes:]
+		}
+		t.write(tf, line)
+	}
+}
+
+func (t *Tailer) flushPartial(tf *tailedFile) {
+	if len(tf.partial) == 0 {
+		return
+	}
+
+	t.write(tf, tf.partial)
+	tf.partial = nil
+}
+
+func (t *Tailer) write(tf *tailedFile, line []byte) {
+	payload := make([]byte, len(line))
+	copy(payload, line)
+
+	tags := make(map[string]string, len(tf.source.Tags)+1)
+	for k, v := range tf.source.Tags {
+		tags[k] = v
+	}
+	tags["file"] = tf.path
+
+	t.setter.Set(&v2.Envelope{
+		SourceId:  tf.source.SourceID,
+		Timestamp: time.Now().UnixNano(),
+		Tags:      tags,
+		Message: &v2.Envelope_Log{
+			Log: &v2.Log{
+				Payload: payload,
+				Type:    v2.Log_OUT,
+			},
+		},
+	})
+
+	// metric-documentation-v2: (loggregator.metron.tailed_lines) Number of
+	// lines read from tailed files.
+	t.linesMetric.Increment(1)
+}
+
+// saveOffsets persists the offsets of the tailed files if they changed. The
+// offset of an incomplete last line is not persisted so the line is read
+// again after a restart.
+func (t *Tailer) saveOffsets() {
+	offsets := make(map[string]offset, len(t.files))
+	for path, tf := range t.files {
+		offsets[path] = offset{
+			ID:     tf.id,
+			Offset: tf.offset - int64(len(tf.partial)),
+		}
+	}
+
+	if !t.unsaved && equalOffsets(offsets, t.offsets) {
+		return
+	}
+	t.offsets = offsets
+
+	if t.offsetsFile == "" {
+		return
+	}
+
+	if err := storeOffsets(t.offsetsFile, offsets); err != nil {
+		log.Printf("Failed to persist tailed file offsets: %s", err)
+		t.unsaved = true
+		return
+	}
+	t.unsaved = false
+}
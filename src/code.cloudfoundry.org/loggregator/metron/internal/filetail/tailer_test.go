@@ -0,0 +1,308 @@
+package filetail_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/metricemitter/testhelper"
+	"code.cloudfoundry.org/loggregator/metron/internal/filetail"
+
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tailer", func() {
+	var (
+		spySetter    *SpySetter
+		metricClient *testhelper.SpyMetricClient
+		dir          string
+		config       filetail.Config
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "filetail")
+		Expect(err).ToNot(HaveOccurred())
+
+		spySetter = NewSpySetter()
+		metricClient = testhelper.NewMetricClient()
+		config = filetail.Config{
+			Sources: []filetail.Source{{
+				Globs:    []string{filepath.Join(dir, "*.log")},
+				SourceID: "some-job",
+				Tags:     map[string]string{"job": "some-job"},
+			}},
+			OffsetsFile:  filepath.Join(dir, "offsets.json"),
+			PollInterval: time.Minute,
+			MaxLineBytes: 1024,
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("writes lines appended to a file as log envelopes", func() {
+		path := filepath.Join(dir, "job.log")
+		appendFile(path, "before start\n")
+
+		t, err := filetail.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+		t.Poll()
+		Expect(spySetter.envelopes).ToNot(Receive())
+
+		appendFile(path, "first\r\nsecond\n")
+		t.Poll()
+
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(e.SourceId).To(Equal("some-job"))
+		Expect(e.Tags).To(Equal(map[string]string{
+			"job":  "some-job",
+			"file": path,
+		}))
+		Expect(e.GetLog().Type).To(Equal(v2.Log_OUT))
+		Expect(string(e.GetLog().Payload)).To(Equal("first"))
+
+		Expect(payloads(spySetter)).To(Equal([]string{"second"}))
+		Expect(metricClient.GetDelta("tailed_lines")).To(Equal(uint64(2)))
+	})
+
+	It("reads files created after the first poll from the start", func() {
+		t, err := filetail.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+		t.Poll()
+
+		appendFile(filepath.Join(dir, "new.log"), "one\ntwo\n")
+		appendFile(filepath.Join(dir, "ignored.txt"), "three\n")
+		t.Poll()
+
+		Expect(payloads(spySetter)).To(Equal([]string{"one", "two"}))
+	})
+
+	It("holds incomplete lines until they are completed", func() {
+		path := filepath.Join(dir, "job.log")
+		t, err := filetail.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+		t.Poll()
+
+		appendFile(path, "hello ")
+		t.Poll()
+		Expect(spySetter.envelopes).ToNot(Receive())
+
+		appendFile(path, "world\n")
+		t.Poll()
+		Expect(payloads(spySetter)).To(Equal([]string{"hello world"}))
+	})
+
+	It("splits lines longer than the max line bytes", func() {
+		config.MaxLineBytes = 4
+		path := filepath.Join(dir, "job.log")
+		t, err := filetail.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+		t.Poll()
+
+		appendFile(path, "abcdefghij\n")
+		t.Poll()
+		Expect(payloads(spySetter)).To(Equal([]string{"abcd", "efgh", "ij"}))
+	})
+
+	It("reads the remainder of a rotated file before the new file", func() {
+		path := filepath.Join(dir, "job.log")
+		t, err := filetail.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+		t.Poll()
+
+		appendFile(path, "old\n")
+		t.Poll()
+		appendFile(path, "old remainder\n")
+		Expect(os.Rename(path, filepath.Join(dir, "job.log.1"))).To(Succeed())
+		appendFile(path, "new\n")
+		t.Poll()
+
+		Expect(payloads(spySetter)).To(Equal([]string{"old", "old remainder", "new"}))
+	})
+
+	It("reads a truncated file from the start", func() {
+		path := filepath.Join(dir, "job.log")
+		t, err := filetail.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+		t.Poll()
+
+		appendFile(path, "a long line before truncation\n")
+		t.Poll()
+		Expect(os.Truncate(path, 0)).To(Succeed())
+		appendFile(path, "short\n")
+		t.Poll()
+
+		Expect(payloads(spySetter)).To(Equal([]string{"a long line before truncation", "short"}))
+	})
+
+	It("stops tailing removed files", func() {
+		path := filepath.Join(dir, "job.log")
+		t, err := filetail.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+		t.Poll()
+
+		appendFile(path, "first\n")
+		t.Poll()
+
+		appendFile(path, "last words")
+		Expect(os.Remove(path)).To(Succeed())
+		t.Poll()
+		t.Poll()
+
+		Expect(payloads(spySetter)).To(Equal([]string{"first", "last words"}))
+	})
+
+	Context("with persisted offsets", func() {
+		var path string
+
+		BeforeEach(func() {
+			path = filepath.Join(dir, "job.log")
+
+			t, err := filetail.New(spySetter, config, metricClient)
+			Expect(err).ToNot(HaveOccurred())
+			t.Poll()
+
+			appendFile(path, "first\nincomplete")
+			t.Poll()
+			Expect(payloads(spySetter)).To(Equal([]string{"first"}))
+		})
+
+		It("resumes where the previous tailer stopped", func() {
+			appendFile(path, " line\nwritten while stopped\n")
+
+			t, err := filetail.New(spySetter, config, metricClient)
+			Expect(err).ToNot(HaveOccurred())
+			t.Poll()
+
+			Expect(payloads(spySetter)).To(Equal([]string{
+				"incomplete line",
+				"written while stopped",
+			}))
+		})
+
+		It("reads a file that was replaced while stopped from the start", func() {
+			Expect(os.Remove(path)).To(Succeed())
+			appendFile(path, "replacement\n")
+
+			t, err := filetail.New(spySetter, config, metricClient)
+			Expect(err).ToNot(HaveOccurred())
+			t.Poll()
+
+			Expect(payloads(spySetter)).To(Equal([]string{"replacement"}))
+		})
+	})
+
+	It("writes the offsets file only when the offsets change", func() {
+		path := filepath.Join(dir, "job.log")
+		appendFile(path, "before start\n")
+
+		t, err := filetail.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+		t.Poll()
+		Expect(config.OffsetsFile).To(BeAnExistingFile())
+
+		Expect(os.Remove(config.OffsetsFile)).To(Succeed())
+		t.Poll()
+		Expect(config.OffsetsFile).ToNot(BeAnExistingFile())
+
+		appendFile(path, "line\n")
+		t.Poll()
+		Expect(config.OffsetsFile).To(BeAnExistingFile())
+	})
+
+	It("logs a file that cannot be opened once", func() {
+		var logs bytes.Buffer
+		log.SetOutput(io.MultiWriter(&logs, GinkgoWriter))
+		defer log.SetOutput(GinkgoWriter)
+
+		path := filepath.Join(dir, "dangling.log")
+		Expect(os.Symlink(filepath.Join(dir, "missing"), path)).To(Succeed())
+
+		t, err := filetail.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+		t.Poll()
+		t.Poll()
+		Expect(strings.Count(logs.String(), "Failed to open tailed file "+path)).To(Equal(1))
+
+		Expect(os.Remove(path)).To(Succeed())
+		t.Poll()
+		Expect(os.Symlink(filepath.Join(dir, "missing"), path)).To(Succeed())
+		t.Poll()
+		Expect(strings.Count(logs.String(), "Failed to open tailed file "+path)).To(Equal(2))
+	})
+
+	It("rejects invalid configuration", func() {
+		c := config
+		c.Sources = nil
+		_, err := filetail.New(spySetter, c, metricClient)
+		Expect(err).To(HaveOccurred())
+
+		c = config
+		c.Sources = []filetail.Source{{Globs: []string{"["}, SourceID: "some-job"}}
+		_, err = filetail.New(spySetter, c, metricClient)
+		Expect(err).To(HaveOccurred())
+
+		c = config
+		c.Sources = []filetail.Source{{Globs: []string{"*.log"}}}
+		_, err = filetail.New(spySetter, c, metricClient)
+		Expect(err).To(HaveOccurred())
+
+		c = config
+		c.MaxLineBytes = 0
+		_, err = filetail.New(spySetter, c, metricClient)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rejects a corrupt offsets file", func() {
+		Expect(ioutil.WriteFile(config.OffsetsFile, []byte("{"), 0644)).To(Succeed())
+
+		_, err := filetail.New(spySetter, config, metricClient)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+func appendFile(path, content string) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	Expect(err).ToNot(HaveOccurred())
+	defer f.Close()
+
+	_, err = f.WriteString(content)
+	Expect(err).ToNot(HaveOccurred())
+}
+
+func payloads(s *SpySetter) []string {
+	var lines []string
+	for {
+		select {
+		case e := <-s.envelopes:
+			lines = append(lines, string(e.GetLog().Payload))
+		default:
+			return lines
+		}
+	}
+}
+
+type SpySetter struct {
+	envelopes chan *v2.Envelope
+}
+
+func NewSpySetter() *SpySetter {
+	return &SpySetter{
+		envelopes: make(chan *v2.Envelope, 100),
+	}
+}
+
+func (s *SpySetter) Set(e *v2.Envelope) {
+	s.envelopes <- e
+}
@@ -0,0 +1,393 @@
+package hostmetrics
+
+import (
+	"log"
+	"path"
+	"sync"
+	"time"
+
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+)
+
+// SourceID is the source ID of the envelopes of the host metrics.
+const SourceID = "system"
+
+// DefaultStatfsTimeout is how long the usage of a mount is waited for
+// before it is skipped.
+const DefaultStatfsTimeout = 5 * time.Second
+
+// DefaultExcludedInterfaces are the patterns of the network interfaces that
+// are not collected by default. They match the virtual interfaces of
+// containers, of which there may be many on a Diego cell.
+var DefaultExcludedInterfaces = []string{"veth*", "cali*"}
+
+type DataSetter interface {
+	Set(e *v2.Envelope)
+}
+
+type diskUsage struct {
+	total      uint64
+	free       uint64
+	available  uint64
+	inodes     uint64
+	freeInodes uint64
+}
+
+// sample holds the cumulative counters of the previous collection to
+// compute utilisation and rates.
+type sample struct {
+	time    time.Time
+	cpu     cpuTimes
+	disks   map[string]diskIO
+	network map[string]networkIO
+}
+
+// Collector reads CPU, load, memory, disk and network statistics of the
+// host from /proc and /sys and writes them as gauge envelopes to a
+// DataSetter. CPU utilisation and disk and network rates are computed
+// between collections and are therefore first written by the second
+// collection. Statistics that cannot be read are logged and skipped.
+type Collector struct {
+	setter            DataSetter
+	interval          time.Duration
+	procPath          string
+	sysPath           string
+	includeInterfaces []string
+	excludeInterfaces []string
+	statfsTimeout     time.Duration
+
+	prev *sample
+
+	mu sync.Mutex
+	// hung are the mounts whose statfs has not returned yet. They are
+	// skipped so that a hung file system, e.g. an unreachable NFS server,
+	// does not pile up goroutines.
+	hung map[string]bool
+}
+
+// CollectorOption configures a Collector.
+type CollectorOption func(*Collector)
+
+// WithPaths reads the statistics from the given proc and sys file systems
+// instead of /proc and /sys.
+func WithPaths(procPath, sysPath string) CollectorOption {
+	return func(c *Collector) {
+		c.procPath = procPath
+		c.sysPath = sysPath
+	}
+}
+
+// WithInterfaces selects the network interfaces that are collected by
+// patterns as understood by path.Match. If include is not empty, only the
+// interfaces matching one of its patterns are collected. Interfaces
+// matching one of the exclude patterns are never collected. The exclude
+// patterns default to DefaultExcludedInterfaces.
+func WithInterfaces(include, exclude []string) CollectorOption {
+	return func(c *Collector) {
+		c.includeInterfaces = include
+		c.excludeInterfaces = exclude
+	}
+}
+
+// WithStatfsTimeout sets how long the usage of a mount is waited for before
+// it is skipped. It defaults to DefaultStatfsTimeout.
+func WithStatfsTimeout(d time.Duration) CollectorOption {
+	return func(c *Collector) {
+		c.statfsTimeout = d
+	}
+}
+
+func New(setter DataSetter, interval time.Duration, opts ...CollectorOption) *Collector {
+	c := &Collector{
+		setter:            setter,
+		interval:          interval,
+		procPath:          "/proc",
+		sysPath:           "/sys",
+		excludeInterfaces: DefaultExcludedInterfaces,
+		statfsTimeout:     DefaultStatfsTimeout,
+		hung:              make(map[string]bool),
+	}
+
+	for _, o := range opts {
+		o(c)
+	}
+
+	return c
+}
+
+// Start collects the statistics every interval. It blocks forever.
+func (c *Collector) Start() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	c.Collect()
+	for range ticker.C {
+		c.Collect()
+	}
+}
+
+// Collect reads the statistics and writes them to the DataSetter.
+func (c *Collector) Collect() {
+	now := time.Now()
+	next := &sample{time: now}
+
+	metrics := make(map[string]*v2.GaugeValue)
+	c.collectCPU(next, metrics)
+	c.collectLoad(metrics)
+	c.collectMemory(metrics)
+	c.write(now, nil, metrics)
+
+	c.collectDisks(now, next)
+	c.collectNetwork(now, next)
+
+	c.prev = next
+}
+
+func (c *Collector) collectCPU(next *sample, metrics map[string]*v2.GaugeValue) {
+	cpu, err := readCPU(c.procPath)
+	if err != nil {
+		log.Printf("Failed to read CPU statistics: %s", err)
+		return
+	}
+	next.cpu = cpu
+
+	if c.prev == nil || cpu.total <= c.prev.cpu.total {
+		return
+	}
+
+	total := float64(cpu.total - c.prev.cpu.total)
+	percent := func(cur, prev uint64) *v2.GaugeValue {
+		if cur < prev {
+			return gauge("percent", 0)
+		}
+		return gauge("percent", float64(cur-prev)*100/total)
+	}
+
+	metrics["system.cpu.user"] = percent(cpu.user, c.prev.cpu.user)
+	metrics["system.cpu.sys"] = percent(cpu.system, c.prev.cpu.system)
+	metrics["system.cpu.idle"] = percent(cpu.idle, c.prev.cpu.idle)
+	metrics["system.cpu.wait"] = percent(cpu.wait, c.prev.cpu.wait)
+	metrics["system.cpu.steal"] = percent(cpu.steal, c.prev.cpu.steal)
+}
+
+func (c *Collector) collectLoad(metrics map[string]*v2.GaugeValue) {
+	load, err := readLoadAverage(c.procPath)
+	if err != nil {
+		log.Printf("Failed to read load average: %s", err)
+		return
+	}
+
+	metrics["system.load.1m"] = gauge("load", load.one)
+	metrics["system.load.5m"] = gauge("load", load.five)
+	metrics["system.load.15m"] = gauge("load", load.fifteen)
+}
+
+func (c *Collector) collectMemory(metrics map[string]*v2.GaugeValue) {
+	mem, err := readMemory(c.procPath)
+	if err != nil {
+		log.Printf("Failed to read memory statistics: %s", err)
+		return
+	}
+
+	used := mem.total - mem.available
+	metrics["system.mem.total"] = gauge("bytes", float64(mem.total))
+	metrics["system.mem.available"] = gauge("bytes", float64(mem.available))
+	metrics["system.mem.used"] = gauge("bytes", float64(used))
+	metrics["system.mem.percent"] = gauge("percent", ratio(used, mem.total))
+
+	swapUsed := mem.swapTotal - mem.swapFree
+	metrics["system.swap.total"] = gauge("bytes", float64(mem.swapTotal))
+	metrics["system.swap.used"] = gauge("bytes", float64(swapUsed))
+	metrics["system.swap.percent"] = gauge("percent", ratio(swapUsed, mem.swapTotal))
+}
+
+// collectDisks writes an envelope per mount tagged with the mount path and
+// device.
+func (c *Collector) collectDisks(now time.Time, next *sample) {
+	mounts, err := readMounts(c.procPath)
+	if err != nil {
+		log.Printf("Failed to read mounts: %s", err)
+		return
+	}
+
+	io, err := readDiskIO(c.procPath)
+	if err != nil {
+		log.Printf("Failed to read disk statistics: %s", err)
+	}
+	next.disks = io
+
+	for _, m := range mounts {
+		metrics := make(map[string]*v2.GaugeValue)
+
+		usage, ok := c.statfs(m.path)
+		if ok && usage.total > 0 {
+			used := usage.total - usage.free
+			metrics["system.disk.total"] = gauge("bytes", float64(usage.total))
+			metrics["system.disk.used"] = gauge("bytes", float64(used))
+			metrics["system.disk.available"] = gauge("bytes", float64(usage.available))
+			// Like df, the percentage excludes the blocks reserved for
+			// root.
+			metrics["system.disk.percent"] = gauge("percent", ratio(used, used+usage.available))
+			metrics["system.disk.inode_percent"] = gauge("percent", ratio(usage.inodes-usage.freeInodes, usage.inodes))
+		}
+
+		name := deviceName(m.device)
+		cur, ok := io[name]
+		if ok && c.prev != nil {
+			if prev, ok := c.prev.disks[name]; ok {
+				seconds := now.Sub(c.prev.time).Seconds()
+				metrics["system.disk.read_ops"] = rate("ops/s", cur.readOps, prev.readOps, seconds)
+				metrics["system.disk.write_ops"] = rate("ops/s", cur.writeOps, prev.writeOps, seconds)
+				metrics["system.disk.read_bytes"] = rate("bytes/s", cur.readBytes, prev.readBytes, seconds)
+				metrics["system.disk.write_bytes"] = rate("bytes/s", cur.writeBytes, prev.writeBytes, seconds)
+			}
+		}
+
+		c.write(now, map[string]string{
+			"mount":  m.path,
+			"device": name,
+		}, metrics)
+	}
+}
+
+// statfs returns the usage of the mount. It gives up after the statfs
+// timeout and skips the mount until the pending call returns.
+func (c *Collector) statfs(mount string) (diskUsage, bool) {
+	c.mu.Lock()
+	hung := c.hung[mount]
+	c.mu.Unlock()
+	if hung {
+		return diskUsage{}, false
+	}
+
+	type result struct {
+		usage diskUsage
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		usage, err := statfs(mount)
+		done <- result{usage: usage, err: err}
+	}()
+
+	timer := time.NewTimer(c.statfsTimeout)
+	defer timer.Stop()
+
+	select {
+	case r := <-done:
+		return r.usage, r.err == nil
+	case <-timer.C:
+		log.Printf("Timed out reading disk usage of %s", mount)
+
+		c.mu.Lock()
+		c.hung[mount] = true
+		c.mu.Unlock()
+
+		go func() {
+			<-done
+			c.mu.Lock()
+			delete(c.hung, mount)
+			c.mu.Unlock()
+		}()
+
+		return diskUsage{}, false
+	}
+}
+
+// collectNetwork writes an envelope per network interface tagged with the
+// interface name.
+func (c *Collector) collectNetwork(now time.Time, next *sample) {
+	io, err := readNetworkIO(c.sysPath)
+	if err != nil {
+		log.Printf("Failed to read network statistics: %s", err)
+		return
+	}
+	next.network = io
+
+	if c.prev == nil {
+		return
+	}
+
+	seconds := now.Sub(c.prev.time).Seconds()
+	for name, cur := range io {
+		if !c.collectsInterface(name) {
+			continue
+		}
+
+		prev, ok := c.prev.network[name]
+		if !ok {
+			continue
+		}
+
+		c.write(now, map[string]string{"interface": name}, map[string]*v2.GaugeValue{
+			"system.network.rx_bytes":   rate("bytes/s", cur.rxBytes, prev.rxBytes, seconds),
+			"system.network.tx_bytes":   rate("bytes/s", cur.txBytes, prev.txBytes, seconds),
+			"system.network.rx_errors":  rate("errors/s", cur.rxErrors, prev.rxErrors, seconds),
+			"system.network.tx_errors":  rate("errors/s", cur.txErrors, prev.txErrors, seconds),
+			"system.network.rx_dropped": rate("packets/s", cur.rxDropped, prev.rxDropped, seconds),
+			"system.network.tx_dropped": rate("packets/s", cur.txDropped, prev.txDropped, seconds),
+		})
+	}
+}
+
+func (c *Collector) collectsInterface(name string) bool {
+	for _, p := range c.excludeInterfaces {
+		if ok, _ := path.Match(p, name); ok {
+			return false
+		}
+	}
+
+	if len(c.includeInterfaces) == 0 {
+		return true
+	}
+
+	for _, p := range c.includeInterfaces {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *Collector) write(now time.Time, tags map[string]string, metrics map[string]*v2.GaugeValue) {
+	if len(metrics) == 0 {
+		return
+	}
+
+	c.setter.Set(&v2.Envelope{
+		SourceId:  SourceID,
+		Timestamp: now.UnixNano(),
+		Tags:      tags,
+		Message: &v2.Envelope_Gauge{
+			Gauge: &v2.Gauge{
+				Metrics: metrics,
+			},
+		},
+	})
+}
+
+func gauge(unit string, value float64) *v2.GaugeValue {
+	return &v2.GaugeValue{
+		Unit:  unit,
+		Value: value,
+	}
+}
+
+func ratio(part, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(part) * 100 / float64(total)
+}
+
+// rate returns the per second rate of a cumulative counter. Counters that
+// were reset, e.g. by a driver reload, have a rate of zero.
+func rate(unit string, cur, prev uint64, seconds float64) *v2.GaugeValue {
+	if cur < prev || seconds <= 0 {
+		return gauge(unit, 0)
+	}
+
+	return gauge(unit, float64(cur-prev)/seconds)
+}
@@ -0,0 +1,272 @@
+package hostmetrics_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/metron/internal/hostmetrics"
+
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Collector", func() {
+	var (
+		spySetter *SpySetter
+		root      string
+		procPath  string
+		sysPath   string
+		collector *hostmetrics.Collector
+	)
+
+	BeforeEach(func() {
+		var err error
+		root, err = ioutil.TempDir("", "hostmetrics")
+		Expect(err).ToNot(HaveOccurred())
+
+		procPath = filepath.Join(root, "proc")
+		sysPath = filepath.Join(root, "sys")
+
+		writeFile(procPath, "stat", "cpu  100 0 50 800 50 0 0 0 0 0\ncpu0 100 0 50 800 50 0 0 0 0 0\n")
+		writeFile(procPath, "loadavg", "0.50 0.25 0.10 1/100 1234\n")
+		writeFile(procPath, "meminfo", "MemTotal:       1000 kB\nMemFree:         200 kB\nMemAvailable:    400 kB\nSwapTotal:       100 kB\nSwapFree:         75 kB\n")
+		writeFile(procPath, "mounts", fmt.Sprintf(
+			"proc /proc proc rw 0 0\n/dev/sda1 %s ext4 rw 0 0\n/dev/sda1 /bind ext4 rw 0 0\n",
+			root,
+		))
+		writeFile(procPath, "diskstats", "   8       1 sda1 10 0 20 0 30 0 40 0 0 0 0\n")
+		writeNetwork(sysPath, "eth0", 1000, 2000, 0)
+		writeNetwork(sysPath, "lo", 1000, 2000, 0)
+		writeFile(filepath.Join(sysPath, "class", "net"), "bonding_masters", "")
+
+		spySetter = NewSpySetter()
+		collector = hostmetrics.New(spySetter, time.Minute, hostmetrics.WithPaths(procPath, sysPath))
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(root)
+	})
+
+	It("writes load, memory and swap", func() {
+		collector.Collect()
+
+		e := findEnvelope(spySetter, nil)
+		Expect(e.SourceId).To(Equal("system"))
+		Expect(e.GetGauge().GetMetrics()).ToNot(HaveKey("system.cpu.user"))
+		Expect(gaugeValues(e)).To(And(
+			HaveKeyWithValue("system.load.1m", 0.5),
+			HaveKeyWithValue("system.load.5m", 0.25),
+			HaveKeyWithValue("system.load.15m", 0.1),
+			HaveKeyWithValue("system.mem.total", 1024000.0),
+			HaveKeyWithValue("system.mem.available", 409600.0),
+			HaveKeyWithValue("system.mem.used", 614400.0),
+			HaveKeyWithValue("system.mem.percent", 60.0),
+			HaveKeyWithValue("system.swap.total", 102400.0),
+			HaveKeyWithValue("system.swap.used", 25600.0),
+			HaveKeyWithValue("system.swap.percent", 25.0),
+		))
+	})
+
+	It("approximates available memory on older kernels", func() {
+		writeFile(procPath, "meminfo", "MemTotal: 1000 kB\nMemFree: 200 kB\nBuffers: 100 kB\nCached: 200 kB\n")
+		collector.Collect()
+
+		e := findEnvelope(spySetter, nil)
+		Expect(gaugeValues(e)).To(HaveKeyWithValue("system.mem.percent", 50.0))
+	})
+
+	It("writes the CPU utilisation since the previous collection", func() {
+		collector.Collect()
+		writeFile(procPath, "stat", "cpu  150 30 80 880 60 0 0 0 0 0\n")
+		drain(spySetter)
+
+		collector.Collect()
+
+		e := findEnvelope(spySetter, nil)
+		Expect(gaugeValues(e)).To(And(
+			HaveKeyWithValue("system.cpu.user", 40.0),
+			HaveKeyWithValue("system.cpu.sys", 15.0),
+			HaveKeyWithValue("system.cpu.idle", 40.0),
+			HaveKeyWithValue("system.cpu.wait", 5.0),
+			HaveKeyWithValue("system.cpu.steal", 0.0),
+		))
+	})
+
+	It("writes the disk usage and IO per mount", func() {
+		if runtime.GOOS != "linux" {
+			Skip("disk usage is only supported on linux")
+		}
+
+		collector.Collect()
+		e := findEnvelope(spySetter, map[string]string{"mount": root, "device": "sda1"})
+		Expect(gaugeValues(e)).To(And(
+			HaveKey("system.disk.total"),
+			HaveKey("system.disk.used"),
+			HaveKey("system.disk.available"),
+			HaveKey("system.disk.percent"),
+			HaveKey("system.disk.inode_percent"),
+		))
+		Expect(gaugeValues(e)).ToNot(HaveKey("system.disk.read_bytes"))
+
+		writeFile(procPath, "diskstats", "   8       1 sda1 20 0 40 0 30 0 40 0 0 0 0\n")
+		drain(spySetter)
+		collector.Collect()
+
+		e = findEnvelope(spySetter, map[string]string{"mount": root, "device": "sda1"})
+		Expect(gaugeValues(e)["system.disk.read_ops"]).To(BeNumerically(">", 0))
+		Expect(gaugeValues(e)["system.disk.read_bytes"]).To(BeNumerically(">", 0))
+		Expect(gaugeValues(e)).To(HaveKeyWithValue("system.disk.write_ops", 0.0))
+		Expect(gaugeValues(e)).To(HaveKeyWithValue("system.disk.write_bytes", 0.0))
+	})
+
+	It("writes the network rates per interface but loopback", func() {
+		collector.Collect()
+		Expect(envelopesWithTag(spySetter, "interface")).To(BeEmpty())
+
+		writeNetwork(sysPath, "eth0", 3000, 2000, 1)
+		writeNetwork(sysPath, "lo", 5000, 6000, 0)
+		collector.Collect()
+
+		envelopes := envelopesWithTag(spySetter, "interface")
+		Expect(envelopes).To(HaveLen(1))
+		Expect(envelopes[0].Tags).To(Equal(map[string]string{"interface": "eth0"}))
+
+		values := gaugeValues(envelopes[0])
+		Expect(values["system.network.rx_bytes"]).To(BeNumerically(">", 0))
+		Expect(values["system.network.rx_errors"]).To(BeNumerically(">", 0))
+		Expect(values).To(HaveKeyWithValue("system.network.tx_bytes", 0.0))
+		Expect(values).To(HaveKeyWithValue("system.network.tx_dropped", 0.0))
+	})
+
+	It("excludes the interfaces of containers by default", func() {
+		writeNetwork(sysPath, "eth0", 1000, 1000, 0)
+		writeNetwork(sysPath, "veth1234", 1000, 1000, 0)
+		writeNetwork(sysPath, "cali5678", 1000, 1000, 0)
+		collector.Collect()
+		collector.Collect()
+
+		envelopes := envelopesWithTag(spySetter, "interface")
+		Expect(envelopes).To(HaveLen(1))
+		Expect(envelopes[0].Tags).To(Equal(map[string]string{"interface": "eth0"}))
+	})
+
+	It("writes the included interfaces but the excluded ones", func() {
+		collector = hostmetrics.New(spySetter, time.Minute,
+			hostmetrics.WithPaths(procPath, sysPath),
+			hostmetrics.WithInterfaces([]string{"eth*", "bond*"}, []string{"eth1"}),
+		)
+		writeNetwork(sysPath, "eth0", 1000, 1000, 0)
+		writeNetwork(sysPath, "eth1", 1000, 1000, 0)
+		writeNetwork(sysPath, "bond0", 1000, 1000, 0)
+		writeNetwork(sysPath, "veth1234", 1000, 1000, 0)
+		collector.Collect()
+		collector.Collect()
+
+		var names []string
+		for _, e := range envelopesWithTag(spySetter, "interface") {
+			names = append(names, e.Tags["interface"])
+		}
+		Expect(names).To(ConsistOf("eth0", "bond0"))
+	})
+
+	It("reports reset counters as zero", func() {
+		collector.Collect()
+		writeNetwork(sysPath, "eth0", 10, 20, 0)
+		collector.Collect()
+
+		envelopes := envelopesWithTag(spySetter, "interface")
+		Expect(envelopes).To(HaveLen(1))
+		Expect(gaugeValues(envelopes[0])).To(HaveKeyWithValue("system.network.rx_bytes", 0.0))
+	})
+
+	It("skips statistics that cannot be read", func() {
+		Expect(os.Remove(filepath.Join(procPath, "loadavg"))).To(Succeed())
+		Expect(os.RemoveAll(sysPath)).To(Succeed())
+
+		collector.Collect()
+
+		e := findEnvelope(spySetter, nil)
+		Expect(gaugeValues(e)).ToNot(HaveKey("system.load.1m"))
+		Expect(gaugeValues(e)).To(HaveKey("system.mem.total"))
+	})
+})
+
+func writeFile(dir, name, content string) {
+	Expect(os.MkdirAll(dir, 0755)).To(Succeed())
+	Expect(ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)).To(Succeed())
+}
+
+func writeNetwork(sysPath, iface string, rxBytes, txBytes, rxErrors int) {
+	dir := filepath.Join(sysPath, "class", "net", iface, "statistics")
+	writeFile(dir, "rx_bytes", fmt.Sprintf("%d\n", rxBytes))
+	writeFile(dir, "tx_bytes", fmt.Sprintf("%d\n", txBytes))
+	writeFile(dir, "rx_errors", fmt.Sprintf("%d\n", rxErrors))
+	writeFile(dir, "tx_errors", "0\n")
+	writeFile(dir, "rx_dropped", "0\n")
+	writeFile(dir, "tx_dropped", "0\n")
+}
+
+func drain(s *SpySetter) {
+	for {
+		select {
+		case <-s.envelopes:
+		default:
+			return
+		}
+	}
+}
+
+// findEnvelope returns the first written envelope with the given tags.
+func findEnvelope(s *SpySetter, tags map[string]string) *v2.Envelope {
+	for {
+		var e *v2.Envelope
+		Expect(s.envelopes).To(Receive(&e))
+		if len(e.Tags) == len(tags) && (len(tags) == 0 || reflect.DeepEqual(e.Tags, tags)) {
+			return e
+		}
+	}
+}
+
+func envelopesWithTag(s *SpySetter, tag string) []*v2.Envelope {
+	var envelopes []*v2.Envelope
+	for {
+		select {
+		case e := <-s.envelopes:
+			if _, ok := e.Tags[tag]; ok {
+				envelopes = append(envelopes, e)
+			}
+		default:
+			return envelopes
+		}
+	}
+}
+
+func gaugeValues(e *v2.Envelope) map[string]float64 {
+	values := make(map[string]float64)
+	for name, v := range e.GetGauge().GetMetrics() {
+		values[name] = v.Value
+	}
+
+	return values
+}
+
+type SpySetter struct {
+	envelopes chan *v2.Envelope
+}
+
+func NewSpySetter() *SpySetter {
+	return &SpySetter{
+		envelopes: make(chan *v2.Envelope, 100),
+	}
+}
+
+func (s *SpySetter) Set(e *v2.Envelope) {
+	s.envelopes <- e
+}
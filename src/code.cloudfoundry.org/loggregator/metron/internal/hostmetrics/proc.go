@@ -0,0 +1,345 @@
+package hostmetrics
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// sectorSize is the size in bytes of the sectors reported by
+// /proc/diskstats regardless of the sector size of the device.
+const sectorSize = 512
+
+type cpuTimes struct {
+	user   uint64
+	system uint64
+	idle   uint64
+	wait   uint64
+	steal  uint64
+	total  uint64
+}
+
+type loadAverage struct {
+	one     float64
+	five    float64
+	fifteen float64
+}
+
+type memory struct {
+	total     uint64
+	available uint64
+	swapTotal uint64
+	swapFree  uint64
+}
+
+type mount struct {
+	device string
+	path   string
+}
+
+type diskIO struct {
+	readOps    uint64
+	writeOps   uint64
+	readBytes  uint64
+	writeBytes uint64
+}
+
+type networkIO struct {
+	rxBytes   uint64
+	txBytes   uint64
+	rxErrors  uint64
+	txErrors  uint64
+	rxDropped uint64
+	txDropped uint64
+}
+
+// readCPU reads the aggregate CPU times from the first line of /proc/stat.
+func readCPU(procPath string) (cpuTimes, error) {
+	lines, err := readLines(filepath.Join(procPath, "stat"))
+	if err != nil {
+		return cpuTimes{}, err
+	}
+
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 9 || fields[0] != "cpu" {
+			continue
+		}
+
+		// user nice system idle iowait irq softirq steal. Guest time is
+		// already part of user time.
+		values, err := parseUints(fields[1:9])
+		if err != nil {
+			return cpuTimes{}, err
+		}
+
+		var total uint64
+		for _, v := range values {
+			total += v
+		}
+
+		return cpuTimes{
+			user:   values[0] + values[1],
+			system: values[2] + values[5] + values[6],
+			idle:   values[3],
+			wait:   values[4],
+			steal:  values[7],
+			total:  total,
+		}, nil
+	}
+
+	return cpuTimes{}, fmt.Errorf("no cpu line in %s", filepath.Join(procPath, "stat"))
+}
+
+func readLoadAverage(procPath string) (loadAverage, error) {
+	data, err := ioutil.ReadFile(filepath.Join(procPath, "loadavg"))
+	if err != nil {
+		return loadAverage{}, err
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) < 3 {
+		return loadAverage{}, fmt.Errorf("malformed loadavg: %q", data)
+	}
+
+	var values [3]float64
+	for i := range values {
+		values[i], err = strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return loadAverage{}, err
+		}
+	}
+
+	return loadAverage{
+		one:     values[0],
+		five:    values[1],
+		fifteen: values[2],
+	}, nil
+}
+
+// readMemory reads /proc/meminfo. Kernels before 3.14 do not report
+// MemAvailable, in which case it is approximated by the free memory and
+// the page cache.
+func readMemory(procPath string) (memory, error) {
+	lines, err := readLines(filepath.Join(procPath, "meminfo"))
+	if err != nil {
+		return memory{}, err
+	}
+
+	values := make(map[string]uint64)
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		values[strings.TrimSuffix(fields[0], ":")] = v * 1024
+	}
+
+	total, ok := values["MemTotal"]
+	if !ok {
+		return memory{}, fmt.Errorf("no MemTotal in %s", filepath.Join(procPath, "meminfo"))
+	}
+
+	available, ok := values["MemAvailable"]
+	if !ok {
+		available = values["MemFree"] + values["Buffers"] + values["Cached"]
+	}
+
+	return memory{
+		total:     total,
+		available: available,
+		swapTotal: values["SwapTotal"],
+		swapFree:  values["SwapFree"],
+	}, nil
+}
+
+// readMounts returns the mounts of block devices from /proc/mounts. Only
+// the first mount of a device is returned.
+func readMounts(procPath string) ([]mount, error) {
+	lines, err := readLines(filepath.Join(procPath, "mounts"))
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var mounts []mount
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.HasPrefix(fields[0], "/dev/") {
+			continue
+		}
+
+		if seen[fields[0]] {
+			continue
+		}
+		seen[fields[0]] = true
+
+		mounts = append(mounts, mount{
+			device: fields[0],
+			path:   unescapeMountPath(fields[1]),
+		})
+	}
+
+	return mounts, nil
+}
+
+// readDiskIO reads /proc/diskstats keyed by device name.
+func readDiskIO(procPath string) (map[string]diskIO, error) {
+	lines, err := readLines(filepath.Join(procPath, "diskstats"))
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]diskIO)
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 10 {
+			continue
+		}
+
+		// reads completed, reads merged, sectors read, time reading,
+		// writes completed, writes merged, sectors written
+		values, err := parseUints(fields[3:10])
+		if err != nil {
+			continue
+		}
+
+		stats[fields[2]] = diskIO{
+			readOps:    values[0],
+			readBytes:  values[2] * sectorSize,
+			writeOps:   values[4],
+			writeBytes: values[6] * sectorSize,
+		}
+	}
+
+	return stats, nil
+}
+
+// readNetworkIO reads the statistics of every network interface but the
+// loopback interface from /sys/class/net.
+func readNetworkIO(sysPath string) (map[string]networkIO, error) {
+	dir := filepath.Join(sysPath, "class", "net")
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]networkIO)
+	for _, info := range infos {
+		name := info.Name()
+		if name == "lo" {
+			continue
+		}
+
+		var values [6]uint64
+		var failed bool
+		for i, stat := range []string{
+			"rx_bytes", "tx_bytes",
+			"rx_errors", "tx_errors",
+			"rx_dropped", "tx_dropped",
+		} {
+			values[i], err = readUint(filepath.Join(dir, name, "statistics", stat))
+			if err != nil {
+				failed = true
+				break
+			}
+		}
+
+		// Entries without statistics, e.g. bonding_masters, are no
+		// interfaces.
+		if failed {
+			continue
+		}
+
+		stats[name] = networkIO{
+			rxBytes:   values[0],
+			txBytes:   values[1],
+			rxErrors:  values[2],
+			txErrors:  values[3],
+			rxDropped: values[4],
+			txDropped: values[5],
+		}
+	}
+
+	return stats, nil
+}
+
+// deviceName returns the name of the given device in /proc/diskstats.
+// Devices such as /dev/mapper/* and /dev/disk/by-uuid/* are symlinks to the
+// actual device.
+func deviceName(device string) string {
+	if resolved, err := filepath.EvalSymlinks(device); err == nil {
+		device = resolved
+	}
+
+	return filepath.Base(device)
+}
+
+// unescapeMountPath replaces the octal escapes /proc/mounts uses for
+// whitespace and backslashes in mount paths.
+func unescapeMountPath(path string) string {
+	if !strings.Contains(path, `\`) {
+		return path
+	}
+
+	var unescaped []byte
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+3 < len(path) {
+			if c, err := strconv.ParseUint(path[i+1:i+4], 8, 8); err == nil {
+				unescaped = append(unescaped, byte(c))
+				i += 3
+				continue
+			}
+		}
+		unescaped = append(unescaped, path[i])
+	}
+
+	return string(unescaped)
+}
+
+func readLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	return lines, scanner.Err()
+}
+
+func readUint(path string) (uint64, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+func parseUints(fields []string) ([]uint64, error) {
+	values := make([]uint64, len(fields))
+	for i, f := range fields {
+		v, err := strconv.ParseUint(f, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+
+	return values, nil
+}
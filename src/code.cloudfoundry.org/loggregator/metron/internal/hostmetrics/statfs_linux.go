@@ -0,0 +1,21 @@
+//go:build linux
+// +build linux
+
+package hostmetrics
+
+import "syscall"
+
+func statfs(path string) (diskUsage, error) {
+	var s syscall.Statfs_t
+	if err := syscall.Statfs(path, &s); err != nil {
+		return diskUsage{}, err
+	}
+
+	return diskUsage{
+		total:      s.Blocks * uint64(s.Bsize),
+		free:       s.Bfree * uint64(s.Bsize),
+		available:  s.Bavail * uint64(s.Bsize),
+		inodes:     s.Files,
+		freeInodes: s.Ffree,
+	}, nil
+}
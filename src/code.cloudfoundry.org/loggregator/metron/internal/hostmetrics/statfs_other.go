@@ -0,0 +1,10 @@
+//go:build !linux
+// +build !linux
+
+package hostmetrics
+
+import "errors"
+
+func statfs(path string) (diskUsage, error) {
+	return diskUsage{}, errors.New("disk usage is only supported on linux")
+}
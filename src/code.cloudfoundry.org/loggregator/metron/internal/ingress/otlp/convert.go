@@ -0,0 +1,537 @@
+package otlp
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"strconv"
+
+	collogs "code.cloudfoundry.org/loggregator/plumbing/otlp/collector/logs/v1"
+	colmetrics "code.cloudfoundry.org/loggregator/plumbing/otlp/collector/metrics/v1"
+	common "code.cloudfoundry.org/loggregator/plumbing/otlp/common/v1"
+	logs "code.cloudfoundry.org/loggregator/plumbing/otlp/logs/v1"
+	metrics "code.cloudfoundry.org/loggregator/plumbing/otlp/metrics/v1"
+	resource "code.cloudfoundry.org/loggregator/plumbing/otlp/resource/v1"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+)
+
+const (
+	// DefaultSourceID is the source ID of envelopes from resources without
+	// a service.name attribute.
+	DefaultSourceID = "otlp"
+
+	serviceNameKey       = "service.name"
+	serviceInstanceIDKey = "service.instance.id"
+
+	// severityError is the lowest OTLP severity number of the ERROR range.
+	severityError = logs.SeverityNumber_SEVERITY_NUMBER_ERROR
+
+	// maxValueDepth is how deep arrays and key value lists may be nested
+	// in a value. Deeper values are dropped.
+	maxValueDepth = 16
+)
+
+// origin holds the source ID, instance ID and tags shared by every
+// envelope of a resource and scope.
+type origin struct {
+	sourceID   string
+	instanceID string
+	tags       map[string]string
+}
+
+func newOrigin(r *resource.Resource, s *common.InstrumentationScope) origin {
+	o := origin{
+		sourceID: DefaultSourceID,
+		tags:     make(map[string]string),
+	}
+
+	if r != nil {
+		for _, kv := range r.Attributes {
+			if kv == nil || kv.Key == "" {
+				continue
+			}
+
+			switch kv.Key {
+			case serviceNameKey:
+				if v := valueString(kv.Value); v != "" {
+					o.sourceID = v
+				}
+			case serviceInstanceIDKey:
+				o.instanceID = valueString(kv.Value)
+			default:
+				o.tags[kv.Key] = valueString(kv.Value)
+			}
+		}
+	}
+
+	if s != nil {
+		if s.Name != "" {
+			o.tags["otel.scope.name"] = s.Name
+		}
+		if s.Version != "" {
+			o.tags["otel.scope.version"] = s.Version
+		}
+	}
+
+	return o
+}
+
+// envelope returns an envelope with the origin's tags and the given
+// attributes as tags.
+func (o origin) envelope(timestamp uint64, attributes []*common.KeyValue) *v2.Envelope {
+	tags := make(map[string]string, len(o.tags)+len(attributes))
+	for k, v := range o.tags {
+		tags[k] = v
+	}
+	for _, kv := range attributes {
+		if kv != nil && kv.Key != "" {
+			tags[kv.Key] = valueString(kv.Value)
+		}
+	}
+
+	return &v2.Envelope{
+		SourceId:   o.sourceID,
+		InstanceId: o.instanceID,
+		Timestamp:  int64(timestamp),
+		Tags:       tags,
+	}
+}
+
+// logEnvelopes maps every log record to a log envelope. Records with a
+// severity of ERROR or above are written to stderr.
+func logEnvelopes(req *collogs.ExportLogsServiceRequest) []*v2.Envelope {
+	var envelopes []*v2.Envelope
+	for _, rl := range req.ResourceLogs {
+		if rl == nil {
+			continue
+		}
+
+		for _, sl := range rl.ScopeLogs {
+			if sl == nil {
+				continue
+			}
+
+			o := newOrigin(rl.Resource, sl.Scope)
+			for _, lr := range sl.LogRecords {
+				if lr == nil {
+					continue
+				}
+				envelopes = append(envelopes, logEnvelope(o, lr))
+			}
+		}
+	}
+
+	return envelopes
+}
+
+func logEnvelope(o origin, lr *logs.LogRecord) *v2.Envelope {
+	timestamp := lr.TimeUnixNano
+	if timestamp == 0 {
+		timestamp = lr.ObservedTimeUnixNano
+	}
+
+	e := o.envelope(timestamp, lr.Attributes)
+	if lr.SeverityText != "" {
+		e.Tags["severity"] = lr.SeverityText
+	}
+	if len(lr.TraceId) > 0 {
+		e.Tags["trace_id"] = hex.EncodeToString(lr.TraceId)
+	}
+	if len(lr.SpanId) > 0 {
+		e.Tags["span_id"] = hex.EncodeToString(lr.SpanId)
+	}
+
+	logType := v2.Log_OUT
+	if lr.SeverityNumber >= severityError {
+		logType = v2.Log_ERR
+	}
+
+	e.Message = &v2.Envelope_Log{
+		Log: &v2.Log{
+			Payload: []byte(valueString(lr.Body)),
+			Type:    logType,
+		},
+	}
+
+	return e
+}
+
+// metricEnvelopes maps every data point to an envelope. Monotonic sums
+// become counters, other sums and gauges become gauges. Histograms and
+// summaries become gauges with a metric per statistic, named after the
+// OTLP metric with a suffix. Points of cumulative monotonic sums are
+// converted to deltas with sums.
+func metricEnvelopes(req *colmetrics.ExportMetricsServiceRequest, sums *cumulativeSums) []*v2.Envelope {
+	var envelopes []*v2.Envelope
+	for _, rm := range req.ResourceMetrics {
+		if rm == nil {
+			continue
+		}
+
+		for _, sm := range rm.ScopeMetrics {
+			if sm == nil {
+				continue
+			}
+
+			o := newOrigin(rm.Resource, sm.Scope)
+			for _, m := range sm.Metrics {
+				if m == nil || m.Name == "" {
+					continue
+				}
+				envelopes = append(envelopes, metricEnvelope(o, m, sums)...)
+			}
+		}
+	}
+
+	return envelopes
+}
+
+// metricDataPoints returns the number of data points of the named metrics
+// of an export, which is the most envelopes it converts to.
+func metricDataPoints(req *colmetrics.ExportMetricsServiceRequest) int {
+	var n int
+	for _, rm := range req.ResourceMetrics {
+		if rm == nil {
+			continue
+		}
+
+		for _, sm := range rm.ScopeMetrics {
+			if sm == nil {
+				continue
+			}
+
+			for _, m := range sm.Metrics {
+				if m == nil || m.Name == "" {
+					continue
+				}
+				n += dataPoints(m)
+			}
+		}
+	}
+
+	return n
+}
+
+func dataPoints(m *metrics.Metric) int {
+	switch data := m.Data.(type) {
+	case *metrics.Metric_Gauge:
+		return len(data.Gauge.GetDataPoints())
+	case *metrics.Metric_Sum:
+		return len(data.Sum.GetDataPoints())
+	case *metrics.Metric_Histogram:
+		return len(data.Histogram.GetDataPoints())
+	case *metrics.Metric_ExponentialHistogram:
+		return len(data.ExponentialHistogram.GetDataPoints())
+	case *metrics.Metric_Summary:
+		return len(data.Summary.GetDataPoints())
+	}
+	return 0
+}
+
+func metricEnvelope(o origin, m *metrics.Metric, sums *cumulativeSums) []*v2.Envelope {
+	var envelopes []*v2.Envelope
+	switch data := m.Data.(type) {
+	case *metrics.Metric_Gauge:
+		for _, dp := range data.Gauge.GetDataPoints() {
+			if dp != nil {
+				envelopes = append(envelopes, gaugeEnvelope(o, m, dp))
+			}
+		}
+	case *metrics.Metric_Sum:
+		for _, dp := range data.Sum.GetDataPoints() {
+			if dp == nil {
+				continue
+			}
+			if !data.Sum.IsMonotonic {
+				envelopes = append(envelopes, gaugeEnvelope(o, m, dp))
+				continue
+			}
+			if e := counterEnvelope(o, m, data.Sum, dp, sums); e != nil {
+				envelopes = append(envelopes, e)
+			}
+		}
+	case *metrics.Metric_Histogram:
+		for _, dp := range data.Histogram.GetDataPoints() {
+			if dp != nil {
+				envelopes = append(envelopes, histogramEnvelope(o, m, dp))
+			}
+		}
+	case *metrics.Metric_ExponentialHistogram:
+		for _, dp := range data.ExponentialHistogram.GetDataPoints() {
+			if dp != nil {
+				envelopes = append(envelopes, exponentialHistogramEnvelope(o, m, dp))
+			}
+		}
+	case *metrics.Metric_Summary:
+		for _, dp := range data.Summary.GetDataPoints() {
+			if dp != nil {
+				envelopes = append(envelopes, summaryEnvelope(o, m, dp))
+			}
+		}
+	}
+
+	return envelopes
+}
+
+func numberValue(dp *metrics.NumberDataPoint) float64 {
+	switch v := dp.Value.(type) {
+	case *metrics.NumberDataPoint_AsInt:
+		return float64(v.AsInt)
+	case *metrics.NumberDataPoint_AsDouble:
+		return v.AsDouble
+	}
+	return 0
+}
+
+func gaugeEnvelope(o origin, m *metrics.Metric, dp *metrics.NumberDataPoint) *v2.Envelope {
+	e := o.envelope(dp.TimeUnixNano, dp.Attributes)
+	e.Message = &v2.Envelope_Gauge{
+		Gauge: &v2.Gauge{
+			Metrics: map[string]*v2.GaugeValue{
+				m.Name: {Unit: m.Unit, Value: numberValue(dp)},
+			},
+		},
+	}
+
+	return e
+}
+
+// counterEnvelope maps a data point of a monotonic sum to a counter delta.
+// Points of cumulative sums are converted to the delta since the last
+// point of their series. Negative values are invalid for monotonic sums
+// and are dropped.
+func counterEnvelope(o origin, m *metrics.Metric, sum *metrics.Sum, dp *metrics.NumberDataPoint, sums *cumulativeSums) *v2.Envelope {
+	var n uint64
+	switch v := dp.Value.(type) {
+	case *metrics.NumberDataPoint_AsInt:
+		if v.AsInt < 0 {
+			return nil
+		}
+		n = uint64(v.AsInt)
+	case *metrics.NumberDataPoint_AsDouble:
+		if !(v.AsDouble >= 0) {
+			return nil
+		}
+		n = uint64(v.AsDouble + 0.5)
+	}
+
+	e := o.envelope(dp.TimeUnixNano, dp.Attributes)
+	if sum.AggregationTemporality != metrics.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA {
+		n = sums.delta(e, m.Name, dp.StartTimeUnixNano, n)
+	}
+	e.Message = &v2.Envelope_Counter{
+		Counter: &v2.Counter{
+			Name:  m.Name,
+			Value: &v2.Counter_Delta{Delta: n},
+		},
+	}
+
+	return e
+}
+
+// histogramEnvelope maps a histogram data point to a gauge with the
+// count, sum, min and max of the data point and the cumulative count of
+// every bucket, e.g. name.le_100 for the bucket with upper bound 100.
+func histogramEnvelope(o origin, m *metrics.Metric, dp *metrics.HistogramDataPoint) *v2.Envelope {
+	var sum, min, max *float64
+	if v, ok := dp.XSum.(*metrics.HistogramDataPoint_Sum); ok {
+		sum = &v.Sum
+	}
+	if v, ok := dp.XMin.(*metrics.HistogramDataPoint_Min); ok {
+		min = &v.Min
+	}
+	if v, ok := dp.XMax.(*metrics.HistogramDataPoint_Max); ok {
+		max = &v.Max
+	}
+	gauges := statistics(m, dp.Count, sum, min, max)
+
+	var cumulative uint64
+	for i, n := range dp.BucketCounts {
+		cumulative += n
+		bound := "inf"
+		if i < len(dp.ExplicitBounds) {
+			bound = formatFloat(dp.ExplicitBounds[i])
+		}
+		gauges[m.Name+".le_"+bound] = &v2.GaugeValue{
+			Unit:  "count",
+			Value: float64(cumulative),
+		}
+	}
+
+	return histogramGauge(o, dp.TimeUnixNano, dp.Attributes, gauges)
+}
+
+// exponentialHistogramEnvelope maps an exponential histogram data point to
+// a gauge like histogramEnvelope. The upper bound of the positive bucket
+// with index i is base^(i+1) where base is 2^(2^-scale). Negative and zero
+// values are counted in name.le_0.
+func exponentialHistogramEnvelope(o origin, m *metrics.Metric, dp *metrics.ExponentialHistogramDataPoint) *v2.Envelope {
+	var sum, min, max *float64
+	if v, ok := dp.XSum.(*metrics.ExponentialHistogramDataPoint_Sum); ok {
+		sum = &v.Sum
+	}
+	if v, ok := dp.XMin.(*metrics.ExponentialHistogramDataPoint_Min); ok {
+		min = &v.Min
+	}
+	if v, ok := dp.XMax.(*metrics.ExponentialHistogramDataPoint_Max); ok {
+		max = &v.Max
+	}
+	gauges := statistics(m, dp.Count, sum, min, max)
+
+	cumulative := dp.ZeroCount
+	if dp.Negative != nil {
+		for _, n := range dp.Negative.BucketCounts {
+			cumulative += n
+		}
+	}
+	if cumulative > 0 {
+		gauges[m.Name+".le_0"] = &v2.GaugeValue{
+			Unit:  "count",
+			Value: float64(cumulative),
+		}
+	}
+
+	if dp.Positive != nil {
+		base := math.Pow(2, math.Pow(2, -float64(dp.Scale)))
+		for i, n := range dp.Positive.BucketCounts {
+			cumulative += n
+			bound := math.Pow(base, float64(int(dp.Positive.Offset)+i+1))
+			gauges[m.Name+".le_"+formatFloat(bound)] = &v2.GaugeValue{
+				Unit:  "count",
+				Value: float64(cumulative),
+			}
+		}
+	}
+
+	gauges[m.Name+".le_inf"] = &v2.GaugeValue{
+		Unit:  "count",
+		Value: float64(dp.Count),
+	}
+
+	return histogramGauge(o, dp.TimeUnixNano, dp.Attributes, gauges)
+}
+
+// summaryEnvelope maps a summary data point to a gauge with the count,
+// sum and quantiles of the data point, e.g. name.p99 for the 0.99
+// quantile.
+func summaryEnvelope(o origin, m *metrics.Metric, dp *metrics.SummaryDataPoint) *v2.Envelope {
+	sum := dp.Sum
+	gauges := statistics(m, dp.Count, &sum, nil, nil)
+
+	for _, q := range dp.QuantileValues {
+		if q == nil {
+			continue
+		}
+		gauges[m.Name+".p"+formatFloat(q.Quantile*100)] = &v2.GaugeValue{
+			Unit:  m.Unit,
+			Value: q.Value,
+		}
+	}
+
+	return histogramGauge(o, dp.TimeUnixNano, dp.Attributes, gauges)
+}
+
+func statistics(m *metrics.Metric, count uint64, sum, min, max *float64) map[string]*v2.GaugeValue {
+	gauges := map[string]*v2.GaugeValue{
+		m.Name + ".count": {Unit: "count", Value: float64(count)},
+	}
+
+	for suffix, v := range map[string]*float64{".sum": sum, ".min": min, ".max": max} {
+		if v != nil {
+			gauges[m.Name+suffix] = &v2.GaugeValue{Unit: m.Unit, Value: *v}
+		}
+	}
+
+	return gauges
+}
+
+func histogramGauge(o origin, timestamp uint64, attributes []*common.KeyValue, gauges map[string]*v2.GaugeValue) *v2.Envelope {
+	e := o.envelope(timestamp, attributes)
+	e.Message = &v2.Envelope_Gauge{
+		Gauge: &v2.Gauge{Metrics: gauges},
+	}
+
+	return e
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// valueString returns the value as a tag value or log payload. Arrays and
+// key value lists are encoded as JSON, bytes as base64.
+func valueString(v *common.AnyValue) string {
+	if v == nil {
+		return ""
+	}
+
+	switch value := v.Value.(type) {
+	case *common.AnyValue_StringValue:
+		return value.StringValue
+	case *common.AnyValue_BoolValue:
+		return strconv.FormatBool(value.BoolValue)
+	case *common.AnyValue_IntValue:
+		return strconv.FormatInt(value.IntValue, 10)
+	case *common.AnyValue_DoubleValue:
+		return strconv.FormatFloat(value.DoubleValue, 'g', -1, 64)
+	case *common.AnyValue_BytesValue:
+		return base64.StdEncoding.EncodeToString(value.BytesValue)
+	case *common.AnyValue_ArrayValue, *common.AnyValue_KvlistValue:
+		b, err := json.Marshal(plainValue(v, 0))
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+
+	return ""
+}
+
+// plainValue returns the value as a value that encodes to plain JSON.
+// Arrays and key value lists nested deeper than maxValueDepth are
+// dropped.
+func plainValue(v *common.AnyValue, depth int) interface{} {
+	if v == nil {
+		return nil
+	}
+
+	switch value := v.Value.(type) {
+	case *common.AnyValue_StringValue:
+		return value.StringValue
+	case *common.AnyValue_BoolValue:
+		return value.BoolValue
+	case *common.AnyValue_IntValue:
+		return value.IntValue
+	case *common.AnyValue_DoubleValue:
+		if math.IsNaN(value.DoubleValue) || math.IsInf(value.DoubleValue, 0) {
+			return valueString(v)
+		}
+		return value.DoubleValue
+	case *common.AnyValue_BytesValue:
+		return value.BytesValue
+	}
+
+	if depth >= maxValueDepth {
+		return nil
+	}
+
+	switch value := v.Value.(type) {
+	case *common.AnyValue_ArrayValue:
+		values := make([]interface{}, 0, len(value.ArrayValue.GetValues()))
+		for _, av := range value.ArrayValue.GetValues() {
+			values = append(values, plainValue(av, depth+1))
+		}
+		return values
+	case *common.AnyValue_KvlistValue:
+		values := make(map[string]interface{}, len(value.KvlistValue.GetValues()))
+		for _, kv := range value.KvlistValue.GetValues() {
+			if kv != nil {
+				values[kv.Key] = plainValue(kv.Value, depth+1)
+			}
+		}
+		return values
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package otlp
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/metron/internal/egress/counters"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+)
+
+// cumulativeTTL is the time after which series of cumulative sums that
+// have not been seen are evicted.
+const cumulativeTTL = 10 * time.Minute
+
+// seriesID identifies a series of a cumulative sum. Hash is the hash of the
+// source ID, instance ID, name and tags of the series. Points with a new
+// start time belong to a new series as the sum was reset.
+type seriesID struct {
+	hash      uint64
+	startTime uint64
+}
+
+type seriesValue struct {
+	value    uint64
+	lastSeen int64
+}
+
+// cumulativeSums converts the points of cumulative sums to deltas. Metron
+// emits counters as deltas and sets their totals on egress, so the total
+// that comes out is the cumulative value of the series. It is safe for
+// concurrent use.
+type cumulativeSums struct {
+	ttl int64
+
+	mu        sync.Mutex
+	series    map[seriesID]seriesValue
+	lastSweep int64
+}
+
+func newCumulativeSums(ttl time.Duration) *cumulativeSums {
+	return &cumulativeSums{
+		ttl:       int64(ttl),
+		series:    make(map[seriesID]seriesValue),
+		lastSweep: time.Now().UnixNano(),
+	}
+}
+
+// delta returns the increase of the series of the envelope and name since
+// its last point. The first point of a series, the first point after the
+// series was evicted and a point lower than the last one, which means the
+// sum was reset, return the value itself.
+func (c *cumulativeSums) delta(e *v2.Envelope, name string, startTime, value uint64) uint64 {
+	id := seriesID{
+		hash: uint64(counters.NewHash().
+			String(e.SourceId).
+			String(e.InstanceId).
+			String(name).
+			Tags(e.Tags)),
+		startTime: startTime,
+	}
+	now := time.Now().UnixNano()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now-c.lastSweep > c.ttl {
+		c.sweep(now)
+	}
+
+	prev, ok := c.series[id]
+	c.series[id] = seriesValue{value: value, lastSeen: now}
+
+	if !ok || now-prev.lastSeen > c.ttl || value < prev.value {
+		return value
+	}
+	return value - prev.value
+}
+
+// sweep evicts the series that have not been seen within the TTL. It
+// needs to be called with the lock held.
+func (c *cumulativeSums) sweep(now int64) {
+	for id, s := range c.series {
+		if now-s.lastSeen > c.ttl {
+			delete(c.series, id)
+		}
+	}
+	c.lastSweep = now
+}
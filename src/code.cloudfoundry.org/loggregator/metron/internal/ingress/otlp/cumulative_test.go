@@ -0,0 +1,78 @@
+package otlp_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/diodes"
+	"code.cloudfoundry.org/loggregator/metricemitter/testhelper"
+	"code.cloudfoundry.org/loggregator/plumbing/limits"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+
+	egress "code.cloudfoundry.org/loggregator/metron/internal/egress/v2"
+	"code.cloudfoundry.org/loggregator/metron/internal/ingress/otlp"
+
+	gendiodes "github.com/cloudfoundry/diodes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cumulative sums", func() {
+	It("emits the cumulative value as the counter total on egress", func() {
+		metricClient := testhelper.NewMetricClient()
+		writer := newSpyWriter()
+
+		buffer := diodes.NewManyToOneEnvelopeV2(100, gendiodes.AlertFunc(func(int) {}))
+		tx := egress.NewTransponder(
+			buffer,
+			egress.NewCounterAggregator(writer),
+			nil,
+			1, time.Second,
+			metricClient,
+		)
+		go tx.Start()
+
+		rx := otlp.NewReceiver(buffer, limits.New(limits.Config{}, metricClient), metricClient)
+		handler := otlp.NewHTTPHandler(rx, 4096)
+
+		for _, value := range []int{42, 50} {
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/v1/metrics", bytes.NewReader([]byte(fmt.Sprintf(`{"resourceMetrics": [{
+				"scopeMetrics": [{"metrics": [{"name": "requests", "sum": {
+					"aggregationTemporality": 2,
+					"isMonotonic": true,
+					"dataPoints": [{"startTimeUnixNano": "1400000000000000000", "asInt": "%d"}]
+				}}]}]
+			}]}`, value))))
+			req.Header.Set("Content-Type", "application/json")
+			handler.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+		}
+
+		var e *v2.Envelope
+		Eventually(writer.envelopes).Should(Receive(&e))
+		Expect(e.GetCounter().GetTotal()).To(Equal(uint64(42)))
+		Eventually(writer.envelopes).Should(Receive(&e))
+		Expect(e.GetCounter().GetTotal()).To(Equal(uint64(50)))
+	})
+})
+
+type spyWriter struct {
+	envelopes chan *v2.Envelope
+}
+
+func newSpyWriter() *spyWriter {
+	return &spyWriter{
+		envelopes: make(chan *v2.Envelope, 100),
+	}
+}
+
+func (s *spyWriter) Write(msgs []*v2.Envelope) error {
+	for _, e := range msgs {
+		s.envelopes <- e
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+
+	collogs "code.cloudfoundry.org/loggregator/plumbing/otlp/collector/logs/v1"
+	colmetrics "code.cloudfoundry.org/loggregator/plumbing/otlp/collector/metrics/v1"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+)
+
+const (
+	contentTypeProtobuf = "application/x-protobuf"
+	contentTypeJSON     = "application/json"
+)
+
+// HTTPHandler implements OTLP/HTTP. It accepts POST requests to /v1/logs
+// and /v1/metrics with a body in the protobuf or JSON encoding of OTLP,
+// optionally gzip compressed. The response is encoded like the request.
+type HTTPHandler struct {
+	rx           *Receiver
+	maxBodyBytes int64
+}
+
+func NewHTTPHandler(rx *Receiver, maxBodyBytes int64) *HTTPHandler {
+	return &HTTPHandler{
+		rx:           rx,
+		maxBodyBytes: maxBodyBytes,
+	}
+}
+
+func (h *HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/logs" && r.URL.Path != "/v1/metrics" {
+		h.reject(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		h.reject(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (contentType != contentTypeProtobuf && contentType != contentTypeJSON) {
+		h.reject(w, fmt.Sprintf("Content-Type must be %s or %s", contentTypeProtobuf, contentTypeJSON), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body, status, err := h.readBody(w, r)
+	if err != nil {
+		h.reject(w, err.Error(), status)
+		return
+	}
+
+	if r.URL.Path == "/v1/logs" {
+		req := &collogs.ExportLogsServiceRequest{}
+		if err := decode(contentType, body, req); err != nil {
+			h.reject(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if contentType == contentTypeJSON {
+			if err := decodeHexIDs(req); err != nil {
+				h.reject(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		err = h.rx.export(logEnvelopes(req))
+	} else {
+		req := &colmetrics.ExportMetricsServiceRequest{}
+		if err := decode(contentType, body, req); err != nil {
+			h.reject(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		err = h.rx.exportMetrics(req)
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if contentType == contentTypeJSON {
+		w.Write([]byte("{}"))
+	}
+}
+
+// readBody reads the body of the request and decompresses it. Both the
+// compressed and the decompressed body are limited to the max body bytes.
+func (h *HTTPHandler) readBody(w http.ResponseWriter, r *http.Request) ([]byte, int, error) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, h.maxBodyBytes))
+	if err != nil {
+		return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("body exceeds %d bytes", h.maxBodyBytes)
+	}
+
+	switch r.Header.Get("Content-Encoding") {
+	case "", "identity":
+		return body, 0, nil
+	case "gzip":
+	default:
+		return nil, http.StatusUnsupportedMediaType, fmt.Errorf("Content-Encoding must be gzip or identity")
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid gzip body: %s", err)
+	}
+
+	body, err = ioutil.ReadAll(io.LimitReader(gz, h.maxBodyBytes+1))
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid gzip body: %s", err)
+	}
+
+	if int64(len(body)) > h.maxBodyBytes {
+		return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("decompressed body exceeds %d bytes", h.maxBodyBytes)
+	}
+
+	return body, 0, nil
+}
+
+func (h *HTTPHandler) reject(w http.ResponseWriter, msg string, status int) {
+	h.rx.reject()
+	http.Error(w, msg, status)
+}
+
+func decode(contentType string, body []byte, m proto.Message) error {
+	if contentType == contentTypeJSON {
+		u := jsonpb.Unmarshaler{AllowUnknownFields: true}
+		if err := u.Unmarshal(bytes.NewReader(body), m); err != nil {
+			return fmt.Errorf("invalid OTLP JSON: %s", err)
+		}
+		return nil
+	}
+
+	if err := proto.Unmarshal(body, m); err != nil {
+		return fmt.Errorf("invalid OTLP protobuf: %s", err)
+	}
+	return nil
+}
+
+// decodeHexIDs decodes the trace and span IDs of the log records. The
+// JSON encoding of OTLP uses hex for them instead of the base64 jsonpb
+// decodes bytes fields with, so they are encoded back to the original
+// string and decoded as hex.
+func decodeHexIDs(req *collogs.ExportLogsServiceRequest) error {
+	for _, rl := range req.ResourceLogs {
+		for _, sl := range rl.GetScopeLogs() {
+			for _, lr := range sl.GetLogRecords() {
+				if lr == nil {
+					continue
+				}
+
+				var err error
+				if lr.TraceId, err = hexID(lr.TraceId); err != nil {
+					return fmt.Errorf("invalid OTLP JSON: traceId: %s", err)
+				}
+				if lr.SpanId, err = hexID(lr.SpanId); err != nil {
+					return fmt.Errorf("invalid OTLP JSON: spanId: %s", err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func hexID(b []byte) ([]byte, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+	return hex.DecodeString(base64.StdEncoding.EncodeToString(b))
+}
@@ -0,0 +1,456 @@
+package otlp_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"code.cloudfoundry.org/loggregator/metricemitter/testhelper"
+	"code.cloudfoundry.org/loggregator/plumbing/limits"
+
+	collogs "code.cloudfoundry.org/loggregator/plumbing/otlp/collector/logs/v1"
+	colmetrics "code.cloudfoundry.org/loggregator/plumbing/otlp/collector/metrics/v1"
+	common "code.cloudfoundry.org/loggregator/plumbing/otlp/common/v1"
+	logs "code.cloudfoundry.org/loggregator/plumbing/otlp/logs/v1"
+	metrics "code.cloudfoundry.org/loggregator/plumbing/otlp/metrics/v1"
+	resource "code.cloudfoundry.org/loggregator/plumbing/otlp/resource/v1"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+
+	"code.cloudfoundry.org/loggregator/metron/internal/ingress/otlp"
+
+	"github.com/golang/protobuf/proto"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HTTPHandler", func() {
+	var (
+		handler *otlp.HTTPHandler
+
+		spySetter    *SpySetter
+		metricClient *testhelper.SpyMetricClient
+		limitsConfig limits.Config
+		recorder     *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		spySetter = NewSpySetter()
+		metricClient = testhelper.NewMetricClient()
+		limitsConfig = limits.Config{}
+		recorder = httptest.NewRecorder()
+	})
+
+	JustBeforeEach(func() {
+		limiter := limits.New(limitsConfig, metricClient)
+		rx := otlp.NewReceiver(spySetter, limiter, metricClient)
+		handler = otlp.NewHTTPHandler(rx, 4096)
+	})
+
+	post := func(path, contentType string, body []byte) {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		handler.ServeHTTP(recorder, req)
+	}
+
+	postMetric := func(metric string) {
+		post("/v1/metrics", "application/json", []byte(`{"resourceMetrics": [{
+			"resource": {"attributes": [
+				{"key": "service.name", "value": {"stringValue": "checkout"}}
+			]},
+			"scopeMetrics": [{"metrics": [`+metric+`]}]
+		}]}`))
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+	}
+
+	It("maps JSON log records to log envelopes", func() {
+		post("/v1/logs", "application/json", []byte(`{"resourceLogs": [{
+			"resource": {"attributes": [
+				{"key": "service.name", "value": {"stringValue": "checkout"}},
+				{"key": "service.instance.id", "value": {"stringValue": "3"}},
+				{"key": "host.name", "value": {"stringValue": "vm-1"}}
+			]},
+			"scopeLogs": [{
+				"scope": {"name": "app-logger", "version": "1.0"},
+				"logRecords": [{
+					"timeUnixNano": "1500000000000000000",
+					"severityNumber": 17,
+					"severityText": "ERROR",
+					"body": {"stringValue": "boom"},
+					"attributes": [{"key": "retries", "value": {"intValue": "3"}}],
+					"traceId": "5b8efff798038103d269b633813fc60c",
+					"spanId": "eee19b7ec3c1b174"
+				}, {
+					"body": {"kvlistValue": {"values": [
+						{"key": "ok", "value": {"boolValue": true}}
+					]}}
+				}]
+			}]
+		}]}`))
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+		Expect(recorder.Body.String()).To(Equal("{}"))
+
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(e.SourceId).To(Equal("checkout"))
+		Expect(e.InstanceId).To(Equal("3"))
+		Expect(e.Timestamp).To(Equal(int64(1500000000000000000)))
+		Expect(e.Tags).To(Equal(map[string]string{
+			"host.name":          "vm-1",
+			"otel.scope.name":    "app-logger",
+			"otel.scope.version": "1.0",
+			"retries":            "3",
+			"severity":           "ERROR",
+			"trace_id":           "5b8efff798038103d269b633813fc60c",
+			"span_id":            "eee19b7ec3c1b174",
+		}))
+		Expect(string(e.GetLog().Payload)).To(Equal("boom"))
+		Expect(e.GetLog().Type).To(Equal(v2.Log_ERR))
+
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(string(e.GetLog().Payload)).To(Equal(`{"ok":true}`))
+		Expect(e.GetLog().Type).To(Equal(v2.Log_OUT))
+		Expect(e.Timestamp).ToNot(BeZero())
+
+		Expect(metricClient.GetDelta("ingress")).To(Equal(uint64(2)))
+	})
+
+	It("maps protobuf log records to log envelopes", func() {
+		body, err := proto.Marshal(&collogs.ExportLogsServiceRequest{
+			ResourceLogs: []*logs.ResourceLogs{{
+				Resource: &resource.Resource{
+					Attributes: []*common.KeyValue{stringKeyValue("service.name", "checkout")},
+				},
+				ScopeLogs: []*logs.ScopeLogs{{
+					LogRecords: []*logs.LogRecord{{
+						TimeUnixNano:   1500000000000000000,
+						SeverityNumber: logs.SeverityNumber_SEVERITY_NUMBER_INFO,
+						Body:           stringValue("hello"),
+						TraceId:        []byte{0x5b, 0x8e},
+					}},
+				}},
+			}},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		post("/v1/logs", "application/x-protobuf", body)
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/x-protobuf"))
+		Expect(recorder.Body.Len()).To(BeZero())
+
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(e.SourceId).To(Equal("checkout"))
+		Expect(e.Timestamp).To(Equal(int64(1500000000000000000)))
+		Expect(string(e.GetLog().Payload)).To(Equal("hello"))
+		Expect(e.GetLog().Type).To(Equal(v2.Log_OUT))
+		Expect(e.Tags["trace_id"]).To(Equal("5b8e"))
+	})
+
+	It("uses a default source ID for resources without a service name", func() {
+		post("/v1/logs", "application/json", []byte(`{"resourceLogs": [{
+			"scopeLogs": [{"logRecords": [{"body": {"stringValue": "hi"}}]}]
+		}]}`))
+
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(e.SourceId).To(Equal(otlp.DefaultSourceID))
+	})
+
+	It("drops values nested deeper than the max depth", func() {
+		body := `{"stringValue": "deep"}`
+		for i := 0; i < 20; i++ {
+			body = `{"arrayValue": {"values": [` + body + `]}}`
+		}
+		post("/v1/logs", "application/json", []byte(`{"resourceLogs": [{
+			"scopeLogs": [{"logRecords": [{"body": `+body+`}]}]
+		}]}`))
+
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(string(e.GetLog().Payload)).To(Equal(
+			strings.Repeat("[", 16) + "null" + strings.Repeat("]", 16),
+		))
+	})
+
+	It("accepts gzip compressed bodies", func() {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		gz.Write([]byte(`{"resourceLogs": [{"scopeLogs": [{"logRecords": [{"body": {"stringValue": "hi"}}]}]}]}`))
+		gz.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/logs", &buf)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", "gzip")
+		handler.ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(spySetter.envelopes).To(Receive())
+	})
+
+	Describe("cumulative monotonic sums", func() {
+		cumulativeSum := func(startTime string, value int) string {
+			return fmt.Sprintf(`{"name": "requests", "sum": {
+				"aggregationTemporality": 2,
+				"isMonotonic": true,
+				"dataPoints": [{
+					"startTimeUnixNano": %q,
+					"timeUnixNano": "1500000000000000000",
+					"asInt": "%d",
+					"attributes": [{"key": "route", "value": {"stringValue": "/pay"}}]
+				}]
+			}}`, startTime, value)
+		}
+
+		It("maps the first point to a counter delta of its value", func() {
+			postMetric(cumulativeSum("1400000000000000000", 42))
+
+			var e *v2.Envelope
+			Expect(spySetter.envelopes).To(Receive(&e))
+			Expect(e.SourceId).To(Equal("checkout"))
+			Expect(e.Timestamp).To(Equal(int64(1500000000000000000)))
+			Expect(e.Tags).To(Equal(map[string]string{"route": "/pay"}))
+			Expect(e.GetCounter().Name).To(Equal("requests"))
+			Expect(e.GetCounter().GetDelta()).To(Equal(uint64(42)))
+			Expect(e.GetCounter().GetTotal()).To(BeZero())
+		})
+
+		It("maps later points to the delta since the last point", func() {
+			postMetric(cumulativeSum("1400000000000000000", 42))
+			postMetric(cumulativeSum("1400000000000000000", 50))
+
+			var e *v2.Envelope
+			Expect(spySetter.envelopes).To(Receive())
+			Expect(spySetter.envelopes).To(Receive(&e))
+			Expect(e.GetCounter().GetDelta()).To(Equal(uint64(8)))
+		})
+
+		It("starts over when the start time changes", func() {
+			postMetric(cumulativeSum("1400000000000000000", 42))
+			postMetric(cumulativeSum("1450000000000000000", 5))
+
+			var e *v2.Envelope
+			Expect(spySetter.envelopes).To(Receive())
+			Expect(spySetter.envelopes).To(Receive(&e))
+			Expect(e.GetCounter().GetDelta()).To(Equal(uint64(5)))
+		})
+
+		It("starts over when the value decreases", func() {
+			postMetric(cumulativeSum("0", 42))
+			postMetric(cumulativeSum("0", 5))
+
+			var e *v2.Envelope
+			Expect(spySetter.envelopes).To(Receive())
+			Expect(spySetter.envelopes).To(Receive(&e))
+			Expect(e.GetCounter().GetDelta()).To(Equal(uint64(5)))
+		})
+	})
+
+	It("maps delta monotonic sums to counter deltas", func() {
+		postMetric(`{"name": "requests", "sum": {
+			"aggregationTemporality": 1,
+			"isMonotonic": true,
+			"dataPoints": [{"asDouble": 2.0}]
+		}}`)
+
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(e.GetCounter().GetDelta()).To(Equal(uint64(2)))
+	})
+
+	It("maps gauges and non-monotonic sums to gauges", func() {
+		postMetric(`{"name": "temperature", "unit": "Cel", "gauge": {
+			"dataPoints": [{"asDouble": 21.5}, {"asDouble": 22.5}]
+		}}, {"name": "queue_depth", "unit": "1", "sum": {
+			"aggregationTemporality": 2,
+			"dataPoints": [{"asInt": "-3"}]
+		}}`)
+
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(e.GetGauge().Metrics).To(Equal(map[string]*v2.GaugeValue{
+			"temperature": {Unit: "Cel", Value: 21.5},
+		}))
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(e.GetGauge().Metrics["temperature"].Value).To(Equal(22.5))
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(e.GetGauge().Metrics).To(Equal(map[string]*v2.GaugeValue{
+			"queue_depth": {Unit: "1", Value: -3},
+		}))
+	})
+
+	It("maps histograms to gauges with cumulative bucket counts", func() {
+		postMetric(`{"name": "latency", "unit": "ms", "histogram": {
+			"aggregationTemporality": 2,
+			"dataPoints": [{
+				"count": "6",
+				"sum": 180,
+				"min": 2,
+				"max": 90,
+				"bucketCounts": ["1", "3", "2"],
+				"explicitBounds": [10, 50]
+			}]
+		}}`)
+
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(e.GetGauge().Metrics).To(Equal(map[string]*v2.GaugeValue{
+			"latency.count":  {Unit: "count", Value: 6},
+			"latency.sum":    {Unit: "ms", Value: 180},
+			"latency.min":    {Unit: "ms", Value: 2},
+			"latency.max":    {Unit: "ms", Value: 90},
+			"latency.le_10":  {Unit: "count", Value: 1},
+			"latency.le_50":  {Unit: "count", Value: 4},
+			"latency.le_inf": {Unit: "count", Value: 6},
+		}))
+	})
+
+	It("maps exponential histograms to gauges with cumulative bucket counts", func() {
+		postMetric(`{"name": "size", "unit": "By", "exponentialHistogram": {
+			"aggregationTemporality": 2,
+			"dataPoints": [{
+				"count": "4",
+				"sum": 7,
+				"scale": 0,
+				"zeroCount": "1",
+				"positive": {"offset": 0, "bucketCounts": ["1", "2"]}
+			}]
+		}}`)
+
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(e.GetGauge().Metrics).To(Equal(map[string]*v2.GaugeValue{
+			"size.count":  {Unit: "count", Value: 4},
+			"size.sum":    {Unit: "By", Value: 7},
+			"size.le_0":   {Unit: "count", Value: 1},
+			"size.le_2":   {Unit: "count", Value: 2},
+			"size.le_4":   {Unit: "count", Value: 4},
+			"size.le_inf": {Unit: "count", Value: 4},
+		}))
+	})
+
+	It("maps summaries to gauges with quantiles", func() {
+		postMetric(`{"name": "gc_pause", "unit": "s", "summary": {
+			"dataPoints": [{
+				"count": "10",
+				"sum": 1.5,
+				"quantileValues": [
+					{"quantile": 0.5, "value": 0.1},
+					{"quantile": 0.99, "value": 0.4}
+				]
+			}]
+		}}`)
+
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(e.GetGauge().Metrics).To(Equal(map[string]*v2.GaugeValue{
+			"gc_pause.count": {Unit: "count", Value: 10},
+			"gc_pause.sum":   {Unit: "s", Value: 1.5},
+			"gc_pause.p50":   {Unit: "s", Value: 0.1},
+			"gc_pause.p99":   {Unit: "s", Value: 0.4},
+		}))
+	})
+
+	It("maps protobuf histograms to gauges", func() {
+		body, err := proto.Marshal(&colmetrics.ExportMetricsServiceRequest{
+			ResourceMetrics: []*metrics.ResourceMetrics{{
+				ScopeMetrics: []*metrics.ScopeMetrics{{
+					Metrics: []*metrics.Metric{{
+						Name: "latency",
+						Unit: "ms",
+						Data: &metrics.Metric_Histogram{Histogram: &metrics.Histogram{
+							DataPoints: []*metrics.HistogramDataPoint{{
+								Count:          3,
+								XSum:           &metrics.HistogramDataPoint_Sum{Sum: 30},
+								BucketCounts:   []uint64{1, 2},
+								ExplicitBounds: []float64{10},
+							}},
+						}},
+					}},
+				}},
+			}},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		post("/v1/metrics", "application/x-protobuf", body)
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(e.GetGauge().Metrics).To(Equal(map[string]*v2.GaugeValue{
+			"latency.count":  {Unit: "count", Value: 3},
+			"latency.sum":    {Unit: "ms", Value: 30},
+			"latency.le_10":  {Unit: "count", Value: 1},
+			"latency.le_inf": {Unit: "count", Value: 3},
+		}))
+	})
+
+	DescribeTable("rejects invalid requests", func(method, path, contentType, body string, status int) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		handler.ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(status))
+		Expect(spySetter.envelopes).ToNot(Receive())
+		Expect(metricClient.GetDelta("rejected_requests")).To(Equal(uint64(1)))
+	},
+		Entry("unknown path", http.MethodPost, "/v1/traces", "application/json", `{}`, http.StatusNotFound),
+		Entry("GET", http.MethodGet, "/v1/logs", "application/json", ``, http.StatusMethodNotAllowed),
+		Entry("unsupported content type", http.MethodPost, "/v1/logs", "text/plain", `{}`, http.StatusUnsupportedMediaType),
+		Entry("malformed JSON", http.MethodPost, "/v1/logs", "application/json", `{"resourceLogs":`, http.StatusBadRequest),
+		Entry("trace ID that is not hex", http.MethodPost, "/v1/logs", "application/json", `{"resourceLogs": [{"scopeLogs": [{"logRecords": [{"traceId": "zzzz"}]}]}]}`, http.StatusBadRequest),
+		Entry("truncated protobuf", http.MethodPost, "/v1/metrics", "application/x-protobuf", "\x0a\x05", http.StatusBadRequest),
+		Entry("body too large", http.MethodPost, "/v1/logs", "application/json", strings.Repeat(" ", 4097), http.StatusRequestEntityTooLarge),
+	)
+
+	Context("with ingress limits", func() {
+		BeforeEach(func() {
+			limitsConfig = limits.Config{
+				MaxBatchSize: 1,
+			}
+		})
+
+		It("rejects exports with more envelopes than the max batch size", func() {
+			post("/v1/logs", "application/json", []byte(`{"resourceLogs": [{"scopeLogs": [{"logRecords": [
+				{"body": {"stringValue": "a"}},
+				{"body": {"stringValue": "b"}}
+			]}]}]}`))
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(spySetter.envelopes).ToNot(Receive())
+		})
+
+		It("does not advance cumulative sums of rejected exports", func() {
+			point := func(value int) string {
+				return fmt.Sprintf(`{"startTimeUnixNano": "1400000000000000000", "asInt": "%d"}`, value)
+			}
+			cumulativeSum := func(points ...string) string {
+				return fmt.Sprintf(`{"name": "requests", "sum": {
+					"aggregationTemporality": 2,
+					"isMonotonic": true,
+					"dataPoints": [%s]
+				}}`, strings.Join(points, ","))
+			}
+
+			post("/v1/metrics", "application/json", []byte(`{"resourceMetrics": [{
+				"resource": {"attributes": [
+					{"key": "service.name", "value": {"stringValue": "checkout"}}
+				]},
+				"scopeMetrics": [{"metrics": [`+cumulativeSum(point(42), point(45))+`]}]
+			}]}`))
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+
+			recorder = httptest.NewRecorder()
+			postMetric(cumulativeSum(point(50)))
+
+			var e *v2.Envelope
+			Expect(spySetter.envelopes).To(Receive(&e))
+			Expect(e.GetCounter().GetDelta()).To(Equal(uint64(50)))
+		})
+	})
+})
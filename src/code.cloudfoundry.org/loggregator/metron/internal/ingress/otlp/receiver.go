@@ -0,0 +1,154 @@
+package otlp
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/metricemitter"
+
+	collogs "code.cloudfoundry.org/loggregator/plumbing/otlp/collector/logs/v1"
+	colmetrics "code.cloudfoundry.org/loggregator/plumbing/otlp/collector/metrics/v1"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// DataSetter accepts writes of v2.Envelopes
+type DataSetter interface {
+	Set(e *v2.Envelope)
+}
+
+// Limiter applies the ingress limits to the envelopes converted from an
+// export.
+type Limiter interface {
+	BatchAllowed(size int) bool
+	Envelope(e *v2.Envelope) []*v2.Envelope
+}
+
+// MetricClient creates the counters of received and rejected exports.
+type MetricClient interface {
+	NewCounter(name string, opts ...metricemitter.MetricOption) *metricemitter.Counter
+}
+
+var errBatchTooLarge = errors.New("export exceeds the maximum batch size")
+
+// Receiver maps OTLP logs and metrics exports to envelopes. It serves the
+// OTLP LogsService and MetricsService over gRPC, see Register.
+type Receiver struct {
+	dataSetter DataSetter
+	limiter    Limiter
+	sums       *cumulativeSums
+
+	ingressMetric  *metricemitter.Counter
+	rejectedMetric *metricemitter.Counter
+}
+
+func NewReceiver(dataSetter DataSetter, limiter Limiter, metricClient MetricClient) *Receiver {
+	ingressMetric := metricClient.NewCounter("ingress",
+		metricemitter.WithVersion(2, 0),
+		metricemitter.WithTags(map[string]string{"protocol": "otlp"}),
+	)
+	rejectedMetric := metricClient.NewCounter("rejected_requests",
+		metricemitter.WithVersion(2, 0),
+		metricemitter.WithTags(map[string]string{"protocol": "otlp"}),
+	)
+
+	return &Receiver{
+		dataSetter:     dataSetter,
+		limiter:        limiter,
+		sums:           newCumulativeSums(cumulativeTTL),
+		ingressMetric:  ingressMetric,
+		rejectedMetric: rejectedMetric,
+	}
+}
+
+// ExportLogs implements the Export method of the OTLP LogsService.
+func (r *Receiver) ExportLogs(ctx context.Context, req *collogs.ExportLogsServiceRequest) (*collogs.ExportLogsServiceResponse, error) {
+	if err := r.export(logEnvelopes(req)); err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "%s", err)
+	}
+
+	return &collogs.ExportLogsServiceResponse{}, nil
+}
+
+// ExportMetrics implements the Export method of the OTLP MetricsService.
+func (r *Receiver) ExportMetrics(ctx context.Context, req *colmetrics.ExportMetricsServiceRequest) (*colmetrics.ExportMetricsServiceResponse, error) {
+	if err := r.exportMetrics(req); err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "%s", err)
+	}
+
+	return &colmetrics.ExportMetricsServiceResponse{}, nil
+}
+
+// export writes the envelopes of an export unless there are more than the
+// limiter allows in a batch. Envelopes without a timestamp are stamped
+// with the time they are received.
+func (r *Receiver) export(envelopes []*v2.Envelope) error {
+	if !r.limiter.BatchAllowed(len(envelopes)) {
+		r.reject()
+		return errBatchTooLarge
+	}
+
+	now := time.Now().UnixNano()
+	for _, e := range envelopes {
+		if e.Timestamp == 0 {
+			e.Timestamp = now
+		}
+
+		for _, limited := range r.limiter.Envelope(e) {
+			r.dataSetter.Set(limited)
+		}
+	}
+
+	// metric-documentation-v2: (loggregator.metron.ingress) The number of
+	// received messages over Metrons OTLP API (tagged with protocol otlp).
+	r.ingressMetric.Increment(uint64(len(envelopes)))
+
+	return nil
+}
+
+// exportMetrics checks the size of a metrics export before converting it.
+// Converting cumulative sums advances their series, so an export that is
+// rejected must not be converted.
+func (r *Receiver) exportMetrics(req *colmetrics.ExportMetricsServiceRequest) error {
+	if !r.limiter.BatchAllowed(metricDataPoints(req)) {
+		r.reject()
+		return errBatchTooLarge
+	}
+
+	return r.export(metricEnvelopes(req, r.sums))
+}
+
+func (r *Receiver) reject() {
+	// metric-documentation-v2: (loggregator.metron.rejected_requests) The
+	// number of exports to Metrons OTLP API rejected as invalid or too
+	// large.
+	r.rejectedMetric.Increment(1)
+}
+
+// Register registers the receiver as the OTLP LogsService and
+// MetricsService of the gRPC server.
+func Register(s *grpc.Server, r *Receiver) {
+	collogs.RegisterLogsServiceServer(s, logsService{r})
+	colmetrics.RegisterMetricsServiceServer(s, metricsService{r})
+}
+
+// logsService and metricsService adapt the receiver to the generated
+// service interfaces, which both name their method Export.
+type logsService struct {
+	r *Receiver
+}
+
+func (s logsService) Export(ctx context.Context, req *collogs.ExportLogsServiceRequest) (*collogs.ExportLogsServiceResponse, error) {
+	return s.r.ExportLogs(ctx, req)
+}
+
+type metricsService struct {
+	r *Receiver
+}
+
+func (s metricsService) Export(ctx context.Context, req *colmetrics.ExportMetricsServiceRequest) (*colmetrics.ExportMetricsServiceResponse, error) {
+	return s.r.ExportMetrics(ctx, req)
+}
@@ -0,0 +1,174 @@
+package otlp_test
+
+import (
+	"net"
+
+	"code.cloudfoundry.org/loggregator/metricemitter/testhelper"
+	"code.cloudfoundry.org/loggregator/plumbing/limits"
+
+	collogs "code.cloudfoundry.org/loggregator/plumbing/otlp/collector/logs/v1"
+	colmetrics "code.cloudfoundry.org/loggregator/plumbing/otlp/collector/metrics/v1"
+	common "code.cloudfoundry.org/loggregator/plumbing/otlp/common/v1"
+	logs "code.cloudfoundry.org/loggregator/plumbing/otlp/logs/v1"
+	metrics "code.cloudfoundry.org/loggregator/plumbing/otlp/metrics/v1"
+	resource "code.cloudfoundry.org/loggregator/plumbing/otlp/resource/v1"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+
+	"code.cloudfoundry.org/loggregator/metron/internal/ingress/otlp"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Receiver", func() {
+	var (
+		spySetter    *SpySetter
+		metricClient *testhelper.SpyMetricClient
+		limitsConfig limits.Config
+
+		server *grpc.Server
+		conn   *grpc.ClientConn
+	)
+
+	BeforeEach(func() {
+		spySetter = NewSpySetter()
+		metricClient = testhelper.NewMetricClient()
+		limitsConfig = limits.Config{}
+	})
+
+	JustBeforeEach(func() {
+		limiter := limits.New(limitsConfig, metricClient)
+		rx := otlp.NewReceiver(spySetter, limiter, metricClient)
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+
+		server = grpc.NewServer()
+		otlp.Register(server, rx)
+		go server.Serve(lis)
+
+		conn, err = grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		conn.Close()
+		server.Stop()
+	})
+
+	It("exports logs over gRPC", func() {
+		req := &collogs.ExportLogsServiceRequest{
+			ResourceLogs: []*logs.ResourceLogs{{
+				Resource: &resource.Resource{
+					Attributes: []*common.KeyValue{stringKeyValue("service.name", "checkout")},
+				},
+				ScopeLogs: []*logs.ScopeLogs{{
+					Scope: &common.InstrumentationScope{Name: "app-logger"},
+					LogRecords: []*logs.LogRecord{{
+						SeverityNumber: logs.SeverityNumber_SEVERITY_NUMBER_FATAL,
+						SeverityText:   "FATAL",
+						Body:           stringValue("crashed"),
+						Attributes:     []*common.KeyValue{stringKeyValue("pid", "12")},
+					}},
+				}},
+			}},
+		}
+		_, err := collogs.NewLogsServiceClient(conn).Export(context.Background(), req)
+		Expect(err).ToNot(HaveOccurred())
+
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(e.SourceId).To(Equal("checkout"))
+		Expect(e.Timestamp).ToNot(BeZero())
+		Expect(e.Tags).To(Equal(map[string]string{
+			"otel.scope.name": "app-logger",
+			"pid":             "12",
+			"severity":        "FATAL",
+		}))
+		Expect(string(e.GetLog().Payload)).To(Equal("crashed"))
+		Expect(e.GetLog().Type).To(Equal(v2.Log_ERR))
+		Expect(metricClient.GetDelta("ingress")).To(Equal(uint64(1)))
+	})
+
+	It("exports metrics over gRPC", func() {
+		req := &colmetrics.ExportMetricsServiceRequest{
+			ResourceMetrics: []*metrics.ResourceMetrics{{
+				ScopeMetrics: []*metrics.ScopeMetrics{{
+					Metrics: []*metrics.Metric{{
+						Name: "requests",
+						Data: &metrics.Metric_Sum{Sum: &metrics.Sum{
+							AggregationTemporality: metrics.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA,
+							IsMonotonic:            true,
+							DataPoints: []*metrics.NumberDataPoint{{
+								TimeUnixNano: 1500000000000000000,
+								Value:        &metrics.NumberDataPoint_AsInt{AsInt: 7},
+								Attributes:   []*common.KeyValue{stringKeyValue("route", "/pay")},
+							}},
+						}},
+					}},
+				}},
+			}},
+		}
+		_, err := colmetrics.NewMetricsServiceClient(conn).Export(context.Background(), req)
+		Expect(err).ToNot(HaveOccurred())
+
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(e.Timestamp).To(Equal(int64(1500000000000000000)))
+		Expect(e.Tags).To(Equal(map[string]string{"route": "/pay"}))
+		Expect(e.GetCounter().Name).To(Equal("requests"))
+		Expect(e.GetCounter().GetDelta()).To(Equal(uint64(7)))
+	})
+
+	Context("with ingress limits", func() {
+		BeforeEach(func() {
+			limitsConfig = limits.Config{
+				MaxBatchSize: 1,
+			}
+		})
+
+		It("rejects exports with more envelopes than the max batch size", func() {
+			record := &logs.LogRecord{Body: stringValue("a")}
+			req := &collogs.ExportLogsServiceRequest{
+				ResourceLogs: []*logs.ResourceLogs{{
+					ScopeLogs: []*logs.ScopeLogs{{
+						LogRecords: []*logs.LogRecord{record, record},
+					}},
+				}},
+			}
+
+			_, err := collogs.NewLogsServiceClient(conn).Export(context.Background(), req)
+			Expect(grpc.Code(err)).To(Equal(codes.InvalidArgument))
+			Expect(spySetter.envelopes).ToNot(Receive())
+			Expect(metricClient.GetDelta("rejected_requests")).To(Equal(uint64(1)))
+		})
+	})
+})
+
+type SpySetter struct {
+	envelopes chan *v2.Envelope
+}
+
+func NewSpySetter() *SpySetter {
+	return &SpySetter{
+		envelopes: make(chan *v2.Envelope, 100),
+	}
+}
+
+func (s *SpySetter) Set(e *v2.Envelope) {
+	s.envelopes <- e
+}
+
+func stringValue(s string) *common.AnyValue {
+	return &common.AnyValue{
+		Value: &common.AnyValue_StringValue{StringValue: s},
+	}
+}
+
+func stringKeyValue(k, v string) *common.KeyValue {
+	return &common.KeyValue{Key: k, Value: stringValue(v)}
+}
@@ -0,0 +1,36 @@
+package otlp
+
+import (
+	"log"
+	"net"
+
+	"google.golang.org/grpc"
+)
+
+type Server struct {
+	addr string
+	rx   *Receiver
+	opts []grpc.ServerOption
+}
+
+func NewServer(addr string, rx *Receiver, opts ...grpc.ServerOption) *Server {
+	return &Server{
+		addr: addr,
+		rx:   rx,
+		opts: opts,
+	}
+}
+
+func (s *Server) Start() {
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		log.Fatalf("failed to listen: %v", err)
+	}
+
+	grpcServer := grpc.NewServer(s.opts...)
+	Register(grpcServer, s.rx)
+
+	if err := grpcServer.Serve(lis); err != nil {
+		log.Fatalf("failed to serve: %v", err)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"code.cloudfoundry.org/loggregator/diodes"
 
 	gendiodes "github.com/cloudfoundry/diodes"
+	"github.com/cloudfoundry/sonde-go/events"
+	"github.com/gogo/protobuf/proto"
 
 	"github.com/cloudfoundry/dropsonde/metrics"
 )
@@ -15,15 +17,22 @@ type ByteArrayWriter interface {
 	Write(message []byte)
 }
 
+// Limiter applies the ingress limits to dropsonde messages read from UDP.
+type Limiter interface {
+	MayExceed(data []byte) bool
+	V1Envelope(e *events.Envelope) []*events.Envelope
+}
+
 type NetworkReader struct {
 	connection net.PacketConn
 	writer     ByteArrayWriter
+	limiter    Limiter
 
 	contextName string
 	buffer      *diodes.OneToOne
 }
 
-func New(address string, name string, writer ByteArrayWriter) (*NetworkReader, error) {
+func New(address string, name string, writer ByteArrayWriter, limiter Limiter) (*NetworkReader, error) {
 	connection, err := net.ListenPacket("udp4", address)
 	if err != nil {
 		return nil, err
@@ -34,6 +43,7 @@ func New(address string, name string, writer ByteArrayWriter) (*NetworkReader, e
 		connection:  connection,
 		contextName: name,
 		writer:      writer,
+		limiter:     limiter,
 		buffer: diodes.NewOneToOne(10000, gendiodes.AlertFunc(func(missed int) {
 			log.Printf("network reader dropped messages %d", missed)
 			// metric-documentation-v1: (udp.receiveErrorCount) Number of dropped messages
@@ -70,7 +80,30 @@ func (nr *NetworkReader) StartWriting() {
 		// metric-documentation-v1: (dropsondeAgentListener.receivedByteCount) Number of
 		// received bytes inbound to Metron over the v1 (UDP) API
 		metrics.BatchAddCounter(receivedByteCountName, uint64(len(data)))
+		nr.write(data)
+	}
+}
+
+func (nr *NetworkReader) write(data []byte) {
+	if !nr.limiter.MayExceed(data) {
+		nr.writer.Write(data)
+		return
+	}
+
+	envelope := &events.Envelope{}
+	if err := proto.Unmarshal(data, envelope); err != nil {
+		// Leave reporting invalid envelopes to the unmarshaller.
 		nr.writer.Write(data)
+		return
+	}
+
+	for _, e := range nr.limiter.V1Envelope(envelope) {
+		limited, err := proto.Marshal(e)
+		if err != nil {
+			log.Printf("Failed to marshal envelope: %s", err)
+			continue
+		}
+		nr.writer.Write(limited)
 	}
 }
 
@@ -5,10 +5,14 @@ import (
 	"strconv"
 	"sync"
 
+	"code.cloudfoundry.org/loggregator/metricemitter/testhelper"
 	ingress "code.cloudfoundry.org/loggregator/metron/internal/ingress/v1"
+	"code.cloudfoundry.org/loggregator/plumbing/limits"
 
 	"github.com/cloudfoundry/dropsonde/metric_sender/fake"
 	"github.com/cloudfoundry/dropsonde/metrics"
+	"github.com/cloudfoundry/sonde-go/events"
+	"github.com/gogo/protobuf/proto"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -35,14 +39,20 @@ var _ = Describe("NetworkReader", func() {
 		port             int
 		address          string
 		fakeMetricSender *fake.FakeMetricSender
+		limitsConfig     limits.Config
 	)
 
 	BeforeEach(func() {
+		limitsConfig = limits.Config{}
+	})
+
+	JustBeforeEach(func() {
 		port = randomPort() + GinkgoParallelNode()
 		address = net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
 		writer = MockByteArrayWriter{}
+		limiter := limits.New(limitsConfig, testhelper.NewMetricClient())
 		var err error
-		reader, err = ingress.New(address, "networkReader", &writer)
+		reader, err = ingress.New(address, "networkReader", &writer, limiter)
 		Expect(err).NotTo(HaveOccurred())
 		readerStopped = make(chan struct{})
 	})
@@ -50,7 +60,7 @@ var _ = Describe("NetworkReader", func() {
 	Context("with a reader running", func() {
 		var mockBatcher *mockMetricBatcher
 
-		BeforeEach(func() {
+		JustBeforeEach(func() {
 			fakeMetricSender = fake.NewFakeMetricSender()
 			mockBatcher = newMockMetricBatcher()
 			metrics.Initialize(fakeMetricSender, mockBatcher)
@@ -83,6 +93,40 @@ var _ = Describe("NetworkReader", func() {
 			data := string(writer.Data()[0])
 			Expect(data).To(Equal(expectedData))
 		})
+
+		Context("with a max log payload size", func() {
+			BeforeEach(func() {
+				limitsConfig = limits.Config{MaxLogPayloadBytes: 20}
+			})
+
+			It("truncates oversized logs", func() {
+				data, err := proto.Marshal(&events.Envelope{
+					Origin:    proto.String("origin"),
+					EventType: events.Envelope_LogMessage.Enum(),
+					LogMessage: &events.LogMessage{
+						Message:     []byte("0123456789abcdefghijklmnop"),
+						MessageType: events.LogMessage_OUT.Enum(),
+						Timestamp:   proto.Int64(1),
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				connection, err := net.Dial("udp", address)
+				Expect(err).NotTo(HaveOccurred())
+
+				f := func() int {
+					_, err = connection.Write(data)
+					Expect(err).NotTo(HaveOccurred())
+
+					return len(writer.Data())
+				}
+
+				Eventually(f).ShouldNot(BeZero())
+				var e events.Envelope
+				Expect(proto.Unmarshal(writer.Data()[0], &e)).To(Succeed())
+				Expect(string(e.GetLogMessage().GetMessage())).To(Equal("012345...[truncated]"))
+			})
+		})
 	})
 })
 
@@ -0,0 +1,198 @@
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/metricemitter"
+
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+)
+
+// HTTPHandler accepts envelopes in the protobuf JSON mapping of v2
+// envelopes. The body of a POST request is either a single envelope or an
+// envelope batch, i.e. an object with a batch field holding a list of
+// envelopes. Either every envelope of a request is accepted or none.
+// Envelopes without a timestamp are stamped with the time they are
+// received.
+type HTTPHandler struct {
+	dataSetter   DataSetter
+	limiter      Limiter
+	maxBodyBytes int64
+
+	ingressMetric  *metricemitter.Counter
+	rejectedMetric *metricemitter.Counter
+}
+
+func NewHTTPHandler(
+	dataSetter DataSetter,
+	limiter Limiter,
+	maxBodyBytes int64,
+	metricClient MetricClient,
+) *HTTPHandler {
+	ingressMetric := metricClient.NewCounter("ingress",
+		metricemitter.WithVersion(2, 0),
+		metricemitter.WithTags(map[string]string{"protocol": "http"}),
+	)
+	rejectedMetric := metricClient.NewCounter("rejected_requests",
+		metricemitter.WithVersion(2, 0),
+		metricemitter.WithTags(map[string]string{"protocol": "http"}),
+	)
+
+	return &HTTPHandler{
+		dataSetter:     dataSetter,
+		limiter:        limiter,
+		maxBodyBytes:   maxBodyBytes,
+		ingressMetric:  ingressMetric,
+		rejectedMetric: rejectedMetric,
+	}
+}
+
+func (h *HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		h.reject(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, h.maxBodyBytes))
+	if err != nil {
+		h.reject(w, fmt.Sprintf("body exceeds %d bytes", h.maxBodyBytes), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	envelopes, err := decodeEnvelopes(body)
+	if err != nil {
+		h.reject(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !h.limiter.BatchAllowed(len(envelopes)) {
+		h.reject(w, "batch exceeds the maximum batch size", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	now := time.Now().UnixNano()
+	limited := make([]*v2.Envelope, 0, len(envelopes))
+	for i, e := range envelopes {
+		if err := validate(e); err != nil {
+			h.reject(w, fmt.Sprintf("envelope %d: %s", i, err), http.StatusBadRequest)
+			return
+		}
+
+		if e.Timestamp == 0 {
+			e.Timestamp = now
+		}
+
+		allowed := h.limiter.Envelope(e)
+		if len(allowed) == 0 {
+			h.reject(w, fmt.Sprintf("envelope %d: tags exceed the ingress limits", i), http.StatusBadRequest)
+			return
+		}
+		limited = append(limited, allowed...)
+	}
+
+	for _, e := range limited {
+		h.dataSetter.Set(e)
+	}
+
+	// metric-documentation-v2: (loggregator.metron.ingress) The number of
+	// received messages over Metrons V2 HTTP API (tagged with protocol http).
+	h.ingressMetric.Increment(uint64(len(envelopes)))
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *HTTPHandler) reject(w http.ResponseWriter, msg string, status int) {
+	// metric-documentation-v2: (loggregator.metron.rejected_requests) The
+	// number of requests to Metrons V2 HTTP API rejected as invalid.
+	h.rejectedMetric.Increment(1)
+
+	http.Error(w, msg, status)
+}
+
+// decodeEnvelopes decodes a single envelope or an envelope batch.
+func decodeEnvelopes(body []byte) ([]*v2.Envelope, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, fmt.Errorf("body must be a JSON object: %s", err)
+	}
+
+	if _, ok := fields["batch"]; ok {
+		var batch v2.EnvelopeBatch
+		if err := unmarshal(body, &batch); err != nil {
+			return nil, err
+		}
+
+		if len(batch.Batch) == 0 {
+			return nil, fmt.Errorf("batch is empty")
+		}
+
+		return batch.Batch, nil
+	}
+
+	var e v2.Envelope
+	if err := unmarshal(body, &e); err != nil {
+		return nil, err
+	}
+
+	return []*v2.Envelope{&e}, nil
+}
+
+func unmarshal(body []byte, m proto.Message) error {
+	if err := jsonpb.Unmarshal(bytes.NewReader(body), m); err != nil {
+		return fmt.Errorf("invalid envelope: %s", err)
+	}
+
+	return nil
+}
+
+// validate checks that the envelope has a source ID and a message with
+// the fields required to route and display it.
+func validate(e *v2.Envelope) error {
+	if e == nil {
+		return fmt.Errorf("envelope is null")
+	}
+
+	if e.SourceId == "" {
+		return fmt.Errorf("source_id is required")
+	}
+
+	switch m := e.Message.(type) {
+	case *v2.Envelope_Log:
+		if m.Log == nil {
+			return fmt.Errorf("log is empty")
+		}
+	case *v2.Envelope_Counter:
+		if m.Counter == nil || m.Counter.Name == "" {
+			return fmt.Errorf("counter requires a name")
+		}
+	case *v2.Envelope_Gauge:
+		if m.Gauge == nil || len(m.Gauge.Metrics) == 0 {
+			return fmt.Errorf("gauge requires at least one metric")
+		}
+		for name, v := range m.Gauge.Metrics {
+			if name == "" || v == nil {
+				return fmt.Errorf("gauge metrics require a name and a value")
+			}
+		}
+	case *v2.Envelope_Timer:
+		if m.Timer == nil || m.Timer.Name == "" {
+			return fmt.Errorf("timer requires a name")
+		}
+		if m.Timer.Stop < m.Timer.Start {
+			return fmt.Errorf("timer stops before it starts")
+		}
+	default:
+		return fmt.Errorf("one of log, counter, gauge or timer is required")
+	}
+
+	return nil
+}
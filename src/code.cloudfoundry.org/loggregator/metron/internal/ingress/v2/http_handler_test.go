@@ -0,0 +1,167 @@
+package v2_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"code.cloudfoundry.org/loggregator/metricemitter/testhelper"
+	"code.cloudfoundry.org/loggregator/plumbing/limits"
+
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+
+	ingress "code.cloudfoundry.org/loggregator/metron/internal/ingress/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HTTPHandler", func() {
+	var (
+		handler *ingress.HTTPHandler
+
+		spySetter    *SpySetter
+		metricClient *testhelper.SpyMetricClient
+		limitsConfig limits.Config
+		recorder     *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		spySetter = NewSpySetter()
+		metricClient = testhelper.NewMetricClient()
+		limitsConfig = limits.Config{}
+		recorder = httptest.NewRecorder()
+	})
+
+	JustBeforeEach(func() {
+		limiter := limits.New(limitsConfig, metricClient)
+		handler = ingress.NewHTTPHandler(spySetter, limiter, 1024, metricClient)
+	})
+
+	post := func(body string) {
+		req := httptest.NewRequest(http.MethodPost, "/v2/envelopes", strings.NewReader(body))
+		handler.ServeHTTP(recorder, req)
+	}
+
+	It("accepts a single envelope", func() {
+		post(`{
+			"source_id": "some-script",
+			"timestamp": "1500000000000000000",
+			"tags": {"job": "cron"},
+			"log": {"payload": "aGVsbG8=", "type": "ERR"}
+		}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusAccepted))
+
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(e.SourceId).To(Equal("some-script"))
+		Expect(e.Timestamp).To(Equal(int64(1500000000000000000)))
+		Expect(e.Tags).To(Equal(map[string]string{"job": "cron"}))
+		Expect(string(e.GetLog().Payload)).To(Equal("hello"))
+		Expect(e.GetLog().Type).To(Equal(v2.Log_ERR))
+		Expect(metricClient.GetDelta("ingress")).To(Equal(uint64(1)))
+	})
+
+	It("accepts a batch of envelopes", func() {
+		post(`{"batch": [
+			{"source_id": "some-script", "counter": {"name": "runs", "delta": "1"}},
+			{"source_id": "some-script", "gauge": {"metrics": {"duration": {"unit": "s", "value": 1.5}}}}
+		]}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusAccepted))
+
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(e.GetCounter().Name).To(Equal("runs"))
+		Expect(e.GetCounter().GetDelta()).To(Equal(uint64(1)))
+
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(e.GetGauge().Metrics["duration"].Value).To(Equal(1.5))
+		Expect(metricClient.GetDelta("ingress")).To(Equal(uint64(2)))
+	})
+
+	It("stamps envelopes without a timestamp", func() {
+		post(`{"source_id": "some-script", "counter": {"name": "runs"}}`)
+
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(e.Timestamp).ToNot(BeZero())
+	})
+
+	It("rejects the whole batch if any envelope is invalid", func() {
+		post(`{"batch": [
+			{"source_id": "some-script", "counter": {"name": "runs"}},
+			{"source_id": "some-script", "counter": {}}
+		]}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(recorder.Body.String()).To(ContainSubstring("envelope 1: counter requires a name"))
+		Expect(spySetter.envelopes).ToNot(Receive())
+		Expect(metricClient.GetDelta("rejected_requests")).To(Equal(uint64(1)))
+	})
+
+	DescribeTable("rejects invalid envelopes", func(body, msg string) {
+		post(body)
+
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(recorder.Body.String()).To(ContainSubstring(msg))
+		Expect(spySetter.envelopes).ToNot(Receive())
+	},
+		Entry("malformed JSON", `{"source_id":`, "body must be a JSON object"),
+		Entry("unknown fields", `{"source_id": "a", "bogus": 1, "log": {}}`, "invalid envelope"),
+		Entry("no source ID", `{"log": {"payload": "aGk="}}`, "source_id is required"),
+		Entry("no message", `{"source_id": "a"}`, "one of log, counter, gauge or timer is required"),
+		Entry("empty gauge", `{"source_id": "a", "gauge": {}}`, "gauge requires at least one metric"),
+		Entry("backwards timer", `{"source_id": "a", "timer": {"name": "t", "start": "2", "stop": "1"}}`, "timer stops before it starts"),
+		Entry("empty batch", `{"batch": []}`, "batch is empty"),
+	)
+
+	It("rejects methods other than POST", func() {
+		req := httptest.NewRequest(http.MethodGet, "/v2/envelopes", nil)
+		handler.ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+
+	It("rejects bodies larger than the maximum", func() {
+		post(`{"source_id": "` + strings.Repeat("a", 1024) + `", "counter": {"name": "c"}}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusRequestEntityTooLarge))
+		Expect(spySetter.envelopes).ToNot(Receive())
+	})
+
+	Context("with ingress limits", func() {
+		BeforeEach(func() {
+			limitsConfig = limits.Config{
+				MaxBatchSize: 2,
+				MaxTagCount:  1,
+			}
+		})
+
+		It("rejects batches larger than the max batch size", func() {
+			post(`{"batch": [
+				{"source_id": "a", "counter": {"name": "c"}},
+				{"source_id": "a", "counter": {"name": "c"}},
+				{"source_id": "a", "counter": {"name": "c"}}
+			]}`)
+
+			Expect(recorder.Code).To(Equal(http.StatusRequestEntityTooLarge))
+			Expect(spySetter.envelopes).ToNot(Receive())
+		})
+
+		It("rejects the whole batch if any envelope exceeds the limits", func() {
+			post(`{"batch": [
+				{"source_id": "a", "counter": {"name": "c"}},
+				{"source_id": "a", "counter": {"name": "c"}, "tags": {"x": "1", "y": "2"}}
+			]}`)
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("envelope 1: tags exceed the ingress limits"))
+			Expect(spySetter.envelopes).ToNot(Receive())
+			Expect(metricClient.GetDelta("ingress")).To(BeZero())
+			Expect(metricClient.GetDelta("rejected_requests")).To(Equal(uint64(1)))
+		})
+	})
+})
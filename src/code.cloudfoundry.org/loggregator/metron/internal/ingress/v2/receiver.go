@@ -6,30 +6,47 @@ import (
 	"code.cloudfoundry.org/loggregator/metricemitter"
 
 	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 )
 
 type DataSetter interface {
 	Set(e *v2.Envelope)
 }
 
+// Limiter applies the ingress limits to batches and envelopes received
+// over the v2 API.
+type Limiter interface {
+	BatchAllowed(size int) bool
+	Envelope(e *v2.Envelope) []*v2.Envelope
+}
+
 // MetricClient creates new CounterMetrics to be emitted periodically.
 type MetricClient interface {
 	NewCounter(name string, opts ...metricemitter.MetricOption) *metricemitter.Counter
 }
 
 type Receiver struct {
-	dataSetter    DataSetter
-	ingressMetric *metricemitter.Counter
+	dataSetter     DataSetter
+	limiter        Limiter
+	ingressMetric  *metricemitter.Counter
+	rejectedMetric *metricemitter.Counter
 }
 
-func NewReceiver(dataSetter DataSetter, metricClient MetricClient) *Receiver {
+func NewReceiver(dataSetter DataSetter, limiter Limiter, metricClient MetricClient) *Receiver {
 	ingressMetric := metricClient.NewCounter("ingress",
 		metricemitter.WithVersion(2, 0),
 	)
+	rejectedMetric := metricClient.NewCounter("rejected_requests",
+		metricemitter.WithVersion(2, 0),
+	)
 
 	return &Receiver{
-		dataSetter:    dataSetter,
-		ingressMetric: ingressMetric,
+		dataSetter:     dataSetter,
+		limiter:        limiter,
+		ingressMetric:  ingressMetric,
+		rejectedMetric: rejectedMetric,
 	}
 }
 
@@ -41,7 +58,7 @@ func (s *Receiver) Sender(sender v2.Ingress_SenderServer) error {
 			return err
 		}
 
-		s.dataSetter.Set(e)
+		s.set(e)
 		// metric-documentation-v2: (loggregator.metron.ingress) The number of
 		// received messages over Metrons V2 gRPC API.
 		s.ingressMetric.Increment(1)
@@ -58,8 +75,16 @@ func (s *Receiver) BatchSender(sender v2.Ingress_BatchSenderServer) error {
 			return err
 		}
 
+		if !s.limiter.BatchAllowed(len(envelopes.Batch)) {
+			// metric-documentation-v2: (loggregator.metron.rejected_requests)
+			// The number of batches to Metrons V2 gRPC API rejected as too
+			// large.
+			s.rejectedMetric.Increment(1)
+			return grpc.Errorf(codes.ResourceExhausted, "batch of %d envelopes exceeds the maximum batch size", len(envelopes.Batch))
+		}
+
 		for _, e := range envelopes.Batch {
-			s.dataSetter.Set(e)
+			s.set(e)
 		}
 
 		// metric-documentation-v2: (loggregator.metron.ingress) The number of
@@ -69,3 +94,9 @@ func (s *Receiver) BatchSender(sender v2.Ingress_BatchSenderServer) error {
 
 	return nil
 }
+
+func (s *Receiver) set(e *v2.Envelope) {
+	for _, limited := range s.limiter.Envelope(e) {
+		s.dataSetter.Set(limited)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 
 	"code.cloudfoundry.org/loggregator/metricemitter/testhelper"
+	"code.cloudfoundry.org/loggregator/plumbing/limits"
 
 	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
 
@@ -12,19 +13,29 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 )
 
 var _ = Describe("Receiver", func() {
 	var (
 		rx *ingress.Receiver
 
-		spySetter *SpySetter
+		spySetter    *SpySetter
+		metricClient *testhelper.SpyMetricClient
+		limitsConfig limits.Config
 	)
 
 	BeforeEach(func() {
 		spySetter = NewSpySetter()
+		limitsConfig = limits.Config{}
+	})
+
+	JustBeforeEach(func() {
+		metricClient = testhelper.NewMetricClient()
+		limiter := limits.New(limitsConfig, metricClient)
 
-		rx = ingress.NewReceiver(spySetter, testhelper.NewMetricClient())
+		rx = ingress.NewReceiver(spySetter, limiter, metricClient)
 	})
 
 	Describe("Sender()", func() {
@@ -66,6 +77,50 @@ var _ = Describe("Receiver", func() {
 
 			Expect(err).To(HaveOccurred())
 		})
+
+		Context("with ingress limits", func() {
+			BeforeEach(func() {
+				limitsConfig = limits.Config{
+					MaxTagCount:        1,
+					MaxLogPayloadBytes: 5,
+					SplitOversizedLogs: true,
+				}
+			})
+
+			It("drops envelopes that exceed a limit", func() {
+				spySender.recvResponses <- SenderRecvResponse{
+					envelope: &v2.Envelope{
+						SourceId: "some-id",
+						Tags:     map[string]string{"a": "1", "b": "2"},
+					},
+				}
+				spySender.recvResponses <- SenderRecvResponse{
+					err: io.EOF,
+				}
+
+				rx.Sender(spySender)
+
+				Expect(spySetter.envelopes).To(BeEmpty())
+			})
+
+			It("splits oversized logs", func() {
+				spySender.recvResponses <- SenderRecvResponse{
+					envelope: &v2.Envelope{
+						SourceId: "some-id",
+						Message: &v2.Envelope_Log{
+							Log: &v2.Log{Payload: []byte("0123456789")},
+						},
+					},
+				}
+				spySender.recvResponses <- SenderRecvResponse{
+					err: io.EOF,
+				}
+
+				rx.Sender(spySender)
+
+				Expect(spySetter.envelopes).To(HaveLen(2))
+			})
+		})
 	})
 
 	Describe("BatchSender()", func() {
@@ -94,6 +149,31 @@ var _ = Describe("Receiver", func() {
 			Expect(spySetter.envelopes).Should(HaveLen(5))
 		})
 
+		Context("with a max batch size", func() {
+			BeforeEach(func() {
+				limitsConfig = limits.Config{MaxBatchSize: 2}
+			})
+
+			It("rejects batches that are too large", func() {
+				e := &v2.Envelope{
+					SourceId: "some-id",
+				}
+
+				spyBatchSender.recvResponses <- BatchSenderRecvResponse{
+					envelopes: []*v2.Envelope{e, e},
+				}
+				spyBatchSender.recvResponses <- BatchSenderRecvResponse{
+					envelopes: []*v2.Envelope{e, e, e},
+				}
+
+				err := rx.BatchSender(spyBatchSender)
+
+				Expect(grpc.Code(err)).To(Equal(codes.ResourceExhausted))
+				Expect(spySetter.envelopes).Should(HaveLen(2))
+				Expect(metricClient.GetDelta("rejected_requests")).To(Equal(uint64(1)))
+			})
+		})
+
 		It("returns an error when receive fails", func() {
 			spyBatchSender.recvResponses <- BatchSenderRecvResponse{
 				err: errors.New("error occurred"),
@@ -0,0 +1,241 @@
+package redactor
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/metricemitter"
+
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+)
+
+type DataSetter interface {
+	Set(e *v2.Envelope)
+}
+
+// MetricClient creates a counter per redaction rule of the matches it
+// masked.
+type MetricClient interface {
+	NewCounter(name string, opts ...metricemitter.MetricOption) *metricemitter.Counter
+}
+
+// Rule masks every match of Pattern with Replacement. Replacement may refer
+// to capture groups of Pattern, e.g. "${1}****".
+type Rule struct {
+	Name        string
+	Pattern     string
+	Replacement string
+}
+
+type compiledRule struct {
+	re          *regexp.Regexp
+	replacement []byte
+	matches     *metricemitter.Counter
+}
+
+// Redactor is a DataSetter that masks sensitive data in log payloads (and
+// optionally tag values) before writing envelopes to the next DataSetter.
+type Redactor struct {
+	setter       DataSetter
+	redactTags   bool
+	metricClient MetricClient
+
+	rules atomic.Value
+
+	// path and lastMod are the rules file and its modification time when
+	// the rules were last loaded from it.
+	path    string
+	lastMod time.Time
+
+	mu       sync.Mutex
+	counters map[string]*metricemitter.Counter
+}
+
+func New(setter DataSetter, rules []Rule, redactTags bool, metricClient MetricClient) (*Redactor, error) {
+	r := &Redactor{
+		setter:       setter,
+		redactTags:   redactTags,
+		metricClient: metricClient,
+		counters:     make(map[string]*metricemitter.Counter),
+	}
+
+	if err := r.Update(rules); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// NewFromFile returns a Redactor with the rules of the file at the given
+// path. Watch reloads them when the file changes.
+func NewFromFile(setter DataSetter, path string, redactTags bool, metricClient MetricClient) (*Redactor, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := LoadRules(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := New(setter, rules, redactTags, metricClient)
+	if err != nil {
+		return nil, err
+	}
+	r.path = path
+	r.lastMod = info.ModTime()
+
+	return r, nil
+}
+
+// Update replaces the set of rules. If any of the rules are invalid, the
+// existing rules are kept and an error is returned.
+func (r *Redactor) Update(rules []Rule) error {
+	var compiled []compiledRule
+	for _, rule := range rules {
+		if rule.Name == "" {
+			return fmt.Errorf("redaction rule with pattern %q has no name", rule.Pattern)
+		}
+
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern for redaction rule %s: %s", rule.Name, err)
+		}
+
+		compiled = append(compiled, compiledRule{
+			re:          re,
+			replacement: []byte(rule.Replacement),
+			matches:     r.counter(rule.Name),
+		})
+	}
+
+	r.rules.Store(compiled)
+
+	return nil
+}
+
+// Watch polls the rules file of a Redactor created by NewFromFile and
+// updates the rules when the file changes. It blocks forever.
+func (r *Redactor) Watch(interval time.Duration) {
+	for range time.Tick(interval) {
+		info, err := os.Stat(r.path)
+		if err != nil {
+			log.Printf("Failed to stat redaction rules file: %s", err)
+			continue
+		}
+
+		if !info.ModTime().After(r.lastMod) {
+			continue
+		}
+		r.lastMod = info.ModTime()
+
+		rules, err := LoadRules(r.path)
+		if err != nil {
+			log.Printf("Failed to reload redaction rules: %s", err)
+			continue
+		}
+
+		if err := r.Update(rules); err != nil {
+			log.Printf("Failed to reload redaction rules: %s", err)
+			continue
+		}
+
+		log.Printf("Reloaded %d redaction rules", len(rules))
+	}
+}
+
+func (r *Redactor) Set(e *v2.Envelope) {
+	rules := r.rules.Load().([]compiledRule)
+	if len(rules) == 0 {
+		r.setter.Set(e)
+		return
+	}
+
+	if l := e.GetLog(); l != nil {
+		l.Payload = redact(rules, l.Payload)
+	}
+
+	if r.redactTags {
+		for k, v := range e.Tags {
+			e.Tags[k] = string(redact(rules, []byte(v)))
+		}
+
+		for k, v := range e.DeprecatedTags {
+			t, ok := v.GetData().(*v2.Value_Text)
+			if !ok {
+				continue
+			}
+
+			e.DeprecatedTags[k] = &v2.Value{
+				Data: &v2.Value_Text{
+					Text: string(redact(rules, []byte(t.Text))),
+				},
+			}
+		}
+	}
+
+	r.setter.Set(e)
+}
+
+func (r *Redactor) counter(name string) *metricemitter.Counter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.counters[name]
+	if !ok {
+		c = r.metricClient.NewCounter("redacted",
+			metricemitter.WithVersion(2, 0),
+			metricemitter.WithTags(map[string]string{"rule": name}),
+		)
+		r.counters[name] = c
+	}
+
+	return c
+}
+
+// LoadRules reads a JSON list of rules from the given path.
+func LoadRules(path string) ([]Rule, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var rules []Rule
+	if err := json.NewDecoder(file).Decode(&rules); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func redact(rules []compiledRule, src []byte) []byte {
+	for _, rule := range rules {
+		matches := rule.re.FindAllSubmatchIndex(src, -1)
+		if len(matches) == 0 {
+			continue
+		}
+
+		// metric-documentation-v2: (loggregator.metron.redacted) Number of
+		// matches masked by each redaction rule.
+		rule.matches.Increment(uint64(len(matches)))
+
+		var dst []byte
+		last := 0
+		for _, m := range matches {
+			dst = append(dst, src[last:m[0]]...)
+			dst = rule.re.Expand(dst, rule.replacement, src, m)
+			last = m[1]
+		}
+		src = append(dst, src[last:]...)
+	}
+
+	return src
+}
@@ -0,0 +1,239 @@
+package redactor_test
+
+import (
+	"io/ioutil"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/metricemitter/testhelper"
+	"code.cloudfoundry.org/loggregator/metron/internal/redactor"
+
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Redactor", func() {
+	var (
+		spySetter    *SpySetter
+		metricClient *testhelper.SpyMetricClient
+		rules        []redactor.Rule
+	)
+
+	BeforeEach(func() {
+		spySetter = NewSpySetter()
+		metricClient = testhelper.NewMetricClient()
+		rules = []redactor.Rule{
+			{
+				Name:        "card",
+				Pattern:     `\b\d{12}(\d{4})\b`,
+				Replacement: "************${1}",
+			},
+			{
+				Name:        "password",
+				Pattern:     `(password=)\S+`,
+				Replacement: "${1}[REDACTED]",
+			},
+		}
+	})
+
+	It("masks matches in log payloads", func() {
+		r, err := redactor.New(spySetter, rules, false, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+
+		r.Set(buildLog("card 1234567812345678 and password=hunter2 end"))
+
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(string(e.GetLog().Payload)).To(Equal(
+			"card ************5678 and password=[REDACTED] end",
+		))
+	})
+
+	It("counts matches per rule", func() {
+		r, err := redactor.New(spySetter, rules, false, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+
+		r.Set(buildLog("password=a password=b"))
+
+		var passwordMatches uint64
+		for _, e := range metricClient.GetEnvelopes("redacted") {
+			if e.DeprecatedTags["rule"].GetText() == "password" {
+				passwordMatches = e.GetCounter().GetDelta()
+			}
+		}
+		Expect(passwordMatches).To(Equal(uint64(2)))
+	})
+
+	It("passes through envelopes without matches", func() {
+		r, err := redactor.New(spySetter, rules, false, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+
+		r.Set(buildLog("nothing to see"))
+
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(string(e.GetLog().Payload)).To(Equal("nothing to see"))
+	})
+
+	It("does not touch tags by default", func() {
+		r, err := redactor.New(spySetter, rules, false, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+
+		e := buildLog("")
+		e.Tags = map[string]string{"url": "password=hunter2"}
+		r.Set(e)
+
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(e.Tags["url"]).To(Equal("password=hunter2"))
+	})
+
+	It("masks tag values when configured", func() {
+		r, err := redactor.New(spySetter, rules, true, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+
+		e := &v2.Envelope{
+			Tags: map[string]string{"url": "password=hunter2"},
+			DeprecatedTags: map[string]*v2.Value{
+				"url": {Data: &v2.Value_Text{Text: "password=hunter2"}},
+				"num": {Data: &v2.Value_Integer{Integer: 99}},
+			},
+			Message: &v2.Envelope_Counter{
+				Counter: &v2.Counter{Name: "some-counter"},
+			},
+		}
+		r.Set(e)
+
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(e.Tags["url"]).To(Equal("password=[REDACTED]"))
+		Expect(e.DeprecatedTags["url"].GetText()).To(Equal("password=[REDACTED]"))
+		Expect(e.DeprecatedTags["num"].GetInteger()).To(Equal(int64(99)))
+	})
+
+	It("returns an error for invalid rules", func() {
+		_, err := redactor.New(spySetter, []redactor.Rule{
+			{Name: "bad", Pattern: "("},
+		}, false, metricClient)
+		Expect(err).To(HaveOccurred())
+
+		_, err = redactor.New(spySetter, []redactor.Rule{
+			{Pattern: "a"},
+		}, false, metricClient)
+		Expect(err).To(HaveOccurred())
+	})
+
+	Describe("Update()", func() {
+		It("replaces the rules", func() {
+			r, err := redactor.New(spySetter, rules, false, metricClient)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = r.Update([]redactor.Rule{
+				{Name: "token", Pattern: "token-[a-z]+", Replacement: "token-***"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			r.Set(buildLog("password=x token-abc"))
+
+			var e *v2.Envelope
+			Expect(spySetter.envelopes).To(Receive(&e))
+			Expect(string(e.GetLog().Payload)).To(Equal("password=x token-***"))
+		})
+
+		It("keeps the existing rules when given invalid rules", func() {
+			r, err := redactor.New(spySetter, rules, false, metricClient)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = r.Update([]redactor.Rule{{Name: "bad", Pattern: "("}})
+			Expect(err).To(HaveOccurred())
+
+			r.Set(buildLog("password=x"))
+
+			var e *v2.Envelope
+			Expect(spySetter.envelopes).To(Receive(&e))
+			Expect(string(e.GetLog().Payload)).To(Equal("password=[REDACTED]"))
+		})
+	})
+
+	Describe("Watch()", func() {
+		var rulesFile string
+
+		BeforeEach(func() {
+			f, err := ioutil.TempFile("", "redaction-rules")
+			Expect(err).ToNot(HaveOccurred())
+			rulesFile = f.Name()
+
+			_, err = f.WriteString(`[{"Name": "password", "Pattern": "(password=)\\S+", "Replacement": "${1}***"}]`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Close()).To(Succeed())
+		})
+
+		AfterEach(func() {
+			os.Remove(rulesFile)
+		})
+
+		It("loads rules from a file", func() {
+			loaded, err := redactor.LoadRules(rulesFile)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loaded).To(Equal([]redactor.Rule{
+				{Name: "password", Pattern: `(password=)\S+`, Replacement: "${1}***"},
+			}))
+		})
+
+		It("returns an error when the file does not exist", func() {
+			_, err := redactor.NewFromFile(spySetter, rulesFile+"-missing", false, metricClient)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("reloads rules when the file changes", func() {
+			r, err := redactor.NewFromFile(spySetter, rulesFile, false, metricClient)
+			Expect(err).ToNot(HaveOccurred())
+
+			r.Set(buildLog("password=hunter2"))
+			var e *v2.Envelope
+			Expect(spySetter.envelopes).To(Receive(&e))
+			Expect(string(e.GetLog().Payload)).To(Equal("password=***"))
+
+			go r.Watch(10 * time.Millisecond)
+
+			err = ioutil.WriteFile(rulesFile, []byte(`[{"Name": "secret", "Pattern": "secret", "Replacement": "***"}]`), 0644)
+			Expect(err).ToNot(HaveOccurred())
+			future := time.Now().Add(time.Minute)
+			Expect(os.Chtimes(rulesFile, future, future)).To(Succeed())
+
+			Eventually(func() string {
+				r.Set(buildLog("secret"))
+
+				var e *v2.Envelope
+				Expect(spySetter.envelopes).To(Receive(&e))
+				return string(e.GetLog().Payload)
+			}).Should(Equal("***"))
+		})
+	})
+})
+
+func buildLog(payload string) *v2.Envelope {
+	return &v2.Envelope{
+		SourceId: "some-id",
+		Message: &v2.Envelope_Log{
+			Log: &v2.Log{
+				Payload: []byte(payload),
+				Type:    v2.Log_OUT,
+			},
+		},
+	}
+}
+
+type SpySetter struct {
+	envelopes chan *v2.Envelope
+}
+
+func NewSpySetter() *SpySetter {
+	return &SpySetter{
+		envelopes: make(chan *v2.Envelope, 100),
+	}
+}
+
+func (s *SpySetter) Set(e *v2.Envelope) {
+	s.envelopes <- e
+}
@@ -0,0 +1,253 @@
+package stitcher
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+	"unicode"
+
+	"code.cloudfoundry.org/loggregator/metricemitter"
+	"code.cloudfoundry.org/loggregator/plumbing/limits"
+
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+)
+
+type DataSetter interface {
+	Set(e *v2.Envelope)
+}
+
+// MetricClient creates the counter of lines joined into stitched
+// envelopes.
+type MetricClient interface {
+	NewCounter(name string, opts ...metricemitter.MetricOption) *metricemitter.Counter
+}
+
+// Config determines how lines are grouped into records.
+type Config struct {
+	// StartPatterns are regular expressions that match the first line of a
+	// record. When set, any line that does not match one of them is treated
+	// as a continuation of the previous line.
+	StartPatterns []string
+
+	// IndentedContinuation treats any line starting with whitespace as a
+	// continuation of the previous line.
+	IndentedContinuation bool
+
+	MaxLines     int
+	MaxBytes     int
+	FlushTimeout time.Duration
+}
+
+type recordKey struct {
+	sourceID   string
+	instanceID string
+	logType    v2.Log_Type
+}
+
+type record struct {
+	envelope *v2.Envelope
+	lines    int
+	created  time.Time
+}
+
+// Stitcher is a DataSetter that buffers consecutive log envelopes from the
+// same source, instance and stream and writes them to the next DataSetter
+// as a single envelope. Non-log envelopes and the parts of logs split by
+// the ingress limits are passed through untouched.
+type Stitcher struct {
+	setter        DataSetter
+	startPatterns []*regexp.Regexp
+	indented      bool
+	maxLines      int
+	maxBytes      int
+	flushTimeout  time.Duration
+
+	mu      sync.Mutex
+	pending map[recordKey]*record
+	stopped bool
+	done    chan struct{}
+
+	stitchedMetric *metricemitter.Counter
+}
+
+func New(setter DataSetter, c Config, metricClient MetricClient) (*Stitcher, error) {
+	if len(c.StartPatterns) == 0 && !c.IndentedContinuation {
+		return nil, fmt.Errorf("either start patterns or indented continuation must be configured")
+	}
+
+	if c.MaxLines < 1 || c.MaxBytes < 1 || c.FlushTimeout <= 0 {
+		return nil, fmt.Errorf("max lines, max bytes and flush timeout must be positive")
+	}
+
+	var patterns []*regexp.Regexp
+	for _, p := range c.StartPatterns {
+		r, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start pattern %q: %s", p, err)
+		}
+		patterns = append(patterns, r)
+	}
+
+	stitchedMetric := metricClient.NewCounter("stitched",
+		metricemitter.WithVersion(2, 0),
+	)
+
+	return &Stitcher{
+		setter:         setter,
+		startPatterns:  patterns,
+		indented:       c.IndentedContinuation,
+		maxLines:       c.MaxLines,
+		maxBytes:       c.MaxBytes,
+		flushTimeout:   c.FlushTimeout,
+		pending:        make(map[recordKey]*record),
+		done:           make(chan struct{}),
+		stitchedMetric: stitchedMetric,
+	}, nil
+}
+
+// Start flushes records that have been pending for longer than the flush
+// timeout. It returns when the Stitcher is stopped.
+func (s *Stitcher) Start() {
+	ticker := time.NewTicker(s.flushTimeout / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.flushExpired()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Stop flushes every pending record. Envelopes set afterwards are passed
+// through.
+func (s *Stitcher) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return
+	}
+	s.stopped = true
+	close(s.done)
+
+	for key, r := range s.pending {
+		delete(s.pending, key)
+		s.setter.Set(r.envelope)
+	}
+}
+
+func (s *Stitcher) Set(e *v2.Envelope) {
+	l := e.GetLog()
+	if l == nil {
+		s.setter.Set(e)
+		return
+	}
+
+	key := recordKey{
+		sourceID:   e.SourceId,
+		instanceID: e.InstanceId,
+		logType:    l.Type,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.pending[key]
+	if _, isPart := e.Tags[limits.PartTag]; isPart || s.stopped {
+		if ok {
+			delete(s.pending, key)
+			s.setter.Set(r.envelope)
+		}
+		s.setter.Set(e)
+		return
+	}
+
+	continuation := s.isContinuation(l.Payload)
+	if ok && continuation && s.fits(r, l.Payload) {
+		r.append(l.Payload)
+		// metric-documentation-v2: (loggregator.metron.stitched) Number of
+		// log lines joined onto a preceding log envelope.
+		s.stitchedMetric.Increment(1)
+
+		if r.lines >= s.maxLines {
+			delete(s.pending, key)
+			s.setter.Set(r.envelope)
+		}
+		return
+	}
+
+	if ok {
+		delete(s.pending, key)
+		s.setter.Set(r.envelope)
+	}
+
+	// A continuation without a record to join, e.g. one that did not fit,
+	// is written right away rather than starting a record.
+	if continuation || s.maxLines == 1 {
+		s.setter.Set(e)
+		return
+	}
+
+	s.pending[key] = newRecord(e)
+}
+
+func (s *Stitcher) flushExpired() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, r := range s.pending {
+		if time.Since(r.created) < s.flushTimeout {
+			continue
+		}
+
+		delete(s.pending, key)
+		s.setter.Set(r.envelope)
+	}
+}
+
+func (s *Stitcher) isContinuation(payload []byte) bool {
+	if s.indented && len(payload) > 0 && unicode.IsSpace(rune(payload[0])) {
+		return true
+	}
+
+	if len(s.startPatterns) == 0 {
+		return false
+	}
+
+	for _, p := range s.startPatterns {
+		if p.Match(payload) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *Stitcher) fits(r *record, payload []byte) bool {
+	return len(r.envelope.GetLog().Payload)+1+len(payload) <= s.maxBytes
+}
+
+func newRecord(e *v2.Envelope) *record {
+	// Copy the payload so appending continuation lines never writes into
+	// memory shared with the original envelope.
+	payload := make([]byte, len(e.GetLog().Payload))
+	copy(payload, e.GetLog().Payload)
+	e.GetLog().Payload = payload
+
+	return &record{
+		envelope: e,
+		lines:    1,
+		created:  time.Now(),
+	}
+}
+
+func (r *record) append(payload []byte) {
+	l := r.envelope.GetLog()
+	l.Payload = append(l.Payload, '\n')
+	l.Payload = append(l.Payload, payload...)
+	r.lines++
+}
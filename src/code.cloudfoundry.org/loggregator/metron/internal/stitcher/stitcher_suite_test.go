@@ -0,0 +1,13 @@
+package stitcher_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestStitcher(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Stitcher Suite")
+}
@@ -0,0 +1,257 @@
+package stitcher_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/loggregator/metricemitter/testhelper"
+	"code.cloudfoundry.org/loggregator/metron/internal/stitcher"
+	"code.cloudfoundry.org/loggregator/plumbing/limits"
+
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Stitcher", func() {
+	var (
+		spySetter    *SpySetter
+		metricClient *testhelper.SpyMetricClient
+		config       stitcher.Config
+	)
+
+	BeforeEach(func() {
+		spySetter = NewSpySetter()
+		metricClient = testhelper.NewMetricClient()
+		config = stitcher.Config{
+			IndentedContinuation: true,
+			MaxLines:             100,
+			MaxBytes:             1024,
+			FlushTimeout:         time.Minute,
+		}
+	})
+
+	It("passes through non-log envelopes", func() {
+		s, err := stitcher.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+
+		e := &v2.Envelope{
+			SourceId: "some-id",
+			Message: &v2.Envelope_Counter{
+				Counter: &v2.Counter{Name: "some-counter"},
+			},
+		}
+		s.Set(e)
+
+		Expect(spySetter.envelopes).To(Receive(Equal(e)))
+	})
+
+	It("joins indented lines onto the preceding line", func() {
+		s, err := stitcher.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+
+		s.Set(buildLog("some-id", "0", "java.lang.NullPointerException"))
+		s.Set(buildLog("some-id", "0", "\tat Foo.bar(Foo.java:10)"))
+		s.Set(buildLog("some-id", "0", "\tat Foo.main(Foo.java:3)"))
+		Expect(spySetter.envelopes).ToNot(Receive())
+
+		s.Set(buildLog("some-id", "0", "next record"))
+
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(string(e.GetLog().Payload)).To(Equal(
+			"java.lang.NullPointerException\n\tat Foo.bar(Foo.java:10)\n\tat Foo.main(Foo.java:3)",
+		))
+		Expect(metricClient.GetDelta("stitched")).To(Equal(uint64(2)))
+	})
+
+	It("joins lines that do not match a start pattern", func() {
+		config.IndentedContinuation = false
+		config.StartPatterns = []string{`^\d{4}-\d{2}-\d{2}`}
+		s, err := stitcher.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+
+		s.Set(buildLog("some-id", "0", "2017-08-01 ERROR boom"))
+		s.Set(buildLog("some-id", "0", "Caused by: something else"))
+		s.Set(buildLog("some-id", "0", "2017-08-01 INFO fine"))
+
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(string(e.GetLog().Payload)).To(Equal(
+			"2017-08-01 ERROR boom\nCaused by: something else",
+		))
+		Expect(spySetter.envelopes).ToNot(Receive())
+	})
+
+	It("keeps records from different sources, instances and streams apart", func() {
+		s, err := stitcher.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+
+		s.Set(buildLog("some-id", "0", "first"))
+		s.Set(buildLog("some-id", "1", "other instance"))
+		s.Set(buildLog("other-id", "0", "other source"))
+
+		e := buildLog("some-id", "0", "other stream")
+		e.GetLog().Type = v2.Log_ERR
+		s.Set(e)
+
+		Expect(spySetter.envelopes).ToNot(Receive())
+
+		s.Set(buildLog("some-id", "0", "second"))
+
+		var flushed *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&flushed))
+		Expect(string(flushed.GetLog().Payload)).To(Equal("first"))
+	})
+
+	It("flushes a record once it reaches the max lines", func() {
+		config.MaxLines = 3
+		s, err := stitcher.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+
+		s.Set(buildLog("some-id", "0", "a"))
+		s.Set(buildLog("some-id", "0", " b"))
+		s.Set(buildLog("some-id", "0", " c"))
+
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(string(e.GetLog().Payload)).To(Equal("a\n b\n c"))
+	})
+
+	It("writes a line that would exceed the max bytes on its own", func() {
+		config.MaxBytes = 10
+		s, err := stitcher.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+
+		s.Set(buildLog("some-id", "0", "abcde"))
+		s.Set(buildLog("some-id", "0", " fghij"))
+
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(string(e.GetLog().Payload)).To(Equal("abcde"))
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(string(e.GetLog().Payload)).To(Equal(" fghij"))
+	})
+
+	It("writes continuations without a preceding line right away", func() {
+		config.IndentedContinuation = false
+		config.StartPatterns = []string{`^\d{4}-\d{2}-\d{2}`}
+		s, err := stitcher.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+
+		s.Set(buildLog("some-id", "0", "Caused by: something"))
+
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(string(e.GetLog().Payload)).To(Equal("Caused by: something"))
+	})
+
+	It("passes through the parts of split logs", func() {
+		s, err := stitcher.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+
+		s.Set(buildLog("some-id", "0", "first"))
+		part := buildLog("some-id", "0", " part")
+		part.Tags = map[string]string{limits.PartTag: "1/2"}
+		s.Set(part)
+
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(string(e.GetLog().Payload)).To(Equal("first"))
+		Expect(spySetter.envelopes).To(Receive(Equal(part)))
+		Expect(metricClient.GetDelta("stitched")).To(BeZero())
+	})
+
+	It("flushes pending records after the flush timeout", func() {
+		config.FlushTimeout = 50 * time.Millisecond
+		s, err := stitcher.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+		go s.Start()
+
+		s.Set(buildLog("some-id", "0", "a"))
+		s.Set(buildLog("some-id", "0", " b"))
+
+		var e *v2.Envelope
+		Eventually(spySetter.envelopes).Should(Receive(&e))
+		Expect(string(e.GetLog().Payload)).To(Equal("a\n b"))
+	})
+
+	It("flushes pending records and passes through envelopes once stopped", func() {
+		s, err := stitcher.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+		done := make(chan struct{})
+		go func() {
+			s.Start()
+			close(done)
+		}()
+
+		s.Set(buildLog("some-id", "0", "a"))
+		s.Set(buildLog("some-id", "0", " b"))
+		s.Stop()
+
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(string(e.GetLog().Payload)).To(Equal("a\n b"))
+		Eventually(done).Should(BeClosed())
+
+		s.Set(buildLog("some-id", "0", "c"))
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(string(e.GetLog().Payload)).To(Equal("c"))
+	})
+
+	It("does not modify the payload of the original envelope", func() {
+		s, err := stitcher.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+
+		payload := make([]byte, 1, 64)
+		payload[0] = 'a'
+		s.Set(&v2.Envelope{
+			SourceId: "some-id",
+			Message: &v2.Envelope_Log{
+				Log: &v2.Log{Payload: payload},
+			},
+		})
+		s.Set(buildLog("some-id", "", " b"))
+
+		Expect(payload[:2]).To(Equal([]byte{'a', 0}))
+	})
+
+	It("returns an error for an invalid start pattern", func() {
+		config.StartPatterns = []string{"("}
+		_, err := stitcher.New(spySetter, config, metricClient)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("returns an error when no grouping rule is configured", func() {
+		config.IndentedContinuation = false
+		_, err := stitcher.New(spySetter, config, metricClient)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+func buildLog(sourceID, instanceID, payload string) *v2.Envelope {
+	return &v2.Envelope{
+		SourceId:   sourceID,
+		InstanceId: instanceID,
+		Message: &v2.Envelope_Log{
+			Log: &v2.Log{
+				Payload: []byte(payload),
+				Type:    v2.Log_OUT,
+			},
+		},
+	}
+}
+
+type SpySetter struct {
+	envelopes chan *v2.Envelope
+}
+
+func NewSpySetter() *SpySetter {
+	return &SpySetter{
+		envelopes: make(chan *v2.Envelope, 100),
+	}
+}
+
+func (s *SpySetter) Set(e *v2.Envelope) {
+	s.envelopes <- e
+}
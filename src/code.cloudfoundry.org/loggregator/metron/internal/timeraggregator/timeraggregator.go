@@ -0,0 +1,317 @@
+package timeraggregator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/metricemitter"
+
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+)
+
+type DataSetter interface {
+	Set(e *v2.Envelope)
+}
+
+// MetricClient creates the counter of timers folded into histograms.
+type MetricClient interface {
+	NewCounter(name string, opts ...metricemitter.MetricOption) *metricemitter.Counter
+}
+
+// Config determines which timers are aggregated and how.
+type Config struct {
+	// Names and SourceIDs select the timers that are aggregated. A timer is
+	// aggregated if either its name or its source ID is listed.
+	Names     []string
+	SourceIDs []string
+
+	// GroupTags are the tags that are kept on the aggregated envelopes.
+	// Timers are aggregated per source ID, instance ID, name and the values
+	// of these tags. All other tags are dropped.
+	GroupTags []string
+
+	// Buckets are the upper bounds of the histogram buckets in
+	// milliseconds. They must be positive and increasing. A bucket for
+	// durations above the highest bound is always added.
+	Buckets []float64
+
+	Interval time.Duration
+
+	// MaxSeries limits the number of histograms per interval. Timers that
+	// would start a new histogram beyond it are passed through unchanged.
+	MaxSeries int
+}
+
+// ExponentialBuckets returns count bucket bounds starting at start with each
+// bound factor times the previous one.
+func ExponentialBuckets(start, factor float64, count int) ([]float64, error) {
+	if start <= 0 || factor <= 1 || count < 1 {
+		return nil, fmt.Errorf("exponential buckets require a positive start, a factor above 1 and a positive count")
+	}
+
+	buckets := make([]float64, count)
+	for i := range buckets {
+		buckets[i] = start
+		start *= factor
+	}
+
+	return buckets, nil
+}
+
+type seriesKey struct {
+	sourceID   string
+	instanceID string
+	name       string
+	tags       string
+}
+
+type histogram struct {
+	tags     map[string]string
+	count    uint64
+	sum      float64
+	min      float64
+	max      float64
+	buckets  []uint64
+	lastStop int64
+}
+
+// TimerAggregator is a DataSetter that folds selected timer envelopes into
+// a histogram per interval and writes a single gauge envelope per histogram
+// to the next DataSetter. The gauge has the count, sum, min and max of the
+// durations and the cumulative count of each bucket. Durations are in
+// milliseconds. All other envelopes are passed through untouched.
+type TimerAggregator struct {
+	setter    DataSetter
+	names     map[string]bool
+	sourceIDs map[string]bool
+	groupTags []string
+	buckets   []float64
+	interval  time.Duration
+	maxSeries int
+
+	mu     sync.Mutex
+	series map[seriesKey]*histogram
+
+	aggregatedMetric *metricemitter.Counter
+}
+
+func New(setter DataSetter, c Config, metricClient MetricClient) (*TimerAggregator, error) {
+	if len(c.Names) == 0 && len(c.SourceIDs) == 0 {
+		return nil, fmt.Errorf("either timer names or source IDs must be configured")
+	}
+
+	if c.Interval <= 0 || c.MaxSeries < 1 {
+		return nil, fmt.Errorf("interval and max series must be positive")
+	}
+
+	if len(c.Buckets) == 0 {
+		return nil, fmt.Errorf("at least one bucket must be configured")
+	}
+
+	for i, b := range c.Buckets {
+		if b <= 0 || (i > 0 && b <= c.Buckets[i-1]) {
+			return nil, fmt.Errorf("buckets must be positive and increasing: %v", c.Buckets)
+		}
+	}
+
+	groupTags := make([]string, len(c.GroupTags))
+	copy(groupTags, c.GroupTags)
+	sort.Strings(groupTags)
+
+	aggregatedMetric := metricClient.NewCounter("aggregated_timers",
+		metricemitter.WithVersion(2, 0),
+	)
+
+	return &TimerAggregator{
+		setter:           setter,
+		names:            toSet(c.Names),
+		sourceIDs:        toSet(c.SourceIDs),
+		groupTags:        groupTags,
+		buckets:          c.Buckets,
+		interval:         c.Interval,
+		maxSeries:        c.MaxSeries,
+		series:           make(map[seriesKey]*histogram),
+		aggregatedMetric: aggregatedMetric,
+	}, nil
+}
+
+// Start writes the histograms every interval. It blocks forever.
+func (a *TimerAggregator) Start() {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		a.Flush()
+	}
+}
+
+// Flush writes the histograms of the current interval and resets them.
+func (a *TimerAggregator) Flush() {
+	a.mu.Lock()
+	series := a.series
+	a.series = make(map[seriesKey]*histogram, len(series))
+	a.mu.Unlock()
+
+	for key, h := range series {
+		a.setter.Set(a.toEnvelope(key, h))
+	}
+}
+
+// Aggregates reports whether the envelope is a timer that is folded into a
+// histogram rather than passed through.
+func (a *TimerAggregator) Aggregates(e *v2.Envelope) bool {
+	t := e.GetTimer()
+	return t != nil && t.Stop >= t.Start && (a.names[t.Name] || a.sourceIDs[e.SourceId])
+}
+
+func (a *TimerAggregator) Set(e *v2.Envelope) {
+	if !a.Aggregates(e) {
+		a.setter.Set(e)
+		return
+	}
+
+	t := e.GetTimer()
+
+	tags := a.extractTags(e)
+	key := seriesKey{
+		sourceID:   e.SourceId,
+		instanceID: e.InstanceId,
+		name:       t.Name,
+		tags:       a.encodeTags(tags),
+	}
+	duration := float64(t.Stop-t.Start) / float64(time.Millisecond)
+
+	a.mu.Lock()
+	h, ok := a.series[key]
+	if !ok {
+		if len(a.series) >= a.maxSeries {
+			a.mu.Unlock()
+			a.setter.Set(e)
+			return
+		}
+
+		h = &histogram{
+			tags:    tags,
+			min:     duration,
+			max:     duration,
+			buckets: make([]uint64, len(a.buckets)+1),
+		}
+		a.series[key] = h
+	}
+	h.observe(a.buckets, duration, t.Stop)
+	a.mu.Unlock()
+
+	// metric-documentation-v2: (loggregator.metron.aggregated_timers) Number
+	// of timer envelopes folded into histograms.
+	a.aggregatedMetric.Increment(1)
+}
+
+// extractTags returns the values of the group tags of the envelope. Tags
+// take precedence over text deprecated tags.
+func (a *TimerAggregator) extractTags(e *v2.Envelope) map[string]string {
+	if len(a.groupTags) == 0 {
+		return nil
+	}
+
+	tags := make(map[string]string, len(a.groupTags))
+	for _, k := range a.groupTags {
+		if v, ok := e.GetTags()[k]; ok {
+			tags[k] = v
+			continue
+		}
+
+		if v, ok := e.GetDeprecatedTags()[k]; ok {
+			if t, ok := v.GetData().(*v2.Value_Text); ok {
+				tags[k] = t.Text
+			}
+		}
+	}
+
+	return tags
+}
+
+// encodeTags returns a unique string for the given group tag values.
+func (a *TimerAggregator) encodeTags(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	parts := make([]string, 0, len(a.groupTags))
+	for _, k := range a.groupTags {
+		v, ok := tags[k]
+		if !ok {
+			parts = append(parts, "")
+			continue
+		}
+		parts = append(parts, strconv.Quote(v))
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func (a *TimerAggregator) toEnvelope(key seriesKey, h *histogram) *v2.Envelope {
+	metrics := map[string]*v2.GaugeValue{
+		key.name + ".count": {Unit: "count", Value: float64(h.count)},
+		key.name + ".sum":   {Unit: "ms", Value: h.sum},
+		key.name + ".min":   {Unit: "ms", Value: h.min},
+		key.name + ".max":   {Unit: "ms", Value: h.max},
+	}
+
+	var cumulative uint64
+	for i, c := range h.buckets {
+		cumulative += c
+
+		bound := "inf"
+		if i < len(a.buckets) {
+			bound = strconv.FormatFloat(a.buckets[i], 'f', -1, 64)
+		}
+
+		metrics[key.name+".le_"+bound] = &v2.GaugeValue{
+			Unit:  "count",
+			Value: float64(cumulative),
+		}
+	}
+
+	return &v2.Envelope{
+		SourceId:   key.sourceID,
+		InstanceId: key.instanceID,
+		Timestamp:  h.lastStop,
+		Tags:       h.tags,
+		Message: &v2.Envelope_Gauge{
+			Gauge: &v2.Gauge{
+				Metrics: metrics,
+			},
+		},
+	}
+}
+
+func (h *histogram) observe(buckets []float64, duration float64, stop int64) {
+	h.count++
+	h.sum += duration
+	if duration < h.min {
+		h.min = duration
+	}
+	if duration > h.max {
+		h.max = duration
+	}
+	if stop > h.lastStop {
+		h.lastStop = stop
+	}
+
+	// SearchFloat64s returns the first bucket with a bound of at least the
+	// duration or len(buckets) for the overflow bucket.
+	h.buckets[sort.SearchFloat64s(buckets, duration)]++
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+
+	return set
+}
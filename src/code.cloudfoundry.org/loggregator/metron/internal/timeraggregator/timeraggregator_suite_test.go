@@ -0,0 +1,13 @@
+package timeraggregator_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestTimerAggregator(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "TimerAggregator Suite")
+}
@@ -0,0 +1,279 @@
+package timeraggregator_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/loggregator/metricemitter/testhelper"
+	"code.cloudfoundry.org/loggregator/metron/internal/timeraggregator"
+
+	"code.cloudfoundry.org/loggregator/plumbing/conversion"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+
+	"github.com/cloudfoundry/sonde-go/events"
+	"github.com/gogo/protobuf/proto"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TimerAggregator", func() {
+	var (
+		spySetter    *SpySetter
+		metricClient *testhelper.SpyMetricClient
+		config       timeraggregator.Config
+	)
+
+	BeforeEach(func() {
+		spySetter = NewSpySetter()
+		metricClient = testhelper.NewMetricClient()
+		config = timeraggregator.Config{
+			Names:     []string{"http"},
+			Buckets:   []float64{10, 100},
+			Interval:  time.Minute,
+			MaxSeries: 100,
+		}
+	})
+
+	It("passes through envelopes that are not selected timers", func() {
+		a, err := timeraggregator.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+
+		counter := &v2.Envelope{
+			SourceId: "some-id",
+			Message: &v2.Envelope_Counter{
+				Counter: &v2.Counter{Name: "http"},
+			},
+		}
+		timer := buildTimer("some-id", "other", 0, 5)
+		a.Set(counter)
+		a.Set(timer)
+
+		Expect(spySetter.envelopes).To(Receive(Equal(counter)))
+		Expect(spySetter.envelopes).To(Receive(Equal(timer)))
+	})
+
+	It("folds timers into a gauge per interval", func() {
+		a, err := timeraggregator.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+
+		a.Set(buildTimer("some-id", "http", 0, ms(5)))
+		a.Set(buildTimer("some-id", "http", ms(10), ms(60)))
+		a.Set(buildTimer("some-id", "http", ms(20), ms(520)))
+		Expect(spySetter.envelopes).ToNot(Receive())
+		Expect(metricClient.GetDelta("aggregated_timers")).To(Equal(uint64(3)))
+
+		a.Flush()
+
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(e.SourceId).To(Equal("some-id"))
+		Expect(e.InstanceId).To(Equal("0"))
+		Expect(e.Timestamp).To(Equal(ms(520)))
+		Expect(gaugeValues(e)).To(Equal(map[string]float64{
+			"http.count":  3,
+			"http.sum":    555,
+			"http.min":    5,
+			"http.max":    500,
+			"http.le_10":  1,
+			"http.le_100": 2,
+			"http.le_inf": 3,
+		}))
+
+		a.Flush()
+		Expect(spySetter.envelopes).ToNot(Receive())
+	})
+
+	It("reports which envelopes it aggregates", func() {
+		a, err := timeraggregator.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(a.Aggregates(buildTimer("some-id", "http", 0, 5))).To(BeTrue())
+		Expect(a.Aggregates(buildTimer("some-id", "other", 0, 5))).To(BeFalse())
+		Expect(a.Aggregates(buildTimer("some-id", "http", 5, 0))).To(BeFalse())
+	})
+
+	It("aggregates HttpStartStop events converted to v2", func() {
+		a, err := timeraggregator.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+
+		e := conversion.ToV2(&events.Envelope{
+			Origin:     proto.String("gorouter"),
+			Deployment: proto.String("cf"),
+			Job:        proto.String("router"),
+			EventType:  events.Envelope_HttpStartStop.Enum(),
+			HttpStartStop: &events.HttpStartStop{
+				StartTimestamp: proto.Int64(0),
+				StopTimestamp:  proto.Int64(ms(5)),
+				PeerType:       events.PeerType_Client.Enum(),
+				Method:         events.Method_GET.Enum(),
+				Uri:            proto.String("http://example.com"),
+				StatusCode:     proto.Int32(200),
+				ContentLength:  proto.Int64(10),
+			},
+		}, true)
+		Expect(a.Aggregates(e)).To(BeTrue())
+		a.Set(e)
+
+		a.Flush()
+		var gauge *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&gauge))
+		Expect(gauge.SourceId).To(Equal("cf/router"))
+		Expect(gaugeValues(gauge)).To(HaveKeyWithValue("http.count", 1.0))
+	})
+
+	It("aggregates timers of configured source IDs", func() {
+		config.Names = nil
+		config.SourceIDs = []string{"gorouter"}
+		a, err := timeraggregator.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+
+		a.Set(buildTimer("gorouter", "anything", 0, ms(1)))
+		Expect(spySetter.envelopes).ToNot(Receive())
+
+		a.Flush()
+		var e *v2.Envelope
+		Expect(spySetter.envelopes).To(Receive(&e))
+		Expect(gaugeValues(e)).To(HaveKeyWithValue("anything.count", 1.0))
+	})
+
+	It("groups by the configured tags and drops the others", func() {
+		config.GroupTags = []string{"status_code"}
+		a, err := timeraggregator.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, tags := range [][]string{{"200", "a"}, {"200", "b"}, {"500", "c"}} {
+			e := buildTimer("some-id", "http", 0, ms(1))
+			e.Tags = map[string]string{
+				"status_code": tags[0],
+				"request_id":  tags[1],
+			}
+			a.Set(e)
+		}
+
+		deprecated := buildTimer("some-id", "http", 0, ms(1))
+		deprecated.DeprecatedTags = map[string]*v2.Value{
+			"status_code": {Data: &v2.Value_Text{Text: "500"}},
+		}
+		a.Set(deprecated)
+
+		a.Flush()
+
+		counts := make(map[string]float64)
+		for i := 0; i < 2; i++ {
+			var e *v2.Envelope
+			Expect(spySetter.envelopes).To(Receive(&e))
+			Expect(e.Tags).To(HaveLen(1))
+			counts[e.Tags["status_code"]] = gaugeValues(e)["http.count"]
+		}
+		Expect(counts).To(Equal(map[string]float64{"200": 2, "500": 2}))
+	})
+
+	It("passes through timers beyond the max series", func() {
+		config.MaxSeries = 1
+		a, err := timeraggregator.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+
+		a.Set(buildTimer("some-id", "http", 0, ms(1)))
+		a.Set(buildTimer("some-id", "http", 0, ms(2)))
+		Expect(spySetter.envelopes).ToNot(Receive())
+
+		overflow := buildTimer("other-id", "http", 0, ms(1))
+		a.Set(overflow)
+		Expect(spySetter.envelopes).To(Receive(Equal(overflow)))
+	})
+
+	It("passes through timers that stop before they start", func() {
+		a, err := timeraggregator.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+
+		e := buildTimer("some-id", "http", ms(2), ms(1))
+		a.Set(e)
+
+		Expect(spySetter.envelopes).To(Receive(Equal(e)))
+	})
+
+	It("flushes every interval", func() {
+		config.Interval = 10 * time.Millisecond
+		a, err := timeraggregator.New(spySetter, config, metricClient)
+		Expect(err).ToNot(HaveOccurred())
+		go a.Start()
+
+		a.Set(buildTimer("some-id", "http", 0, ms(1)))
+
+		Eventually(spySetter.envelopes).Should(Receive())
+	})
+
+	DescribeTable("rejects invalid configuration", func(modify func(*timeraggregator.Config)) {
+		modify(&config)
+		_, err := timeraggregator.New(spySetter, config, metricClient)
+		Expect(err).To(HaveOccurred())
+	},
+		Entry("no selection", func(c *timeraggregator.Config) { c.Names = nil }),
+		Entry("no buckets", func(c *timeraggregator.Config) { c.Buckets = nil }),
+		Entry("unsorted buckets", func(c *timeraggregator.Config) { c.Buckets = []float64{10, 5} }),
+		Entry("negative buckets", func(c *timeraggregator.Config) { c.Buckets = []float64{-1, 5} }),
+		Entry("no interval", func(c *timeraggregator.Config) { c.Interval = 0 }),
+		Entry("no max series", func(c *timeraggregator.Config) { c.MaxSeries = 0 }),
+	)
+
+	Describe("ExponentialBuckets", func() {
+		It("multiplies each bound by the factor", func() {
+			buckets, err := timeraggregator.ExponentialBuckets(1, 2, 4)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(buckets).To(Equal([]float64{1, 2, 4, 8}))
+		})
+
+		It("rejects invalid layouts", func() {
+			_, err := timeraggregator.ExponentialBuckets(0, 2, 4)
+			Expect(err).To(HaveOccurred())
+
+			_, err = timeraggregator.ExponentialBuckets(1, 1, 4)
+			Expect(err).To(HaveOccurred())
+
+			_, err = timeraggregator.ExponentialBuckets(1, 2, 0)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+func ms(n int64) int64 {
+	return n * int64(time.Millisecond)
+}
+
+func buildTimer(sourceID, name string, start, stop int64) *v2.Envelope {
+	return &v2.Envelope{
+		SourceId:   sourceID,
+		InstanceId: "0",
+		Message: &v2.Envelope_Timer{
+			Timer: &v2.Timer{
+				Name:  name,
+				Start: start,
+				Stop:  stop,
+			},
+		},
+	}
+}
+
+func gaugeValues(e *v2.Envelope) map[string]float64 {
+	values := make(map[string]float64)
+	for name, v := range e.GetGauge().GetMetrics() {
+		values[name] = v.Value
+	}
+
+	return values
+}
+
+type SpySetter struct {
+	envelopes chan *v2.Envelope
+}
+
+func NewSpySetter() *SpySetter {
+	return &SpySetter{
+		envelopes: make(chan *v2.Envelope, 100),
+	}
+}
+
+func (s *SpySetter) Set(e *v2.Envelope) {
+	s.envelopes <- e
+}
@@ -17,6 +17,7 @@ import (
 	"google.golang.org/grpc/grpclog"
 
 	"code.cloudfoundry.org/loggregator/plumbing"
+	"code.cloudfoundry.org/loggregator/plumbing/limits"
 	"code.cloudfoundry.org/loggregator/profiler"
 
 	"code.cloudfoundry.org/loggregator/metron/app"
@@ -73,6 +74,7 @@ func main() {
 		log.Fatalf("Could not use GRPC creds for server: %s", err)
 	}
 
+	mirror := healthendpoint.NewMirror("loggregator", "metron")
 	batchInterval := time.Duration(config.MetricBatchIntervalMilliseconds) * time.Millisecond
 	// metric-documentation-v2: setup function
 	metricClient, err := metricemitter.NewClient(
@@ -81,17 +83,20 @@ func main() {
 		metricemitter.WithOrigin("loggregator.metron"),
 		metricemitter.WithDeployment(config.Deployment, config.Job, config.Index),
 		metricemitter.WithPulseInterval(batchInterval),
+		metricemitter.WithMirror(mirror),
 	)
 	if err != nil {
 		log.Fatalf("Could not configure metric emitter: %s", err)
 	}
 
-	healthRegistrar := startHealthEndpoint(fmt.Sprintf(":%d", config.HealthEndpointPort))
+	healthRegistrar := startHealthEndpoint(fmt.Sprintf(":%d", config.HealthEndpointPort), mirror)
 
-	appV1 := app.NewV1App(config, healthRegistrar, clientCreds, metricClient)
+	// The v1 and v2 APIs share the limiter so that its rejections are
+	// counted by one set of metrics.
+	limiter := limits.New(config.IngressLimits, metricClient)
+	appV2 := app.NewV2App(config, healthRegistrar, clientCreds, serverCreds, metricClient, limiter)
+	appV1 := app.NewV1App(config, healthRegistrar, clientCreds, metricClient, mirror, limiter, appV2.TimerAggregator())
 	go appV1.Start()
-
-	appV2 := app.NewV2App(config, healthRegistrar, clientCreds, serverCreds, metricClient)
 	go appV2.Start()
 
 	// We start the profiler last so that we can definitively say that we're
@@ -99,9 +104,9 @@ func main() {
 	profiler.New(config.PPROFPort).Start()
 }
 
-func startHealthEndpoint(addr string) *healthendpoint.Registrar {
+func startHealthEndpoint(addr string, mirror *healthendpoint.Mirror) *healthendpoint.Registrar {
 	promRegistry := prometheus.NewRegistry()
-	healthendpoint.StartServer(addr, promRegistry)
+	healthendpoint.StartServer(addr, prometheus.Gatherers{promRegistry, mirror})
 	healthRegistrar := healthendpoint.New(promRegistry, map[string]prometheus.Gauge{
 		// metric-documentation-health: (dopplerConnections)
 		// Number of connections open to dopplers.
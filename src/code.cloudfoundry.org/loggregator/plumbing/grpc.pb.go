@@ -97,6 +97,7 @@ type Filter struct {
 	//	*Filter_Log
 	//	*Filter_Metric
 	Message isFilter_Message `protobuf_oneof:"Message"`
+	AppIDs  []string         `protobuf:"bytes,4,rep,name=appIDs" json:"appIDs,omitempty"`
 }
 
 func (m *Filter) Reset()                    { *m = Filter{} }
@@ -132,6 +133,13 @@ func (m *Filter) GetAppID() string {
 	return ""
 }
 
+func (m *Filter) GetAppIDs() []string {
+	if m != nil {
+		return m.AppIDs
+	}
+	return nil
+}
+
 func (m *Filter) GetLog() *LogFilter {
 	if x, ok := m.GetMessage().(*Filter_Log); ok {
 		return x.Log
@@ -599,31 +607,31 @@ var _DopplerIngestor_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("grpc.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 403 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x84, 0x53, 0x4d, 0x6f, 0xd3, 0x40,
-	0x10, 0x8d, 0x89, 0x70, 0xea, 0x69, 0x04, 0x65, 0x8a, 0xc0, 0x32, 0x20, 0x95, 0x15, 0x12, 0xe6,
-	0x62, 0xaa, 0xc0, 0x91, 0x53, 0x09, 0x88, 0x48, 0xad, 0x40, 0xe6, 0x84, 0x38, 0xad, 0xdd, 0xc1,
-	0xb5, 0xe4, 0xec, 0x2e, 0xbb, 0x6b, 0x24, 0xee, 0xfc, 0x65, 0xee, 0xc8, 0xdf, 0x26, 0x98, 0xe4,
-	0x38, 0x33, 0x6f, 0xde, 0xec, 0x7b, 0x3b, 0x03, 0x90, 0x69, 0x95, 0x46, 0x4a, 0x4b, 0x2b, 0xf1,
-	0x48, 0x15, 0xe5, 0x36, 0xc9, 0x45, 0xc6, 0x42, 0x58, 0xbe, 0x13, 0x3f, 0xa8, 0x90, 0x8a, 0xd6,
-	0xdc, 0x72, 0xf4, 0x61, 0xa1, 0xf8, 0xcf, 0x42, 0xf2, 0x6b, 0xdf, 0x39, 0x73, 0xc2, 0x65, 0xdc,
-	0x85, 0xec, 0x0e, 0x2c, 0x3f, 0x95, 0xe6, 0x26, 0x26, 0xa3, 0xa4, 0x30, 0xc4, 0xbe, 0xc0, 0xe9,
-	0xe7, 0x32, 0x31, 0xa9, 0xce, 0x95, 0xcd, 0xa5, 0x88, 0xe9, 0x7b, 0x49, 0xc6, 0x56, 0x04, 0xe6,
-	0x86, 0xeb, 0xeb, 0xcd, 0xba, 0x26, 0xf0, 0xe2, 0x2e, 0xc4, 0x10, 0xdc, 0x6f, 0x79, 0x61, 0x49,
-	0xfb, 0xb7, 0xce, 0x9c, 0xf0, 0x78, 0x75, 0x12, 0x75, 0xaf, 0x88, 0xde, 0xd7, 0xf9, 0xb8, 0xad,
-	0xb3, 0x5f, 0x0e, 0xb8, 0x4d, 0x0a, 0xef, 0xc3, 0x6d, 0xae, 0x54, 0x4f, 0xd6, 0x04, 0xf8, 0x1c,
-	0xe6, 0x85, 0xcc, 0x5a, 0x9e, 0xd3, 0x81, 0xe7, 0x52, 0x66, 0x4d, 0xdf, 0x87, 0x59, 0x5c, 0x21,
-	0xf0, 0x1c, 0xdc, 0x2d, 0x59, 0x9d, 0xa7, 0xfe, 0xbc, 0xc6, 0x3e, 0x18, 0xb0, 0x57, 0x75, 0xbe,
-	0x87, 0xb7, 0xb8, 0x0b, 0x0f, 0x16, 0x57, 0x64, 0x0c, 0xcf, 0x88, 0x1d, 0x83, 0xd7, 0x13, 0x56,
-	0xf2, 0xc7, 0x1d, 0xec, 0x19, 0x1c, 0x75, 0x56, 0xec, 0x31, 0xed, 0x25, 0x3c, 0x7c, 0x2b, 0x85,
-	0xe5, 0xb9, 0x20, 0xdd, 0xb4, 0x9b, 0xce, 0xa8, 0x49, 0x65, 0xec, 0x35, 0xf8, 0xff, 0x36, 0x4c,
-	0x8d, 0x99, 0x8f, 0xc7, 0xbc, 0x80, 0x7b, 0x31, 0xa5, 0x24, 0xec, 0xa5, 0xcc, 0x0e, 0x0c, 0x88,
-	0x00, 0xc7, 0xd0, 0x43, 0xd4, 0xab, 0xdf, 0x0e, 0x2c, 0xd6, 0x52, 0xa9, 0x82, 0x34, 0x5e, 0x80,
-	0xd7, 0x7e, 0x79, 0x42, 0xf8, 0x64, 0xb0, 0x72, 0x62, 0x0f, 0x02, 0x1c, 0xca, 0xfd, 0xca, 0xcc,
-	0xce, 0x1d, 0xfc, 0x0a, 0x27, 0xbb, 0x02, 0xf1, 0xe9, 0x80, 0xfd, 0x8f, 0x5b, 0x01, 0xdb, 0x07,
-	0xe9, 0xe8, 0x71, 0x03, 0x30, 0x88, 0xc3, 0x47, 0xe3, 0x27, 0xec, 0xb8, 0x13, 0x3c, 0x9e, 0x2e,
-	0x76, 0x54, 0xab, 0x8f, 0x70, 0xb7, 0x95, 0xbd, 0x11, 0x19, 0x19, 0x2b, 0x35, 0xbe, 0x01, 0xb7,
-	0xba, 0x00, 0xd2, 0x38, 0x5a, 0xa3, 0xf1, 0xf5, 0x04, 0xa3, 0xfc, 0x5f, 0xb7, 0x32, 0x0b, 0x9d,
-	0xc4, 0xad, 0x4f, 0xef, 0xd5, 0x9f, 0x00, 0x00, 0x00, 0xff, 0xff, 0xcd, 0x3b, 0x70, 0x6c, 0x88,
-	0x03, 0x00, 0x00,
+	// 408 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x85, 0x53, 0x4d, 0x4f, 0x83, 0x40,
+	0x10, 0x2d, 0xa2, 0xb4, 0x4c, 0x1b, 0xad, 0x5b, 0x53, 0x09, 0x6a, 0x52, 0x89, 0x89, 0x78, 0xc1,
+	0xa6, 0x7a, 0xf4, 0x54, 0xab, 0xb1, 0x89, 0x8d, 0x06, 0x4f, 0xc6, 0x13, 0xe0, 0x4a, 0x49, 0x28,
+	0xbb, 0xb2, 0x60, 0xe2, 0x7f, 0xf1, 0x6f, 0x7a, 0x97, 0x6f, 0xb0, 0x62, 0x7b, 0x9c, 0x99, 0x37,
+	0xef, 0xed, 0xbc, 0x9d, 0x01, 0xb0, 0x7d, 0x6a, 0x69, 0xd4, 0x27, 0x01, 0x41, 0x2d, 0xea, 0x86,
+	0x0b, 0xd3, 0xf1, 0x6c, 0x45, 0x85, 0xce, 0x8d, 0xf7, 0x81, 0x5d, 0x42, 0xf1, 0xc4, 0x08, 0x0c,
+	0x24, 0x41, 0x93, 0x1a, 0x9f, 0x2e, 0x31, 0x5e, 0x25, 0x6e, 0xc0, 0xa9, 0x1d, 0x3d, 0x0f, 0x95,
+	0x6d, 0xe8, 0x3c, 0x86, 0x6c, 0xae, 0x63, 0x46, 0x89, 0xc7, 0xb0, 0xf2, 0x0c, 0xbd, 0xa7, 0xd0,
+	0x64, 0x96, 0xef, 0xd0, 0xc0, 0x21, 0x9e, 0x8e, 0xdf, 0x43, 0xcc, 0x82, 0x98, 0x80, 0xcd, 0x0d,
+	0xff, 0x75, 0x3a, 0x49, 0x08, 0x44, 0x3d, 0x0f, 0x91, 0x0a, 0xc2, 0x9b, 0xe3, 0x06, 0xd8, 0x97,
+	0x36, 0xa2, 0x42, 0x7b, 0xd4, 0xd5, 0xf2, 0x57, 0x68, 0xb7, 0x49, 0x5e, 0xcf, 0xea, 0xca, 0x17,
+	0x07, 0x42, 0x9a, 0x42, 0x7b, 0xb0, 0x65, 0x50, 0x5a, 0x90, 0xa5, 0x01, 0x3a, 0x05, 0xde, 0x25,
+	0x76, 0xc6, 0xd3, 0x2b, 0x79, 0xee, 0x89, 0x9d, 0xf6, 0xdd, 0x35, 0xf4, 0x18, 0x81, 0x86, 0x20,
+	0x2c, 0x70, 0xe0, 0x3b, 0x96, 0xc4, 0x27, 0xd8, 0x7e, 0x89, 0x9d, 0x25, 0xf9, 0x02, 0x9e, 0xe1,
+	0x50, 0x1f, 0x84, 0x44, 0x83, 0x49, 0x9b, 0x03, 0x3e, 0x52, 0xcc, 0xa2, 0xb1, 0x08, 0xcd, 0x19,
+	0x66, 0xcc, 0xb0, 0xb1, 0xd2, 0x06, 0xb1, 0x10, 0x8a, 0x6d, 0xa9, 0x32, 0x29, 0x27, 0xd0, 0xca,
+	0x2d, 0x5a, 0x61, 0xe6, 0x39, 0xec, 0x5f, 0x13, 0x2f, 0x30, 0x1c, 0x0f, 0xfb, 0x69, 0x3b, 0xcb,
+	0x0d, 0xac, 0x9d, 0x58, 0xb9, 0x04, 0xe9, 0x6f, 0x43, 0x9d, 0x0c, 0x5f, 0x95, 0x39, 0x83, 0x5d,
+	0x1d, 0x5b, 0xd8, 0x0b, 0xa2, 0xf7, 0xae, 0x11, 0xd0, 0x00, 0x55, 0xa1, 0xeb, 0xa8, 0x47, 0xdf,
+	0x1c, 0x34, 0x27, 0x84, 0x52, 0x37, 0xfa, 0xa4, 0x31, 0x88, 0xd9, 0x2a, 0x98, 0x18, 0x1d, 0x95,
+	0x16, 0xd7, 0xec, 0x87, 0x8c, 0xca, 0x72, 0xb1, 0x4a, 0x8d, 0x21, 0x87, 0x5e, 0xa0, 0xbb, 0x3c,
+	0x20, 0x3a, 0x2e, 0xb1, 0xff, 0xb8, 0x25, 0x2b, 0xab, 0x20, 0x39, 0x3d, 0x9a, 0x02, 0x94, 0xc3,
+	0xa1, 0x83, 0xea, 0x13, 0x96, 0xdc, 0x91, 0x0f, 0xeb, 0x8b, 0x39, 0xd5, 0xe8, 0x01, 0x76, 0xb2,
+	0xb1, 0xa7, 0x9e, 0x1d, 0x35, 0x10, 0x1f, 0x5d, 0x81, 0x10, 0x5f, 0x46, 0x64, 0x44, 0x65, 0xbd,
+	0xaa, 0x57, 0x25, 0x57, 0xf2, 0xbf, 0x6e, 0xa8, 0xa1, 0x72, 0xa6, 0x90, 0x9c, 0xe4, 0xc5, 0x0f,
+	0x8f, 0x14, 0xb0, 0xaa, 0xa0, 0x03, 0x00, 0x00,
 }
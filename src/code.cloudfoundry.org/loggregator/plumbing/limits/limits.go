@@ -0,0 +1,430 @@
+package limits
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"code.cloudfoundry.org/loggregator/metricemitter"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+
+	"github.com/cloudfoundry/sonde-go/events"
+	"github.com/gogo/protobuf/proto"
+)
+
+const (
+	truncatedMarker = "...[truncated]"
+
+	// PartTag is added to each envelope a log payload is split into. Its
+	// value is the 1-based index and the total number of parts, e.g. "2/3".
+	PartTag = "log_part"
+)
+
+// Config holds the limits applied to envelopes at ingress. A zero value
+// disables the corresponding limit.
+type Config struct {
+	MaxLogPayloadBytes int
+	MaxTagCount        int
+	MaxTagKeyLength    int
+	MaxTagValueLength  int
+	MaxBatchSize       int
+
+	// SplitOversizedLogs splits oversized log payloads into continuation
+	// envelopes. Otherwise they are truncated and end with a marker.
+	SplitOversizedLogs bool
+}
+
+// MetricClient creates the counters of rejected envelopes and oversized
+// logs.
+type MetricClient interface {
+	NewCounter(name string, opts ...metricemitter.MetricOption) *metricemitter.Counter
+}
+
+// Limiter enforces a Config on envelopes.
+type Limiter struct {
+	c Config
+
+	tagCountMetric     *metricemitter.Counter
+	tagKeyMetric       *metricemitter.Counter
+	tagValueMetric     *metricemitter.Counter
+	batchSizeMetric    *metricemitter.Counter
+	oversizedLogMetric *metricemitter.Counter
+}
+
+func New(c Config, m MetricClient) *Limiter {
+	rejected := func(reason string) *metricemitter.Counter {
+		return m.NewCounter("ingress.rejected",
+			metricemitter.WithVersion(2, 0),
+			metricemitter.WithTags(map[string]string{"reason": reason}),
+		)
+	}
+
+	action := "truncated"
+	if c.SplitOversizedLogs {
+		action = "split"
+	}
+
+	return &Limiter{
+		c:               c,
+		tagCountMetric:  rejected("tag_count"),
+		tagKeyMetric:    rejected("tag_key_length"),
+		tagValueMetric:  rejected("tag_value_length"),
+		batchSizeMetric: rejected("batch_size"),
+		oversizedLogMetric: m.NewCounter("ingress.oversized_logs",
+			metricemitter.WithVersion(2, 0),
+			metricemitter.WithTags(map[string]string{"action": action}),
+		),
+	}
+}
+
+// Field numbers and wire types of the dropsonde envelope fields read by
+// MayExceed.
+const (
+	envelopeLogMessageField = 8
+	envelopeTagsField       = 17
+	logMessageMessageField  = 1
+	mapKeyField             = 1
+	mapValueField           = 2
+
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// MayExceed reports whether a serialized dropsonde envelope exceeds any
+// limit. It reads the number and sizes of the tags and the size of the log
+// payload from the wire format without unmarshalling the envelope.
+// Envelopes that do not exceed a limit do not need to be unmarshalled to be
+// checked. Data that cannot be read is reported as exceeding, so that it is
+// checked once unmarshalled.
+func (l *Limiter) MayExceed(data []byte) bool {
+	if l.c.MaxLogPayloadBytes <= 0 && l.c.MaxTagCount <= 0 &&
+		l.c.MaxTagKeyLength <= 0 && l.c.MaxTagValueLength <= 0 {
+		return false
+	}
+
+	var tags int
+	for len(data) > 0 {
+		field, wireType, value, rest, ok := nextField(data)
+		if !ok {
+			return true
+		}
+		data = rest
+
+		if wireType != wireBytes {
+			continue
+		}
+
+		switch field {
+		case envelopeTagsField:
+			tags++
+			if exceeds(tags, l.c.MaxTagCount) || l.tagMayExceed(value) {
+				return true
+			}
+		case envelopeLogMessageField:
+			if l.payloadMayExceed(value) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (l *Limiter) tagMayExceed(entry []byte) bool {
+	for len(entry) > 0 {
+		field, _, value, rest, ok := nextField(entry)
+		if !ok {
+			return true
+		}
+		entry = rest
+
+		switch field {
+		case mapKeyField:
+			if exceeds(len(value), l.c.MaxTagKeyLength) {
+				return true
+			}
+		case mapValueField:
+			if exceeds(len(value), l.c.MaxTagValueLength) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (l *Limiter) payloadMayExceed(log []byte) bool {
+	for len(log) > 0 {
+		field, wireType, value, rest, ok := nextField(log)
+		if !ok {
+			return true
+		}
+		log = rest
+
+		if field == logMessageMessageField && wireType == wireBytes {
+			return exceeds(len(value), l.c.MaxLogPayloadBytes)
+		}
+	}
+
+	return false
+}
+
+// nextField reads the first field of the protobuf wire format data. The
+// value is only set for length delimited fields.
+func nextField(data []byte) (field uint64, wireType uint64, value, rest []byte, ok bool) {
+	key, n := proto.DecodeVarint(data)
+	if n == 0 {
+		return 0, 0, nil, nil, false
+	}
+	data = data[n:]
+	field, wireType = key>>3, key&7
+
+	switch wireType {
+	case wireVarint:
+		_, n = proto.DecodeVarint(data)
+		if n == 0 {
+			return 0, 0, nil, nil, false
+		}
+		return field, wireType, nil, data[n:], true
+	case wireFixed64:
+		if len(data) < 8 {
+			return 0, 0, nil, nil, false
+		}
+		return field, wireType, nil, data[8:], true
+	case wireFixed32:
+		if len(data) < 4 {
+			return 0, 0, nil, nil, false
+		}
+		return field, wireType, nil, data[4:], true
+	case wireBytes:
+		size, n := proto.DecodeVarint(data)
+		if n == 0 || size > uint64(len(data)-n) {
+			return 0, 0, nil, nil, false
+		}
+		data = data[n:]
+		return field, wireType, data[:size], data[size:], true
+	}
+
+	return 0, 0, nil, nil, false
+}
+
+// BatchAllowed reports whether a batch of the given size is allowed. Each
+// envelope of a rejected batch is counted.
+func (l *Limiter) BatchAllowed(size int) bool {
+	if !exceeds(size, l.c.MaxBatchSize) {
+		return true
+	}
+
+	// metric-documentation-v2: (loggregator.<component>.ingress.rejected)
+	// Number of envelopes rejected at ingress, tagged by the limit that was
+	// exceeded.
+	l.batchSizeMetric.Increment(uint64(size))
+	return false
+}
+
+// Envelope returns the envelopes to forward for e. It returns nil if e is
+// rejected. Oversized log payloads are split or truncated.
+func (l *Limiter) Envelope(e *v2.Envelope) []*v2.Envelope {
+	if !l.tagCountAllowed(len(e.GetTags()) + len(e.GetDeprecatedTags())) {
+		return nil
+	}
+
+	for k, v := range e.GetTags() {
+		if !l.tagAllowed(k, v) {
+			return nil
+		}
+	}
+
+	for k, v := range e.GetDeprecatedTags() {
+		if !l.tagAllowed(k, v.GetText()) {
+			return nil
+		}
+	}
+
+	log := e.GetLog()
+	if log == nil || !exceeds(len(log.Payload), l.c.MaxLogPayloadBytes) {
+		return []*v2.Envelope{e}
+	}
+
+	// metric-documentation-v2: (loggregator.<component>.ingress.oversized_logs)
+	// Number of log envelopes whose payload exceeded the maximum size and
+	// were split or truncated.
+	l.oversizedLogMetric.Increment(1)
+
+	split := l.splitAllowed(len(e.GetTags())+len(e.GetDeprecatedTags()), e.GetTags())
+	parts := l.payloadParts(log.Payload, split)
+	result := make([]*v2.Envelope, 0, len(parts))
+	for i, p := range parts {
+		result = append(result, copyV2Log(e, p, i, len(parts)))
+	}
+
+	return result
+}
+
+// V1Envelope applies the same limits as Envelope to a dropsonde envelope.
+func (l *Limiter) V1Envelope(e *events.Envelope) []*events.Envelope {
+	if !l.tagCountAllowed(len(e.GetTags())) {
+		return nil
+	}
+
+	for k, v := range e.GetTags() {
+		if !l.tagAllowed(k, v) {
+			return nil
+		}
+	}
+
+	log := e.GetLogMessage()
+	if log == nil || !exceeds(len(log.GetMessage()), l.c.MaxLogPayloadBytes) {
+		return []*events.Envelope{e}
+	}
+
+	// metric-documentation-v2: (loggregator.<component>.ingress.oversized_logs)
+	// Number of log envelopes whose payload exceeded the maximum size and
+	// were split or truncated.
+	l.oversizedLogMetric.Increment(1)
+
+	split := l.splitAllowed(len(e.GetTags()), e.GetTags())
+	parts := l.payloadParts(log.GetMessage(), split)
+	result := make([]*events.Envelope, 0, len(parts))
+	for i, p := range parts {
+		result = append(result, copyV1Log(e, p, i, len(parts)))
+	}
+
+	return result
+}
+
+func (l *Limiter) tagCountAllowed(count int) bool {
+	if !exceeds(count, l.c.MaxTagCount) {
+		return true
+	}
+
+	// metric-documentation-v2: (loggregator.<component>.ingress.rejected)
+	// Number of envelopes rejected at ingress, tagged by the limit that was
+	// exceeded.
+	l.tagCountMetric.Increment(1)
+	return false
+}
+
+func (l *Limiter) tagAllowed(k, v string) bool {
+	if exceeds(len(k), l.c.MaxTagKeyLength) {
+		// metric-documentation-v2: (loggregator.<component>.ingress.rejected)
+		// Number of envelopes rejected at ingress, tagged by the limit that
+		// was exceeded.
+		l.tagKeyMetric.Increment(1)
+		return false
+	}
+
+	if exceeds(len(v), l.c.MaxTagValueLength) {
+		// metric-documentation-v2: (loggregator.<component>.ingress.rejected)
+		// Number of envelopes rejected at ingress, tagged by the limit that
+		// was exceeded.
+		l.tagValueMetric.Increment(1)
+		return false
+	}
+
+	return true
+}
+
+// splitAllowed reports whether an oversized log payload of an envelope
+// with the given tag count and tags may be split. Splitting adds the part
+// tag, so payloads are truncated instead when the tag would exceed the tag
+// count limit.
+func (l *Limiter) splitAllowed(count int, tags map[string]string) bool {
+	if !l.c.SplitOversizedLogs {
+		return false
+	}
+
+	if _, ok := tags[PartTag]; !ok {
+		count++
+	}
+	return !exceeds(count, l.c.MaxTagCount)
+}
+
+func (l *Limiter) payloadParts(payload []byte, split bool) [][]byte {
+	max := l.c.MaxLogPayloadBytes
+
+	if !split {
+		keep := max - len(truncatedMarker)
+		if keep < 0 {
+			return [][]byte{payload[:runeBoundary(payload, max)]}
+		}
+
+		keep = runeBoundary(payload, keep)
+		truncated := make([]byte, 0, keep+len(truncatedMarker))
+		truncated = append(truncated, payload[:keep]...)
+		truncated = append(truncated, truncatedMarker...)
+		return [][]byte{truncated}
+	}
+
+	var parts [][]byte
+	for len(payload) > max {
+		n := runeBoundary(payload, max)
+		parts = append(parts, payload[:n])
+		payload = payload[n:]
+	}
+	return append(parts, payload)
+}
+
+// runeBoundary returns the largest index not greater than n at which the
+// UTF-8 encoded rune of b starts, so that b can be cut there without
+// splitting a rune. If b is not valid UTF-8 around n or the rune would
+// leave nothing before the cut, n is returned. n has to be less than
+// len(b).
+func runeBoundary(b []byte, n int) int {
+	for i := n; i > 0 && n-i < utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			return i
+		}
+	}
+	return n
+}
+
+func copyV2Log(e *v2.Envelope, payload []byte, i, n int) *v2.Envelope {
+	tags := partTags(e.GetTags(), i, n)
+
+	return &v2.Envelope{
+		Timestamp:      e.Timestamp,
+		SourceId:       e.SourceId,
+		InstanceId:     e.InstanceId,
+		DeprecatedTags: e.DeprecatedTags,
+		Tags:           tags,
+		Message: &v2.Envelope_Log{
+			Log: &v2.Log{
+				Payload: payload,
+				Type:    e.GetLog().GetType(),
+			},
+		},
+	}
+}
+
+func copyV1Log(e *events.Envelope, payload []byte, i, n int) *events.Envelope {
+	tags := partTags(e.GetTags(), i, n)
+
+	log := *e.GetLogMessage()
+	log.Message = payload
+
+	copied := *e
+	copied.LogMessage = &log
+	copied.Tags = tags
+
+	return &copied
+}
+
+func partTags(tags map[string]string, i, n int) map[string]string {
+	if n == 1 {
+		return tags
+	}
+
+	copied := make(map[string]string, len(tags)+1)
+	for k, v := range tags {
+		copied[k] = v
+	}
+	copied[PartTag] = fmt.Sprintf("%d/%d", i+1, n)
+
+	return copied
+}
+
+func exceeds(n, max int) bool {
+	return max > 0 && n > max
+}
@@ -0,0 +1,255 @@
+package limits_test
+
+import (
+	"code.cloudfoundry.org/loggregator/metricemitter/testhelper"
+	"code.cloudfoundry.org/loggregator/plumbing/limits"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+
+	"github.com/cloudfoundry/sonde-go/events"
+	"github.com/gogo/protobuf/proto"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Limiter", func() {
+	var (
+		metricClient *testhelper.SpyMetricClient
+		config       limits.Config
+		limiter      *limits.Limiter
+	)
+
+	BeforeEach(func() {
+		metricClient = testhelper.NewMetricClient()
+		config = limits.Config{}
+	})
+
+	JustBeforeEach(func() {
+		limiter = limits.New(config, metricClient)
+	})
+
+	Context("with no limits", func() {
+		It("allows every envelope", func() {
+			e := logEnvelope("some-log", map[string]string{"a": "b"})
+
+			Expect(limiter.Envelope(e)).To(Equal([]*v2.Envelope{e}))
+			Expect(limiter.BatchAllowed(1000000)).To(BeTrue())
+			Expect(limiter.MayExceed(marshal(dropsondeLog("some-log", map[string]string{"a": "b"})))).To(BeFalse())
+		})
+	})
+
+	Context("with a max batch size", func() {
+		BeforeEach(func() {
+			config.MaxBatchSize = 2
+		})
+
+		It("rejects larger batches and counts each envelope", func() {
+			Expect(limiter.BatchAllowed(2)).To(BeTrue())
+			Expect(limiter.BatchAllowed(3)).To(BeFalse())
+
+			Expect(rejected(metricClient)).To(Equal(map[string]uint64{"batch_size": 3}))
+		})
+	})
+
+	Context("with tag limits", func() {
+		BeforeEach(func() {
+			config.MaxTagCount = 2
+			config.MaxTagKeyLength = 3
+			config.MaxTagValueLength = 4
+		})
+
+		It("allows envelopes within the limits", func() {
+			e := logEnvelope("some-log", map[string]string{"abc": "defg"})
+
+			Expect(limiter.Envelope(e)).To(HaveLen(1))
+		})
+
+		It("rejects envelopes with too many tags", func() {
+			e := logEnvelope("some-log", map[string]string{"a": "1", "b": "2", "c": "3"})
+
+			Expect(limiter.Envelope(e)).To(BeNil())
+			Expect(rejected(metricClient)).To(Equal(map[string]uint64{"tag_count": 1}))
+		})
+
+		It("counts deprecated tags towards the tag count", func() {
+			e := logEnvelope("some-log", map[string]string{"a": "1", "b": "2"})
+			e.DeprecatedTags = map[string]*v2.Value{
+				"c": {Data: &v2.Value_Text{Text: "3"}},
+			}
+
+			Expect(limiter.Envelope(e)).To(BeNil())
+		})
+
+		It("rejects envelopes with a long tag key", func() {
+			e := logEnvelope("some-log", map[string]string{"abcd": "1"})
+
+			Expect(limiter.Envelope(e)).To(BeNil())
+			Expect(rejected(metricClient)).To(Equal(map[string]uint64{"tag_key_length": 1}))
+		})
+
+		It("rejects envelopes with a long tag value", func() {
+			e := logEnvelope("some-log", map[string]string{"a": "12345"})
+
+			Expect(limiter.Envelope(e)).To(BeNil())
+			Expect(rejected(metricClient)).To(Equal(map[string]uint64{"tag_value_length": 1}))
+		})
+
+		It("applies the limits to dropsonde envelopes", func() {
+			e := &events.Envelope{
+				Origin:    proto.String("origin"),
+				EventType: events.Envelope_CounterEvent.Enum(),
+				Tags:      map[string]string{"a": "12345"},
+			}
+
+			Expect(limiter.V1Envelope(e)).To(BeNil())
+		})
+
+		It("reports that serialized envelopes exceeding a tag limit may exceed", func() {
+			Expect(limiter.MayExceed(marshal(dropsondeLog("some-log", map[string]string{"abc": "defg"})))).To(BeFalse())
+			Expect(limiter.MayExceed(marshal(dropsondeLog("some-log", map[string]string{"a": "1", "b": "2", "c": "3"})))).To(BeTrue())
+			Expect(limiter.MayExceed(marshal(dropsondeLog("some-log", map[string]string{"abcd": "1"})))).To(BeTrue())
+			Expect(limiter.MayExceed(marshal(dropsondeLog("some-log", map[string]string{"a": "12345"})))).To(BeTrue())
+		})
+	})
+
+	Context("with a max log payload size", func() {
+		BeforeEach(func() {
+			config.MaxLogPayloadBytes = 20
+		})
+
+		It("does not modify smaller logs", func() {
+			e := logEnvelope("short", nil)
+
+			Expect(limiter.Envelope(e)).To(Equal([]*v2.Envelope{e}))
+		})
+
+		It("truncates oversized logs with a marker", func() {
+			e := logEnvelope("0123456789abcdefghijklmnop", map[string]string{"a": "b"})
+
+			envs := limiter.Envelope(e)
+			Expect(envs).To(HaveLen(1))
+			Expect(string(envs[0].GetLog().Payload)).To(Equal("012345...[truncated]"))
+			Expect(envs[0].SourceId).To(Equal("some-source"))
+			Expect(envs[0].Tags).To(Equal(map[string]string{"a": "b"}))
+			Expect(metricClient.GetDelta("ingress.oversized_logs")).To(Equal(uint64(1)))
+		})
+
+		It("truncates oversized logs without splitting a rune", func() {
+			e := logEnvelope("01234éabcdefghijklmnop", nil)
+
+			envs := limiter.Envelope(e)
+			Expect(envs).To(HaveLen(1))
+			Expect(string(envs[0].GetLog().Payload)).To(Equal("01234...[truncated]"))
+		})
+
+		It("truncates oversized dropsonde logs", func() {
+			e := dropsondeLog("0123456789abcdefghijklmnop", nil)
+
+			envs := limiter.V1Envelope(e)
+			Expect(envs).To(HaveLen(1))
+			Expect(string(envs[0].GetLogMessage().GetMessage())).To(Equal("012345...[truncated]"))
+			Expect(envs[0].GetLogMessage().GetAppId()).To(Equal("some-app"))
+			Expect(string(e.GetLogMessage().GetMessage())).To(Equal("0123456789abcdefghijklmnop"))
+		})
+
+		It("reports that serialized envelopes with a large payload may exceed", func() {
+			Expect(limiter.MayExceed(marshal(dropsondeLog("01234567890123456789", nil)))).To(BeFalse())
+			Expect(limiter.MayExceed(marshal(dropsondeLog("012345678901234567890", nil)))).To(BeTrue())
+		})
+
+		It("reports that malformed data may exceed", func() {
+			data := marshal(dropsondeLog("some-log", nil))
+
+			Expect(limiter.MayExceed(data[:len(data)-1])).To(BeTrue())
+		})
+
+		Context("when splitting is enabled", func() {
+			BeforeEach(func() {
+				config.MaxLogPayloadBytes = 10
+				config.SplitOversizedLogs = true
+			})
+
+			It("splits oversized logs into tagged parts", func() {
+				e := logEnvelope("0123456789abcdefghijklmnop", map[string]string{"a": "b"})
+
+				envs := limiter.Envelope(e)
+				Expect(envs).To(HaveLen(3))
+
+				var payloads, parts []string
+				for _, env := range envs {
+					payloads = append(payloads, string(env.GetLog().Payload))
+					parts = append(parts, env.Tags[limits.PartTag])
+					Expect(env.Tags).To(HaveKeyWithValue("a", "b"))
+					Expect(env.GetLog().Type).To(Equal(v2.Log_ERR))
+				}
+				Expect(payloads).To(Equal([]string{"0123456789", "abcdefghij", "klmnop"}))
+				Expect(parts).To(Equal([]string{"1/3", "2/3", "3/3"}))
+				Expect(e.Tags).ToNot(HaveKey(limits.PartTag))
+			})
+
+			It("splits oversized logs without splitting a rune", func() {
+				e := logEnvelope("012345678éabcdefgh", nil)
+
+				envs := limiter.Envelope(e)
+				Expect(envs).To(HaveLen(2))
+				Expect(string(envs[0].GetLog().Payload)).To(Equal("012345678"))
+				Expect(string(envs[1].GetLog().Payload)).To(Equal("éabcdefgh"))
+			})
+
+			It("truncates oversized logs when the part tag would exceed the tag count", func() {
+				config.MaxLogPayloadBytes = 20
+				config.MaxTagCount = 1
+				limiter = limits.New(config, metricClient)
+				e := logEnvelope("0123456789abcdefghijklmnop", map[string]string{"a": "b"})
+
+				envs := limiter.Envelope(e)
+				Expect(envs).To(HaveLen(1))
+				Expect(envs[0].Tags).To(Equal(map[string]string{"a": "b"}))
+				Expect(string(envs[0].GetLog().Payload)).To(Equal("012345...[truncated]"))
+			})
+		})
+	})
+})
+
+func logEnvelope(payload string, tags map[string]string) *v2.Envelope {
+	return &v2.Envelope{
+		SourceId: "some-source",
+		Tags:     tags,
+		Message: &v2.Envelope_Log{
+			Log: &v2.Log{
+				Payload: []byte(payload),
+				Type:    v2.Log_ERR,
+			},
+		},
+	}
+}
+
+func dropsondeLog(message string, tags map[string]string) *events.Envelope {
+	return &events.Envelope{
+		Origin:    proto.String("origin"),
+		EventType: events.Envelope_LogMessage.Enum(),
+		Tags:      tags,
+		LogMessage: &events.LogMessage{
+			Message:     []byte(message),
+			MessageType: events.LogMessage_OUT.Enum(),
+			Timestamp:   proto.Int64(1),
+			AppId:       proto.String("some-app"),
+		},
+	}
+}
+
+func marshal(e *events.Envelope) []byte {
+	data, err := proto.Marshal(e)
+	Expect(err).ToNot(HaveOccurred())
+	return data
+}
+
+func rejected(m *testhelper.SpyMetricClient) map[string]uint64 {
+	reasons := make(map[string]uint64)
+	for _, e := range m.GetEnvelopes("ingress.rejected") {
+		if delta := e.GetCounter().GetDelta(); delta > 0 {
+			reasons[e.GetDeprecatedTags()["reason"].GetText()] = delta
+		}
+	}
+	return reasons
+}
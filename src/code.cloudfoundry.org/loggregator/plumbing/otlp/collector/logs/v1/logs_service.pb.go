@@ -0,0 +1,204 @@
+// Code generated by protoc-gen-go.
+// source: opentelemetry/proto/collector/logs/v1/logs_service.proto
+// DO NOT EDIT!
+
+/*
+Package v1 is a generated protocol buffer package.
+
+It is generated from these files:
+
+	opentelemetry/proto/collector/logs/v1/logs_service.proto
+
+It has these top-level messages:
+
+	ExportLogsServiceRequest
+	ExportLogsServiceResponse
+	ExportLogsPartialSuccess
+*/
+package v1
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import opentelemetry_proto_logs_v1 "code.cloudfoundry.org/loggregator/plumbing/otlp/logs/v1"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type ExportLogsServiceRequest struct {
+	ResourceLogs []*opentelemetry_proto_logs_v1.ResourceLogs `protobuf:"bytes,1,rep,name=resource_logs,json=resourceLogs" json:"resource_logs,omitempty"`
+}
+
+func (m *ExportLogsServiceRequest) Reset()                    { *m = ExportLogsServiceRequest{} }
+func (m *ExportLogsServiceRequest) String() string            { return proto.CompactTextString(m) }
+func (*ExportLogsServiceRequest) ProtoMessage()               {}
+func (*ExportLogsServiceRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+func (m *ExportLogsServiceRequest) GetResourceLogs() []*opentelemetry_proto_logs_v1.ResourceLogs {
+	if m != nil {
+		return m.ResourceLogs
+	}
+	return nil
+}
+
+type ExportLogsServiceResponse struct {
+	PartialSuccess *ExportLogsPartialSuccess `protobuf:"bytes,1,opt,name=partial_success,json=partialSuccess" json:"partial_success,omitempty"`
+}
+
+func (m *ExportLogsServiceResponse) Reset()                    { *m = ExportLogsServiceResponse{} }
+func (m *ExportLogsServiceResponse) String() string            { return proto.CompactTextString(m) }
+func (*ExportLogsServiceResponse) ProtoMessage()               {}
+func (*ExportLogsServiceResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *ExportLogsServiceResponse) GetPartialSuccess() *ExportLogsPartialSuccess {
+	if m != nil {
+		return m.PartialSuccess
+	}
+	return nil
+}
+
+type ExportLogsPartialSuccess struct {
+	RejectedLogRecords int64  `protobuf:"varint,1,opt,name=rejected_log_records,json=rejectedLogRecords" json:"rejected_log_records,omitempty"`
+	ErrorMessage       string `protobuf:"bytes,2,opt,name=error_message,json=errorMessage" json:"error_message,omitempty"`
+}
+
+func (m *ExportLogsPartialSuccess) Reset()                    { *m = ExportLogsPartialSuccess{} }
+func (m *ExportLogsPartialSuccess) String() string            { return proto.CompactTextString(m) }
+func (*ExportLogsPartialSuccess) ProtoMessage()               {}
+func (*ExportLogsPartialSuccess) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *ExportLogsPartialSuccess) GetRejectedLogRecords() int64 {
+	if m != nil {
+		return m.RejectedLogRecords
+	}
+	return 0
+}
+
+func (m *ExportLogsPartialSuccess) GetErrorMessage() string {
+	if m != nil {
+		return m.ErrorMessage
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*ExportLogsServiceRequest)(nil), "opentelemetry.proto.collector.logs.v1.ExportLogsServiceRequest")
+	proto.RegisterType((*ExportLogsServiceResponse)(nil), "opentelemetry.proto.collector.logs.v1.ExportLogsServiceResponse")
+	proto.RegisterType((*ExportLogsPartialSuccess)(nil), "opentelemetry.proto.collector.logs.v1.ExportLogsPartialSuccess")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for LogsService service
+
+type LogsServiceClient interface {
+	Export(ctx context.Context, in *ExportLogsServiceRequest, opts ...grpc.CallOption) (*ExportLogsServiceResponse, error)
+}
+
+type logsServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewLogsServiceClient(cc *grpc.ClientConn) LogsServiceClient {
+	return &logsServiceClient{cc}
+}
+
+func (c *logsServiceClient) Export(ctx context.Context, in *ExportLogsServiceRequest, opts ...grpc.CallOption) (*ExportLogsServiceResponse, error) {
+	out := new(ExportLogsServiceResponse)
+	err := grpc.Invoke(ctx, "/opentelemetry.proto.collector.logs.v1.LogsService/Export", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for LogsService service
+
+type LogsServiceServer interface {
+	Export(context.Context, *ExportLogsServiceRequest) (*ExportLogsServiceResponse, error)
+}
+
+func RegisterLogsServiceServer(s *grpc.Server, srv LogsServiceServer) {
+	s.RegisterService(&_LogsService_serviceDesc, srv)
+}
+
+func _LogsService_Export_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportLogsServiceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LogsServiceServer).Export(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/opentelemetry.proto.collector.logs.v1.LogsService/Export",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LogsServiceServer).Export(ctx, req.(*ExportLogsServiceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _LogsService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "opentelemetry.proto.collector.logs.v1.LogsService",
+	HandlerType: (*LogsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Export",
+			Handler:    _LogsService_Export_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "opentelemetry/proto/collector/logs/v1/logs_service.proto",
+}
+
+func init() {
+	proto.RegisterFile("opentelemetry/proto/collector/logs/v1/logs_service.proto", fileDescriptor0)
+}
+
+var fileDescriptor0 = []byte{
+	// 364 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x92, 0xc1, 0x4e, 0x22, 0x41,
+	0x10, 0x86, 0xd3, 0x90, 0x90, 0x6c, 0x03, 0xbb, 0x9b, 0xce, 0x1e, 0x66, 0x39, 0x11, 0x36, 0x6c,
+	0x66, 0x2f, 0x3d, 0xc0, 0x5e, 0xf6, 0xb6, 0x06, 0xe3, 0x0d, 0x95, 0x0c, 0xc6, 0x83, 0x97, 0x09,
+	0x36, 0x95, 0x71, 0xc8, 0x40, 0x35, 0xd5, 0x0d, 0xd1, 0x07, 0xf0, 0xe4, 0x0b, 0x98, 0xf8, 0x06,
+	0x3e, 0xa5, 0x99, 0x69, 0xc4, 0x41, 0x31, 0x41, 0x4f, 0x93, 0xae, 0xae, 0xff, 0xfb, 0xeb, 0x9f,
+	0x2e, 0xfe, 0x0f, 0x35, 0xcc, 0x2d, 0xa4, 0x30, 0x03, 0x4b, 0x37, 0x81, 0x26, 0xb4, 0x18, 0x28,
+	0x4c, 0x53, 0x50, 0x16, 0x29, 0x48, 0x31, 0x36, 0xc1, 0xaa, 0x9b, 0x7f, 0x23, 0x03, 0xb4, 0x4a,
+	0x14, 0xc8, 0xbc, 0x49, 0xb4, 0xb7, 0x94, 0xae, 0x28, 0x37, 0x4a, 0x99, 0x29, 0xe4, 0xaa, 0xdb,
+	0xf8, 0xbd, 0xcb, 0xa0, 0x88, 0x75, 0xca, 0xd6, 0x94, 0x7b, 0x47, 0xd7, 0x1a, 0xc9, 0x0e, 0x30,
+	0x36, 0x23, 0xe7, 0x14, 0xc2, 0x62, 0x09, 0xc6, 0x8a, 0x13, 0x5e, 0x27, 0x30, 0xb8, 0x24, 0x05,
+	0x51, 0x26, 0xf1, 0x58, 0xb3, 0xec, 0x57, 0x7b, 0x7f, 0xe4, 0xae, 0x11, 0xd6, 0xc6, 0x32, 0x5c,
+	0x2b, 0x32, 0x5e, 0x58, 0xa3, 0xc2, 0xa9, 0x75, 0xcb, 0xf8, 0xcf, 0x1d, 0x66, 0x46, 0xe3, 0xdc,
+	0x80, 0xb8, 0xe2, 0xdf, 0xf4, 0x98, 0x6c, 0x32, 0x4e, 0x23, 0xb3, 0x54, 0x0a, 0x4c, 0xe6, 0xc7,
+	0xfc, 0x6a, 0xef, 0xbf, 0xdc, 0x2b, 0xb2, 0x7c, 0x41, 0x0f, 0x1d, 0x67, 0xe4, 0x30, 0xe1, 0x57,
+	0xbd, 0x75, 0x6e, 0x2d, 0xb8, 0xf7, 0x5e, 0xaf, 0xe8, 0xf0, 0x1f, 0x04, 0x53, 0x50, 0x16, 0x26,
+	0x59, 0xe6, 0x88, 0x40, 0x21, 0x4d, 0xdc, 0x28, 0xe5, 0x50, 0x3c, 0xdf, 0x0d, 0x30, 0x0e, 0xdd,
+	0x8d, 0xf8, 0xc5, 0xeb, 0x40, 0x84, 0x14, 0xcd, 0xc0, 0x98, 0x71, 0x0c, 0x5e, 0xa9, 0xc9, 0xfc,
+	0x2f, 0x61, 0x2d, 0x2f, 0x1e, 0xbb, 0x5a, 0xef, 0x81, 0xf1, 0x6a, 0x21, 0xb4, 0xb8, 0x63, 0xbc,
+	0xe2, 0x66, 0x10, 0x1f, 0x8f, 0xb7, 0xfd, 0x4c, 0x8d, 0x83, 0xcf, 0x03, 0xdc, 0xaf, 0xef, 0xdf,
+	0x33, 0xee, 0x27, 0xb8, 0x1f, 0xa6, 0xff, 0xbd, 0x40, 0x18, 0x66, 0x3d, 0x43, 0x76, 0xd1, 0x89,
+	0x5f, 0xab, 0x13, 0x5c, 0xef, 0x1c, 0xda, 0x54, 0xbf, 0xdd, 0xec, 0xc7, 0x52, 0xfb, 0x54, 0xc3,
+	0xfc, 0x6c, 0xd3, 0x9f, 0x93, 0xe4, 0xe1, 0xc6, 0x2d, 0x33, 0x91, 0xe7, 0xdd, 0xcb, 0x4a, 0x4e,
+	0xf9, 0xfb, 0x34, 0x00, 0x33, 0x0c, 0x67, 0x10, 0x31, 0x03, 0x00, 0x00,
+}
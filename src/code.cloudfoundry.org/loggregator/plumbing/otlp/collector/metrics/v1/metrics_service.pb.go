@@ -0,0 +1,204 @@
+// Code generated by protoc-gen-go.
+// source: opentelemetry/proto/collector/metrics/v1/metrics_service.proto
+// DO NOT EDIT!
+
+/*
+Package v1 is a generated protocol buffer package.
+
+It is generated from these files:
+
+	opentelemetry/proto/collector/metrics/v1/metrics_service.proto
+
+It has these top-level messages:
+
+	ExportMetricsServiceRequest
+	ExportMetricsServiceResponse
+	ExportMetricsPartialSuccess
+*/
+package v1
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import opentelemetry_proto_metrics_v1 "code.cloudfoundry.org/loggregator/plumbing/otlp/metrics/v1"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type ExportMetricsServiceRequest struct {
+	ResourceMetrics []*opentelemetry_proto_metrics_v1.ResourceMetrics `protobuf:"bytes,1,rep,name=resource_metrics,json=resourceMetrics" json:"resource_metrics,omitempty"`
+}
+
+func (m *ExportMetricsServiceRequest) Reset()                    { *m = ExportMetricsServiceRequest{} }
+func (m *ExportMetricsServiceRequest) String() string            { return proto.CompactTextString(m) }
+func (*ExportMetricsServiceRequest) ProtoMessage()               {}
+func (*ExportMetricsServiceRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+func (m *ExportMetricsServiceRequest) GetResourceMetrics() []*opentelemetry_proto_metrics_v1.ResourceMetrics {
+	if m != nil {
+		return m.ResourceMetrics
+	}
+	return nil
+}
+
+type ExportMetricsServiceResponse struct {
+	PartialSuccess *ExportMetricsPartialSuccess `protobuf:"bytes,1,opt,name=partial_success,json=partialSuccess" json:"partial_success,omitempty"`
+}
+
+func (m *ExportMetricsServiceResponse) Reset()                    { *m = ExportMetricsServiceResponse{} }
+func (m *ExportMetricsServiceResponse) String() string            { return proto.CompactTextString(m) }
+func (*ExportMetricsServiceResponse) ProtoMessage()               {}
+func (*ExportMetricsServiceResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *ExportMetricsServiceResponse) GetPartialSuccess() *ExportMetricsPartialSuccess {
+	if m != nil {
+		return m.PartialSuccess
+	}
+	return nil
+}
+
+type ExportMetricsPartialSuccess struct {
+	RejectedDataPoints int64  `protobuf:"varint,1,opt,name=rejected_data_points,json=rejectedDataPoints" json:"rejected_data_points,omitempty"`
+	ErrorMessage       string `protobuf:"bytes,2,opt,name=error_message,json=errorMessage" json:"error_message,omitempty"`
+}
+
+func (m *ExportMetricsPartialSuccess) Reset()                    { *m = ExportMetricsPartialSuccess{} }
+func (m *ExportMetricsPartialSuccess) String() string            { return proto.CompactTextString(m) }
+func (*ExportMetricsPartialSuccess) ProtoMessage()               {}
+func (*ExportMetricsPartialSuccess) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *ExportMetricsPartialSuccess) GetRejectedDataPoints() int64 {
+	if m != nil {
+		return m.RejectedDataPoints
+	}
+	return 0
+}
+
+func (m *ExportMetricsPartialSuccess) GetErrorMessage() string {
+	if m != nil {
+		return m.ErrorMessage
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*ExportMetricsServiceRequest)(nil), "opentelemetry.proto.collector.metrics.v1.ExportMetricsServiceRequest")
+	proto.RegisterType((*ExportMetricsServiceResponse)(nil), "opentelemetry.proto.collector.metrics.v1.ExportMetricsServiceResponse")
+	proto.RegisterType((*ExportMetricsPartialSuccess)(nil), "opentelemetry.proto.collector.metrics.v1.ExportMetricsPartialSuccess")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for MetricsService service
+
+type MetricsServiceClient interface {
+	Export(ctx context.Context, in *ExportMetricsServiceRequest, opts ...grpc.CallOption) (*ExportMetricsServiceResponse, error)
+}
+
+type metricsServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewMetricsServiceClient(cc *grpc.ClientConn) MetricsServiceClient {
+	return &metricsServiceClient{cc}
+}
+
+func (c *metricsServiceClient) Export(ctx context.Context, in *ExportMetricsServiceRequest, opts ...grpc.CallOption) (*ExportMetricsServiceResponse, error) {
+	out := new(ExportMetricsServiceResponse)
+	err := grpc.Invoke(ctx, "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for MetricsService service
+
+type MetricsServiceServer interface {
+	Export(context.Context, *ExportMetricsServiceRequest) (*ExportMetricsServiceResponse, error)
+}
+
+func RegisterMetricsServiceServer(s *grpc.Server, srv MetricsServiceServer) {
+	s.RegisterService(&_MetricsService_serviceDesc, srv)
+}
+
+func _MetricsService_Export_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportMetricsServiceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).Export(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).Export(ctx, req.(*ExportMetricsServiceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _MetricsService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "opentelemetry.proto.collector.metrics.v1.MetricsService",
+	HandlerType: (*MetricsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Export",
+			Handler:    _MetricsService_Export_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "opentelemetry/proto/collector/metrics/v1/metrics_service.proto",
+}
+
+func init() {
+	proto.RegisterFile("opentelemetry/proto/collector/metrics/v1/metrics_service.proto", fileDescriptor0)
+}
+
+var fileDescriptor0 = []byte{
+	// 359 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x93, 0x41, 0x4f, 0xc2, 0x30,
+	0x1c, 0xc5, 0x53, 0x48, 0x48, 0x2c, 0x0a, 0xa6, 0x7a, 0x20, 0xe0, 0x81, 0xe0, 0x65, 0x89, 0xa6,
+	0x13, 0xb8, 0x7b, 0x40, 0xf1, 0x46, 0x5c, 0x86, 0xf1, 0xc0, 0x65, 0xa9, 0xe5, 0x1f, 0x32, 0x33,
+	0xd6, 0xda, 0x16, 0x22, 0x5f, 0xc2, 0xab, 0x5f, 0xc0, 0x13, 0x9f, 0xd2, 0xb0, 0x0e, 0x4c, 0x75,
+	0x31, 0x44, 0x6f, 0xdb, 0xeb, 0xff, 0xfd, 0xde, 0x5b, 0xbb, 0xe2, 0x6b, 0x21, 0x21, 0x35, 0x90,
+	0xc0, 0x1c, 0x8c, 0x5a, 0xf9, 0x52, 0x09, 0x23, 0x7c, 0x2e, 0x92, 0x04, 0xb8, 0x11, 0xca, 0xdf,
+	0xa8, 0x31, 0xd7, 0xfe, 0xb2, 0xbb, 0x7d, 0x8c, 0x34, 0xa8, 0x65, 0xcc, 0x81, 0x66, 0xa3, 0xc4,
+	0x73, 0xfc, 0x56, 0xa4, 0x3b, 0x3f, 0xcd, 0x4d, 0x74, 0xd9, 0x6d, 0x5e, 0x16, 0x25, 0xfd, 0xe4,
+	0x5b, 0x44, 0x67, 0x85, 0x5b, 0xc3, 0x57, 0x29, 0x94, 0x19, 0x59, 0x79, 0x6c, 0x53, 0x43, 0x78,
+	0x59, 0x80, 0x36, 0x64, 0x82, 0x8f, 0x15, 0x68, 0xb1, 0x50, 0x1c, 0xa2, 0xdc, 0xd8, 0x40, 0xed,
+	0xb2, 0x57, 0xed, 0xf9, 0xb4, 0xa8, 0xd1, 0x57, 0x0f, 0x1a, 0xe6, 0xbe, 0x1c, 0x1c, 0xd6, 0x95,
+	0x2b, 0x74, 0xde, 0x10, 0x3e, 0x2b, 0xce, 0xd6, 0x52, 0xa4, 0x1a, 0x48, 0x8a, 0xeb, 0x92, 0x29,
+	0x13, 0xb3, 0x24, 0xd2, 0x0b, 0xce, 0x41, 0x6f, 0xb2, 0x91, 0x57, 0xed, 0x0d, 0xe9, 0xbe, 0xbb,
+	0x41, 0x9d, 0x80, 0xc0, 0xd2, 0xc6, 0x16, 0x16, 0xd6, 0xa4, 0xf3, 0xde, 0x31, 0xb8, 0xf5, 0xcb,
+	0x38, 0xb9, 0xc2, 0xa7, 0x0a, 0x9e, 0x81, 0x1b, 0x98, 0x46, 0x53, 0x66, 0x58, 0x24, 0x45, 0x9c,
+	0x1a, 0xdb, 0xa9, 0x1c, 0x92, 0xed, 0xda, 0x2d, 0x33, 0x2c, 0xc8, 0x56, 0xc8, 0x39, 0x3e, 0x02,
+	0xa5, 0x84, 0x8a, 0xe6, 0xa0, 0x35, 0x9b, 0x41, 0xa3, 0xd4, 0x46, 0xde, 0x41, 0x78, 0x98, 0x89,
+	0x23, 0xab, 0xf5, 0xd6, 0x08, 0xd7, 0xdc, 0x0d, 0x20, 0xef, 0x08, 0x57, 0x6c, 0x13, 0xf2, 0xd7,
+	0x4f, 0x75, 0xcf, 0xb1, 0x79, 0xf7, 0x5f, 0x8c, 0x3d, 0x92, 0xc1, 0x07, 0xc2, 0x17, 0xb1, 0xd8,
+	0x1b, 0x36, 0x38, 0x71, 0x39, 0xc1, 0x66, 0x32, 0x40, 0x93, 0xfe, 0xec, 0x3b, 0x23, 0x16, 0xf9,
+	0x9f, 0x2a, 0x4c, 0x22, 0x0b, 0x2f, 0xc6, 0xba, 0xe4, 0xdd, 0x4b, 0x48, 0x1f, 0x76, 0x96, 0x0c,
+	0x46, 0x6f, 0x76, 0xb1, 0x79, 0x14, 0x7d, 0xec, 0x3e, 0x55, 0x32, 0x56, 0xff, 0x73, 0x00, 0xa6,
+	0x3c, 0xb1, 0x32, 0x76, 0x03, 0x00, 0x00,
+}
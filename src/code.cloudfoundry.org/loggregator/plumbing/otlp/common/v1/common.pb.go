@@ -0,0 +1,429 @@
+// Code generated by protoc-gen-go.
+// source: opentelemetry/proto/common/v1/common.proto
+// DO NOT EDIT!
+
+/*
+Package v1 is a generated protocol buffer package.
+
+It is generated from these files:
+
+	opentelemetry/proto/common/v1/common.proto
+
+It has these top-level messages:
+
+	AnyValue
+	ArrayValue
+	KeyValueList
+	KeyValue
+	InstrumentationScope
+*/
+package v1
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type AnyValue struct {
+	// Types that are valid to be assigned to Value:
+	//	*AnyValue_StringValue
+	//	*AnyValue_BoolValue
+	//	*AnyValue_IntValue
+	//	*AnyValue_DoubleValue
+	//	*AnyValue_ArrayValue
+	//	*AnyValue_KvlistValue
+	//	*AnyValue_BytesValue
+	Value isAnyValue_Value `protobuf_oneof:"value"`
+}
+
+func (m *AnyValue) Reset()                    { *m = AnyValue{} }
+func (m *AnyValue) String() string            { return proto.CompactTextString(m) }
+func (*AnyValue) ProtoMessage()               {}
+func (*AnyValue) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+type isAnyValue_Value interface{ isAnyValue_Value() }
+
+type AnyValue_StringValue struct {
+	StringValue string `protobuf:"bytes,1,opt,name=string_value,json=stringValue,oneof"`
+}
+type AnyValue_BoolValue struct {
+	BoolValue bool `protobuf:"varint,2,opt,name=bool_value,json=boolValue,oneof"`
+}
+type AnyValue_IntValue struct {
+	IntValue int64 `protobuf:"varint,3,opt,name=int_value,json=intValue,oneof"`
+}
+type AnyValue_DoubleValue struct {
+	DoubleValue float64 `protobuf:"fixed64,4,opt,name=double_value,json=doubleValue,oneof"`
+}
+type AnyValue_ArrayValue struct {
+	ArrayValue *ArrayValue `protobuf:"bytes,5,opt,name=array_value,json=arrayValue,oneof"`
+}
+type AnyValue_KvlistValue struct {
+	KvlistValue *KeyValueList `protobuf:"bytes,6,opt,name=kvlist_value,json=kvlistValue,oneof"`
+}
+type AnyValue_BytesValue struct {
+	BytesValue []byte `protobuf:"bytes,7,opt,name=bytes_value,json=bytesValue,proto3,oneof"`
+}
+
+func (*AnyValue_StringValue) isAnyValue_Value() {}
+func (*AnyValue_BoolValue) isAnyValue_Value()   {}
+func (*AnyValue_IntValue) isAnyValue_Value()    {}
+func (*AnyValue_DoubleValue) isAnyValue_Value() {}
+func (*AnyValue_ArrayValue) isAnyValue_Value()  {}
+func (*AnyValue_KvlistValue) isAnyValue_Value() {}
+func (*AnyValue_BytesValue) isAnyValue_Value()  {}
+
+func (m *AnyValue) GetValue() isAnyValue_Value {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *AnyValue) GetStringValue() string {
+	if x, ok := m.GetValue().(*AnyValue_StringValue); ok {
+		return x.StringValue
+	}
+	return ""
+}
+
+func (m *AnyValue) GetBoolValue() bool {
+	if x, ok := m.GetValue().(*AnyValue_BoolValue); ok {
+		return x.BoolValue
+	}
+	return false
+}
+
+func (m *AnyValue) GetIntValue() int64 {
+	if x, ok := m.GetValue().(*AnyValue_IntValue); ok {
+		return x.IntValue
+	}
+	return 0
+}
+
+func (m *AnyValue) GetDoubleValue() float64 {
+	if x, ok := m.GetValue().(*AnyValue_DoubleValue); ok {
+		return x.DoubleValue
+	}
+	return 0
+}
+
+func (m *AnyValue) GetArrayValue() *ArrayValue {
+	if x, ok := m.GetValue().(*AnyValue_ArrayValue); ok {
+		return x.ArrayValue
+	}
+	return nil
+}
+
+func (m *AnyValue) GetKvlistValue() *KeyValueList {
+	if x, ok := m.GetValue().(*AnyValue_KvlistValue); ok {
+		return x.KvlistValue
+	}
+	return nil
+}
+
+func (m *AnyValue) GetBytesValue() []byte {
+	if x, ok := m.GetValue().(*AnyValue_BytesValue); ok {
+		return x.BytesValue
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*AnyValue) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _AnyValue_OneofMarshaler, _AnyValue_OneofUnmarshaler, _AnyValue_OneofSizer, []interface{}{
+		(*AnyValue_StringValue)(nil),
+		(*AnyValue_BoolValue)(nil),
+		(*AnyValue_IntValue)(nil),
+		(*AnyValue_DoubleValue)(nil),
+		(*AnyValue_ArrayValue)(nil),
+		(*AnyValue_KvlistValue)(nil),
+		(*AnyValue_BytesValue)(nil),
+	}
+}
+
+func _AnyValue_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*AnyValue)
+	// value
+	switch x := m.Value.(type) {
+	case *AnyValue_StringValue:
+		b.EncodeVarint(1<<3 | proto.WireBytes)
+		b.EncodeStringBytes(x.StringValue)
+	case *AnyValue_BoolValue:
+		t := uint64(0)
+		if x.BoolValue {
+			t = 1
+		}
+		b.EncodeVarint(2<<3 | proto.WireVarint)
+		b.EncodeVarint(t)
+	case *AnyValue_IntValue:
+		b.EncodeVarint(3<<3 | proto.WireVarint)
+		b.EncodeVarint(uint64(x.IntValue))
+	case *AnyValue_DoubleValue:
+		b.EncodeVarint(4<<3 | proto.WireFixed64)
+		b.EncodeFixed64(math.Float64bits(x.DoubleValue))
+	case *AnyValue_ArrayValue:
+		b.EncodeVarint(5<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.ArrayValue); err != nil {
+			return err
+		}
+	case *AnyValue_KvlistValue:
+		b.EncodeVarint(6<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.KvlistValue); err != nil {
+			return err
+		}
+	case *AnyValue_BytesValue:
+		b.EncodeVarint(7<<3 | proto.WireBytes)
+		b.EncodeRawBytes(x.BytesValue)
+	case nil:
+	default:
+		return fmt.Errorf("AnyValue.Value has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _AnyValue_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*AnyValue)
+	switch tag {
+	case 1: // value.string_value
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeStringBytes()
+		m.Value = &AnyValue_StringValue{x}
+		return true, err
+	case 2: // value.bool_value
+		if wire != proto.WireVarint {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeVarint()
+		m.Value = &AnyValue_BoolValue{x != 0}
+		return true, err
+	case 3: // value.int_value
+		if wire != proto.WireVarint {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeVarint()
+		m.Value = &AnyValue_IntValue{int64(x)}
+		return true, err
+	case 4: // value.double_value
+		if wire != proto.WireFixed64 {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeFixed64()
+		m.Value = &AnyValue_DoubleValue{math.Float64frombits(x)}
+		return true, err
+	case 5: // value.array_value
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(ArrayValue)
+		err := b.DecodeMessage(msg)
+		m.Value = &AnyValue_ArrayValue{msg}
+		return true, err
+	case 6: // value.kvlist_value
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(KeyValueList)
+		err := b.DecodeMessage(msg)
+		m.Value = &AnyValue_KvlistValue{msg}
+		return true, err
+	case 7: // value.bytes_value
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeRawBytes(true)
+		m.Value = &AnyValue_BytesValue{x}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _AnyValue_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*AnyValue)
+	// value
+	switch x := m.Value.(type) {
+	case *AnyValue_StringValue:
+		n += proto.SizeVarint(1<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(len(x.StringValue)))
+		n += len(x.StringValue)
+	case *AnyValue_BoolValue:
+		n += proto.SizeVarint(2<<3 | proto.WireVarint)
+		n += 1
+	case *AnyValue_IntValue:
+		n += proto.SizeVarint(3<<3 | proto.WireVarint)
+		n += proto.SizeVarint(uint64(x.IntValue))
+	case *AnyValue_DoubleValue:
+		n += proto.SizeVarint(4<<3 | proto.WireFixed64)
+		n += 8
+	case *AnyValue_ArrayValue:
+		s := proto.Size(x.ArrayValue)
+		n += proto.SizeVarint(5<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *AnyValue_KvlistValue:
+		s := proto.Size(x.KvlistValue)
+		n += proto.SizeVarint(6<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *AnyValue_BytesValue:
+		n += proto.SizeVarint(7<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(len(x.BytesValue)))
+		n += len(x.BytesValue)
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+type ArrayValue struct {
+	Values []*AnyValue `protobuf:"bytes,1,rep,name=values" json:"values,omitempty"`
+}
+
+func (m *ArrayValue) Reset()                    { *m = ArrayValue{} }
+func (m *ArrayValue) String() string            { return proto.CompactTextString(m) }
+func (*ArrayValue) ProtoMessage()               {}
+func (*ArrayValue) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *ArrayValue) GetValues() []*AnyValue {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+type KeyValueList struct {
+	Values []*KeyValue `protobuf:"bytes,1,rep,name=values" json:"values,omitempty"`
+}
+
+func (m *KeyValueList) Reset()                    { *m = KeyValueList{} }
+func (m *KeyValueList) String() string            { return proto.CompactTextString(m) }
+func (*KeyValueList) ProtoMessage()               {}
+func (*KeyValueList) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *KeyValueList) GetValues() []*KeyValue {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+type KeyValue struct {
+	Key   string    `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+	Value *AnyValue `protobuf:"bytes,2,opt,name=value" json:"value,omitempty"`
+}
+
+func (m *KeyValue) Reset()                    { *m = KeyValue{} }
+func (m *KeyValue) String() string            { return proto.CompactTextString(m) }
+func (*KeyValue) ProtoMessage()               {}
+func (*KeyValue) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *KeyValue) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *KeyValue) GetValue() *AnyValue {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+type InstrumentationScope struct {
+	Name                   string      `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Version                string      `protobuf:"bytes,2,opt,name=version" json:"version,omitempty"`
+	Attributes             []*KeyValue `protobuf:"bytes,3,rep,name=attributes" json:"attributes,omitempty"`
+	DroppedAttributesCount uint32      `protobuf:"varint,4,opt,name=dropped_attributes_count,json=droppedAttributesCount" json:"dropped_attributes_count,omitempty"`
+}
+
+func (m *InstrumentationScope) Reset()                    { *m = InstrumentationScope{} }
+func (m *InstrumentationScope) String() string            { return proto.CompactTextString(m) }
+func (*InstrumentationScope) ProtoMessage()               {}
+func (*InstrumentationScope) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *InstrumentationScope) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *InstrumentationScope) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *InstrumentationScope) GetAttributes() []*KeyValue {
+	if m != nil {
+		return m.Attributes
+	}
+	return nil
+}
+
+func (m *InstrumentationScope) GetDroppedAttributesCount() uint32 {
+	if m != nil {
+		return m.DroppedAttributesCount
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*AnyValue)(nil), "opentelemetry.proto.common.v1.AnyValue")
+	proto.RegisterType((*ArrayValue)(nil), "opentelemetry.proto.common.v1.ArrayValue")
+	proto.RegisterType((*KeyValueList)(nil), "opentelemetry.proto.common.v1.KeyValueList")
+	proto.RegisterType((*KeyValue)(nil), "opentelemetry.proto.common.v1.KeyValue")
+	proto.RegisterType((*InstrumentationScope)(nil), "opentelemetry.proto.common.v1.InstrumentationScope")
+}
+
+func init() { proto.RegisterFile("opentelemetry/proto/common/v1/common.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 464 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x53, 0xcd, 0x6e, 0xd3, 0x40,
+	0x10, 0xce, 0xc6, 0xcd, 0xdf, 0x38, 0x48, 0x68, 0x85, 0x90, 0x2f, 0x11, 0x26, 0x1c, 0x30, 0x20,
+	0x39, 0x4a, 0xb9, 0x70, 0x41, 0x28, 0xe9, 0x81, 0xa0, 0x16, 0x35, 0x5a, 0x50, 0x0f, 0x70, 0x88,
+	0xec, 0x64, 0x55, 0xad, 0x6a, 0xef, 0x5a, 0xeb, 0xb5, 0x25, 0x8b, 0x37, 0xe2, 0x45, 0x78, 0x0d,
+	0x1e, 0x05, 0xed, 0x4f, 0xe2, 0xd2, 0x43, 0xa3, 0xdc, 0x66, 0xbe, 0xf9, 0xbe, 0x6f, 0x66, 0x34,
+	0xbb, 0xf0, 0x56, 0x14, 0x94, 0x2b, 0x9a, 0xd1, 0x9c, 0x2a, 0xd9, 0xcc, 0x0a, 0x29, 0x94, 0x98,
+	0x6d, 0x45, 0x9e, 0x0b, 0x3e, 0xab, 0xe7, 0x2e, 0x8a, 0x0d, 0x8c, 0x27, 0xff, 0x71, 0x2d, 0x18,
+	0x3b, 0x46, 0x3d, 0x9f, 0xfe, 0xed, 0xc2, 0x70, 0xc1, 0x9b, 0x9b, 0x24, 0xab, 0x28, 0x7e, 0x05,
+	0xe3, 0x52, 0x49, 0xc6, 0x6f, 0x37, 0xb5, 0xce, 0x03, 0x14, 0xa2, 0x68, 0xb4, 0xea, 0x10, 0xdf,
+	0xa2, 0x96, 0xf4, 0x02, 0x20, 0x15, 0x22, 0x73, 0x94, 0x6e, 0x88, 0xa2, 0xe1, 0xaa, 0x43, 0x46,
+	0x1a, 0xb3, 0x84, 0x09, 0x8c, 0x18, 0x57, 0xae, 0xee, 0x85, 0x28, 0xf2, 0x56, 0x1d, 0x32, 0x64,
+	0x5c, 0x1d, 0x9a, 0xec, 0x44, 0x95, 0x66, 0xd4, 0x31, 0xce, 0x42, 0x14, 0x21, 0xdd, 0xc4, 0xa2,
+	0x96, 0x74, 0x05, 0x7e, 0x22, 0x65, 0xd2, 0x38, 0x4e, 0x2f, 0x44, 0x91, 0x7f, 0xfe, 0x26, 0x7e,
+	0x74, 0x97, 0x78, 0xa1, 0x15, 0x46, 0xbf, 0xea, 0x10, 0x48, 0x0e, 0x19, 0x5e, 0xc3, 0xf8, 0xae,
+	0xce, 0x58, 0xb9, 0x1f, 0xaa, 0x6f, 0xec, 0xde, 0x1d, 0xb1, 0xbb, 0xa4, 0x56, 0x7e, 0xc5, 0x4a,
+	0xa5, 0xe7, 0xb3, 0x16, 0xd6, 0xf1, 0x25, 0xf8, 0x69, 0xa3, 0x68, 0xe9, 0x0c, 0x07, 0x21, 0x8a,
+	0xc6, 0xba, 0xa9, 0x01, 0x0d, 0x65, 0x39, 0x80, 0x9e, 0x29, 0x4e, 0xbf, 0x02, 0xb4, 0x93, 0xe1,
+	0x4f, 0xd0, 0x37, 0x70, 0x19, 0xa0, 0xd0, 0x8b, 0xfc, 0xf3, 0xd7, 0xc7, 0x96, 0x72, 0xc7, 0x21,
+	0x4e, 0x36, 0xbd, 0x86, 0xf1, 0xfd, 0xc9, 0x4e, 0x36, 0xbc, 0xa4, 0x0f, 0x0c, 0x7f, 0xc2, 0x70,
+	0x8f, 0xe1, 0xa7, 0xe0, 0xdd, 0xd1, 0xc6, 0x1e, 0x9e, 0xe8, 0x10, 0x7f, 0x84, 0x5e, 0x7b, 0xe9,
+	0x13, 0xc6, 0x75, 0xcb, 0xff, 0x41, 0xf0, 0xec, 0x0b, 0x2f, 0x95, 0xac, 0x72, 0xca, 0x55, 0xa2,
+	0x98, 0xe0, 0xdf, 0xb6, 0xa2, 0xa0, 0x18, 0xc3, 0x19, 0x4f, 0x72, 0xf7, 0xc6, 0x88, 0x89, 0x71,
+	0x00, 0x83, 0x9a, 0xca, 0x92, 0x09, 0x6e, 0xba, 0x8d, 0xc8, 0x3e, 0xc5, 0x9f, 0x01, 0x12, 0xa5,
+	0x24, 0x4b, 0x2b, 0x45, 0xcb, 0xc0, 0x3b, 0x6d, 0xd1, 0x7b, 0x52, 0xfc, 0x01, 0x82, 0x9d, 0x14,
+	0x45, 0x41, 0x77, 0x9b, 0x16, 0xdd, 0x6c, 0x45, 0xc5, 0x95, 0x79, 0x89, 0x4f, 0xc8, 0x73, 0x57,
+	0x5f, 0x1c, 0xca, 0x17, 0xba, 0xba, 0xfc, 0x05, 0x21, 0x13, 0x8f, 0xb7, 0x5c, 0xfa, 0x17, 0x26,
+	0x5c, 0x6b, 0x78, 0x8d, 0x7e, 0x44, 0xb7, 0x0f, 0x05, 0x4c, 0xb8, 0xdf, 0x2a, 0x54, 0x56, 0xb4,
+	0x5f, 0xf6, 0x77, 0x77, 0x72, 0x5d, 0x50, 0xfe, 0xfd, 0xc0, 0x33, 0x0e, 0xb1, 0x75, 0x8b, 0x6f,
+	0xe6, 0x69, 0xdf, 0xa8, 0xde, 0xff, 0x1b, 0x00, 0x85, 0x19, 0x1b, 0xd7, 0xfa, 0x03, 0x00, 0x00,
+}
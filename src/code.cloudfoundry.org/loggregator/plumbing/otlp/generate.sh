@@ -0,0 +1,40 @@
+#!/bin/bash
+
+# Generates the Go packages of the OTLP protos. The protos under
+# opentelemetry/proto are copied from opentelemetry-proto v1.0.0. protoc-gen-go
+# does not support proto3 optional fields, so the optional fields of
+# metrics.proto are written as the single field oneofs protoc would
+# synthesize for them. This does not change the wire or JSON encoding.
+
+dir_resolve()
+{
+    cd "$1" 2>/dev/null || return $?  # cd to desired directory; if fail, quell any error messages but return exit status
+    echo "`pwd -P`" # output full, link-resolved path
+}
+
+set -e
+
+TARGET=`dirname $0`
+TARGET=`dir_resolve $TARGET`
+cd $TARGET
+
+go get github.com/golang/protobuf/{proto,protoc-gen-go}
+
+import_path=code.cloudfoundry.org/loggregator/plumbing/otlp
+
+mappings=""
+for pkg in common resource logs metrics; do
+    mappings="$mappings,Mopentelemetry/proto/$pkg/v1/$pkg.proto=$import_path/$pkg/v1"
+done
+
+tmp_dir=$(mktemp -d)
+
+for proto in `find opentelemetry -name '*.proto'`; do
+    protoc $proto --go_out=plugins=grpc$mappings:$tmp_dir --proto_path=.
+done
+
+for pkg in common resource logs metrics collector/logs collector/metrics; do
+    cp $tmp_dir/go.opentelemetry.io/proto/otlp/$pkg/v1/*.pb.go $pkg/v1
+done
+
+rm -r $tmp_dir
@@ -0,0 +1,382 @@
+// Code generated by protoc-gen-go.
+// source: opentelemetry/proto/logs/v1/logs.proto
+// DO NOT EDIT!
+
+/*
+Package v1 is a generated protocol buffer package.
+
+It is generated from these files:
+
+	opentelemetry/proto/logs/v1/logs.proto
+
+It has these top-level messages:
+
+	LogsData
+	ResourceLogs
+	ScopeLogs
+	LogRecord
+*/
+package v1
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import opentelemetry_proto_common_v1 "code.cloudfoundry.org/loggregator/plumbing/otlp/common/v1"
+import opentelemetry_proto_resource_v1 "code.cloudfoundry.org/loggregator/plumbing/otlp/resource/v1"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type SeverityNumber int32
+
+const (
+	SeverityNumber_SEVERITY_NUMBER_UNSPECIFIED SeverityNumber = 0
+	SeverityNumber_SEVERITY_NUMBER_TRACE       SeverityNumber = 1
+	SeverityNumber_SEVERITY_NUMBER_TRACE2      SeverityNumber = 2
+	SeverityNumber_SEVERITY_NUMBER_TRACE3      SeverityNumber = 3
+	SeverityNumber_SEVERITY_NUMBER_TRACE4      SeverityNumber = 4
+	SeverityNumber_SEVERITY_NUMBER_DEBUG       SeverityNumber = 5
+	SeverityNumber_SEVERITY_NUMBER_DEBUG2      SeverityNumber = 6
+	SeverityNumber_SEVERITY_NUMBER_DEBUG3      SeverityNumber = 7
+	SeverityNumber_SEVERITY_NUMBER_DEBUG4      SeverityNumber = 8
+	SeverityNumber_SEVERITY_NUMBER_INFO        SeverityNumber = 9
+	SeverityNumber_SEVERITY_NUMBER_INFO2       SeverityNumber = 10
+	SeverityNumber_SEVERITY_NUMBER_INFO3       SeverityNumber = 11
+	SeverityNumber_SEVERITY_NUMBER_INFO4       SeverityNumber = 12
+	SeverityNumber_SEVERITY_NUMBER_WARN        SeverityNumber = 13
+	SeverityNumber_SEVERITY_NUMBER_WARN2       SeverityNumber = 14
+	SeverityNumber_SEVERITY_NUMBER_WARN3       SeverityNumber = 15
+	SeverityNumber_SEVERITY_NUMBER_WARN4       SeverityNumber = 16
+	SeverityNumber_SEVERITY_NUMBER_ERROR       SeverityNumber = 17
+	SeverityNumber_SEVERITY_NUMBER_ERROR2      SeverityNumber = 18
+	SeverityNumber_SEVERITY_NUMBER_ERROR3      SeverityNumber = 19
+	SeverityNumber_SEVERITY_NUMBER_ERROR4      SeverityNumber = 20
+	SeverityNumber_SEVERITY_NUMBER_FATAL       SeverityNumber = 21
+	SeverityNumber_SEVERITY_NUMBER_FATAL2      SeverityNumber = 22
+	SeverityNumber_SEVERITY_NUMBER_FATAL3      SeverityNumber = 23
+	SeverityNumber_SEVERITY_NUMBER_FATAL4      SeverityNumber = 24
+)
+
+var SeverityNumber_name = map[int32]string{
+	0:  "SEVERITY_NUMBER_UNSPECIFIED",
+	1:  "SEVERITY_NUMBER_TRACE",
+	2:  "SEVERITY_NUMBER_TRACE2",
+	3:  "SEVERITY_NUMBER_TRACE3",
+	4:  "SEVERITY_NUMBER_TRACE4",
+	5:  "SEVERITY_NUMBER_DEBUG",
+	6:  "SEVERITY_NUMBER_DEBUG2",
+	7:  "SEVERITY_NUMBER_DEBUG3",
+	8:  "SEVERITY_NUMBER_DEBUG4",
+	9:  "SEVERITY_NUMBER_INFO",
+	10: "SEVERITY_NUMBER_INFO2",
+	11: "SEVERITY_NUMBER_INFO3",
+	12: "SEVERITY_NUMBER_INFO4",
+	13: "SEVERITY_NUMBER_WARN",
+	14: "SEVERITY_NUMBER_WARN2",
+	15: "SEVERITY_NUMBER_WARN3",
+	16: "SEVERITY_NUMBER_WARN4",
+	17: "SEVERITY_NUMBER_ERROR",
+	18: "SEVERITY_NUMBER_ERROR2",
+	19: "SEVERITY_NUMBER_ERROR3",
+	20: "SEVERITY_NUMBER_ERROR4",
+	21: "SEVERITY_NUMBER_FATAL",
+	22: "SEVERITY_NUMBER_FATAL2",
+	23: "SEVERITY_NUMBER_FATAL3",
+	24: "SEVERITY_NUMBER_FATAL4",
+}
+var SeverityNumber_value = map[string]int32{
+	"SEVERITY_NUMBER_UNSPECIFIED": 0,
+	"SEVERITY_NUMBER_TRACE":       1,
+	"SEVERITY_NUMBER_TRACE2":      2,
+	"SEVERITY_NUMBER_TRACE3":      3,
+	"SEVERITY_NUMBER_TRACE4":      4,
+	"SEVERITY_NUMBER_DEBUG":       5,
+	"SEVERITY_NUMBER_DEBUG2":      6,
+	"SEVERITY_NUMBER_DEBUG3":      7,
+	"SEVERITY_NUMBER_DEBUG4":      8,
+	"SEVERITY_NUMBER_INFO":        9,
+	"SEVERITY_NUMBER_INFO2":       10,
+	"SEVERITY_NUMBER_INFO3":       11,
+	"SEVERITY_NUMBER_INFO4":       12,
+	"SEVERITY_NUMBER_WARN":        13,
+	"SEVERITY_NUMBER_WARN2":       14,
+	"SEVERITY_NUMBER_WARN3":       15,
+	"SEVERITY_NUMBER_WARN4":       16,
+	"SEVERITY_NUMBER_ERROR":       17,
+	"SEVERITY_NUMBER_ERROR2":      18,
+	"SEVERITY_NUMBER_ERROR3":      19,
+	"SEVERITY_NUMBER_ERROR4":      20,
+	"SEVERITY_NUMBER_FATAL":       21,
+	"SEVERITY_NUMBER_FATAL2":      22,
+	"SEVERITY_NUMBER_FATAL3":      23,
+	"SEVERITY_NUMBER_FATAL4":      24,
+}
+
+func (x SeverityNumber) String() string {
+	return proto.EnumName(SeverityNumber_name, int32(x))
+}
+func (SeverityNumber) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+type LogRecordFlags int32
+
+const (
+	LogRecordFlags_LOG_RECORD_FLAGS_DO_NOT_USE       LogRecordFlags = 0
+	LogRecordFlags_LOG_RECORD_FLAGS_TRACE_FLAGS_MASK LogRecordFlags = 255
+)
+
+var LogRecordFlags_name = map[int32]string{
+	0:   "LOG_RECORD_FLAGS_DO_NOT_USE",
+	255: "LOG_RECORD_FLAGS_TRACE_FLAGS_MASK",
+}
+var LogRecordFlags_value = map[string]int32{
+	"LOG_RECORD_FLAGS_DO_NOT_USE":       0,
+	"LOG_RECORD_FLAGS_TRACE_FLAGS_MASK": 255,
+}
+
+func (x LogRecordFlags) String() string {
+	return proto.EnumName(LogRecordFlags_name, int32(x))
+}
+func (LogRecordFlags) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+type LogsData struct {
+	ResourceLogs []*ResourceLogs `protobuf:"bytes,1,rep,name=resource_logs,json=resourceLogs" json:"resource_logs,omitempty"`
+}
+
+func (m *LogsData) Reset()                    { *m = LogsData{} }
+func (m *LogsData) String() string            { return proto.CompactTextString(m) }
+func (*LogsData) ProtoMessage()               {}
+func (*LogsData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+func (m *LogsData) GetResourceLogs() []*ResourceLogs {
+	if m != nil {
+		return m.ResourceLogs
+	}
+	return nil
+}
+
+type ResourceLogs struct {
+	Resource  *opentelemetry_proto_resource_v1.Resource `protobuf:"bytes,1,opt,name=resource" json:"resource,omitempty"`
+	ScopeLogs []*ScopeLogs                              `protobuf:"bytes,2,rep,name=scope_logs,json=scopeLogs" json:"scope_logs,omitempty"`
+	SchemaUrl string                                    `protobuf:"bytes,3,opt,name=schema_url,json=schemaUrl" json:"schema_url,omitempty"`
+}
+
+func (m *ResourceLogs) Reset()                    { *m = ResourceLogs{} }
+func (m *ResourceLogs) String() string            { return proto.CompactTextString(m) }
+func (*ResourceLogs) ProtoMessage()               {}
+func (*ResourceLogs) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *ResourceLogs) GetResource() *opentelemetry_proto_resource_v1.Resource {
+	if m != nil {
+		return m.Resource
+	}
+	return nil
+}
+
+func (m *ResourceLogs) GetScopeLogs() []*ScopeLogs {
+	if m != nil {
+		return m.ScopeLogs
+	}
+	return nil
+}
+
+func (m *ResourceLogs) GetSchemaUrl() string {
+	if m != nil {
+		return m.SchemaUrl
+	}
+	return ""
+}
+
+type ScopeLogs struct {
+	Scope      *opentelemetry_proto_common_v1.InstrumentationScope `protobuf:"bytes,1,opt,name=scope" json:"scope,omitempty"`
+	LogRecords []*LogRecord                                        `protobuf:"bytes,2,rep,name=log_records,json=logRecords" json:"log_records,omitempty"`
+	SchemaUrl  string                                              `protobuf:"bytes,3,opt,name=schema_url,json=schemaUrl" json:"schema_url,omitempty"`
+}
+
+func (m *ScopeLogs) Reset()                    { *m = ScopeLogs{} }
+func (m *ScopeLogs) String() string            { return proto.CompactTextString(m) }
+func (*ScopeLogs) ProtoMessage()               {}
+func (*ScopeLogs) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *ScopeLogs) GetScope() *opentelemetry_proto_common_v1.InstrumentationScope {
+	if m != nil {
+		return m.Scope
+	}
+	return nil
+}
+
+func (m *ScopeLogs) GetLogRecords() []*LogRecord {
+	if m != nil {
+		return m.LogRecords
+	}
+	return nil
+}
+
+func (m *ScopeLogs) GetSchemaUrl() string {
+	if m != nil {
+		return m.SchemaUrl
+	}
+	return ""
+}
+
+type LogRecord struct {
+	TimeUnixNano           uint64                                    `protobuf:"fixed64,1,opt,name=time_unix_nano,json=timeUnixNano" json:"time_unix_nano,omitempty"`
+	ObservedTimeUnixNano   uint64                                    `protobuf:"fixed64,11,opt,name=observed_time_unix_nano,json=observedTimeUnixNano" json:"observed_time_unix_nano,omitempty"`
+	SeverityNumber         SeverityNumber                            `protobuf:"varint,2,opt,name=severity_number,json=severityNumber,enum=opentelemetry.proto.logs.v1.SeverityNumber" json:"severity_number,omitempty"`
+	SeverityText           string                                    `protobuf:"bytes,3,opt,name=severity_text,json=severityText" json:"severity_text,omitempty"`
+	Body                   *opentelemetry_proto_common_v1.AnyValue   `protobuf:"bytes,5,opt,name=body" json:"body,omitempty"`
+	Attributes             []*opentelemetry_proto_common_v1.KeyValue `protobuf:"bytes,6,rep,name=attributes" json:"attributes,omitempty"`
+	DroppedAttributesCount uint32                                    `protobuf:"varint,7,opt,name=dropped_attributes_count,json=droppedAttributesCount" json:"dropped_attributes_count,omitempty"`
+	Flags                  uint32                                    `protobuf:"fixed32,8,opt,name=flags" json:"flags,omitempty"`
+	TraceId                []byte                                    `protobuf:"bytes,9,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
+	SpanId                 []byte                                    `protobuf:"bytes,10,opt,name=span_id,json=spanId,proto3" json:"span_id,omitempty"`
+}
+
+func (m *LogRecord) Reset()                    { *m = LogRecord{} }
+func (m *LogRecord) String() string            { return proto.CompactTextString(m) }
+func (*LogRecord) ProtoMessage()               {}
+func (*LogRecord) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *LogRecord) GetTimeUnixNano() uint64 {
+	if m != nil {
+		return m.TimeUnixNano
+	}
+	return 0
+}
+
+func (m *LogRecord) GetObservedTimeUnixNano() uint64 {
+	if m != nil {
+		return m.ObservedTimeUnixNano
+	}
+	return 0
+}
+
+func (m *LogRecord) GetSeverityNumber() SeverityNumber {
+	if m != nil {
+		return m.SeverityNumber
+	}
+	return SeverityNumber_SEVERITY_NUMBER_UNSPECIFIED
+}
+
+func (m *LogRecord) GetSeverityText() string {
+	if m != nil {
+		return m.SeverityText
+	}
+	return ""
+}
+
+func (m *LogRecord) GetBody() *opentelemetry_proto_common_v1.AnyValue {
+	if m != nil {
+		return m.Body
+	}
+	return nil
+}
+
+func (m *LogRecord) GetAttributes() []*opentelemetry_proto_common_v1.KeyValue {
+	if m != nil {
+		return m.Attributes
+	}
+	return nil
+}
+
+func (m *LogRecord) GetDroppedAttributesCount() uint32 {
+	if m != nil {
+		return m.DroppedAttributesCount
+	}
+	return 0
+}
+
+func (m *LogRecord) GetFlags() uint32 {
+	if m != nil {
+		return m.Flags
+	}
+	return 0
+}
+
+func (m *LogRecord) GetTraceId() []byte {
+	if m != nil {
+		return m.TraceId
+	}
+	return nil
+}
+
+func (m *LogRecord) GetSpanId() []byte {
+	if m != nil {
+		return m.SpanId
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*LogsData)(nil), "opentelemetry.proto.logs.v1.LogsData")
+	proto.RegisterType((*ResourceLogs)(nil), "opentelemetry.proto.logs.v1.ResourceLogs")
+	proto.RegisterType((*ScopeLogs)(nil), "opentelemetry.proto.logs.v1.ScopeLogs")
+	proto.RegisterType((*LogRecord)(nil), "opentelemetry.proto.logs.v1.LogRecord")
+	proto.RegisterEnum("opentelemetry.proto.logs.v1.SeverityNumber", SeverityNumber_name, SeverityNumber_value)
+	proto.RegisterEnum("opentelemetry.proto.logs.v1.LogRecordFlags", LogRecordFlags_name, LogRecordFlags_value)
+}
+
+func init() { proto.RegisterFile("opentelemetry/proto/logs/v1/logs.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 826 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x95, 0xdf, 0x6e, 0xe2, 0x46,
+	0x14, 0xc6, 0xd7, 0x21, 0xfc, 0x3b, 0x21, 0xec, 0x74, 0x9a, 0x4d, 0xbc, 0x89, 0xda, 0xa5, 0x69,
+	0x95, 0xd2, 0x54, 0x22, 0x0a, 0x50, 0xa9, 0x52, 0xaf, 0x48, 0x30, 0x88, 0x5d, 0x16, 0xa2, 0x01,
+	0x52, 0xed, 0xde, 0x8c, 0x0c, 0x9e, 0x52, 0x4b, 0xc6, 0x63, 0x8d, 0xc7, 0x28, 0x79, 0xa5, 0x3e,
+	0x44, 0x6f, 0xf6, 0x65, 0xda, 0xeb, 0x3e, 0x40, 0x2b, 0x0f, 0xb6, 0x4b, 0xb2, 0x76, 0x76, 0xaf,
+	0x98, 0x39, 0xbf, 0xef, 0xfb, 0xe6, 0x8c, 0x07, 0x8f, 0xe1, 0x8c, 0x7b, 0xcc, 0x95, 0xcc, 0x61,
+	0x2b, 0x26, 0xc5, 0xfd, 0x85, 0x27, 0xb8, 0xe4, 0x17, 0x0e, 0x5f, 0xfa, 0x17, 0xeb, 0x4b, 0xf5,
+	0xdb, 0x50, 0x25, 0x7c, 0xf2, 0x40, 0xb7, 0x29, 0x36, 0x14, 0x5f, 0x5f, 0x1e, 0x9f, 0xa7, 0x85,
+	0x2c, 0xf8, 0x6a, 0xc5, 0xdd, 0x30, 0x66, 0x33, 0xda, 0x78, 0x8e, 0x1b, 0x69, 0x5a, 0xc1, 0x7c,
+	0x1e, 0x88, 0x05, 0x0b, 0xd5, 0xf1, 0x78, 0xa3, 0x3f, 0x7d, 0x0f, 0xa5, 0x21, 0x5f, 0xfa, 0x5d,
+	0x53, 0x9a, 0x78, 0x04, 0xfb, 0x31, 0xa5, 0xe1, 0xda, 0xba, 0x56, 0xcb, 0xd5, 0xf7, 0x9a, 0x3f,
+	0x34, 0x9e, 0x68, 0xae, 0x41, 0x22, 0x47, 0x98, 0x42, 0x2a, 0x62, 0x6b, 0x76, 0xfa, 0x41, 0x83,
+	0xca, 0x36, 0xc6, 0x06, 0x94, 0x62, 0x81, 0xae, 0xd5, 0xb4, 0xcc, 0xec, 0xa4, 0xc7, 0xad, 0x7c,
+	0x92, 0x58, 0xb1, 0x01, 0xe0, 0x2f, 0xb8, 0x17, 0x35, 0xb9, 0xa3, 0x9a, 0x3c, 0x7b, 0xb2, 0xc9,
+	0x49, 0x28, 0x57, 0x1d, 0x96, 0xfd, 0x78, 0x88, 0xbf, 0x0a, 0x63, 0x7e, 0x67, 0x2b, 0x93, 0x06,
+	0xc2, 0xd1, 0x73, 0x35, 0xad, 0x5e, 0x26, 0xe5, 0x4d, 0x65, 0x26, 0x9c, 0xd7, 0x85, 0xd2, 0x5f,
+	0x45, 0xf4, 0x77, 0xf1, 0xf4, 0x4f, 0x0d, 0xca, 0x89, 0x1f, 0x0f, 0x20, 0xaf, 0x12, 0xa2, 0xfe,
+	0x5b, 0xa9, 0xcb, 0x46, 0x27, 0xb2, 0xbe, 0x6c, 0x0c, 0x5c, 0x5f, 0x8a, 0x60, 0xc5, 0x5c, 0x69,
+	0x4a, 0x9b, 0xbb, 0x2a, 0x87, 0x6c, 0x12, 0x70, 0x1f, 0xf6, 0x1c, 0xbe, 0xa4, 0x82, 0x2d, 0xb8,
+	0xb0, 0x3e, 0x6f, 0x1f, 0x43, 0xbe, 0x24, 0x4a, 0x4e, 0xc0, 0x89, 0x87, 0x9f, 0xda, 0xc8, 0xe9,
+	0x3f, 0x39, 0x28, 0x27, 0x46, 0xfc, 0x1d, 0x54, 0xa5, 0xbd, 0x62, 0x34, 0x70, 0xed, 0x3b, 0xea,
+	0x9a, 0x2e, 0x57, 0x3b, 0x29, 0x90, 0x4a, 0x58, 0x9d, 0xb9, 0xf6, 0xdd, 0xc8, 0x74, 0x39, 0xfe,
+	0x09, 0x8e, 0xf8, 0xdc, 0x67, 0x62, 0xcd, 0x2c, 0xfa, 0x48, 0xbe, 0xa7, 0xe4, 0x07, 0x31, 0x9e,
+	0x6e, 0xdb, 0xa6, 0xf0, 0xdc, 0x67, 0x6b, 0x26, 0x6c, 0x79, 0x4f, 0xdd, 0x60, 0x35, 0x67, 0x42,
+	0xdf, 0xa9, 0x69, 0xf5, 0x6a, 0xf3, 0xc7, 0xa7, 0x8f, 0x27, 0xf2, 0x8c, 0x94, 0x85, 0x54, 0xfd,
+	0x07, 0x73, 0xfc, 0x2d, 0xec, 0x27, 0xa9, 0x92, 0xdd, 0xc9, 0x68, 0x8b, 0x95, 0xb8, 0x38, 0x65,
+	0x77, 0x12, 0xff, 0x02, 0xbb, 0x73, 0x6e, 0xdd, 0xeb, 0x79, 0x75, 0x2e, 0xdf, 0x7f, 0xe2, 0x5c,
+	0x3a, 0xee, 0xfd, 0xad, 0xe9, 0x04, 0x8c, 0x28, 0x13, 0xee, 0x03, 0x98, 0x52, 0x0a, 0x7b, 0x1e,
+	0x48, 0xe6, 0xeb, 0x85, 0x5a, 0xee, 0x33, 0x22, 0xde, 0xb0, 0x28, 0x62, 0xcb, 0x8a, 0x7f, 0x06,
+	0xdd, 0x12, 0xdc, 0xf3, 0x98, 0x45, 0xff, 0xaf, 0xd2, 0x05, 0x0f, 0x5c, 0xa9, 0x17, 0x6b, 0x5a,
+	0x7d, 0x9f, 0x1c, 0x46, 0xbc, 0x93, 0xe0, 0xeb, 0x90, 0xe2, 0x03, 0xc8, 0xff, 0xe6, 0x98, 0x4b,
+	0x5f, 0x2f, 0xd5, 0xb4, 0x7a, 0x91, 0x6c, 0x26, 0xf8, 0x25, 0x94, 0xa4, 0x30, 0x17, 0x8c, 0xda,
+	0x96, 0x5e, 0xae, 0x69, 0xf5, 0x0a, 0x29, 0xaa, 0xf9, 0xc0, 0xc2, 0x47, 0x50, 0xf4, 0x3d, 0xd3,
+	0x0d, 0x09, 0x28, 0x52, 0x08, 0xa7, 0x03, 0xeb, 0xf5, 0x6e, 0x69, 0x17, 0xe5, 0xcf, 0x3f, 0xe4,
+	0xa1, 0xfa, 0xf0, 0xb9, 0xe2, 0x57, 0x70, 0x32, 0x31, 0x6e, 0x0d, 0x32, 0x98, 0xbe, 0xa3, 0xa3,
+	0xd9, 0xdb, 0x2b, 0x83, 0xd0, 0xd9, 0x68, 0x72, 0x63, 0x5c, 0x0f, 0x7a, 0x03, 0xa3, 0x8b, 0x9e,
+	0xe1, 0x97, 0xf0, 0xe2, 0xb1, 0x60, 0x4a, 0x3a, 0xd7, 0x06, 0xd2, 0xf0, 0x31, 0x1c, 0xa6, 0xa2,
+	0x26, 0xda, 0xc9, 0x64, 0x2d, 0x94, 0xcb, 0x64, 0x6d, 0xb4, 0x9b, 0xb6, 0x5c, 0xd7, 0xb8, 0x9a,
+	0xf5, 0x51, 0x3e, 0xcd, 0xa6, 0x50, 0x13, 0x15, 0x32, 0x59, 0x0b, 0x15, 0x33, 0x59, 0x1b, 0x95,
+	0xb0, 0x0e, 0x07, 0x8f, 0xd9, 0x60, 0xd4, 0x1b, 0xa3, 0x72, 0x5a, 0x23, 0x21, 0x69, 0x22, 0xc8,
+	0x42, 0x2d, 0xb4, 0x97, 0x85, 0xda, 0xa8, 0x92, 0xb6, 0xd4, 0xaf, 0x1d, 0x32, 0x42, 0xfb, 0x69,
+	0xa6, 0x90, 0x34, 0x51, 0x35, 0x0b, 0xb5, 0xd0, 0xf3, 0x2c, 0xd4, 0x46, 0x28, 0x0d, 0x19, 0x84,
+	0x8c, 0x09, 0xfa, 0x22, 0xed, 0x61, 0x28, 0xd4, 0x44, 0x38, 0x93, 0xb5, 0xd0, 0x97, 0x99, 0xac,
+	0x8d, 0x0e, 0xd2, 0x96, 0xeb, 0x75, 0xa6, 0x9d, 0x21, 0x7a, 0x91, 0x66, 0x53, 0xa8, 0x89, 0x0e,
+	0x33, 0x59, 0x0b, 0x1d, 0x65, 0xb2, 0x36, 0xd2, 0xcf, 0xdf, 0x41, 0x35, 0xb9, 0xba, 0x7a, 0xea,
+	0x8d, 0x78, 0x05, 0x27, 0xc3, 0x71, 0x9f, 0x12, 0xe3, 0x7a, 0x4c, 0xba, 0xb4, 0x37, 0xec, 0xf4,
+	0x27, 0xb4, 0x3b, 0xa6, 0xa3, 0xf1, 0x94, 0xce, 0x26, 0x06, 0x7a, 0x86, 0xcf, 0xe0, 0x9b, 0x8f,
+	0x04, 0xea, 0x2f, 0x17, 0x8d, 0xdf, 0x76, 0x26, 0x6f, 0xd0, 0xbf, 0xda, 0x95, 0x0f, 0x5f, 0xdb,
+	0xfc, 0xa9, 0x6b, 0xe9, 0x2a, 0xbc, 0x35, 0xfd, 0x9b, 0xb0, 0x74, 0xa3, 0xbd, 0x3f, 0x5b, 0x3e,
+	0x16, 0xdb, 0x3c, 0xfa, 0xbc, 0x72, 0xe9, 0x78, 0xf1, 0x47, 0xfd, 0x8f, 0x9d, 0x93, 0xb1, 0xc7,
+	0xdc, 0x69, 0xa2, 0x52, 0xfe, 0xf0, 0xe2, 0xf6, 0x1b, 0xb7, 0x97, 0xf3, 0x82, 0x72, 0xb4, 0xfe,
+	0x1b, 0x00, 0x6c, 0xf3, 0x87, 0xcd, 0x18, 0x08, 0x00, 0x00,
+}
@@ -0,0 +1,1548 @@
+// Code generated by protoc-gen-go.
+// source: opentelemetry/proto/metrics/v1/metrics.proto
+// DO NOT EDIT!
+
+/*
+Package v1 is a generated protocol buffer package.
+
+It is generated from these files:
+
+	opentelemetry/proto/metrics/v1/metrics.proto
+
+It has these top-level messages:
+
+	MetricsData
+	ResourceMetrics
+	ScopeMetrics
+	Metric
+	Gauge
+	Sum
+	Histogram
+	ExponentialHistogram
+	Summary
+	NumberDataPoint
+	HistogramDataPoint
+	ExponentialHistogramDataPoint
+	SummaryDataPoint
+	Exemplar
+*/
+package v1
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import opentelemetry_proto_common_v1 "code.cloudfoundry.org/loggregator/plumbing/otlp/common/v1"
+import opentelemetry_proto_resource_v1 "code.cloudfoundry.org/loggregator/plumbing/otlp/resource/v1"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type AggregationTemporality int32
+
+const (
+	AggregationTemporality_AGGREGATION_TEMPORALITY_UNSPECIFIED AggregationTemporality = 0
+	AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA       AggregationTemporality = 1
+	AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE  AggregationTemporality = 2
+)
+
+var AggregationTemporality_name = map[int32]string{
+	0: "AGGREGATION_TEMPORALITY_UNSPECIFIED",
+	1: "AGGREGATION_TEMPORALITY_DELTA",
+	2: "AGGREGATION_TEMPORALITY_CUMULATIVE",
+}
+var AggregationTemporality_value = map[string]int32{
+	"AGGREGATION_TEMPORALITY_UNSPECIFIED": 0,
+	"AGGREGATION_TEMPORALITY_DELTA":       1,
+	"AGGREGATION_TEMPORALITY_CUMULATIVE":  2,
+}
+
+func (x AggregationTemporality) String() string {
+	return proto.EnumName(AggregationTemporality_name, int32(x))
+}
+func (AggregationTemporality) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+type DataPointFlags int32
+
+const (
+	DataPointFlags_DATA_POINT_FLAGS_DO_NOT_USE             DataPointFlags = 0
+	DataPointFlags_DATA_POINT_FLAGS_NO_RECORDED_VALUE_MASK DataPointFlags = 1
+)
+
+var DataPointFlags_name = map[int32]string{
+	0: "DATA_POINT_FLAGS_DO_NOT_USE",
+	1: "DATA_POINT_FLAGS_NO_RECORDED_VALUE_MASK",
+}
+var DataPointFlags_value = map[string]int32{
+	"DATA_POINT_FLAGS_DO_NOT_USE":             0,
+	"DATA_POINT_FLAGS_NO_RECORDED_VALUE_MASK": 1,
+}
+
+func (x DataPointFlags) String() string {
+	return proto.EnumName(DataPointFlags_name, int32(x))
+}
+func (DataPointFlags) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+type MetricsData struct {
+	ResourceMetrics []*ResourceMetrics `protobuf:"bytes,1,rep,name=resource_metrics,json=resourceMetrics" json:"resource_metrics,omitempty"`
+}
+
+func (m *MetricsData) Reset()                    { *m = MetricsData{} }
+func (m *MetricsData) String() string            { return proto.CompactTextString(m) }
+func (*MetricsData) ProtoMessage()               {}
+func (*MetricsData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+func (m *MetricsData) GetResourceMetrics() []*ResourceMetrics {
+	if m != nil {
+		return m.ResourceMetrics
+	}
+	return nil
+}
+
+type ResourceMetrics struct {
+	Resource     *opentelemetry_proto_resource_v1.Resource `protobuf:"bytes,1,opt,name=resource" json:"resource,omitempty"`
+	ScopeMetrics []*ScopeMetrics                           `protobuf:"bytes,2,rep,name=scope_metrics,json=scopeMetrics" json:"scope_metrics,omitempty"`
+	SchemaUrl    string                                    `protobuf:"bytes,3,opt,name=schema_url,json=schemaUrl" json:"schema_url,omitempty"`
+}
+
+func (m *ResourceMetrics) Reset()                    { *m = ResourceMetrics{} }
+func (m *ResourceMetrics) String() string            { return proto.CompactTextString(m) }
+func (*ResourceMetrics) ProtoMessage()               {}
+func (*ResourceMetrics) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *ResourceMetrics) GetResource() *opentelemetry_proto_resource_v1.Resource {
+	if m != nil {
+		return m.Resource
+	}
+	return nil
+}
+
+func (m *ResourceMetrics) GetScopeMetrics() []*ScopeMetrics {
+	if m != nil {
+		return m.ScopeMetrics
+	}
+	return nil
+}
+
+func (m *ResourceMetrics) GetSchemaUrl() string {
+	if m != nil {
+		return m.SchemaUrl
+	}
+	return ""
+}
+
+type ScopeMetrics struct {
+	Scope     *opentelemetry_proto_common_v1.InstrumentationScope `protobuf:"bytes,1,opt,name=scope" json:"scope,omitempty"`
+	Metrics   []*Metric                                           `protobuf:"bytes,2,rep,name=metrics" json:"metrics,omitempty"`
+	SchemaUrl string                                              `protobuf:"bytes,3,opt,name=schema_url,json=schemaUrl" json:"schema_url,omitempty"`
+}
+
+func (m *ScopeMetrics) Reset()                    { *m = ScopeMetrics{} }
+func (m *ScopeMetrics) String() string            { return proto.CompactTextString(m) }
+func (*ScopeMetrics) ProtoMessage()               {}
+func (*ScopeMetrics) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *ScopeMetrics) GetScope() *opentelemetry_proto_common_v1.InstrumentationScope {
+	if m != nil {
+		return m.Scope
+	}
+	return nil
+}
+
+func (m *ScopeMetrics) GetMetrics() []*Metric {
+	if m != nil {
+		return m.Metrics
+	}
+	return nil
+}
+
+func (m *ScopeMetrics) GetSchemaUrl() string {
+	if m != nil {
+		return m.SchemaUrl
+	}
+	return ""
+}
+
+type Metric struct {
+	Name        string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Description string `protobuf:"bytes,2,opt,name=description" json:"description,omitempty"`
+	Unit        string `protobuf:"bytes,3,opt,name=unit" json:"unit,omitempty"`
+	// Types that are valid to be assigned to Data:
+	//	*Metric_Gauge
+	//	*Metric_Sum
+	//	*Metric_Histogram
+	//	*Metric_ExponentialHistogram
+	//	*Metric_Summary
+	Data isMetric_Data `protobuf_oneof:"data"`
+}
+
+func (m *Metric) Reset()                    { *m = Metric{} }
+func (m *Metric) String() string            { return proto.CompactTextString(m) }
+func (*Metric) ProtoMessage()               {}
+func (*Metric) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+type isMetric_Data interface{ isMetric_Data() }
+
+type Metric_Gauge struct {
+	Gauge *Gauge `protobuf:"bytes,5,opt,name=gauge,oneof"`
+}
+type Metric_Sum struct {
+	Sum *Sum `protobuf:"bytes,7,opt,name=sum,oneof"`
+}
+type Metric_Histogram struct {
+	Histogram *Histogram `protobuf:"bytes,9,opt,name=histogram,oneof"`
+}
+type Metric_ExponentialHistogram struct {
+	ExponentialHistogram *ExponentialHistogram `protobuf:"bytes,10,opt,name=exponential_histogram,json=exponentialHistogram,oneof"`
+}
+type Metric_Summary struct {
+	Summary *Summary `protobuf:"bytes,11,opt,name=summary,oneof"`
+}
+
+func (*Metric_Gauge) isMetric_Data()                {}
+func (*Metric_Sum) isMetric_Data()                  {}
+func (*Metric_Histogram) isMetric_Data()            {}
+func (*Metric_ExponentialHistogram) isMetric_Data() {}
+func (*Metric_Summary) isMetric_Data()              {}
+
+func (m *Metric) GetData() isMetric_Data {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *Metric) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Metric) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *Metric) GetUnit() string {
+	if m != nil {
+		return m.Unit
+	}
+	return ""
+}
+
+func (m *Metric) GetGauge() *Gauge {
+	if x, ok := m.GetData().(*Metric_Gauge); ok {
+		return x.Gauge
+	}
+	return nil
+}
+
+func (m *Metric) GetSum() *Sum {
+	if x, ok := m.GetData().(*Metric_Sum); ok {
+		return x.Sum
+	}
+	return nil
+}
+
+func (m *Metric) GetHistogram() *Histogram {
+	if x, ok := m.GetData().(*Metric_Histogram); ok {
+		return x.Histogram
+	}
+	return nil
+}
+
+func (m *Metric) GetExponentialHistogram() *ExponentialHistogram {
+	if x, ok := m.GetData().(*Metric_ExponentialHistogram); ok {
+		return x.ExponentialHistogram
+	}
+	return nil
+}
+
+func (m *Metric) GetSummary() *Summary {
+	if x, ok := m.GetData().(*Metric_Summary); ok {
+		return x.Summary
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*Metric) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _Metric_OneofMarshaler, _Metric_OneofUnmarshaler, _Metric_OneofSizer, []interface{}{
+		(*Metric_Gauge)(nil),
+		(*Metric_Sum)(nil),
+		(*Metric_Histogram)(nil),
+		(*Metric_ExponentialHistogram)(nil),
+		(*Metric_Summary)(nil),
+	}
+}
+
+func _Metric_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*Metric)
+	// data
+	switch x := m.Data.(type) {
+	case *Metric_Gauge:
+		b.EncodeVarint(5<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Gauge); err != nil {
+			return err
+		}
+	case *Metric_Sum:
+		b.EncodeVarint(7<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Sum); err != nil {
+			return err
+		}
+	case *Metric_Histogram:
+		b.EncodeVarint(9<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Histogram); err != nil {
+			return err
+		}
+	case *Metric_ExponentialHistogram:
+		b.EncodeVarint(10<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.ExponentialHistogram); err != nil {
+			return err
+		}
+	case *Metric_Summary:
+		b.EncodeVarint(11<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Summary); err != nil {
+			return err
+		}
+	case nil:
+	default:
+		return fmt.Errorf("Metric.Data has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _Metric_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*Metric)
+	switch tag {
+	case 5: // data.gauge
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Gauge)
+		err := b.DecodeMessage(msg)
+		m.Data = &Metric_Gauge{msg}
+		return true, err
+	case 7: // data.sum
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Sum)
+		err := b.DecodeMessage(msg)
+		m.Data = &Metric_Sum{msg}
+		return true, err
+	case 9: // data.histogram
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Histogram)
+		err := b.DecodeMessage(msg)
+		m.Data = &Metric_Histogram{msg}
+		return true, err
+	case 10: // data.exponential_histogram
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(ExponentialHistogram)
+		err := b.DecodeMessage(msg)
+		m.Data = &Metric_ExponentialHistogram{msg}
+		return true, err
+	case 11: // data.summary
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Summary)
+		err := b.DecodeMessage(msg)
+		m.Data = &Metric_Summary{msg}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _Metric_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*Metric)
+	// data
+	switch x := m.Data.(type) {
+	case *Metric_Gauge:
+		s := proto.Size(x.Gauge)
+		n += proto.SizeVarint(5<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Metric_Sum:
+		s := proto.Size(x.Sum)
+		n += proto.SizeVarint(7<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Metric_Histogram:
+		s := proto.Size(x.Histogram)
+		n += proto.SizeVarint(9<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Metric_ExponentialHistogram:
+		s := proto.Size(x.ExponentialHistogram)
+		n += proto.SizeVarint(10<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Metric_Summary:
+		s := proto.Size(x.Summary)
+		n += proto.SizeVarint(11<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+type Gauge struct {
+	DataPoints []*NumberDataPoint `protobuf:"bytes,1,rep,name=data_points,json=dataPoints" json:"data_points,omitempty"`
+}
+
+func (m *Gauge) Reset()                    { *m = Gauge{} }
+func (m *Gauge) String() string            { return proto.CompactTextString(m) }
+func (*Gauge) ProtoMessage()               {}
+func (*Gauge) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *Gauge) GetDataPoints() []*NumberDataPoint {
+	if m != nil {
+		return m.DataPoints
+	}
+	return nil
+}
+
+type Sum struct {
+	DataPoints             []*NumberDataPoint     `protobuf:"bytes,1,rep,name=data_points,json=dataPoints" json:"data_points,omitempty"`
+	AggregationTemporality AggregationTemporality `protobuf:"varint,2,opt,name=aggregation_temporality,json=aggregationTemporality,enum=opentelemetry.proto.metrics.v1.AggregationTemporality" json:"aggregation_temporality,omitempty"`
+	IsMonotonic            bool                   `protobuf:"varint,3,opt,name=is_monotonic,json=isMonotonic" json:"is_monotonic,omitempty"`
+}
+
+func (m *Sum) Reset()                    { *m = Sum{} }
+func (m *Sum) String() string            { return proto.CompactTextString(m) }
+func (*Sum) ProtoMessage()               {}
+func (*Sum) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *Sum) GetDataPoints() []*NumberDataPoint {
+	if m != nil {
+		return m.DataPoints
+	}
+	return nil
+}
+
+func (m *Sum) GetAggregationTemporality() AggregationTemporality {
+	if m != nil {
+		return m.AggregationTemporality
+	}
+	return AggregationTemporality_AGGREGATION_TEMPORALITY_UNSPECIFIED
+}
+
+func (m *Sum) GetIsMonotonic() bool {
+	if m != nil {
+		return m.IsMonotonic
+	}
+	return false
+}
+
+type Histogram struct {
+	DataPoints             []*HistogramDataPoint  `protobuf:"bytes,1,rep,name=data_points,json=dataPoints" json:"data_points,omitempty"`
+	AggregationTemporality AggregationTemporality `protobuf:"varint,2,opt,name=aggregation_temporality,json=aggregationTemporality,enum=opentelemetry.proto.metrics.v1.AggregationTemporality" json:"aggregation_temporality,omitempty"`
+}
+
+func (m *Histogram) Reset()                    { *m = Histogram{} }
+func (m *Histogram) String() string            { return proto.CompactTextString(m) }
+func (*Histogram) ProtoMessage()               {}
+func (*Histogram) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *Histogram) GetDataPoints() []*HistogramDataPoint {
+	if m != nil {
+		return m.DataPoints
+	}
+	return nil
+}
+
+func (m *Histogram) GetAggregationTemporality() AggregationTemporality {
+	if m != nil {
+		return m.AggregationTemporality
+	}
+	return AggregationTemporality_AGGREGATION_TEMPORALITY_UNSPECIFIED
+}
+
+type ExponentialHistogram struct {
+	DataPoints             []*ExponentialHistogramDataPoint `protobuf:"bytes,1,rep,name=data_points,json=dataPoints" json:"data_points,omitempty"`
+	AggregationTemporality AggregationTemporality           `protobuf:"varint,2,opt,name=aggregation_temporality,json=aggregationTemporality,enum=opentelemetry.proto.metrics.v1.AggregationTemporality" json:"aggregation_temporality,omitempty"`
+}
+
+func (m *ExponentialHistogram) Reset()                    { *m = ExponentialHistogram{} }
+func (m *ExponentialHistogram) String() string            { return proto.CompactTextString(m) }
+func (*ExponentialHistogram) ProtoMessage()               {}
+func (*ExponentialHistogram) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *ExponentialHistogram) GetDataPoints() []*ExponentialHistogramDataPoint {
+	if m != nil {
+		return m.DataPoints
+	}
+	return nil
+}
+
+func (m *ExponentialHistogram) GetAggregationTemporality() AggregationTemporality {
+	if m != nil {
+		return m.AggregationTemporality
+	}
+	return AggregationTemporality_AGGREGATION_TEMPORALITY_UNSPECIFIED
+}
+
+type Summary struct {
+	DataPoints []*SummaryDataPoint `protobuf:"bytes,1,rep,name=data_points,json=dataPoints" json:"data_points,omitempty"`
+}
+
+func (m *Summary) Reset()                    { *m = Summary{} }
+func (m *Summary) String() string            { return proto.CompactTextString(m) }
+func (*Summary) ProtoMessage()               {}
+func (*Summary) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *Summary) GetDataPoints() []*SummaryDataPoint {
+	if m != nil {
+		return m.DataPoints
+	}
+	return nil
+}
+
+type NumberDataPoint struct {
+	Attributes        []*opentelemetry_proto_common_v1.KeyValue `protobuf:"bytes,7,rep,name=attributes" json:"attributes,omitempty"`
+	StartTimeUnixNano uint64                                    `protobuf:"fixed64,2,opt,name=start_time_unix_nano,json=startTimeUnixNano" json:"start_time_unix_nano,omitempty"`
+	TimeUnixNano      uint64                                    `protobuf:"fixed64,3,opt,name=time_unix_nano,json=timeUnixNano" json:"time_unix_nano,omitempty"`
+	// Types that are valid to be assigned to Value:
+	//	*NumberDataPoint_AsDouble
+	//	*NumberDataPoint_AsInt
+	Value     isNumberDataPoint_Value `protobuf_oneof:"value"`
+	Exemplars []*Exemplar             `protobuf:"bytes,5,rep,name=exemplars" json:"exemplars,omitempty"`
+	Flags     uint32                  `protobuf:"varint,8,opt,name=flags" json:"flags,omitempty"`
+}
+
+func (m *NumberDataPoint) Reset()                    { *m = NumberDataPoint{} }
+func (m *NumberDataPoint) String() string            { return proto.CompactTextString(m) }
+func (*NumberDataPoint) ProtoMessage()               {}
+func (*NumberDataPoint) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+type isNumberDataPoint_Value interface{ isNumberDataPoint_Value() }
+
+type NumberDataPoint_AsDouble struct {
+	AsDouble float64 `protobuf:"fixed64,4,opt,name=as_double,json=asDouble,oneof"`
+}
+type NumberDataPoint_AsInt struct {
+	AsInt int64 `protobuf:"fixed64,6,opt,name=as_int,json=asInt,oneof"`
+}
+
+func (*NumberDataPoint_AsDouble) isNumberDataPoint_Value() {}
+func (*NumberDataPoint_AsInt) isNumberDataPoint_Value()    {}
+
+func (m *NumberDataPoint) GetValue() isNumberDataPoint_Value {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *NumberDataPoint) GetAttributes() []*opentelemetry_proto_common_v1.KeyValue {
+	if m != nil {
+		return m.Attributes
+	}
+	return nil
+}
+
+func (m *NumberDataPoint) GetStartTimeUnixNano() uint64 {
+	if m != nil {
+		return m.StartTimeUnixNano
+	}
+	return 0
+}
+
+func (m *NumberDataPoint) GetTimeUnixNano() uint64 {
+	if m != nil {
+		return m.TimeUnixNano
+	}
+	return 0
+}
+
+func (m *NumberDataPoint) GetAsDouble() float64 {
+	if x, ok := m.GetValue().(*NumberDataPoint_AsDouble); ok {
+		return x.AsDouble
+	}
+	return 0
+}
+
+func (m *NumberDataPoint) GetAsInt() int64 {
+	if x, ok := m.GetValue().(*NumberDataPoint_AsInt); ok {
+		return x.AsInt
+	}
+	return 0
+}
+
+func (m *NumberDataPoint) GetExemplars() []*Exemplar {
+	if m != nil {
+		return m.Exemplars
+	}
+	return nil
+}
+
+func (m *NumberDataPoint) GetFlags() uint32 {
+	if m != nil {
+		return m.Flags
+	}
+	return 0
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*NumberDataPoint) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _NumberDataPoint_OneofMarshaler, _NumberDataPoint_OneofUnmarshaler, _NumberDataPoint_OneofSizer, []interface{}{
+		(*NumberDataPoint_AsDouble)(nil),
+		(*NumberDataPoint_AsInt)(nil),
+	}
+}
+
+func _NumberDataPoint_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*NumberDataPoint)
+	// value
+	switch x := m.Value.(type) {
+	case *NumberDataPoint_AsDouble:
+		b.EncodeVarint(4<<3 | proto.WireFixed64)
+		b.EncodeFixed64(math.Float64bits(x.AsDouble))
+	case *NumberDataPoint_AsInt:
+		b.EncodeVarint(6<<3 | proto.WireFixed64)
+		b.EncodeFixed64(uint64(x.AsInt))
+	case nil:
+	default:
+		return fmt.Errorf("NumberDataPoint.Value has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _NumberDataPoint_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*NumberDataPoint)
+	switch tag {
+	case 4: // value.as_double
+		if wire != proto.WireFixed64 {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeFixed64()
+		m.Value = &NumberDataPoint_AsDouble{math.Float64frombits(x)}
+		return true, err
+	case 6: // value.as_int
+		if wire != proto.WireFixed64 {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeFixed64()
+		m.Value = &NumberDataPoint_AsInt{int64(x)}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _NumberDataPoint_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*NumberDataPoint)
+	// value
+	switch x := m.Value.(type) {
+	case *NumberDataPoint_AsDouble:
+		n += proto.SizeVarint(4<<3 | proto.WireFixed64)
+		n += 8
+	case *NumberDataPoint_AsInt:
+		n += proto.SizeVarint(6<<3 | proto.WireFixed64)
+		n += 8
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+type HistogramDataPoint struct {
+	Attributes        []*opentelemetry_proto_common_v1.KeyValue `protobuf:"bytes,9,rep,name=attributes" json:"attributes,omitempty"`
+	StartTimeUnixNano uint64                                    `protobuf:"fixed64,2,opt,name=start_time_unix_nano,json=startTimeUnixNano" json:"start_time_unix_nano,omitempty"`
+	TimeUnixNano      uint64                                    `protobuf:"fixed64,3,opt,name=time_unix_nano,json=timeUnixNano" json:"time_unix_nano,omitempty"`
+	Count             uint64                                    `protobuf:"fixed64,4,opt,name=count" json:"count,omitempty"`
+	// Types that are valid to be assigned to XSum:
+	//	*HistogramDataPoint_Sum
+	XSum           isHistogramDataPoint_XSum `protobuf_oneof:"_sum"`
+	BucketCounts   []uint64                  `protobuf:"fixed64,6,rep,packed,name=bucket_counts,json=bucketCounts" json:"bucket_counts,omitempty"`
+	ExplicitBounds []float64                 `protobuf:"fixed64,7,rep,packed,name=explicit_bounds,json=explicitBounds" json:"explicit_bounds,omitempty"`
+	Exemplars      []*Exemplar               `protobuf:"bytes,8,rep,name=exemplars" json:"exemplars,omitempty"`
+	Flags          uint32                    `protobuf:"varint,10,opt,name=flags" json:"flags,omitempty"`
+	// Types that are valid to be assigned to XMin:
+	//	*HistogramDataPoint_Min
+	XMin isHistogramDataPoint_XMin `protobuf_oneof:"_min"`
+	// Types that are valid to be assigned to XMax:
+	//	*HistogramDataPoint_Max
+	XMax isHistogramDataPoint_XMax `protobuf_oneof:"_max"`
+}
+
+func (m *HistogramDataPoint) Reset()                    { *m = HistogramDataPoint{} }
+func (m *HistogramDataPoint) String() string            { return proto.CompactTextString(m) }
+func (*HistogramDataPoint) ProtoMessage()               {}
+func (*HistogramDataPoint) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+type isHistogramDataPoint_XSum interface{ isHistogramDataPoint_XSum() }
+type isHistogramDataPoint_XMin interface{ isHistogramDataPoint_XMin() }
+type isHistogramDataPoint_XMax interface{ isHistogramDataPoint_XMax() }
+
+type HistogramDataPoint_Sum struct {
+	Sum float64 `protobuf:"fixed64,5,opt,name=sum,oneof"`
+}
+type HistogramDataPoint_Min struct {
+	Min float64 `protobuf:"fixed64,11,opt,name=min,oneof"`
+}
+type HistogramDataPoint_Max struct {
+	Max float64 `protobuf:"fixed64,12,opt,name=max,oneof"`
+}
+
+func (*HistogramDataPoint_Sum) isHistogramDataPoint_XSum() {}
+func (*HistogramDataPoint_Min) isHistogramDataPoint_XMin() {}
+func (*HistogramDataPoint_Max) isHistogramDataPoint_XMax() {}
+
+func (m *HistogramDataPoint) GetXSum() isHistogramDataPoint_XSum {
+	if m != nil {
+		return m.XSum
+	}
+	return nil
+}
+func (m *HistogramDataPoint) GetXMin() isHistogramDataPoint_XMin {
+	if m != nil {
+		return m.XMin
+	}
+	return nil
+}
+func (m *HistogramDataPoint) GetXMax() isHistogramDataPoint_XMax {
+	if m != nil {
+		return m.XMax
+	}
+	return nil
+}
+
+func (m *HistogramDataPoint) GetAttributes() []*opentelemetry_proto_common_v1.KeyValue {
+	if m != nil {
+		return m.Attributes
+	}
+	return nil
+}
+
+func (m *HistogramDataPoint) GetStartTimeUnixNano() uint64 {
+	if m != nil {
+		return m.StartTimeUnixNano
+	}
+	return 0
+}
+
+func (m *HistogramDataPoint) GetTimeUnixNano() uint64 {
+	if m != nil {
+		return m.TimeUnixNano
+	}
+	return 0
+}
+
+func (m *HistogramDataPoint) GetCount() uint64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *HistogramDataPoint) GetSum() float64 {
+	if x, ok := m.GetXSum().(*HistogramDataPoint_Sum); ok {
+		return x.Sum
+	}
+	return 0
+}
+
+func (m *HistogramDataPoint) GetBucketCounts() []uint64 {
+	if m != nil {
+		return m.BucketCounts
+	}
+	return nil
+}
+
+func (m *HistogramDataPoint) GetExplicitBounds() []float64 {
+	if m != nil {
+		return m.ExplicitBounds
+	}
+	return nil
+}
+
+func (m *HistogramDataPoint) GetExemplars() []*Exemplar {
+	if m != nil {
+		return m.Exemplars
+	}
+	return nil
+}
+
+func (m *HistogramDataPoint) GetFlags() uint32 {
+	if m != nil {
+		return m.Flags
+	}
+	return 0
+}
+
+func (m *HistogramDataPoint) GetMin() float64 {
+	if x, ok := m.GetXMin().(*HistogramDataPoint_Min); ok {
+		return x.Min
+	}
+	return 0
+}
+
+func (m *HistogramDataPoint) GetMax() float64 {
+	if x, ok := m.GetXMax().(*HistogramDataPoint_Max); ok {
+		return x.Max
+	}
+	return 0
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*HistogramDataPoint) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _HistogramDataPoint_OneofMarshaler, _HistogramDataPoint_OneofUnmarshaler, _HistogramDataPoint_OneofSizer, []interface{}{
+		(*HistogramDataPoint_Sum)(nil),
+		(*HistogramDataPoint_Min)(nil),
+		(*HistogramDataPoint_Max)(nil),
+	}
+}
+
+func _HistogramDataPoint_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*HistogramDataPoint)
+	// _sum
+	switch x := m.XSum.(type) {
+	case *HistogramDataPoint_Sum:
+		b.EncodeVarint(5<<3 | proto.WireFixed64)
+		b.EncodeFixed64(math.Float64bits(x.Sum))
+	case nil:
+	default:
+		return fmt.Errorf("HistogramDataPoint.XSum has unexpected type %T", x)
+	}
+	// _min
+	switch x := m.XMin.(type) {
+	case *HistogramDataPoint_Min:
+		b.EncodeVarint(11<<3 | proto.WireFixed64)
+		b.EncodeFixed64(math.Float64bits(x.Min))
+	case nil:
+	default:
+		return fmt.Errorf("HistogramDataPoint.XMin has unexpected type %T", x)
+	}
+	// _max
+	switch x := m.XMax.(type) {
+	case *HistogramDataPoint_Max:
+		b.EncodeVarint(12<<3 | proto.WireFixed64)
+		b.EncodeFixed64(math.Float64bits(x.Max))
+	case nil:
+	default:
+		return fmt.Errorf("HistogramDataPoint.XMax has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _HistogramDataPoint_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*HistogramDataPoint)
+	switch tag {
+	case 5: // _sum.sum
+		if wire != proto.WireFixed64 {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeFixed64()
+		m.XSum = &HistogramDataPoint_Sum{math.Float64frombits(x)}
+		return true, err
+	case 11: // _min.min
+		if wire != proto.WireFixed64 {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeFixed64()
+		m.XMin = &HistogramDataPoint_Min{math.Float64frombits(x)}
+		return true, err
+	case 12: // _max.max
+		if wire != proto.WireFixed64 {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeFixed64()
+		m.XMax = &HistogramDataPoint_Max{math.Float64frombits(x)}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _HistogramDataPoint_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*HistogramDataPoint)
+	// _sum
+	switch x := m.XSum.(type) {
+	case *HistogramDataPoint_Sum:
+		n += proto.SizeVarint(5<<3 | proto.WireFixed64)
+		n += 8
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	// _min
+	switch x := m.XMin.(type) {
+	case *HistogramDataPoint_Min:
+		n += proto.SizeVarint(11<<3 | proto.WireFixed64)
+		n += 8
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	// _max
+	switch x := m.XMax.(type) {
+	case *HistogramDataPoint_Max:
+		n += proto.SizeVarint(12<<3 | proto.WireFixed64)
+		n += 8
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+type ExponentialHistogramDataPoint struct {
+	Attributes        []*opentelemetry_proto_common_v1.KeyValue `protobuf:"bytes,1,rep,name=attributes" json:"attributes,omitempty"`
+	StartTimeUnixNano uint64                                    `protobuf:"fixed64,2,opt,name=start_time_unix_nano,json=startTimeUnixNano" json:"start_time_unix_nano,omitempty"`
+	TimeUnixNano      uint64                                    `protobuf:"fixed64,3,opt,name=time_unix_nano,json=timeUnixNano" json:"time_unix_nano,omitempty"`
+	Count             uint64                                    `protobuf:"fixed64,4,opt,name=count" json:"count,omitempty"`
+	// Types that are valid to be assigned to XSum:
+	//	*ExponentialHistogramDataPoint_Sum
+	XSum      isExponentialHistogramDataPoint_XSum   `protobuf_oneof:"_sum"`
+	Scale     int32                                  `protobuf:"zigzag32,6,opt,name=scale" json:"scale,omitempty"`
+	ZeroCount uint64                                 `protobuf:"fixed64,7,opt,name=zero_count,json=zeroCount" json:"zero_count,omitempty"`
+	Positive  *ExponentialHistogramDataPoint_Buckets `protobuf:"bytes,8,opt,name=positive" json:"positive,omitempty"`
+	Negative  *ExponentialHistogramDataPoint_Buckets `protobuf:"bytes,9,opt,name=negative" json:"negative,omitempty"`
+	Flags     uint32                                 `protobuf:"varint,10,opt,name=flags" json:"flags,omitempty"`
+	Exemplars []*Exemplar                            `protobuf:"bytes,11,rep,name=exemplars" json:"exemplars,omitempty"`
+	// Types that are valid to be assigned to XMin:
+	//	*ExponentialHistogramDataPoint_Min
+	XMin isExponentialHistogramDataPoint_XMin `protobuf_oneof:"_min"`
+	// Types that are valid to be assigned to XMax:
+	//	*ExponentialHistogramDataPoint_Max
+	XMax          isExponentialHistogramDataPoint_XMax `protobuf_oneof:"_max"`
+	ZeroThreshold float64                              `protobuf:"fixed64,14,opt,name=zero_threshold,json=zeroThreshold" json:"zero_threshold,omitempty"`
+}
+
+func (m *ExponentialHistogramDataPoint) Reset()                    { *m = ExponentialHistogramDataPoint{} }
+func (m *ExponentialHistogramDataPoint) String() string            { return proto.CompactTextString(m) }
+func (*ExponentialHistogramDataPoint) ProtoMessage()               {}
+func (*ExponentialHistogramDataPoint) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+type isExponentialHistogramDataPoint_XSum interface{ isExponentialHistogramDataPoint_XSum() }
+type isExponentialHistogramDataPoint_XMin interface{ isExponentialHistogramDataPoint_XMin() }
+type isExponentialHistogramDataPoint_XMax interface{ isExponentialHistogramDataPoint_XMax() }
+
+type ExponentialHistogramDataPoint_Sum struct {
+	Sum float64 `protobuf:"fixed64,5,opt,name=sum,oneof"`
+}
+type ExponentialHistogramDataPoint_Min struct {
+	Min float64 `protobuf:"fixed64,12,opt,name=min,oneof"`
+}
+type ExponentialHistogramDataPoint_Max struct {
+	Max float64 `protobuf:"fixed64,13,opt,name=max,oneof"`
+}
+
+func (*ExponentialHistogramDataPoint_Sum) isExponentialHistogramDataPoint_XSum() {}
+func (*ExponentialHistogramDataPoint_Min) isExponentialHistogramDataPoint_XMin() {}
+func (*ExponentialHistogramDataPoint_Max) isExponentialHistogramDataPoint_XMax() {}
+
+func (m *ExponentialHistogramDataPoint) GetXSum() isExponentialHistogramDataPoint_XSum {
+	if m != nil {
+		return m.XSum
+	}
+	return nil
+}
+func (m *ExponentialHistogramDataPoint) GetXMin() isExponentialHistogramDataPoint_XMin {
+	if m != nil {
+		return m.XMin
+	}
+	return nil
+}
+func (m *ExponentialHistogramDataPoint) GetXMax() isExponentialHistogramDataPoint_XMax {
+	if m != nil {
+		return m.XMax
+	}
+	return nil
+}
+
+func (m *ExponentialHistogramDataPoint) GetAttributes() []*opentelemetry_proto_common_v1.KeyValue {
+	if m != nil {
+		return m.Attributes
+	}
+	return nil
+}
+
+func (m *ExponentialHistogramDataPoint) GetStartTimeUnixNano() uint64 {
+	if m != nil {
+		return m.StartTimeUnixNano
+	}
+	return 0
+}
+
+func (m *ExponentialHistogramDataPoint) GetTimeUnixNano() uint64 {
+	if m != nil {
+		return m.TimeUnixNano
+	}
+	return 0
+}
+
+func (m *ExponentialHistogramDataPoint) GetCount() uint64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *ExponentialHistogramDataPoint) GetSum() float64 {
+	if x, ok := m.GetXSum().(*ExponentialHistogramDataPoint_Sum); ok {
+		return x.Sum
+	}
+	return 0
+}
+
+func (m *ExponentialHistogramDataPoint) GetScale() int32 {
+	if m != nil {
+		return m.Scale
+	}
+	return 0
+}
+
+func (m *ExponentialHistogramDataPoint) GetZeroCount() uint64 {
+	if m != nil {
+		return m.ZeroCount
+	}
+	return 0
+}
+
+func (m *ExponentialHistogramDataPoint) GetPositive() *ExponentialHistogramDataPoint_Buckets {
+	if m != nil {
+		return m.Positive
+	}
+	return nil
+}
+
+func (m *ExponentialHistogramDataPoint) GetNegative() *ExponentialHistogramDataPoint_Buckets {
+	if m != nil {
+		return m.Negative
+	}
+	return nil
+}
+
+func (m *ExponentialHistogramDataPoint) GetFlags() uint32 {
+	if m != nil {
+		return m.Flags
+	}
+	return 0
+}
+
+func (m *ExponentialHistogramDataPoint) GetExemplars() []*Exemplar {
+	if m != nil {
+		return m.Exemplars
+	}
+	return nil
+}
+
+func (m *ExponentialHistogramDataPoint) GetMin() float64 {
+	if x, ok := m.GetXMin().(*ExponentialHistogramDataPoint_Min); ok {
+		return x.Min
+	}
+	return 0
+}
+
+func (m *ExponentialHistogramDataPoint) GetMax() float64 {
+	if x, ok := m.GetXMax().(*ExponentialHistogramDataPoint_Max); ok {
+		return x.Max
+	}
+	return 0
+}
+
+func (m *ExponentialHistogramDataPoint) GetZeroThreshold() float64 {
+	if m != nil {
+		return m.ZeroThreshold
+	}
+	return 0
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*ExponentialHistogramDataPoint) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _ExponentialHistogramDataPoint_OneofMarshaler, _ExponentialHistogramDataPoint_OneofUnmarshaler, _ExponentialHistogramDataPoint_OneofSizer, []interface{}{
+		(*ExponentialHistogramDataPoint_Sum)(nil),
+		(*ExponentialHistogramDataPoint_Min)(nil),
+		(*ExponentialHistogramDataPoint_Max)(nil),
+	}
+}
+
+func _ExponentialHistogramDataPoint_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*ExponentialHistogramDataPoint)
+	// _sum
+	switch x := m.XSum.(type) {
+	case *ExponentialHistogramDataPoint_Sum:
+		b.EncodeVarint(5<<3 | proto.WireFixed64)
+		b.EncodeFixed64(math.Float64bits(x.Sum))
+	case nil:
+	default:
+		return fmt.Errorf("ExponentialHistogramDataPoint.XSum has unexpected type %T", x)
+	}
+	// _min
+	switch x := m.XMin.(type) {
+	case *ExponentialHistogramDataPoint_Min:
+		b.EncodeVarint(12<<3 | proto.WireFixed64)
+		b.EncodeFixed64(math.Float64bits(x.Min))
+	case nil:
+	default:
+		return fmt.Errorf("ExponentialHistogramDataPoint.XMin has unexpected type %T", x)
+	}
+	// _max
+	switch x := m.XMax.(type) {
+	case *ExponentialHistogramDataPoint_Max:
+		b.EncodeVarint(13<<3 | proto.WireFixed64)
+		b.EncodeFixed64(math.Float64bits(x.Max))
+	case nil:
+	default:
+		return fmt.Errorf("ExponentialHistogramDataPoint.XMax has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _ExponentialHistogramDataPoint_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*ExponentialHistogramDataPoint)
+	switch tag {
+	case 5: // _sum.sum
+		if wire != proto.WireFixed64 {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeFixed64()
+		m.XSum = &ExponentialHistogramDataPoint_Sum{math.Float64frombits(x)}
+		return true, err
+	case 12: // _min.min
+		if wire != proto.WireFixed64 {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeFixed64()
+		m.XMin = &ExponentialHistogramDataPoint_Min{math.Float64frombits(x)}
+		return true, err
+	case 13: // _max.max
+		if wire != proto.WireFixed64 {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeFixed64()
+		m.XMax = &ExponentialHistogramDataPoint_Max{math.Float64frombits(x)}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _ExponentialHistogramDataPoint_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*ExponentialHistogramDataPoint)
+	// _sum
+	switch x := m.XSum.(type) {
+	case *ExponentialHistogramDataPoint_Sum:
+		n += proto.SizeVarint(5<<3 | proto.WireFixed64)
+		n += 8
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	// _min
+	switch x := m.XMin.(type) {
+	case *ExponentialHistogramDataPoint_Min:
+		n += proto.SizeVarint(12<<3 | proto.WireFixed64)
+		n += 8
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	// _max
+	switch x := m.XMax.(type) {
+	case *ExponentialHistogramDataPoint_Max:
+		n += proto.SizeVarint(13<<3 | proto.WireFixed64)
+		n += 8
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+type ExponentialHistogramDataPoint_Buckets struct {
+	Offset       int32    `protobuf:"zigzag32,1,opt,name=offset" json:"offset,omitempty"`
+	BucketCounts []uint64 `protobuf:"varint,2,rep,packed,name=bucket_counts,json=bucketCounts" json:"bucket_counts,omitempty"`
+}
+
+func (m *ExponentialHistogramDataPoint_Buckets) Reset()         { *m = ExponentialHistogramDataPoint_Buckets{} }
+func (m *ExponentialHistogramDataPoint_Buckets) String() string { return proto.CompactTextString(m) }
+func (*ExponentialHistogramDataPoint_Buckets) ProtoMessage()    {}
+func (*ExponentialHistogramDataPoint_Buckets) Descriptor() ([]byte, []int) {
+	return fileDescriptor0, []int{11, 0}
+}
+
+func (m *ExponentialHistogramDataPoint_Buckets) GetOffset() int32 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+func (m *ExponentialHistogramDataPoint_Buckets) GetBucketCounts() []uint64 {
+	if m != nil {
+		return m.BucketCounts
+	}
+	return nil
+}
+
+type SummaryDataPoint struct {
+	Attributes        []*opentelemetry_proto_common_v1.KeyValue `protobuf:"bytes,7,rep,name=attributes" json:"attributes,omitempty"`
+	StartTimeUnixNano uint64                                    `protobuf:"fixed64,2,opt,name=start_time_unix_nano,json=startTimeUnixNano" json:"start_time_unix_nano,omitempty"`
+	TimeUnixNano      uint64                                    `protobuf:"fixed64,3,opt,name=time_unix_nano,json=timeUnixNano" json:"time_unix_nano,omitempty"`
+	Count             uint64                                    `protobuf:"fixed64,4,opt,name=count" json:"count,omitempty"`
+	Sum               float64                                   `protobuf:"fixed64,5,opt,name=sum" json:"sum,omitempty"`
+	QuantileValues    []*SummaryDataPoint_ValueAtQuantile       `protobuf:"bytes,6,rep,name=quantile_values,json=quantileValues" json:"quantile_values,omitempty"`
+	Flags             uint32                                    `protobuf:"varint,8,opt,name=flags" json:"flags,omitempty"`
+}
+
+func (m *SummaryDataPoint) Reset()                    { *m = SummaryDataPoint{} }
+func (m *SummaryDataPoint) String() string            { return proto.CompactTextString(m) }
+func (*SummaryDataPoint) ProtoMessage()               {}
+func (*SummaryDataPoint) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *SummaryDataPoint) GetAttributes() []*opentelemetry_proto_common_v1.KeyValue {
+	if m != nil {
+		return m.Attributes
+	}
+	return nil
+}
+
+func (m *SummaryDataPoint) GetStartTimeUnixNano() uint64 {
+	if m != nil {
+		return m.StartTimeUnixNano
+	}
+	return 0
+}
+
+func (m *SummaryDataPoint) GetTimeUnixNano() uint64 {
+	if m != nil {
+		return m.TimeUnixNano
+	}
+	return 0
+}
+
+func (m *SummaryDataPoint) GetCount() uint64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *SummaryDataPoint) GetSum() float64 {
+	if m != nil {
+		return m.Sum
+	}
+	return 0
+}
+
+func (m *SummaryDataPoint) GetQuantileValues() []*SummaryDataPoint_ValueAtQuantile {
+	if m != nil {
+		return m.QuantileValues
+	}
+	return nil
+}
+
+func (m *SummaryDataPoint) GetFlags() uint32 {
+	if m != nil {
+		return m.Flags
+	}
+	return 0
+}
+
+type SummaryDataPoint_ValueAtQuantile struct {
+	Quantile float64 `protobuf:"fixed64,1,opt,name=quantile" json:"quantile,omitempty"`
+	Value    float64 `protobuf:"fixed64,2,opt,name=value" json:"value,omitempty"`
+}
+
+func (m *SummaryDataPoint_ValueAtQuantile) Reset()         { *m = SummaryDataPoint_ValueAtQuantile{} }
+func (m *SummaryDataPoint_ValueAtQuantile) String() string { return proto.CompactTextString(m) }
+func (*SummaryDataPoint_ValueAtQuantile) ProtoMessage()    {}
+func (*SummaryDataPoint_ValueAtQuantile) Descriptor() ([]byte, []int) {
+	return fileDescriptor0, []int{12, 0}
+}
+
+func (m *SummaryDataPoint_ValueAtQuantile) GetQuantile() float64 {
+	if m != nil {
+		return m.Quantile
+	}
+	return 0
+}
+
+func (m *SummaryDataPoint_ValueAtQuantile) GetValue() float64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+type Exemplar struct {
+	FilteredAttributes []*opentelemetry_proto_common_v1.KeyValue `protobuf:"bytes,7,rep,name=filtered_attributes,json=filteredAttributes" json:"filtered_attributes,omitempty"`
+	TimeUnixNano       uint64                                    `protobuf:"fixed64,2,opt,name=time_unix_nano,json=timeUnixNano" json:"time_unix_nano,omitempty"`
+	// Types that are valid to be assigned to Value:
+	//	*Exemplar_AsDouble
+	//	*Exemplar_AsInt
+	Value   isExemplar_Value `protobuf_oneof:"value"`
+	SpanId  []byte           `protobuf:"bytes,4,opt,name=span_id,json=spanId,proto3" json:"span_id,omitempty"`
+	TraceId []byte           `protobuf:"bytes,5,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
+}
+
+func (m *Exemplar) Reset()                    { *m = Exemplar{} }
+func (m *Exemplar) String() string            { return proto.CompactTextString(m) }
+func (*Exemplar) ProtoMessage()               {}
+func (*Exemplar) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+type isExemplar_Value interface{ isExemplar_Value() }
+
+type Exemplar_AsDouble struct {
+	AsDouble float64 `protobuf:"fixed64,3,opt,name=as_double,json=asDouble,oneof"`
+}
+type Exemplar_AsInt struct {
+	AsInt int64 `protobuf:"fixed64,6,opt,name=as_int,json=asInt,oneof"`
+}
+
+func (*Exemplar_AsDouble) isExemplar_Value() {}
+func (*Exemplar_AsInt) isExemplar_Value()    {}
+
+func (m *Exemplar) GetValue() isExemplar_Value {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *Exemplar) GetFilteredAttributes() []*opentelemetry_proto_common_v1.KeyValue {
+	if m != nil {
+		return m.FilteredAttributes
+	}
+	return nil
+}
+
+func (m *Exemplar) GetTimeUnixNano() uint64 {
+	if m != nil {
+		return m.TimeUnixNano
+	}
+	return 0
+}
+
+func (m *Exemplar) GetAsDouble() float64 {
+	if x, ok := m.GetValue().(*Exemplar_AsDouble); ok {
+		return x.AsDouble
+	}
+	return 0
+}
+
+func (m *Exemplar) GetAsInt() int64 {
+	if x, ok := m.GetValue().(*Exemplar_AsInt); ok {
+		return x.AsInt
+	}
+	return 0
+}
+
+func (m *Exemplar) GetSpanId() []byte {
+	if m != nil {
+		return m.SpanId
+	}
+	return nil
+}
+
+func (m *Exemplar) GetTraceId() []byte {
+	if m != nil {
+		return m.TraceId
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*Exemplar) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _Exemplar_OneofMarshaler, _Exemplar_OneofUnmarshaler, _Exemplar_OneofSizer, []interface{}{
+		(*Exemplar_AsDouble)(nil),
+		(*Exemplar_AsInt)(nil),
+	}
+}
+
+func _Exemplar_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*Exemplar)
+	// value
+	switch x := m.Value.(type) {
+	case *Exemplar_AsDouble:
+		b.EncodeVarint(3<<3 | proto.WireFixed64)
+		b.EncodeFixed64(math.Float64bits(x.AsDouble))
+	case *Exemplar_AsInt:
+		b.EncodeVarint(6<<3 | proto.WireFixed64)
+		b.EncodeFixed64(uint64(x.AsInt))
+	case nil:
+	default:
+		return fmt.Errorf("Exemplar.Value has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _Exemplar_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*Exemplar)
+	switch tag {
+	case 3: // value.as_double
+		if wire != proto.WireFixed64 {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeFixed64()
+		m.Value = &Exemplar_AsDouble{math.Float64frombits(x)}
+		return true, err
+	case 6: // value.as_int
+		if wire != proto.WireFixed64 {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeFixed64()
+		m.Value = &Exemplar_AsInt{int64(x)}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _Exemplar_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*Exemplar)
+	// value
+	switch x := m.Value.(type) {
+	case *Exemplar_AsDouble:
+		n += proto.SizeVarint(3<<3 | proto.WireFixed64)
+		n += 8
+	case *Exemplar_AsInt:
+		n += proto.SizeVarint(6<<3 | proto.WireFixed64)
+		n += 8
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+func init() {
+	proto.RegisterType((*MetricsData)(nil), "opentelemetry.proto.metrics.v1.MetricsData")
+	proto.RegisterType((*ResourceMetrics)(nil), "opentelemetry.proto.metrics.v1.ResourceMetrics")
+	proto.RegisterType((*ScopeMetrics)(nil), "opentelemetry.proto.metrics.v1.ScopeMetrics")
+	proto.RegisterType((*Metric)(nil), "opentelemetry.proto.metrics.v1.Metric")
+	proto.RegisterType((*Gauge)(nil), "opentelemetry.proto.metrics.v1.Gauge")
+	proto.RegisterType((*Sum)(nil), "opentelemetry.proto.metrics.v1.Sum")
+	proto.RegisterType((*Histogram)(nil), "opentelemetry.proto.metrics.v1.Histogram")
+	proto.RegisterType((*ExponentialHistogram)(nil), "opentelemetry.proto.metrics.v1.ExponentialHistogram")
+	proto.RegisterType((*Summary)(nil), "opentelemetry.proto.metrics.v1.Summary")
+	proto.RegisterType((*NumberDataPoint)(nil), "opentelemetry.proto.metrics.v1.NumberDataPoint")
+	proto.RegisterType((*HistogramDataPoint)(nil), "opentelemetry.proto.metrics.v1.HistogramDataPoint")
+	proto.RegisterType((*ExponentialHistogramDataPoint)(nil), "opentelemetry.proto.metrics.v1.ExponentialHistogramDataPoint")
+	proto.RegisterType((*ExponentialHistogramDataPoint_Buckets)(nil), "opentelemetry.proto.metrics.v1.ExponentialHistogramDataPoint.Buckets")
+	proto.RegisterType((*SummaryDataPoint)(nil), "opentelemetry.proto.metrics.v1.SummaryDataPoint")
+	proto.RegisterType((*SummaryDataPoint_ValueAtQuantile)(nil), "opentelemetry.proto.metrics.v1.SummaryDataPoint.ValueAtQuantile")
+	proto.RegisterType((*Exemplar)(nil), "opentelemetry.proto.metrics.v1.Exemplar")
+	proto.RegisterEnum("opentelemetry.proto.metrics.v1.AggregationTemporality", AggregationTemporality_name, AggregationTemporality_value)
+	proto.RegisterEnum("opentelemetry.proto.metrics.v1.DataPointFlags", DataPointFlags_name, DataPointFlags_value)
+}
+
+func init() { proto.RegisterFile("opentelemetry/proto/metrics/v1/metrics.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 1429 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xd4, 0x58, 0xcd, 0x52, 0x1b, 0xc7,
+	0x13, 0x67, 0xf5, 0xb9, 0x6a, 0x09, 0x90, 0xe7, 0xcf, 0xdf, 0xde, 0x90, 0xc2, 0x91, 0xe5, 0xd8,
+	0x60, 0xc7, 0x25, 0x02, 0x4e, 0x25, 0x27, 0x57, 0x59, 0x20, 0x01, 0xc2, 0x80, 0xf0, 0x20, 0xa8,
+	0xd8, 0x95, 0xf2, 0xd6, 0x20, 0x0d, 0x62, 0xca, 0xfb, 0xa1, 0xec, 0xcc, 0x52, 0x90, 0x4b, 0x4e,
+	0xb9, 0xe5, 0x39, 0x72, 0xc8, 0x23, 0xe4, 0x29, 0x92, 0x1c, 0x72, 0xcf, 0x29, 0x49, 0xe5, 0x94,
+	0x37, 0x48, 0xcd, 0xec, 0x2e, 0xfa, 0x60, 0xb1, 0x88, 0xe3, 0x03, 0x39, 0x69, 0xba, 0xa7, 0x7f,
+	0x3d, 0xdd, 0xd3, 0xbf, 0x99, 0x1e, 0x2d, 0x3c, 0x72, 0x7b, 0xd4, 0x11, 0xd4, 0xa2, 0x36, 0x15,
+	0xde, 0xd9, 0x62, 0xcf, 0x73, 0x85, 0xbb, 0x28, 0xc7, 0xac, 0xcd, 0x17, 0x4f, 0x96, 0xa2, 0x61,
+	0x45, 0x4d, 0xa0, 0xdb, 0x43, 0xd6, 0x81, 0xb2, 0x12, 0x99, 0x9c, 0x2c, 0xcd, 0x3e, 0x8c, 0xf3,
+	0xd6, 0x76, 0x6d, 0xdb, 0x75, 0xa4, 0xb3, 0x60, 0x14, 0xc0, 0x66, 0x2b, 0x71, 0xb6, 0x1e, 0xe5,
+	0xae, 0xef, 0xb5, 0xa9, 0xb4, 0x8e, 0xc6, 0x81, 0x7d, 0x99, 0x41, 0x7e, 0x3b, 0x58, 0xa9, 0x46,
+	0x04, 0x41, 0x2f, 0xa1, 0x18, 0x19, 0x98, 0x61, 0x04, 0x86, 0x56, 0x4a, 0x2e, 0xe4, 0x97, 0x17,
+	0x2b, 0x6f, 0x8e, 0xb2, 0x82, 0x43, 0x5c, 0xe8, 0x0e, 0x4f, 0x7b, 0xc3, 0x8a, 0xf2, 0xcf, 0x1a,
+	0x4c, 0x8f, 0x18, 0xa1, 0x3a, 0xe8, 0x91, 0x99, 0xa1, 0x95, 0xb4, 0x85, 0xfc, 0xf2, 0x83, 0xd8,
+	0x75, 0xce, 0xa3, 0x1e, 0x58, 0x08, 0x9f, 0x43, 0xd1, 0x73, 0x98, 0xe4, 0x6d, 0xb7, 0xd7, 0x8f,
+	0x39, 0xa1, 0x62, 0x7e, 0x34, 0x2e, 0xe6, 0x3d, 0x09, 0x8a, 0x02, 0x2e, 0xf0, 0x01, 0x09, 0xcd,
+	0x01, 0xf0, 0xf6, 0x31, 0xb5, 0x89, 0xe9, 0x7b, 0x96, 0x91, 0x2c, 0x69, 0x0b, 0x39, 0x9c, 0x0b,
+	0x34, 0xfb, 0x9e, 0xb5, 0x99, 0xd1, 0x7f, 0xcf, 0x16, 0xff, 0xc8, 0x96, 0x7f, 0xd0, 0xa0, 0x30,
+	0xe8, 0x05, 0x35, 0x20, 0xad, 0xfc, 0x84, 0xe9, 0x3c, 0x8e, 0x0d, 0x21, 0x2c, 0xd9, 0xc9, 0x52,
+	0xa5, 0xe1, 0x70, 0xe1, 0xf9, 0x36, 0x75, 0x04, 0x11, 0xcc, 0x75, 0x94, 0x2b, 0x1c, 0x78, 0x40,
+	0x4f, 0x21, 0x3b, 0x9c, 0xcf, 0xfd, 0x71, 0xf9, 0x04, 0x41, 0xe0, 0xac, 0x7d, 0xa5, 0x24, 0xca,
+	0xbf, 0x26, 0x21, 0x13, 0x40, 0x10, 0x82, 0x94, 0x43, 0xec, 0x20, 0xea, 0x1c, 0x56, 0x63, 0x54,
+	0x82, 0x7c, 0x87, 0xf2, 0xb6, 0xc7, 0x7a, 0x32, 0x34, 0x23, 0xa1, 0xa6, 0x06, 0x55, 0x12, 0xe5,
+	0x3b, 0x4c, 0x84, 0x9e, 0xd5, 0x18, 0x3d, 0x81, 0x74, 0x97, 0xf8, 0x5d, 0x6a, 0xa4, 0xd5, 0x06,
+	0xdc, 0x1b, 0x17, 0xf3, 0xba, 0x34, 0xde, 0x98, 0xc0, 0x01, 0x0a, 0x7d, 0x06, 0x49, 0xee, 0xdb,
+	0x46, 0x56, 0x81, 0xef, 0x8e, 0x2d, 0xa0, 0x6f, 0x6f, 0x4c, 0x60, 0x89, 0x40, 0x0d, 0xc8, 0x1d,
+	0x33, 0x2e, 0xdc, 0xae, 0x47, 0x6c, 0x23, 0xf7, 0x06, 0x2e, 0x0d, 0xc0, 0x37, 0x22, 0xc0, 0xc6,
+	0x04, 0xee, 0xa3, 0xd1, 0x6b, 0xf8, 0x3f, 0x3d, 0xed, 0xb9, 0x0e, 0x75, 0x04, 0x23, 0x96, 0xd9,
+	0x77, 0x0b, 0xca, 0xed, 0x27, 0xe3, 0xdc, 0xd6, 0xfb, 0xe0, 0xc1, 0x15, 0x66, 0x68, 0x8c, 0x1e,
+	0xad, 0x42, 0x96, 0xfb, 0xb6, 0x4d, 0xbc, 0x33, 0x23, 0xaf, 0xdc, 0xcf, 0x5f, 0x21, 0x69, 0x69,
+	0xbe, 0x31, 0x81, 0x23, 0xe4, 0x4a, 0x06, 0x52, 0x1d, 0x22, 0xc8, 0x66, 0x4a, 0x4f, 0x15, 0xd3,
+	0x9b, 0x29, 0x3d, 0x53, 0xcc, 0x6e, 0xa6, 0x74, 0xbd, 0x98, 0x2b, 0xbf, 0x80, 0xb4, 0xda, 0x61,
+	0xb4, 0x0b, 0x79, 0x69, 0x62, 0xf6, 0x5c, 0xe6, 0x88, 0x2b, 0x9f, 0xea, 0x1d, 0xdf, 0x3e, 0xa4,
+	0x9e, 0xbc, 0x1b, 0x76, 0x25, 0x0e, 0x43, 0x27, 0x1a, 0xf2, 0xf2, 0x9f, 0x1a, 0x24, 0xf7, 0x7c,
+	0xfb, 0xdd, 0x7b, 0x46, 0x2e, 0xdc, 0x22, 0xdd, 0xae, 0x47, 0xbb, 0xea, 0x50, 0x98, 0x82, 0xda,
+	0x3d, 0xd7, 0x23, 0x16, 0x13, 0x67, 0x8a, 0x85, 0x53, 0xcb, 0x9f, 0x8e, 0xf3, 0x5e, 0xed, 0xc3,
+	0x5b, 0x7d, 0x34, 0xbe, 0x49, 0x62, 0xf5, 0xe8, 0x0e, 0x14, 0x18, 0x37, 0x6d, 0xd7, 0x71, 0x85,
+	0xeb, 0xb0, 0xb6, 0x22, 0xb4, 0x8e, 0xf3, 0x8c, 0x6f, 0x47, 0xaa, 0xf2, 0x4f, 0x1a, 0xe4, 0xfa,
+	0x55, 0xdb, 0x8b, 0xcb, 0x79, 0xf9, 0xca, 0x7c, 0xbb, 0x1e, 0x69, 0x97, 0x7f, 0xd3, 0x60, 0x26,
+	0x8e, 0xac, 0xe8, 0x55, 0x5c, 0x7a, 0x4f, 0xde, 0x86, 0xf7, 0xd7, 0x24, 0xd3, 0x2f, 0x20, 0x1b,
+	0x1e, 0x1b, 0xf4, 0x3c, 0x2e, 0xb7, 0x8f, 0xaf, 0x78, 0xe8, 0xe2, 0x4f, 0xc2, 0x2f, 0x09, 0x98,
+	0x1e, 0xe1, 0x33, 0x5a, 0x07, 0x20, 0x42, 0x78, 0xec, 0xd0, 0x17, 0x94, 0x1b, 0xd9, 0x52, 0xf2,
+	0xd2, 0xa3, 0xdd, 0xef, 0x06, 0xcf, 0xe8, 0xd9, 0x01, 0xb1, 0x7c, 0x8a, 0x07, 0xa0, 0x68, 0x11,
+	0x66, 0xb8, 0x20, 0x9e, 0x30, 0x05, 0xb3, 0xa9, 0xe9, 0x3b, 0xec, 0xd4, 0x74, 0x88, 0xe3, 0xaa,
+	0x8d, 0xca, 0xe0, 0x1b, 0x6a, 0xae, 0xc5, 0x6c, 0xba, 0xef, 0xb0, 0xd3, 0x1d, 0xe2, 0xb8, 0xe8,
+	0x43, 0x98, 0x1a, 0x31, 0x4d, 0x2a, 0xd3, 0x82, 0x18, 0xb4, 0x9a, 0x83, 0x1c, 0xe1, 0x66, 0xc7,
+	0xf5, 0x0f, 0x2d, 0x6a, 0xa4, 0x4a, 0xda, 0x82, 0xb6, 0x31, 0x81, 0x75, 0xc2, 0x6b, 0x4a, 0x83,
+	0x6e, 0x41, 0x86, 0x70, 0x93, 0x39, 0xc2, 0xc8, 0x94, 0xb4, 0x85, 0xa2, 0xbc, 0xa0, 0x09, 0x6f,
+	0x38, 0x02, 0xad, 0x41, 0x8e, 0x9e, 0x52, 0xbb, 0x67, 0x11, 0x8f, 0x1b, 0x69, 0x95, 0xd6, 0xc2,
+	0x78, 0x62, 0x04, 0x00, 0xdc, 0x87, 0xa2, 0x19, 0x48, 0x1f, 0x59, 0xa4, 0xcb, 0x0d, 0xbd, 0xa4,
+	0x2d, 0x4c, 0xe2, 0x40, 0x58, 0xc9, 0x42, 0xfa, 0x44, 0xee, 0xc0, 0x66, 0x4a, 0xd7, 0x8a, 0x89,
+	0xf2, 0x8f, 0x49, 0x40, 0x17, 0xa9, 0x34, 0xb2, 0xb7, 0xb9, 0x6b, 0xb7, 0xb7, 0x33, 0x90, 0x6e,
+	0xbb, 0xbe, 0x23, 0xd4, 0xbe, 0x66, 0x70, 0x20, 0x20, 0x14, 0xb4, 0xb6, 0x74, 0xb8, 0xd7, 0x52,
+	0x40, 0x77, 0x61, 0xf2, 0xd0, 0x6f, 0xbf, 0xa6, 0xc2, 0x54, 0x36, 0xdc, 0xc8, 0x94, 0x92, 0xd2,
+	0x5d, 0xa0, 0x5c, 0x55, 0x3a, 0x34, 0x0f, 0xd3, 0xf4, 0xb4, 0x67, 0xb1, 0x36, 0x13, 0xe6, 0xa1,
+	0xeb, 0x3b, 0x9d, 0x80, 0x4f, 0x1a, 0x9e, 0x8a, 0xd4, 0x2b, 0x4a, 0x3b, 0x5c, 0x1b, 0xfd, 0x1d,
+	0xd4, 0x06, 0x06, 0x6a, 0x23, 0xe3, 0xb7, 0x99, 0xa3, 0xba, 0x94, 0xb6, 0xa1, 0x61, 0x29, 0x28,
+	0x1d, 0x39, 0x35, 0x0a, 0x4a, 0x97, 0xc0, 0x52, 0x90, 0xcd, 0xc8, 0xe4, 0xbe, 0xad, 0x7e, 0x6d,
+	0xe6, 0x04, 0xbf, 0xe4, 0x34, 0x2c, 0xe9, 0x5f, 0x69, 0x98, 0x7b, 0xe3, 0x45, 0x31, 0x52, 0x5d,
+	0xed, 0x3f, 0x5c, 0xdd, 0x19, 0xf9, 0x18, 0x24, 0x16, 0x55, 0x67, 0xe8, 0x06, 0x0e, 0x04, 0xf9,
+	0x2a, 0xfb, 0x8a, 0x7a, 0x6e, 0x50, 0x71, 0xf5, 0xd2, 0xc9, 0xe0, 0x9c, 0xd4, 0xa8, 0x72, 0x23,
+	0x02, 0x7a, 0xcf, 0xe5, 0x4c, 0xb0, 0x13, 0xaa, 0xce, 0x46, 0x7e, 0xb9, 0xfe, 0xaf, 0x2e, 0xde,
+	0xca, 0x8a, 0xe2, 0x12, 0xc7, 0xe7, 0x6e, 0xe5, 0x12, 0x8e, 0xba, 0x24, 0x4f, 0xa8, 0x91, 0x7b,
+	0xa7, 0x4b, 0x44, 0x6e, 0x2f, 0xa1, 0xd0, 0x10, 0x41, 0xf3, 0x6f, 0x4f, 0xd0, 0x90, 0x8a, 0x85,
+	0x18, 0x2a, 0x4e, 0x0e, 0x50, 0x11, 0xdd, 0x83, 0x29, 0xb5, 0xd5, 0xe2, 0xd8, 0xa3, 0xfc, 0xd8,
+	0xb5, 0x3a, 0xc6, 0x94, 0x9c, 0xc6, 0x93, 0x52, 0xdb, 0x8a, 0x94, 0xb3, 0x6b, 0x90, 0x0d, 0x33,
+	0x40, 0x37, 0x21, 0xe3, 0x1e, 0x1d, 0x71, 0x2a, 0xd4, 0x53, 0xf8, 0x06, 0x0e, 0xa5, 0x8b, 0x07,
+	0x55, 0x3e, 0xc9, 0x53, 0xc3, 0x07, 0xf5, 0x32, 0xe6, 0x97, 0xbf, 0x4b, 0x42, 0x71, 0xb4, 0x81,
+	0x5c, 0xfb, 0x06, 0x11, 0x4f, 0xf3, 0xe2, 0x00, 0xcd, 0x03, 0x92, 0x33, 0x98, 0xfe, 0xd2, 0x27,
+	0x8e, 0x60, 0x16, 0x35, 0xd5, 0xdd, 0x1d, 0x5c, 0x62, 0xf9, 0xe5, 0xa7, 0xff, 0xb4, 0xa7, 0x56,
+	0x54, 0x6e, 0x55, 0xf1, 0x3c, 0x74, 0x87, 0xa7, 0x22, 0xc7, 0x6a, 0xe2, 0x92, 0x9e, 0x31, 0xbb,
+	0x0a, 0xd3, 0x23, 0x40, 0x34, 0x0b, 0x7a, 0x04, 0x55, 0x75, 0xd4, 0xf0, 0xb9, 0x2c, 0x9d, 0xa8,
+	0x30, 0xd5, 0xfe, 0x68, 0x78, 0xa8, 0xdf, 0x7c, 0x93, 0x00, 0x3d, 0xe2, 0x1b, 0xfa, 0x1c, 0xfe,
+	0x77, 0xc4, 0x2c, 0x41, 0x3d, 0xda, 0x31, 0xdf, 0xbe, 0x52, 0x28, 0xf2, 0x51, 0xed, 0x57, 0xec,
+	0x62, 0x01, 0x12, 0xe3, 0x3a, 0x74, 0xf2, 0xea, 0x1d, 0xfa, 0x16, 0x64, 0x79, 0x8f, 0x38, 0x26,
+	0xeb, 0xa8, 0xd2, 0x15, 0x70, 0x46, 0x8a, 0x8d, 0x0e, 0x7a, 0x0f, 0x74, 0xe1, 0x91, 0x36, 0x95,
+	0x33, 0x69, 0x35, 0x93, 0x55, 0x72, 0xa3, 0x33, 0xd2, 0x77, 0x1f, 0x7e, 0xab, 0xc1, 0xcd, 0xf8,
+	0x17, 0x16, 0x9a, 0x87, 0xbb, 0xd5, 0xf5, 0x75, 0x5c, 0x5f, 0xaf, 0xb6, 0x1a, 0xcd, 0x1d, 0xb3,
+	0x55, 0xdf, 0xde, 0x6d, 0xe2, 0xea, 0x56, 0xa3, 0xf5, 0xc2, 0xdc, 0xdf, 0xd9, 0xdb, 0xad, 0xaf,
+	0x36, 0xd6, 0x1a, 0xf5, 0x5a, 0x71, 0x02, 0xdd, 0x81, 0xb9, 0xcb, 0x0c, 0x6b, 0xf5, 0xad, 0x56,
+	0xb5, 0xa8, 0xa1, 0xfb, 0x50, 0xbe, 0xcc, 0x64, 0x75, 0x7f, 0x7b, 0x7f, 0xab, 0xda, 0x6a, 0x1c,
+	0xd4, 0x8b, 0x89, 0x87, 0xaf, 0x60, 0xea, 0x9c, 0x24, 0x6b, 0xea, 0x22, 0xf9, 0x00, 0xde, 0xaf,
+	0x55, 0x5b, 0x55, 0x73, 0xb7, 0xd9, 0xd8, 0x69, 0x99, 0x6b, 0x5b, 0xd5, 0xf5, 0x3d, 0xb3, 0xd6,
+	0x34, 0x77, 0x9a, 0x2d, 0x73, 0x7f, 0xaf, 0x5e, 0x9c, 0x40, 0x1f, 0xc1, 0xfc, 0x05, 0x83, 0x9d,
+	0xa6, 0x89, 0xeb, 0xab, 0x4d, 0x5c, 0xab, 0xd7, 0xcc, 0x83, 0xea, 0xd6, 0x7e, 0xdd, 0xdc, 0xae,
+	0xee, 0x3d, 0x2b, 0x6a, 0x2b, 0x5f, 0xc3, 0x1d, 0xe6, 0x8e, 0x61, 0xeb, 0x4a, 0x21, 0xfc, 0x8b,
+	0xbf, 0x2b, 0x27, 0x76, 0xb5, 0x97, 0x0f, 0xba, 0xa3, 0x10, 0xe6, 0x86, 0x1f, 0x5c, 0x5c, 0x61,
+	0xf5, 0x06, 0xbe, 0xf7, 0x7c, 0x9f, 0xb8, 0xdd, 0xec, 0x51, 0xa7, 0x75, 0x6e, 0xa8, 0x5c, 0x84,
+	0xff, 0xd6, 0x79, 0xe5, 0x60, 0xe9, 0x30, 0xa3, 0x70, 0x8f, 0xff, 0x1e, 0x00, 0x23, 0x3c, 0xca,
+	0xef, 0x39, 0x12, 0x00, 0x00,
+}
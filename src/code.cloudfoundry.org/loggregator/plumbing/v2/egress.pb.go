@@ -23,6 +23,8 @@ type EgressRequest struct {
 	Filter  *Filter `protobuf:"bytes,2,opt,name=filter" json:"filter,omitempty"`
 	// TODO: This can be removed once the envelope.deprecated_tags is removed.
 	UsePreferredTags bool `protobuf:"varint,3,opt,name=use_preferred_tags,json=usePreferredTags" json:"use_preferred_tags,omitempty"`
+	// Adds app, space and organization names and GUIDs as tags.
+	IncludeAppMetadata bool `protobuf:"varint,4,opt,name=include_app_metadata,json=includeAppMetadata" json:"include_app_metadata,omitempty"`
 }
 
 func (m *EgressRequest) Reset()                    { *m = EgressRequest{} }
@@ -51,6 +53,13 @@ func (m *EgressRequest) GetUsePreferredTags() bool {
 	return false
 }
 
+func (m *EgressRequest) GetIncludeAppMetadata() bool {
+	if m != nil {
+		return m.IncludeAppMetadata
+	}
+	return false
+}
+
 type Filter struct {
 	SourceId string `protobuf:"bytes,1,opt,name=source_id,json=sourceId" json:"source_id,omitempty"`
 	// Types that are valid to be assigned to Message:
@@ -265,22 +274,23 @@ var _Egress_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("egress.proto", fileDescriptor1) }
 
 var fileDescriptor1 = []byte{
-	// 261 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x90, 0x5f, 0x4b, 0xc3, 0x30,
-	0x14, 0xc5, 0x17, 0x07, 0x5d, 0x7b, 0xa7, 0x43, 0xf2, 0x20, 0xdd, 0x44, 0x28, 0x7d, 0xea, 0x83,
-	0x16, 0xa9, 0xdf, 0x40, 0xf0, 0xcf, 0x40, 0x41, 0x83, 0xef, 0xa5, 0x2e, 0x77, 0xb1, 0x50, 0x4c,
-	0xbd, 0x37, 0xed, 0x67, 0xf0, 0x63, 0x8b, 0x4d, 0x55, 0x36, 0x1f, 0x93, 0xdf, 0x39, 0x87, 0x73,
-	0x0f, 0x1c, 0xa2, 0x21, 0x64, 0xce, 0x5b, 0xb2, 0xce, 0xca, 0x45, 0x63, 0x8d, 0x21, 0x34, 0x95,
-	0xb3, 0x94, 0xf7, 0xc5, 0x6a, 0x81, 0xef, 0x3d, 0x36, 0xb6, 0x45, 0xcf, 0xd3, 0x4f, 0x01, 0x47,
-	0x37, 0x83, 0x41, 0xe1, 0x47, 0x87, 0xec, 0xe4, 0x12, 0x42, 0x7e, 0xab, 0x48, 0x97, 0xb5, 0x8e,
-	0x45, 0x22, 0xb2, 0x48, 0xcd, 0x86, 0xf7, 0x5a, 0xcb, 0x1c, 0x82, 0x6d, 0xdd, 0x38, 0xa4, 0xf8,
-	0x20, 0x11, 0xd9, 0xbc, 0x38, 0xc9, 0x77, 0xd3, 0xf3, 0xdb, 0x81, 0xaa, 0x51, 0x25, 0xcf, 0x41,
-	0x76, 0x8c, 0x65, 0x4b, 0xb8, 0x45, 0x22, 0xd4, 0xa5, 0xab, 0x0c, 0xc7, 0xd3, 0x44, 0x64, 0xa1,
-	0x3a, 0xee, 0x18, 0x9f, 0x7e, 0xc0, 0x4b, 0x65, 0x38, 0x2d, 0x21, 0xf0, 0x7e, 0x79, 0x0a, 0x11,
-	0xdb, 0x8e, 0x36, 0xf8, 0xd7, 0x21, 0xf4, 0x1f, 0x6b, 0x2d, 0x2f, 0x60, 0xda, 0x58, 0x33, 0x36,
-	0x58, 0xee, 0x37, 0x78, 0xb0, 0xc6, 0x87, 0xdc, 0x4f, 0xd4, 0xb7, 0xee, 0x3a, 0x82, 0xd9, 0x23,
-	0x32, 0x57, 0x06, 0xd3, 0x39, 0x44, 0xbf, 0xb8, 0x78, 0x86, 0xc0, 0xdf, 0x2d, 0xef, 0x20, 0x54,
-	0xb8, 0xc1, 0xba, 0x47, 0x92, 0x67, 0xfb, 0x79, 0x3b, 0xdb, 0xac, 0xe2, 0x7f, 0x78, 0x5c, 0x33,
-	0x9d, 0x5c, 0x8a, 0xd7, 0x60, 0x98, 0xf4, 0xea, 0x2b, 0x00, 0x00, 0xff, 0xff, 0x69, 0xb4, 0x70,
-	0x7c, 0x82, 0x01, 0x00, 0x00,
+	// 283 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x65, 0x91, 0x61, 0x4b, 0xc3, 0x30,
+	0x10, 0x86, 0x57, 0x27, 0x5d, 0x7b, 0xd3, 0x21, 0x61, 0x48, 0x37, 0x11, 0xa4, 0x9f, 0xfc, 0xa0,
+	0x65, 0xd4, 0x5f, 0xa0, 0xe0, 0x54, 0x70, 0xa0, 0xc1, 0xef, 0x21, 0x36, 0xb7, 0x58, 0xa8, 0x4b,
+	0x4c, 0xd2, 0xfe, 0x38, 0x7f, 0x9d, 0xb1, 0xad, 0xca, 0xe6, 0xc7, 0xbb, 0xe7, 0xbd, 0xbb, 0x97,
+	0xf7, 0xe0, 0x00, 0xa5, 0x41, 0x6b, 0x33, 0x6d, 0x94, 0x53, 0x64, 0x52, 0x29, 0xe9, 0x6b, 0xc9,
+	0x9d, 0x32, 0x59, 0x93, 0xcf, 0x27, 0xb8, 0x69, 0xb0, 0x52, 0x1a, 0x3b, 0x9e, 0x7e, 0x06, 0x70,
+	0x78, 0xdb, 0x0e, 0x50, 0xfc, 0xa8, 0xd1, 0x3a, 0x32, 0x83, 0xc8, 0xbe, 0x71, 0x23, 0x58, 0x29,
+	0x92, 0xe0, 0x2c, 0x38, 0x8f, 0xe9, 0xa8, 0xad, 0x1f, 0x04, 0xc9, 0x20, 0x5c, 0x97, 0x95, 0x43,
+	0x93, 0xec, 0x79, 0x30, 0xce, 0x8f, 0xb3, 0xed, 0xed, 0xd9, 0xb2, 0xa5, 0xb4, 0x57, 0x91, 0x0b,
+	0x20, 0xb5, 0x45, 0xa6, 0x0d, 0xae, 0xd1, 0x18, 0x14, 0xcc, 0x71, 0x69, 0x93, 0xa1, 0x9f, 0x8d,
+	0xe8, 0x91, 0x27, 0x4f, 0x3f, 0xe0, 0xc5, 0xf7, 0xc9, 0x02, 0xa6, 0xe5, 0xa6, 0xa8, 0x6a, 0x81,
+	0x8c, 0x6b, 0xcd, 0xde, 0xd1, 0x71, 0xc1, 0x1d, 0x4f, 0xf6, 0x5b, 0x3d, 0xe9, 0xd9, 0xb5, 0xd6,
+	0xab, 0x9e, 0xa4, 0x0c, 0xc2, 0xee, 0x22, 0x39, 0x81, 0xd8, 0xaa, 0xda, 0x14, 0xf8, 0xe7, 0x3a,
+	0xea, 0x1a, 0xde, 0xf6, 0x25, 0x0c, 0xbd, 0xcf, 0xde, 0xf3, 0x6c, 0xd7, 0xf3, 0xa3, 0x92, 0xdd,
+	0x92, 0xfb, 0x01, 0xfd, 0xd6, 0xdd, 0xc4, 0x30, 0x5a, 0xf9, 0x3c, 0xb8, 0xc4, 0x74, 0x0c, 0xf1,
+	0x2f, 0xce, 0x9f, 0x21, 0xec, 0x92, 0x22, 0x77, 0x10, 0x51, 0x2c, 0xb0, 0x6c, 0xfc, 0xe5, 0xd3,
+	0xdd, 0x7d, 0x5b, 0x69, 0xce, 0x93, 0x7f, 0xb8, 0xcf, 0x3f, 0x1d, 0x2c, 0x82, 0xd7, 0xb0, 0x7d,
+	0xc2, 0xd5, 0x17, 0xab, 0x65, 0xd6, 0xea, 0xb4, 0x01, 0x00, 0x00,
 }
@@ -19,6 +19,7 @@ tmp_dir=$(mktemp -d)
 mkdir -p $tmp_dir/loggregator
 
 cp $GOPATH/src/github.com/cloudfoundry/loggregator-api/v2/*proto $tmp_dir/loggregator
+# Local protos replace the loggregator-api protos of the same name.
 cp *.proto $tmp_dir/loggregator
 
 protoc $tmp_dir/loggregator/*.proto --go_out=plugins=grpc:. --proto_path=$tmp_dir/loggregator
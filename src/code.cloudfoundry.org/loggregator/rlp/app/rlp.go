@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"net/http"
 	"sync"
+	"time"
 
 	"golang.org/x/net/netutil"
+	"google.golang.org/grpc/credentials"
 
 	"github.com/prometheus/client_golang/prometheus"
 
@@ -15,8 +18,13 @@ import (
 	"code.cloudfoundry.org/loggregator/metricemitter"
 	"code.cloudfoundry.org/loggregator/plumbing"
 	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+	"code.cloudfoundry.org/loggregator/rlp/internal/appmeta"
+	"code.cloudfoundry.org/loggregator/rlp/internal/auth"
 	"code.cloudfoundry.org/loggregator/rlp/internal/egress"
+	"code.cloudfoundry.org/loggregator/rlp/internal/federation"
+	"code.cloudfoundry.org/loggregator/rlp/internal/httpmetrics"
 	"code.cloudfoundry.org/loggregator/rlp/internal/ingress"
+	"code.cloudfoundry.org/loggregator/rlp/internal/remotewrite"
 
 	"google.golang.org/grpc"
 )
@@ -24,6 +32,8 @@ import (
 // MetricClient creates new CounterMetrics to be emitted periodically.
 type MetricClient interface {
 	NewCounter(name string, opts ...metricemitter.MetricOption) *metricemitter.Counter
+	NewGauge(name, unit string, opts ...metricemitter.MetricOption) *metricemitter.Gauge
+	NewTimer(name string, opts ...metricemitter.MetricOption) *metricemitter.Timer
 }
 
 // RLP represents the reverse log proxy component. It connects to various gRPC
@@ -40,8 +50,12 @@ type RLP struct {
 	ingressDialOpts []grpc.DialOption
 	ingressPool     *plumbing.Pool
 
-	receiver *ingress.Receiver
-	querier  *ingress.Querier
+	foundations []federation.Foundation
+	clusters    []*federation.Cluster
+
+	subscriber httpmetrics.Subscriber
+	receiver   egress.Receiver
+	querier    egress.ContainerMetricFetcher
 
 	egressAddr     net.Addr
 	egressListener net.Listener
@@ -49,6 +63,17 @@ type RLP struct {
 
 	healthAddr string
 	health     *healthendpoint.Registrar
+	mirror     *healthendpoint.Mirror
+
+	appMetadataFetcher appmeta.Fetcher
+	appMetadataTTL     time.Duration
+
+	authorizer egress.Authorizer
+
+	httpMetricsInterval time.Duration
+	httpMetricsIndex    string
+
+	remoteWrite remotewrite.Config
 
 	metricClient MetricClient
 
@@ -65,6 +90,7 @@ func NewRLP(m MetricClient, opts ...RLPOption) *RLP {
 		maxEgressConnections: 500,
 		metricClient:         m,
 		healthAddr:           "localhost:33333",
+		authorizer:           auth.AllowAll{},
 		ctx:                  ctx,
 		ctxCancel:            cancel,
 	}
@@ -126,6 +152,59 @@ func WithMaxEgressConnections(max int) RLPOption {
 	}
 }
 
+// WithAppMetadata enables adding app, space and organization names to
+// envelopes for subscriptions that request it. Metadata is fetched with the
+// given fetcher and cached for the given TTL.
+func WithAppMetadata(f appmeta.Fetcher, ttl time.Duration) RLPOption {
+	return func(r *RLP) {
+		r.appMetadataFetcher = f
+		r.appMetadataTTL = ttl
+	}
+}
+
+// WithAuthorizer specifies the authorizer used to restrict what each egress
+// client may read. By default every client may read everything.
+func WithAuthorizer(a egress.Authorizer) RLPOption {
+	return func(r *RLP) {
+		r.authorizer = a
+	}
+}
+
+// WithHTTPMetrics enables aggregating the HTTP requests of every app into
+// request counts and latency percentiles. They are emitted every interval
+// to subscriptions for the source ID http_metrics. The index of the RLP
+// identifies its subscription to Dopplers.
+func WithHTTPMetrics(interval time.Duration, index string) RLPOption {
+	return func(r *RLP) {
+		r.httpMetricsInterval = interval
+		r.httpMetricsIndex = index
+	}
+}
+
+// WithFederation connects to the Doppler pool of each foundation instead of
+// the ingress addresses. Every subscription receives from every foundation
+// and envelopes are tagged with the name of their foundation.
+func WithFederation(foundations []federation.Foundation) RLPOption {
+	return func(r *RLP) {
+		r.foundations = foundations
+	}
+}
+
+// WithRemoteWrite enables pushing counters, gauges and timers to a
+// Prometheus remote write endpoint.
+func WithRemoteWrite(conf remotewrite.Config) RLPOption {
+	return func(r *RLP) {
+		r.remoteWrite = conf
+	}
+}
+
+// WithMirror serves the metrics of the mirror on the health endpoint.
+func WithMirror(m *healthendpoint.Mirror) RLPOption {
+	return func(r *RLP) {
+		r.mirror = m
+	}
+}
+
 // EgressAddr returns the address used for the egress server.
 func (r *RLP) EgressAddr() net.Addr {
 	return r.egressAddr
@@ -153,6 +232,14 @@ func (r *RLP) Stop() {
 		r.egressServer.GracefulStop()
 	}()
 
+	for _, c := range r.clusters {
+		c.Stop()
+	}
+
+	if r.finder == nil {
+		return
+	}
+
 	// Stop reconnects to ingress servers
 	r.finder.Stop()
 
@@ -163,6 +250,11 @@ func (r *RLP) Stop() {
 }
 
 func (r *RLP) setupIngress() {
+	if len(r.foundations) > 0 {
+		r.setupFederatedIngress()
+		return
+	}
+
 	r.finder = plumbing.NewStaticFinder(r.ingressAddrs)
 	r.ingressPool = plumbing.NewPool(20, r.ingressDialOpts...)
 
@@ -170,10 +262,31 @@ func (r *RLP) setupIngress() {
 
 	connector := plumbing.NewGRPCConnector(1000, r.ingressPool, r.finder, batcher, r.metricClient)
 	converter := ingress.NewConverter()
+	r.subscriber = connector
 	r.receiver = ingress.NewReceiver(converter, ingress.NewRequestConverter(), connector)
 	r.querier = ingress.NewQuerier(converter, connector)
 }
 
+func (r *RLP) setupFederatedIngress() {
+	for _, f := range r.foundations {
+		tlsConfig, err := f.TLSConfig()
+		if err != nil {
+			log.Fatalf("Could not use TLS config: %s", err)
+		}
+
+		r.clusters = append(r.clusters, federation.Dial(
+			f,
+			r.metricClient,
+			r.health,
+			grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
+		))
+	}
+
+	r.subscriber = federation.NewMultiSubscriber(r.clusters, 1000)
+	r.receiver = federation.NewReceiver(r.clusters, 1000)
+	r.querier = federation.NewQuerier(r.clusters)
+}
+
 func (r *RLP) startEgressListener() {
 	l, err := net.Listen("tcp", fmt.Sprintf(":%d", r.egressPort))
 	if err != nil {
@@ -184,29 +297,63 @@ func (r *RLP) startEgressListener() {
 }
 
 func (r *RLP) setupEgress() {
+	var receiver egress.Receiver = r.receiver
+	if r.appMetadataFetcher != nil {
+		cache := appmeta.NewCache(r.appMetadataFetcher, r.appMetadataTTL, r.metricClient)
+		go cache.Start(time.Second)
+		receiver = appmeta.NewReceiver(r.receiver, cache)
+	}
+
+	if r.httpMetricsInterval > 0 {
+		hr := httpmetrics.NewReceiver(
+			receiver,
+			r.subscriber,
+			httpmetrics.NewAggregator(10000),
+			r.httpMetricsInterval,
+			r.httpMetricsIndex,
+			r.metricClient,
+		)
+		go hr.Start(r.ctx)
+		receiver = hr
+	}
+
+	if r.remoteWrite.URL != "" {
+		exporter := remotewrite.NewExporter(
+			r.remoteWrite,
+			r.subscriber,
+			&http.Client{Timeout: 10 * time.Second},
+			r.metricClient,
+		)
+		go exporter.Start(r.ctx)
+	}
+
 	r.egressServer = grpc.NewServer(r.egressServerOpts...)
 	v2.RegisterEgressServer(
 		r.egressServer,
-		egress.NewServer(r.receiver, r.metricClient, r.health, r.ctx),
+		egress.NewServer(receiver, r.authorizer, r.metricClient, r.health, r.ctx),
 	)
-	v2.RegisterEgressQueryServer(r.egressServer, egress.NewQueryServer(r.querier))
+	v2.RegisterEgressQueryServer(r.egressServer, egress.NewQueryServer(r.querier, r.authorizer))
 }
 
 func (r *RLP) setupHealthEndpoint() {
 	promRegistry := prometheus.NewRegistry()
-	healthendpoint.StartServer(r.healthAddr, promRegistry)
-	r.health = healthendpoint.New(promRegistry, map[string]prometheus.Gauge{
-		// metric-documentation-health: (subscriptionCount)
-		// Number of open subscriptions
-		"subscriptionCount": prometheus.NewGauge(
-			prometheus.GaugeOpts{
-				Namespace: "loggregator",
-				Subsystem: "reverseLogProxy",
-				Name:      "subscriptionCount",
-				Help:      "Number of open subscriptions",
-			},
-		),
-	})
+	gatherers := prometheus.Gatherers{promRegistry}
+	if r.mirror != nil {
+		gatherers = append(gatherers, r.mirror)
+	}
+	healthendpoint.StartServer(r.healthAddr, gatherers)
+	gauges := federation.HealthGauges(r.foundations)
+	// metric-documentation-health: (subscriptionCount)
+	// Number of open subscriptions
+	gauges["subscriptionCount"] = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "loggregator",
+			Subsystem: "reverseLogProxy",
+			Name:      "subscriptionCount",
+			Help:      "Number of open subscriptions",
+		},
+	)
+	r.health = healthendpoint.New(promRegistry, gauges)
 }
 
 func (r *RLP) serveEgress() {
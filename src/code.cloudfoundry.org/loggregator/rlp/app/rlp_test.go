@@ -16,6 +16,7 @@ import (
 	"code.cloudfoundry.org/loggregator/testservers"
 
 	app "code.cloudfoundry.org/loggregator/rlp/app"
+	"code.cloudfoundry.org/loggregator/rlp/internal/federation"
 
 	"google.golang.org/grpc"
 
@@ -56,6 +57,47 @@ var _ = Describe("Start", func() {
 		Expect(envelope.GetDeprecatedTags()["origin"].GetText()).To(Equal("some-origin"))
 	})
 
+	It("receives messages from every foundation when federated", func() {
+		east, eastLis := setupDoppler()
+		defer eastLis.Close()
+		west, westLis := setupDoppler()
+		defer westLis.Close()
+
+		egressAddr, _ := setupFederatedRLP(map[string]net.Listener{
+			"east": eastLis,
+			"west": westLis,
+		})
+
+		egressStream, cleanup := setupRLPStream(egressAddr)
+		defer cleanup()
+
+		for _, doppler := range []*mockDopplerServer{east, west} {
+			var subscriber plumbing.Doppler_SubscribeServer
+			Eventually(doppler.SubscribeInput.Stream, 5).Should(Receive(&subscriber))
+			go func(s plumbing.Doppler_SubscribeServer) {
+				response := &plumbing.Response{
+					Payload: buildLogMessage(),
+				}
+
+				for {
+					err := s.Send(response)
+					if err != nil {
+						log.Printf("subscriber#Send failed: %s\n", err)
+						return
+					}
+				}
+			}(subscriber)
+		}
+
+		foundations := make(map[string]bool)
+		Eventually(func() map[string]bool {
+			envelope, err := egressStream.Recv()
+			Expect(err).ToNot(HaveOccurred())
+			foundations[envelope.GetDeprecatedTags()["foundation"].GetText()] = true
+			return foundations
+		}, 5).Should(And(HaveKey("east"), HaveKey("west")))
+	})
+
 	It("receives container metrics via egress query client", func() {
 		doppler, dopplerLis := setupDoppler()
 		defer dopplerLis.Close()
@@ -307,6 +349,38 @@ func setupRLP(dopplerLis net.Listener, healthAddr string) (addr string, rlp *app
 	return rlp.EgressAddr().String(), rlp
 }
 
+func setupFederatedRLP(dopplers map[string]net.Listener) (addr string, rlp *app.RLP) {
+	egressTLSCredentials, err := plumbing.NewServerCredentials(
+		testservers.Cert("reverselogproxy.crt"),
+		testservers.Cert("reverselogproxy.key"),
+		testservers.Cert("loggregator-ca.crt"),
+	)
+	Expect(err).ToNot(HaveOccurred())
+
+	var foundations []federation.Foundation
+	for name, lis := range dopplers {
+		foundations = append(foundations, federation.Foundation{
+			Name:   name,
+			Addrs:  []string{lis.Addr().String()},
+			CACert: string(testservers.MustAsset("loggregator-ca.crt")),
+			Cert:   string(testservers.MustAsset("reverselogproxy.crt")),
+			Key:    string(testservers.MustAsset("reverselogproxy.key")),
+		})
+	}
+
+	rlp = app.NewRLP(
+		testhelper.NewMetricClient(),
+		app.WithEgressPort(0),
+		app.WithFederation(foundations),
+		app.WithEgressServerOptions(grpc.Creds(egressTLSCredentials)),
+		app.WithMaxEgressConnections(1),
+		app.WithHealthAddr("localhost:0"),
+	)
+
+	go rlp.Start()
+	return rlp.EgressAddr().String(), rlp
+}
+
 func setupRLPClient(egressAddr string) (v2.EgressClient, func()) {
 	ingressTLSCredentials, err := plumbing.NewClientCredentials(
 		testservers.Cert("reverselogproxy.crt"),
@@ -0,0 +1,156 @@
+package appmeta
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/metricemitter"
+)
+
+// Fetcher fetches metadata for many apps at once.
+type Fetcher interface {
+	AppMetadata(guids []string) (map[string]Metadata, error)
+}
+
+// MetricClient creates the hit and miss counters of the cache.
+type MetricClient interface {
+	NewCounter(name string, opts ...metricemitter.MetricOption) *metricemitter.Counter
+}
+
+type entry struct {
+	metadata Metadata
+	found    bool
+	expires  time.Time
+}
+
+// maxRefreshBackoff is the longest Start waits between fetches while
+// fetching fails.
+const maxRefreshBackoff = 5 * time.Minute
+
+// Cache stores app metadata for a TTL. Lookups never block on Cloud
+// Controller: unknown or expired GUIDs are queued and fetched in bulk by
+// Start.
+type Cache struct {
+	fetcher Fetcher
+	ttl     time.Duration
+
+	mu      sync.RWMutex
+	entries map[string]entry
+
+	pendingMu sync.Mutex
+	pending   map[string]struct{}
+
+	hitMetric  *metricemitter.Counter
+	missMetric *metricemitter.Counter
+}
+
+func NewCache(f Fetcher, ttl time.Duration, m MetricClient) *Cache {
+	hitMetric := m.NewCounter("app_metadata_cache_hits",
+		metricemitter.WithVersion(2, 0),
+	)
+
+	missMetric := m.NewCounter("app_metadata_cache_misses",
+		metricemitter.WithVersion(2, 0),
+	)
+
+	return &Cache{
+		fetcher:    f,
+		ttl:        ttl,
+		entries:    make(map[string]entry),
+		pending:    make(map[string]struct{}),
+		hitMetric:  hitMetric,
+		missMetric: missMetric,
+	}
+}
+
+// Lookup returns the metadata for the given GUID. Expired metadata is still
+// returned while it is being refreshed.
+func (c *Cache) Lookup(guid string) (Metadata, bool) {
+	c.mu.RLock()
+	e, ok := c.entries[guid]
+	c.mu.RUnlock()
+
+	if ok && time.Now().Before(e.expires) {
+		// metric-documentation-v2: (loggregator.rlp.app_metadata_cache_hits)
+		// Number of app metadata lookups answered from the cache.
+		c.hitMetric.Increment(1)
+		return e.metadata, e.found
+	}
+
+	// metric-documentation-v2: (loggregator.rlp.app_metadata_cache_misses)
+	// Number of app metadata lookups that required a Cloud Controller fetch.
+	c.missMetric.Increment(1)
+
+	c.pendingMu.Lock()
+	c.pending[guid] = struct{}{}
+	c.pendingMu.Unlock()
+
+	return e.metadata, e.found
+}
+
+// Start fetches queued GUIDs on the given interval. While fetching fails
+// the interval is doubled up to maxRefreshBackoff. It blocks forever.
+func (c *Cache) Start(interval time.Duration) {
+	wait := interval
+	for {
+		time.Sleep(wait)
+
+		if err := c.refresh(); err != nil {
+			log.Printf("Failed to fetch app metadata: %s", err)
+
+			wait *= 2
+			if wait > maxRefreshBackoff {
+				wait = maxRefreshBackoff
+			}
+			continue
+		}
+
+		wait = interval
+	}
+}
+
+func (c *Cache) refresh() error {
+	c.pendingMu.Lock()
+	var guids []string
+	for guid := range c.pending {
+		guids = append(guids, guid)
+	}
+	c.pendingMu.Unlock()
+
+	if len(guids) == 0 {
+		return nil
+	}
+
+	results, err := c.fetcher.AppMetadata(guids)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	now := time.Now()
+	for _, guid := range guids {
+		md, found := results[guid]
+		c.entries[guid] = entry{
+			metadata: md,
+			found:    found,
+			expires:  now.Add(c.ttl),
+		}
+	}
+
+	// Drop entries nobody has asked for in a while.
+	for guid, e := range c.entries {
+		if now.Sub(e.expires) > c.ttl {
+			delete(c.entries, guid)
+		}
+	}
+	c.mu.Unlock()
+
+	c.pendingMu.Lock()
+	for _, guid := range guids {
+		delete(c.pending, guid)
+	}
+	c.pendingMu.Unlock()
+
+	return nil
+}
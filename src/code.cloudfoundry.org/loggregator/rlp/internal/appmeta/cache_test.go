@@ -0,0 +1,148 @@
+package appmeta_test
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/metricemitter/testhelper"
+	"code.cloudfoundry.org/loggregator/rlp/internal/appmeta"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cache", func() {
+	var (
+		fetcher      *spyFetcher
+		metricClient *testhelper.SpyMetricClient
+		cache        *appmeta.Cache
+	)
+
+	BeforeEach(func() {
+		fetcher = newSpyFetcher()
+		fetcher.results = map[string]appmeta.Metadata{
+			"app-1": {AppID: "app-1", AppName: "app-name-1"},
+		}
+		metricClient = testhelper.NewMetricClient()
+		cache = appmeta.NewCache(fetcher, time.Hour, metricClient)
+	})
+
+	It("reports a miss for unknown apps and fetches them in the background", func() {
+		_, ok := cache.Lookup("app-1")
+		Expect(ok).To(BeFalse())
+		Expect(metricClient.GetDelta("app_metadata_cache_misses")).To(Equal(uint64(1)))
+
+		go cache.Start(10 * time.Millisecond)
+
+		Eventually(func() bool {
+			_, ok := cache.Lookup("app-1")
+			return ok
+		}).Should(BeTrue())
+
+		md, _ := cache.Lookup("app-1")
+		Expect(md.AppName).To(Equal("app-name-1"))
+		Expect(metricClient.GetDelta("app_metadata_cache_hits")).To(BeNumerically(">", 0))
+	})
+
+	It("fetches many apps in a single request", func() {
+		cache.Lookup("app-1")
+		cache.Lookup("app-2")
+		cache.Lookup("app-1")
+
+		go cache.Start(10 * time.Millisecond)
+
+		Eventually(fetcher.requestedGUIDs).Should(ConsistOf("app-1", "app-2"))
+	})
+
+	It("does not refetch apps that Cloud Controller does not know about", func() {
+		cache.Lookup("not-an-app")
+		go cache.Start(10 * time.Millisecond)
+		Eventually(fetcher.callCount).Should(Equal(1))
+
+		_, ok := cache.Lookup("not-an-app")
+		Expect(ok).To(BeFalse())
+		Consistently(fetcher.callCount).Should(Equal(1))
+	})
+
+	It("refetches apps after the TTL expires", func() {
+		cache = appmeta.NewCache(fetcher, 50*time.Millisecond, metricClient)
+		cache.Lookup("app-1")
+		go cache.Start(10 * time.Millisecond)
+		Eventually(fetcher.callCount).Should(Equal(1))
+
+		time.Sleep(60 * time.Millisecond)
+		md, ok := cache.Lookup("app-1")
+		Expect(ok).To(BeTrue())
+		Expect(md.AppName).To(Equal("app-name-1"))
+
+		Eventually(fetcher.callCount).Should(Equal(2))
+	})
+
+	It("retries apps when fetching fails", func() {
+		fetcher.setErr(errors.New("some-error"))
+		cache.Lookup("app-1")
+		go cache.Start(10 * time.Millisecond)
+		Eventually(fetcher.callCount).Should(BeNumerically(">=", 1))
+
+		fetcher.setErr(nil)
+		Eventually(func() bool {
+			_, ok := cache.Lookup("app-1")
+			return ok
+		}).Should(BeTrue())
+	})
+
+	It("backs off while fetching fails", func() {
+		fetcher.setErr(errors.New("some-error"))
+		cache.Lookup("app-1")
+		go cache.Start(10 * time.Millisecond)
+
+		// Fetches at 10, 30, 70 and 150ms rather than every 10ms.
+		time.Sleep(200 * time.Millisecond)
+		Expect(fetcher.callCount()).To(BeNumerically("<=", 5))
+		Expect(fetcher.callCount()).To(BeNumerically(">=", 2))
+	})
+})
+
+type spyFetcher struct {
+	mu      sync.Mutex
+	results map[string]appmeta.Metadata
+	err     error
+	calls   int
+	guids   []string
+}
+
+func newSpyFetcher() *spyFetcher {
+	return &spyFetcher{}
+}
+
+func (s *spyFetcher) AppMetadata(guids []string) (map[string]appmeta.Metadata, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls++
+	s.guids = guids
+
+	return s.results, s.err
+}
+
+func (s *spyFetcher) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.err = err
+}
+
+func (s *spyFetcher) callCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.calls
+}
+
+func (s *spyFetcher) requestedGUIDs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.guids
+}
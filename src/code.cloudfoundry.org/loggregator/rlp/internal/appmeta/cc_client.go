@@ -0,0 +1,259 @@
+package appmeta
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metadata holds the names and GUIDs an app is known by in Cloud Controller.
+type Metadata struct {
+	AppID            string
+	AppName          string
+	SpaceID          string
+	SpaceName        string
+	OrganizationID   string
+	OrganizationName string
+}
+
+// CCClient fetches app metadata from the Cloud Controller v3 API. It
+// authenticates with a UAA client credentials grant.
+type CCClient struct {
+	ccAddr       string
+	uaaAddr      string
+	clientID     string
+	clientSecret string
+	batchSize    int
+	httpClient   *http.Client
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+func NewCCClient(
+	c *http.Client,
+	ccAddr string,
+	uaaAddr string,
+	clientID string,
+	clientSecret string,
+) *CCClient {
+	return &CCClient{
+		ccAddr:       strings.TrimRight(ccAddr, "/"),
+		uaaAddr:      strings.TrimRight(uaaAddr, "/"),
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		batchSize:    50,
+		httpClient:   c,
+	}
+}
+
+type ccResource struct {
+	GUID          string `json:"guid"`
+	Name          string `json:"name"`
+	Relationships struct {
+		Space struct {
+			Data struct {
+				GUID string `json:"guid"`
+			} `json:"data"`
+		} `json:"space"`
+		Organization struct {
+			Data struct {
+				GUID string `json:"guid"`
+			} `json:"data"`
+		} `json:"organization"`
+	} `json:"relationships"`
+}
+
+type ccListResponse struct {
+	Resources  []ccResource `json:"resources"`
+	Pagination struct {
+		Next *struct {
+			Href string `json:"href"`
+		} `json:"next"`
+	} `json:"pagination"`
+}
+
+// AppMetadata returns the metadata for each of the given app GUIDs that
+// Cloud Controller knows about. GUIDs that are not apps are omitted.
+func (c *CCClient) AppMetadata(guids []string) (map[string]Metadata, error) {
+	apps, err := c.list("apps", guids)
+	if err != nil {
+		return nil, err
+	}
+
+	var spaceGUIDs []string
+	for _, a := range apps {
+		spaceGUIDs = append(spaceGUIDs, a.Relationships.Space.Data.GUID)
+	}
+
+	spaces, err := c.list("spaces", unique(spaceGUIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	var orgGUIDs []string
+	for _, s := range spaces {
+		orgGUIDs = append(orgGUIDs, s.Relationships.Organization.Data.GUID)
+	}
+
+	orgs, err := c.list("organizations", unique(orgGUIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]Metadata)
+	for _, a := range apps {
+		space := spaces[a.Relationships.Space.Data.GUID]
+		org := orgs[space.Relationships.Organization.Data.GUID]
+
+		result[a.GUID] = Metadata{
+			AppID:            a.GUID,
+			AppName:          a.Name,
+			SpaceID:          space.GUID,
+			SpaceName:        space.Name,
+			OrganizationID:   org.GUID,
+			OrganizationName: org.Name,
+		}
+	}
+
+	return result, nil
+}
+
+func (c *CCClient) list(resource string, guids []string) (map[string]ccResource, error) {
+	result := make(map[string]ccResource)
+
+	for len(guids) > 0 {
+		n := c.batchSize
+		if n > len(guids) {
+			n = len(guids)
+		}
+
+		query := url.Values{
+			"guids":    []string{strings.Join(guids[:n], ",")},
+			"per_page": []string{fmt.Sprint(n)},
+		}
+		next := fmt.Sprintf("%s/v3/%s?%s", c.ccAddr, resource, query.Encode())
+		guids = guids[n:]
+
+		for next != "" {
+			var resp ccListResponse
+			if err := c.get(next, &resp); err != nil {
+				return nil, err
+			}
+
+			for _, r := range resp.Resources {
+				result[r.GUID] = r
+			}
+
+			next = ""
+			if resp.Pagination.Next != nil {
+				next = resp.Pagination.Next.Href
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (c *CCClient) get(addr string, v interface{}) error {
+	token, err := c.getToken()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("GET", addr, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		c.resetToken()
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code from Cloud Controller: %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+func (c *CCClient) getToken() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && time.Now().Before(c.tokenExpiry) {
+		return c.token, nil
+	}
+
+	form := url.Values{"grant_type": []string{"client_credentials"}}
+	req, err := http.NewRequest("POST", c.uaaAddr+"/oauth/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(c.clientID, c.clientSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code from UAA: %d", resp.StatusCode)
+	}
+
+	var t tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return "", err
+	}
+
+	if t.AccessToken == "" {
+		return "", errors.New("UAA returned an empty access token")
+	}
+
+	// Refresh the token a little early so requests in flight do not fail.
+	c.token = t.AccessToken
+	c.tokenExpiry = time.Now().Add(time.Duration(t.ExpiresIn)*time.Second - 30*time.Second)
+
+	return c.token, nil
+}
+
+func (c *CCClient) resetToken() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.token = ""
+}
+
+func unique(s []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, v := range s {
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+
+	return result
+}
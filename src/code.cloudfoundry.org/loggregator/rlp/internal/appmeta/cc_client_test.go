@@ -0,0 +1,174 @@
+package appmeta_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+
+	"code.cloudfoundry.org/loggregator/rlp/internal/appmeta"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CCClient", func() {
+	var (
+		fakeCC     *fakeCloudController
+		server     *httptest.Server
+		client     *appmeta.CCClient
+		tokenCalls int64
+	)
+
+	BeforeEach(func() {
+		atomic.StoreInt64(&tokenCalls, 0)
+		fakeCC = &fakeCloudController{
+			apps: map[string][2]string{
+				"app-1": {"app-name-1", "space-1"},
+				"app-2": {"app-name-2", "space-2"},
+			},
+			spaces: map[string][2]string{
+				"space-1": {"space-name-1", "org-1"},
+				"space-2": {"space-name-2", "org-1"},
+			},
+			orgs: map[string]string{
+				"org-1": "org-name-1",
+			},
+		}
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt64(&tokenCalls, 1)
+			id, secret, _ := r.BasicAuth()
+			if id != "some-client" || secret != "some-secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"access_token": "some-token", "expires_in": 3600}`))
+		})
+		mux.Handle("/v3/", fakeCC)
+		server = httptest.NewServer(mux)
+
+		client = appmeta.NewCCClient(http.DefaultClient, server.URL, server.URL, "some-client", "some-secret")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("resolves apps to their space and organization", func() {
+		md, err := client.AppMetadata([]string{"app-1", "app-2", "not-an-app"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(md).To(Equal(map[string]appmeta.Metadata{
+			"app-1": {
+				AppID:            "app-1",
+				AppName:          "app-name-1",
+				SpaceID:          "space-1",
+				SpaceName:        "space-name-1",
+				OrganizationID:   "org-1",
+				OrganizationName: "org-name-1",
+			},
+			"app-2": {
+				AppID:            "app-2",
+				AppName:          "app-name-2",
+				SpaceID:          "space-2",
+				SpaceName:        "space-name-2",
+				OrganizationID:   "org-1",
+				OrganizationName: "org-name-1",
+			},
+		}))
+	})
+
+	It("sends the UAA token to Cloud Controller", func() {
+		_, err := client.AppMetadata([]string{"app-1"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fakeCC.authHeader()).To(Equal("bearer some-token"))
+	})
+
+	It("reuses the token until it expires", func() {
+		_, err := client.AppMetadata([]string{"app-1"})
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.AppMetadata([]string{"app-2"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(atomic.LoadInt64(&tokenCalls)).To(Equal(int64(1)))
+	})
+
+	It("returns an error when UAA rejects the credentials", func() {
+		client = appmeta.NewCCClient(http.DefaultClient, server.URL, server.URL, "bad", "creds")
+
+		_, err := client.AppMetadata([]string{"app-1"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("returns an error when Cloud Controller fails", func() {
+		fakeCC.fail = true
+
+		_, err := client.AppMetadata([]string{"app-1"})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+type fakeCloudController struct {
+	apps   map[string][2]string
+	spaces map[string][2]string
+	orgs   map[string]string
+	fail   bool
+
+	lastAuth atomic.Value
+}
+
+func (f *fakeCloudController) authHeader() string {
+	v, _ := f.lastAuth.Load().(string)
+	return v
+}
+
+func (f *fakeCloudController) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.lastAuth.Store(r.Header.Get("Authorization"))
+
+	if f.fail {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var resources []map[string]interface{}
+	for _, guid := range strings.Split(r.URL.Query().Get("guids"), ",") {
+		switch r.URL.Path {
+		case "/v3/apps":
+			if a, ok := f.apps[guid]; ok {
+				resources = append(resources, resource(guid, a[0], "space", a[1]))
+			}
+		case "/v3/spaces":
+			if s, ok := f.spaces[guid]; ok {
+				resources = append(resources, resource(guid, s[0], "organization", s[1]))
+			}
+		case "/v3/organizations":
+			if name, ok := f.orgs[guid]; ok {
+				resources = append(resources, map[string]interface{}{"guid": guid, "name": name})
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"resources":  resources,
+		"pagination": map[string]interface{}{"next": nil},
+	})
+}
+
+func resource(guid, name, relationship, relatedGUID string) map[string]interface{} {
+	return map[string]interface{}{
+		"guid": guid,
+		"name": name,
+		"relationships": map[string]interface{}{
+			relationship: map[string]interface{}{
+				"data": map[string]interface{}{"guid": relatedGUID},
+			},
+		},
+	}
+}
@@ -0,0 +1,83 @@
+package appmeta
+
+import (
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+
+	"golang.org/x/net/context"
+)
+
+type EgressReceiver interface {
+	Receive(ctx context.Context, req *v2.EgressRequest) (rx func() (*v2.Envelope, error), err error)
+}
+
+type MetadataStore interface {
+	Lookup(guid string) (Metadata, bool)
+}
+
+// Receiver adds app metadata tags to envelopes for requests that ask for
+// them.
+type Receiver struct {
+	receiver EgressReceiver
+	store    MetadataStore
+}
+
+func NewReceiver(r EgressReceiver, s MetadataStore) *Receiver {
+	return &Receiver{
+		receiver: r,
+		store:    s,
+	}
+}
+
+func (r *Receiver) Receive(ctx context.Context, req *v2.EgressRequest) (rx func() (*v2.Envelope, error), err error) {
+	next, err := r.receiver.Receive(ctx, req)
+	if err != nil || !req.GetIncludeAppMetadata() {
+		return next, err
+	}
+
+	return func() (*v2.Envelope, error) {
+		e, err := next()
+		if err != nil {
+			return nil, err
+		}
+
+		if md, ok := r.store.Lookup(e.GetSourceId()); ok {
+			addTags(e, md, req.GetUsePreferredTags())
+		}
+
+		return e, nil
+	}, nil
+}
+
+func addTags(e *v2.Envelope, md Metadata, usePreferredTags bool) {
+	tags := map[string]string{
+		"app_id":            md.AppID,
+		"app_name":          md.AppName,
+		"space_id":          md.SpaceID,
+		"space_name":        md.SpaceName,
+		"organization_id":   md.OrganizationID,
+		"organization_name": md.OrganizationName,
+	}
+
+	if usePreferredTags {
+		if e.Tags == nil {
+			e.Tags = make(map[string]string)
+		}
+
+		for k, v := range tags {
+			e.Tags[k] = v
+		}
+		return
+	}
+
+	if e.DeprecatedTags == nil {
+		e.DeprecatedTags = make(map[string]*v2.Value)
+	}
+
+	for k, v := range tags {
+		e.DeprecatedTags[k] = &v2.Value{
+			Data: &v2.Value_Text{
+				Text: v,
+			},
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package appmeta_test
+
+import (
+	"errors"
+
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+	"code.cloudfoundry.org/loggregator/rlp/internal/appmeta"
+
+	"golang.org/x/net/context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Receiver", func() {
+	var (
+		spyReceiver *spyEgressReceiver
+		store       *spyStore
+		receiver    *appmeta.Receiver
+	)
+
+	BeforeEach(func() {
+		spyReceiver = &spyEgressReceiver{
+			envelope: &v2.Envelope{SourceId: "app-1"},
+		}
+		store = &spyStore{
+			metadata: map[string]appmeta.Metadata{
+				"app-1": {
+					AppID:            "app-1",
+					AppName:          "app-name",
+					SpaceID:          "space-1",
+					SpaceName:        "space-name",
+					OrganizationID:   "org-1",
+					OrganizationName: "org-name",
+				},
+			},
+		}
+		receiver = appmeta.NewReceiver(spyReceiver, store)
+	})
+
+	It("does not add tags when the request does not ask for them", func() {
+		rx, err := receiver.Receive(context.TODO(), &v2.EgressRequest{})
+		Expect(err).ToNot(HaveOccurred())
+
+		e, err := rx()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(e.Tags).To(BeEmpty())
+		Expect(e.DeprecatedTags).To(BeEmpty())
+		Expect(store.lookups).To(BeZero())
+	})
+
+	It("adds app metadata as preferred tags", func() {
+		rx, err := receiver.Receive(context.TODO(), &v2.EgressRequest{
+			IncludeAppMetadata: true,
+			UsePreferredTags:   true,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		e, err := rx()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(e.Tags).To(Equal(map[string]string{
+			"app_id":            "app-1",
+			"app_name":          "app-name",
+			"space_id":          "space-1",
+			"space_name":        "space-name",
+			"organization_id":   "org-1",
+			"organization_name": "org-name",
+		}))
+	})
+
+	It("adds app metadata as deprecated tags", func() {
+		rx, err := receiver.Receive(context.TODO(), &v2.EgressRequest{
+			IncludeAppMetadata: true,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		e, err := rx()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(e.DeprecatedTags["app_name"].GetText()).To(Equal("app-name"))
+		Expect(e.DeprecatedTags["organization_name"].GetText()).To(Equal("org-name"))
+	})
+
+	It("leaves envelopes for unknown sources untouched", func() {
+		spyReceiver.envelope = &v2.Envelope{SourceId: "doppler"}
+		rx, err := receiver.Receive(context.TODO(), &v2.EgressRequest{
+			IncludeAppMetadata: true,
+			UsePreferredTags:   true,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		e, err := rx()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(e.Tags).To(BeEmpty())
+	})
+
+	It("returns errors from the underlying receiver", func() {
+		spyReceiver.err = errors.New("some-error")
+
+		_, err := receiver.Receive(context.TODO(), &v2.EgressRequest{IncludeAppMetadata: true})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+type spyEgressReceiver struct {
+	envelope *v2.Envelope
+	err      error
+}
+
+func (s *spyEgressReceiver) Receive(ctx context.Context, req *v2.EgressRequest) (func() (*v2.Envelope, error), error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return func() (*v2.Envelope, error) {
+		return s.envelope, nil
+	}, nil
+}
+
+type spyStore struct {
+	metadata map[string]appmeta.Metadata
+	lookups  int
+}
+
+func (s *spyStore) Lookup(guid string) (appmeta.Metadata, bool) {
+	s.lookups++
+	md, ok := s.metadata[guid]
+	return md, ok
+}
@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// Client configures the permissions of an RLP client. A client is identified
+// by the common name or a subject alternative name of its certificate or by
+// the UAA client ID of the bearer token it presents.
+type Client struct {
+	Name             string   `json:"name"`
+	CertificateNames []string `json:"certificate_names"`
+	UAAClientIDs     []string `json:"uaa_client_ids"`
+	SourceIDs        []string `json:"source_ids"`
+	EnvelopeTypes    []string `json:"envelope_types"`
+	Firehose         bool     `json:"firehose"`
+}
+
+// LoadClients reads a JSON list of clients from the given file.
+func LoadClients(path string) ([]Client, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var clients []Client
+	if err := json.NewDecoder(f).Decode(&clients); err != nil {
+		return nil, fmt.Errorf("invalid authorization config %s: %s", path, err)
+	}
+
+	return clients, nil
+}
+
+// TokenValidator validates a bearer token and returns the UAA client ID it
+// was issued to.
+type TokenValidator interface {
+	ClientID(token string) (string, error)
+}
+
+// Authorizer looks up the permissions of the client making a gRPC request.
+type Authorizer struct {
+	byCertName map[string]Permissions
+	byClientID map[string]Permissions
+	validator  TokenValidator
+}
+
+// NewAuthorizer returns an Authorizer for the given clients. The validator
+// may be nil if no client is identified by a UAA client ID.
+func NewAuthorizer(clients []Client, v TokenValidator) (*Authorizer, error) {
+	a := &Authorizer{
+		byCertName: make(map[string]Permissions),
+		byClientID: make(map[string]Permissions),
+		validator:  v,
+	}
+
+	for _, c := range clients {
+		if len(c.CertificateNames) == 0 && len(c.UAAClientIDs) == 0 {
+			return nil, fmt.Errorf("client %s requires certificate names or UAA client IDs", c.Name)
+		}
+
+		if len(c.UAAClientIDs) > 0 && v == nil {
+			return nil, fmt.Errorf("client %s requires UAA to validate tokens", c.Name)
+		}
+
+		p, err := NewPermissions(c.SourceIDs, c.EnvelopeTypes, c.Firehose)
+		if err != nil {
+			return nil, fmt.Errorf("client %s: %s", c.Name, err)
+		}
+
+		for _, n := range c.CertificateNames {
+			if _, ok := a.byCertName[n]; ok {
+				return nil, fmt.Errorf("certificate name %s is configured more than once", n)
+			}
+			a.byCertName[n] = p
+		}
+
+		for _, id := range c.UAAClientIDs {
+			if _, ok := a.byClientID[id]; ok {
+				return nil, fmt.Errorf("UAA client ID %s is configured more than once", id)
+			}
+			a.byClientID[id] = p
+		}
+	}
+
+	return a, nil
+}
+
+// Authorize returns the permissions of the client. A bearer token in the
+// authorization metadata takes precedence over the client certificate.
+func (a *Authorizer) Authorize(ctx context.Context) (Permissions, error) {
+	if token, ok := bearerToken(ctx); ok {
+		return a.authorizeToken(token)
+	}
+
+	return a.authorizeCert(ctx)
+}
+
+func (a *Authorizer) authorizeToken(token string) (Permissions, error) {
+	if a.validator == nil {
+		return Permissions{}, errors.New("bearer tokens are not accepted")
+	}
+
+	clientID, err := a.validator.ClientID(token)
+	if err != nil {
+		return Permissions{}, err
+	}
+
+	p, ok := a.byClientID[clientID]
+	if !ok {
+		return Permissions{}, fmt.Errorf("UAA client %s is not authorized", clientID)
+	}
+
+	return p, nil
+}
+
+func (a *Authorizer) authorizeCert(ctx context.Context) (Permissions, error) {
+	pr, ok := peer.FromContext(ctx)
+	if !ok {
+		return Permissions{}, errors.New("unable to determine peer")
+	}
+
+	tlsInfo, ok := pr.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.PeerCertificates) == 0 {
+		return Permissions{}, errors.New("client did not present a certificate")
+	}
+
+	cert := tlsInfo.State.PeerCertificates[0]
+	names := append([]string{cert.Subject.CommonName}, cert.DNSNames...)
+	for _, n := range names {
+		if p, ok := a.byCertName[n]; ok {
+			return p, nil
+		}
+	}
+
+	return Permissions{}, fmt.Errorf("certificate %s is not authorized", cert.Subject.CommonName)
+}
+
+func bearerToken(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	for _, v := range md["authorization"] {
+		parts := strings.SplitN(v, " ", 2)
+		if len(parts) == 2 && strings.ToLower(parts[0]) == "bearer" {
+			return parts[1], true
+		}
+	}
+
+	return "", false
+}
+
+// AllowAll grants every client unrestricted access. It is used when no
+// clients are configured.
+type AllowAll struct{}
+
+// Authorize always returns Unrestricted.
+func (AllowAll) Authorize(context.Context) (Permissions, error) {
+	return Unrestricted, nil
+}
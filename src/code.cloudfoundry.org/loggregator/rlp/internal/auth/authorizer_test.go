@@ -0,0 +1,159 @@
+package auth_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+
+	"code.cloudfoundry.org/loggregator/rlp/internal/auth"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Authorizer", func() {
+	var (
+		validator  *spyTokenValidator
+		authorizer *auth.Authorizer
+	)
+
+	BeforeEach(func() {
+		validator = &spyTokenValidator{
+			clientIDs: map[string]string{"good-token": "nozzle-client"},
+		}
+
+		var err error
+		authorizer, err = auth.NewAuthorizer([]auth.Client{
+			{
+				Name:             "app-team",
+				CertificateNames: []string{"app-nozzle", "app-nozzle.example.com"},
+				SourceIDs:        []string{"app-1"},
+				EnvelopeTypes:    []string{"log"},
+			},
+			{
+				Name:         "platform-team",
+				UAAClientIDs: []string{"nozzle-client"},
+				Firehose:     true,
+			},
+		}, validator)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("authorizes clients by certificate common name", func() {
+		p, err := authorizer.Authorize(certContext("app-nozzle"))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(p.CanRead("app-1")).To(BeTrue())
+		Expect(p.CanRead("app-2")).To(BeFalse())
+		Expect(p.CanReadType("log")).To(BeTrue())
+		Expect(p.CanReadType("gauge")).To(BeFalse())
+	})
+
+	It("authorizes clients by certificate subject alternative name", func() {
+		p, err := authorizer.Authorize(certContext("other", "app-nozzle.example.com"))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(p.CanRead("app-1")).To(BeTrue())
+	})
+
+	It("rejects unknown certificates", func() {
+		_, err := authorizer.Authorize(certContext("unknown"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rejects clients without a certificate or token", func() {
+		_, err := authorizer.Authorize(context.Background())
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("authorizes clients by bearer token", func() {
+		ctx := tokenContext(certContext("unknown"), "bearer good-token")
+
+		p, err := authorizer.Authorize(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(p.CanRead("any-source")).To(BeTrue())
+		Expect(validator.token).To(Equal("good-token"))
+	})
+
+	It("rejects invalid tokens", func() {
+		_, err := authorizer.Authorize(tokenContext(certContext("app-nozzle"), "bearer bad-token"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rejects tokens of unknown UAA clients", func() {
+		validator.clientIDs["other-token"] = "other-client"
+
+		_, err := authorizer.Authorize(tokenContext(context.Background(), "bearer other-token"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rejects tokens when no validator is configured", func() {
+		a, err := auth.NewAuthorizer([]auth.Client{
+			{Name: "app-team", CertificateNames: []string{"app-nozzle"}},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = a.Authorize(tokenContext(certContext("app-nozzle"), "bearer good-token"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	DescribeTable("returns an error for invalid clients", func(c auth.Client) {
+		_, err := auth.NewAuthorizer([]auth.Client{c}, nil)
+		Expect(err).To(HaveOccurred())
+	},
+		Entry("no identity", auth.Client{Name: "a"}),
+		Entry("UAA clients without a validator", auth.Client{Name: "a", UAAClientIDs: []string{"id"}}),
+		Entry("unknown envelope type", auth.Client{Name: "a", CertificateNames: []string{"a"}, EnvelopeTypes: []string{"metric"}}),
+	)
+
+	It("returns an error for duplicate certificate names", func() {
+		_, err := auth.NewAuthorizer([]auth.Client{
+			{Name: "a", CertificateNames: []string{"a"}},
+			{Name: "b", CertificateNames: []string{"a"}},
+		}, nil)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+func certContext(cn string, sans ...string) context.Context {
+	cert := &x509.Certificate{
+		Subject:  pkix.Name{CommonName: cn},
+		DNSNames: sans,
+	}
+
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{cert},
+			},
+		},
+	})
+}
+
+func tokenContext(ctx context.Context, authorization string) context.Context {
+	return metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", authorization))
+}
+
+type spyTokenValidator struct {
+	token     string
+	clientIDs map[string]string
+}
+
+func (s *spyTokenValidator) ClientID(token string) (string, error) {
+	s.token = token
+
+	id, ok := s.clientIDs[token]
+	if !ok {
+		return "", errors.New("invalid token")
+	}
+
+	return id, nil
+}
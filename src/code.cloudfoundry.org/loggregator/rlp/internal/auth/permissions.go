@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+)
+
+// Envelope types that access can be restricted to.
+const (
+	LogType     = "log"
+	CounterType = "counter"
+	GaugeType   = "gauge"
+	TimerType   = "timer"
+)
+
+// Permissions describe what a client is allowed to read.
+type Permissions struct {
+	unrestricted  bool
+	firehose      bool
+	sourceIDs     map[string]bool
+	envelopeTypes map[string]bool
+}
+
+// Unrestricted permissions allow reading everything.
+var Unrestricted = Permissions{unrestricted: true}
+
+// NewPermissions returns Permissions for the given source IDs and envelope
+// types. An empty list of envelope types allows every type. Firehose access
+// allows reading every source ID.
+func NewPermissions(sourceIDs, envelopeTypes []string, firehose bool) (Permissions, error) {
+	p := Permissions{
+		firehose:  firehose,
+		sourceIDs: make(map[string]bool),
+	}
+
+	for _, id := range sourceIDs {
+		p.sourceIDs[id] = true
+	}
+
+	if len(envelopeTypes) > 0 {
+		p.envelopeTypes = make(map[string]bool)
+	}
+	for _, t := range envelopeTypes {
+		switch t {
+		case LogType, CounterType, GaugeType, TimerType:
+			p.envelopeTypes[t] = true
+		default:
+			return Permissions{}, fmt.Errorf("unknown envelope type: %s", t)
+		}
+	}
+
+	return p, nil
+}
+
+// CanRead reports whether envelopes with the given source ID may be read.
+func (p Permissions) CanRead(sourceID string) bool {
+	return p.unrestricted || p.firehose || p.sourceIDs[sourceID]
+}
+
+// CanReadType reports whether envelopes of the given type may be read.
+func (p Permissions) CanReadType(envelopeType string) bool {
+	return p.unrestricted || p.envelopeTypes == nil || p.envelopeTypes[envelopeType]
+}
+
+// CheckRequest returns an error if the request asks for data the client may
+// not read.
+func (p Permissions) CheckRequest(req *v2.EgressRequest) error {
+	sourceID := req.GetFilter().GetSourceId()
+	if sourceID == "" {
+		if !p.unrestricted && !p.firehose {
+			return errors.New("not authorized to read the firehose")
+		}
+		return nil
+	}
+
+	if !p.CanRead(sourceID) {
+		return fmt.Errorf("not authorized to read source id %s", sourceID)
+	}
+
+	if req.GetFilter().GetLog() != nil && !p.CanReadType(LogType) {
+		return errors.New("not authorized to read logs")
+	}
+
+	return nil
+}
+
+// Allows reports whether the envelope may be read.
+func (p Permissions) Allows(e *v2.Envelope) bool {
+	return p.CanRead(e.GetSourceId()) && p.CanReadType(envelopeType(e))
+}
+
+func envelopeType(e *v2.Envelope) string {
+	switch e.GetMessage().(type) {
+	case *v2.Envelope_Log:
+		return LogType
+	case *v2.Envelope_Counter:
+		return CounterType
+	case *v2.Envelope_Gauge:
+		return GaugeType
+	case *v2.Envelope_Timer:
+		return TimerType
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,83 @@
+package auth_test
+
+import (
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+	"code.cloudfoundry.org/loggregator/rlp/internal/auth"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Permissions", func() {
+	var (
+		logEnvelope   = &v2.Envelope{SourceId: "app-1", Message: &v2.Envelope_Log{Log: &v2.Log{}}}
+		gaugeEnvelope = &v2.Envelope{SourceId: "app-1", Message: &v2.Envelope_Gauge{Gauge: &v2.Gauge{}}}
+		otherEnvelope = &v2.Envelope{SourceId: "app-2", Message: &v2.Envelope_Log{Log: &v2.Log{}}}
+	)
+
+	It("allows everything when unrestricted", func() {
+		Expect(auth.Unrestricted.CheckRequest(&v2.EgressRequest{})).To(Succeed())
+		Expect(auth.Unrestricted.Allows(gaugeEnvelope)).To(BeTrue())
+	})
+
+	Context("with source IDs and envelope types", func() {
+		var p auth.Permissions
+
+		BeforeEach(func() {
+			var err error
+			p, err = auth.NewPermissions([]string{"app-1"}, []string{"log"}, false)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("allows requests for an allowed source ID", func() {
+			Expect(p.CheckRequest(sourceRequest("app-1"))).To(Succeed())
+		})
+
+		It("denies requests for other source IDs", func() {
+			Expect(p.CheckRequest(sourceRequest("app-2"))).ToNot(Succeed())
+		})
+
+		It("denies firehose requests", func() {
+			Expect(p.CheckRequest(&v2.EgressRequest{})).ToNot(Succeed())
+		})
+
+		It("only allows envelopes of allowed types and sources", func() {
+			Expect(p.Allows(logEnvelope)).To(BeTrue())
+			Expect(p.Allows(gaugeEnvelope)).To(BeFalse())
+			Expect(p.Allows(otherEnvelope)).To(BeFalse())
+		})
+	})
+
+	Context("with firehose access", func() {
+		var p auth.Permissions
+
+		BeforeEach(func() {
+			var err error
+			p, err = auth.NewPermissions(nil, []string{"gauge"}, true)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("allows firehose and source ID requests", func() {
+			Expect(p.CheckRequest(&v2.EgressRequest{})).To(Succeed())
+			Expect(p.CheckRequest(sourceRequest("app-2"))).To(Succeed())
+		})
+
+		It("denies log requests without log access", func() {
+			req := sourceRequest("app-1")
+			req.Filter.Message = &v2.Filter_Log{Log: &v2.LogFilter{}}
+
+			Expect(p.CheckRequest(req)).ToNot(Succeed())
+		})
+
+		It("filters envelopes by type", func() {
+			Expect(p.Allows(gaugeEnvelope)).To(BeTrue())
+			Expect(p.Allows(logEnvelope)).To(BeFalse())
+		})
+	})
+})
+
+func sourceRequest(sourceID string) *v2.EgressRequest {
+	return &v2.EgressRequest{
+		Filter: &v2.Filter{SourceId: sourceID},
+	}
+}
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// UAAClient validates bearer tokens with the UAA check_token endpoint. It
+// authenticates with a client that has the uaa.resource authority.
+type UAAClient struct {
+	addr         string
+	clientID     string
+	clientSecret string
+	httpClient   *http.Client
+}
+
+// NewUAAClient returns a UAAClient for the UAA at addr that authenticates
+// with the given client ID and secret.
+func NewUAAClient(c *http.Client, addr, clientID, clientSecret string) *UAAClient {
+	return &UAAClient{
+		addr:         strings.TrimRight(addr, "/"),
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		httpClient:   c,
+	}
+}
+
+type checkTokenResponse struct {
+	ClientID string `json:"client_id"`
+}
+
+// ClientID returns the UAA client the token was issued to.
+func (c *UAAClient) ClientID(token string) (string, error) {
+	form := url.Values{"token": []string{token}}
+	req, err := http.NewRequest("POST", c.addr+"/check_token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(c.clientID, c.clientSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("invalid token: UAA returned %d", resp.StatusCode)
+	}
+
+	var r checkTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return "", err
+	}
+
+	if r.ClientID == "" {
+		return "", errors.New("invalid token: no client_id")
+	}
+
+	return r.ClientID, nil
+}
@@ -0,0 +1,59 @@
+package auth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"code.cloudfoundry.org/loggregator/rlp/internal/auth"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UAAClient", func() {
+	var (
+		server *httptest.Server
+		client *auth.UAAClient
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, secret, _ := r.BasicAuth()
+			if r.URL.Path != "/check_token" || id != "rlp" || secret != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			if r.FormValue("token") != "good-token" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			w.Write([]byte(`{"client_id": "nozzle-client", "scope": ["doppler.firehose"]}`))
+		}))
+
+		client = auth.NewUAAClient(http.DefaultClient, server.URL, "rlp", "secret")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("returns the client ID of a valid token", func() {
+		id, err := client.ClientID("good-token")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(id).To(Equal("nozzle-client"))
+	})
+
+	It("returns an error for an invalid token", func() {
+		_, err := client.ClientID("bad-token")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("returns an error when UAA rejects the credentials", func() {
+		client = auth.NewUAAClient(http.DefaultClient, server.URL, "bad", "creds")
+
+		_, err := client.ClientID("good-token")
+		Expect(err).To(HaveOccurred())
+	})
+})
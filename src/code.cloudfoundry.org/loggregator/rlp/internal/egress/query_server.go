@@ -4,8 +4,11 @@ import (
 	"errors"
 
 	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+	"code.cloudfoundry.org/loggregator/rlp/internal/auth"
 
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 )
 
 type ContainerMetricFetcher interface {
@@ -13,12 +16,14 @@ type ContainerMetricFetcher interface {
 }
 
 type QueryServer struct {
-	fetcher ContainerMetricFetcher
+	fetcher    ContainerMetricFetcher
+	authorizer Authorizer
 }
 
-func NewQueryServer(f ContainerMetricFetcher) *QueryServer {
+func NewQueryServer(f ContainerMetricFetcher, a Authorizer) *QueryServer {
 	return &QueryServer{
-		fetcher: f,
+		fetcher:    f,
+		authorizer: a,
 	}
 }
 
@@ -27,6 +32,15 @@ func (s *QueryServer) ContainerMetrics(ctx context.Context, req *v2.ContainerMet
 		return nil, errors.New("source_id is required")
 	}
 
+	perms, err := s.authorizer.Authorize(ctx)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Unauthenticated, "unauthenticated")
+	}
+
+	if !perms.CanRead(req.SourceId) || !perms.CanReadType(auth.GaugeType) {
+		return nil, grpc.Errorf(codes.PermissionDenied, "not authorized to read container metrics for source id %s", req.SourceId)
+	}
+
 	results, err := s.fetcher.ContainerMetrics(ctx, req.SourceId, req.UsePreferredTags)
 	if err != nil {
 		return nil, err
@@ -5,9 +5,12 @@ import (
 
 	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
 
+	"code.cloudfoundry.org/loggregator/rlp/internal/auth"
 	"code.cloudfoundry.org/loggregator/rlp/internal/egress"
 
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -21,7 +24,7 @@ var _ = Describe("QueryServer", func() {
 
 	BeforeEach(func() {
 		spy = newSpyContainerMetricFetcher()
-		server = egress.NewQueryServer(spy)
+		server = egress.NewQueryServer(spy, auth.AllowAll{})
 	})
 
 	It("requests the correct appID", func() {
@@ -71,6 +74,27 @@ var _ = Describe("QueryServer", func() {
 		})
 		Expect(err).To(HaveOccurred())
 	})
+
+	It("returns an error when the client is not authenticated", func() {
+		server = egress.NewQueryServer(spy, &spyAuthorizer{err: errors.New("unknown client")})
+
+		_, err := server.ContainerMetrics(context.TODO(), &v2.ContainerMetricRequest{
+			SourceId: "some-app",
+		})
+		Expect(grpc.Code(err)).To(Equal(codes.Unauthenticated))
+	})
+
+	It("returns an error when the client may not read the source id", func() {
+		perms, err := auth.NewPermissions([]string{"other-app"}, nil, false)
+		Expect(err).ToNot(HaveOccurred())
+		server = egress.NewQueryServer(spy, &spyAuthorizer{perms: perms})
+
+		_, err = server.ContainerMetrics(context.TODO(), &v2.ContainerMetricRequest{
+			SourceId: "some-app",
+		})
+		Expect(grpc.Code(err)).To(Equal(codes.PermissionDenied))
+		Expect(spy.appID).To(BeEmpty())
+	})
 })
 
 type spyContainerMetricFetcher struct {
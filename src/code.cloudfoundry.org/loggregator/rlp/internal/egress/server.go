@@ -5,12 +5,16 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"time"
 
 	"code.cloudfoundry.org/loggregator/metricemitter"
 
 	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+	"code.cloudfoundry.org/loggregator/rlp/internal/auth"
 
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 )
 
 type HealthRegistrar interface {
@@ -22,21 +26,30 @@ type Receiver interface {
 	Receive(ctx context.Context, req *v2.EgressRequest) (rx func() (*v2.Envelope, error), err error)
 }
 
+// Authorizer returns the permissions of the client making a request.
+type Authorizer interface {
+	Authorize(ctx context.Context) (auth.Permissions, error)
+}
+
 // MetricClient creates new CounterMetrics to be emitted periodically.
 type MetricClient interface {
 	NewCounter(name string, opts ...metricemitter.MetricOption) *metricemitter.Counter
+	NewTimer(name string, opts ...metricemitter.MetricOption) *metricemitter.Timer
 }
 
 type Server struct {
 	receiver      Receiver
+	authorizer    Authorizer
 	egressMetric  *metricemitter.Counter
 	droppedMetric *metricemitter.Counter
+	latencyMetric *metricemitter.Timer
 	health        HealthRegistrar
 	ctx           context.Context
 }
 
 func NewServer(
 	r Receiver,
+	a Authorizer,
 	m MetricClient,
 	h HealthRegistrar,
 	c context.Context,
@@ -52,10 +65,16 @@ func NewServer(
 		}),
 	)
 
+	latencyMetric := m.NewTimer("egress_latency",
+		metricemitter.WithVersion(2, 0),
+	)
+
 	return &Server{
 		receiver:      r,
+		authorizer:    a,
 		egressMetric:  egressMetric,
 		droppedMetric: droppedMetric,
+		latencyMetric: latencyMetric,
 		health:        h,
 		ctx:           c,
 	}
@@ -71,6 +90,16 @@ func (s *Server) Receiver(r *v2.EgressRequest, srv v2.Egress_ReceiverServer) err
 		return errors.New("invalid request: cannot have type filter without source id")
 	}
 
+	perms, err := s.authorizer.Authorize(srv.Context())
+	if err != nil {
+		log.Printf("Unauthenticated subscription: %s", err)
+		return grpc.Errorf(codes.Unauthenticated, "unauthenticated")
+	}
+
+	if err := perms.CheckRequest(r); err != nil {
+		return grpc.Errorf(codes.PermissionDenied, "%s", err)
+	}
+
 	ctx, cancel := context.WithCancel(srv.Context())
 	defer cancel()
 
@@ -91,14 +120,20 @@ func (s *Server) Receiver(r *v2.EgressRequest, srv v2.Egress_ReceiverServer) err
 		return fmt.Errorf("unable to setup subscription")
 	}
 
-	go s.consumeReceiver(buffer, rx, cancel)
+	go s.consumeReceiver(buffer, rx, perms, cancel)
 
 	for data := range buffer {
+		start := time.Now()
 		if err := srv.Send(data); err != nil {
 			log.Printf("Send error: %s", err)
 			return io.ErrUnexpectedEOF
 		}
 
+		// metric-documentation-v2: (loggregator.rlp.egress_latency) Count,
+		// sum and quantiles of the time in ms to send a v2 envelope to an
+		// RLP consumer.
+		s.latencyMetric.ObserveSince(start)
+
 		// metric-documentation-v2: (loggregator.rlp.egress) Number of v2
 		// envelopes sent to RLP consumers.
 		s.egressMetric.Increment(1)
@@ -117,6 +152,7 @@ func (s *Server) Alert(missed int) {
 func (s *Server) consumeReceiver(
 	buffer chan<- *v2.Envelope,
 	rx func() (*v2.Envelope, error),
+	perms auth.Permissions,
 	cancel func(),
 ) {
 
@@ -134,6 +170,10 @@ func (s *Server) consumeReceiver(
 			break
 		}
 
+		if !perms.Allows(e) {
+			continue
+		}
+
 		select {
 		case buffer <- e:
 		default:
@@ -8,9 +8,11 @@ import (
 	"time"
 
 	"code.cloudfoundry.org/loggregator/metricemitter/testhelper"
+	"code.cloudfoundry.org/loggregator/rlp/internal/auth"
 	"code.cloudfoundry.org/loggregator/rlp/internal/egress"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 
 	"golang.org/x/net/context"
 
@@ -45,7 +47,7 @@ var _ = Describe("Server", func() {
 			}
 			receiverServer = &spyReceiverServer{}
 			receiver = newSpyReceiver(0)
-			server = egress.NewServer(receiver, metricClient, newSpyHealthRegistrar(), context.TODO())
+			server = egress.NewServer(receiver, auth.AllowAll{}, metricClient, newSpyHealthRegistrar(), context.TODO())
 
 			err := server.Receiver(req, receiverServer)
 			Expect(err).To(MatchError("invalid request: cannot have type filter without source id"))
@@ -55,7 +57,7 @@ var _ = Describe("Server", func() {
 			receiverServer = &spyReceiverServer{err: errors.New("Oh No!")}
 			receiver = newSpyReceiver(1)
 
-			server = egress.NewServer(receiver, metricClient, newSpyHealthRegistrar(), context.TODO())
+			server = egress.NewServer(receiver, auth.AllowAll{}, metricClient, newSpyHealthRegistrar(), context.TODO())
 			err := server.Receiver(&v2.EgressRequest{}, receiverServer)
 			Expect(err).To(Equal(io.ErrUnexpectedEOF))
 		})
@@ -64,7 +66,7 @@ var _ = Describe("Server", func() {
 			receiverServer = &spyReceiverServer{}
 			receiver = newSpyReceiver(10)
 
-			server = egress.NewServer(receiver, metricClient, newSpyHealthRegistrar(), context.TODO())
+			server = egress.NewServer(receiver, auth.AllowAll{}, metricClient, newSpyHealthRegistrar(), context.TODO())
 			server.Receiver(&v2.EgressRequest{}, receiverServer)
 
 			Eventually(receiverServer.EnvelopeCount).Should(Equal(int64(10)))
@@ -75,7 +77,7 @@ var _ = Describe("Server", func() {
 			receiver = newSpyReceiver(1000000000)
 
 			ctx, cancel := context.WithCancel(context.TODO())
-			server = egress.NewServer(receiver, metricClient, newSpyHealthRegistrar(), ctx)
+			server = egress.NewServer(receiver, auth.AllowAll{}, metricClient, newSpyHealthRegistrar(), ctx)
 			go server.Receiver(&v2.EgressRequest{}, receiverServer)
 
 			cancel()
@@ -91,7 +93,7 @@ var _ = Describe("Server", func() {
 			}
 			receiver = newSpyReceiver(100000000)
 
-			server = egress.NewServer(receiver, metricClient, newSpyHealthRegistrar(), context.TODO())
+			server = egress.NewServer(receiver, auth.AllowAll{}, metricClient, newSpyHealthRegistrar(), context.TODO())
 			go server.Receiver(&v2.EgressRequest{}, receiverServer)
 
 			var ctx context.Context
@@ -99,12 +101,65 @@ var _ = Describe("Server", func() {
 			Eventually(ctx.Done()).Should(BeClosed())
 		})
 
+		Describe("authorization", func() {
+			It("returns an error when the client is not authenticated", func() {
+				receiverServer = &spyReceiverServer{}
+				receiver = newSpyReceiver(10)
+				authorizer := &spyAuthorizer{err: errors.New("unknown client")}
+
+				server = egress.NewServer(receiver, authorizer, metricClient, newSpyHealthRegistrar(), context.TODO())
+				err := server.Receiver(&v2.EgressRequest{}, receiverServer)
+
+				Expect(grpc.Code(err)).To(Equal(codes.Unauthenticated))
+				Expect(receiver.ctx).ToNot(Receive())
+			})
+
+			It("returns an error when the client may not read the firehose", func() {
+				receiverServer = &spyReceiverServer{}
+				receiver = newSpyReceiver(10)
+				perms, err := auth.NewPermissions([]string{"app-1"}, nil, false)
+				Expect(err).ToNot(HaveOccurred())
+
+				server = egress.NewServer(receiver, &spyAuthorizer{perms: perms}, metricClient, newSpyHealthRegistrar(), context.TODO())
+				err = server.Receiver(&v2.EgressRequest{}, receiverServer)
+
+				Expect(grpc.Code(err)).To(Equal(codes.PermissionDenied))
+			})
+
+			It("only sends envelopes of allowed types", func() {
+				receiverServer = &spyReceiverServer{}
+				receiver = newSpyReceiver(10)
+				receiver.envelope = &v2.Envelope{
+					SourceId: "app-1",
+					Message:  &v2.Envelope_Gauge{Gauge: &v2.Gauge{}},
+				}
+				perms, err := auth.NewPermissions(nil, []string{"log"}, true)
+				Expect(err).ToNot(HaveOccurred())
+
+				server = egress.NewServer(receiver, &spyAuthorizer{perms: perms}, metricClient, newSpyHealthRegistrar(), context.TODO())
+				server.Receiver(&v2.EgressRequest{}, receiverServer)
+
+				Expect(receiverServer.EnvelopeCount()).To(BeZero())
+			})
+
+			It("passes the stream context to the authorizer", func() {
+				receiverServer = &spyReceiverServer{}
+				receiver = newSpyReceiver(1)
+				authorizer := &spyAuthorizer{perms: auth.Unrestricted}
+
+				server = egress.NewServer(receiver, authorizer, metricClient, newSpyHealthRegistrar(), context.TODO())
+				server.Receiver(&v2.EgressRequest{}, receiverServer)
+
+				Expect(authorizer.ctx).To(Equal(receiverServer.Context()))
+			})
+		})
+
 		Describe("Metrics", func() {
 			It("emits 'egress' metric for each envelope", func() {
 				receiverServer = &spyReceiverServer{}
 				receiver = newSpyReceiver(10)
 
-				server = egress.NewServer(receiver, metricClient, newSpyHealthRegistrar(), context.TODO())
+				server = egress.NewServer(receiver, auth.AllowAll{}, metricClient, newSpyHealthRegistrar(), context.TODO())
 				server.Receiver(&v2.EgressRequest{}, receiverServer)
 
 				Eventually(func() uint64 {
@@ -112,13 +167,25 @@ var _ = Describe("Server", func() {
 				}).Should(BeNumerically("==", 10))
 			})
 
+			It("emits 'egress_latency' metric for each envelope", func() {
+				receiverServer = &spyReceiverServer{}
+				receiver = newSpyReceiver(10)
+
+				server = egress.NewServer(receiver, auth.AllowAll{}, metricClient, newSpyHealthRegistrar(), context.TODO())
+				server.Receiver(&v2.EgressRequest{}, receiverServer)
+
+				Eventually(func() uint64 {
+					return metricClient.GetCount("egress_latency")
+				}).Should(BeNumerically("==", 10))
+			})
+
 			It("emits 'dropped' metric for each envelope", func() {
 				receiverServer = &spyReceiverServer{
 					sendDelay: time.Hour,
 				}
 				receiver = newSpyReceiver(1000000)
 
-				server = egress.NewServer(receiver, metricClient, newSpyHealthRegistrar(), context.TODO())
+				server = egress.NewServer(receiver, auth.AllowAll{}, metricClient, newSpyHealthRegistrar(), context.TODO())
 				go server.Receiver(&v2.EgressRequest{}, receiverServer)
 
 				Eventually(func() uint64 {
@@ -133,7 +200,7 @@ var _ = Describe("Server", func() {
 				receiver = newSpyReceiver(1000000000)
 
 				health := newSpyHealthRegistrar()
-				server = egress.NewServer(receiver, metricClient, health, context.TODO())
+				server = egress.NewServer(receiver, auth.AllowAll{}, metricClient, health, context.TODO())
 				go server.Receiver(&v2.EgressRequest{}, receiverServer)
 
 				Eventually(func() float64 {
@@ -150,6 +217,17 @@ var _ = Describe("Server", func() {
 	})
 })
 
+type spyAuthorizer struct {
+	ctx   context.Context
+	perms auth.Permissions
+	err   error
+}
+
+func (s *spyAuthorizer) Authorize(ctx context.Context) (auth.Permissions, error) {
+	s.ctx = ctx
+	return s.perms, s.err
+}
+
 type spyReceiverServer struct {
 	err           error
 	envelopeCount int64
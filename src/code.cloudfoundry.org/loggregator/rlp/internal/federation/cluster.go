@@ -0,0 +1,197 @@
+package federation
+
+import (
+	"code.cloudfoundry.org/loggregator/dopplerservice"
+	"code.cloudfoundry.org/loggregator/metricemitter"
+	"code.cloudfoundry.org/loggregator/plumbing"
+	"code.cloudfoundry.org/loggregator/rlp/internal/ingress"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+)
+
+// MetricClient creates the ingress, error and Doppler count metrics of a
+// federated cluster. They are tagged with the name of the cluster.
+type MetricClient interface {
+	NewCounter(name string, opts ...metricemitter.MetricOption) *metricemitter.Counter
+	NewGauge(name, unit string, opts ...metricemitter.MetricOption) *metricemitter.Gauge
+}
+
+// HealthRegistrar sets the health metrics of a cluster.
+type HealthRegistrar interface {
+	Set(name string, value float64)
+}
+
+// Cluster is the Doppler pool of a single foundation. Its envelopes are
+// tagged with the name of the foundation.
+type Cluster struct {
+	name       string
+	receiver   EgressReceiver
+	querier    ContainerMetricFetcher
+	subscriber Subscriber
+	stop       func()
+
+	ingressMetric *metricemitter.Counter
+	errMetric     *metricemitter.Counter
+}
+
+// NewCluster returns a Cluster for the given foundation that receives from
+// the given receivers.
+func NewCluster(
+	name string,
+	r EgressReceiver,
+	q ContainerMetricFetcher,
+	s Subscriber,
+	m MetricClient,
+) *Cluster {
+	tags := foundationTag(name)
+
+	// metric-documentation-v2: (loggregator.rlp.federation.ingress) Number
+	// of envelopes received from the Dopplers of a foundation.
+	ingressMetric := m.NewCounter("federation.ingress",
+		metricemitter.WithVersion(2, 0),
+		tags,
+	)
+
+	// metric-documentation-v2: (loggregator.rlp.federation.errors) Number
+	// of errors subscribing to or receiving from the Dopplers of a
+	// foundation.
+	errMetric := m.NewCounter("federation.errors",
+		metricemitter.WithVersion(2, 0),
+		tags,
+	)
+
+	return &Cluster{
+		name:          name,
+		receiver:      r,
+		querier:       q,
+		subscriber:    s,
+		stop:          func() {},
+		ingressMetric: ingressMetric,
+		errMetric:     errMetric,
+	}
+}
+
+// Dial returns a Cluster that connects to the Dopplers of the foundation
+// with the given dial options.
+func Dial(
+	f Foundation,
+	m MetricClient,
+	h HealthRegistrar,
+	opts ...grpc.DialOption,
+) *Cluster {
+	tags := foundationTag(f.Name)
+
+	// metric-documentation-v2: (loggregator.rlp.federation.dopplers) Number
+	// of Dopplers of a foundation.
+	dopplersMetric := m.NewGauge("federation.dopplers", "dopplers",
+		metricemitter.WithVersion(2, 0),
+		tags,
+	)
+
+	finder := plumbing.NewStaticFinder(f.Addrs)
+	pool := plumbing.NewPool(20, opts...)
+	connector := plumbing.NewGRPCConnector(
+		1000,
+		pool,
+		&countingFinder{
+			finder:     finder,
+			gauge:      dopplersMetric,
+			health:     h,
+			healthName: healthName(f.Name),
+		},
+		&ingress.NullMetricBatcher{},
+		taggedMetricClient{m: m, tags: tags},
+	)
+	converter := ingress.NewConverter()
+
+	c := NewCluster(
+		f.Name,
+		ingress.NewReceiver(converter, ingress.NewRequestConverter(), connector),
+		ingress.NewQuerier(converter, connector),
+		connector,
+		m,
+	)
+	c.stop = func() {
+		finder.Stop()
+
+		for _, addr := range f.Addrs {
+			pool.Close(addr)
+		}
+	}
+
+	return c
+}
+
+// Name returns the name of the foundation.
+func (c *Cluster) Name() string {
+	return c.name
+}
+
+// Stop stops reconnecting to the Dopplers and closes the connections.
+func (c *Cluster) Stop() {
+	c.stop()
+}
+
+// HealthGauges returns the health gauges of the given foundations. They
+// have to be registered with the health endpoint before clusters are
+// created.
+func HealthGauges(foundations []Foundation) map[string]prometheus.Gauge {
+	gauges := make(map[string]prometheus.Gauge, len(foundations))
+	for _, f := range foundations {
+		// metric-documentation-health: (federatedDopplerCount)
+		// Number of Dopplers of a foundation
+		gauges[healthName(f.Name)] = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: "loggregator",
+				Subsystem: "reverseLogProxy",
+				Name:      "federatedDopplerCount",
+				Help:      "Number of Dopplers of a foundation",
+				ConstLabels: prometheus.Labels{
+					"foundation": f.Name,
+				},
+			},
+		)
+	}
+
+	return gauges
+}
+
+func foundationTag(foundation string) metricemitter.MetricOption {
+	return metricemitter.WithTags(map[string]string{
+		"foundation": foundation,
+	})
+}
+
+func healthName(foundation string) string {
+	return "federatedDopplerCount." + foundation
+}
+
+// countingFinder records the number of Dopplers of each event.
+type countingFinder struct {
+	finder     plumbing.Finder
+	gauge      *metricemitter.Gauge
+	health     HealthRegistrar
+	healthName string
+}
+
+func (f *countingFinder) Next() dopplerservice.Event {
+	e := f.finder.Next()
+
+	n := float64(len(e.GRPCDopplers))
+	f.gauge.Set(n)
+	f.health.Set(f.healthName, n)
+
+	return e
+}
+
+// taggedMetricClient adds the foundation tag to the metrics of the
+// connector of a cluster.
+type taggedMetricClient struct {
+	m    MetricClient
+	tags metricemitter.MetricOption
+}
+
+func (c taggedMetricClient) NewCounter(name string, opts ...metricemitter.MetricOption) *metricemitter.Counter {
+	return c.m.NewCounter(name, append(opts, c.tags)...)
+}
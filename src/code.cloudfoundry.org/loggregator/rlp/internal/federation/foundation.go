@@ -0,0 +1,82 @@
+package federation
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"code.cloudfoundry.org/loggregator/plumbing"
+)
+
+// Foundation configures a Doppler pool of a foundation. Certificates and
+// keys are PEM encoded.
+type Foundation struct {
+	Name       string   `json:"name"`
+	Addrs      []string `json:"addrs"`
+	CACert     string   `json:"ca_cert"`
+	Cert       string   `json:"cert"`
+	Key        string   `json:"key"`
+	ServerName string   `json:"server_name"`
+}
+
+// LoadFoundations reads a JSON list of foundations from the given file.
+// Every foundation needs a unique name and at least one address.
+func LoadFoundations(path string) ([]Foundation, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var foundations []Foundation
+	if err := json.NewDecoder(f).Decode(&foundations); err != nil {
+		return nil, fmt.Errorf("invalid federation config %s: %s", path, err)
+	}
+
+	names := make(map[string]bool)
+	for _, fd := range foundations {
+		if fd.Name == "" {
+			return nil, errors.New("every foundation requires a name")
+		}
+
+		if names[fd.Name] {
+			return nil, fmt.Errorf("duplicate foundation %s", fd.Name)
+		}
+		names[fd.Name] = true
+
+		if len(fd.Addrs) == 0 {
+			return nil, fmt.Errorf("foundation %s has no Doppler addresses", fd.Name)
+		}
+	}
+
+	return foundations, nil
+}
+
+// TLSConfig returns the mutual TLS config used to connect to the Dopplers of
+// the foundation. The server name defaults to doppler.
+func (f Foundation) TLSConfig() (*tls.Config, error) {
+	cert, err := tls.X509KeyPair([]byte(f.Cert), []byte(f.Key))
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate of foundation %s: %s", f.Name, err)
+	}
+
+	caCertPool := x509.NewCertPool()
+	if !caCertPool.AppendCertsFromPEM([]byte(f.CACert)) {
+		return nil, fmt.Errorf("invalid CA certificate of foundation %s", f.Name)
+	}
+
+	serverName := f.ServerName
+	if serverName == "" {
+		serverName = "doppler"
+	}
+
+	tlsConfig := plumbing.NewTLSConfig()
+	tlsConfig.Certificates = []tls.Certificate{cert}
+	tlsConfig.RootCAs = caCertPool
+	tlsConfig.ServerName = serverName
+
+	return tlsConfig, nil
+}
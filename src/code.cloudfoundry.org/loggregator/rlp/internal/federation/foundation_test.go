@@ -0,0 +1,92 @@
+package federation_test
+
+import (
+	"io/ioutil"
+	"os"
+
+	"code.cloudfoundry.org/loggregator/rlp/internal/federation"
+	"code.cloudfoundry.org/loggregator/testservers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Foundation", func() {
+	Describe("LoadFoundations", func() {
+		It("reads foundations from a JSON file", func() {
+			path := writeConfig(`[
+				{"name": "east", "addrs": ["10.0.0.1:8082", "10.0.0.2:8082"], "server_name": "doppler-east"},
+				{"name": "west", "addrs": ["10.1.0.1:8082"]}
+			]`)
+			defer os.Remove(path)
+
+			foundations, err := federation.LoadFoundations(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(foundations).To(Equal([]federation.Foundation{
+				{
+					Name:       "east",
+					Addrs:      []string{"10.0.0.1:8082", "10.0.0.2:8082"},
+					ServerName: "doppler-east",
+				},
+				{
+					Name:  "west",
+					Addrs: []string{"10.1.0.1:8082"},
+				},
+			}))
+		})
+
+		DescribeTable("rejects invalid foundations", func(config string) {
+			path := writeConfig(config)
+			defer os.Remove(path)
+
+			_, err := federation.LoadFoundations(path)
+			Expect(err).To(HaveOccurred())
+		},
+			Entry("invalid JSON", `{`),
+			Entry("missing name", `[{"addrs": ["10.0.0.1:8082"]}]`),
+			Entry("duplicate name", `[{"name": "east", "addrs": ["a"]}, {"name": "east", "addrs": ["b"]}]`),
+			Entry("missing addrs", `[{"name": "east"}]`),
+		)
+	})
+
+	Describe("TLSConfig", func() {
+		It("uses the certificates of the foundation", func() {
+			f := federation.Foundation{
+				Name:   "east",
+				CACert: string(testservers.MustAsset("loggregator-ca.crt")),
+				Cert:   string(testservers.MustAsset("reverselogproxy.crt")),
+				Key:    string(testservers.MustAsset("reverselogproxy.key")),
+			}
+
+			tlsConfig, err := f.TLSConfig()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tlsConfig.Certificates).To(HaveLen(1))
+			Expect(tlsConfig.RootCAs).ToNot(BeNil())
+			Expect(tlsConfig.ServerName).To(Equal("doppler"))
+		})
+
+		It("returns an error for invalid certificates", func() {
+			f := federation.Foundation{
+				Name:   "east",
+				CACert: "invalid",
+				Cert:   "invalid",
+				Key:    "invalid",
+			}
+
+			_, err := f.TLSConfig()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+func writeConfig(config string) string {
+	f, err := ioutil.TempFile("", "federation")
+	Expect(err).ToNot(HaveOccurred())
+	defer f.Close()
+
+	_, err = f.WriteString(config)
+	Expect(err).ToNot(HaveOccurred())
+
+	return f.Name()
+}
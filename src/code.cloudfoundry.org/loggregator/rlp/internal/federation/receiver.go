@@ -0,0 +1,228 @@
+package federation
+
+import (
+	"errors"
+	"log"
+
+	"code.cloudfoundry.org/loggregator/plumbing"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+
+	"golang.org/x/net/context"
+)
+
+// Tag is the tag that holds the name of the foundation of an envelope.
+const Tag = "foundation"
+
+type EgressReceiver interface {
+	Receive(ctx context.Context, req *v2.EgressRequest) (rx func() (*v2.Envelope, error), err error)
+}
+
+type ContainerMetricFetcher interface {
+	ContainerMetrics(ctx context.Context, sourceId string, usePreferredTags bool) ([]*v2.Envelope, error)
+}
+
+type Subscriber interface {
+	Subscribe(ctx context.Context, req *plumbing.SubscriptionRequest) (recv func() ([]byte, error), err error)
+}
+
+// Receiver merges the envelopes of every cluster into a single
+// subscription. Envelopes are tagged with the foundation they come from.
+type Receiver struct {
+	clusters   []*Cluster
+	bufferSize int
+}
+
+func NewReceiver(clusters []*Cluster, bufferSize int) *Receiver {
+	return &Receiver{
+		clusters:   clusters,
+		bufferSize: bufferSize,
+	}
+}
+
+// Receive subscribes to every cluster. It only fails if no cluster accepts
+// the subscription. Errors of a single cluster do not end the subscription.
+func (r *Receiver) Receive(ctx context.Context, req *v2.EgressRequest) (rx func() (*v2.Envelope, error), err error) {
+	envs := make(chan *v2.Envelope, r.bufferSize)
+
+	var subscribed int
+	for _, c := range r.clusters {
+		crx, err := c.receiver.Receive(ctx, req)
+		if err != nil {
+			log.Printf("failed to subscribe to foundation %s: %s", c.name, err)
+			c.errMetric.Increment(1)
+			continue
+		}
+		subscribed++
+
+		go forward(ctx, c, crx, req.GetUsePreferredTags(), envs)
+	}
+
+	if subscribed == 0 {
+		return nil, errors.New("failed to subscribe to any foundation")
+	}
+
+	return func() (*v2.Envelope, error) {
+		select {
+		case e := <-envs:
+			return e, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}, nil
+}
+
+func forward(
+	ctx context.Context,
+	c *Cluster,
+	rx func() (*v2.Envelope, error),
+	usePreferredTags bool,
+	envs chan<- *v2.Envelope,
+) {
+	for {
+		e, err := rx()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			c.errMetric.Increment(1)
+			continue
+		}
+		c.ingressMetric.Increment(1)
+
+		addTag(e, c.name, usePreferredTags)
+
+		select {
+		case envs <- e:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Querier queries the container metrics of every cluster.
+type Querier struct {
+	clusters []*Cluster
+}
+
+func NewQuerier(clusters []*Cluster) *Querier {
+	return &Querier{
+		clusters: clusters,
+	}
+}
+
+// ContainerMetrics returns the container metrics of every cluster tagged
+// with their foundation. Clusters that fail are skipped.
+func (q *Querier) ContainerMetrics(ctx context.Context, sourceID string, usePreferredTags bool) ([]*v2.Envelope, error) {
+	results := make(chan []*v2.Envelope, len(q.clusters))
+
+	for _, c := range q.clusters {
+		go func(c *Cluster) {
+			envs, err := c.querier.ContainerMetrics(ctx, sourceID, usePreferredTags)
+			if err != nil {
+				log.Printf("failed to query container metrics of foundation %s: %s", c.name, err)
+				c.errMetric.Increment(1)
+				results <- nil
+				return
+			}
+
+			for _, e := range envs {
+				addTag(e, c.name, usePreferredTags)
+			}
+			results <- envs
+		}(c)
+	}
+
+	var envs []*v2.Envelope
+	for range q.clusters {
+		envs = append(envs, <-results...)
+	}
+
+	return envs, nil
+}
+
+// MultiSubscriber merges the v1 envelopes of every cluster. Unlike the
+// Receiver it cannot tag envelopes with their foundation.
+type MultiSubscriber struct {
+	clusters   []*Cluster
+	bufferSize int
+}
+
+func NewMultiSubscriber(clusters []*Cluster, bufferSize int) *MultiSubscriber {
+	return &MultiSubscriber{
+		clusters:   clusters,
+		bufferSize: bufferSize,
+	}
+}
+
+// Subscribe subscribes to every cluster. It only fails if no cluster
+// accepts the subscription.
+func (s *MultiSubscriber) Subscribe(ctx context.Context, req *plumbing.SubscriptionRequest) (recv func() ([]byte, error), err error) {
+	data := make(chan []byte, s.bufferSize)
+
+	var subscribed int
+	for _, c := range s.clusters {
+		crecv, err := c.subscriber.Subscribe(ctx, req)
+		if err != nil {
+			log.Printf("failed to subscribe to foundation %s: %s", c.name, err)
+			c.errMetric.Increment(1)
+			continue
+		}
+
+		subscribed++
+
+		go func(c *Cluster, recv func() ([]byte, error)) {
+			for {
+				d, err := recv()
+				if err != nil {
+					if ctx.Err() != nil {
+						return
+					}
+
+					c.errMetric.Increment(1)
+					continue
+				}
+
+				select {
+				case data <- d:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(c, crecv)
+	}
+
+	if subscribed == 0 {
+		return nil, errors.New("failed to subscribe to any foundation")
+	}
+
+	return func() ([]byte, error) {
+		select {
+		case d := <-data:
+			return d, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}, nil
+}
+
+func addTag(e *v2.Envelope, foundation string, usePreferredTags bool) {
+	if usePreferredTags {
+		if e.Tags == nil {
+			e.Tags = make(map[string]string)
+		}
+
+		e.Tags[Tag] = foundation
+		return
+	}
+
+	if e.DeprecatedTags == nil {
+		e.DeprecatedTags = make(map[string]*v2.Value)
+	}
+
+	e.DeprecatedTags[Tag] = &v2.Value{
+		Data: &v2.Value_Text{
+			Text: foundation,
+		},
+	}
+}
@@ -0,0 +1,290 @@
+package federation_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/loggregator/metricemitter/testhelper"
+	"code.cloudfoundry.org/loggregator/plumbing"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+	"code.cloudfoundry.org/loggregator/rlp/internal/federation"
+
+	"golang.org/x/net/context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Receiver", func() {
+	var (
+		east, west   *spyCluster
+		metricClient *testhelper.SpyMetricClient
+		clusters     []*federation.Cluster
+		ctx          context.Context
+		cancel       func()
+	)
+
+	BeforeEach(func() {
+		east = newSpyCluster()
+		west = newSpyCluster()
+		metricClient = testhelper.NewMetricClient()
+		clusters = []*federation.Cluster{
+			federation.NewCluster("east", east, east, east, metricClient),
+			federation.NewCluster("west", west, west, west, metricClient),
+		}
+		ctx, cancel = context.WithCancel(context.Background())
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("merges the envelopes of every cluster", func() {
+		receiver := federation.NewReceiver(clusters, 10)
+		req := &v2.EgressRequest{ShardId: "some-shard"}
+
+		rx, err := receiver.Receive(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(east.request()).To(Equal(req))
+		Expect(west.request()).To(Equal(req))
+
+		east.envelopes <- &v2.Envelope{SourceId: "app-1"}
+		west.envelopes <- &v2.Envelope{SourceId: "app-2"}
+
+		var sourceIDs []string
+		for i := 0; i < 2; i++ {
+			e, err := rx()
+			Expect(err).ToNot(HaveOccurred())
+			sourceIDs = append(sourceIDs, e.SourceId)
+		}
+		Expect(sourceIDs).To(ConsistOf("app-1", "app-2"))
+	})
+
+	It("tags envelopes with their foundation", func() {
+		receiver := federation.NewReceiver(clusters, 10)
+
+		rx, err := receiver.Receive(ctx, &v2.EgressRequest{UsePreferredTags: true})
+		Expect(err).ToNot(HaveOccurred())
+
+		east.envelopes <- &v2.Envelope{SourceId: "app-1"}
+		e, err := rx()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(e.Tags).To(HaveKeyWithValue("foundation", "east"))
+		Expect(e.DeprecatedTags).To(BeEmpty())
+	})
+
+	It("tags envelopes with deprecated tags when preferred tags are not requested", func() {
+		receiver := federation.NewReceiver(clusters, 10)
+
+		rx, err := receiver.Receive(ctx, &v2.EgressRequest{})
+		Expect(err).ToNot(HaveOccurred())
+
+		west.envelopes <- &v2.Envelope{SourceId: "app-1"}
+		e, err := rx()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(e.DeprecatedTags["foundation"].GetText()).To(Equal("west"))
+		Expect(e.Tags).To(BeEmpty())
+	})
+
+	It("counts envelopes per foundation", func() {
+		receiver := federation.NewReceiver(clusters, 10)
+
+		rx, err := receiver.Receive(ctx, &v2.EgressRequest{})
+		Expect(err).ToNot(HaveOccurred())
+
+		east.envelopes <- &v2.Envelope{}
+		east.envelopes <- &v2.Envelope{}
+		west.envelopes <- &v2.Envelope{}
+		for i := 0; i < 3; i++ {
+			_, err := rx()
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		Expect(ingressCounts(metricClient)).To(Equal(map[string]uint64{
+			"east": 2,
+			"west": 1,
+		}))
+	})
+
+	It("keeps receiving from other clusters when one fails to subscribe", func() {
+		east.subscribeErr = errors.New("some-error")
+		receiver := federation.NewReceiver(clusters, 10)
+
+		rx, err := receiver.Receive(ctx, &v2.EgressRequest{})
+		Expect(err).ToNot(HaveOccurred())
+
+		west.envelopes <- &v2.Envelope{SourceId: "app-1"}
+		e, err := rx()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(e.SourceId).To(Equal("app-1"))
+		Expect(errorCount(metricClient, "east")).To(Equal(uint64(1)))
+	})
+
+	It("keeps receiving from a cluster after an error", func() {
+		receiver := federation.NewReceiver(clusters, 10)
+
+		rx, err := receiver.Receive(ctx, &v2.EgressRequest{})
+		Expect(err).ToNot(HaveOccurred())
+
+		east.errs <- errors.New("some-error")
+		east.envelopes <- &v2.Envelope{SourceId: "app-1"}
+		e, err := rx()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(e.SourceId).To(Equal("app-1"))
+		Expect(errorCount(metricClient, "east")).To(Equal(uint64(1)))
+	})
+
+	It("fails when no cluster accepts the subscription", func() {
+		east.subscribeErr = errors.New("some-error")
+		west.subscribeErr = errors.New("some-error")
+		receiver := federation.NewReceiver(clusters, 10)
+
+		_, err := receiver.Receive(ctx, &v2.EgressRequest{})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("returns an error once the context is done", func() {
+		receiver := federation.NewReceiver(clusters, 10)
+
+		rx, err := receiver.Receive(ctx, &v2.EgressRequest{})
+		Expect(err).ToNot(HaveOccurred())
+
+		cancel()
+		_, err = rx()
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Querier", func() {
+	It("returns the container metrics of every cluster", func() {
+		east := newSpyCluster()
+		east.containerMetrics = []*v2.Envelope{{SourceId: "app-1"}}
+		west := newSpyCluster()
+		west.containerMetrics = []*v2.Envelope{{SourceId: "app-1"}}
+		broken := newSpyCluster()
+		broken.queryErr = errors.New("some-error")
+
+		metricClient := testhelper.NewMetricClient()
+		querier := federation.NewQuerier([]*federation.Cluster{
+			federation.NewCluster("east", east, east, east, metricClient),
+			federation.NewCluster("west", west, west, west, metricClient),
+			federation.NewCluster("broken", broken, broken, broken, metricClient),
+		})
+
+		envs, err := querier.ContainerMetrics(context.Background(), "app-1", true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(envs).To(HaveLen(2))
+
+		var foundations []string
+		for _, e := range envs {
+			foundations = append(foundations, e.Tags["foundation"])
+		}
+		Expect(foundations).To(ConsistOf("east", "west"))
+		Expect(errorCount(metricClient, "broken")).To(Equal(uint64(1)))
+	})
+})
+
+var _ = Describe("MultiSubscriber", func() {
+	It("merges the v1 envelopes of every cluster", func() {
+		east := newSpyCluster()
+		west := newSpyCluster()
+		metricClient := testhelper.NewMetricClient()
+		subscriber := federation.NewMultiSubscriber([]*federation.Cluster{
+			federation.NewCluster("east", east, east, east, metricClient),
+			federation.NewCluster("west", west, west, west, metricClient),
+		}, 10)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		recv, err := subscriber.Subscribe(ctx, &plumbing.SubscriptionRequest{ShardID: "some-shard"})
+		Expect(err).ToNot(HaveOccurred())
+
+		east.data <- []byte("east")
+		west.data <- []byte("west")
+
+		var data []string
+		for i := 0; i < 2; i++ {
+			d, err := recv()
+			Expect(err).ToNot(HaveOccurred())
+			data = append(data, string(d))
+		}
+		Expect(data).To(ConsistOf("east", "west"))
+	})
+})
+
+// Reading the envelopes of a counter resets it, so the counts of every
+// foundation are read at once.
+func ingressCounts(m *testhelper.SpyMetricClient) map[string]uint64 {
+	return countsFor(m, "federation.ingress")
+}
+
+func errorCount(m *testhelper.SpyMetricClient, foundation string) uint64 {
+	return countsFor(m, "federation.errors")[foundation]
+}
+
+func countsFor(m *testhelper.SpyMetricClient, name string) map[string]uint64 {
+	counts := make(map[string]uint64)
+	for _, e := range m.GetEnvelopes(name) {
+		counts[e.GetDeprecatedTags()["foundation"].GetText()] += e.GetCounter().GetDelta()
+	}
+	return counts
+}
+
+type spyCluster struct {
+	req          *v2.EgressRequest
+	subscribeErr error
+	envelopes    chan *v2.Envelope
+	errs         chan error
+	data         chan []byte
+
+	containerMetrics []*v2.Envelope
+	queryErr         error
+}
+
+func newSpyCluster() *spyCluster {
+	return &spyCluster{
+		envelopes: make(chan *v2.Envelope, 10),
+		errs:      make(chan error, 10),
+		data:      make(chan []byte, 10),
+	}
+}
+
+func (s *spyCluster) Receive(ctx context.Context, req *v2.EgressRequest) (func() (*v2.Envelope, error), error) {
+	s.req = req
+	if s.subscribeErr != nil {
+		return nil, s.subscribeErr
+	}
+
+	return func() (*v2.Envelope, error) {
+		select {
+		case err := <-s.errs:
+			return nil, err
+		case e := <-s.envelopes:
+			return e, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}, nil
+}
+
+func (s *spyCluster) request() *v2.EgressRequest {
+	return s.req
+}
+
+func (s *spyCluster) ContainerMetrics(ctx context.Context, sourceID string, usePreferredTags bool) ([]*v2.Envelope, error) {
+	return s.containerMetrics, s.queryErr
+}
+
+func (s *spyCluster) Subscribe(ctx context.Context, req *plumbing.SubscriptionRequest) (func() ([]byte, error), error) {
+	if s.subscribeErr != nil {
+		return nil, s.subscribeErr
+	}
+
+	return func() ([]byte, error) {
+		select {
+		case d := <-s.data:
+			return d, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}, nil
+}
@@ -0,0 +1,215 @@
+package httpmetrics
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+)
+
+// SourceID is the source ID of the envelopes with aggregated HTTP request
+// metrics.
+const SourceID = "http_metrics"
+
+var percentiles = []struct {
+	name  string
+	value float64
+}{
+	{"http.latency_p50", 0.50},
+	{"http.latency_p95", 0.95},
+	{"http.latency_p99", 0.99},
+}
+
+// Aggregator computes per app request counts by status class and latency
+// percentiles from the HTTP timers emitted by the router.
+type Aggregator struct {
+	maxSamples int
+
+	mu   sync.Mutex
+	apps map[string]*appStats
+}
+
+type appStats struct {
+	counts  map[string]uint64
+	samples []int64
+	seen    int64
+}
+
+// NewAggregator returns an Aggregator that keeps at most maxSamples request
+// durations per app and interval to compute latency percentiles. Beyond that
+// durations are sampled.
+func NewAggregator(maxSamples int) *Aggregator {
+	return &Aggregator{
+		maxSamples: maxSamples,
+		apps:       make(map[string]*appStats),
+	}
+}
+
+// Observe records the envelope if it is an HTTP timer emitted by the router.
+// Timers emitted by the app for the same request are ignored so requests are
+// not counted twice.
+func (a *Aggregator) Observe(e *v2.Envelope) {
+	t := e.GetTimer()
+	if t == nil || t.GetName() != "http" {
+		return
+	}
+
+	if peerType := tag(e, "peer_type"); peerType != "" && peerType != "Client" {
+		return
+	}
+
+	duration := t.GetStop() - t.GetStart()
+	if duration < 0 {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	s, ok := a.apps[e.GetSourceId()]
+	if !ok {
+		s = &appStats{
+			counts: make(map[string]uint64),
+		}
+		a.apps[e.GetSourceId()] = s
+	}
+
+	s.counts[statusClass(tag(e, "status_code"))]++
+	s.seen++
+
+	if len(s.samples) < a.maxSamples {
+		s.samples = append(s.samples, duration)
+		return
+	}
+
+	if i := rand.Int63n(s.seen); i < int64(a.maxSamples) {
+		s.samples[i] = duration
+	}
+}
+
+// Flush returns the metrics for the requests observed since the last flush
+// and resets them. Apps without requests in an interval are forgotten.
+func (a *Aggregator) Flush(interval time.Duration) []*v2.Envelope {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now().UnixNano()
+	appIDs := make([]string, 0, len(a.apps))
+	for id := range a.apps {
+		appIDs = append(appIDs, id)
+	}
+	sort.Strings(appIDs)
+
+	var envs []*v2.Envelope
+	for _, id := range appIDs {
+		s := a.apps[id]
+		if s.seen == 0 {
+			delete(a.apps, id)
+			continue
+		}
+
+		classes := make([]string, 0, len(s.counts))
+		for c := range s.counts {
+			classes = append(classes, c)
+		}
+		sort.Strings(classes)
+
+		var requests uint64
+		for _, c := range classes {
+			requests += s.counts[c]
+
+			envs = append(envs, &v2.Envelope{
+				SourceId:  SourceID,
+				Timestamp: now,
+				Tags: map[string]string{
+					"app_id":       id,
+					"status_class": c,
+				},
+				Message: &v2.Envelope_Counter{
+					Counter: &v2.Counter{
+						Name: "http.requests",
+						Value: &v2.Counter_Delta{
+							Delta: s.counts[c],
+						},
+					},
+				},
+			})
+		}
+
+		metrics := map[string]*v2.GaugeValue{
+			"http.requests_per_second": {
+				Unit:  "requests/second",
+				Value: float64(requests) / interval.Seconds(),
+			},
+		}
+
+		sort.Sort(int64s(s.samples))
+		for _, p := range percentiles {
+			metrics[p.name] = &v2.GaugeValue{
+				Unit:  "ms",
+				Value: float64(percentile(s.samples, p.value)) / float64(time.Millisecond),
+			}
+		}
+
+		envs = append(envs, &v2.Envelope{
+			SourceId:  SourceID,
+			Timestamp: now,
+			Tags: map[string]string{
+				"app_id": id,
+			},
+			Message: &v2.Envelope_Gauge{
+				Gauge: &v2.Gauge{
+					Metrics: metrics,
+				},
+			},
+		})
+
+		s.counts = make(map[string]uint64)
+		s.samples = s.samples[:0]
+		s.seen = 0
+	}
+
+	return envs
+}
+
+// percentile returns the nearest rank percentile of the sorted samples.
+func percentile(sorted []int64, p float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+
+	return sorted[i]
+}
+
+func statusClass(code string) string {
+	c, err := strconv.Atoi(code)
+	if err != nil || c < 100 || c > 599 {
+		return "unknown"
+	}
+
+	return fmt.Sprintf("%dxx", c/100)
+}
+
+func tag(e *v2.Envelope, key string) string {
+	if v, ok := e.GetTags()[key]; ok {
+		return v
+	}
+
+	return e.GetDeprecatedTags()[key].GetText()
+}
+
+type int64s []int64
+
+func (s int64s) Len() int           { return len(s) }
+func (s int64s) Less(i, j int) bool { return s[i] < s[j] }
+func (s int64s) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -0,0 +1,159 @@
+package httpmetrics_test
+
+import (
+	"time"
+
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+	"code.cloudfoundry.org/loggregator/rlp/internal/httpmetrics"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Aggregator", func() {
+	var aggregator *httpmetrics.Aggregator
+
+	BeforeEach(func() {
+		aggregator = httpmetrics.NewAggregator(1000)
+	})
+
+	It("counts requests by status class", func() {
+		aggregator.Observe(httpTimer("app-1", "200", "Client", time.Millisecond))
+		aggregator.Observe(httpTimer("app-1", "204", "Client", time.Millisecond))
+		aggregator.Observe(httpTimer("app-1", "503", "Client", time.Millisecond))
+		aggregator.Observe(httpTimer("app-2", "404", "Client", time.Millisecond))
+
+		envs := aggregator.Flush(time.Second)
+
+		Expect(counters(envs)).To(Equal(map[string]uint64{
+			"app-1/2xx": 2,
+			"app-1/5xx": 1,
+			"app-2/4xx": 1,
+		}))
+		for _, e := range envs {
+			Expect(e.GetSourceId()).To(Equal(httpmetrics.SourceID))
+		}
+	})
+
+	It("computes latency percentiles and request rate", func() {
+		for i := 1; i <= 100; i++ {
+			aggregator.Observe(httpTimer("app-1", "200", "Client", time.Duration(i)*time.Millisecond))
+		}
+
+		metrics := gauges(aggregator.Flush(10 * time.Second))["app-1"]
+
+		Expect(metrics["http.latency_p50"].GetValue()).To(Equal(50.0))
+		Expect(metrics["http.latency_p95"].GetValue()).To(Equal(95.0))
+		Expect(metrics["http.latency_p99"].GetValue()).To(Equal(99.0))
+		Expect(metrics["http.latency_p99"].GetUnit()).To(Equal("ms"))
+		Expect(metrics["http.requests_per_second"].GetValue()).To(Equal(10.0))
+	})
+
+	It("ignores timers emitted by apps", func() {
+		aggregator.Observe(httpTimer("app-1", "200", "Client", time.Millisecond))
+		aggregator.Observe(httpTimer("app-1", "200", "Server", time.Millisecond))
+
+		Expect(counters(aggregator.Flush(time.Second))).To(Equal(map[string]uint64{
+			"app-1/2xx": 1,
+		}))
+	})
+
+	It("ignores other envelopes", func() {
+		aggregator.Observe(&v2.Envelope{
+			SourceId: "app-1",
+			Message: &v2.Envelope_Timer{
+				Timer: &v2.Timer{Name: "other", Start: 1, Stop: 2},
+			},
+		})
+		aggregator.Observe(&v2.Envelope{
+			SourceId: "app-1",
+			Message: &v2.Envelope_Log{
+				Log: &v2.Log{Payload: []byte("hello")},
+			},
+		})
+
+		Expect(aggregator.Flush(time.Second)).To(BeEmpty())
+	})
+
+	It("reads deprecated tags", func() {
+		e := httpTimer("app-1", "", "", time.Millisecond)
+		e.Tags = nil
+		e.DeprecatedTags = map[string]*v2.Value{
+			"status_code": {Data: &v2.Value_Text{Text: "302"}},
+			"peer_type":   {Data: &v2.Value_Text{Text: "Client"}},
+		}
+		aggregator.Observe(e)
+
+		Expect(counters(aggregator.Flush(time.Second))).To(Equal(map[string]uint64{
+			"app-1/3xx": 1,
+		}))
+	})
+
+	It("counts invalid status codes as unknown", func() {
+		aggregator.Observe(httpTimer("app-1", "", "Client", time.Millisecond))
+
+		Expect(counters(aggregator.Flush(time.Second))).To(Equal(map[string]uint64{
+			"app-1/unknown": 1,
+		}))
+	})
+
+	It("resets after each flush", func() {
+		aggregator.Observe(httpTimer("app-1", "200", "Client", time.Millisecond))
+		Expect(aggregator.Flush(time.Second)).ToNot(BeEmpty())
+
+		Expect(aggregator.Flush(time.Second)).To(BeEmpty())
+	})
+
+	It("samples latencies beyond the maximum number of samples", func() {
+		aggregator = httpmetrics.NewAggregator(10)
+		for i := 0; i < 1000; i++ {
+			aggregator.Observe(httpTimer("app-1", "200", "Client", 5*time.Millisecond))
+		}
+
+		envs := aggregator.Flush(time.Second)
+		Expect(counters(envs)).To(Equal(map[string]uint64{
+			"app-1/2xx": 1000,
+		}))
+		Expect(gauges(envs)["app-1"]["http.latency_p50"].GetValue()).To(Equal(5.0))
+	})
+})
+
+func httpTimer(appID, status, peerType string, d time.Duration) *v2.Envelope {
+	return &v2.Envelope{
+		SourceId: appID,
+		Tags: map[string]string{
+			"status_code": status,
+			"peer_type":   peerType,
+		},
+		Message: &v2.Envelope_Timer{
+			Timer: &v2.Timer{
+				Name:  "http",
+				Start: 1000,
+				Stop:  1000 + int64(d),
+			},
+		},
+	}
+}
+
+func counters(envs []*v2.Envelope) map[string]uint64 {
+	result := make(map[string]uint64)
+	for _, e := range envs {
+		if c := e.GetCounter(); c != nil {
+			Expect(c.GetName()).To(Equal("http.requests"))
+			result[e.Tags["app_id"]+"/"+e.Tags["status_class"]] += c.GetDelta()
+		}
+	}
+
+	return result
+}
+
+func gauges(envs []*v2.Envelope) map[string]map[string]*v2.GaugeValue {
+	result := make(map[string]map[string]*v2.GaugeValue)
+	for _, e := range envs {
+		if g := e.GetGauge(); g != nil {
+			result[e.Tags["app_id"]] = g.GetMetrics()
+		}
+	}
+
+	return result
+}
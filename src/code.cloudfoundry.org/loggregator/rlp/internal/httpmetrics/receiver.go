@@ -0,0 +1,192 @@
+package httpmetrics
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/metricemitter"
+	"code.cloudfoundry.org/loggregator/plumbing"
+	"code.cloudfoundry.org/loggregator/plumbing/conversion"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+
+	"github.com/cloudfoundry/sonde-go/events"
+	"golang.org/x/net/context"
+)
+
+// MetricClient creates the counter of aggregated HTTP metrics dropped for
+// slow subscribers.
+type MetricClient interface {
+	NewCounter(name string, opts ...metricemitter.MetricOption) *metricemitter.Counter
+}
+
+type EgressReceiver interface {
+	Receive(ctx context.Context, req *v2.EgressRequest) (rx func() (*v2.Envelope, error), err error)
+}
+
+type Subscriber interface {
+	Subscribe(ctx context.Context, req *plumbing.SubscriptionRequest) (recv func() ([]byte, error), err error)
+}
+
+// Receiver aggregates the HTTP timers of every app and serves the resulting
+// metrics to requests for SourceID. Other requests are passed to the wrapped
+// receiver.
+type Receiver struct {
+	receiver   EgressReceiver
+	subscriber Subscriber
+	aggregator *Aggregator
+	interval   time.Duration
+	shardID    string
+
+	mu          sync.Mutex
+	subscribers map[chan *v2.Envelope]struct{}
+
+	droppedMetric *metricemitter.Counter
+}
+
+func NewReceiver(
+	r EgressReceiver,
+	s Subscriber,
+	a *Aggregator,
+	interval time.Duration,
+	index string,
+	m MetricClient,
+) *Receiver {
+	// metric-documentation-v2: (loggregator.rlp.http_metrics.dropped) Number
+	// of aggregated HTTP metrics dropped because a subscriber was too slow.
+	droppedMetric := m.NewCounter("http_metrics.dropped",
+		metricemitter.WithVersion(2, 0),
+	)
+
+	return &Receiver{
+		receiver:      r,
+		subscriber:    s,
+		aggregator:    a,
+		interval:      interval,
+		shardID:       "rlp-http-metrics-" + index,
+		subscribers:   make(map[chan *v2.Envelope]struct{}),
+		droppedMetric: droppedMetric,
+	}
+}
+
+// Start consumes the metrics from every Doppler and emits the aggregated
+// metrics every interval. Its shard ID is unique to the index of the RLP so
+// every RLP sees every request and keeps its shard ID when restarted. It
+// returns when the context is done.
+func (r *Receiver) Start(ctx context.Context) {
+	go r.flush(ctx)
+
+	for ctx.Err() == nil {
+		recv, err := r.subscriber.Subscribe(ctx, &plumbing.SubscriptionRequest{
+			ShardID: r.shardID,
+			Filter: &plumbing.Filter{
+				Message: &plumbing.Filter_Metric{
+					Metric: &plumbing.MetricFilter{},
+				},
+			},
+		})
+		if err != nil {
+			log.Printf("failed to subscribe for HTTP metrics: %s", err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		r.consume(recv)
+	}
+}
+
+func (r *Receiver) consume(recv func() ([]byte, error)) {
+	for {
+		data, err := recv()
+		if err != nil {
+			return
+		}
+
+		var e events.Envelope
+		if err := e.Unmarshal(data); err != nil {
+			continue
+		}
+
+		if e.GetEventType() != events.Envelope_HttpStartStop {
+			continue
+		}
+
+		r.aggregator.Observe(conversion.ToV2(&e, true))
+	}
+}
+
+func (r *Receiver) flush(ctx context.Context) {
+	t := time.NewTicker(r.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			r.publish(r.aggregator.Flush(r.interval))
+		}
+	}
+}
+
+func (r *Receiver) publish(envs []*v2.Envelope) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for s := range r.subscribers {
+		for _, e := range envs {
+			select {
+			case s <- e:
+			default:
+				r.droppedMetric.Increment(1)
+			}
+		}
+	}
+}
+
+func (r *Receiver) Receive(ctx context.Context, req *v2.EgressRequest) (rx func() (*v2.Envelope, error), err error) {
+	if req.GetFilter().GetSourceId() != SourceID {
+		return r.receiver.Receive(ctx, req)
+	}
+
+	s := make(chan *v2.Envelope, 1000)
+	r.mu.Lock()
+	r.subscribers[s] = struct{}{}
+	r.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		r.mu.Lock()
+		delete(r.subscribers, s)
+		r.mu.Unlock()
+	}()
+
+	return func() (*v2.Envelope, error) {
+		select {
+		case e := <-s:
+			if !req.GetUsePreferredTags() {
+				return deprecateTags(e), nil
+			}
+			return e, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}, nil
+}
+
+// deprecateTags returns a copy of the envelope with its tags moved to the
+// deprecated tags. Envelopes are shared by every subscriber.
+func deprecateTags(e *v2.Envelope) *v2.Envelope {
+	c := *e
+	c.Tags = nil
+	c.DeprecatedTags = make(map[string]*v2.Value, len(e.Tags))
+	for k, v := range e.Tags {
+		c.DeprecatedTags[k] = &v2.Value{
+			Data: &v2.Value_Text{
+				Text: v,
+			},
+		}
+	}
+
+	return &c
+}
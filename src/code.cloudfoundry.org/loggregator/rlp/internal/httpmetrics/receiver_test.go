@@ -0,0 +1,203 @@
+package httpmetrics_test
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/metricemitter/testhelper"
+	"code.cloudfoundry.org/loggregator/plumbing"
+	"code.cloudfoundry.org/loggregator/plumbing/conversion"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+	"code.cloudfoundry.org/loggregator/rlp/internal/httpmetrics"
+
+	"github.com/cloudfoundry/sonde-go/events"
+	"github.com/gogo/protobuf/proto"
+	"golang.org/x/net/context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Receiver", func() {
+	var (
+		spyReceiver   *spyEgressReceiver
+		spySubscriber *spySubscriber
+		receiver      *httpmetrics.Receiver
+		ctx           context.Context
+		cancel        func()
+	)
+
+	BeforeEach(func() {
+		spyReceiver = &spyEgressReceiver{}
+		spySubscriber = newSpySubscriber()
+		receiver = httpmetrics.NewReceiver(
+			spyReceiver,
+			spySubscriber,
+			httpmetrics.NewAggregator(100),
+			10*time.Millisecond,
+			"1",
+			testhelper.NewMetricClient(),
+		)
+
+		ctx, cancel = context.WithCancel(context.Background())
+		go receiver.Start(ctx)
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("subscribes to metrics with the shard ID of its index", func() {
+		Eventually(spySubscriber.request).ShouldNot(BeNil())
+
+		req := spySubscriber.request()
+		Expect(req.GetShardID()).To(Equal("rlp-http-metrics-1"))
+		Expect(req.GetFilter().GetMetric()).ToNot(BeNil())
+		Expect(req.GetFilter().GetAppID()).To(BeEmpty())
+	})
+
+	It("passes other requests to the wrapped receiver", func() {
+		req := &v2.EgressRequest{
+			Filter: &v2.Filter{SourceId: "app-1"},
+		}
+		_, err := receiver.Receive(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(spyReceiver.request).To(Equal(req))
+	})
+
+	It("serves the aggregated metrics", func() {
+		v1e := httpStartStop(200)
+		appID := conversion.ToV2(v1e, true).GetSourceId()
+
+		rx, err := receiver.Receive(ctx, &v2.EgressRequest{
+			Filter:           &v2.Filter{SourceId: httpmetrics.SourceID},
+			UsePreferredTags: true,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(spyReceiver.request).To(BeNil())
+
+		spySubscriber.send(v1e)
+		spySubscriber.send(counterEvent())
+
+		e, err := rx()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(e.GetSourceId()).To(Equal(httpmetrics.SourceID))
+		Expect(e.GetCounter().GetDelta()).To(Equal(uint64(1)))
+		Expect(e.GetTags()).To(Equal(map[string]string{
+			"app_id":       appID,
+			"status_class": "2xx",
+		}))
+
+		e, err = rx()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(e.GetGauge().GetMetrics()).To(HaveKey("http.latency_p99"))
+	})
+
+	It("uses deprecated tags for requests that do not prefer tags", func() {
+		rx, err := receiver.Receive(ctx, &v2.EgressRequest{
+			Filter: &v2.Filter{SourceId: httpmetrics.SourceID},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		spySubscriber.send(httpStartStop(500))
+
+		e, err := rx()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(e.GetTags()).To(BeEmpty())
+		Expect(e.GetDeprecatedTags()["status_class"].GetText()).To(Equal("5xx"))
+	})
+
+	It("returns an error when the request is done", func() {
+		reqCtx, reqCancel := context.WithCancel(ctx)
+		rx, err := receiver.Receive(reqCtx, &v2.EgressRequest{
+			Filter: &v2.Filter{SourceId: httpmetrics.SourceID},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		reqCancel()
+		_, err = rx()
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+func httpStartStop(status int32) *events.Envelope {
+	return &events.Envelope{
+		Origin:    proto.String("gorouter"),
+		EventType: events.Envelope_HttpStartStop.Enum(),
+		HttpStartStop: &events.HttpStartStop{
+			StartTimestamp: proto.Int64(1000),
+			StopTimestamp:  proto.Int64(1000 + int64(time.Millisecond)),
+			RequestId:      &events.UUID{Low: proto.Uint64(1), High: proto.Uint64(2)},
+			PeerType:       events.PeerType_Client.Enum(),
+			Method:         events.Method_GET.Enum(),
+			Uri:            proto.String("http://example.com"),
+			RemoteAddress:  proto.String("10.0.0.1"),
+			UserAgent:      proto.String("curl"),
+			StatusCode:     proto.Int32(status),
+			ContentLength:  proto.Int64(10),
+			ApplicationId:  &events.UUID{Low: proto.Uint64(3), High: proto.Uint64(4)},
+		},
+	}
+}
+
+func counterEvent() *events.Envelope {
+	return &events.Envelope{
+		Origin:    proto.String("some-origin"),
+		EventType: events.Envelope_CounterEvent.Enum(),
+		CounterEvent: &events.CounterEvent{
+			Name:  proto.String("some-counter"),
+			Delta: proto.Uint64(1),
+		},
+	}
+}
+
+type spyEgressReceiver struct {
+	request *v2.EgressRequest
+}
+
+func (s *spyEgressReceiver) Receive(ctx context.Context, req *v2.EgressRequest) (func() (*v2.Envelope, error), error) {
+	s.request = req
+	return func() (*v2.Envelope, error) {
+		return &v2.Envelope{}, nil
+	}, nil
+}
+
+type spySubscriber struct {
+	mu   sync.Mutex
+	req  *plumbing.SubscriptionRequest
+	data chan []byte
+}
+
+func newSpySubscriber() *spySubscriber {
+	return &spySubscriber{
+		data: make(chan []byte, 100),
+	}
+}
+
+func (s *spySubscriber) Subscribe(ctx context.Context, req *plumbing.SubscriptionRequest) (func() ([]byte, error), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.req = req
+
+	return func() ([]byte, error) {
+		select {
+		case d := <-s.data:
+			return d, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}, nil
+}
+
+func (s *spySubscriber) request() *plumbing.SubscriptionRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.req
+}
+
+func (s *spySubscriber) send(e *events.Envelope) {
+	data, err := proto.Marshal(e)
+	Expect(err).ToNot(HaveOccurred())
+	s.data <- data
+}
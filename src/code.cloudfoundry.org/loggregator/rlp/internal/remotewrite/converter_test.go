@@ -0,0 +1,168 @@
+package remotewrite_test
+
+import (
+	"time"
+
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+	"code.cloudfoundry.org/loggregator/rlp/internal/remotewrite"
+
+	"github.com/prometheus/prometheus/prompb"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Converter", func() {
+	var (
+		c         *remotewrite.Converter
+		now       time.Time
+		timestamp = int64(2 * time.Second)
+	)
+
+	BeforeEach(func() {
+		now = time.Unix(0, 0)
+		c = remotewrite.NewConverter(
+			map[string]string{"app_name": "app", "index": ""},
+			map[string]string{"foundation": "cf-1", "source_id": "ignored"},
+			"0",
+			remotewrite.WithClock(func() time.Time { return now }),
+		)
+	})
+
+	It("labels series with the tags, IDs and external labels", func() {
+		series, ok := c.Convert(&v2.Envelope{
+			SourceId:   "some-source",
+			InstanceId: "1",
+			Timestamp:  timestamp,
+			Tags: map[string]string{
+				"app_name":    "some-app",
+				"index":       "0",
+				"some.tag":    "value",
+				"rlp_replica": "ignored",
+			},
+			DeprecatedTags: map[string]*v2.Value{
+				"deprecated": {Data: &v2.Value_Integer{Integer: 3}},
+			},
+			Message: &v2.Envelope_Gauge{
+				Gauge: &v2.Gauge{
+					Metrics: map[string]*v2.GaugeValue{
+						"cpu.percent": {Value: 1.5},
+					},
+				},
+			},
+		})
+		Expect(ok).To(BeTrue())
+
+		Expect(series).To(Equal([]*prompb.TimeSeries{
+			{
+				Labels: []*prompb.Label{
+					{Name: "__name__", Value: "cpu_percent"},
+					{Name: "app", Value: "some-app"},
+					{Name: "deprecated", Value: "3"},
+					{Name: "foundation", Value: "cf-1"},
+					{Name: "instance_id", Value: "1"},
+					{Name: "some_tag", Value: "value"},
+					{Name: "source_id", Value: "some-source"},
+				},
+				Samples: []*prompb.Sample{
+					{Value: 1.5, Timestamp: 2000},
+				},
+			},
+		}))
+	})
+
+	It("uses the total of counters without the replica", func() {
+		series, _ := c.Convert(counter(&v2.Counter{
+			Name:  "egress",
+			Value: &v2.Counter_Total{Total: 10},
+		}))
+
+		Expect(series).To(HaveLen(1))
+		Expect(series[0].Labels).To(Equal([]*prompb.Label{
+			{Name: "__name__", Value: "egress_total"},
+			{Name: "foundation", Value: "cf-1"},
+			{Name: "source_id", Value: "some-source"},
+		}))
+		Expect(series[0].Samples[0].Value).To(Equal(10.0))
+	})
+
+	It("accumulates delta counters", func() {
+		c.Convert(counter(&v2.Counter{
+			Name:  "requests_total",
+			Value: &v2.Counter_Delta{Delta: 2},
+		}))
+		series, _ := c.Convert(counter(&v2.Counter{
+			Name:  "requests_total",
+			Value: &v2.Counter_Delta{Delta: 3},
+		}))
+
+		Expect(series).To(HaveLen(1))
+		Expect(series[0].Labels).To(Equal([]*prompb.Label{
+			{Name: "__name__", Value: "requests_total"},
+			{Name: "foundation", Value: "cf-1"},
+			{Name: "rlp_replica", Value: "0"},
+			{Name: "source_id", Value: "some-source"},
+		}))
+		Expect(series[0].Samples[0].Value).To(Equal(5.0))
+	})
+
+	It("starts delta counters over when they have not been seen for ten minutes", func() {
+		c.Convert(counter(&v2.Counter{
+			Name:  "requests_total",
+			Value: &v2.Counter_Delta{Delta: 2},
+		}))
+		now = now.Add(11 * time.Minute)
+		series, ok := c.Convert(counter(&v2.Counter{
+			Name:  "requests_total",
+			Value: &v2.Counter_Delta{Delta: 3},
+		}))
+
+		Expect(ok).To(BeTrue())
+		Expect(series).To(HaveLen(1))
+		Expect(series[0].Samples[0].Value).To(Equal(3.0))
+	})
+
+	It("accumulates the count and sum of timers", func() {
+		timer := &v2.Envelope{
+			SourceId: "some-source",
+			Message: &v2.Envelope_Timer{
+				Timer: &v2.Timer{
+					Name:  "http",
+					Start: 0,
+					Stop:  int64(500 * time.Millisecond),
+				},
+			},
+		}
+		c.Convert(timer)
+		series, _ := c.Convert(timer)
+
+		Expect(series).To(HaveLen(2))
+		Expect(series[0].Labels[0].Value).To(Equal("http_seconds_count"))
+		Expect(series[0].Samples[0].Value).To(Equal(2.0))
+		Expect(series[1].Labels[0].Value).To(Equal("http_seconds_sum"))
+		Expect(series[1].Samples[0].Value).To(Equal(1.0))
+		for _, s := range series {
+			Expect(s.Labels).To(ContainElement(&prompb.Label{Name: "rlp_replica", Value: "0"}))
+		}
+	})
+
+	It("has no series for logs", func() {
+		series, ok := c.Convert(&v2.Envelope{
+			Message: &v2.Envelope_Log{
+				Log: &v2.Log{Payload: []byte("hello")},
+			},
+		})
+
+		Expect(ok).To(BeTrue())
+		Expect(series).To(BeEmpty())
+	})
+})
+
+func counter(c *v2.Counter) *v2.Envelope {
+	return &v2.Envelope{
+		SourceId: "some-source",
+		Message: &v2.Envelope_Counter{
+			Counter: c,
+		},
+	}
+}
@@ -0,0 +1,263 @@
+package remotewrite
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/metricemitter"
+	"code.cloudfoundry.org/loggregator/plumbing"
+	"code.cloudfoundry.org/loggregator/plumbing/conversion"
+
+	"github.com/cloudfoundry/sonde-go/events"
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"golang.org/x/net/context"
+)
+
+// MetricClient creates the counters of the sent and dropped samples and
+// the retried batches of the exporter.
+type MetricClient interface {
+	NewCounter(name string, opts ...metricemitter.MetricOption) *metricemitter.Counter
+}
+
+type Subscriber interface {
+	Subscribe(ctx context.Context, req *plumbing.SubscriptionRequest) (recv func() ([]byte, error), err error)
+}
+
+// Config configures an Exporter.
+type Config struct {
+	// URL is the remote write endpoint.
+	URL string
+
+	// SourceID restricts the exported envelopes to a source ID. Every
+	// source ID is exported when empty.
+	SourceID string
+
+	BatchSize     int
+	FlushInterval time.Duration
+
+	// MaxRetries is the number of times a batch is retried after a
+	// network error or a 5xx or 429 response. The time between retries
+	// doubles from MinBackoff up to MaxBackoff.
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	LabelMap       map[string]string
+	ExternalLabels map[string]string
+
+	// Replica identifies the RLP, e.g. by its instance index. Every RLP
+	// accumulates delta counters and timers from its share of the
+	// envelopes, so the replica is added to their series as the
+	// rlp_replica label to keep the totals of different RLPs apart.
+	Replica string
+}
+
+// shardID is shared by every RLP so that each envelope is exported once.
+const shardID = "rlp-remote-write"
+
+// Exporter subscribes to the metrics of every Doppler and pushes them to a
+// Prometheus remote write endpoint.
+type Exporter struct {
+	conf       Config
+	subscriber Subscriber
+	client     *http.Client
+	converter  *Converter
+	series     chan *prompb.TimeSeries
+
+	sentMetric    *metricemitter.Counter
+	droppedMetric *metricemitter.Counter
+	retriesMetric *metricemitter.Counter
+}
+
+func NewExporter(
+	conf Config,
+	s Subscriber,
+	c *http.Client,
+	m MetricClient,
+) *Exporter {
+	// metric-documentation-v2: (loggregator.rlp.remote_write.sent) Number
+	// of samples written to the remote write endpoint.
+	sentMetric := m.NewCounter("remote_write.sent",
+		metricemitter.WithVersion(2, 0),
+	)
+
+	// metric-documentation-v2: (loggregator.rlp.remote_write.dropped)
+	// Number of samples dropped because the buffer was full, too many
+	// series were tracked or the remote write endpoint did not accept them.
+	droppedMetric := m.NewCounter("remote_write.dropped",
+		metricemitter.WithVersion(2, 0),
+	)
+
+	// metric-documentation-v2: (loggregator.rlp.remote_write.retries)
+	// Number of times a batch was retried.
+	retriesMetric := m.NewCounter("remote_write.retries",
+		metricemitter.WithVersion(2, 0),
+	)
+
+	return &Exporter{
+		conf:          conf,
+		subscriber:    s,
+		client:        c,
+		converter:     NewConverter(conf.LabelMap, conf.ExternalLabels, conf.Replica),
+		series:        make(chan *prompb.TimeSeries, 10*conf.BatchSize),
+		sentMetric:    sentMetric,
+		droppedMetric: droppedMetric,
+		retriesMetric: retriesMetric,
+	}
+}
+
+// Start subscribes to the metrics and writes them to the remote write
+// endpoint. Logs are filtered out by Doppler. It shares its shard ID with
+// the other RLPs so that each of them receives a share of the metrics. It
+// returns when the context is done.
+func (e *Exporter) Start(ctx context.Context) {
+	go e.write(ctx)
+
+	for ctx.Err() == nil {
+		recv, err := e.subscriber.Subscribe(ctx, &plumbing.SubscriptionRequest{
+			ShardID: shardID,
+			Filter: &plumbing.Filter{
+				AppID: e.conf.SourceID,
+				Message: &plumbing.Filter_Metric{
+					Metric: &plumbing.MetricFilter{},
+				},
+			},
+		})
+		if err != nil {
+			log.Printf("failed to subscribe for remote write: %s", err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		e.consume(recv)
+	}
+}
+
+func (e *Exporter) consume(recv func() ([]byte, error)) {
+	for {
+		data, err := recv()
+		if err != nil {
+			return
+		}
+
+		var env events.Envelope
+		if err := env.Unmarshal(data); err != nil {
+			continue
+		}
+
+		series, ok := e.converter.Convert(conversion.ToV2(&env, true))
+		if !ok {
+			e.droppedMetric.Increment(1)
+			continue
+		}
+
+		for _, s := range series {
+			select {
+			case e.series <- s:
+			default:
+				e.droppedMetric.Increment(1)
+			}
+		}
+	}
+}
+
+func (e *Exporter) write(ctx context.Context) {
+	t := time.NewTicker(e.conf.FlushInterval)
+	defer t.Stop()
+
+	batch := make([]*prompb.TimeSeries, 0, e.conf.BatchSize)
+	for {
+		select {
+		case s := <-e.series:
+			batch = append(batch, s)
+			if len(batch) < e.conf.BatchSize {
+				continue
+			}
+		case <-t.C:
+			if len(batch) == 0 {
+				continue
+			}
+		case <-ctx.Done():
+			return
+		}
+
+		e.send(ctx, batch)
+		batch = make([]*prompb.TimeSeries, 0, e.conf.BatchSize)
+	}
+}
+
+// send writes the batch and retries when the endpoint is unavailable. The
+// batch is dropped once it has been retried MaxRetries times or the
+// endpoint rejects it.
+func (e *Exporter) send(ctx context.Context, batch []*prompb.TimeSeries) {
+	data, err := proto.Marshal(&prompb.WriteRequest{Timeseries: batch})
+	if err != nil {
+		log.Printf("failed to marshal remote write request: %s", err)
+		e.droppedMetric.Increment(uint64(len(batch)))
+		return
+	}
+	body := snappy.Encode(nil, data)
+
+	backoff := e.conf.MinBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := e.post(body)
+		if err == nil {
+			e.sentMetric.Increment(uint64(len(batch)))
+			return
+		}
+
+		if !retry || attempt >= e.conf.MaxRetries {
+			log.Printf("dropping %d remote write samples: %s", len(batch), err)
+			e.droppedMetric.Increment(uint64(len(batch)))
+			return
+		}
+
+		e.retriesMetric.Increment(1)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+
+		backoff *= 2
+		if backoff > e.conf.MaxBackoff {
+			backoff = e.conf.MaxBackoff
+		}
+	}
+}
+
+// post writes the body to the endpoint. It returns whether a failed request
+// may be retried.
+func (e *Exporter) post(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, e.conf.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer func(r *http.Response) {
+		io.Copy(ioutil.Discard, r.Body)
+		r.Body.Close()
+	}(resp)
+
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+
+	err = fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	retry := resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, err
+}
@@ -0,0 +1,252 @@
+package remotewrite_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/metricemitter/testhelper"
+	"code.cloudfoundry.org/loggregator/plumbing"
+	"code.cloudfoundry.org/loggregator/rlp/internal/remotewrite"
+
+	"github.com/cloudfoundry/sonde-go/events"
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"golang.org/x/net/context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Exporter", func() {
+	var (
+		receiver      *stubReceiver
+		spySubscriber *spySubscriber
+		metricClient  *testhelper.SpyMetricClient
+		conf          remotewrite.Config
+		ctx           context.Context
+		cancel        func()
+	)
+
+	BeforeEach(func() {
+		receiver = newStubReceiver()
+		spySubscriber = newSpySubscriber()
+		metricClient = testhelper.NewMetricClient()
+		conf = remotewrite.Config{
+			URL:           receiver.server.URL,
+			SourceID:      "some-source",
+			BatchSize:     10,
+			FlushInterval: time.Hour,
+			MaxRetries:    2,
+			MinBackoff:    time.Millisecond,
+			MaxBackoff:    time.Millisecond,
+		}
+		ctx, cancel = context.WithCancel(context.Background())
+	})
+
+	AfterEach(func() {
+		cancel()
+		receiver.server.Close()
+	})
+
+	start := func() {
+		e := remotewrite.NewExporter(conf, spySubscriber, http.DefaultClient, metricClient)
+		go e.Start(ctx)
+	}
+
+	It("subscribes to metrics with the configured filter and the shared shard ID", func() {
+		start()
+
+		Eventually(spySubscriber.request).ShouldNot(BeNil())
+		req := spySubscriber.request()
+		Expect(req.GetShardID()).To(Equal("rlp-remote-write"))
+		Expect(req.GetFilter().GetAppID()).To(Equal("some-source"))
+		Expect(req.GetFilter().GetMetric()).ToNot(BeNil())
+	})
+
+	It("writes batches of snappy compressed series", func() {
+		start()
+
+		for i := 0; i < 10; i++ {
+			spySubscriber.send(gauge(fmt.Sprintf("gauge-%d", i), float64(i)))
+		}
+
+		var wr *prompb.WriteRequest
+		Eventually(receiver.requests).Should(Receive(&wr))
+		Expect(wr.Timeseries).To(HaveLen(10))
+		Expect(wr.Timeseries[9].Samples[0].Value).To(Equal(9.0))
+
+		Expect(receiver.header.Get("Content-Encoding")).To(Equal("snappy"))
+		Expect(receiver.header.Get("Content-Type")).To(Equal("application/x-protobuf"))
+		Expect(receiver.header.Get("X-Prometheus-Remote-Write-Version")).To(Equal("0.1.0"))
+		Eventually(func() uint64 {
+			return metricClient.GetDelta("remote_write.sent")
+		}).Should(Equal(uint64(10)))
+	})
+
+	It("writes partial batches every flush interval", func() {
+		conf.FlushInterval = 10 * time.Millisecond
+		start()
+
+		spySubscriber.send(gauge("some-gauge", 1))
+
+		var wr *prompb.WriteRequest
+		Eventually(receiver.requests).Should(Receive(&wr))
+		Expect(wr.Timeseries).To(HaveLen(1))
+	})
+
+	It("retries batches when the endpoint is unavailable", func() {
+		receiver.setStatusCodes(http.StatusServiceUnavailable, http.StatusOK)
+		start()
+
+		for i := 0; i < 10; i++ {
+			spySubscriber.send(gauge("some-gauge", float64(i)))
+		}
+
+		Eventually(receiver.requests).Should(Receive())
+		Eventually(receiver.requests).Should(Receive())
+		Eventually(func() uint64 {
+			return metricClient.GetDelta("remote_write.sent")
+		}).Should(Equal(uint64(10)))
+		Expect(metricClient.GetDelta("remote_write.retries")).To(Equal(uint64(1)))
+	})
+
+	It("drops batches that are rejected", func() {
+		receiver.setStatusCodes(http.StatusBadRequest)
+		start()
+
+		for i := 0; i < 10; i++ {
+			spySubscriber.send(gauge("some-gauge", float64(i)))
+		}
+
+		Eventually(func() uint64 {
+			return metricClient.GetDelta("remote_write.dropped")
+		}).Should(Equal(uint64(10)))
+		Expect(metricClient.GetDelta("remote_write.retries")).To(BeZero())
+	})
+
+	It("drops batches once they have been retried", func() {
+		receiver.setStatusCodes(http.StatusInternalServerError)
+		start()
+
+		for i := 0; i < 10; i++ {
+			spySubscriber.send(gauge("some-gauge", float64(i)))
+		}
+
+		Eventually(func() uint64 {
+			return metricClient.GetDelta("remote_write.dropped")
+		}).Should(Equal(uint64(10)))
+		Expect(metricClient.GetDelta("remote_write.retries")).To(Equal(uint64(2)))
+	})
+})
+
+func gauge(name string, value float64) *events.Envelope {
+	return &events.Envelope{
+		Origin:     proto.String("some-origin"),
+		EventType:  events.Envelope_ValueMetric.Enum(),
+		Timestamp:  proto.Int64(time.Now().UnixNano()),
+		Deployment: proto.String("cf"),
+		Job:        proto.String("router"),
+		Tags: map[string]string{
+			"source_id": "some-source",
+		},
+		ValueMetric: &events.ValueMetric{
+			Name:  proto.String(name),
+			Value: proto.Float64(value),
+			Unit:  proto.String("percent"),
+		},
+	}
+}
+
+// stubReceiver stands in for a remote write endpoint. It responds with the
+// given status codes in order and repeats the last one.
+type stubReceiver struct {
+	server   *httptest.Server
+	requests chan *prompb.WriteRequest
+
+	mu          sync.Mutex
+	statusCodes []int
+	header      http.Header
+}
+
+func newStubReceiver() *stubReceiver {
+	r := &stubReceiver{
+		requests:    make(chan *prompb.WriteRequest, 100),
+		statusCodes: []int{http.StatusNoContent},
+	}
+	r.server = httptest.NewServer(r)
+
+	return r
+}
+
+func (r *stubReceiver) setStatusCodes(codes ...int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statusCodes = codes
+}
+
+func (r *stubReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	defer GinkgoRecover()
+
+	body, err := ioutil.ReadAll(req.Body)
+	Expect(err).ToNot(HaveOccurred())
+	data, err := snappy.Decode(nil, body)
+	Expect(err).ToNot(HaveOccurred())
+
+	var wr prompb.WriteRequest
+	Expect(proto.Unmarshal(data, &wr)).To(Succeed())
+
+	r.mu.Lock()
+	r.header = req.Header
+	code := r.statusCodes[0]
+	if len(r.statusCodes) > 1 {
+		r.statusCodes = r.statusCodes[1:]
+	}
+	r.mu.Unlock()
+
+	r.requests <- &wr
+	w.WriteHeader(code)
+}
+
+type spySubscriber struct {
+	mu   sync.Mutex
+	req  *plumbing.SubscriptionRequest
+	data chan []byte
+}
+
+func newSpySubscriber() *spySubscriber {
+	return &spySubscriber{
+		data: make(chan []byte, 100),
+	}
+}
+
+func (s *spySubscriber) Subscribe(ctx context.Context, req *plumbing.SubscriptionRequest) (func() ([]byte, error), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.req = req
+
+	return func() ([]byte, error) {
+		select {
+		case d := <-s.data:
+			return d, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}, nil
+}
+
+func (s *spySubscriber) request() *plumbing.SubscriptionRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.req
+}
+
+func (s *spySubscriber) send(e *events.Envelope) {
+	data, err := proto.Marshal(e)
+	Expect(err).ToNot(HaveOccurred())
+	s.data <- data
+}
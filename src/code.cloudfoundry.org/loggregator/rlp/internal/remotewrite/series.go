@@ -0,0 +1,278 @@
+package remotewrite
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// maxTrackedSeries bounds the number of series whose totals are tracked for
+// delta counters and timers.
+const maxTrackedSeries = 100000
+
+// seriesTTL is the time after which the totals of series that have not been
+// seen are evicted. An evicted series starts over at zero, which Prometheus
+// treats as a counter reset.
+const seriesTTL = 10 * time.Minute
+
+// Converter turns v2 envelopes into remote write time series. Counters are
+// named <name>_total. Delta counters and timers are accumulated into totals
+// as Prometheus expects cumulative values; timers become the series
+// <name>_seconds_count and <name>_seconds_sum. Every series is labeled with
+// the source ID, the instance ID and the tags of its envelope. Accumulated
+// series are also labeled with the replica as each RLP only accumulates its
+// share of the envelopes. Counter totals and gauges are the same whichever
+// RLP pushes them, so they are not.
+type Converter struct {
+	labelMap       map[string]string
+	externalLabels map[string]string
+	replica        string
+	now            func() time.Time
+	totals         map[string]seriesTotal
+	lastSweep      int64
+}
+
+type seriesTotal struct {
+	value    float64
+	lastSeen int64
+}
+
+// ConverterOption configures a Converter.
+type ConverterOption func(*Converter)
+
+// WithClock sets the function the current time is read from. It defaults to
+// time.Now.
+func WithClock(now func() time.Time) ConverterOption {
+	return func(c *Converter) {
+		c.now = now
+	}
+}
+
+// NewConverter returns a Converter. Tags found in labelMap are renamed to
+// the mapped label name or dropped when it is empty. The external labels
+// are added to every series unless the series already has the label. The
+// replica, when not empty, is added to accumulated series as the
+// rlp_replica label. Totals of series not seen within ten minutes are
+// evicted.
+func NewConverter(labelMap, externalLabels map[string]string, replica string, opts ...ConverterOption) *Converter {
+	c := &Converter{
+		labelMap:       labelMap,
+		externalLabels: externalLabels,
+		replica:        replica,
+		now:            time.Now,
+		totals:         make(map[string]seriesTotal),
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	c.lastSweep = c.now().UnixNano()
+
+	return c
+}
+
+// Convert returns the time series of the envelope. Logs and events have no
+// time series. ok is false when a series could not be tracked because the
+// maximum number of tracked series has been reached.
+func (c *Converter) Convert(e *v2.Envelope) (series []*prompb.TimeSeries, ok bool) {
+	timestamp := e.GetTimestamp() / int64(time.Millisecond)
+	if timestamp == 0 {
+		timestamp = time.Now().UnixNano() / int64(time.Millisecond)
+	}
+
+	ok = true
+	switch m := e.Message.(type) {
+	case *v2.Envelope_Counter:
+		name := sanitize(m.Counter.GetName())
+		if !strings.HasSuffix(name, "_total") {
+			name += "_total"
+		}
+		if _, isDelta := m.Counter.GetValue().(*v2.Counter_Delta); !isDelta {
+			labels := c.labels(name, e, false)
+			series = append(series, newSeries(labels, float64(m.Counter.GetTotal()), timestamp))
+			break
+		}
+
+		labels := c.labels(name, e, true)
+		value, added := c.add(labels, float64(m.Counter.GetDelta()))
+		if !added {
+			return nil, false
+		}
+
+		series = append(series, newSeries(labels, value, timestamp))
+	case *v2.Envelope_Gauge:
+		names := make([]string, 0, len(m.Gauge.GetMetrics()))
+		for n := range m.Gauge.GetMetrics() {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+
+		for _, n := range names {
+			labels := c.labels(sanitize(n), e, false)
+			series = append(series, newSeries(labels, m.Gauge.GetMetrics()[n].GetValue(), timestamp))
+		}
+	case *v2.Envelope_Timer:
+		name := sanitize(m.Timer.GetName()) + "_seconds"
+		duration := float64(m.Timer.GetStop()-m.Timer.GetStart()) / float64(time.Second)
+
+		countLabels := c.labels(name+"_count", e, true)
+		count, countOK := c.add(countLabels, 1)
+		sumLabels := c.labels(name+"_sum", e, true)
+		sum, sumOK := c.add(sumLabels, duration)
+		if !countOK || !sumOK {
+			return nil, false
+		}
+
+		series = append(series,
+			newSeries(countLabels, count, timestamp),
+			newSeries(sumLabels, sum, timestamp),
+		)
+	}
+
+	return series, ok
+}
+
+// add adds the value to the total of the series with the given labels.
+// The total of a series not seen within the TTL starts over at zero.
+func (c *Converter) add(labels []*prompb.Label, value float64) (float64, bool) {
+	now := c.now().UnixNano()
+	if now-c.lastSweep > int64(seriesTTL) {
+		c.sweep(now)
+	}
+
+	k := key(labels)
+	total, ok := c.totals[k]
+	if ok && now-total.lastSeen > int64(seriesTTL) {
+		total.value = 0
+	}
+	if !ok && len(c.totals) >= maxTrackedSeries {
+		c.sweep(now)
+		if len(c.totals) >= maxTrackedSeries {
+			return 0, false
+		}
+	}
+
+	total.value += value
+	total.lastSeen = now
+	c.totals[k] = total
+
+	return total.value, true
+}
+
+// sweep evicts the totals of series that have not been seen within the
+// TTL.
+func (c *Converter) sweep(now int64) {
+	for k, t := range c.totals {
+		if now-t.lastSeen > int64(seriesTTL) {
+			delete(c.totals, k)
+		}
+	}
+	c.lastSweep = now
+}
+
+// labels returns the sorted labels of a series of the envelope. The
+// rlp_replica label is reserved for the replica of accumulated series.
+func (c *Converter) labels(name string, e *v2.Envelope, accumulated bool) []*prompb.Label {
+	m := map[string]string{
+		"__name__": name,
+	}
+
+	for k, v := range e.GetDeprecatedTags() {
+		c.addTag(m, k, valueText(v))
+	}
+	for k, v := range e.GetTags() {
+		c.addTag(m, k, v)
+	}
+
+	if e.GetSourceId() != "" {
+		m["source_id"] = e.GetSourceId()
+	}
+	if e.GetInstanceId() != "" {
+		m["instance_id"] = e.GetInstanceId()
+	}
+
+	for k, v := range c.externalLabels {
+		if _, ok := m[k]; !ok {
+			m[k] = v
+		}
+	}
+
+	delete(m, "rlp_replica")
+	if accumulated && c.replica != "" {
+		m["rlp_replica"] = c.replica
+	}
+
+	labels := make([]*prompb.Label, 0, len(m))
+	for k, v := range m {
+		labels = append(labels, &prompb.Label{Name: k, Value: v})
+	}
+	sort.Sort(byName(labels))
+
+	return labels
+}
+
+func (c *Converter) addTag(m map[string]string, k, v string) {
+	if name, ok := c.labelMap[k]; ok {
+		k = name
+	}
+	if k == "" || v == "" {
+		return
+	}
+
+	m[sanitize(k)] = v
+}
+
+func newSeries(labels []*prompb.Label, value float64, timestamp int64) *prompb.TimeSeries {
+	return &prompb.TimeSeries{
+		Labels: labels,
+		Samples: []*prompb.Sample{
+			{Value: value, Timestamp: timestamp},
+		},
+	}
+}
+
+func key(labels []*prompb.Label) string {
+	var k string
+	for _, l := range labels {
+		k += l.Name + "\xff" + l.Value + "\xff"
+	}
+
+	return k
+}
+
+func valueText(v *v2.Value) string {
+	switch d := v.GetData().(type) {
+	case *v2.Value_Text:
+		return d.Text
+	case *v2.Value_Integer:
+		return strconv.FormatInt(d.Integer, 10)
+	case *v2.Value_Decimal:
+		return strconv.FormatFloat(d.Decimal, 'f', -1, 64)
+	}
+
+	return ""
+}
+
+// sanitize replaces every character that is not valid in a Prometheus
+// metric or label name with an underscore.
+func sanitize(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' ||
+			r >= 'A' && r <= 'Z' ||
+			r >= '0' && r <= '9' ||
+			r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+type byName []*prompb.Label
+
+func (l byName) Len() int           { return len(l) }
+func (l byName) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+func (l byName) Less(i, j int) bool { return l[i].Name < l[j].Name }
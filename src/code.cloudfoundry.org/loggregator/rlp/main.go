@@ -1,15 +1,18 @@
 package main
 
 import (
+	"crypto/tls"
 	"flag"
 	"io/ioutil"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
 	"time"
 
+	"code.cloudfoundry.org/loggregator/healthendpoint"
 	"code.cloudfoundry.org/loggregator/metricemitter"
 
 	"google.golang.org/grpc"
@@ -19,6 +22,10 @@ import (
 	"code.cloudfoundry.org/loggregator/profiler"
 
 	"code.cloudfoundry.org/loggregator/rlp/app"
+	"code.cloudfoundry.org/loggregator/rlp/internal/appmeta"
+	"code.cloudfoundry.org/loggregator/rlp/internal/auth"
+	"code.cloudfoundry.org/loggregator/rlp/internal/federation"
+	"code.cloudfoundry.org/loggregator/rlp/internal/remotewrite"
 )
 
 func main() {
@@ -28,6 +35,7 @@ func main() {
 	ingressAddrsList := flag.String("ingress-addrs", "", "The addresses of Dopplers")
 	pprofPort := flag.Int("pprof-port", 6061, "The port of pprof for health checks")
 	healthAddr := flag.String("health-addr", "localhost:14825", "The address for the health endpoint")
+	index := flag.String("index", "", "The instance index of the RLP. It has to be unique when HTTP metrics are enabled")
 
 	caFile := flag.String("ca", "", "The file path for the CA cert")
 	certFile := flag.String("cert", "", "The file path for the client cert")
@@ -37,6 +45,32 @@ func main() {
 	metronAddr := flag.String("metron-addr", "localhost:3458", "The GRPC address to inject metrics to")
 	metricEmitterInterval := flag.Duration("metric-emitter-interval", time.Minute, "The interval to send batched metrics to metron")
 
+	ccAddr := flag.String("cc-addr", "", "The address of Cloud Controller used to look up app metadata. App metadata is disabled when empty")
+	uaaAddr := flag.String("uaa-addr", "", "The address of UAA used to authenticate with Cloud Controller and validate bearer tokens of clients")
+	uaaClientID := flag.String("uaa-client-id", "", "The UAA client ID used to authenticate with Cloud Controller")
+	uaaClientSecret := flag.String("uaa-client-secret", "", "The UAA client secret used to authenticate with Cloud Controller")
+	skipCertVerify := flag.Bool("skip-cert-verify", false, "Skip TLS certificate verification for Cloud Controller and UAA")
+	appMetadataTTL := flag.Duration("app-metadata-ttl", 5*time.Minute, "How long app metadata is cached before it is refreshed")
+
+	authorizationFile := flag.String("authorization-file", "", "The file path for the JSON list of authorized clients. Every client may read everything when empty")
+	tokenUAAClientID := flag.String("token-uaa-client-id", "", "The UAA client ID used to validate bearer tokens of clients")
+	tokenUAAClientSecret := flag.String("token-uaa-client-secret", "", "The UAA client secret used to validate bearer tokens of clients")
+
+	federationFile := flag.String("federation-file", "", "The file path for the JSON list of foundations whose Doppler pools are federated. Ingress addrs are used when empty")
+
+	httpMetricsInterval := flag.Duration("http-metrics-interval", 0, "The interval to emit aggregated HTTP request metrics of every app. HTTP metrics are disabled when 0")
+
+	remoteWriteURL := flag.String("remote-write-url", "", "The Prometheus remote write endpoint to push counters, gauges and timers to. Remote write is disabled when empty")
+	remoteWriteSourceID := flag.String("remote-write-source-id", "", "The source ID to push to the remote write endpoint. Every source ID is pushed when empty")
+	remoteWriteBatchSize := flag.Int("remote-write-batch-size", 1000, "The maximum number of samples per remote write request")
+	remoteWriteFlushInterval := flag.Duration("remote-write-flush-interval", 5*time.Second, "The interval to push partial batches to the remote write endpoint")
+	remoteWriteMaxRetries := flag.Int("remote-write-max-retries", 3, "The number of times a failed remote write request is retried")
+	remoteWriteMinBackoff := flag.Duration("remote-write-min-backoff", 100*time.Millisecond, "The time before the first retry of a remote write request")
+	remoteWriteMaxBackoff := flag.Duration("remote-write-max-backoff", 5*time.Second, "The maximum time between retries of a remote write request")
+	remoteWriteLabelMap := flag.String("remote-write-label-map", "", "Tags to rename as tag:label pairs separated by ','. Tags mapped to an empty label are dropped")
+	remoteWriteExternalLabels := flag.String("remote-write-external-labels", "", "Labels added to every series as label=value pairs separated by ','")
+	remoteWriteReplica := flag.String("remote-write-replica", "", "The rlp_replica label added to accumulated delta counter and timer series. It must be unique per RLP")
+
 	flag.Parse()
 
 	dopplerCredentials, err := plumbing.NewClientCredentials(
@@ -80,25 +114,96 @@ func main() {
 		log.Fatalf("Could not use TLS config: %s", err)
 	}
 
+	mirror := healthendpoint.NewMirror("loggregator", "rlp")
+
 	// metric-documentation-v2: setup function
 	metric, err := metricemitter.NewClient(
 		*metronAddr,
 		metricemitter.WithGRPCDialOptions(grpc.WithTransportCredentials(metronCredentials)),
 		metricemitter.WithOrigin("loggregator.rlp"),
 		metricemitter.WithPulseInterval(*metricEmitterInterval),
+		metricemitter.WithMirror(mirror),
 	)
 	if err != nil {
 		log.Fatalf("Couldn't connect to metric emitter: %s", err)
 	}
 
-	rlp := app.NewRLP(
-		metric,
+	rlpOpts := []app.RLPOption{
 		app.WithEgressPort(*egressPort),
 		app.WithIngressAddrs(hostPorts),
 		app.WithIngressDialOptions(grpc.WithTransportCredentials(dopplerCredentials)),
 		app.WithEgressServerOptions(grpc.Creds(rlpCredentials)),
 		app.WithHealthAddr(*healthAddr),
-	)
+		app.WithMirror(mirror),
+	}
+
+	httpClient := &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: *skipCertVerify},
+		},
+	}
+
+	if *ccAddr != "" {
+		fetcher := appmeta.NewCCClient(httpClient, *ccAddr, *uaaAddr, *uaaClientID, *uaaClientSecret)
+		rlpOpts = append(rlpOpts, app.WithAppMetadata(fetcher, *appMetadataTTL))
+	}
+
+	if *authorizationFile != "" {
+		clients, err := auth.LoadClients(*authorizationFile)
+		if err != nil {
+			log.Fatalf("Could not load authorized clients: %s", err)
+		}
+
+		var validator auth.TokenValidator
+		if *tokenUAAClientID != "" {
+			if *uaaAddr == "" {
+				log.Fatal("uaa-addr is required with token-uaa-client-id")
+			}
+			validator = auth.NewUAAClient(httpClient, *uaaAddr, *tokenUAAClientID, *tokenUAAClientSecret)
+		}
+
+		authorizer, err := auth.NewAuthorizer(clients, validator)
+		if err != nil {
+			log.Fatalf("Could not use authorized clients: %s", err)
+		}
+		rlpOpts = append(rlpOpts, app.WithAuthorizer(authorizer))
+	}
+
+	if *federationFile != "" {
+		foundations, err := federation.LoadFoundations(*federationFile)
+		if err != nil {
+			log.Fatalf("Could not load foundations: %s", err)
+		}
+		rlpOpts = append(rlpOpts, app.WithFederation(foundations))
+	}
+
+	if *httpMetricsInterval > 0 {
+		if *index == "" {
+			log.Fatal("index is required with http-metrics-interval")
+		}
+		rlpOpts = append(rlpOpts, app.WithHTTPMetrics(*httpMetricsInterval, *index))
+	}
+
+	if *remoteWriteURL != "" {
+		if *remoteWriteReplica == "" {
+			log.Fatal("remote-write-replica is required with remote-write-url")
+		}
+		rlpOpts = append(rlpOpts, app.WithRemoteWrite(remotewrite.Config{
+			URL:            *remoteWriteURL,
+			SourceID:       *remoteWriteSourceID,
+			BatchSize:      *remoteWriteBatchSize,
+			FlushInterval:  *remoteWriteFlushInterval,
+			MaxRetries:     *remoteWriteMaxRetries,
+			MinBackoff:     *remoteWriteMinBackoff,
+			MaxBackoff:     *remoteWriteMaxBackoff,
+			LabelMap:       parsePairs(*remoteWriteLabelMap, ":"),
+			ExternalLabels: parsePairs(*remoteWriteExternalLabels, "="),
+			Replica:        *remoteWriteReplica,
+		}))
+	}
+
+	rlp := app.NewRLP(metric, rlpOpts...)
 	go rlp.Start()
 	go profiler.New(uint32(*pprofPort)).Start()
 	defer rlp.Stop()
@@ -107,3 +212,22 @@ func main() {
 	signal.Notify(killSignal, syscall.SIGINT, syscall.SIGTERM)
 	<-killSignal
 }
+
+// parsePairs parses pairs separated by ',' into a map. The key and value of
+// each pair are separated by sep.
+func parsePairs(s, sep string) map[string]string {
+	pairs := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		if pair == "" {
+			continue
+		}
+
+		kv := strings.SplitN(pair, sep, 2)
+		if len(kv) != 2 {
+			log.Fatalf("invalid pair: %s", pair)
+		}
+		pairs[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+
+	return pairs
+}
@@ -40,12 +40,14 @@ import (
 type MetricClient interface {
 	NewCounter(name string, opts ...metricemitter.MetricOption) *metricemitter.Counter
 	NewGauge(name, unit string, opts ...metricemitter.MetricOption) *metricemitter.Gauge
+	NewTimer(name string, opts ...metricemitter.MetricOption) *metricemitter.Timer
 }
 
 type TrafficController struct {
 	conf                 *Config
 	disableAccessControl bool
 	metricClient         MetricClient
+	mirror               *healthendpoint.Mirror
 	uaaHTTPClient        *http.Client
 	ccHTTPClient         *http.Client
 }
@@ -60,6 +62,7 @@ func NewTrafficController(
 	c *Config,
 	disableAccessControl bool,
 	metricClient MetricClient,
+	mirror *healthendpoint.Mirror,
 	uaaHTTPClient *http.Client,
 	ccHTTPClient *http.Client,
 ) *TrafficController {
@@ -67,6 +70,7 @@ func NewTrafficController(
 		conf:                 c,
 		disableAccessControl: disableAccessControl,
 		metricClient:         metricClient,
+		mirror:               mirror,
 		uaaHTTPClient:        uaaHTTPClient,
 		ccHTTPClient:         ccHTTPClient,
 	}
@@ -102,10 +106,11 @@ func (t *TrafficController) Start() {
 		t.conf.UaaClientSecret,
 	)
 	adminAuthorizer := auth.NewAdminAccessAuthorizer(t.disableAccessControl, &uaaClient)
+	appLister := auth.NewAppLister(t.ccHTTPClient, t.conf.ApiHost)
 
 	// Start the health endpoint listener
 	promRegistry := prometheus.NewRegistry()
-	healthendpoint.StartServer(t.conf.HealthAddr, promRegistry)
+	healthendpoint.StartServer(t.conf.HealthAddr, prometheus.Gatherers{promRegistry, t.mirror})
 	healthRegistry := healthendpoint.New(promRegistry, map[string]prometheus.Gauge{
 		// metric-documentation-health: (firehoseStreamCount)
 		// Number of open firehose streams
@@ -117,6 +122,16 @@ func (t *TrafficController) Start() {
 				Help:      "Number of open firehose streams",
 			},
 		),
+		// metric-documentation-health: (scopedFirehoseStreamCount)
+		// Number of open organization and space scoped firehose streams
+		"scopedFirehoseStreamCount": prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: "loggregator",
+				Subsystem: "trafficcontroller",
+				Name:      "scopedFirehoseStreamCount",
+				Help:      "Number of open organization and space scoped firehose streams",
+			},
+		),
 		// metric-documentation-health: (appStreamCount)
 		// Number of open app streams
 		"appStreamCount": prometheus.NewGauge(
@@ -167,6 +182,7 @@ func (t *TrafficController) Start() {
 		proxy.NewDopplerProxy(
 			logAuthorizer,
 			adminAuthorizer,
+			appLister,
 			grpcConnector,
 			"doppler."+t.conf.SystemDomain,
 			5*time.Second,
@@ -225,7 +241,7 @@ func (t *TrafficController) setupDefaultEmitter(origin, destination string) erro
 	return nil
 }
 
-func (t *TrafficController) initializeMetrics(origin, destination string) (*metricbatcher.MetricBatcher, error) {
+func (t *TrafficController) initializeMetrics(origin, destination string) (*healthendpoint.MirroredBatcher, error) {
 	err := t.setupDefaultEmitter(origin, destination)
 	if err != nil {
 		// Legacy holdover.  We would prefer to panic, rather than just throwing our metrics
@@ -237,7 +253,7 @@ func (t *TrafficController) initializeMetrics(origin, destination string) (*metr
 	// Copied from dropsonde.initialize(), since we stopped using
 	// dropsonde.Initialize but needed it to continue operating the same.
 	sender := metric_sender.NewMetricSender(dropsonde.DefaultEmitter)
-	batcher := metricbatcher.New(sender, time.Second)
+	batcher := t.mirror.Batcher(metricbatcher.New(sender, time.Second))
 	metrics.Initialize(sender, batcher)
 	logs.Initialize(log_sender.NewLogSender(dropsonde.DefaultEmitter))
 	envelopes.Initialize(envelope_sender.NewEnvelopeSender(dropsonde.DefaultEmitter))
@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+)
+
+const (
+	OrganizationScope = "organizations"
+	SpaceScope        = "spaces"
+)
+
+// AppLister returns the GUIDs of the apps in an organization or space that
+// are visible to the owner of the token. Like LogAccessAuthorizer it returns
+// the status code from Cloud Controller.
+type AppLister func(authToken, scope, guid string) ([]string, int, error)
+
+func NewAppLister(c *http.Client, apiHost string) AppLister {
+	return AppLister(func(authToken, scope, guid string) ([]string, int, error) {
+		if authToken == "" {
+			log.Printf(NO_AUTH_TOKEN_PROVIDED_ERROR_MESSAGE)
+			return nil, http.StatusUnauthorized, errors.New(NO_AUTH_TOKEN_PROVIDED_ERROR_MESSAGE)
+		}
+
+		var filter string
+		switch scope {
+		case OrganizationScope:
+			filter = "organization_guids"
+		case SpaceScope:
+			filter = "space_guids"
+		default:
+			return nil, http.StatusNotFound, fmt.Errorf("unknown scope: %s", scope)
+		}
+
+		// Listing apps does not fail for organizations or spaces the user
+		// cannot see so their visibility is checked first.
+		status, err := ccGet(c, apiHost, "/v3/"+scope+"/"+url.PathEscape(guid), authToken, nil)
+		if err != nil {
+			return nil, status, err
+		}
+
+		var appIDs []string
+		path := "/v3/apps?" + url.Values{
+			filter:     []string{guid},
+			"per_page": []string{"5000"},
+		}.Encode()
+		for path != "" {
+			var page appsPage
+			status, err := ccGet(c, apiHost, path, authToken, &page)
+			if err != nil {
+				return nil, status, err
+			}
+
+			for _, r := range page.Resources {
+				appIDs = append(appIDs, r.GUID)
+			}
+
+			path, err = page.nextPath()
+			if err != nil {
+				return nil, http.StatusInternalServerError, err
+			}
+		}
+
+		return appIDs, http.StatusOK, nil
+	})
+}
+
+type appsPage struct {
+	Resources []struct {
+		GUID string `json:"guid"`
+	} `json:"resources"`
+	Pagination struct {
+		Next *struct {
+			Href string `json:"href"`
+		} `json:"next"`
+	} `json:"pagination"`
+}
+
+// nextPath returns the path of the next page relative to the API host. The
+// href Cloud Controller returns uses its external URL.
+func (p appsPage) nextPath() (string, error) {
+	if p.Pagination.Next == nil || p.Pagination.Next.Href == "" {
+		return "", nil
+	}
+
+	u, err := url.Parse(p.Pagination.Next.Href)
+	if err != nil {
+		return "", err
+	}
+
+	return u.RequestURI(), nil
+}
+
+func ccGet(c *http.Client, apiHost, path, authToken string, result interface{}) (int, error) {
+	req, err := http.NewRequest("GET", apiHost+path, nil)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	req.Header.Set("Authorization", authToken)
+
+	res, err := c.Do(req)
+	if err != nil {
+		log.Printf("Could not get app information: [%s]", err)
+		return http.StatusInternalServerError, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		log.Printf("Non 200 response from CC API: %d for %s", res.StatusCode, path)
+		return res.StatusCode, errors.New(http.StatusText(res.StatusCode))
+	}
+
+	if result == nil {
+		return http.StatusOK, nil
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(result); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
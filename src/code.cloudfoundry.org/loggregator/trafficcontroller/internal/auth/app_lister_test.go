@@ -0,0 +1,106 @@
+package auth_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"code.cloudfoundry.org/loggregator/trafficcontroller/internal/auth"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AppLister", func() {
+	var (
+		server   *httptest.Server
+		requests []*http.Request
+		lister   auth.AppLister
+	)
+
+	BeforeEach(func() {
+		requests = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r)
+
+			if r.Header.Get("Authorization") != "bearer valid" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			switch r.URL.Path {
+			case "/v3/organizations/my-org", "/v3/spaces/my-space":
+				w.Write([]byte(`{}`))
+			case "/v3/organizations/other-org":
+				w.WriteHeader(http.StatusNotFound)
+			case "/v3/apps":
+				if r.URL.Query().Get("page") == "2" {
+					w.Write([]byte(`{
+						"pagination": {"next": null},
+						"resources": [{"guid": "app-3"}]
+					}`))
+					return
+				}
+				fmt.Fprintf(w, `{
+					"pagination": {"next": {"href": "https://api.example.com/v3/apps?page=2&%s"}},
+					"resources": [{"guid": "app-1"}, {"guid": "app-2"}]
+				}`, r.URL.RawQuery)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+
+		lister = auth.NewAppLister(http.DefaultClient, server.URL)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("lists every page of apps in an organization", func() {
+		appIDs, status, err := lister("bearer valid", auth.OrganizationScope, "my-org")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(appIDs).To(Equal([]string{"app-1", "app-2", "app-3"}))
+
+		Expect(requests).To(HaveLen(3))
+		Expect(requests[1].URL.Query().Get("organization_guids")).To(Equal("my-org"))
+		Expect(requests[2].URL.Query().Get("page")).To(Equal("2"))
+	})
+
+	It("lists the apps in a space", func() {
+		appIDs, status, err := lister("bearer valid", auth.SpaceScope, "my-space")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(appIDs).To(HaveLen(3))
+		Expect(requests[1].URL.Query().Get("space_guids")).To(Equal("my-space"))
+	})
+
+	It("returns the status when the organization is not visible", func() {
+		_, status, err := lister("bearer valid", auth.OrganizationScope, "other-org")
+		Expect(err).To(HaveOccurred())
+		Expect(status).To(Equal(http.StatusNotFound))
+		Expect(requests).To(HaveLen(1))
+	})
+
+	It("returns the status when the token is rejected", func() {
+		_, status, err := lister("bearer invalid", auth.SpaceScope, "my-space")
+		Expect(err).To(HaveOccurred())
+		Expect(status).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("does not allow requests without a token", func() {
+		_, status, err := lister("", auth.SpaceScope, "my-space")
+		Expect(status).To(Equal(http.StatusUnauthorized))
+		Expect(err).To(Equal(errors.New(auth.NO_AUTH_TOKEN_PROVIDED_ERROR_MESSAGE)))
+		Expect(requests).To(BeEmpty())
+	})
+
+	It("returns not found for unknown scopes", func() {
+		_, status, err := lister("bearer valid", "apps", "my-app")
+		Expect(err).To(HaveOccurred())
+		Expect(status).To(Equal(http.StatusNotFound))
+		Expect(requests).To(BeEmpty())
+	})
+})
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// UserID returns the ID of the user or client the token was issued to. The
+// signature of the token is not checked so the ID may only be trusted once
+// Cloud Controller or UAA accepted the token.
+func UserID(authToken string) (string, error) {
+	if authToken == "" {
+		return "", errors.New(NO_AUTH_TOKEN_PROVIDED_ERROR_MESSAGE)
+	}
+
+	token := authToken
+	if i := strings.IndexByte(token, ' '); i >= 0 {
+		token = token[i+1:]
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errors.New(INVALID_AUTH_TOKEN_ERROR_MESSAGE)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return "", errors.New(INVALID_AUTH_TOKEN_ERROR_MESSAGE)
+	}
+
+	var claims struct {
+		UserID  string `json:"user_id"`
+		Subject string `json:"sub"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return "", errors.New(INVALID_AUTH_TOKEN_ERROR_MESSAGE)
+	}
+
+	// Client credentials tokens have no user ID. Their subject is the
+	// client ID.
+	if claims.UserID != "" {
+		return claims.UserID, nil
+	}
+	if claims.Subject != "" {
+		return claims.Subject, nil
+	}
+
+	return "", errors.New(INVALID_AUTH_TOKEN_ERROR_MESSAGE)
+}
@@ -0,0 +1,45 @@
+package auth_test
+
+import (
+	"encoding/base64"
+
+	"code.cloudfoundry.org/loggregator/trafficcontroller/internal/auth"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UserID", func() {
+	token := func(claims string) string {
+		return "bearer header." +
+			base64.RawURLEncoding.EncodeToString([]byte(claims)) +
+			".signature"
+	}
+
+	It("returns the user ID of the token", func() {
+		id, err := auth.UserID(token(`{"user_id": "user-1", "sub": "user-1"}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(id).To(Equal("user-1"))
+	})
+
+	It("returns the subject of a client token", func() {
+		id, err := auth.UserID(token(`{"sub": "my-client"}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(id).To(Equal("my-client"))
+	})
+
+	It("returns an error without a token", func() {
+		_, err := auth.UserID("")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("returns an error when the token is not a JWT", func() {
+		_, err := auth.UserID("bearer token")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("returns an error when the token has no subject", func() {
+		_, err := auth.UserID(token(`{"scope": ["openid"]}`))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -29,8 +29,9 @@ type DopplerProxy struct {
 
 	health Health
 
-	firehoseConnMetric  *metricemitter.Gauge
-	appStreamConnMetric *metricemitter.Gauge
+	firehoseConnMetric       *metricemitter.Gauge
+	scopedFirehoseConnMetric *metricemitter.Gauge
+	appStreamConnMetric      *metricemitter.Gauge
 }
 
 type grpcConnector interface {
@@ -42,11 +43,13 @@ type grpcConnector interface {
 type MetricClient interface {
 	NewCounter(string, ...metricemitter.MetricOption) *metricemitter.Counter
 	NewGauge(string, string, ...metricemitter.MetricOption) *metricemitter.Gauge
+	NewTimer(string, ...metricemitter.MetricOption) *metricemitter.Timer
 }
 
 func NewDopplerProxy(
 	logAuthorizer auth.LogAccessAuthorizer,
 	adminAuthorizer auth.AdminAccessAuthorizer,
+	appLister auth.AppLister,
 	grpcConn grpcConnector,
 	cookieDomain string,
 	timeout time.Duration,
@@ -58,6 +61,12 @@ func NewDopplerProxy(
 		metricemitter.WithVersion(2, 0),
 	)
 
+	// metric-documentation-v2: (doppler_proxy.scoped_firehoses) Number of
+	// open organization and space scoped firehose streams
+	scopedFirehoseConnMetric := m.NewGauge("doppler_proxy.scoped_firehoses", "connections",
+		metricemitter.WithVersion(2, 0),
+	)
+
 	// metric-documentation-v2: (doppler_proxy.app_streams) Number of open app streams
 	appStreamConnMetric := m.NewGauge("doppler_proxy.app_streams", "connections",
 		metricemitter.WithVersion(2, 0),
@@ -85,23 +94,34 @@ func NewDopplerProxy(
 	firehoseHandler := NewFirehoseHandler(grpcConn, wsServer, m)
 	r.Handle("/firehose/{subID}", adminAccessMiddleware.Wrap(firehoseHandler))
 
+	scopedFirehoseHandler := NewScopedFirehoseHandler(grpcConn, wsServer, appLister, logAuthorizer, m)
+	r.Handle("/{scope:organizations|spaces}/{guid}/firehose/{subID}", scopedFirehoseHandler)
+
 	d := &DopplerProxy{
-		Router:              r,
-		health:              health,
-		firehoseConnMetric:  firehoseConnMetric,
-		appStreamConnMetric: appStreamConnMetric,
+		Router:                   r,
+		health:                   health,
+		firehoseConnMetric:       firehoseConnMetric,
+		scopedFirehoseConnMetric: scopedFirehoseConnMetric,
+		appStreamConnMetric:      appStreamConnMetric,
 	}
 
-	go d.emitMetrics(firehoseHandler, streamHandler)
+	go d.emitMetrics(firehoseHandler, scopedFirehoseHandler, streamHandler)
 
 	return d
 }
 
-func (d *DopplerProxy) emitMetrics(firehose *FirehoseHandler, stream *StreamHandler) {
+func (d *DopplerProxy) emitMetrics(
+	firehose *FirehoseHandler,
+	scopedFirehose *ScopedFirehoseHandler,
+	stream *StreamHandler,
+) {
 	for range time.Tick(MetricsInterval) {
 		d.firehoseConnMetric.Set(float64(firehose.Count()))
 		d.health.Set("firehoseStreamCount", float64(firehose.Count()))
 
+		d.scopedFirehoseConnMetric.Set(float64(scopedFirehose.Count()))
+		d.health.Set("scopedFirehoseStreamCount", float64(scopedFirehose.Count()))
+
 		d.appStreamConnMetric.Set(float64(stream.Count()))
 		d.health.Set("appStreamCount", float64(stream.Count()))
 	}
@@ -26,6 +26,7 @@ var _ = Describe("DopplerProxy", func() {
 	var (
 		auth         LogAuthorizer
 		adminAuth    AdminAuthorizer
+		appLister    *SpyAppLister
 		dopplerProxy *proxy.DopplerProxy
 		recorder     *httptest.ResponseRecorder
 
@@ -39,6 +40,7 @@ var _ = Describe("DopplerProxy", func() {
 	BeforeEach(func() {
 		auth = LogAuthorizer{Result: AuthorizerResult{Status: http.StatusOK}}
 		adminAuth = AdminAuthorizer{Result: AuthorizerResult{Status: http.StatusOK}}
+		appLister = &SpyAppLister{}
 
 		mockGrpcConnector = newMockGrpcConnector()
 		mockDopplerStreamClient = newMockReceiver()
@@ -50,6 +52,7 @@ var _ = Describe("DopplerProxy", func() {
 		dopplerProxy = proxy.NewDopplerProxy(
 			auth.Authorize,
 			adminAuth.Authorize,
+			appLister.List,
 			mockGrpcConnector,
 			"cookieDomain",
 			50*time.Millisecond,
@@ -70,7 +73,7 @@ var _ = Describe("DopplerProxy", func() {
 	})
 
 	Describe("metrics", func() {
-		It("emits latency value metric for recentlogs request", func() {
+		It("emits latency timer metric for recentlogs request", func() {
 			mockGrpcConnector.RecentLogsOutput.Ret0 <- nil
 			req, _ := http.NewRequest("GET", "/apps/appID123/recentlogs", nil)
 			metricName := "doppler_proxy.recent_logs_latency"
@@ -78,9 +81,12 @@ var _ = Describe("DopplerProxy", func() {
 
 			dopplerProxy.ServeHTTP(recorder, req)
 
-			metricValue := mockSender.GetValue(metricName)
 			elapsed := float64(time.Since(requestStart)) / float64(time.Millisecond)
-			Expect(metricValue).To(BeNumerically("<", elapsed))
+			Expect(mockSender.GetCount(metricName)).To(Equal(uint64(1)))
+			envs := mockSender.GetEnvelopes(metricName)
+			Expect(envs).To(HaveLen(1))
+			metrics := envs[0].GetGauge().GetMetrics()
+			Expect(metrics[metricName+".p99"].GetValue()).To(BeNumerically("<", elapsed))
 		})
 
 		It("emits latency value metric for containermetrics request", func() {
@@ -45,27 +45,9 @@ func (h *FirehoseHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	var filter *plumbing.Filter
-	switch r.URL.Query().Get("filter-type") {
-	case "logs":
-		filter = &plumbing.Filter{
-			Message: &plumbing.Filter_Log{
-				Log: &plumbing.LogFilter{},
-			},
-		}
-	case "metrics":
-		filter = &plumbing.Filter{
-			Message: &plumbing.Filter_Metric{
-				Metric: &plumbing.MetricFilter{},
-			},
-		}
-	default:
-		filter = nil
-	}
-
 	client, err := h.grpcConn.Subscribe(ctx, &plumbing.SubscriptionRequest{
 		ShardID: subID,
-		Filter:  filter,
+		Filter:  typeFilter(r),
 	})
 	if err != nil {
 		w.WriteHeader(http.StatusServiceUnavailable)
@@ -79,3 +61,24 @@ func (h *FirehoseHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 func (h *FirehoseHandler) Count() int64 {
 	return atomic.LoadInt64(&h.counter)
 }
+
+// typeFilter returns a filter for the envelope type given by the filter-type
+// query parameter or nil if all types are requested.
+func typeFilter(r *http.Request) *plumbing.Filter {
+	switch r.URL.Query().Get("filter-type") {
+	case "logs":
+		return &plumbing.Filter{
+			Message: &plumbing.Filter_Log{
+				Log: &plumbing.LogFilter{},
+			},
+		}
+	case "metrics":
+		return &plumbing.Filter{
+			Message: &plumbing.Filter_Metric{
+				Metric: &plumbing.MetricFilter{},
+			},
+		}
+	default:
+		return nil
+	}
+}
@@ -24,6 +24,7 @@ var _ = Describe("FirehoseHandler", func() {
 	var (
 		auth       LogAuthorizer
 		adminAuth  AdminAuthorizer
+		appLister  *SpyAppLister
 		recorder   *httptest.ResponseRecorder
 		connector  *SpyGRPCConnector
 		mockSender *testhelper.SpyMetricClient
@@ -34,6 +35,7 @@ var _ = Describe("FirehoseHandler", func() {
 		connector = newSpyGRPCConnector(nil)
 
 		adminAuth = AdminAuthorizer{Result: AuthorizerResult{Status: http.StatusOK}}
+		appLister = &SpyAppLister{}
 		auth = LogAuthorizer{Result: AuthorizerResult{Status: http.StatusOK}}
 
 		recorder = httptest.NewRecorder()
@@ -45,6 +47,7 @@ var _ = Describe("FirehoseHandler", func() {
 		handler := proxy.NewDopplerProxy(
 			auth.Authorize,
 			adminAuth.Authorize,
+			appLister.List,
 			connector,
 			"cookieDomain",
 			50*time.Millisecond,
@@ -68,6 +71,7 @@ var _ = Describe("FirehoseHandler", func() {
 		_ = proxy.NewDopplerProxy(
 			auth.Authorize,
 			adminAuth.Authorize,
+			appLister.List,
 			connector,
 			"cookieDomain",
 			50*time.Millisecond,
@@ -94,6 +98,7 @@ var _ = Describe("FirehoseHandler", func() {
 		_ = proxy.NewDopplerProxy(
 			auth.Authorize,
 			adminAuth.Authorize,
+			appLister.List,
 			connector,
 			"cookieDomain",
 			50*time.Millisecond,
@@ -120,6 +125,7 @@ var _ = Describe("FirehoseHandler", func() {
 		handler := proxy.NewDopplerProxy(
 			auth.Authorize,
 			adminAuth.Authorize,
+			appLister.List,
 			connector,
 			"cookieDomain",
 			50*time.Millisecond,
@@ -145,6 +151,7 @@ var _ = Describe("FirehoseHandler", func() {
 		handler := proxy.NewDopplerProxy(
 			auth.Authorize,
 			adminAuth.Authorize,
+			appLister.List,
 			connector,
 			"cookieDomain",
 			50*time.Millisecond,
@@ -164,6 +171,7 @@ var _ = Describe("FirehoseHandler", func() {
 		handler := proxy.NewDopplerProxy(
 			auth.Authorize,
 			adminAuth.Authorize,
+			appLister.List,
 			connector,
 			"cookieDomain",
 			50*time.Millisecond,
@@ -191,6 +199,7 @@ var _ = Describe("FirehoseHandler", func() {
 		handler := proxy.NewDopplerProxy(
 			auth.Authorize,
 			adminAuth.Authorize,
+			appLister.List,
 			connector,
 			"cookieDomain",
 			50*time.Millisecond,
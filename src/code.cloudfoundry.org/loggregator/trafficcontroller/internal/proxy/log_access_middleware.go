@@ -25,21 +25,26 @@ func (m *LogAccessMiddleware) Wrap(h http.Handler) http.Handler {
 
 		status, _ := m.authorize(authToken, appID)
 		if status != http.StatusOK {
-			switch status {
-			case http.StatusUnauthorized:
-				w.WriteHeader(status)
-				w.Header().Set("WWW-Authenticate", "Basic")
-			case http.StatusForbidden, http.StatusNotFound:
-				status = http.StatusNotFound
-			default:
-				status = http.StatusInternalServerError
-			}
-
-			w.WriteHeader(status)
-
+			writeAccessError(w, status)
 			return
 		}
 
 		h.ServeHTTP(w, r)
 	})
 }
+
+// writeAccessError maps the status returned by Cloud Controller to the
+// response status. Resources the user may not see are reported as not found.
+func writeAccessError(w http.ResponseWriter, status int) {
+	switch status {
+	case http.StatusUnauthorized:
+		w.WriteHeader(status)
+		w.Header().Set("WWW-Authenticate", "Basic")
+	case http.StatusForbidden, http.StatusNotFound:
+		status = http.StatusNotFound
+	default:
+		status = http.StatusInternalServerError
+	}
+
+	w.WriteHeader(status)
+}
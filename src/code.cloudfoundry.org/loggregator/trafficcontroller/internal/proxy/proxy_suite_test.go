@@ -41,6 +41,7 @@ type LogAuthorizer struct {
 
 var _ = BeforeSuite(func() {
 	proxy.MetricsInterval = 100 * time.Millisecond
+	proxy.AppsRefreshInterval = 100 * time.Millisecond
 })
 
 func (a *LogAuthorizer) Authorize(authToken string, target string) (int, error) {
@@ -61,6 +62,36 @@ func (a *AdminAuthorizer) Authorize(authToken string) (bool, error) {
 	return a.Result.Status == http.StatusOK, errors.New(a.Result.ErrorMessage)
 }
 
+type SpyAppLister struct {
+	mu     sync.Mutex
+	token  string
+	scope  string
+	guid   string
+	appIDs []string
+	status int
+}
+
+func (s *SpyAppLister) setResult(appIDs []string, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.appIDs = appIDs
+	s.status = status
+}
+
+func (s *SpyAppLister) List(authToken, scope, guid string) ([]string, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = authToken
+	s.scope = scope
+	s.guid = guid
+
+	if s.status != http.StatusOK {
+		return nil, s.status, errors.New(http.StatusText(s.status))
+	}
+
+	return s.appIDs, s.status, nil
+}
+
 func startListener(addr string) net.Listener {
 	var lis net.Listener
 	f := func() error {
@@ -95,6 +126,7 @@ type subscribeRequest struct {
 type SpyGRPCConnector struct {
 	mu               sync.Mutex
 	subscriptions    *subscribeRequest
+	allSubscriptions []*subscribeRequest
 	subscriptionsErr error
 	recentLogs       *recentLogsRequest
 }
@@ -112,10 +144,18 @@ func (s *SpyGRPCConnector) Subscribe(ctx context.Context, req *plumbing.Subscrip
 		ctx:     ctx,
 		request: req,
 	}
+	s.allSubscriptions = append(s.allSubscriptions, s.subscriptions)
 
 	return func() ([]byte, error) { return []byte("a-slice"), s.subscriptionsErr }, nil
 }
 
+func (s *SpyGRPCConnector) subscribeRequests() []*subscribeRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.allSubscriptions
+}
+
 func (s *SpyGRPCConnector) ContainerMetrics(ctx context.Context, appID string) [][]byte {
 	return nil
 }
@@ -14,7 +14,7 @@ import (
 type RecentLogsHandler struct {
 	grpcConn      grpcConnector
 	timeout       time.Duration
-	latencyMetric *metricemitter.Gauge
+	latencyMetric *metricemitter.Timer
 }
 
 func NewRecentLogsHandler(
@@ -22,9 +22,9 @@ func NewRecentLogsHandler(
 	t time.Duration,
 	m MetricClient,
 ) *RecentLogsHandler {
-	// metric-documentation-v2: (doppler_proxy.recent_logs_latency) Measures
-	// amount of time to serve the request for recent logs
-	latencyMetric := m.NewGauge("doppler_proxy.recent_logs_latency", "ms",
+	// metric-documentation-v2: (doppler_proxy.recent_logs_latency) Count,
+	// sum and quantiles of the time in ms to serve requests for recent logs
+	latencyMetric := m.NewTimer("doppler_proxy.recent_logs_latency",
 		metricemitter.WithVersion(2, 0),
 	)
 
@@ -36,11 +36,7 @@ func NewRecentLogsHandler(
 }
 
 func (h *RecentLogsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	startTime := time.Now()
-	defer func() {
-		elapsedMillisecond := float64(time.Since(startTime)) / float64(time.Millisecond)
-		h.latencyMetric.Set(elapsedMillisecond)
-	}()
+	defer h.latencyMetric.ObserveSince(time.Now())
 
 	appID := mux.Vars(r)["appID"]
 
@@ -0,0 +1,416 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/metricemitter"
+	"code.cloudfoundry.org/loggregator/plumbing"
+	"code.cloudfoundry.org/loggregator/trafficcontroller/internal/auth"
+
+	"github.com/gorilla/mux"
+)
+
+// AppsRefreshInterval is how often the apps of a scoped firehose are listed
+// again to pick up new and deleted apps and revoked access.
+var AppsRefreshInterval = time.Minute
+
+// AuthorizeTimeout is how long the apps of a scoped firehose may take to be
+// authorized before the request fails with a 503.
+var AuthorizeTimeout = 10 * time.Second
+
+// maxAuthorizeWorkers limits the number of concurrent app authorizations
+// against the Cloud Controller per scoped firehose.
+const maxAuthorizeWorkers = 10
+
+// ScopedFirehoseHandler streams the envelopes of every app in an
+// organization or space the user is allowed to read.
+type ScopedFirehoseHandler struct {
+	server       *WebSocketServer
+	grpcConn     grpcConnector
+	listApps     auth.AppLister
+	authorize    auth.LogAccessAuthorizer
+	counter      int64
+	egressMetric *metricemitter.Counter
+}
+
+func NewScopedFirehoseHandler(
+	grpcConn grpcConnector,
+	w *WebSocketServer,
+	listApps auth.AppLister,
+	authorize auth.LogAccessAuthorizer,
+	m MetricClient,
+) *ScopedFirehoseHandler {
+	// metric-documentation-v2: (egress) Number of envelopes egressed via
+	// organization and space scoped firehoses.
+	egressMetric := m.NewCounter("egress",
+		metricemitter.WithVersion(2, 0),
+		metricemitter.WithTags(
+			map[string]string{"endpoint": "scoped_firehose"},
+		),
+	)
+
+	return &ScopedFirehoseHandler{
+		grpcConn:     grpcConn,
+		server:       w,
+		listApps:     listApps,
+		authorize:    authorize,
+		egressMetric: egressMetric,
+	}
+}
+
+func (h *ScopedFirehoseHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	scope, guid, subID := vars["scope"], vars["guid"], vars["subID"]
+	authToken := getAuthToken(r)
+
+	authorized := make(map[string]bool)
+	appIDs, status := h.authorizedApps(authToken, scope, guid, authorized)
+	switch status {
+	case http.StatusOK:
+	case http.StatusServiceUnavailable:
+		w.WriteHeader(status)
+		return
+	default:
+		writeAccessError(w, status)
+		return
+	}
+
+	// Subscriptions of different users must not share a shard ID or Doppler
+	// would split the envelopes of apps only one of them may read between
+	// them.
+	userID, err := auth.UserID(authToken)
+	if err != nil {
+		log.Printf("failed to read user ID for scoped firehose: %s", err)
+		writeAccessError(w, http.StatusUnauthorized)
+		return
+	}
+
+	atomic.AddInt64(&h.counter, 1)
+	defer atomic.AddInt64(&h.counter, -1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sub := newScopedSubscription(
+		ctx,
+		h.grpcConn,
+		fmt.Sprintf("%s/%s/%s/%s", scope, guid, userID, subID),
+		typeFilter(r),
+	)
+	if err := sub.update(appIDs); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		log.Printf("error occurred when subscribing to doppler: %s", err)
+		return
+	}
+
+	go h.refresh(ctx, sub, authToken, scope, guid, authorized)
+
+	h.server.serveWS(w, r, sub.next, h.egressMetric)
+}
+
+func (h *ScopedFirehoseHandler) Count() int64 {
+	return atomic.LoadInt64(&h.counter)
+}
+
+func (h *ScopedFirehoseHandler) refresh(
+	ctx context.Context,
+	sub *scopedSubscription,
+	authToken, scope, guid string,
+	authorized map[string]bool,
+) {
+	t := time.NewTicker(AppsRefreshInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		appIDs, status := h.authorizedApps(authToken, scope, guid, authorized)
+		switch status {
+		case http.StatusOK:
+			if err := sub.update(appIDs); err != nil {
+				log.Printf("failed to update scoped firehose for %s %s: %s", scope, guid, err)
+			}
+		case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+			sub.fail(fmt.Errorf("access to %s %s revoked", scope, guid))
+			return
+		default:
+			log.Printf("failed to refresh apps for %s %s, keeping current apps", scope, guid)
+		}
+	}
+}
+
+// authorizedApps lists the apps in the organization or space and returns the
+// ones the user may read logs for. Apps in authorized have already been
+// checked and are not checked again. The map is updated in place. The other
+// apps are checked concurrently. If any check fails or they do not finish
+// within AuthorizeTimeout, a 503 is returned and the map is left as is.
+func (h *ScopedFirehoseHandler) authorizedApps(
+	authToken, scope, guid string,
+	authorized map[string]bool,
+) ([]string, int) {
+	appIDs, status, err := h.listApps(authToken, scope, guid)
+	switch status {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+		return nil, status
+	default:
+		log.Printf("failed to list apps for %s %s: %d %v", scope, guid, status, err)
+		return nil, http.StatusServiceUnavailable
+	}
+
+	var unchecked []string
+	for _, id := range appIDs {
+		if !authorized[id] {
+			unchecked = append(unchecked, id)
+		}
+	}
+
+	checked, status := h.authorizeApps(authToken, unchecked)
+	if status != http.StatusOK {
+		return nil, status
+	}
+
+	for _, id := range checked {
+		authorized[id] = true
+	}
+
+	listed := make(map[string]bool, len(appIDs))
+	var allowed []string
+	for _, id := range appIDs {
+		listed[id] = true
+		if authorized[id] {
+			allowed = append(allowed, id)
+		}
+	}
+
+	for id := range authorized {
+		if !listed[id] {
+			delete(authorized, id)
+		}
+	}
+
+	return allowed, http.StatusOK
+}
+
+type authorizeResult struct {
+	appID  string
+	status int
+	err    error
+}
+
+// authorizeApps checks the given apps concurrently and returns the ones the
+// user may read logs for. Apps the user may not see are skipped.
+func (h *ScopedFirehoseHandler) authorizeApps(authToken string, appIDs []string) ([]string, int) {
+	if len(appIDs) == 0 {
+		return nil, http.StatusOK
+	}
+
+	ids := make(chan string, len(appIDs))
+	for _, id := range appIDs {
+		ids <- id
+	}
+	close(ids)
+
+	done := make(chan struct{})
+	defer close(done)
+
+	results := make(chan authorizeResult, len(appIDs))
+	workers := maxAuthorizeWorkers
+	if len(appIDs) < workers {
+		workers = len(appIDs)
+	}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for id := range ids {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				status, err := h.authorize(authToken, id)
+				results <- authorizeResult{appID: id, status: status, err: err}
+			}
+		}()
+	}
+
+	timer := time.NewTimer(AuthorizeTimeout)
+	defer timer.Stop()
+
+	var allowed []string
+	for range appIDs {
+		select {
+		case r := <-results:
+			switch r.status {
+			case http.StatusOK:
+				allowed = append(allowed, r.appID)
+			case http.StatusUnauthorized:
+				return nil, r.status
+			case http.StatusForbidden, http.StatusNotFound:
+			default:
+				log.Printf("failed to authorize app %s: %d %v", r.appID, r.status, r.err)
+				return nil, http.StatusServiceUnavailable
+			}
+		case <-timer.C:
+			log.Printf("timed out authorizing %d apps", len(appIDs))
+			return nil, http.StatusServiceUnavailable
+		}
+	}
+
+	return allowed, http.StatusOK
+}
+
+// scopedSubscription is a subscription to Doppler for a set of apps that
+// can change while it is being read. Changing the apps opens a new
+// subscription before the previous one is closed.
+type scopedSubscription struct {
+	ctx      context.Context
+	cancel   func()
+	grpcConn grpcConnector
+	shardID  string
+	filter   *plumbing.Filter
+	data     chan []byte
+
+	mu        sync.Mutex
+	err       error
+	appIDs    []string
+	cancelSub func()
+}
+
+func newScopedSubscription(
+	ctx context.Context,
+	grpcConn grpcConnector,
+	shardID string,
+	filter *plumbing.Filter,
+) *scopedSubscription {
+	if filter == nil {
+		filter = &plumbing.Filter{}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	return &scopedSubscription{
+		ctx:      ctx,
+		cancel:   cancel,
+		grpcConn: grpcConn,
+		shardID:  shardID,
+		filter:   filter,
+		data:     make(chan []byte),
+	}
+}
+
+// update subscribes to the given apps if they differ from the current ones.
+// No subscription is held for an empty set of apps as Doppler would treat
+// it as a firehose subscription.
+func (s *scopedSubscription) update(appIDs []string) error {
+	sort.Strings(appIDs)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancelSub != nil && equalStrings(s.appIDs, appIDs) {
+		return nil
+	}
+
+	if len(appIDs) == 0 {
+		s.stop()
+		s.appIDs = nil
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	recv, err := s.grpcConn.Subscribe(ctx, &plumbing.SubscriptionRequest{
+		ShardID: s.shardID,
+		Filter: &plumbing.Filter{
+			AppIDs:  appIDs,
+			Message: s.filter.Message,
+		},
+	})
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	go s.read(ctx, recv)
+
+	s.stop()
+	s.appIDs = appIDs
+	s.cancelSub = cancel
+
+	return nil
+}
+
+func (s *scopedSubscription) stop() {
+	if s.cancelSub != nil {
+		s.cancelSub()
+		s.cancelSub = nil
+	}
+}
+
+func (s *scopedSubscription) read(ctx context.Context, recv func() ([]byte, error)) {
+	for {
+		data, err := recv()
+		if err != nil {
+			if ctx.Err() == nil {
+				s.fail(err)
+			}
+			return
+		}
+
+		select {
+		case s.data <- data:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// fail ends the subscription. next returns the given error from then on.
+func (s *scopedSubscription) fail(err error) {
+	s.mu.Lock()
+	if s.err == nil {
+		s.err = err
+	}
+	s.mu.Unlock()
+
+	s.cancel()
+}
+
+func (s *scopedSubscription) next() ([]byte, error) {
+	select {
+	case data := <-s.data:
+		return data, nil
+	case <-s.ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, errors.New("scoped firehose closed")
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
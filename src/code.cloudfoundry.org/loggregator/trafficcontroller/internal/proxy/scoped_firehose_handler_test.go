@@ -0,0 +1,310 @@
+package proxy_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/metricemitter/testhelper"
+	"code.cloudfoundry.org/loggregator/plumbing"
+	"code.cloudfoundry.org/loggregator/trafficcontroller/internal/auth"
+	"code.cloudfoundry.org/loggregator/trafficcontroller/internal/proxy"
+
+	"github.com/gorilla/websocket"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ScopedFirehoseHandler", func() {
+	var (
+		mu         sync.Mutex
+		appStatus  map[string]int
+		appDelay   time.Duration
+		adminAuth  AdminAuthorizer
+		appLister  *SpyAppLister
+		connector  *SpyGRPCConnector
+		mockSender *testhelper.SpyMetricClient
+		recorder   *httptest.ResponseRecorder
+		handler    http.Handler
+	)
+
+	authorize := func(authToken, appID string) (int, error) {
+		mu.Lock()
+		delay := appDelay
+		mu.Unlock()
+		time.Sleep(delay)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if status, ok := appStatus[appID]; ok {
+			return status, nil
+		}
+		return http.StatusOK, nil
+	}
+
+	setAppStatus := func(appID string, status int) {
+		mu.Lock()
+		defer mu.Unlock()
+		appStatus[appID] = status
+	}
+
+	BeforeEach(func() {
+		appStatus = make(map[string]int)
+		appDelay = 0
+		adminAuth = AdminAuthorizer{Result: AuthorizerResult{Status: http.StatusUnauthorized}}
+		appLister = &SpyAppLister{}
+		appLister.setResult([]string{"app-1", "app-2", "app-3"}, http.StatusOK)
+		connector = newSpyGRPCConnector(nil)
+		mockSender = testhelper.NewMetricClient()
+		recorder = httptest.NewRecorder()
+
+		handler = proxy.NewDopplerProxy(
+			authorize,
+			adminAuth.Authorize,
+			appLister.List,
+			connector,
+			"cookieDomain",
+			50*time.Millisecond,
+			mockSender,
+			newMockHealth(),
+		)
+	})
+
+	It("subscribes to the apps the user may read in an organization", func() {
+		setAppStatus("app-2", http.StatusForbidden)
+
+		req, _ := http.NewRequest("GET", "/organizations/org-guid/firehose/sub-id", nil)
+		req.Header.Add("Authorization", userToken("user-1"))
+
+		handler.ServeHTTP(recorder, req)
+
+		Expect(appLister.token).To(Equal(userToken("user-1")))
+		Expect(appLister.scope).To(Equal(auth.OrganizationScope))
+		Expect(appLister.guid).To(Equal("org-guid"))
+		Expect(connector.subscriptions.request).To(Equal(&plumbing.SubscriptionRequest{
+			ShardID: "organizations/org-guid/user-1/sub-id",
+			Filter: &plumbing.Filter{
+				AppIDs: []string{"app-1", "app-3"},
+			},
+		}))
+	})
+
+	It("subscribes to the apps in a space with a type filter", func() {
+		req, _ := http.NewRequest("GET", "/spaces/space-guid/firehose/sub-id?filter-type=logs", nil)
+		req.Header.Add("Authorization", userToken("user-1"))
+
+		handler.ServeHTTP(recorder, req)
+
+		Expect(appLister.scope).To(Equal(auth.SpaceScope))
+		Expect(connector.subscriptions.request).To(Equal(&plumbing.SubscriptionRequest{
+			ShardID: "spaces/space-guid/user-1/sub-id",
+			Filter: &plumbing.Filter{
+				AppIDs: []string{"app-1", "app-2", "app-3"},
+				Message: &plumbing.Filter_Log{
+					Log: &plumbing.LogFilter{},
+				},
+			},
+		}))
+	})
+
+	It("gives users with the same subscription ID their own shard", func() {
+		for _, user := range []string{"user-1", "user-2"} {
+			req, _ := http.NewRequest("GET", "/organizations/org-guid/firehose/sub-id", nil)
+			req.Header.Add("Authorization", userToken(user))
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		}
+
+		subs := connector.subscribeRequests()
+		Expect(subs).To(HaveLen(2))
+		Expect(subs[0].request.ShardID).To(Equal("organizations/org-guid/user-1/sub-id"))
+		Expect(subs[1].request.ShardID).To(Equal("organizations/org-guid/user-2/sub-id"))
+	})
+
+	It("returns unauthorized when the token has no user ID", func() {
+		req, _ := http.NewRequest("GET", "/spaces/space-guid/firehose/sub-id", nil)
+		req.Header.Add("Authorization", "bearer token")
+		handler.ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+		Expect(connector.subscribeRequests()).To(BeEmpty())
+	})
+
+	It("does not subscribe when there are no readable apps", func() {
+		appLister.setResult(nil, http.StatusOK)
+
+		req, _ := http.NewRequest("GET", "/spaces/space-guid/firehose/sub-id", nil)
+		req.Header.Add("Authorization", userToken("user-1"))
+
+		handler.ServeHTTP(recorder, req)
+
+		Expect(connector.subscribeRequests()).To(BeEmpty())
+	})
+
+	It("returns unauthorized when the token is rejected", func() {
+		appLister.setResult(nil, http.StatusUnauthorized)
+
+		req, _ := http.NewRequest("GET", "/spaces/space-guid/firehose/sub-id", nil)
+		handler.ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+		Expect(recorder.HeaderMap.Get("WWW-Authenticate")).To(Equal("Basic"))
+		Expect(connector.subscribeRequests()).To(BeEmpty())
+	})
+
+	It("returns not found when the organization is not visible", func() {
+		appLister.setResult(nil, http.StatusForbidden)
+
+		req, _ := http.NewRequest("GET", "/organizations/org-guid/firehose/sub-id", nil)
+		req.Header.Add("Authorization", userToken("user-1"))
+		handler.ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("returns service unavailable when an app lookup fails", func() {
+		setAppStatus("app-2", http.StatusInternalServerError)
+
+		req, _ := http.NewRequest("GET", "/spaces/space-guid/firehose/sub-id", nil)
+		req.Header.Add("Authorization", userToken("user-1"))
+		handler.ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(connector.subscribeRequests()).To(BeEmpty())
+	})
+
+	It("returns service unavailable when listing the apps fails", func() {
+		appLister.setResult(nil, http.StatusBadGateway)
+
+		req, _ := http.NewRequest("GET", "/spaces/space-guid/firehose/sub-id", nil)
+		req.Header.Add("Authorization", userToken("user-1"))
+		handler.ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(connector.subscribeRequests()).To(BeEmpty())
+	})
+
+	It("looks up the apps in parallel", func() {
+		mu.Lock()
+		appDelay = 100 * time.Millisecond
+		mu.Unlock()
+
+		req, _ := http.NewRequest("GET", "/spaces/space-guid/firehose/sub-id", nil)
+		req.Header.Add("Authorization", userToken("user-1"))
+
+		start := time.Now()
+		handler.ServeHTTP(recorder, req)
+
+		Expect(time.Since(start)).To(BeNumerically("<", 250*time.Millisecond))
+		Expect(connector.subscriptions.request.Filter.AppIDs).To(Equal(
+			[]string{"app-1", "app-2", "app-3"},
+		))
+	})
+
+	Context("when the app lookups time out", func() {
+		var original time.Duration
+
+		BeforeEach(func() {
+			original = proxy.AuthorizeTimeout
+			proxy.AuthorizeTimeout = 10 * time.Millisecond
+
+			mu.Lock()
+			appDelay = 100 * time.Millisecond
+			mu.Unlock()
+		})
+
+		AfterEach(func() {
+			proxy.AuthorizeTimeout = original
+		})
+
+		It("returns service unavailable", func() {
+			req, _ := http.NewRequest("GET", "/spaces/space-guid/firehose/sub-id", nil)
+			req.Header.Add("Authorization", userToken("user-1"))
+			handler.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(connector.subscribeRequests()).To(BeEmpty())
+		})
+	})
+
+	It("returns not found for other scopes", func() {
+		req, _ := http.NewRequest("GET", "/apps/app-guid/firehose/sub-id", nil)
+		req.Header.Add("Authorization", userToken("user-1"))
+		handler.ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+	})
+
+	Context("with an open stream", func() {
+		var (
+			server *httptest.Server
+			conn   *websocket.Conn
+		)
+
+		BeforeEach(func() {
+			server = httptest.NewServer(handler)
+
+			var err error
+			conn, _, err = websocket.DefaultDialer.Dial(
+				wsEndpoint(server, "/organizations/org-guid/firehose/sub-id"),
+				http.Header{"Authorization": []string{userToken("user-1")}},
+			)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			conn.Close()
+			server.CloseClientConnections()
+			server.Close()
+		})
+
+		It("streams envelopes to the client", func() {
+			_, msg, err := conn.ReadMessage()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(msg).To(Equal([]byte("a-slice")))
+		})
+
+		It("resubscribes when the apps change", func() {
+			Eventually(connector.subscribeRequests).Should(HaveLen(1))
+			appLister.setResult([]string{"app-4", "app-1"}, http.StatusOK)
+
+			Eventually(connector.subscribeRequests).Should(HaveLen(2))
+			subs := connector.subscribeRequests()
+			Expect(subs[1].request.Filter.AppIDs).To(Equal([]string{"app-1", "app-4"}))
+			Eventually(subs[0].ctx.Done()).Should(BeClosed())
+			Consistently(subs[1].ctx.Done()).ShouldNot(BeClosed())
+		})
+
+		It("does not resubscribe when the apps do not change", func() {
+			Consistently(connector.subscribeRequests, 500*time.Millisecond).Should(HaveLen(1))
+		})
+
+		It("closes the stream when access is revoked", func() {
+			appLister.setResult(nil, http.StatusNotFound)
+			conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+			var err error
+			for err == nil {
+				_, _, err = conn.ReadMessage()
+			}
+			Expect(err.Error()).To(ContainSubstring("websocket: close 1000"))
+		})
+
+		It("emits the number of connections as a metric", func() {
+			f := func() float64 {
+				return mockSender.GetValue("doppler_proxy.scoped_firehoses")
+			}
+			Eventually(f, 1, "100ms").Should(Equal(1.0))
+		})
+	})
+})
+
+func userToken(userID string) string {
+	claims := base64.RawURLEncoding.EncodeToString(
+		[]byte(fmt.Sprintf(`{"user_id": %q}`, userID)),
+	)
+	return "bearer header." + claims + ".signature"
+}
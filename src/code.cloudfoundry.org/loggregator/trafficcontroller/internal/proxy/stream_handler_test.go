@@ -18,6 +18,7 @@ var _ = Describe("StreamHandler", func() {
 	var (
 		auth         LogAuthorizer
 		adminAuth    AdminAuthorizer
+		appLister    *SpyAppLister
 		dopplerProxy *proxy.DopplerProxy
 		recorder     *httptest.ResponseRecorder
 
@@ -29,6 +30,7 @@ var _ = Describe("StreamHandler", func() {
 	BeforeEach(func() {
 		auth = LogAuthorizer{Result: AuthorizerResult{Status: http.StatusOK}}
 		adminAuth = AdminAuthorizer{Result: AuthorizerResult{Status: http.StatusOK}}
+		appLister = &SpyAppLister{}
 
 		connector = newSpyGRPCConnector(nil)
 		mockSender = testhelper.NewMetricClient()
@@ -37,6 +39,7 @@ var _ = Describe("StreamHandler", func() {
 		dopplerProxy = proxy.NewDopplerProxy(
 			auth.Authorize,
 			adminAuth.Authorize,
+			appLister.List,
 			connector,
 			"cookieDomain",
 			50*time.Millisecond,
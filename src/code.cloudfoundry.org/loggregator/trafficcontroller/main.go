@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"time"
 
+	"code.cloudfoundry.org/loggregator/healthendpoint"
 	"code.cloudfoundry.org/loggregator/metricemitter"
 	"code.cloudfoundry.org/loggregator/plumbing"
 
@@ -46,12 +47,15 @@ func main() {
 		log.Fatalf("Could not use GRPC creds for client: %s", err)
 	}
 
+	mirror := healthendpoint.NewMirror("loggregator", "trafficcontroller")
+
 	// metric-documentation-v2: setup function
 	metricClient, err := metricemitter.NewClient(
 		conf.MetronConfig.GRPCAddress,
 		metricemitter.WithGRPCDialOptions(grpc.WithTransportCredentials(credentials)),
 		metricemitter.WithOrigin("loggregator.trafficcontroller"),
 		metricemitter.WithPulseInterval(conf.MetricEmitterDuration),
+		metricemitter.WithMirror(mirror),
 	)
 	if err != nil {
 		log.Fatalf("Couldn't connect to metric emitter: %s", err)
@@ -61,6 +65,7 @@ func main() {
 		conf,
 		*disableAccessControl,
 		metricClient,
+		mirror,
 		uaaHTTPClient(conf),
 		ccHTTPClient(conf),
 	)
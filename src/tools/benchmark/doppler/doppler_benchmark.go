@@ -33,6 +33,7 @@ func main() {
 	iterations := flag.Int("iter", 10000, "The number of envelopes to emit to doppler.")
 	delay := flag.Duration("delay", 2*time.Microsecond, "The delay between envelope emission.")
 	cycles := flag.Int("cycles", 5, "The number of tests to run")
+	subscribers := flag.Int("subscribers", 1, "The number of firehose subscriptions. Each has its own shard ID.")
 	flag.Parse()
 
 	if *dopplerCmd == "" {
@@ -64,8 +65,12 @@ func main() {
 		log.Fatalf("Unable to setup TLS")
 	}
 
-	c := newConsumer()
-	go c.Start(*dopplerAddr, creds)
+	var consumers []*consumer
+	for i := 0; i < *subscribers; i++ {
+		c := newConsumer(fmt.Sprintf("doppler-benchmark-%d", i))
+		consumers = append(consumers, c)
+		go c.Start(*dopplerAddr, creds)
+	}
 
 	chunks := strings.SplitN(*dopplerCmd, " ", -1)
 
@@ -79,14 +84,21 @@ func main() {
 	var results []int64
 	for i := 0; i < *cycles; i++ {
 		time.Sleep(5 * time.Second)
-		atomic.StoreInt64(&c.count, 0)
+		for _, c := range consumers {
+			atomic.StoreInt64(&c.count, 0)
+		}
 
 		produceEnvelopes(*iterations, *delay, *dopplerAddr, creds)
 
 		time.Sleep(5 * time.Second)
 
-		log.Printf("Total Received: %d", atomic.LoadInt64(&c.count))
-		results = append(results, atomic.LoadInt64(&c.count))
+		var received int64
+		for _, c := range consumers {
+			received += atomic.LoadInt64(&c.count)
+		}
+
+		log.Printf("Total Received: %d", received)
+		results = append(results, received)
 	}
 
 	fmt.Printf("%s %v\n", *resultTag, results)
@@ -138,11 +150,14 @@ func createEnvelope() []byte {
 }
 
 type consumer struct {
-	count int64
+	shardID string
+	count   int64
 }
 
-func newConsumer() *consumer {
-	return &consumer{}
+func newConsumer(shardID string) *consumer {
+	return &consumer{
+		shardID: shardID,
+	}
 }
 
 func (c *consumer) Start(addr string, creds credentials.TransportCredentials) {
@@ -154,7 +169,7 @@ func (c *consumer) Start(addr string, creds credentials.TransportCredentials) {
 	client := plumbing.NewDopplerClient(conn)
 	ctx := context.Background()
 	req := &plumbing.SubscriptionRequest{
-		ShardID: "doppler-benchmark",
+		ShardID: c.shardID,
 	}
 	subClient, err := client.Subscribe(ctx, req)
 	if err != nil {